@@ -162,11 +162,57 @@ func (s *aiGRPCServer) ProcessAudioData(ctx context.Context, req *pb.ProcessAudi
 }
 
 func (s *aiGRPCServer) ProcessVideoFrame(ctx context.Context, req *pb.ProcessVideoFrameRequest) (*pb.ProcessVideoFrameResponse, error) {
+	taskID := fmt.Sprintf("ai_video_%d", time.Now().UnixNano())
+	now := timestamppb.Now()
+
+	frame := req.GetFrameData()
+	if len(frame) == 0 {
+		return &pb.ProcessVideoFrameResponse{
+			TaskId:  taskID,
+			Status:  "error",
+			Error:   "frame_data is required",
+			Results: map[string]*pb.AIResult{},
+		}, nil
+	}
+
+	taskList := req.GetTasks()
+	if len(taskList) == 0 {
+		taskList = []string{"face_detection"}
+	}
+
+	taskResults, firstErr := s.aiService.AnalyzeVideoFrame(ctx, services.VideoFrame{
+		Data:   frame,
+		Format: req.GetFormat(),
+		Width:  int(req.GetWidth()),
+		Height: int(req.GetHeight()),
+	}, taskList)
+
+	results := make(map[string]*pb.AIResult, len(taskResults))
+	for _, r := range taskResults {
+		payload, _ := json.Marshal(r.Payload)
+		results[r.Task] = &pb.AIResult{
+			ResultType: r.Task,
+			ResultData: string(payload),
+			Confidence: r.Confidence,
+			CreatedAt:  now,
+		}
+	}
+
+	status := "ok"
+	errText := ""
+	if firstErr != nil && len(results) == 0 {
+		status = "error"
+		errText = firstErr.Error()
+	} else if firstErr != nil {
+		status = "partial"
+		errText = firstErr.Error()
+	}
+
 	return &pb.ProcessVideoFrameResponse{
-		TaskId:  fmt.Sprintf("ai_video_%d", time.Now().UnixNano()),
-		Status:  "unimplemented",
-		Error:   "video processing is not implemented by ai-inference-service",
-		Results: map[string]*pb.AIResult{},
+		TaskId:  taskID,
+		Status:  status,
+		Error:   errText,
+		Results: results,
 	}, nil
 }
 
@@ -211,7 +257,43 @@ func (s *aiGRPCServer) StreamAudioProcessing(stream pb.AIService_StreamAudioProc
 }
 
 func (s *aiGRPCServer) StreamVideoProcessing(stream pb.AIService_StreamVideoProcessingServer) error {
-	return fmt.Errorf("video streaming is not implemented by ai-inference-service")
+	ctx := stream.Context()
+	var session *services.VideoStreamSession
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if session == nil {
+			session = services.NewVideoStreamSession(
+				s.aiService,
+				chunk.GetStreamId(),
+				chunk.GetTasks(),
+				chunk.GetFormat(),
+				int(chunk.GetWidth()),
+				int(chunk.GetHeight()),
+			)
+		}
+
+		results, err := session.Append(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+		}
+
+		if chunk.GetIsFinal() {
+			return nil
+		}
+	}
 }
 
 func startAIGrpcServer(port int, aiService *services.AIInferenceService) (*grpc.Server, error) {
@@ -12,6 +12,7 @@ import (
 
 	"meeting-system/ai-inference-service/runtime"
 	"meeting-system/ai-inference-service/runtime/audio"
+	"meeting-system/ai-inference-service/runtime/vision"
 )
 
 type Model struct {
@@ -55,6 +56,10 @@ func (m *Model) Infer(ctx context.Context, req runtime.InferenceRequest) (*runti
 		return m.inferEmotion(ctx, req)
 	case runtime.TaskSynthesis:
 		return m.inferSynthesis(ctx, req)
+	case runtime.TaskFaceDetection:
+		return m.inferFaceDetection(ctx, req)
+	case runtime.TaskDeepfake:
+		return m.inferDeepfake(ctx, req)
 	default:
 		return nil, runtime.ErrInferenceNotImplemented
 	}
@@ -356,6 +361,140 @@ func (m *Model) inferSynthesis(ctx context.Context, req runtime.InferenceRequest
 	}, nil
 }
 
+func (m *Model) inferFaceDetection(ctx context.Context, req runtime.InferenceRequest) (*runtime.InferenceResult, error) {
+	outputs, err := m.inferImage(ctx, req, "detections")
+	if err != nil {
+		return nil, err
+	}
+	values := toFloat32Slice(outputs.Data)
+	threshold := m.spec.ScoreThreshold
+	if req.Params != nil {
+		if v, err := strconv.ParseFloat(req.Params["score_threshold"], 64); err == nil {
+			threshold = v
+		}
+	}
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+
+	inputW, inputH := float64(req.ImageShape[3]), float64(req.ImageShape[2])
+	boxes, err := parseDetections(values, outputs.Shape, threshold, inputW, inputH)
+	if err != nil {
+		return nil, err
+	}
+	confidence := 0.0
+	if len(boxes) > 0 {
+		confidence = boxes[0].Score
+	}
+
+	return &runtime.InferenceResult{
+		Outputs: map[string]interface{}{
+			"faces":      boxes,
+			"face_count": len(boxes),
+			"confidence": confidence,
+		},
+	}, nil
+}
+
+func (m *Model) inferDeepfake(ctx context.Context, req runtime.InferenceRequest) (*runtime.InferenceResult, error) {
+	outputs, err := m.inferImage(ctx, req, "logits")
+	if err != nil {
+		return nil, err
+	}
+	values := toFloat32Slice(outputs.Data)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("deepfake output empty")
+	}
+
+	// 单输出按 sigmoid，两类输出取 fake 类（下标 1）的 softmax 概率
+	prob := synthesisProbability(values)
+	return &runtime.InferenceResult{
+		Outputs: map[string]interface{}{
+			"is_deepfake":      prob > 0.5,
+			"probability_fake": prob,
+			"confidence":       prob,
+		},
+	}, nil
+}
+
+// inferImage sends a preprocessed NCHW image tensor to the model.
+func (m *Model) inferImage(ctx context.Context, req runtime.InferenceRequest, defaultOutput string) (tensorResponse, error) {
+	if len(req.ImageTensor) == 0 || len(req.ImageShape) != 4 {
+		return tensorResponse{}, fmt.Errorf("NCHW image tensor is required for %s", m.spec.Task)
+	}
+	if m.client == nil {
+		return tensorResponse{}, fmt.Errorf("triton client not initialized")
+	}
+	inputName := strings.TrimSpace(m.spec.InputName)
+	if inputName == "" {
+		inputName = "image_input"
+	}
+	outputNames := m.spec.OutputNames
+	if len(outputNames) == 0 {
+		outputNames = []string{defaultOutput}
+	}
+	modelName := strings.TrimSpace(m.spec.Name)
+	if modelName == "" {
+		return tensorResponse{}, fmt.Errorf("%s model name is required", m.spec.Task)
+	}
+
+	inputs := []inferenceInput{
+		{
+			Name:     inputName,
+			Datatype: "FP32",
+			Shape:    req.ImageShape,
+			Data:     req.ImageTensor,
+		},
+	}
+	outputs, err := m.client.Infer(ctx, modelName, inputs, outputNames)
+	if err != nil {
+		return tensorResponse{}, err
+	}
+	return selectOutput(outputs, outputNames)
+}
+
+// parseDetections decodes a [..., N, K>=5] detection tensor whose rows are
+// (x1, y1, x2, y2, score). Coordinates may be normalized or in input pixels;
+// boxes are returned normalized and sorted by descending score.
+func parseDetections(values []float32, shape []int64, threshold, inputW, inputH float64) ([]vision.Box, error) {
+	if len(values) == 0 {
+		return []vision.Box{}, nil
+	}
+	if len(shape) < 2 {
+		return nil, fmt.Errorf("unexpected detection output shape: %v", shape)
+	}
+	stride := int(shape[len(shape)-1])
+	if stride < 5 || len(values)%stride != 0 {
+		return nil, fmt.Errorf("unexpected detection output shape: %v", shape)
+	}
+
+	boxes := make([]vision.Box, 0)
+	for off := 0; off < len(values); off += stride {
+		row := values[off : off+stride]
+		score := float64(row[4])
+		if score < threshold {
+			continue
+		}
+		box := vision.Box{X1: float64(row[0]), Y1: float64(row[1]), X2: float64(row[2]), Y2: float64(row[3]), Score: score}
+		if box.X2 > 1.5 || box.Y2 > 1.5 {
+			box.X1 /= inputW
+			box.X2 /= inputW
+			box.Y1 /= inputH
+			box.Y2 /= inputH
+		}
+		box.X1 = math.Max(0, box.X1)
+		box.Y1 = math.Max(0, box.Y1)
+		box.X2 = math.Min(1, box.X2)
+		box.Y2 = math.Min(1, box.Y2)
+		if box.Area() == 0 {
+			continue
+		}
+		boxes = append(boxes, box)
+	}
+	sort.SliceStable(boxes, func(i, j int) bool { return boxes[i].Score > boxes[j].Score })
+	return boxes, nil
+}
+
 func loadLabels(path string) ([]string, error) {
 	if strings.TrimSpace(path) == "" {
 		return nil, nil
@@ -0,0 +1,109 @@
+package triton
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/ai-inference-service/runtime"
+	"meeting-system/ai-inference-service/runtime/vision"
+)
+
+// fakeTriton 在 CPU 上模拟 Triton HTTP 推理接口，按模型名返回固定输出
+func fakeTriton(t *testing.T, outputs map[string]tensorResponse, got *inferenceRequest) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/models/{name}/ready", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := outputs[r.PathValue("name")]; !ok {
+			http.Error(w, "unknown model", http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/v2/models/{name}/infer", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(got))
+		_ = json.NewEncoder(w).Encode(inferenceResponse{Outputs: []tensorResponse{outputs[r.PathValue("name")]}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func imageRequest(task runtime.TaskType) runtime.InferenceRequest {
+	return runtime.InferenceRequest{
+		Task:        task,
+		ImageTensor: make([]float32, 3*20*40),
+		ImageShape:  []int64{1, 3, 20, 40},
+	}
+}
+
+func TestModel_InferFaceDetection(t *testing.T) {
+	var got inferenceRequest
+	srv := fakeTriton(t, map[string]tensorResponse{
+		"faces": {
+			Name:  "detections",
+			Shape: []int64{1, 3, 5},
+			Data: []interface{}{
+				0.1, 0.1, 0.3, 0.4, 0.6,
+				4.0, 2.0, 20.0, 10.0, 0.95, // 输入像素坐标
+				0.5, 0.5, 0.6, 0.6, 0.2, // 低于阈值
+			},
+		},
+	}, &got)
+
+	engine := NewEngine(runtime.EngineConfig{TritonEndpoint: srv.URL, TritonTimeoutMs: int(time.Second / time.Millisecond)})
+	model, err := engine.LoadModel(context.Background(), runtime.ModelSpec{Name: "faces", Task: runtime.TaskFaceDetection, ScoreThreshold: 0.5})
+	require.NoError(t, err)
+
+	result, err := model.Infer(context.Background(), imageRequest(runtime.TaskFaceDetection))
+	require.NoError(t, err)
+	require.Len(t, got.Inputs, 1)
+	assert.Equal(t, "image_input", got.Inputs[0].Name)
+	assert.Equal(t, []int64{1, 3, 20, 40}, got.Inputs[0].Shape)
+
+	faces := result.Outputs["faces"].([]vision.Box)
+	require.Len(t, faces, 2)
+	assert.InDelta(t, 0.1, faces[0].X1, 1e-6)
+	assert.InDelta(t, 0.1, faces[0].Y1, 1e-6)
+	assert.InDelta(t, 0.5, faces[0].X2, 1e-6)
+	assert.InDelta(t, 0.5, faces[0].Y2, 1e-6)
+	assert.InDelta(t, 0.95, faces[0].Score, 1e-6)
+	assert.InDelta(t, 0.6, faces[1].Score, 1e-6)
+	assert.Equal(t, 2, result.Outputs["face_count"])
+	assert.InDelta(t, 0.95, result.Outputs["confidence"], 1e-6)
+
+	_, err = engine.LoadModel(context.Background(), runtime.ModelSpec{Name: "missing", Task: runtime.TaskFaceDetection})
+	assert.Error(t, err)
+}
+
+func TestModel_InferDeepfake(t *testing.T) {
+	var got inferenceRequest
+	srv := fakeTriton(t, map[string]tensorResponse{
+		"deepfake": {Name: "logits", Shape: []int64{1, 2}, Data: []interface{}{0.0, 2.0}},
+	}, &got)
+
+	engine := NewEngine(runtime.EngineConfig{TritonEndpoint: srv.URL})
+	model, err := engine.LoadModel(context.Background(), runtime.ModelSpec{Name: "deepfake", Task: runtime.TaskDeepfake})
+	require.NoError(t, err)
+
+	result, err := model.Infer(context.Background(), imageRequest(runtime.TaskDeepfake))
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Outputs["is_deepfake"])
+	assert.InDelta(t, 0.8808, result.Outputs["probability_fake"], 1e-4)
+
+	_, err = model.Infer(context.Background(), runtime.InferenceRequest{Task: runtime.TaskDeepfake})
+	assert.Error(t, err, "缺少图像张量")
+}
+
+func TestParseDetections_InvalidShape(t *testing.T) {
+	_, err := parseDetections([]float32{1, 2, 3, 4}, []int64{1, 4}, 0.5, 1, 1)
+	assert.Error(t, err)
+
+	boxes, err := parseDetections(nil, nil, 0.5, 1, 1)
+	require.NoError(t, err)
+	assert.Empty(t, boxes)
+}
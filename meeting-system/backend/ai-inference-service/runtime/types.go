@@ -9,6 +9,10 @@ const (
 	TaskASR       TaskType = "asr"
 	TaskEmotion   TaskType = "emotion"
 	TaskSynthesis TaskType = "synthesis"
+	// TaskDeepfake classifies a face crop as real or manipulated.
+	TaskDeepfake TaskType = "deepfake"
+	// TaskFaceDetection locates faces in a frame (participant presence).
+	TaskFaceDetection TaskType = "face_detection"
 )
 
 var (
@@ -47,6 +51,11 @@ type ModelSpec struct {
 	SpecialTokensPath  string
 	ConfigPath         string
 	LabelsPath         string
+	InputWidth         int
+	InputHeight        int
+	Mean               []float32
+	Std                []float32
+	ScoreThreshold     float64
 }
 
 // InferenceRequest represents the input payload for a model.
@@ -57,7 +66,10 @@ type InferenceRequest struct {
 	SampleRate   int
 	Channels     int
 	Text         string
-	Params       map[string]string
+	// ImageTensor holds a preprocessed NCHW float32 image with shape ImageShape.
+	ImageTensor []float32
+	ImageShape  []int64
+	Params      map[string]string
 }
 
 // InferenceResult wraps model outputs for downstream parsing.
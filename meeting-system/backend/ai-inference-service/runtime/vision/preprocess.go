@@ -0,0 +1,196 @@
+package vision
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Box is a detection box in coordinates normalized to [0,1] of the source frame.
+type Box struct {
+	X1    float64 `json:"x1"`
+	Y1    float64 `json:"y1"`
+	X2    float64 `json:"x2"`
+	Y2    float64 `json:"y2"`
+	Score float64 `json:"score"`
+}
+
+// Area returns the normalized box area.
+func (b Box) Area() float64 {
+	w := b.X2 - b.X1
+	h := b.Y2 - b.Y1
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+// DecodeFrame decodes an encoded (jpeg/png) or raw rgb24 video frame.
+func DecodeFrame(data []byte, format string, width, height int) (image.Image, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty video frame")
+	}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "jpeg", "jpg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode jpeg frame: %w", err)
+		}
+		return img, nil
+	case "png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode png frame: %w", err)
+		}
+		return img, nil
+	case "rgb24", "rgb":
+		if width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("invalid rgb24 frame size: %dx%d", width, height)
+		}
+		if len(data) != width*height*3 {
+			return nil, fmt.Errorf("rgb24 frame size mismatch: got %d bytes, want %d", len(data), width*height*3)
+		}
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for i, j := 0, 0; i < len(data); i, j = i+3, j+4 {
+			img.Pix[j] = data[i]
+			img.Pix[j+1] = data[i+1]
+			img.Pix[j+2] = data[i+2]
+			img.Pix[j+3] = 0xFF
+		}
+		return img, nil
+	default:
+		return nil, fmt.Errorf("unsupported video frame format: %s", format)
+	}
+}
+
+// ToNCHW resizes the image (bilinear) to width x height and returns a normalized
+// [1,3,H,W] RGB float32 tensor: (pixel/255 - mean[c]) / std[c].
+func ToNCHW(img image.Image, width, height int, mean, std []float32) ([]float32, []int64, error) {
+	if img == nil {
+		return nil, nil, errors.New("image is nil")
+	}
+	if width <= 0 || height <= 0 {
+		return nil, nil, fmt.Errorf("invalid tensor size: %dx%d", width, height)
+	}
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return nil, nil, errors.New("empty image")
+	}
+
+	var m, s [3]float32
+	for c := 0; c < 3; c++ {
+		m[c] = channelParam(mean, c, 0)
+		s[c] = channelParam(std, c, 1)
+		if s[c] == 0 {
+			return nil, nil, fmt.Errorf("std for channel %d must be non-zero", c)
+		}
+	}
+
+	plane := width * height
+	tensor := make([]float32, 3*plane)
+	scaleX := float64(srcW) / float64(width)
+	scaleY := float64(srcH) / float64(height)
+	for y := 0; y < height; y++ {
+		// pixel-center alignment, same as cv2.resize(INTER_LINEAR)
+		sy := clampFloat((float64(y)+0.5)*scaleY-0.5, 0, float64(srcH-1))
+		y0 := int(sy)
+		y1 := minInt(y0+1, srcH-1)
+		fy := sy - float64(y0)
+		for x := 0; x < width; x++ {
+			sx := clampFloat((float64(x)+0.5)*scaleX-0.5, 0, float64(srcW-1))
+			x0 := int(sx)
+			x1 := minInt(x0+1, srcW-1)
+			fx := sx - float64(x0)
+
+			p00 := rgbAt(img, bounds.Min.X+x0, bounds.Min.Y+y0)
+			p01 := rgbAt(img, bounds.Min.X+x1, bounds.Min.Y+y0)
+			p10 := rgbAt(img, bounds.Min.X+x0, bounds.Min.Y+y1)
+			p11 := rgbAt(img, bounds.Min.X+x1, bounds.Min.Y+y1)
+
+			idx := y*width + x
+			for c := 0; c < 3; c++ {
+				top := p00[c]*(1-fx) + p01[c]*fx
+				bottom := p10[c]*(1-fx) + p11[c]*fx
+				v := float32((top*(1-fy) + bottom*fy) / 255.0)
+				tensor[c*plane+idx] = (v - m[c]) / s[c]
+			}
+		}
+	}
+	return tensor, []int64{1, 3, int64(height), int64(width)}, nil
+}
+
+// Crop returns the region of img covered by the normalized box, expanded by
+// margin (fraction of the box size on each side) and clamped to the image.
+func Crop(img image.Image, box Box, margin float64) (image.Image, error) {
+	bounds := img.Bounds()
+	w := float64(bounds.Dx())
+	h := float64(bounds.Dy())
+	bw := (box.X2 - box.X1) * w
+	bh := (box.Y2 - box.Y1) * h
+	if bw <= 0 || bh <= 0 {
+		return nil, fmt.Errorf("invalid crop box: %+v", box)
+	}
+	rect := image.Rect(
+		bounds.Min.X+int(math.Floor(box.X1*w-bw*margin)),
+		bounds.Min.Y+int(math.Floor(box.Y1*h-bh*margin)),
+		bounds.Min.X+int(math.Ceil(box.X2*w+bw*margin)),
+		bounds.Min.Y+int(math.Ceil(box.Y2*h+bh*margin)),
+	).Intersect(bounds)
+	if rect.Empty() {
+		return nil, fmt.Errorf("crop box outside image: %+v", box)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			out.Set(x, y, img.At(rect.Min.X+x, rect.Min.Y+y))
+		}
+	}
+	return out, nil
+}
+
+func rgbAt(img image.Image, x, y int) [3]float64 {
+	if rgba, ok := img.(*image.RGBA); ok {
+		i := rgba.PixOffset(x, y)
+		return [3]float64{float64(rgba.Pix[i]), float64(rgba.Pix[i+1]), float64(rgba.Pix[i+2])}
+	}
+	c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	return [3]float64{float64(c.R), float64(c.G), float64(c.B)}
+}
+
+func channelParam(values []float32, c int, fallback float32) float32 {
+	switch {
+	case len(values) == 0:
+		return fallback
+	case len(values) == 1:
+		return values[0]
+	case c < len(values):
+		return values[c]
+	default:
+		return fallback
+	}
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
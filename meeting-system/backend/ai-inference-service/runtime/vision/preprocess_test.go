@@ -0,0 +1,69 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFrameRGB24(t *testing.T) {
+	img, err := DecodeFrame([]byte{255, 0, 0, 0, 255, 0}, "rgb24", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(0, 0))
+	assert.Equal(t, color.RGBA{G: 255, A: 255}, img.At(1, 0))
+
+	_, err = DecodeFrame([]byte{1, 2, 3}, "rgb24", 2, 1)
+	assert.Error(t, err)
+	_, err = DecodeFrame([]byte{1}, "bmp", 1, 1)
+	assert.Error(t, err)
+}
+
+func TestToNCHW(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: 255, G: 0, B: 51, A: 255})
+		}
+	}
+
+	tensor, shape, err := ToNCHW(img, 2, 2, []float32{0.5, 0, 0}, []float32{0.5, 1, 0.2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3, 2, 2}, shape)
+	require.Len(t, tensor, 12)
+	for i := 0; i < 4; i++ {
+		assert.InDelta(t, 1.0, tensor[i], 1e-6, "R 平面")
+		assert.InDelta(t, 0.0, tensor[4+i], 1e-6, "G 平面")
+		assert.InDelta(t, 1.0, tensor[8+i], 1e-6, "B 平面")
+	}
+
+	_, _, err = ToNCHW(img, 2, 2, nil, []float32{0})
+	assert.Error(t, err)
+}
+
+func TestToNCHWBilinear(t *testing.T) {
+	// 左黑右白，缩放到 1 像素宽时取中间值
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.Pix[1] = 255
+	tensor, _, err := ToNCHW(img, 1, 1, nil, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, tensor[0], 1e-6)
+}
+
+func TestCrop(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	crop, err := Crop(img, Box{X1: 0.2, Y1: 0.2, X2: 0.6, Y2: 0.6}, 0.25)
+	require.NoError(t, err)
+	// 40x20 的框每边外扩 25%
+	assert.Equal(t, 60, crop.Bounds().Dx())
+	assert.Equal(t, 30, crop.Bounds().Dy())
+
+	crop, err = Crop(img, Box{X1: 0, Y1: 0, X2: 0.5, Y2: 1}, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 75, 50), crop.Bounds(), "超出画面的部分被裁掉")
+
+	_, err = Crop(img, Box{X1: 0.5, X2: 0.5, Y2: 1}, 0)
+	assert.Error(t, err)
+}
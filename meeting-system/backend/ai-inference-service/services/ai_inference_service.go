@@ -130,9 +130,11 @@ func (s *AIInferenceService) WarmupMeeting(ctx context.Context, meetingID uint,
 	}
 
 	modelMap := map[string]runtime.TaskType{
-		"asr":       runtime.TaskASR,
-		"emotion":   runtime.TaskEmotion,
-		"synthesis": runtime.TaskSynthesis,
+		"asr":            runtime.TaskASR,
+		"emotion":        runtime.TaskEmotion,
+		"synthesis":      runtime.TaskSynthesis,
+		"deepfake":       runtime.TaskDeepfake,
+		"face_detection": runtime.TaskFaceDetection,
 	}
 
 	if len(models) == 0 {
@@ -136,6 +136,8 @@ func NewModelManager(ctx context.Context, cfg *config.Config) (*ModelManager, er
 			SampleRate:         cfg.AI.Models.Synthesis.SampleRate,
 			Channels:           cfg.AI.Models.Synthesis.Channels,
 		})
+		load(runtime.TaskDeepfake, imageModelSpec(runtime.TaskDeepfake, cfg.AI.Models.Deepfake))
+		load(runtime.TaskFaceDetection, imageModelSpec(runtime.TaskFaceDetection, cfg.AI.Models.FaceDetection))
 	}
 
 	if len(loadErrors) > 0 {
@@ -145,6 +147,35 @@ func NewModelManager(ctx context.Context, cfg *config.Config) (*ModelManager, er
 	return manager, nil
 }
 
+// imageModelSpec builds the spec for an image model (NCHW input).
+func imageModelSpec(task runtime.TaskType, cfg config.AIModelConfig) runtime.ModelSpec {
+	return runtime.ModelSpec{
+		Name:           cfg.ModelName,
+		Task:           task,
+		Path:           cfg.ModelPath,
+		InputName:      cfg.InputName,
+		OutputNames:    cfg.OutputNames,
+		InputType:      cfg.InputType,
+		LabelsPath:     cfg.LabelsPath,
+		InputWidth:     cfg.InputWidth,
+		InputHeight:    cfg.InputHeight,
+		Mean:           toFloat32s(cfg.Mean),
+		Std:            toFloat32s(cfg.Std),
+		ScoreThreshold: cfg.ScoreThreshold,
+	}
+}
+
+func toFloat32s(values []float64) []float32 {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]float32, len(values))
+	for i, v := range values {
+		out[i] = float32(v)
+	}
+	return out
+}
+
 // GetModel returns a loaded model for the given task.
 func (m *ModelManager) GetModel(task runtime.TaskType) (runtime.Model, runtime.ModelSpec, bool) {
 	if m == nil {
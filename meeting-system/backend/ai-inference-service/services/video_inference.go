@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"meeting-system/ai-inference-service/runtime"
+	"meeting-system/ai-inference-service/runtime/vision"
+)
+
+const (
+	videoTaskFaceDetection = "face_detection"
+	videoTaskDeepfake      = "deepfake_detection"
+
+	defaultFaceInputSize     = 320
+	defaultDeepfakeInputSize = 224
+	// deepfakeFaceMargin 裁剪人脸时每边外扩的比例（相对人脸框尺寸）
+	deepfakeFaceMargin = 0.2
+)
+
+// ErrVideoGatewayUnsupported 网关模式下远端 HTTP AI 服务没有视频接口
+var ErrVideoGatewayUnsupported = errors.New("video inference is not supported in gateway mode")
+
+// VideoFrame 待分析的视频帧（jpeg/png 或 rgb24 原始像素）
+type VideoFrame struct {
+	Data   []byte
+	Format string
+	Width  int
+	Height int
+}
+
+// VideoTaskResult 单个视频任务的结果
+type VideoTaskResult struct {
+	Task       string
+	Payload    interface{}
+	Confidence float64
+}
+
+// FaceDetectionResponse 人脸 / 在场检测响应
+type FaceDetectionResponse struct {
+	Present    bool         `json:"present"`    // 画面中是否有人
+	FaceCount  int          `json:"face_count"` // 人脸数量
+	Faces      []vision.Box `json:"faces"`      // 归一化人脸框，按置信度降序
+	Confidence float64      `json:"confidence"` // 最高人脸置信度
+	Duration   float64      `json:"duration_ms"`
+}
+
+// DeepfakeDetectionResponse 视频深度伪造检测响应
+type DeepfakeDetectionResponse struct {
+	IsDeepfake bool        `json:"is_deepfake"` // 是否为伪造人脸
+	Confidence float64     `json:"confidence"`  // 置信度
+	Score      float64     `json:"score"`       // 伪造分数（0-1）
+	FaceCount  int         `json:"face_count"`  // 检测到的人脸数量（未加载人脸模型时为 -1）
+	Face       *vision.Box `json:"face,omitempty"`
+	Duration   float64     `json:"duration_ms"`
+}
+
+// normalizeVideoTask 视频任务名归一化；非视频任务返回空字符串
+func normalizeVideoTask(task string) string {
+	switch strings.ToLower(strings.TrimSpace(task)) {
+	case "face_detection", "face", "presence_detection", "presence":
+		return videoTaskFaceDetection
+	case "deepfake_detection", "deepfake", "synthesis_detection", "synthesis":
+		// 视频帧上的合成检测即人脸深度伪造检测
+		return videoTaskDeepfake
+	default:
+		return ""
+	}
+}
+
+// AnalyzeVideoFrame 解码视频帧并依次执行视频任务。
+// 单个任务失败不影响其它任务：返回已成功的结果和第一个错误；同一帧的人脸检测结果在任务间复用。
+func (s *AIInferenceService) AnalyzeVideoFrame(ctx context.Context, frame VideoFrame, tasks []string) ([]VideoTaskResult, error) {
+	if s == nil {
+		return nil, fmt.Errorf("ai service not initialized")
+	}
+	if s.remote != nil {
+		return nil, ErrVideoGatewayUnsupported
+	}
+	img, err := vision.DecodeFrame(frame.Data, frame.Format, frame.Width, frame.Height)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		tasks = []string{videoTaskFaceDetection}
+	}
+
+	var (
+		results  []VideoTaskResult
+		firstErr error
+		faces    *FaceDetectionResponse
+	)
+	detect := func() (*FaceDetectionResponse, error) {
+		if faces != nil {
+			return faces, nil
+		}
+		resp, err := s.detectFaces(ctx, img)
+		if err != nil {
+			return nil, err
+		}
+		faces = resp
+		return faces, nil
+	}
+
+	for _, rawTask := range tasks {
+		switch normalizeVideoTask(rawTask) {
+		case videoTaskFaceDetection:
+			resp, err := detect()
+			if err != nil {
+				firstErr = firstError(firstErr, err)
+				continue
+			}
+			results = append(results, VideoTaskResult{Task: videoTaskFaceDetection, Payload: resp, Confidence: resp.Confidence})
+		case videoTaskDeepfake:
+			var detected *FaceDetectionResponse
+			if s.hasModel(runtime.TaskFaceDetection) {
+				if detected, err = detect(); err != nil {
+					firstErr = firstError(firstErr, err)
+					continue
+				}
+			}
+			resp, err := s.detectDeepfake(ctx, img, detected)
+			if err != nil {
+				firstErr = firstError(firstErr, err)
+				continue
+			}
+			results = append(results, VideoTaskResult{Task: videoTaskDeepfake, Payload: resp, Confidence: resp.Confidence})
+		default:
+			firstErr = firstError(firstErr, fmt.Errorf("unsupported video task: %s", rawTask))
+		}
+	}
+	return results, firstErr
+}
+
+// FaceDetectionImage 人脸 / 在场检测
+func (s *AIInferenceService) FaceDetectionImage(ctx context.Context, img image.Image) (*FaceDetectionResponse, error) {
+	if s.remote != nil {
+		return nil, ErrVideoGatewayUnsupported
+	}
+	return s.detectFaces(ctx, img)
+}
+
+// DeepfakeDetectionImage 视频深度伪造检测：加载了人脸模型时对置信度最高的人脸裁剪后检测，否则检测整帧
+func (s *AIInferenceService) DeepfakeDetectionImage(ctx context.Context, img image.Image) (*DeepfakeDetectionResponse, error) {
+	if s.remote != nil {
+		return nil, ErrVideoGatewayUnsupported
+	}
+	var faces *FaceDetectionResponse
+	if s.hasModel(runtime.TaskFaceDetection) {
+		var err error
+		if faces, err = s.detectFaces(ctx, img); err != nil {
+			return nil, err
+		}
+	}
+	return s.detectDeepfake(ctx, img, faces)
+}
+
+func (s *AIInferenceService) detectFaces(ctx context.Context, img image.Image) (*FaceDetectionResponse, error) {
+	startTime := time.Now()
+
+	result, err := s.inferImage(ctx, runtime.TaskFaceDetection, img, defaultFaceInputSize)
+	if err != nil {
+		return nil, err
+	}
+
+	faces := []vision.Box{}
+	if result != nil && result.Outputs != nil {
+		if boxes, ok := result.Outputs["faces"].([]vision.Box); ok {
+			faces = boxes
+		}
+	}
+	confidence := 0.0
+	if len(faces) > 0 {
+		confidence = faces[0].Score
+	}
+
+	return &FaceDetectionResponse{
+		Present:    len(faces) > 0,
+		FaceCount:  len(faces),
+		Faces:      faces,
+		Confidence: confidence,
+		Duration:   float64(time.Since(startTime).Milliseconds()),
+	}, nil
+}
+
+// detectDeepfake faces 为 nil 表示未做人脸检测，对整帧推理；检测到 0 张人脸时不做判断
+func (s *AIInferenceService) detectDeepfake(ctx context.Context, img image.Image, faces *FaceDetectionResponse) (*DeepfakeDetectionResponse, error) {
+	startTime := time.Now()
+
+	response := &DeepfakeDetectionResponse{FaceCount: -1}
+	input := img
+	if faces != nil {
+		response.FaceCount = faces.FaceCount
+		if faces.FaceCount == 0 {
+			response.Duration = float64(time.Since(startTime).Milliseconds())
+			return response, nil
+		}
+		face := faces.Faces[0]
+		crop, err := vision.Crop(img, face, deepfakeFaceMargin)
+		if err != nil {
+			return nil, err
+		}
+		input = crop
+		response.Face = &face
+	}
+
+	result, err := s.inferImage(ctx, runtime.TaskDeepfake, input, defaultDeepfakeInputSize)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && result.Outputs != nil {
+		if val, ok := result.Outputs["is_deepfake"].(bool); ok {
+			response.IsDeepfake = val
+		}
+		response.Score = extractFloat(result.Outputs, "probability_fake", response.Score)
+		response.Confidence = extractFloat(result.Outputs, "confidence", response.Confidence)
+		if !response.IsDeepfake && response.Score > 0 {
+			response.IsDeepfake = response.Score > 0.5
+		}
+	}
+	response.Duration = float64(time.Since(startTime).Milliseconds())
+	return response, nil
+}
+
+// inferImage 按模型配置的输入尺寸与归一化参数生成 NCHW 张量后推理
+func (s *AIInferenceService) inferImage(ctx context.Context, task runtime.TaskType, img image.Image, defaultSize int) (*runtime.InferenceResult, error) {
+	if s == nil || s.models == nil {
+		return nil, fmt.Errorf("model manager not initialized")
+	}
+
+	_, spec, loaded := s.models.GetModel(task)
+	if !loaded {
+		return nil, runtime.ErrModelNotLoaded
+	}
+
+	width, height := spec.InputWidth, spec.InputHeight
+	if width <= 0 {
+		width = defaultSize
+	}
+	if height <= 0 {
+		height = defaultSize
+	}
+	tensor, shape, err := vision.ToNCHW(img, width, height, spec.Mean, spec.Std)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := s.models.Infer(ctx, task, runtime.InferenceRequest{
+		Task:        task,
+		ImageTensor: tensor,
+		ImageShape:  shape,
+	})
+	return result, err
+}
+
+func (s *AIInferenceService) hasModel(task runtime.TaskType) bool {
+	if s == nil || s.models == nil {
+		return false
+	}
+	_, _, loaded := s.models.GetModel(task)
+	return loaded
+}
+
+func firstError(current, err error) error {
+	if current != nil {
+		return current
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/ai-inference-service/runtime"
+	"meeting-system/ai-inference-service/runtime/vision"
+	pb "meeting-system/shared/grpc"
+)
+
+// cpuImageModel CPU 测试替身：人脸模型返回固定人脸框，伪造模型以输入张量均值作为伪造概率
+type cpuImageModel struct {
+	spec   runtime.ModelSpec
+	faces  []vision.Box
+	shapes [][]int64
+}
+
+func (m *cpuImageModel) Spec() runtime.ModelSpec { return m.spec }
+
+func (m *cpuImageModel) Infer(_ context.Context, req runtime.InferenceRequest) (*runtime.InferenceResult, error) {
+	m.shapes = append(m.shapes, req.ImageShape)
+	switch m.spec.Task {
+	case runtime.TaskFaceDetection:
+		return &runtime.InferenceResult{Outputs: map[string]interface{}{"faces": m.faces}}, nil
+	case runtime.TaskDeepfake:
+		sum := 0.0
+		for _, v := range req.ImageTensor {
+			sum += float64(v)
+		}
+		prob := sum / float64(len(req.ImageTensor))
+		return &runtime.InferenceResult{Outputs: map[string]interface{}{
+			"is_deepfake":      prob > 0.5,
+			"probability_fake": prob,
+			"confidence":       prob,
+		}}, nil
+	default:
+		return nil, runtime.ErrInferenceNotImplemented
+	}
+}
+
+func (m *cpuImageModel) Close() error { return nil }
+
+func newCPUVideoService(models ...*cpuImageModel) *AIInferenceService {
+	manager := &ModelManager{
+		models: make(map[runtime.TaskType]runtime.Model),
+		specs:  make(map[runtime.TaskType]runtime.ModelSpec),
+	}
+	for _, m := range models {
+		manager.models[m.spec.Task] = m
+		manager.specs[m.spec.Task] = m.spec
+	}
+	return &AIInferenceService{models: manager}
+}
+
+// centerSquareJPEG 黑底、中心 1/2 区域为白色的测试帧
+func centerSquareJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{A: 255}
+			if x >= width/4 && x < width*3/4 && y >= height/4 && y < height*3/4 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func TestAnalyzeVideoFrame_DeepfakeOnFaceCrop(t *testing.T) {
+	face := &cpuImageModel{
+		spec:  runtime.ModelSpec{Task: runtime.TaskFaceDetection, InputWidth: 64, InputHeight: 48},
+		faces: []vision.Box{{X1: 0.25, Y1: 0.25, X2: 0.75, Y2: 0.75, Score: 0.9}},
+	}
+	deepfake := &cpuImageModel{spec: runtime.ModelSpec{Task: runtime.TaskDeepfake, InputWidth: 32, InputHeight: 32}}
+	svc := newCPUVideoService(face, deepfake)
+
+	frame := VideoFrame{Data: centerSquareJPEG(t, 160, 120), Format: "jpeg"}
+	results, err := svc.AnalyzeVideoFrame(context.Background(), frame, []string{"face_detection", "synthesis_detection"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	faces := results[0].Payload.(*FaceDetectionResponse)
+	assert.Equal(t, videoTaskFaceDetection, results[0].Task)
+	assert.True(t, faces.Present)
+	assert.Equal(t, 1, faces.FaceCount)
+	assert.InDelta(t, 0.9, results[0].Confidence, 1e-9)
+
+	// 人脸检测结果在任务间复用；伪造模型的输入为外扩后的人脸裁剪
+	require.Len(t, face.shapes, 1)
+	assert.Equal(t, []int64{1, 3, 48, 64}, face.shapes[0])
+	require.Len(t, deepfake.shapes, 1)
+	assert.Equal(t, []int64{1, 3, 32, 32}, deepfake.shapes[0])
+
+	fake := results[1].Payload.(*DeepfakeDetectionResponse)
+	assert.Equal(t, videoTaskDeepfake, results[1].Task)
+	assert.Equal(t, 1, fake.FaceCount)
+	require.NotNil(t, fake.Face)
+	// 整帧白色占比 1/4，人脸裁剪（外扩 20%）约 1/2
+	assert.Greater(t, fake.Score, 0.4)
+	assert.Less(t, fake.Score, 0.7)
+}
+
+func TestAnalyzeVideoFrame_NoFaceAndMissingModel(t *testing.T) {
+	face := &cpuImageModel{spec: runtime.ModelSpec{Task: runtime.TaskFaceDetection}}
+	svc := newCPUVideoService(face)
+
+	frame := VideoFrame{Data: centerSquareJPEG(t, 32, 32), Format: "jpeg"}
+	results, err := svc.AnalyzeVideoFrame(context.Background(), frame, []string{"presence", "deepfake", "asr"})
+	require.Len(t, results, 2)
+	assert.False(t, results[0].Payload.(*FaceDetectionResponse).Present)
+	assert.Equal(t, []int64{1, 3, defaultFaceInputSize, defaultFaceInputSize}, face.shapes[0])
+	// 没有人脸时不调用伪造模型（即使未加载也不报错）
+	fake := results[1].Payload.(*DeepfakeDetectionResponse)
+	assert.Zero(t, fake.FaceCount)
+	assert.False(t, fake.IsDeepfake)
+	assert.EqualError(t, err, "unsupported video task: asr")
+
+	// 未加载人脸模型时伪造检测使用整帧；未加载伪造模型则报错
+	svc = newCPUVideoService(&cpuImageModel{spec: runtime.ModelSpec{Task: runtime.TaskDeepfake}})
+	results, err = svc.AnalyzeVideoFrame(context.Background(), frame, []string{"deepfake"})
+	require.NoError(t, err)
+	assert.Equal(t, -1, results[0].Payload.(*DeepfakeDetectionResponse).FaceCount)
+
+	results, err = newCPUVideoService().AnalyzeVideoFrame(context.Background(), frame, []string{"deepfake"})
+	assert.Empty(t, results)
+	assert.ErrorIs(t, err, runtime.ErrModelNotLoaded)
+
+	_, err = svc.AnalyzeVideoFrame(context.Background(), VideoFrame{Data: []byte{1, 2, 3}, Format: "rgb24", Width: 2, Height: 2}, nil)
+	assert.Error(t, err)
+}
+
+func TestVideoStreamSession_FiltersAudioTasks(t *testing.T) {
+	face := &cpuImageModel{
+		spec:  runtime.ModelSpec{Task: runtime.TaskFaceDetection, InputWidth: 16, InputHeight: 16},
+		faces: []vision.Box{{X1: 0.1, Y1: 0.1, X2: 0.5, Y2: 0.5, Score: 0.8}},
+	}
+	svc := newCPUVideoService(face)
+	session := NewVideoStreamSession(svc, "peer_1", []string{"speech_recognition", "face_detection", "face"}, "rgb24", 2, 2)
+
+	rgb := bytes.Repeat([]byte{10, 20, 30}, 4)
+	results, err := session.Append(context.Background(), &pb.VideoChunk{Data: rgb, Sequence: 7})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "peer_1", results[0].StreamId)
+	assert.Equal(t, int32(7), results[0].Sequence)
+	assert.Equal(t, videoTaskFaceDetection, results[0].ResultType)
+
+	var payload FaceDetectionResponse
+	require.NoError(t, json.Unmarshal([]byte(results[0].ResultData), &payload))
+	assert.Equal(t, 1, payload.FaceCount)
+
+	// 只有音频任务的流不做视频推理
+	results, err = session.Append(context.Background(), &pb.VideoChunk{Data: rgb, Tasks: []string{"asr"}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Len(t, face.shapes, 1)
+}
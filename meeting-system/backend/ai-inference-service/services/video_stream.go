@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	pb "meeting-system/shared/grpc"
+	"meeting-system/shared/logger"
+)
+
+// VideoStreamSession runs video tasks on each frame of a gRPC video stream.
+// 上游（media-service）已按房间限流抽帧，这里每个 chunk 即一帧，逐帧推理。
+type VideoStreamSession struct {
+	streamID  string
+	tasks     []string
+	format    string
+	width     int
+	height    int
+	aiService *AIInferenceService
+}
+
+// NewVideoStreamSession initializes a video stream session.
+func NewVideoStreamSession(ai *AIInferenceService, streamID string, tasks []string, format string, width, height int) *VideoStreamSession {
+	if format == "" {
+		format = "jpeg"
+	}
+	return &VideoStreamSession{
+		streamID:  streamID,
+		tasks:     tasks,
+		format:    format,
+		width:     width,
+		height:    height,
+		aiService: ai,
+	}
+}
+
+// Append runs the session's video tasks on a frame and returns stream results.
+// 单帧解码或推理失败只记录日志并跳过，不中断整个流。
+func (s *VideoStreamSession) Append(ctx context.Context, chunk *pb.VideoChunk) ([]*pb.AIStreamResult, error) {
+	if chunk == nil {
+		return nil, fmt.Errorf("video chunk is nil")
+	}
+	if s == nil {
+		return nil, fmt.Errorf("stream session not initialized")
+	}
+	if s.aiService == nil {
+		return nil, fmt.Errorf("ai service not available")
+	}
+
+	if chunk.Format != "" {
+		s.format = chunk.Format
+	}
+	if chunk.Width > 0 {
+		s.width = int(chunk.Width)
+	}
+	if chunk.Height > 0 {
+		s.height = int(chunk.Height)
+	}
+	if len(chunk.Tasks) > 0 {
+		s.tasks = chunk.Tasks
+	}
+	if len(chunk.Data) == 0 {
+		return nil, nil
+	}
+
+	streamID := s.streamID
+	if streamID == "" {
+		streamID = "stream"
+	}
+
+	// 流的任务列表可能同时包含音频任务，只执行视频任务
+	tasks := make([]string, 0, len(s.tasks))
+	seen := make(map[string]struct{})
+	for _, task := range s.tasks {
+		name := normalizeVideoTask(task)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		tasks = append(tasks, name)
+	}
+	if len(s.tasks) > 0 && len(tasks) == 0 {
+		return nil, nil
+	}
+
+	taskResults, err := s.aiService.AnalyzeVideoFrame(ctx, VideoFrame{
+		Data:   chunk.Data,
+		Format: s.format,
+		Width:  s.width,
+		Height: s.height,
+	}, tasks)
+	if err != nil {
+		logger.Warn("Video frame inference failed",
+			logger.String("stream_id", streamID),
+			logger.Int("sequence", int(chunk.Sequence)),
+			logger.Err(err))
+	}
+
+	results := make([]*pb.AIStreamResult, 0, len(taskResults))
+	for _, r := range taskResults {
+		results = append(results, streamResult(streamID, chunk.Sequence, r.Task, r.Payload, r.Confidence, chunk.IsFinal))
+	}
+	return results, nil
+}
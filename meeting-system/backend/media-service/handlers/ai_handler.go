@@ -56,11 +56,11 @@ func (h *AIHandler) GetStreamStatus(c *gin.Context) {
 		"stream_id":      stream.StreamID,
 		"user_id":        stream.UserID,
 		"room_id":        stream.RoomID,
-		"is_active":      stream.IsActive,
+		"is_active":      stream.IsActive.Load(),
 		"last_processed": stream.LastProcessed,
 		"ai_tasks":       stream.AITasks,
 		"audio_buffer":   stream.AudioBuffer.Count(),
-		"video_buffer":   stream.VideoBuffer.Count(),
+		"video_frames":   stream.VideoSequence,
 	})
 }
 
@@ -74,7 +74,7 @@ func (h *AIHandler) ListActiveStreams(c *gin.Context) {
 			"stream_id":      stream.StreamID,
 			"user_id":        stream.UserID,
 			"room_id":        stream.RoomID,
-			"is_active":      stream.IsActive,
+			"is_active":      stream.IsActive.Load(),
 			"last_processed": stream.LastProcessed,
 			"ai_tasks":       stream.AITasks,
 		})
@@ -115,7 +115,7 @@ func (h *AIHandler) GetAIProcessingStats(c *gin.Context) {
 	}
 
 	for _, stream := range streams {
-		if stream.IsActive {
+		if stream.IsActive.Load() {
 			stats["active_streams"] = stats["active_streams"].(int) + 1
 		}
 		stats["total_tasks"] = stats["total_tasks"].(int) + len(stream.AITasks)
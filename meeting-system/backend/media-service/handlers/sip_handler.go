@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"meeting-system/media-service/services"
+	"meeting-system/media-service/sip"
+	"meeting-system/shared/middleware"
+)
+
+// SIPHandler SIP 网关处理器（主持人外呼、查看/挂断电话参与者）
+type SIPHandler struct {
+	gateway *services.SIPGateway
+}
+
+// NewSIPHandler 创建SIP网关处理器
+func NewSIPHandler(gateway *services.SIPGateway) *SIPHandler {
+	return &SIPHandler{
+		gateway: gateway,
+	}
+}
+
+// DialOut 主持人从会议外呼电话号码，接听后对方直接入会（主持人身份取自 JWT）
+func (h *SIPHandler) DialOut(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req struct {
+		MeetingID uint   `json:"meeting_id" binding:"required"`
+		Number    string `json:"number" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	call, err := h.gateway.DialOut(c.Request.Context(), req.MeetingID, userID, req.Number)
+	if err != nil {
+		var statusErr *sip.StatusError
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, services.ErrSIPInvalidNumber):
+			status = http.StatusBadRequest
+		case errors.Is(err, services.ErrSIPNotMeetingHost):
+			status = http.StatusForbidden
+		case errors.Is(err, services.ErrSIPMeetingNotJoinable):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrSIPGatewayNotStarted):
+			status = http.StatusServiceUnavailable
+		case errors.As(err, &statusErr):
+			// 被叫拒接/忙/无应答
+			c.JSON(http.StatusConflict, gin.H{
+				"error":      err.Error(),
+				"sip_status": statusErr.Code,
+				"sip_reason": statusErr.Reason,
+			})
+			return
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, call)
+}
+
+// ListCalls 当前已入会的电话
+func (h *SIPHandler) ListCalls(c *gin.Context) {
+	calls := h.gateway.Calls()
+	c.JSON(http.StatusOK, gin.H{
+		"calls": calls,
+		"total": len(calls),
+	})
+}
+
+// HangupCall 主持人挂断电话参与者
+func (h *SIPHandler) HangupCall(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	callID := c.Param("callId")
+	if err := h.gateway.Hangup(callID, userID); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrSIPCallNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrSIPNotMeetingHost):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"call_id": callID,
+		"status":  "hangup",
+	})
+}
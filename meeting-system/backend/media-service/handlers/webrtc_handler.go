@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pion/webrtc/v3"
 	"meeting-system/media-service/services"
 	"meeting-system/shared/logger"
+	"meeting-system/shared/middleware"
 )
 
 // WebRTCHandler WebRTC处理器
@@ -47,6 +50,18 @@ func (h *WebRTCHandler) HandleOfferAndCreateAnswer(c *gin.Context) {
 
 	// 创建Answer（SFU响应客户端的Offer）
 	answer, peerID, err := h.webrtcService.CreateAnswer(request.RoomID, request.UserID, &offer)
+	if errors.Is(err, services.ErrParticipantNotAdmitted) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Participant is not admitted to the meeting",
+		})
+		return
+	}
+	if errors.Is(err, services.ErrNoCodecAllowedByPolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Offer has no codec allowed by meeting codec policy",
+		})
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to create answer: " + err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -216,6 +231,47 @@ func (h *WebRTCHandler) HandlePeerAnswer(c *gin.Context) {
 	})
 }
 
+// HandleICERestart 处理客户端在已有 Peer 上发起的 ICE restart Offer（网络切换后恢复，不离开房间）
+func (h *WebRTCHandler) HandleICERestart(c *gin.Context) {
+	peerID := c.Param("peerId")
+	if peerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "peer_id is required"})
+		return
+	}
+
+	var request struct {
+		Offer struct {
+			Type string `json:"type" binding:"required"`
+			SDP  string `json:"sdp" binding:"required"`
+		} `json:"offer" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	offer := webrtc.SessionDescription{
+		Type: webrtc.NewSDPType(request.Offer.Type),
+		SDP:  request.Offer.SDP,
+	}
+
+	answer, err := h.webrtcService.RestartICE(peerID, &offer)
+	if err != nil {
+		logger.Error("Failed to handle ICE restart: " + err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restart ICE"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"peer_id": peerID,
+		"answer": gin.H{
+			"type": answer.Type.String(),
+			"sdp":  answer.SDP,
+		},
+	})
+}
+
 // JoinRoom 加入房间
 func (h *WebRTCHandler) JoinRoom(c *gin.Context) {
 	roomID := c.Param("roomId")
@@ -239,6 +295,12 @@ func (h *WebRTCHandler) JoinRoom(c *gin.Context) {
 
 	// 加入房间
 	room, err := h.webrtcService.JoinRoom(roomID, request.UserID)
+	if errors.Is(err, services.ErrParticipantNotAdmitted) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Participant is not admitted to the meeting",
+		})
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to join room: " + err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -345,15 +407,123 @@ func (h *WebRTCHandler) GetPeerStatus(c *gin.Context) {
 		return
 	}
 
-	// 这里应该调用webrtcService的GetPeerStatus方法
-	// 为了简化，我们返回一个模拟响应
+	peer, err := h.webrtcService.GetPeer(peerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Peer not found",
+		})
+		return
+	}
+
+	status := gin.H{
+		"peer_id":       peer.ID,
+		"user_id":       peer.UserID,
+		"room_id":       peer.RoomID,
+		"media_type":    peer.MediaType,
+		"status":        peer.Status,
+		"created_at":    peer.CreatedAt,
+		"last_activity": peer.LastActivity,
+	}
+	if peer.Connection != nil {
+		status["connection_state"] = peer.Connection.ConnectionState().String()
+		status["ice_connection_state"] = peer.Connection.ICEConnectionState().String()
+	}
+	// 丢包/抖动/RTT/NACK/PLI/码率等媒体质量统计
+	if stats, err := h.webrtcService.GetPeerStats(peerID); err == nil {
+		status["stats"] = stats
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetPeerStats 获取对等连接的 RTP/RTCP 媒体质量统计
+func (h *WebRTCHandler) GetPeerStats(c *gin.Context) {
+	peerID := c.Param("peerId")
+	if peerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "peer_id is required",
+		})
+		return
+	}
+
+	stats, err := h.webrtcService.GetPeerStats(peerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Peer stats not available",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetPeerStatsHistory 获取对等连接的统计历史（Peer 离开后在保留期内仍可查询，since 为 RFC3339 时间）
+func (h *WebRTCHandler) GetPeerStatsHistory(c *gin.Context) {
+	peerID := c.Param("peerId")
+	if peerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "peer_id is required",
+		})
+		return
+	}
+
+	since, ok := statsHistorySince(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.webrtcService.GetPeerStatsHistory(peerID, since)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Stats history not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetRoomStatsHistory 获取房间内所有 Peer（含已离开的）的统计历史
+func (h *WebRTCHandler) GetRoomStatsHistory(c *gin.Context) {
+	roomID := c.Param("roomId")
+	if roomID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "room_id is required",
+		})
+		return
+	}
+
+	since, ok := statsHistorySince(c)
+	if !ok {
+		return
+	}
+
+	peers := h.webrtcService.GetRoomStatsHistory(roomID, since)
 	c.JSON(http.StatusOK, gin.H{
-		"peer_id": peerID,
-		"status":  "connected",
-		"message": "Peer status retrieved successfully",
+		"room_id": roomID,
+		"peers":   peers,
+		"count":   len(peers),
 	})
 }
 
+func statsHistorySince(c *gin.Context) (time.Time, bool) {
+	raw := c.Query("since")
+	if raw == "" {
+		return time.Time{}, true
+	}
+	since, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid since",
+			"details": "since must be an RFC3339 timestamp",
+		})
+		return time.Time{}, false
+	}
+	return since, true
+}
+
 // UpdatePeerMedia 更新对等连接媒体设置
 func (h *WebRTCHandler) UpdatePeerMedia(c *gin.Context) {
 	peerID := c.Param("peerId")
@@ -432,5 +602,193 @@ func (h *WebRTCHandler) GetRoomStats(c *gin.Context) {
 		}
 	}
 
+	// 转发轨道（含 VP9/AV1 SVC 可用分层与各订阅者当前分层）
+	if tracks, err := h.webrtcService.GetRoomTrackStats(roomID); err == nil {
+		stats["tracks"] = tracks
+		// 屏幕共享按轨道标记统计（Peer 的媒体类型只在以 screen 加入时准确）
+		screenShares := 0
+		for _, t := range tracks {
+			if t.ScreenShare {
+				screenShares++
+			}
+		}
+		if screenShares > stats["screen_shares"].(int) {
+			stats["screen_shares"] = screenShares
+		}
+	}
+
+	// 各 Peer 的丢包/抖动/RTT/码率
+	if peerStats, err := h.webrtcService.GetRoomPeerStats(roomID); err == nil {
+		stats["peer_stats"] = peerStats
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
+
+// SetVideoConstraints 订阅者上报渲染尺寸/期望分层，SFU 据此对 SVC 轨道丢弃多余的空间/时间层
+func (h *WebRTCHandler) SetVideoConstraints(c *gin.Context) {
+	peerID := c.Param("peerId")
+	if peerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "peer_id is required",
+		})
+		return
+	}
+
+	var constraints services.VideoLayerConstraints
+	if err := c.ShouldBindJSON(&constraints); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	updated, err := h.webrtcService.SetSubscriberVideoConstraints(peerID, constraints)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"peer_id":        peerID,
+		"updated_tracks": updated,
+	})
+}
+
+// SubscribeRoomMix 订阅房间 N-1 混音（MCU 模式，替代逐个订阅音频轨道）
+func (h *WebRTCHandler) SubscribeRoomMix(c *gin.Context) {
+	peerID := c.Param("peerId")
+	if peerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "peer_id is required",
+		})
+		return
+	}
+
+	if !h.authorizePeerRoomMix(c, peerID) {
+		return
+	}
+
+	if err := h.webrtcService.SubscribePeerToRoomMix(peerID); err != nil {
+		logger.Error("Failed to subscribe room mix: " + err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrOpusEncoderUnavailable) {
+			status = http.StatusNotImplemented
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subscribed to room mix",
+		"peer_id": peerID,
+	})
+}
+
+// UnsubscribeRoomMix 取消订阅房间混音
+func (h *WebRTCHandler) UnsubscribeRoomMix(c *gin.Context) {
+	peerID := c.Param("peerId")
+	if peerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "peer_id is required",
+		})
+		return
+	}
+
+	if !h.authorizePeerRoomMix(c, peerID) {
+		return
+	}
+
+	if err := h.webrtcService.UnsubscribePeerFromRoomMix(peerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Unsubscribed from room mix",
+		"peer_id": peerID,
+	})
+}
+
+// SetRoomMixGain 设置参与者在房间混音中的增益（仅会议主持人）
+func (h *WebRTCHandler) SetRoomMixGain(c *gin.Context) {
+	roomID := c.Param("roomId")
+	if roomID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "room_id is required",
+		})
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	if err := h.webrtcService.AuthorizeRoomMixGain(roomID, userID); err != nil {
+		respondRoomMixAuthError(c, err)
+		return
+	}
+
+	var req struct {
+		PeerID string   `json:"peer_id" binding:"required"`
+		Gain   *float64 `json:"gain" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	gain, err := h.webrtcService.SetRoomMixGain(roomID, req.PeerID, *req.Gain)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"room_id": roomID,
+		"peer_id": req.PeerID,
+		"gain":    gain,
+	})
+}
+
+// authorizePeerRoomMix 混音订阅只能由 Peer 本人（JWT 用户）或会议主持人切换；未通过时已写入响应
+func (h *WebRTCHandler) authorizePeerRoomMix(c *gin.Context, peerID string) bool {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return false
+	}
+	if err := h.webrtcService.AuthorizePeerRoomMix(peerID, userID); err != nil {
+		respondRoomMixAuthError(c, err)
+		return false
+	}
+	return true
+}
+
+func respondRoomMixAuthError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrRoomMixForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrPeerNotFound):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
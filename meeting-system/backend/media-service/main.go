@@ -143,6 +143,12 @@ func main() {
 
 	// 初始化媒体处理器
 	mediaProcessor := services.NewMediaProcessor(cfg, aiClient, ffmpegService)
+	if queueManager != nil {
+		// 实时 AI 结果经事件总线交给信令服务推送给会议参与者
+		if pubsub := queueManager.GetKafkaEventBus(); pubsub != nil {
+			mediaProcessor.SetResultPublisher(pubsub)
+		}
+	}
 	logger.Info("Media processor created")
 
 	// 初始化WebRTC服务
@@ -152,13 +158,33 @@ func main() {
 		panic(err)
 	}
 	logger.Info("WebRTC service initialized successfully")
+	if !services.OpusEncoderAvailable {
+		logger.Error(`Opus encoder not built: room mix subscriptions will be rejected. Build with -tags "opus nolibopusfile" (requires CGO and libopus)`)
+	}
+	if queueManager != nil {
+		registerSignalingEvents(queueManager, webrtcService)
+		registerMeetingSettingsEvents(queueManager, webrtcService)
+	}
 
 	// 初始化录制服务
 	recordingService := services.NewRecordingService(cfg, mediaService, ffmpegService, signalingClient)
 	logger.Info("Recording service created (initialization skipped)")
 
+	// 初始化SIP网关（电话拨入/外呼，可选）
+	var sipGateway *services.SIPGateway
+	if cfg.SIP.Enabled {
+		sipGateway = services.NewSIPGateway(cfg.SIP, webrtcService)
+		if err := sipGateway.Start(); err != nil {
+			logger.Error("Failed to start SIP gateway: " + err.Error())
+			sipGateway = nil
+		} else {
+			defer sipGateway.Stop()
+			fmt.Println("✅ SIP gateway started")
+		}
+	}
+
 	// 设置路由
-	router := setupRouter(mediaService, webrtcService, ffmpegService, recordingService, mediaProcessor, aiClient)
+	router := setupRouter(mediaService, webrtcService, ffmpegService, recordingService, mediaProcessor, aiClient, sipGateway)
 
 	// 注册HTTP服务实例
 	metadata := map[string]string{
@@ -229,6 +255,7 @@ func setupRouter(
 	recordingService *services.RecordingService,
 	mediaProcessor *services.MediaProcessor,
 	aiClient *services.AIClient,
+	sipGateway *services.SIPGateway,
 ) *gin.Engine {
 	// 设置Gin模式
 	if config.GetConfig().Server.Mode == "release" {
@@ -293,15 +320,24 @@ func setupRouter(
 			webrtc.POST("/room/:roomId/leave", handlers.NewWebRTCHandler(webrtcService).LeaveRoom)
 			webrtc.GET("/room/:roomId/peers", handlers.NewWebRTCHandler(webrtcService).GetRoomPeers)
 			webrtc.GET("/room/:roomId/stats", handlers.NewWebRTCHandler(webrtcService).GetRoomStats)
+			webrtc.GET("/room/:roomId/stats/history", handlers.NewWebRTCHandler(webrtcService).GetRoomStatsHistory)
+			// 混音控制需要 JWT：增益仅限会议主持人，订阅仅限 Peer 本人或主持人
+			webrtc.PUT("/room/:roomId/mix/gain", middleware.JWTAuth(), handlers.NewWebRTCHandler(webrtcService).SetRoomMixGain)
 
 			// 媒体控制
 			webrtc.POST("/peer/:peerId/media", handlers.NewWebRTCHandler(webrtcService).UpdatePeerMedia)
 			webrtc.GET("/peer/:peerId/status", handlers.NewWebRTCHandler(webrtcService).GetPeerStatus)
+			webrtc.GET("/peer/:peerId/stats", handlers.NewWebRTCHandler(webrtcService).GetPeerStats)
+			webrtc.GET("/peer/:peerId/stats/history", handlers.NewWebRTCHandler(webrtcService).GetPeerStatsHistory)
+			webrtc.POST("/peer/:peerId/video-constraints", handlers.NewWebRTCHandler(webrtcService).SetVideoConstraints)
+			webrtc.POST("/peer/:peerId/room-mix", middleware.JWTAuth(), handlers.NewWebRTCHandler(webrtcService).SubscribeRoomMix)
+			webrtc.DELETE("/peer/:peerId/room-mix", middleware.JWTAuth(), handlers.NewWebRTCHandler(webrtcService).UnsubscribeRoomMix)
 
 			// SFU renegotiation / trickle ICE
 			webrtc.GET("/peer/:peerId/ice-candidates", handlers.NewWebRTCHandler(webrtcService).GetICECandidates)
 			webrtc.GET("/peer/:peerId/offer", handlers.NewWebRTCHandler(webrtcService).GetPendingOffer)
 			webrtc.POST("/peer/:peerId/answer", handlers.NewWebRTCHandler(webrtcService).HandlePeerAnswer)
+			webrtc.POST("/peer/:peerId/ice-restart", handlers.NewWebRTCHandler(webrtcService).HandleICERestart)
 		}
 
 		// SFU 架构：FFmpeg 转码相关路由已禁用
@@ -360,6 +396,18 @@ func setupRouter(
 			ai.GET("/stats", aiHandler.GetAIProcessingStats)
 		}
 
+		// SIP网关（仅在 sip.enabled 时注册）；外呼会产生话费，主持人身份必须来自 JWT
+		if sipGateway != nil {
+			sipHandler := handlers.NewSIPHandler(sipGateway)
+			sipGroup := api.Group("/sip")
+			sipGroup.Use(middleware.JWTAuth())
+			{
+				sipGroup.POST("/dial-out", sipHandler.DialOut)
+				sipGroup.GET("/calls", sipHandler.ListCalls)
+				sipGroup.DELETE("/calls/:callId", sipHandler.HangupCall)
+			}
+		}
+
 		// SFU 架构：滤镜和美颜相关路由已完全禁用
 		// 原因：SFU 架构要求所有滤镜、美颜等视觉效果在客户端处理
 		// 服务端仅负责媒体流的选择性转发
@@ -580,3 +628,97 @@ func registerMediaTaskHandlers(qm *queue.QueueManager) {
 
 	logger.Info("All media task handlers registered successfully")
 }
+
+// registerSignalingEvents 订阅信令服务的事件：服务端 AI Live 开关、主持人媒体管控与移出、屏幕共享；
+// 同时设置事件发布器，ICE restart 通知经信令服务推送给客户端
+func registerSignalingEvents(qm *queue.QueueManager, webrtcService *services.WebRTCService) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+	webrtcService.SetEventPublisher(pubsub)
+
+	pubsub.Subscribe(queue.ChannelSignalingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		switch msg.Type {
+		case queue.EventAILiveEnabled:
+			meetingID, ok := msg.Payload["meeting_id"].(float64)
+			if !ok || meetingID <= 0 {
+				return fmt.Errorf("ai live event missing meeting_id")
+			}
+			webrtcService.EnableMeetingAILive(uint(meetingID))
+		case queue.EventAILiveDisabled:
+			// 音频轨道默认即运行 AI，关闭服务端 AI Live 只停止信令侧的结果广播
+			logger.Info(fmt.Sprintf("Server AI Live disabled for meeting %v", msg.Payload["meeting_id"]))
+		case queue.EventMediaModeration:
+			meetingID, _ := msg.Payload["meeting_id"].(float64)
+			userID, _ := msg.Payload["user_id"].(float64)
+			mediaType, _ := msg.Payload["media_type"].(string)
+			muted, _ := msg.Payload["muted"].(bool)
+			if meetingID <= 0 || userID <= 0 {
+				return fmt.Errorf("media moderation event missing meeting_id/user_id")
+			}
+			if _, err := webrtcService.SetMeetingMediaModeration(uint(meetingID), uint(userID), mediaType, muted); err != nil {
+				// 会议房间不在本节点
+				logger.Debug(fmt.Sprintf("Media moderation not applied: %v", err))
+			}
+		case queue.EventScreenShareStarted, queue.EventScreenShareStopped:
+			meetingID, _ := msg.Payload["meeting_id"].(float64)
+			userID, _ := msg.Payload["user_id"].(float64)
+			exclusive, _ := msg.Payload["exclusive"].(bool)
+			contentHint, _ := msg.Payload["content_hint"].(string)
+			if meetingID <= 0 || userID <= 0 {
+				return fmt.Errorf("screen share event missing meeting_id/user_id")
+			}
+			sharing := msg.Type == queue.EventScreenShareStarted
+			if _, err := webrtcService.SetMeetingScreenShare(uint(meetingID), uint(userID), sharing, exclusive, contentHint); err != nil {
+				logger.Debug(fmt.Sprintf("Screen share event not applied: %v", err))
+			}
+		case queue.EventBreakoutMoved:
+			meetingID, _ := msg.Payload["meeting_id"].(float64)
+			fromMeetingID, _ := msg.Payload["from_meeting_id"].(float64)
+			userID, _ := msg.Payload["user_id"].(float64)
+			if meetingID <= 0 || fromMeetingID <= 0 || userID <= 0 {
+				return fmt.Errorf("breakout move event missing meeting_id/from_meeting_id/user_id")
+			}
+			if _, err := webrtcService.MoveMeetingPeers(uint(fromMeetingID), uint(meetingID), uint(userID)); err != nil {
+				// 会议房间不在本节点
+				logger.Debug(fmt.Sprintf("Breakout move not applied: %v", err))
+			}
+		case queue.EventParticipantRemoved:
+			meetingID, _ := msg.Payload["meeting_id"].(float64)
+			userID, _ := msg.Payload["user_id"].(float64)
+			if meetingID <= 0 || userID <= 0 {
+				return fmt.Errorf("participant removed event missing meeting_id/user_id")
+			}
+			if _, err := webrtcService.RemoveMeetingUserPeers(uint(meetingID), uint(userID)); err != nil {
+				// 会议房间不在本节点
+				logger.Debug(fmt.Sprintf("Participant removal not applied: %v", err))
+			}
+		}
+		return nil
+	})
+
+	logger.Info("Signaling event handler registered")
+}
+
+// registerMeetingSettingsEvents 订阅会议设置变更，清除该会议房间缓存的编解码策略
+func registerMeetingSettingsEvents(qm *queue.QueueManager, webrtcService *services.WebRTCService) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+
+	pubsub.Subscribe(queue.ChannelMeetingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		if msg.Type != queue.EventMeetingSettingsUpdated {
+			return nil
+		}
+		meetingID, ok := msg.Payload["meeting_id"].(float64)
+		if !ok || meetingID <= 0 {
+			return fmt.Errorf("meeting settings event missing meeting_id")
+		}
+		webrtcService.ClearMeetingCodecPolicy(uint(meetingID))
+		return nil
+	})
+
+	logger.Info("Meeting settings event handler registered")
+}
@@ -24,6 +24,7 @@ type AIGRPCClient struct {
 
 	// 流式连接管理
 	activeStreams map[string]*AudioStreamClient
+	videoStreams  map[string]*VideoStreamClient
 	streamMutex   sync.RWMutex
 }
 
@@ -37,6 +38,16 @@ type AudioStreamClient struct {
 	Mutex      sync.Mutex
 }
 
+// VideoStreamClient 视频流客户端
+type VideoStreamClient struct {
+	StreamID   string
+	Stream     pb.AIService_StreamVideoProcessingClient
+	ResultChan chan *pb.AIStreamResult
+	ErrorChan  chan error
+	Done       chan struct{}
+	Mutex      sync.Mutex
+}
+
 // NewAIGRPCClient 创建 AI gRPC 客户端
 func NewAIGRPCClient(aiServiceAddr string) (*AIGRPCClient, error) {
 	// 连接到 AI 服务
@@ -56,6 +67,7 @@ func NewAIGRPCClient(aiServiceAddr string) (*AIGRPCClient, error) {
 		conn:          conn,
 		client:        client,
 		activeStreams: make(map[string]*AudioStreamClient),
+		videoStreams:  make(map[string]*VideoStreamClient),
 	}, nil
 }
 
@@ -66,6 +78,9 @@ func (c *AIGRPCClient) Close() error {
 	for _, stream := range c.activeStreams {
 		close(stream.Done)
 	}
+	for _, stream := range c.videoStreams {
+		close(stream.Done)
+	}
 	c.streamMutex.Unlock()
 
 	return c.conn.Close()
@@ -223,25 +238,133 @@ func (c *AIGRPCClient) CloseAudioStream(streamID string) error {
 	return err
 }
 
+// StartVideoStream 启动视频流处理
+func (c *AIGRPCClient) StartVideoStream(ctx context.Context, streamID string, tasks []string) (*VideoStreamClient, error) {
+	// 创建 span
+	span, ctx := opentracing.StartSpanFromContext(ctx, "AIGRPCClient.StartVideoStream")
+	defer span.Finish()
+
+	ext.Component.Set(span, "media-service")
+	span.SetTag("stream_id", streamID)
+	span.SetTag("tasks", tasks)
+
+	stream, err := c.client.StreamVideoProcessing(ctx)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("error.message", err.Error())
+		return nil, fmt.Errorf("failed to start video stream: %w", err)
+	}
+
+	streamClient := &VideoStreamClient{
+		StreamID:   streamID,
+		Stream:     stream,
+		ResultChan: make(chan *pb.AIStreamResult, 100),
+		ErrorChan:  make(chan error, 10),
+		Done:       make(chan struct{}),
+	}
+
+	go c.receiveStreamResults(streamID, stream.Recv, streamClient.ResultChan, streamClient.ErrorChan, streamClient.Done)
+
+	c.streamMutex.Lock()
+	c.videoStreams[streamID] = streamClient
+	c.streamMutex.Unlock()
+
+	logger.Info("Started video stream",
+		logger.String("stream_id", streamID),
+		logger.Int("tasks", len(tasks)))
+
+	return streamClient, nil
+}
+
+// SendVideoFrame 发送一帧抽样后的视频图像
+func (c *AIGRPCClient) SendVideoFrame(streamID string, sequence int32, frame *VideoData, tasks []string, isFinal bool) error {
+	c.streamMutex.RLock()
+	streamClient, exists := c.videoStreams[streamID]
+	c.streamMutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("video stream not found: %s", streamID)
+	}
+
+	chunk := &pb.VideoChunk{
+		Data:     frame.Data,
+		Sequence: sequence,
+		StreamId: streamID,
+		Format:   frame.Format,
+		Width:    int32(frame.Width),
+		Height:   int32(frame.Height),
+		Fps:      int32(frame.FPS),
+		Tasks:    tasks,
+		IsFinal:  isFinal,
+	}
+
+	streamClient.Mutex.Lock()
+	err := streamClient.Stream.Send(chunk)
+	streamClient.Mutex.Unlock()
+
+	if err != nil {
+		logger.Error("Failed to send video frame",
+			logger.String("stream_id", streamID),
+			logger.Err(err))
+		return err
+	}
+
+	logger.Debug("Sent video frame",
+		logger.String("stream_id", streamID),
+		logger.Int32("sequence", sequence),
+		logger.Int("size", len(frame.Data)))
+
+	return nil
+}
+
+// CloseVideoStream 关闭视频流
+func (c *AIGRPCClient) CloseVideoStream(streamID string) error {
+	c.streamMutex.Lock()
+	streamClient, exists := c.videoStreams[streamID]
+	if exists {
+		delete(c.videoStreams, streamID)
+	}
+	c.streamMutex.Unlock()
+
+	if !exists {
+		return fmt.Errorf("video stream not found: %s", streamID)
+	}
+
+	streamClient.Mutex.Lock()
+	err := streamClient.Stream.CloseSend()
+	streamClient.Mutex.Unlock()
+
+	close(streamClient.Done)
+
+	logger.Info("Closed video stream", logger.String("stream_id", streamID))
+
+	return err
+}
+
 // receiveResults 接收流式结果
 func (c *AIGRPCClient) receiveResults(streamClient *AudioStreamClient) {
+	c.receiveStreamResults(streamClient.StreamID, streamClient.Stream.Recv, streamClient.ResultChan, streamClient.ErrorChan, streamClient.Done)
+}
+
+// receiveStreamResults 音频/视频流共用的结果接收循环
+func (c *AIGRPCClient) receiveStreamResults(streamID string, recv func() (*pb.AIStreamResult, error), resultChan chan<- *pb.AIStreamResult, errorChan chan<- error, done <-chan struct{}) {
 	for {
 		select {
-		case <-streamClient.Done:
+		case <-done:
 			return
 		default:
-			result, err := streamClient.Stream.Recv()
+			result, err := recv()
 			if err == io.EOF {
-				logger.Info("Stream ended", logger.String("stream_id", streamClient.StreamID))
+				logger.Info("Stream ended", logger.String("stream_id", streamID))
 				return
 			}
 			if err != nil {
 				logger.Error("Failed to receive result",
-					logger.String("stream_id", streamClient.StreamID),
+					logger.String("stream_id", streamID),
 					logger.Err(err))
 				select {
-				case streamClient.ErrorChan <- err:
-				case <-streamClient.Done:
+				case errorChan <- err:
+				case <-done:
 					return
 				}
 				return
@@ -249,12 +372,12 @@ func (c *AIGRPCClient) receiveResults(streamClient *AudioStreamClient) {
 
 			// 发送结果到通道
 			select {
-			case streamClient.ResultChan <- result:
+			case resultChan <- result:
 				logger.Debug("Received AI result",
-					logger.String("stream_id", streamClient.StreamID),
+					logger.String("stream_id", streamID),
 					logger.String("result_type", result.ResultType),
 					logger.Int32("sequence", result.Sequence))
-			case <-streamClient.Done:
+			case <-done:
 				return
 			}
 		}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/logger"
+)
+
+// aiLiveAudioTasks 服务端 AI Live 对音频轨道必须运行的任务（字幕 + 情绪 + 合成检测）
+var aiLiveAudioTasks = []string{"speech_recognition", "emotion_detection", "synthesis_detection"}
+
+// EnableMeetingAILive 服务端 AI Live 开启时由信令服务触发：确保会议内每条已发布的音频轨道都注册了 AI 流。
+// 之后发布的音频轨道在 handleTrack 中按默认任务注册；返回新注册的流数量。
+func (s *WebRTCService) EnableMeetingAILive(meetingID uint) int {
+	if s.mediaProcessor == nil || meetingID == 0 {
+		return 0
+	}
+
+	s.roomsMux.RLock()
+	rooms := make([]*Room, 0, len(s.rooms))
+	for _, room := range s.rooms {
+		rooms = append(rooms, room)
+	}
+	s.roomsMux.RUnlock()
+
+	registered := 0
+	for _, room := range rooms {
+		if s.roomMeetingID(room.ID) != meetingID {
+			continue
+		}
+		registered += s.ensureRoomAudioAI(room, meetingID)
+	}
+
+	logger.Info(fmt.Sprintf("Server AI Live enabled for meeting %d (%d audio streams registered)", meetingID, registered))
+	return registered
+}
+
+// ensureRoomAudioAI 为房间内尚未进入 AI 处理的音频轨道注册 AI 流；转发循环会把 RTP 分发给新注册的流
+func (s *WebRTCService) ensureRoomAudioAI(room *Room, meetingID uint) int {
+	type audioTrack struct {
+		senderPeer string
+		trackID    string
+	}
+
+	room.TracksMux.RLock()
+	tracks := make([]audioTrack, 0, len(room.Tracks))
+	for _, ft := range room.Tracks {
+		if ft == nil || ft.RemoteTrack == nil || ft.RemoteTrack.Kind() != webrtc.RTPCodecTypeAudio {
+			continue
+		}
+		tracks = append(tracks, audioTrack{senderPeer: ft.SenderPeer, trackID: ft.RemoteTrack.ID()})
+	}
+	room.TracksMux.RUnlock()
+
+	registered := 0
+	for _, track := range tracks {
+		streamID := fmt.Sprintf("%s_%s", track.senderPeer, track.trackID)
+		if _, exists := s.mediaProcessor.GetStreamStatus(streamID); exists {
+			continue
+		}
+
+		s.peersMux.RLock()
+		peer := s.peers[track.senderPeer]
+		s.peersMux.RUnlock()
+		if peer == nil {
+			continue
+		}
+
+		if err := s.mediaProcessor.RegisterStream(streamID, peer.UserID, room.ID, nil, nil, aiLiveAudioTasks); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to register AI Live stream %s: %v", streamID, err))
+			continue
+		}
+		s.mediaProcessor.SetStreamSource(streamID, meetingID, peer.ID, track.trackID, webrtc.RTPCodecTypeAudio)
+		registered++
+	}
+	return registered
+}
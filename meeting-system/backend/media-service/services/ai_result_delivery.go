@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	pb "meeting-system/shared/grpc"
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// aiResultPublishTimeout 单条 AI 结果发布超时，避免事件总线阻塞结果接收
+const aiResultPublishTimeout = 2 * time.Second
+
+// AIResultPublisher AI 流式结果发布接口（生产环境为 Kafka 事件总线）
+type AIResultPublisher interface {
+	Publish(ctx context.Context, channel string, msg *queue.PubSubMessage) error
+}
+
+// SetResultPublisher 设置 AI 结果发布器，结果由信令服务推送给会议参与者
+func (p *MediaProcessor) SetResultPublisher(publisher AIResultPublisher) {
+	p.resultPublisher = publisher
+}
+
+// SetStreamSource 记录 AI 流所属会议、发布者 Peer 与上行轨道，用于投递结果并标注说话人
+func (p *MediaProcessor) SetStreamSource(streamID string, meetingID uint, peerID, trackID string, kind webrtc.RTPCodecType) {
+	p.streamsMux.RLock()
+	stream, exists := p.activeStreams[streamID]
+	p.streamsMux.RUnlock()
+	if !exists {
+		return
+	}
+
+	stream.resultMux.Lock()
+	stream.MeetingID = meetingID
+	stream.PeerID = peerID
+	stream.TrackID = trackID
+	stream.TrackKind = kind.String()
+	stream.resultMux.Unlock()
+}
+
+// publishAIResult 将 AI 结果转为信令消息并发布；重复或无内容的结果不发布
+func (p *MediaProcessor) publishAIResult(stream *StreamProcessor, result *pb.AIStreamResult) {
+	if p.resultPublisher == nil {
+		return
+	}
+
+	msg, meetingID, ok := stream.buildAIResultMessage(result)
+	if !ok {
+		return
+	}
+	if meetingID == 0 {
+		logger.Warn("Skip AI result delivery: meeting unknown",
+			logger.String("stream_id", stream.StreamID),
+			logger.String("room_id", stream.RoomID))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiResultPublishTimeout)
+	defer cancel()
+	if err := p.resultPublisher.Publish(ctx, queue.ChannelAIEvents, &queue.PubSubMessage{
+		Type: queue.EventAIStreamResult,
+		Payload: map[string]interface{}{
+			"meeting_id": meetingID,
+			"result":     msg,
+		},
+		Source: "media-service",
+	}); err != nil {
+		logger.Warn("Failed to publish AI stream result",
+			logger.String("stream_id", stream.StreamID),
+			logger.String("result_id", msg.ResultID),
+			logger.Err(err))
+	}
+}
+
+// buildAIResultMessage 解析 AI 结果并标注说话人/轨道。
+// 同一结果类型的序列号不大于已推送的序列号时视为重复；空字幕、未告警的伪造检测、未变化的在场状态不推送。
+func (stream *StreamProcessor) buildAIResultMessage(result *pb.AIStreamResult) (*models.AIStreamResultMessage, uint, bool) {
+	stream.resultMux.Lock()
+	defer stream.resultMux.Unlock()
+
+	if last, seen := stream.deliveredSeqs[result.ResultType]; seen && result.Sequence <= last {
+		return nil, 0, false
+	}
+
+	var data map[string]interface{}
+	if result.ResultData != "" {
+		if err := json.Unmarshal([]byte(result.ResultData), &data); err != nil {
+			logger.Warn("Invalid AI result data",
+				logger.String("stream_id", stream.StreamID),
+				logger.String("result_type", result.ResultType),
+				logger.Err(err))
+			return nil, 0, false
+		}
+	}
+
+	speakerUserID, _ := strconv.ParseUint(stream.UserID, 10, 64)
+	timestamp := time.Now()
+	if result.Timestamp != nil {
+		timestamp = result.Timestamp.AsTime()
+	}
+	msg := &models.AIStreamResultMessage{
+		ResultID:      fmt.Sprintf("%s/%d/%s/%d", stream.StreamID, stream.StartedAt.UnixMilli(), result.ResultType, result.Sequence),
+		ResultType:    result.ResultType,
+		SpeakerUserID: uint(speakerUserID),
+		SpeakerPeerID: stream.PeerID,
+		TrackID:       stream.TrackID,
+		TrackKind:     stream.TrackKind,
+		StreamID:      stream.StreamID,
+		Sequence:      result.Sequence,
+		IsFinal:       result.IsFinal,
+		Confidence:    result.Confidence,
+		TimestampMs:   timestamp.UnixMilli(),
+	}
+	if data != nil {
+		msg.Data = json.RawMessage(result.ResultData)
+	}
+
+	switch strings.ToLower(result.ResultType) {
+	case "speech_recognition", "asr":
+		msg.Kind = models.AIResultKindCaption
+		msg.Text, _ = data["text"].(string)
+		msg.Text = strings.TrimSpace(msg.Text)
+		if msg.Text == "" {
+			return nil, 0, false
+		}
+	case "emotion_detection", "emotion":
+		msg.Kind = models.AIResultKindEmotion
+		msg.Label, _ = data["emotion"].(string)
+		if msg.Label == "" {
+			return nil, 0, false
+		}
+	case "synthesis_detection", "deepfake_detection":
+		msg.Kind = models.AIResultKindDeepfake
+		synthetic, _ := data["is_synthetic"].(bool)
+		deepfake, _ := data["is_deepfake"].(bool)
+		msg.Warning = synthetic || deepfake
+		if !msg.Warning {
+			return nil, 0, false
+		}
+	case "face_detection":
+		msg.Kind = models.AIResultKindPresence
+		present, _ := data["present"].(bool)
+		if last, seen := stream.presence[result.ResultType]; seen && last == present {
+			return nil, 0, false
+		}
+		if stream.presence == nil {
+			stream.presence = make(map[string]bool)
+		}
+		stream.presence[result.ResultType] = present
+		msg.Warning = !present
+	default:
+		return nil, 0, false
+	}
+
+	if stream.deliveredSeqs == nil {
+		stream.deliveredSeqs = make(map[string]int32)
+	}
+	stream.deliveredSeqs[result.ResultType] = result.Sequence
+	return msg, stream.MeetingID, true
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "meeting-system/shared/grpc"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	channels []string
+	messages []*queue.PubSubMessage
+}
+
+func (r *recordingPublisher) Publish(_ context.Context, channel string, msg *queue.PubSubMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.channels = append(r.channels, channel)
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+// published 已发布消息的快照（异步发布时使用）
+func (r *recordingPublisher) published() ([]string, []*queue.PubSubMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.channels...), append([]*queue.PubSubMessage(nil), r.messages...)
+}
+
+func aiResult(resultType string, seq int32, data string) *pb.AIStreamResult {
+	return &pb.AIStreamResult{StreamId: "peer-a_track-1", Sequence: seq, ResultType: resultType, ResultData: data, Confidence: 0.9}
+}
+
+// TestMediaProcessor_PublishAIResults 结果标注说话人与轨道，按序列号去重，无内容的结果不推送
+func TestMediaProcessor_PublishAIResults(t *testing.T) {
+	publisher := &recordingPublisher{}
+	p := &MediaProcessor{activeStreams: make(map[string]*StreamProcessor)}
+	p.SetResultPublisher(publisher)
+
+	stream := &StreamProcessor{StreamID: "peer-a_track-1", UserID: "7", RoomID: "room_42_1_000001"}
+	stream.IsActive.Store(true)
+	p.activeStreams[stream.StreamID] = stream
+	p.SetStreamSource(stream.StreamID, 42, "peer-a", "track-1", webrtc.RTPCodecTypeAudio)
+
+	for _, r := range []*pb.AIStreamResult{
+		aiResult("speech_recognition", 1, `{"text":" hello "}`),
+		aiResult("speech_recognition", 1, `{"text":"hello"}`), // 重复序列号
+		aiResult("speech_recognition", 2, `{"text":""}`),      // 静音片段
+		aiResult("emotion_detection", 1, `{"emotion":"happy"}`),
+		aiResult("synthesis_detection", 1, `{"is_synthetic":false}`),
+		aiResult("synthesis_detection", 2, `{"is_synthetic":true,"score":0.97}`),
+		aiResult("face_detection", 1, `{"present":true}`),
+		aiResult("face_detection", 2, `{"present":true}`), // 在场状态未变化
+		aiResult("face_detection", 3, `{"present":false}`),
+		aiResult("speech_recognition", 3, `not json`),
+	} {
+		p.publishAIResult(stream, r)
+	}
+
+	require.Len(t, publisher.messages, 5)
+	for _, ch := range publisher.channels {
+		assert.Equal(t, queue.ChannelAIEvents, ch)
+	}
+
+	kinds := make([]string, 0, len(publisher.messages))
+	for _, msg := range publisher.messages {
+		assert.Equal(t, queue.EventAIStreamResult, msg.Type)
+		assert.Equal(t, uint(42), msg.Payload["meeting_id"])
+		kinds = append(kinds, msg.Payload["result"].(*models.AIStreamResultMessage).Kind)
+	}
+	assert.Equal(t, []string{
+		models.AIResultKindCaption,
+		models.AIResultKindEmotion,
+		models.AIResultKindDeepfake,
+		models.AIResultKindPresence,
+		models.AIResultKindPresence,
+	}, kinds)
+
+	caption := publisher.messages[0].Payload["result"].(*models.AIStreamResultMessage)
+	assert.Equal(t, "hello", caption.Text)
+	assert.Equal(t, uint(7), caption.SpeakerUserID)
+	assert.Equal(t, "peer-a", caption.SpeakerPeerID)
+	assert.Equal(t, "track-1", caption.TrackID)
+	assert.Equal(t, "audio", caption.TrackKind)
+	assert.Contains(t, caption.ResultID, "peer-a_track-1/")
+	assert.Contains(t, caption.ResultID, "/speech_recognition/1")
+
+	warning := publisher.messages[2].Payload["result"].(*models.AIStreamResultMessage)
+	assert.True(t, warning.Warning)
+	assert.Equal(t, int32(2), warning.Sequence)
+	encoded, err := json.Marshal(warning)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"data":{"is_synthetic":true,"score":0.97}`)
+
+	assert.False(t, publisher.messages[3].Payload["result"].(*models.AIStreamResultMessage).Warning)
+	assert.True(t, publisher.messages[4].Payload["result"].(*models.AIStreamResultMessage).Warning)
+}
+
+// TestMediaProcessor_PublishAIResultsSkipsUnknownMeeting 未解析出所属会议的流不推送
+func TestMediaProcessor_PublishAIResultsSkipsUnknownMeeting(t *testing.T) {
+	publisher := &recordingPublisher{}
+	p := &MediaProcessor{}
+	p.SetResultPublisher(publisher)
+
+	stream := &StreamProcessor{StreamID: "s", RoomID: "room-1"}
+	stream.IsActive.Store(true)
+	p.publishAIResult(stream, aiResult("speech_recognition", 1, `{"text":"hi"}`))
+	assert.Empty(t, publisher.messages)
+}
+
+func TestMeetingIDFromRoomID(t *testing.T) {
+	assert.Equal(t, uint(42), meetingIDFromRoomID("42"))
+	assert.Equal(t, uint(42), meetingIDFromRoomID("room_42_1700000000000000000_123456"))
+	assert.Zero(t, meetingIDFromRoomID("room-1"))
+	assert.Zero(t, meetingIDFromRoomID("room_x_1"))
+}
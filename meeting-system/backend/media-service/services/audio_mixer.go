@@ -0,0 +1,486 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pion/opus"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"meeting-system/shared/logger"
+)
+
+const (
+	// mixerSampleRate/mixerChannels 混音在 48kHz 单声道上进行，与 Opus RTP 时钟一致
+	mixerSampleRate    = opusRTPClockRate
+	mixerChannels      = 1
+	mixerFrameDuration = 20 * time.Millisecond
+	mixerFrameSamples  = mixerSampleRate / 50
+	// mixerPrimeFrames 输入积累到 2 帧（40ms）才参与混音，吸收网络抖动
+	mixerPrimeFrames = 2
+	// mixerMaxQueuedFrames 输入最多缓存 10 帧（200ms），超出丢弃最旧的样本，防止延迟累积
+	mixerMaxQueuedFrames = 10
+	// mixerMaxGain 单个参与者的最大增益（+12dB）
+	mixerMaxGain = 4.0
+	// mixerEncodeBufferSize Opus 单帧编码输出上限
+	mixerEncodeBufferSize = 1500
+	// mixerConsumerBuffer 内部消费者 PCM 通道容量，消费过慢时丢帧而不阻塞混音循环
+	mixerConsumerBuffer = 50
+
+	// RoomMixListener 全房间混音（不排除任何人），供录制、转推、SIP 等内部消费者使用
+	RoomMixListener = ""
+)
+
+// ErrAudioMixerClosed 混音器已关闭（房间已解散）
+var ErrAudioMixerClosed = errors.New("audio mixer closed")
+
+// ErrOpusEncoderUnavailable 未以 opus 构建标签编译，无法输出混音轨道（构建方式见 opus_encoder.go）
+var ErrOpusEncoderUnavailable = errors.New("opus encoder not built (missing libopus or build tag)")
+
+// opusFrameEncoder Opus 编码器（输入为 mixerSampleRate/mixerChannels 的一帧 PCM）
+type opusFrameEncoder interface {
+	Encode(pcm []int16, data []byte) (int, error)
+}
+
+// newMixerDecoder/newMixerEncoder 可在测试中替换
+var (
+	newMixerDecoder = func() (opusFrameDecoder, error) {
+		decoder, err := opus.NewDecoderWithOutput(mixerSampleRate, mixerChannels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create opus decoder: %w", err)
+		}
+		return &decoder, nil
+	}
+	newMixerEncoder = func() (opusFrameEncoder, error) {
+		return newOpusEncoder(mixerSampleRate, mixerChannels)
+	}
+)
+
+// mixerInput 一条发布音频轨道：重排序 -> 解码 -> 等待混音的 PCM 队列
+type mixerInput struct {
+	participantID string
+	decoder       opusFrameDecoder
+	jitter        audioJitterBuffer
+	scratch       []int16
+	queue         []int16
+	primed        bool
+	nextTS        uint32
+	hasTS         bool
+}
+
+// mixerOutput 一路混音输出；listenerID 为空表示全房间混音，否则为排除该参与者的 N-1 混音
+type mixerOutput struct {
+	listenerID string
+	track      *webrtc.TrackLocalStaticSample
+	encoder    opusFrameEncoder
+	encoded    []byte
+	consumers  map[int]chan []int16
+}
+
+// AudioMixer MCU 式房间混音：解码房间内所有音频轨道，按参与者增益混音，
+// 为每个收听者生成排除其自身声音的 N-1 混音，重新编码为 Opus 并以 TrackLocalStaticSample 输出。
+type AudioMixer struct {
+	roomID string
+
+	mu        sync.Mutex
+	inputs    map[string]*mixerInput // trackKey -> input
+	gains     map[string]float64     // participantID -> gain
+	outputs   map[string]*mixerOutput
+	nextSubID int
+	closed    bool
+	sums      map[string][]int32
+	total     []int32
+	mixed     []int16
+	stopCh    chan struct{}
+}
+
+// NewAudioMixer 创建房间混音器并启动 20ms 混音循环
+func NewAudioMixer(roomID string) *AudioMixer {
+	m := newAudioMixer(roomID)
+	go m.run()
+	return m
+}
+
+func newAudioMixer(roomID string) *AudioMixer {
+	return &AudioMixer{
+		roomID:  roomID,
+		inputs:  make(map[string]*mixerInput),
+		gains:   make(map[string]float64),
+		outputs: make(map[string]*mixerOutput),
+		sums:    make(map[string][]int32),
+		total:   make([]int32, mixerFrameSamples),
+		mixed:   make([]int16, mixerFrameSamples),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// PushRTP 写入发布者音频轨道的一个 Opus RTP 包（RED 需先取主编码）；首次写入时自动加入混音
+func (m *AudioMixer) PushRTP(trackKey, participantID string, seq uint16, timestamp uint32, payload []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+
+	in := m.inputs[trackKey]
+	if in == nil {
+		decoder, err := newMixerDecoder()
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Audio mixer skipped track %s in room %s: %v", trackKey, m.roomID, err))
+			return
+		}
+		in = &mixerInput{
+			participantID: participantID,
+			decoder:       decoder,
+			jitter:        newAudioJitterBuffer(audioTapJitterDepth),
+			scratch:       make([]int16, mixerSampleRate*120/1000*mixerChannels),
+		}
+		m.inputs[trackKey] = in
+	}
+
+	for _, p := range in.jitter.push(seq, timestamp, payload) {
+		in.decodePacket(p)
+	}
+}
+
+// PushPCM 写入已解码的 48kHz 单声道 PCM（SIP G.711 等非 Opus 来源）；首次写入时自动加入混音
+func (m *AudioMixer) PushPCM(trackKey, participantID string, pcm []int16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+
+	in := m.inputs[trackKey]
+	if in == nil {
+		in = &mixerInput{participantID: participantID}
+		m.inputs[trackKey] = in
+	}
+	in.enqueue(pcm)
+}
+
+// RemoveTrack 发布轨道结束时移出混音
+func (m *AudioMixer) RemoveTrack(trackKey string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inputs, trackKey)
+}
+
+// SetGain 设置参与者在所有混音中的增益（0 静音，1 原始音量，最大 mixerMaxGain）
+func (m *AudioMixer) SetGain(participantID string, gain float64) {
+	if math.IsNaN(gain) || gain < 0 {
+		gain = 0
+	}
+	if gain > mixerMaxGain {
+		gain = mixerMaxGain
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if gain == 1 {
+		delete(m.gains, participantID)
+		return
+	}
+	m.gains[participantID] = gain
+}
+
+// Gain 参与者当前增益
+func (m *AudioMixer) Gain(participantID string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gainLocked(participantID)
+}
+
+// Track 返回收听者的 Opus 混音轨道（listenerID 为 RoomMixListener 时为全房间混音），不存在时创建。
+// 返回的轨道可直接 AddTrack 到任意 PeerConnection。
+func (m *AudioMixer) Track(listenerID string) (*webrtc.TrackLocalStaticSample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, ErrAudioMixerClosed
+	}
+
+	if out := m.outputs[listenerID]; out != nil && out.track != nil {
+		return out.track, nil
+	}
+
+	encoder, err := newMixerEncoder()
+	if err != nil {
+		return nil, err
+	}
+	trackID := "mix"
+	if listenerID != RoomMixListener {
+		trackID = "mix_" + listenerID
+	}
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{
+		MimeType:    webrtc.MimeTypeOpus,
+		ClockRate:   opusRTPClockRate,
+		Channels:    2,
+		SDPFmtpLine: "minptime=10;useinbandfec=1",
+	}, trackID, "room_mix_"+m.roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mix track: %w", err)
+	}
+	out := m.outputLocked(listenerID)
+	out.track = track
+	out.encoder = encoder
+	out.encoded = make([]byte, mixerEncodeBufferSize)
+	return track, nil
+}
+
+// Subscribe 内部消费者订阅收听者混音的 PCM（48kHz 单声道，每次一帧 20ms），调用返回的函数取消订阅。
+// 消费过慢时丢帧。
+func (m *AudioMixer) Subscribe(listenerID string) (<-chan []int16, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, nil, ErrAudioMixerClosed
+	}
+
+	out := m.outputLocked(listenerID)
+	m.nextSubID++
+	id := m.nextSubID
+	ch := make(chan []int16, mixerConsumerBuffer)
+	out.consumers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if current := m.outputs[listenerID]; current != nil {
+				if c, ok := current.consumers[id]; ok {
+					delete(current.consumers, id)
+					close(c)
+				}
+			}
+		})
+	}
+	return ch, cancel, nil
+}
+
+// RemoveListener 收听者离开房间时释放其 N-1 混音及订阅
+func (m *AudioMixer) RemoveListener(listenerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := m.outputs[listenerID]
+	if out == nil {
+		return
+	}
+	delete(m.outputs, listenerID)
+	for id, ch := range out.consumers {
+		delete(out.consumers, id)
+		close(ch)
+	}
+}
+
+// Close 停止混音循环并关闭所有订阅
+func (m *AudioMixer) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.stopCh)
+	for listenerID, out := range m.outputs {
+		delete(m.outputs, listenerID)
+		for id, ch := range out.consumers {
+			delete(out.consumers, id)
+			close(ch)
+		}
+	}
+	m.inputs = make(map[string]*mixerInput)
+	m.mu.Unlock()
+}
+
+func (m *AudioMixer) run() {
+	ticker := time.NewTicker(mixerFrameDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.mixFrame()
+		}
+	}
+}
+
+// mixFrame 混合一帧：先按参与者累加（含增益），全房间混音为总和，
+// N-1 混音从总和中减去收听者自身的贡献，再限幅、编码并分发。
+func (m *AudioMixer) mixFrame() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed || len(m.outputs) == 0 {
+		// 没有收听者时仍需消耗输入，避免恢复收听后播放陈旧音频
+		for _, in := range m.inputs {
+			in.takeFrame()
+		}
+		return
+	}
+
+	for _, sum := range m.sums {
+		clear(sum)
+	}
+	clear(m.total)
+
+	for _, in := range m.inputs {
+		frame := in.takeFrame()
+		if frame == nil {
+			continue
+		}
+		gain := m.gainLocked(in.participantID)
+		if gain == 0 {
+			continue
+		}
+		sum := m.sums[in.participantID]
+		if sum == nil {
+			sum = make([]int32, mixerFrameSamples)
+			m.sums[in.participantID] = sum
+		}
+		for i, s := range frame {
+			v := int32(s)
+			if gain != 1 {
+				v = int32(float64(s) * gain)
+			}
+			sum[i] += v
+			m.total[i] += v
+		}
+	}
+
+	// 已离开的参与者不再保留累加缓冲
+	for participantID := range m.sums {
+		if !m.hasParticipantLocked(participantID) {
+			delete(m.sums, participantID)
+		}
+	}
+
+	for _, out := range m.outputs {
+		exclude := m.sums[out.listenerID]
+		for i, v := range m.total {
+			if out.listenerID != RoomMixListener && exclude != nil {
+				v -= exclude[i]
+			}
+			m.mixed[i] = clampInt16(v)
+		}
+		m.deliverLocked(out, m.mixed)
+	}
+}
+
+func (m *AudioMixer) deliverLocked(out *mixerOutput, pcm []int16) {
+	if len(out.consumers) > 0 {
+		frame := append([]int16(nil), pcm...)
+		for _, ch := range out.consumers {
+			select {
+			case ch <- frame:
+			default:
+			}
+		}
+	}
+
+	if out.track == nil || out.encoder == nil {
+		return
+	}
+	n, err := out.encoder.Encode(pcm, out.encoded)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Audio mixer encode failed (room=%s, listener=%s): %v", m.roomID, out.listenerID, err))
+		return
+	}
+	if err := out.track.WriteSample(media.Sample{Data: out.encoded[:n], Duration: mixerFrameDuration}); err != nil {
+		logger.Debug(fmt.Sprintf("Audio mixer write failed (room=%s, listener=%s): %v", m.roomID, out.listenerID, err))
+	}
+}
+
+func (m *AudioMixer) outputLocked(listenerID string) *mixerOutput {
+	out := m.outputs[listenerID]
+	if out == nil {
+		out = &mixerOutput{listenerID: listenerID, consumers: make(map[int]chan []int16)}
+		m.outputs[listenerID] = out
+	}
+	return out
+}
+
+func (m *AudioMixer) gainLocked(participantID string) float64 {
+	if gain, ok := m.gains[participantID]; ok {
+		return gain
+	}
+	return 1
+}
+
+func (m *AudioMixer) hasParticipantLocked(participantID string) bool {
+	for _, in := range m.inputs {
+		if in.participantID == participantID {
+			return true
+		}
+	}
+	return false
+}
+
+// decodePacket 解码一个按序输出的包；DTX 与丢包造成的时间戳缺口补静音
+func (in *mixerInput) decodePacket(p jitterPacket) {
+	if in.hasTS {
+		if gap := int32(p.timestamp - in.nextTS); gap > 0 {
+			samples := int(gap) * mixerSampleRate / opusRTPClockRate
+			in.appendSilence(samples)
+		}
+	}
+
+	n, err := in.decoder.DecodeToInt16(p.payload, in.scratch)
+	if err != nil || n <= 0 {
+		n = mixerFrameSamples
+		in.appendSilence(n)
+	} else {
+		in.enqueue(in.scratch[:n*mixerChannels])
+	}
+	in.nextTS = p.timestamp + uint32(n*opusRTPClockRate/mixerSampleRate)
+	in.hasTS = true
+}
+
+func (in *mixerInput) appendSilence(samples int) {
+	if limit := mixerMaxQueuedFrames * mixerFrameSamples; samples > limit {
+		samples = limit
+	}
+	in.enqueue(make([]int16, samples))
+}
+
+// enqueue 追加 PCM，超过 mixerMaxQueuedFrames 时丢弃最旧的样本
+func (in *mixerInput) enqueue(pcm []int16) {
+	in.queue = append(in.queue, pcm...)
+	if limit := mixerMaxQueuedFrames * mixerFrameSamples; len(in.queue) > limit {
+		in.queue = append(in.queue[:0], in.queue[len(in.queue)-limit:]...)
+	}
+}
+
+// takeFrame 取出一帧 PCM；未积累到 mixerPrimeFrames 或欠载时返回 nil（本帧不参与混音）
+func (in *mixerInput) takeFrame() []int16 {
+	if !in.primed {
+		if len(in.queue) < mixerPrimeFrames*mixerFrameSamples {
+			return nil
+		}
+		in.primed = true
+	}
+	if len(in.queue) < mixerFrameSamples {
+		// 欠载后重新积累，避免每帧都在边界上抖动
+		in.primed = false
+		return nil
+	}
+
+	frame := in.queue[:mixerFrameSamples:mixerFrameSamples]
+	in.queue = in.queue[mixerFrameSamples:]
+	return frame
+}
+
+func clampInt16(v int32) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
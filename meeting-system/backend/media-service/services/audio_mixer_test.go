@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+)
+
+var errOpusEncoderUnavailableForTest = errors.New("encoder unavailable")
+
+// fakeMixerDecoder 每个包解码为 20ms、采样值为 int16(payload[0])*100 的常量帧
+type fakeMixerDecoder struct{}
+
+func (fakeMixerDecoder) DecodeToInt16(in []byte, out []int16) (int, error) {
+	if len(in) == 0 {
+		return 0, errors.New("empty packet")
+	}
+	for i := 0; i < mixerFrameSamples; i++ {
+		out[i] = int16(int8(in[0])) * 100
+	}
+	return mixerFrameSamples, nil
+}
+
+// recordingEncoder 记录每帧首个样本
+type recordingEncoder struct {
+	first []int16
+}
+
+func (e *recordingEncoder) Encode(pcm []int16, data []byte) (int, error) {
+	e.first = append(e.first, pcm[0])
+	data[0] = 0xf8
+	return 1, nil
+}
+
+func useFakeMixerCodecs(t *testing.T, encoder opusFrameEncoder, encoderErr error) {
+	t.Helper()
+	prevDecoder, prevEncoder := newMixerDecoder, newMixerEncoder
+	newMixerDecoder = func() (opusFrameDecoder, error) { return fakeMixerDecoder{}, nil }
+	newMixerEncoder = func() (opusFrameEncoder, error) { return encoder, encoderErr }
+	t.Cleanup(func() {
+		newMixerDecoder, newMixerEncoder = prevDecoder, prevEncoder
+	})
+}
+
+// pushFrames 为轨道写入 n 个连续的 20ms 包
+func pushFrames(m *AudioMixer, trackKey, participantID string, value byte, n int) {
+	for i := 0; i < n; i++ {
+		seq := uint16(i)
+		m.PushRTP(trackKey, participantID, seq, uint32(i*mixerFrameSamples), []byte{value})
+	}
+}
+
+func nextFrame(t *testing.T, ch <-chan []int16) []int16 {
+	t.Helper()
+	select {
+	case frame := <-ch:
+		require.Len(t, frame, mixerFrameSamples)
+		return frame
+	default:
+		t.Fatal("expected a mixed frame")
+		return nil
+	}
+}
+
+func TestAudioMixer_NMinusOneMixes(t *testing.T) {
+	useFakeMixerCodecs(t, nil, errOpusEncoderUnavailableForTest)
+	m := newAudioMixer("room-1")
+
+	room, cancelRoom, err := m.Subscribe(RoomMixListener)
+	require.NoError(t, err)
+	defer cancelRoom()
+	alice, _, err := m.Subscribe("alice")
+	require.NoError(t, err)
+	bob, _, err := m.Subscribe("bob")
+	require.NoError(t, err)
+	carol, _, err := m.Subscribe("carol")
+	require.NoError(t, err)
+
+	pushFrames(m, "alice:mic", "alice", 1, mixerPrimeFrames+1)
+	pushFrames(m, "bob:mic", "bob", 2, mixerPrimeFrames+1)
+	pushFrames(m, "carol:mic", "carol", 4, mixerPrimeFrames+1)
+
+	m.mixFrame()
+
+	assert.Equal(t, int16(700), nextFrame(t, room)[0])
+	assert.Equal(t, int16(600), nextFrame(t, alice)[0], "alice 听不到自己")
+	assert.Equal(t, int16(500), nextFrame(t, bob)[0])
+	assert.Equal(t, int16(300), nextFrame(t, carol)[0])
+}
+
+func TestAudioMixer_GainAndClipping(t *testing.T) {
+	useFakeMixerCodecs(t, nil, errOpusEncoderUnavailableForTest)
+	m := newAudioMixer("room-1")
+
+	m.SetGain("alice", 0.5)
+	m.SetGain("bob", 10)
+	assert.Equal(t, 0.5, m.Gain("alice"))
+	assert.Equal(t, mixerMaxGain, m.Gain("bob"), "增益被限制在上限")
+	assert.Equal(t, 1.0, m.Gain("carol"))
+
+	room, _, err := m.Subscribe(RoomMixListener)
+	require.NoError(t, err)
+	carol, _, err := m.Subscribe("carol")
+	require.NoError(t, err)
+
+	pushFrames(m, "alice:mic", "alice", 100, mixerPrimeFrames+1)
+	pushFrames(m, "bob:mic", "bob", 100, mixerPrimeFrames+1)
+	m.mixFrame()
+
+	assert.Equal(t, int16(32767), nextFrame(t, room)[0], "超出 int16 范围时限幅")
+	assert.Equal(t, int16(32767), nextFrame(t, carol)[0])
+
+	m.SetGain("bob", 0)
+	m.mixFrame()
+	assert.Equal(t, int16(5000), nextFrame(t, room)[0], "增益 0 的参与者不进入混音")
+}
+
+func TestAudioMixer_WaitsForPrimedInput(t *testing.T) {
+	useFakeMixerCodecs(t, nil, errOpusEncoderUnavailableForTest)
+	m := newAudioMixer("room-1")
+	room, _, err := m.Subscribe(RoomMixListener)
+	require.NoError(t, err)
+
+	pushFrames(m, "alice:mic", "alice", 1, 1)
+	m.mixFrame()
+	assert.Equal(t, int16(0), nextFrame(t, room)[0], "未积累到预缓冲帧数时输出静音")
+
+	m.PushRTP("alice:mic", "alice", 1, mixerFrameSamples, []byte{1})
+	m.mixFrame()
+	assert.Equal(t, int16(100), nextFrame(t, room)[0])
+}
+
+func TestAudioMixer_TrackEncodesMix(t *testing.T) {
+	encoder := &recordingEncoder{}
+	useFakeMixerCodecs(t, encoder, nil)
+	m := newAudioMixer("room-1")
+
+	track, err := m.Track("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "mix_alice", track.ID())
+	assert.Equal(t, "room_mix_room-1", track.StreamID())
+
+	again, err := m.Track("alice")
+	require.NoError(t, err)
+	assert.Same(t, track, again)
+
+	pushFrames(m, "alice:mic", "alice", 1, mixerPrimeFrames+1)
+	pushFrames(m, "bob:mic", "bob", 2, mixerPrimeFrames+1)
+	m.mixFrame()
+
+	assert.Equal(t, []int16{200}, encoder.first)
+}
+
+func TestAudioMixer_TrackWithoutEncoder(t *testing.T) {
+	useFakeMixerCodecs(t, nil, errOpusEncoderUnavailableForTest)
+	m := newAudioMixer("room-1")
+
+	_, err := m.Track(RoomMixListener)
+	assert.ErrorIs(t, err, errOpusEncoderUnavailableForTest)
+}
+
+func TestAudioMixer_RemoveListenerAndClose(t *testing.T) {
+	useFakeMixerCodecs(t, nil, errOpusEncoderUnavailableForTest)
+	m := newAudioMixer("room-1")
+
+	alice, _, err := m.Subscribe("alice")
+	require.NoError(t, err)
+	room, _, err := m.Subscribe(RoomMixListener)
+	require.NoError(t, err)
+
+	m.RemoveListener("alice")
+	_, open := <-alice
+	assert.False(t, open, "收听者离开后订阅被关闭")
+
+	m.Close()
+	_, open = <-room
+	assert.False(t, open)
+	_, _, err = m.Subscribe(RoomMixListener)
+	assert.ErrorIs(t, err, ErrAudioMixerClosed)
+}
+
+// subscribedTrackKeys Peer 逐路订阅的轨道
+func subscribedTrackKeys(svc *WebRTCService, roomID, peerID string) []string {
+	svc.roomsMux.RLock()
+	room := svc.rooms[roomID]
+	svc.roomsMux.RUnlock()
+	room.TracksMux.RLock()
+	defer room.TracksMux.RUnlock()
+	var keys []string
+	for key, ft := range room.Tracks {
+		if _, ok := ft.SubscriberSenders[peerID]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// TestWebRTCService_RoomMixReplacesPerTrackAudio 订阅混音期间不再逐路收听音频，取消后恢复
+func TestWebRTCService_RoomMixReplacesPerTrackAudio(t *testing.T) {
+	useFakeMixerCodecs(t, &recordingEncoder{}, nil)
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	speaker := registerTestPeer(t, svc, "1", "7")
+	listener := registerTestPeer(t, svc, "1", "8")
+	_, err := svc.publishExternalTrack("1", speaker.ID, "audio", webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2})
+	require.NoError(t, err)
+	_, err = svc.publishExternalTrack("1", speaker.ID, "video", webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"peer-7:audio", "peer-7:video"}, subscribedTrackKeys(svc, "1", listener.ID))
+
+	require.NoError(t, svc.SubscribePeerToRoomMix(listener.ID))
+	assert.Equal(t, []string{"peer-7:video"}, subscribedTrackKeys(svc, "1", listener.ID), "音频只从混音收听")
+
+	// 订阅混音后新发布的音频也不逐路转发
+	_, err = svc.publishExternalTrack("1", speaker.ID, "audio2", webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"peer-7:video"}, subscribedTrackKeys(svc, "1", listener.ID))
+
+	require.NoError(t, svc.UnsubscribePeerFromRoomMix(listener.ID))
+	assert.ElementsMatch(t, []string{"peer-7:audio", "peer-7:audio2", "peer-7:video"}, subscribedTrackKeys(svc, "1", listener.ID))
+}
+
+// TestWebRTCService_RoomMixAuthorization Peer 本人或主持人可以切换混音订阅，只有主持人可以调整增益
+func TestWebRTCService_RoomMixAuthorization(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+	svc.isMeetingHost = func(meetingID, userID uint) (bool, error) {
+		return meetingID == 1 && userID == 7, nil
+	}
+
+	listener := registerTestPeer(t, svc, "1", "8")
+
+	assert.NoError(t, svc.AuthorizePeerRoomMix(listener.ID, 8), "Peer 本人")
+	assert.NoError(t, svc.AuthorizePeerRoomMix(listener.ID, 7), "会议主持人")
+	assert.ErrorIs(t, svc.AuthorizePeerRoomMix(listener.ID, 9), ErrRoomMixForbidden)
+	assert.ErrorIs(t, svc.AuthorizePeerRoomMix("peer-404", 8), ErrPeerNotFound)
+
+	assert.NoError(t, svc.AuthorizeRoomMixGain("1", 7))
+	assert.ErrorIs(t, svc.AuthorizeRoomMixGain("1", 8), ErrRoomMixForbidden)
+	assert.ErrorIs(t, svc.AuthorizeRoomMixGain("not-a-meeting", 7), ErrRoomMixForbidden)
+}
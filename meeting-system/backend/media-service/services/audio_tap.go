@@ -0,0 +1,246 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/pion/opus"
+	"github.com/pion/webrtc/v3"
+)
+
+const (
+	// aiAudioSampleRate/aiAudioChannels 送往 AI 服务的 PCM 格式（ASR 通用的 16kHz 单声道 S16LE）
+	aiAudioSampleRate = 16000
+	aiAudioChannels   = 1
+	aiAudioFormat     = "pcm"
+
+	opusRTPClockRate = 48000
+	// audioTapJitterDepth 重排序缓冲最多等待的包数（20ms 一包约 120ms）
+	audioTapJitterDepth = 6
+	// audioTapMaxConcealFrames 连续丢包时最多做几帧 PLC，之后插入静音
+	audioTapMaxConcealFrames = 5
+	// audioTapMaxGapSamples 单次补齐的最大样本数（1s），避免长时间中断后一次性写入大量静音
+	audioTapMaxGapSamples = aiAudioSampleRate
+	// audioTapMaxFrameSamples Opus 单包最长 120ms
+	audioTapMaxFrameSamples       = aiAudioSampleRate * 120 / 1000
+	audioTapDefaultFrameSamples   = aiAudioSampleRate / 50
+	audioTapSequenceResetDistance = 1000
+)
+
+// opusFrameDecoder Opus 解码器（输出已按 aiAudioSampleRate/aiAudioChannels 配置）
+type opusFrameDecoder interface {
+	DecodeToInt16(in []byte, out []int16) (int, error)
+}
+
+// jitterPacket 重排序后按序输出的包；lost 为该包之前缺失的包数
+type jitterPacket struct {
+	seq       uint16
+	timestamp uint32
+	payload   []byte
+	lost      int
+}
+
+// audioJitterBuffer 按 RTP 序列号重排序，等待超过 depth 个包仍未到达的序号视为丢失
+type audioJitterBuffer struct {
+	depth       int
+	packets     map[uint16]jitterPacket
+	nextSeq     uint16
+	pendingLost int
+	started     bool
+}
+
+func newAudioJitterBuffer(depth int) audioJitterBuffer {
+	return audioJitterBuffer{depth: depth, packets: make(map[uint16]jitterPacket, depth+1)}
+}
+
+// push 写入一个包，返回当前可以按序输出的包
+func (j *audioJitterBuffer) push(seq uint16, timestamp uint32, payload []byte) []jitterPacket {
+	if !j.started {
+		j.started = true
+		j.nextSeq = seq
+	}
+
+	diff := int16(seq - j.nextSeq)
+	if diff > audioTapSequenceResetDistance || diff < -audioTapSequenceResetDistance {
+		// 序列号跳变（发布端重启编码器等），丢弃缓冲重新开始
+		j.reset(seq)
+	} else if diff < 0 {
+		// 迟到或重复的包，已经按丢包处理过
+		return nil
+	}
+
+	j.packets[seq] = jitterPacket{seq: seq, timestamp: timestamp, payload: payload}
+	return j.drain(j.depth)
+}
+
+// flush 输出缓冲中剩余的全部包
+func (j *audioJitterBuffer) flush() []jitterPacket {
+	return j.drain(0)
+}
+
+func (j *audioJitterBuffer) drain(depth int) []jitterPacket {
+	var out []jitterPacket
+	for len(j.packets) > 0 {
+		if p, ok := j.packets[j.nextSeq]; ok {
+			delete(j.packets, j.nextSeq)
+			p.lost = j.pendingLost
+			j.pendingLost = 0
+			out = append(out, p)
+			j.nextSeq++
+			continue
+		}
+		if len(j.packets) <= depth {
+			break
+		}
+		j.pendingLost++
+		j.nextSeq++
+	}
+	return out
+}
+
+func (j *audioJitterBuffer) reset(seq uint16) {
+	for k := range j.packets {
+		delete(j.packets, k)
+	}
+	j.nextSeq = seq
+	j.pendingLost = 0
+}
+
+// AudioTap 把发布者的 Opus RTP 流转换为 AI 使用的 16kHz 单声道 PCM：
+// 重排序 -> 解码（解码器内完成混音与重采样）-> 丢包做 PLC、DTX/静默期补静音。
+type AudioTap struct {
+	mu        sync.Mutex
+	decoder   opusFrameDecoder
+	jitter    audioJitterBuffer
+	pcm       []int16
+	lastFrame []int16
+	concealed int
+	nextTS    uint32
+	hasTS     bool
+}
+
+// NewAudioTap 创建 Opus 音频分流器
+func NewAudioTap() (*AudioTap, error) {
+	decoder, err := opus.NewDecoderWithOutput(aiAudioSampleRate, aiAudioChannels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create opus decoder: %w", err)
+	}
+	return newAudioTap(&decoder), nil
+}
+
+func newAudioTap(decoder opusFrameDecoder) *AudioTap {
+	return &AudioTap{
+		decoder: decoder,
+		jitter:  newAudioJitterBuffer(audioTapJitterDepth),
+		pcm:     make([]int16, audioTapMaxFrameSamples*aiAudioChannels),
+	}
+}
+
+// Push 写入一个 Opus RTP 包，返回可以送往 AI 的 S16LE PCM（可能为空，包在重排序缓冲中等待）
+func (t *AudioTap) Push(seq uint16, timestamp uint32, payload []byte) []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []byte
+	for _, p := range t.jitter.push(seq, timestamp, payload) {
+		out = t.decodePacket(out, p)
+	}
+	return out
+}
+
+// Flush 解码重排序缓冲中剩余的包（流结束时调用）
+func (t *AudioTap) Flush() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []byte
+	for _, p := range t.jitter.flush() {
+		out = t.decodePacket(out, p)
+	}
+	return out
+}
+
+func (t *AudioTap) decodePacket(out []byte, p jitterPacket) []byte {
+	if t.hasTS {
+		// RTP 时间戳以 48kHz 计；缺口来自丢包则做 PLC，序号连续（DTX）则补静音
+		if gap := int32(p.timestamp - t.nextTS); gap > 0 {
+			samples := int(gap) / (opusRTPClockRate / aiAudioSampleRate)
+			if samples > audioTapMaxGapSamples {
+				samples = audioTapMaxGapSamples
+			}
+			if p.lost > 0 {
+				out = t.conceal(out, samples)
+			} else {
+				out = appendSilence(out, samples)
+			}
+		}
+	}
+
+	n, err := t.decoder.DecodeToInt16(p.payload, t.pcm)
+	if err != nil || n <= 0 {
+		// 无法解码的包按丢包处理
+		n = len(t.lastFrame)
+		if n == 0 {
+			n = audioTapDefaultFrameSamples
+		}
+		out = t.conceal(out, n)
+	} else {
+		frame := t.pcm[:n*aiAudioChannels]
+		out = appendPCM(out, frame, 1)
+		t.lastFrame = append(t.lastFrame[:0], frame...)
+		t.concealed = 0
+	}
+
+	t.nextTS = p.timestamp + uint32(n*(opusRTPClockRate/aiAudioSampleRate))
+	t.hasTS = true
+	return out
+}
+
+// conceal 用上一帧逐帧衰减重复填补缺口，超过 audioTapMaxConcealFrames 帧后填静音
+func (t *AudioTap) conceal(out []byte, samples int) []byte {
+	frameLen := len(t.lastFrame)
+	if frameLen == 0 {
+		return appendSilence(out, samples)
+	}
+	for samples > 0 {
+		chunk := samples
+		if chunk > frameLen {
+			chunk = frameLen
+		}
+		if t.concealed >= audioTapMaxConcealFrames {
+			out = appendSilence(out, chunk)
+		} else {
+			gain := math.Pow(0.5, float64(t.concealed+1))
+			out = appendPCM(out, t.lastFrame[:chunk], gain)
+		}
+		t.concealed++
+		samples -= chunk
+	}
+	return out
+}
+
+func appendPCM(out []byte, samples []int16, gain float64) []byte {
+	for _, s := range samples {
+		if gain != 1 {
+			s = int16(float64(s) * gain)
+		}
+		out = binary.LittleEndian.AppendUint16(out, uint16(s))
+	}
+	return out
+}
+
+func appendSilence(out []byte, samples int) []byte {
+	return append(out, make([]byte, samples*aiAudioChannels*2)...)
+}
+
+// pcmDurationMs S16LE PCM 字节数对应的时长（毫秒）
+func pcmDurationMs(n int) int {
+	return n / (2 * aiAudioChannels) * 1000 / aiAudioSampleRate
+}
+
+func isOpus(mimeType string) bool {
+	return strings.EqualFold(mimeType, webrtc.MimeTypeOpus)
+}
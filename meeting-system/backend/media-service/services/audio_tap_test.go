@@ -0,0 +1,120 @@
+package services
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOpusDecoder 每个包解码为 20ms、采样值为 payload[0]*100 的常量帧；payload[0]==0 模拟损坏包
+type fakeOpusDecoder struct{}
+
+func (fakeOpusDecoder) DecodeToInt16(in []byte, out []int16) (int, error) {
+	if len(in) == 0 || in[0] == 0 {
+		return 0, errors.New("corrupt packet")
+	}
+	n := audioTapDefaultFrameSamples
+	for i := 0; i < n; i++ {
+		out[i] = int16(in[0]) * 100
+	}
+	return n, nil
+}
+
+// pcmFrames 把 S16LE PCM 按 20ms 切分，返回每帧首个样本值
+func pcmFrames(t *testing.T, pcm []byte) []int16 {
+	t.Helper()
+	frameBytes := audioTapDefaultFrameSamples * 2
+	require.Zero(t, len(pcm)%frameBytes, "PCM 长度应为整帧")
+	frames := make([]int16, 0, len(pcm)/frameBytes)
+	for off := 0; off < len(pcm); off += frameBytes {
+		frames = append(frames, int16(binary.LittleEndian.Uint16(pcm[off:])))
+	}
+	return frames
+}
+
+func TestAudioTap_ReordersPackets(t *testing.T) {
+	tap := newAudioTap(fakeOpusDecoder{})
+
+	var pcm []byte
+	pcm = append(pcm, tap.Push(100, 0, []byte{1})...)
+	pcm = append(pcm, tap.Push(102, 1920, []byte{3})...)
+	pcm = append(pcm, tap.Push(101, 960, []byte{2})...)
+	pcm = append(pcm, tap.Push(101, 960, []byte{2})...) // 重复包被丢弃
+	pcm = append(pcm, tap.Flush()...)
+
+	assert.Equal(t, []int16{100, 200, 300}, pcmFrames(t, pcm))
+}
+
+func TestAudioTap_ConcealsLostPacket(t *testing.T) {
+	tap := newAudioTap(fakeOpusDecoder{})
+
+	var pcm []byte
+	pcm = append(pcm, tap.Push(1, 0, []byte{10})...)
+	// seq 2 丢失，后续包超过重排序深度后判定丢包
+	for i := 0; i <= audioTapJitterDepth; i++ {
+		seq := uint16(3 + i)
+		pcm = append(pcm, tap.Push(seq, uint32(seq-1)*960, []byte{20})...)
+	}
+
+	frames := pcmFrames(t, pcm)
+	require.GreaterOrEqual(t, len(frames), 3)
+	assert.Equal(t, int16(1000), frames[0])
+	assert.Equal(t, int16(500), frames[1], "丢失帧用上一帧衰减填补")
+	assert.Equal(t, int16(2000), frames[2])
+
+	// 迟到的 seq 2 已按丢包处理
+	assert.Empty(t, tap.Push(2, 960, []byte{30}))
+}
+
+func TestAudioTap_InsertsSilenceForDTXGap(t *testing.T) {
+	tap := newAudioTap(fakeOpusDecoder{})
+
+	var pcm []byte
+	pcm = append(pcm, tap.Push(1, 0, []byte{10})...)
+	// 序号连续但时间戳跳过 3 帧（DTX）
+	pcm = append(pcm, tap.Push(2, 4*960, []byte{10})...)
+	pcm = append(pcm, tap.Flush()...)
+
+	assert.Equal(t, []int16{1000, 0, 0, 0, 1000}, pcmFrames(t, pcm))
+}
+
+func TestAudioTap_CorruptPacketConcealedThenSilence(t *testing.T) {
+	tap := newAudioTap(fakeOpusDecoder{})
+
+	var pcm []byte
+	pcm = append(pcm, tap.Push(1, 0, []byte{80})...)
+	for i := 0; i < audioTapMaxConcealFrames+1; i++ {
+		seq := uint16(2 + i)
+		pcm = append(pcm, tap.Push(seq, uint32(seq-1)*960, []byte{0})...)
+	}
+	pcm = append(pcm, tap.Flush()...)
+
+	frames := pcmFrames(t, pcm)
+	require.Len(t, frames, audioTapMaxConcealFrames+2)
+	assert.Equal(t, []int16{8000, 4000, 2000, 1000, 500, 250, 0}, frames)
+}
+
+func TestAudioTap_DecodesOpusTo16kMono(t *testing.T) {
+	tap, err := NewAudioTap()
+	require.NoError(t, err)
+
+	// CELT 全频带 20ms 单帧（TOC=0xF8）
+	pcm := tap.Push(1, 0, []byte{0xF8, 0xFF, 0xFE})
+	pcm = append(pcm, tap.Flush()...)
+	assert.Len(t, pcm, aiAudioSampleRate/50*aiAudioChannels*2)
+	assert.Equal(t, 20, pcmDurationMs(len(pcm)))
+}
+
+func TestCircularBuffer_Drain(t *testing.T) {
+	cb := NewCircularBuffer(4, 0)
+	cb.Write([]byte{1, 2, 3, 4, 5})
+	assert.Equal(t, []byte{2, 3, 4, 5}, cb.Drain())
+	assert.Zero(t, cb.Count())
+	assert.Nil(t, cb.Drain())
+
+	cb.Write([]byte{6})
+	assert.Equal(t, []byte{6}, cb.Drain())
+}
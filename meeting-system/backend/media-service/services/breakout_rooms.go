@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/logger"
+)
+
+// MovePeers 分组讨论：不重建 PeerConnection，把用户在 fromRoomID 的 Peer 迁移到 toRoomID。
+// 原房间的订阅者移除其轨道，Peer 退订原房间的轨道与混音；随后在新房间重新发布其轨道并订阅新房间已有轨道，
+// 均通过 renegotiation 完成。返回迁移的 Peer 数。
+func (s *WebRTCService) MovePeers(fromRoomID, toRoomID, userID string) (int, error) {
+	if fromRoomID == toRoomID {
+		return 0, nil
+	}
+
+	s.roomsMux.RLock()
+	from := s.rooms[fromRoomID]
+	s.roomsMux.RUnlock()
+	if from == nil {
+		return 0, fmt.Errorf("room not found: %s", fromRoomID)
+	}
+
+	// 先从原房间 peers 中移除，避免迁移期间原房间新发布的轨道继续向其 AddTrack
+	from.PeersMux.Lock()
+	peers := make([]*Peer, 0, 1)
+	for peerID, peer := range from.Peers {
+		if peer != nil && peer.UserID == userID {
+			peers = append(peers, peer)
+			delete(from.Peers, peerID)
+		}
+	}
+	from.PeersMux.Unlock()
+
+	for _, peer := range peers {
+		s.movePeer(from, toRoomID, peer)
+	}
+	if len(peers) > 0 {
+		s.deleteRoomIfEmpty(fromRoomID)
+		logger.Info(fmt.Sprintf("Moved peers of user %s from room %s to %s (peers=%d)", userID, fromRoomID, toRoomID, len(peers)))
+	}
+	return len(peers), nil
+}
+
+// MoveMeetingPeers 处理信令服务的分组迁移事件（按会议找到本节点的房间）
+func (s *WebRTCService) MoveMeetingPeers(fromMeetingID, toMeetingID, userID uint) (int, error) {
+	return s.MovePeers(s.meetingRoomID(fromMeetingID), s.meetingRoomID(toMeetingID), strconv.FormatUint(uint64(userID), 10))
+}
+
+func (s *WebRTCService) movePeer(from *Room, toRoomID string, peer *Peer) {
+	// 1) 摘下其发布的轨道，并解除其在原房间轨道上的订阅
+	var published []*ForwardedTrack
+	var unsubscribed []*webrtc.RTPSender
+	detached := make(map[string]map[string]*webrtc.RTPSender)
+	from.TracksMux.Lock()
+	for key, ft := range from.Tracks {
+		if ft == nil {
+			continue
+		}
+		if ft.SenderPeer == peer.ID {
+			delete(from.Tracks, key)
+			published = append(published, ft)
+			detached[key] = ft.SubscriberSenders
+			ft.SubscriberSenders = make(map[string]*webrtc.RTPSender)
+			continue
+		}
+		if sender, ok := ft.SubscriberSenders[peer.ID]; ok {
+			delete(ft.SubscriberSenders, peer.ID)
+			if sender != nil {
+				unsubscribed = append(unsubscribed, sender)
+			}
+		}
+	}
+	from.TracksMux.Unlock()
+
+	mixSender := from.removeMixListener(peer.ID)
+	if mixSender != nil {
+		unsubscribed = append(unsubscribed, mixSender)
+	}
+	mixer := from.mixer()
+	for _, ft := range published {
+		s.detachTrackSubscribers(ft.Key, peer.ID, detached[ft.Key])
+		if mixer != nil {
+			mixer.RemoveTrack(ft.Key)
+		}
+	}
+	if peer.Connection != nil {
+		for _, sender := range unsubscribed {
+			if err := peer.Connection.RemoveTrack(sender); err != nil {
+				logger.Debug(fmt.Sprintf("RemoveTrack failed while moving peer %s: %v", peer.ID, err))
+			}
+		}
+	}
+
+	// 2) 加入新房间，在新房间重新发布（继承新房间的强制静音与屏幕共享状态）
+	peer.RoomID = toRoomID
+	s.addPeerToRoom(toRoomID, peer)
+	s.roomsMux.RLock()
+	to := s.rooms[toRoomID]
+	s.roomsMux.RUnlock()
+	if to == nil {
+		return
+	}
+
+	meetingID := s.roomMeetingID(toRoomID)
+	for _, ft := range published {
+		trackID, streamID := ft.LocalTrack.ID(), ""
+		if ft.RemoteTrack != nil {
+			trackID, streamID = ft.RemoteTrack.ID(), ft.RemoteTrack.StreamID()
+		}
+		publish := s.trackPublishState(to, peer.ID, ft.LocalTrack.Kind(), trackID, streamID)
+		ft.LocalTrack.SetMuted(publish.muted)
+		ft.LocalTrack.SetScreenSharePaused(publish.paused)
+
+		to.TracksMux.Lock()
+		to.Tracks[ft.Key] = ft
+		to.TracksMux.Unlock()
+		ft.roomID.Store(toRoomID)
+
+		if s.mediaProcessor != nil && ft.RemoteTrack != nil {
+			s.mediaProcessor.SetStreamSource(fmt.Sprintf("%s_%s", peer.ID, trackID), meetingID, peer.ID, trackID, ft.RemoteTrack.Kind())
+		}
+		s.bindTrackToRoomPeers(to, ft.Key, peer.ID, ft.RemoteSSRC, ft.LocalTrack)
+	}
+
+	// 3) 订阅新房间已有轨道；尚未 connected 的 Peer 由连接建立时统一订阅
+	peer.negotiationMux.Lock()
+	subscribed := peer.subscribedExistingTracks
+	peer.negotiationMux.Unlock()
+	if subscribed {
+		s.subscribePeerToExistingTracks(peer)
+	}
+	if mixSender != nil {
+		if err := s.SubscribePeerToRoomMix(peer.ID); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to resubscribe peer %s to room mix: %v", peer.ID, err))
+		}
+	}
+	if len(unsubscribed) > 0 {
+		s.RequestRenegotiation(peer.ID)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+	sharedmodels "meeting-system/shared/models"
+)
+
+func roomTrackKeys(svc *WebRTCService, roomID string) []string {
+	svc.roomsMux.RLock()
+	room := svc.rooms[roomID]
+	svc.roomsMux.RUnlock()
+	if room == nil {
+		return nil
+	}
+	room.TracksMux.RLock()
+	defer room.TracksMux.RUnlock()
+	keys := make([]string, 0, len(room.Tracks))
+	for key := range room.Tracks {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestWebRTCService_MovePeersBetweenRooms(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	peer := registerTestPeer(t, svc, "1", "9")
+	registerTestPeer(t, svc, "1", "7")
+	registerTestPeer(t, svc, "101", "10")
+
+	opus := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2}
+	audio, err := svc.publishExternalTrack("1", peer.ID, "audio", opus)
+	require.NoError(t, err)
+	_, err = svc.SetMediaModeration("101", "9", sharedmodels.ModerationMediaAudio, true)
+	require.NoError(t, err)
+
+	moved, err := svc.MoveMeetingPeers(1, 101, 9)
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	assert.Equal(t, "101", peer.RoomID)
+	assert.Empty(t, roomTrackKeys(svc, "1"))
+	assert.Equal(t, []string{"peer-9:audio"}, roomTrackKeys(svc, "101"))
+	assert.True(t, audio.Muted(), "继承分组房间的强制静音")
+
+	peers, err := svc.GetRoomPeers("1")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "7", peers[0].UserID)
+
+	// 关闭分组：迁回主会议，空的分组房间被删除
+	moved, err = svc.MovePeers("101", "1", "9")
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	assert.Equal(t, []string{"peer-9:audio"}, roomTrackKeys(svc, "1"))
+	assert.False(t, audio.Muted())
+
+	moved, err = svc.MovePeers("1", "101", "42")
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+	_, err = svc.MovePeers("missing", "1", "9")
+	assert.Error(t, err)
+}
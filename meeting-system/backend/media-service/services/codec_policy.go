@@ -0,0 +1,297 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+	"gorm.io/gorm"
+	"meeting-system/shared/logger"
+	sharedmodels "meeting-system/shared/models"
+)
+
+// ErrNoCodecAllowedByPolicy 客户端 Offer 中没有会议编解码策略允许的编码
+var ErrNoCodecAllowedByPolicy = errors.New("no codec in offer is allowed by meeting codec policy")
+
+// setRoomCodecPolicy 设置房间的编解码策略（覆盖会议设置中的策略），只对之后创建的 PeerConnection 生效；
+// 策略只来自会议设置，不对外暴露修改接口
+func (s *WebRTCService) setRoomCodecPolicy(roomID string, policy *sharedmodels.CodecPolicy) {
+	s.codecPoliciesMux.Lock()
+	s.codecPolicies[roomID] = policy
+	s.codecPoliciesMux.Unlock()
+
+	logger.Info(fmt.Sprintf("Codec policy updated for room %s", roomID))
+}
+
+// ClearMeetingCodecPolicy 会议设置变更时清除该会议房间缓存的编解码策略，之后加入的 Peer 重新从 MeetingSettings 加载；
+// 返回清除的房间数
+func (s *WebRTCService) ClearMeetingCodecPolicy(meetingID uint) int {
+	s.codecPoliciesMux.RLock()
+	roomIDs := make([]string, 0, len(s.codecPolicies))
+	for roomID := range s.codecPolicies {
+		roomIDs = append(roomIDs, roomID)
+	}
+	s.codecPoliciesMux.RUnlock()
+
+	cleared := 0
+	for _, roomID := range roomIDs {
+		if s.roomMeetingID(roomID) != meetingID {
+			continue
+		}
+		s.codecPoliciesMux.Lock()
+		delete(s.codecPolicies, roomID)
+		s.codecPoliciesMux.Unlock()
+		cleared++
+	}
+	if cleared > 0 {
+		logger.Info(fmt.Sprintf("Codec policy cache cleared for meeting %d (%d rooms)", meetingID, cleared))
+	}
+	return cleared
+}
+
+// roomCodecPolicy 获取房间的编解码策略；首次访问时从所属会议的 MeetingSettings 加载并缓存到房间清空或会议设置变更为止
+func (s *WebRTCService) roomCodecPolicy(roomID string) *sharedmodels.CodecPolicy {
+	s.codecPoliciesMux.RLock()
+	policy, ok := s.codecPolicies[roomID]
+	s.codecPoliciesMux.RUnlock()
+	if ok {
+		return policy
+	}
+
+	policy = s.loadMeetingCodecPolicy(roomID)
+
+	s.codecPoliciesMux.Lock()
+	defer s.codecPoliciesMux.Unlock()
+	if existing, ok := s.codecPolicies[roomID]; ok {
+		return existing
+	}
+	s.codecPolicies[roomID] = policy
+	return policy
+}
+
+// loadMeetingCodecPolicy 通过 meeting_rooms 找到房间所属会议并解析其设置中的编解码策略
+func (s *WebRTCService) loadMeetingCodecPolicy(roomID string) *sharedmodels.CodecPolicy {
+	if s.mediaService == nil || s.mediaService.db == nil {
+		return nil
+	}
+
+	var meeting sharedmodels.Meeting
+	err := s.mediaService.db.Model(&sharedmodels.Meeting{}).
+		Select("meetings.settings").
+		Joins("JOIN meeting_rooms ON meeting_rooms.meeting_id = meetings.id").
+		Where("meeting_rooms.room_id = ?", roomID).
+		Take(&meeting).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn(fmt.Sprintf("Failed to load meeting settings for room %s: %v", roomID, err))
+		}
+		return nil
+	}
+	if meeting.Settings == "" {
+		return nil
+	}
+
+	var settings sharedmodels.MeetingSettings
+	if err := json.Unmarshal([]byte(meeting.Settings), &settings); err != nil {
+		logger.Warn(fmt.Sprintf("Invalid meeting settings for room %s: %v", roomID, err))
+		return nil
+	}
+	return settings.Codecs
+}
+
+// applyCodecPolicy 在 SetRemoteDescription 之后、CreateAnswer 之前，按策略设置每个 transceiver 的编码偏好；
+// preferRED 时把 RED 排在音频编码首位。
+func applyCodecPolicy(pc *webrtc.PeerConnection, policy *sharedmodels.CodecPolicy, preferRED bool) error {
+	if policy == nil && !preferRED {
+		return nil
+	}
+
+	for _, transceiver := range pc.GetTransceivers() {
+		receiver := transceiver.Receiver()
+		if receiver == nil {
+			continue
+		}
+		kind := transceiver.Kind()
+		if policy == nil && kind != webrtc.RTPCodecTypeAudio {
+			continue
+		}
+
+		// 协商后 receiver 参数即为双方都支持的编码（PayloadType 与客户端 Offer 一致）
+		preferences := receiver.GetParameters().Codecs
+		if policy != nil {
+			preferences = codecPolicyPreferences(policy, kind, preferences)
+			if len(preferences) == 0 {
+				return fmt.Errorf("%w: %s", ErrNoCodecAllowedByPolicy, kind)
+			}
+		}
+		if preferRED && kind == webrtc.RTPCodecTypeAudio {
+			preferences = preferAudioRED(preferences)
+		}
+		if len(preferences) == 0 {
+			continue
+		}
+		if err := transceiver.SetCodecPreferences(preferences); err != nil {
+			return fmt.Errorf("failed to set codec preferences: %w", err)
+		}
+	}
+	return nil
+}
+
+// codecPolicyPreferences 按策略从协商出的编码中筛选、排序并改写 fmtp
+// rtx/red/ulpfec 等辅助编码只在其关联的主编码被保留时保留。
+func codecPolicyPreferences(policy *sharedmodels.CodecPolicy, kind webrtc.RTPCodecType, negotiated []webrtc.RTPCodecParameters) []webrtc.RTPCodecParameters {
+	var allowed []string
+	switch kind {
+	case webrtc.RTPCodecTypeVideo:
+		allowed = policy.Video
+	case webrtc.RTPCodecTypeAudio:
+		allowed = policy.Audio
+	}
+
+	rank := func(codec webrtc.RTPCodecParameters) (int, bool) {
+		if len(allowed) == 0 {
+			return 0, true
+		}
+		name := codecName(codec.MimeType)
+		for i, a := range allowed {
+			if strings.EqualFold(a, name) {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	type rankedCodec struct {
+		codec webrtc.RTPCodecParameters
+		rank  int
+	}
+	primaries := make([]rankedCodec, 0, len(negotiated))
+	var auxiliaries []webrtc.RTPCodecParameters
+	for _, codec := range negotiated {
+		if isAuxiliaryCodec(codec.MimeType) {
+			auxiliaries = append(auxiliaries, codec)
+			continue
+		}
+		r, ok := rank(codec)
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(codec.MimeType, webrtc.MimeTypeH264) && policy.H264ProfileLevelID != "" &&
+			!strings.EqualFold(fmtpValue(codec.SDPFmtpLine, "profile-level-id"), policy.H264ProfileLevelID) {
+			continue
+		}
+		if strings.EqualFold(codec.MimeType, webrtc.MimeTypeOpus) && policy.Opus != nil {
+			codec.SDPFmtpLine = opusFmtpLine(codec.SDPFmtpLine, policy.Opus)
+		}
+		primaries = append(primaries, rankedCodec{codec: codec, rank: r})
+	}
+	if len(primaries) == 0 {
+		return nil
+	}
+	sort.SliceStable(primaries, func(i, j int) bool { return primaries[i].rank < primaries[j].rank })
+
+	kept := make(map[string]bool, len(primaries))
+	preferences := make([]webrtc.RTPCodecParameters, 0, len(negotiated))
+	for _, p := range primaries {
+		kept[fmt.Sprint(p.codec.PayloadType)] = true
+		preferences = append(preferences, p.codec)
+	}
+	for _, codec := range auxiliaries {
+		if auxiliaryCodecKept(codec, kept) {
+			preferences = append(preferences, codec)
+		}
+	}
+	return preferences
+}
+
+// isAuxiliaryCodec 重传/冗余/FEC 等不单独承载媒体的编码
+func isAuxiliaryCodec(mimeType string) bool {
+	switch strings.ToLower(codecName(mimeType)) {
+	case "rtx", "red", "ulpfec", "flexfec-03":
+		return true
+	default:
+		return false
+	}
+}
+
+// auxiliaryCodecKept rtx 看 apt，audio/red 看 fmtp 中的冗余编码，其余（ulpfec 等）随主编码保留
+func auxiliaryCodecKept(codec webrtc.RTPCodecParameters, kept map[string]bool) bool {
+	switch strings.ToLower(codecName(codec.MimeType)) {
+	case "rtx":
+		return kept[fmtpValue(codec.SDPFmtpLine, "apt")]
+	case "red":
+		if codec.SDPFmtpLine == "" {
+			return true
+		}
+		for _, pt := range strings.Split(codec.SDPFmtpLine, "/") {
+			if !kept[strings.TrimSpace(pt)] {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// opusFmtpLine 在保留原有参数顺序的前提下改写 Opus 的 usedtx/useinbandfec/stereo
+func opusFmtpLine(line string, opts *sharedmodels.OpusCodecOptions) string {
+	overrides := make(map[string]string, 4)
+	setFlag := func(key string, v *bool) {
+		if v == nil {
+			return
+		}
+		if *v {
+			overrides[key] = "1"
+		} else {
+			overrides[key] = "0"
+		}
+	}
+	setFlag("usedtx", opts.DTX)
+	setFlag("useinbandfec", opts.FEC)
+	setFlag("stereo", opts.Stereo)
+	setFlag("sprop-stereo", opts.Stereo)
+
+	params := make([]string, 0, 8)
+	seen := make(map[string]bool, len(overrides))
+	for _, p := range strings.Split(line, ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		key := strings.ToLower(strings.SplitN(p, "=", 2)[0])
+		if v, ok := overrides[key]; ok {
+			p = key + "=" + v
+			seen[key] = true
+		}
+		params = append(params, p)
+	}
+	for _, key := range []string{"useinbandfec", "usedtx", "stereo", "sprop-stereo"} {
+		if v, ok := overrides[key]; ok && !seen[key] {
+			params = append(params, key+"="+v)
+		}
+	}
+	return strings.Join(params, ";")
+}
+
+// codecName 从 MIME 类型中取出编码名，如 video/H264 -> H264
+func codecName(mimeType string) string {
+	if i := strings.IndexByte(mimeType, '/'); i >= 0 {
+		return mimeType[i+1:]
+	}
+	return mimeType
+}
+
+// fmtpValue 读取 fmtp 行中指定参数的值
+func fmtpValue(line, key string) string {
+	for _, p := range strings.Split(line, ";") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) == 2 && strings.EqualFold(kv[0], key) {
+			return kv[1]
+		}
+	}
+	return ""
+}
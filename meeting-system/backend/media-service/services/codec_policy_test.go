@@ -0,0 +1,184 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+	sharedmodels "meeting-system/shared/models"
+)
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestCodecPolicyPreferences_OrderingAndAuxiliary(t *testing.T) {
+	negotiated := []webrtc.RTPCodecParameters{
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, PayloadType: 96},
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "video/rtx", ClockRate: 90000, SDPFmtpLine: "apt=96"}, PayloadType: 97},
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264, ClockRate: 90000, SDPFmtpLine: "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f"}, PayloadType: 102},
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "video/rtx", ClockRate: 90000, SDPFmtpLine: "apt=102"}, PayloadType: 103},
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264, ClockRate: 90000, SDPFmtpLine: "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=640032"}, PayloadType: 112},
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP9, ClockRate: 90000, SDPFmtpLine: "profile-id=0"}, PayloadType: 98},
+	}
+
+	// 只允许 H264 42e01f，其余编码与不相关的 rtx 被过滤
+	prefs := codecPolicyPreferences(&sharedmodels.CodecPolicy{Video: []string{"h264"}, H264ProfileLevelID: "42E01F"}, webrtc.RTPCodecTypeVideo, negotiated)
+	require.Len(t, prefs, 2)
+	assert.Equal(t, webrtc.PayloadType(102), prefs[0].PayloadType)
+	assert.Equal(t, webrtc.PayloadType(103), prefs[1].PayloadType, "保留关联主编码的 rtx")
+
+	// 排序：VP9 优先于 VP8
+	prefs = codecPolicyPreferences(&sharedmodels.CodecPolicy{Video: []string{"VP9", "VP8"}}, webrtc.RTPCodecTypeVideo, negotiated)
+	require.Len(t, prefs, 3)
+	assert.Equal(t, webrtc.MimeTypeVP9, prefs[0].MimeType)
+	assert.Equal(t, webrtc.MimeTypeVP8, prefs[1].MimeType)
+	assert.Equal(t, "apt=96", prefs[2].SDPFmtpLine)
+
+	// 不限制视频时保留全部编码
+	prefs = codecPolicyPreferences(&sharedmodels.CodecPolicy{Audio: []string{"opus"}}, webrtc.RTPCodecTypeVideo, negotiated)
+	assert.Len(t, prefs, len(negotiated))
+
+	// 没有允许的编码
+	prefs = codecPolicyPreferences(&sharedmodels.CodecPolicy{Video: []string{"AV1"}}, webrtc.RTPCodecTypeVideo, negotiated)
+	assert.Empty(t, prefs)
+}
+
+func TestOpusFmtpLine(t *testing.T) {
+	line := opusFmtpLine("minptime=10;useinbandfec=1", &sharedmodels.OpusCodecOptions{
+		DTX:    boolPtr(true),
+		FEC:    boolPtr(false),
+		Stereo: boolPtr(true),
+	})
+	assert.Equal(t, "minptime=10;useinbandfec=0;usedtx=1;stereo=1;sprop-stereo=1", line)
+
+	assert.Equal(t, "minptime=10;useinbandfec=1", opusFmtpLine("minptime=10;useinbandfec=1", &sharedmodels.OpusCodecOptions{}))
+}
+
+func TestCodecPolicy_Validate(t *testing.T) {
+	assert.NoError(t, (*sharedmodels.CodecPolicy)(nil).Validate())
+	assert.NoError(t, (&sharedmodels.CodecPolicy{Video: []string{"h264", "AV1"}, Audio: []string{"opus"}, H264ProfileLevelID: "42e01f"}).Validate())
+	assert.Error(t, (&sharedmodels.CodecPolicy{Video: []string{"H265"}}).Validate())
+	assert.Error(t, (&sharedmodels.CodecPolicy{Audio: []string{"aac"}}).Validate())
+	assert.Error(t, (&sharedmodels.CodecPolicy{H264ProfileLevelID: "42e01"}).Validate())
+}
+
+// newCodecPolicyTestOffer 使用 pion 默认编解码器创建包含音视频的客户端 Offer
+func newCodecPolicyTestOffer(t *testing.T) *webrtc.SessionDescription {
+	client, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio)
+	require.NoError(t, err)
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeVideo)
+	require.NoError(t, err)
+
+	offer, err := client.CreateOffer(nil)
+	require.NoError(t, err)
+	return &offer
+}
+
+// answerCodecs 解析 Answer 中每个媒体段的编码名与 fmtp
+func answerCodecs(t *testing.T, answer *webrtc.SessionDescription) map[string][]sdp.Codec {
+	parsed, err := answer.Unmarshal()
+	require.NoError(t, err)
+
+	out := make(map[string][]sdp.Codec)
+	for _, media := range parsed.MediaDescriptions {
+		for _, format := range media.MediaName.Formats {
+			pt, err := strconv.Atoi(format)
+			require.NoError(t, err)
+			codec, err := parsed.GetCodecForPayloadType(uint8(pt))
+			require.NoError(t, err)
+			out[media.MediaName.Media] = append(out[media.MediaName.Media], codec)
+		}
+	}
+	return out
+}
+
+// TestCreateAnswer_AppliesRoomCodecPolicy Answer 只包含策略允许的编码，Opus fmtp 按策略改写
+func TestCreateAnswer_AppliesRoomCodecPolicy(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	svc.setRoomCodecPolicy("room-h264", &sharedmodels.CodecPolicy{
+		Video:              []string{"H264"},
+		Audio:              []string{"opus"},
+		H264ProfileLevelID: "42e01f",
+		Opus:               &sharedmodels.OpusCodecOptions{DTX: boolPtr(true), Stereo: boolPtr(true)},
+	})
+
+	answer, _, err := svc.CreateAnswer("room-h264", "user-1", newCodecPolicyTestOffer(t))
+	require.NoError(t, err)
+
+	codecs := answerCodecs(t, answer)
+	require.NotEmpty(t, codecs["video"])
+	for _, c := range codecs["video"] {
+		if strings.EqualFold(c.Name, "rtx") {
+			continue
+		}
+		assert.True(t, strings.EqualFold(c.Name, "H264"), "unexpected video codec %s", c.Name)
+		assert.Contains(t, c.Fmtp, "profile-level-id=42e01f")
+	}
+
+	require.NotEmpty(t, codecs["audio"])
+	for _, c := range codecs["audio"] {
+		assert.True(t, strings.EqualFold(c.Name, "opus"), "unexpected audio codec %s", c.Name)
+		assert.Contains(t, c.Fmtp, "usedtx=1")
+		assert.Contains(t, c.Fmtp, "stereo=1")
+	}
+
+	// 未设置策略的房间保持默认编码
+	answer, _, err = svc.CreateAnswer("room-default", "user-2", newCodecPolicyTestOffer(t))
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, c := range answerCodecs(t, answer)["video"] {
+		names[strings.ToUpper(c.Name)] = true
+	}
+	assert.True(t, names["VP8"])
+	assert.True(t, names["H264"])
+}
+
+// TestCreateAnswer_RejectsOfferWithoutAllowedCodec 客户端不支持策略要求的编码时拒绝加入
+func TestCreateAnswer_RejectsOfferWithoutAllowedCodec(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	mediaEngine := &webrtc.MediaEngine{}
+	require.NoError(t, mediaEngine.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+		PayloadType:        96,
+	}, webrtc.RTPCodecTypeVideo))
+	client, err := webrtc.NewAPI(webrtc.WithMediaEngine(mediaEngine)).NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeVideo)
+	require.NoError(t, err)
+	offer, err := client.CreateOffer(nil)
+	require.NoError(t, err)
+
+	svc.setRoomCodecPolicy("room-av1", &sharedmodels.CodecPolicy{Video: []string{"AV1"}})
+	_, _, err = svc.CreateAnswer("room-av1", "user-1", &offer)
+	assert.ErrorIs(t, err, ErrNoCodecAllowedByPolicy)
+
+	_, err = svc.GetRoomPeers("room-av1")
+	assert.Error(t, err, "被拒绝的 Peer 不应加入房间")
+}
+
+// TestClearMeetingCodecPolicy 会议设置变更只清除该会议房间的缓存策略
+func TestClearMeetingCodecPolicy(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	svc.setRoomCodecPolicy("room_42_1_000001", &sharedmodels.CodecPolicy{Video: []string{"H264"}})
+	svc.setRoomCodecPolicy("room_43_1_000001", &sharedmodels.CodecPolicy{Video: []string{"VP8"}})
+
+	assert.Equal(t, 1, svc.ClearMeetingCodecPolicy(42))
+	assert.Nil(t, svc.roomCodecPolicy("room_42_1_000001"), "重新加载（无数据库时为默认编码）")
+	require.NotNil(t, svc.roomCodecPolicy("room_43_1_000001"))
+	assert.Equal(t, []string{"VP8"}, svc.roomCodecPolicy("room_43_1_000001").Video)
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -441,3 +443,34 @@ func (s *FFmpegService) ExtractAudioFromVideo(videoData []byte) ([]byte, error)
 
 	return outputData, nil
 }
+
+// DecodeVideoFrame 解码编码帧序列中的第 frameIndex 帧为 PNG（AI 抽帧用，经 stdin/stdout 传输不落盘）
+// inputFormat 为 ffmpeg demuxer 名称，如 h264（Annex-B）、ivf（VP8）。
+func (s *FFmpegService) DecodeVideoFrame(ctx context.Context, inputFormat string, data []byte, frameIndex int) ([]byte, error) {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-f", inputFormat,
+		"-i", "pipe:0",
+		"-vf", fmt.Sprintf("select=eq(n\\,%d)", frameIndex), // 只输出目标帧
+		"-vsync", "0",
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-vcodec", "png",
+		"pipe:1",
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("video frame decode failed: %v: %s", err, stderr.String())
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("video frame decode produced no output")
+	}
+
+	return stdout.Bytes(), nil
+}
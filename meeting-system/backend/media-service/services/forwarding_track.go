@@ -0,0 +1,661 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+)
+
+const (
+	// defaultKeyframeCacheMaxPackets 关键帧缓存（当前 GOP）最多保留的 RTP 包数，超过后缓存失效直到下一个关键帧
+	defaultKeyframeCacheMaxPackets = 1024
+	// keyframeCacheMaxBytes 关键帧缓存的 payload 总字节上限
+	keyframeCacheMaxBytes = 4 * 1024 * 1024
+)
+
+// 暂停转发的原因（可叠加，全部解除后才恢复转发）
+const (
+	// holdModeration 主持人强制静音/关闭画面
+	holdModeration uint32 = 1 << iota
+	// holdScreenShare 独占屏幕共享模式下他人正在共享
+	holdScreenShare
+)
+
+var errForwardingTrackUnsupportedCodec = errors.New("forwarding track: unsupported codec")
+
+// ForwardingTrack SFU 转发用的本地轨道
+// 与 TrackLocalStaticRTP 一样把一个 remote track fan-out 到多个 PeerConnection，
+// 区别在于每个绑定（订阅者）维护独立的发送状态，从而可以对新绑定的订阅者单独回放关键帧缓存。
+//
+// WriteRTP 只允许由该轨道唯一的转发协程（forwardRTP）调用；Bind/Unbind 由 PeerConnection 在协商时调用。
+type ForwardingTrack struct {
+	mu       sync.RWMutex
+	bindings map[string]*forwardingBinding
+	codec    webrtc.RTPCodecCapability
+	id       string
+	streamID string
+
+	// keyframes 视频轨道的关键帧缓存；音频轨道为 nil
+	keyframes *keyframeCache
+	// svc VP9/AV1 轨道的分层跟踪；其它编码为 nil
+	svc *svcTracker
+	// srcDDExtID 发布者协商的 AV1 Dependency Descriptor 扩展 ID（0 表示未协商）
+	srcDDExtID uint8
+	// holds 暂停转发的原因位，非 0 时丢弃发布者的包
+	holds atomic.Uint32
+
+	// screenShare 屏幕共享轨道（创建后、开始转发前标记），contentHint 为共享者声明的内容提示
+	screenShare bool
+	contentHint atomic.Value
+	// lastKeyframeRequest 最近一次转发给发布者的关键帧请求（UnixNano），用于限制屏幕共享的关键帧频率
+	lastKeyframeRequest atomic.Int64
+	// lastBitrateReport 最近一次向发布者发送码率上限（REMB）的时间（UnixNano），仅非 SVC 轨道使用
+	lastBitrateReport atomic.Int64
+	// frames 发布者发来的视频帧数（按 RTP marker 计数，包含暂停转发期间的帧）
+	frames atomic.Uint64
+}
+
+// forwardingBinding 单个 PeerConnection 的绑定状态（除创建外只由转发协程读写）
+type forwardingBinding struct {
+	id          string
+	ssrc        webrtc.SSRC
+	payloadType webrtc.PayloadType
+	writeStream webrtc.TrackLocalWriter
+
+	// needsReplay 新绑定的订阅者在收到第一个实时包之前先回放关键帧缓存；
+	// replayed 为已成功回放的缓存包数，回放中途失败时下一个包从这里继续，已发送的包不重发
+	needsReplay bool
+	replayed    int
+
+	// stripRED 发布者发送 RED 而订阅者未协商 RED 时，只转发主编码 Opus
+	stripRED bool
+	// redBlockPT 订阅者 RED 内部的 Opus PayloadType；与发布者不同时改写 RED 头部，0 表示不改写
+	redBlockPT uint8
+
+	// svc 绑定的是 SVC 轨道：按分层转发，头扩展只保留按订阅者 ID 重写的 DD；非 SVC 轨道原样转发头扩展
+	svc bool
+	// ddExtID 订阅者协商的 Dependency Descriptor 扩展 ID（0 表示不转发 DD）
+	ddExtID uint8
+
+	// SVC 分层选择：requested 为订阅者请求的上限，bandwidthBps 为 REMB 估计（0 表示未知），
+	// target 为两者共同决定的目标分层，current 为实际生效的分层（只在画面起始/关键帧处切换）；
+	// current 只由转发协程读写，其它协程通过 published 读取
+	requested    SVCLayer
+	bandwidthBps uint64
+	target       SVCLayer
+	current      SVCLayer
+	published    atomic.Uint32
+	// seqOffset 丢弃的包数，用于保持订阅者侧序列号连续
+	seqOffset uint16
+}
+
+// NewForwardingTrack 创建转发轨道；maxCachedPackets<=0 时使用默认的关键帧缓存上限
+func NewForwardingTrack(codec webrtc.RTPCodecCapability, id, streamID string, maxCachedPackets int) *ForwardingTrack {
+	t := &ForwardingTrack{
+		bindings: make(map[string]*forwardingBinding),
+		codec:    codec,
+		id:       id,
+		streamID: streamID,
+	}
+	if strings.HasPrefix(strings.ToLower(codec.MimeType), "video/") {
+		if maxCachedPackets <= 0 {
+			maxCachedPackets = defaultKeyframeCacheMaxPackets
+		}
+		t.keyframes = newKeyframeCache(codec.MimeType, maxCachedPackets, keyframeCacheMaxBytes)
+	}
+	if isSVCCodec(codec.MimeType) {
+		t.svc = newSVCTracker(codec.MimeType)
+	}
+	return t
+}
+
+// Bind 在协商完成后由 PeerConnection 调用，记录该订阅者的 SSRC 与 PayloadType
+func (t *ForwardingTrack) Bind(ctx webrtc.TrackLocalContext) (webrtc.RTPCodecParameters, error) {
+	binding := &forwardingBinding{
+		id:          ctx.ID(),
+		ssrc:        ctx.SSRC(),
+		writeStream: ctx.WriteStream(),
+		needsReplay: t.keyframes != nil,
+		svc:         t.svc != nil,
+		ddExtID:     headerExtensionID(ctx.HeaderExtensions(), av1DependencyDescriptorURI),
+		requested:   svcAllLayers,
+		target:      svcAllLayers,
+		current:     svcAllLayers,
+	}
+	binding.published.Store(packSVCLayer(svcAllLayers))
+
+	codec, ok := matchForwardingCodec(t.codec, ctx.CodecParameters())
+	switch {
+	case ok && isAudioRED(t.codec.MimeType):
+		binding.redBlockPT = redBlockPayloadType(codec.SDPFmtpLine)
+	case !ok && isAudioRED(t.codec.MimeType):
+		// 订阅者未协商 RED：降级为 Opus，转发时剥离冗余块
+		codec, ok = matchForwardingCodec(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, ctx.CodecParameters())
+		binding.stripRED = ok
+	}
+	if !ok {
+		return webrtc.RTPCodecParameters{}, errForwardingTrackUnsupportedCodec
+	}
+	binding.payloadType = codec.PayloadType
+
+	t.mu.Lock()
+	t.bindings[ctx.ID()] = binding
+	t.mu.Unlock()
+
+	return codec, nil
+}
+
+// Unbind 订阅者移除轨道时调用
+func (t *ForwardingTrack) Unbind(ctx webrtc.TrackLocalContext) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.bindings[ctx.ID()]; !ok {
+		return webrtc.ErrUnbindFailed
+	}
+	delete(t.bindings, ctx.ID())
+	return nil
+}
+
+// ID 轨道ID
+func (t *ForwardingTrack) ID() string { return t.id }
+
+// RID 转发轨道不区分 simulcast 层
+func (t *ForwardingTrack) RID() string { return "" }
+
+// StreamID 轨道所属的 MediaStream
+func (t *ForwardingTrack) StreamID() string { return t.streamID }
+
+// Codec 轨道编码
+func (t *ForwardingTrack) Codec() webrtc.RTPCodecCapability { return t.codec }
+
+// Kind 音频或视频
+func (t *ForwardingTrack) Kind() webrtc.RTPCodecType {
+	switch {
+	case strings.HasPrefix(strings.ToLower(t.codec.MimeType), "audio/"):
+		return webrtc.RTPCodecTypeAudio
+	case strings.HasPrefix(strings.ToLower(t.codec.MimeType), "video/"):
+		return webrtc.RTPCodecTypeVideo
+	default:
+		return webrtc.RTPCodecType(0)
+	}
+}
+
+// SetSourceHeaderExtensions 记录发布者协商的头扩展 ID（需在转发协程启动前调用）
+func (t *ForwardingTrack) SetSourceHeaderExtensions(exts []webrtc.RTPHeaderExtensionParameter) {
+	t.srcDDExtID = headerExtensionID(exts, av1DependencyDescriptorURI)
+}
+
+// IsRED 发布者是否以 RED 封装发送音频
+func (t *ForwardingTrack) IsRED() bool { return isAudioRED(t.codec.MimeType) }
+
+// HasKeyframe 关键帧缓存是否可用于新订阅者（可用时无需向发布者发送 PLI）
+func (t *ForwardingTrack) HasKeyframe() bool {
+	return t.keyframes != nil && t.keyframes.ready()
+}
+
+// SVCStats 轨道可用的 SVC 分层；非 SVC 轨道或尚未收到分层信息时返回 nil
+func (t *ForwardingTrack) SVCStats() *SVCLayerStats {
+	if t.svc == nil {
+		return nil
+	}
+	return t.svc.stats()
+}
+
+// SubscriberLayers 各订阅者（按订阅者侧 SSRC）当前生效的分层
+func (t *ForwardingTrack) SubscriberLayers() map[webrtc.SSRC]SVCLayer {
+	if t.svc == nil {
+		return nil
+	}
+	available, ok := t.svc.available()
+	if !ok {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	layers := make(map[webrtc.SSRC]SVCLayer, len(t.bindings))
+	for _, b := range t.bindings {
+		layers[b.ssrc] = minSVCLayer(b.currentLayer(), available)
+	}
+	return layers
+}
+
+// SetSubscriberMaxLayer 设置订阅者请求的分层上限（如根据渲染分辨率），返回是否需要关键帧才能升到目标空间层
+func (t *ForwardingTrack) SetSubscriberMaxLayer(ssrc webrtc.SSRC, limit SVCLayer) (needsKeyframe bool, ok bool) {
+	return t.updateSubscriberLayer(ssrc, func(b *forwardingBinding) { b.requested = clampSVCLayer(limit) })
+}
+
+// SetSubscriberMaxHeight 按订阅者请求的最大分辨率高度选择空间层，temporal<0 表示不限制时间层
+func (t *ForwardingTrack) SetSubscriberMaxHeight(ssrc webrtc.SSRC, maxHeight, temporal int) (needsKeyframe bool, ok bool) {
+	if t.svc == nil {
+		return false, false
+	}
+	limit := SVCLayer{Spatial: t.svc.spatialForHeight(maxHeight), Temporal: svcMaxLayers - 1}
+	if temporal >= 0 {
+		limit.Temporal = temporal
+	}
+	return t.SetSubscriberMaxLayer(ssrc, limit)
+}
+
+// SetSubscriberBandwidth 非 SVC 轨道记录订阅者分得的带宽，返回各订阅者份额的最小值（发布者应限制的码率）
+func (t *ForwardingTrack) SetSubscriberBandwidth(ssrc webrtc.SSRC, bps uint64) (limit uint64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range t.bindings {
+		if b.ssrc == ssrc {
+			b.bandwidthBps = bps
+			ok = true
+		}
+		if b.bandwidthBps > 0 && (limit == 0 || b.bandwidthBps < limit) {
+			limit = b.bandwidthBps
+		}
+	}
+	return limit, ok
+}
+
+// SetSubscriberBitrate 根据订阅者的带宽估计（REMB）选择分层
+func (t *ForwardingTrack) SetSubscriberBitrate(ssrc webrtc.SSRC, bps uint64) (needsKeyframe bool, ok bool) {
+	return t.updateSubscriberLayer(ssrc, func(b *forwardingBinding) { b.bandwidthBps = bps })
+}
+
+func (t *ForwardingTrack) updateSubscriberLayer(ssrc webrtc.SSRC, update func(b *forwardingBinding)) (bool, bool) {
+	if t.svc == nil {
+		return false, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range t.bindings {
+		if b.ssrc != ssrc {
+			continue
+		}
+		update(b)
+		previous := b.target
+		b.target = b.requested
+		if b.bandwidthBps > 0 {
+			b.target = t.svc.layerForBitrate(b.bandwidthBps, b.requested)
+		}
+		// 只在目标变化时请求关键帧，避免每个 REMB 都触发 PLI
+		return b.target != previous && b.target.Spatial > b.currentLayer().Spatial, true
+	}
+	return false, false
+}
+
+// WriteRTP 将发布者的 RTP 包写入所有绑定；新绑定的订阅者先收到关键帧缓存的回放
+func (t *ForwardingTrack) WriteRTP(pkt *rtp.Packet) error {
+	if pkt == nil {
+		return nil
+	}
+	if pkt.Marker && t.keyframes != nil {
+		t.frames.Add(1)
+	}
+
+	if t.holds.Load() != 0 {
+		// 丢弃的包不占用订阅者侧序列号，避免订阅者对其 NACK
+		t.mu.RLock()
+		for _, b := range t.bindings {
+			b.seqOffset++
+		}
+		t.mu.RUnlock()
+		return nil
+	}
+
+	keyframeStart := t.keyframes != nil && t.keyframes.startsNewGOP(pkt)
+	info := t.packetInfo(pkt, true)
+
+	var writeErrs []error
+	t.mu.RLock()
+	var cached []*rtp.Packet
+	if t.keyframes != nil && !keyframeStart {
+		for _, b := range t.bindings {
+			if b.needsReplay {
+				cached = t.keyframes.snapshot()
+				break
+			}
+		}
+	}
+	for _, b := range t.bindings {
+		if b.needsReplay {
+			switch {
+			case keyframeStart:
+				// 实时流本身就是关键帧，直接从这里开始转发
+				b.needsReplay = false
+			case len(cached) > 0:
+				if err := t.replay(b, cached); err != nil {
+					// 绑定尚未就绪（RTPSender 仍在 Send 中），下一个包再重试
+					continue
+				}
+				b.needsReplay = false
+			default:
+				// 没有可用缓存：退化为等待发布者的下一个关键帧（由 PLI 触发）
+				b.needsReplay = false
+			}
+		}
+
+		if err := b.write(&pkt.Header, pkt.Payload, pkt.SequenceNumber, &info); err != nil {
+			writeErrs = append(writeErrs, err)
+		}
+	}
+	t.mu.RUnlock()
+
+	if t.keyframes != nil {
+		if keyframeStart {
+			t.keyframes.reset(pkt)
+		} else {
+			t.keyframes.append(pkt)
+		}
+	}
+
+	return errors.Join(writeErrs...)
+}
+
+// SetMuted 服务端强制静音/解除，返回状态是否变化。
+// 静音时清空关键帧缓存，避免新订阅者回放静音前的画面；解除后视频需由调用方向发布者请求关键帧。
+func (t *ForwardingTrack) SetMuted(muted bool) bool {
+	return t.setHold(holdModeration, muted)
+}
+
+// Muted 是否被服务端强制静音
+func (t *ForwardingTrack) Muted() bool { return t.holds.Load()&holdModeration != 0 }
+
+// SetScreenSharePaused 独占屏幕共享模式下暂停/恢复转发，返回状态是否变化
+func (t *ForwardingTrack) SetScreenSharePaused(paused bool) bool {
+	return t.setHold(holdScreenShare, paused)
+}
+
+// Suppressed 是否暂停转发（被强制静音或屏幕共享被接管）
+func (t *ForwardingTrack) Suppressed() bool { return t.holds.Load() != 0 }
+
+// Frames 收到的视频帧数（音频轨道为 0）
+func (t *ForwardingTrack) Frames() uint64 { return t.frames.Load() }
+
+func (t *ForwardingTrack) setHold(reason uint32, on bool) bool {
+	for {
+		old := t.holds.Load()
+		next := old &^ reason
+		if on {
+			next |= reason
+		}
+		if next == old {
+			return false
+		}
+		if t.holds.CompareAndSwap(old, next) {
+			if old == 0 && t.keyframes != nil {
+				t.keyframes.invalidate()
+			}
+			return true
+		}
+	}
+}
+
+// MarkScreenShare 把轨道标记为屏幕共享，只能在开始转发前调用
+func (t *ForwardingTrack) MarkScreenShare(contentHint string) {
+	t.screenShare = true
+	t.SetContentHint(contentHint)
+}
+
+// IsScreenShare 是否为屏幕共享轨道
+func (t *ForwardingTrack) IsScreenShare() bool { return t.screenShare }
+
+// SetContentHint 更新屏幕共享的内容提示（motion/detail/text）
+func (t *ForwardingTrack) SetContentHint(hint string) { t.contentHint.Store(hint) }
+
+// ContentHint 屏幕共享的内容提示，未声明时为空
+func (t *ForwardingTrack) ContentHint() string {
+	hint, _ := t.contentHint.Load().(string)
+	return hint
+}
+
+// AllowKeyframeRequest 距上次转发的关键帧请求超过 minInterval 时返回 true 并记录本次请求
+func (t *ForwardingTrack) AllowKeyframeRequest(now time.Time, minInterval time.Duration) bool {
+	return allowAfter(&t.lastKeyframeRequest, now, minInterval)
+}
+
+// AllowBitrateReport 距上次向发布者发送码率上限超过 minInterval 时返回 true 并记录本次发送
+func (t *ForwardingTrack) AllowBitrateReport(now time.Time, minInterval time.Duration) bool {
+	return allowAfter(&t.lastBitrateReport, now, minInterval)
+}
+
+// allowAfter 距 last 记录的时间超过 minInterval 时更新为 now 并返回 true
+func allowAfter(last *atomic.Int64, now time.Time, minInterval time.Duration) bool {
+	if minInterval <= 0 {
+		return true
+	}
+	for {
+		prev := last.Load()
+		if prev != 0 && now.Sub(time.Unix(0, prev)) < minInterval {
+			return false
+		}
+		if last.CompareAndSwap(prev, now.UnixNano()) {
+			return true
+		}
+	}
+}
+
+// packetInfo 提取包的 DD 与 SVC 分层信息；live=false 用于回放缓存包（不更新统计与模板结构）
+func (t *ForwardingTrack) packetInfo(pkt *rtp.Packet, live bool) svcPacketInfo {
+	var info svcPacketInfo
+	if t.srcDDExtID != 0 && pkt.Header.Extension {
+		info.dependencyDescriptor = pkt.Header.GetExtension(t.srcDDExtID)
+	}
+	if t.svc != nil {
+		t.svc.parse(pkt, &info, live)
+	}
+	return info
+}
+
+// replay 从 b.replayed 处继续回放缓存包。沿用发布者的原始序列号，与实时包一样只减去该订阅者的 seqOffset，
+// 因此 GOP 内真实的丢包与乱序对订阅者仍然可见，重试时也不会以新的序列号重复发送同一个包
+func (t *ForwardingTrack) replay(b *forwardingBinding, cached []*rtp.Packet) error {
+	for ; b.replayed < len(cached); b.replayed++ {
+		p := cached[b.replayed]
+		info := t.packetInfo(p, false)
+		if err := b.write(&p.Header, p.Payload, p.SequenceNumber, &info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectLayer 在画面起始处切换分层并判断该包是否转发给订阅者
+// 降层立即生效；空间层升层需要关键帧，时间层升层在 TL0 画面处生效。
+func (b *forwardingBinding) selectLayer(info *svcPacketInfo) bool {
+	if !info.hasLayers {
+		return true
+	}
+
+	if info.startOfFrame && info.layer.Spatial == 0 {
+		previous := b.current
+		if b.target.Spatial < b.current.Spatial || b.target.Spatial > b.current.Spatial && info.keyframe {
+			b.current.Spatial = b.target.Spatial
+		}
+		if b.target.Temporal < b.current.Temporal || b.target.Temporal > b.current.Temporal && info.layer.Temporal == 0 {
+			b.current.Temporal = b.target.Temporal
+		}
+		if b.current != previous {
+			b.published.Store(packSVCLayer(b.current))
+		}
+	}
+	return info.layer.Spatial <= b.current.Spatial && info.layer.Temporal <= b.current.Temporal
+}
+
+// currentLayer 实际生效的分层（可在转发协程之外调用）
+func (b *forwardingBinding) currentLayer() SVCLayer {
+	return unpackSVCLayer(b.published.Load())
+}
+
+// packSVCLayer/unpackSVCLayer 把分层打包为 uint32 以便原子读写
+func packSVCLayer(l SVCLayer) uint32 {
+	return uint32(uint16(l.Spatial))<<16 | uint32(uint16(l.Temporal))
+}
+
+func unpackSVCLayer(v uint32) SVCLayer {
+	return SVCLayer{Spatial: int(int16(v >> 16)), Temporal: int(int16(v))}
+}
+
+func (b *forwardingBinding) write(src *rtp.Header, payload []byte, seq uint16, info *svcPacketInfo) error {
+	if !b.selectLayer(info) {
+		// 丢弃的包不占用订阅者侧序列号，避免订阅者对其 NACK
+		b.seqOffset++
+		return nil
+	}
+
+	switch {
+	case b.stripRED:
+		primary, ok := redPrimaryPayload(payload)
+		if !ok || len(primary) == 0 {
+			// 无法解析或主编码为空（如 DTX），对订阅者表现为丢包
+			return nil
+		}
+		payload = primary
+	case b.redBlockPT != 0:
+		payload = rewriteREDBlockPayloadType(payload, b.redBlockPT)
+	}
+
+	header := *src
+	header.SSRC = uint32(b.ssrc)
+	header.PayloadType = uint8(b.payloadType)
+	header.SequenceNumber = seq - b.seqOffset
+	if info.hasLayers && info.endOfFrame && info.layer.Spatial == b.current.Spatial {
+		// 更高空间层被丢弃时，由订阅者可见的最高层结束画面
+		header.Marker = true
+	}
+	if b.svc && header.Extension {
+		// 发布者与订阅者的扩展 ID 各自协商，只按订阅者的 ID 重新写入 DD
+		header.Extension = false
+		header.Extensions = nil
+		header.ExtensionProfile = 0
+		if b.ddExtID != 0 && len(info.dependencyDescriptor) > 0 {
+			if err := header.SetExtension(b.ddExtID, info.dependencyDescriptor); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := b.writeStream.WriteRTP(&header, payload)
+	return err
+}
+
+// matchForwardingCodec 在订阅者协商出的编码中查找与发布者编码匹配的一项（优先 fmtp 完全一致）
+func matchForwardingCodec(codec webrtc.RTPCodecCapability, candidates []webrtc.RTPCodecParameters) (webrtc.RTPCodecParameters, bool) {
+	var partial *webrtc.RTPCodecParameters
+	for i := range candidates {
+		c := candidates[i]
+		if !strings.EqualFold(c.MimeType, codec.MimeType) {
+			continue
+		}
+		if c.SDPFmtpLine == codec.SDPFmtpLine {
+			return c, true
+		}
+		if partial == nil {
+			partial = &candidates[i]
+		}
+	}
+	if partial != nil {
+		return *partial, true
+	}
+	return webrtc.RTPCodecParameters{}, false
+}
+
+// keyframeCache 缓存最近一个关键帧起的整个 GOP（关键帧 + 后续增量帧），
+// 新订阅者回放后即可解码出当前画面，而无需向发布者发送 PLI。
+type keyframeCache struct {
+	mu       sync.RWMutex
+	mimeType string
+	packets  []*rtp.Packet
+	bytes    int
+	valid    bool
+	// keyTimestamp 当前 GOP 关键帧的 RTP 时间戳（同一关键帧的 SPS/IDR 等多个起始包不应重复开启 GOP）
+	keyTimestamp uint32
+	maxPackets   int
+	maxBytes     int
+}
+
+func newKeyframeCache(mimeType string, maxPackets, maxBytes int) *keyframeCache {
+	return &keyframeCache{
+		mimeType:   mimeType,
+		maxPackets: maxPackets,
+		maxBytes:   maxBytes,
+	}
+}
+
+// startsNewGOP 判断该包是否开启一个新的 GOP
+func (c *keyframeCache) startsNewGOP(pkt *rtp.Packet) bool {
+	if !isKeyframeStart(c.mimeType, pkt.Payload) {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.valid || pkt.Timestamp != c.keyTimestamp
+}
+
+// reset 以新的关键帧起始包开始一个新的 GOP
+func (c *keyframeCache) reset(pkt *rtp.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packets = nil
+	c.bytes = 0
+	c.valid = true
+	c.keyTimestamp = pkt.Timestamp
+	c.appendLocked(pkt)
+}
+
+// append 追加当前 GOP 内的后续包；超过上限则缓存失效直到下一个关键帧
+func (c *keyframeCache) append(pkt *rtp.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.valid {
+		return
+	}
+	c.appendLocked(pkt)
+}
+
+func (c *keyframeCache) appendLocked(pkt *rtp.Packet) {
+	if len(c.packets) >= c.maxPackets || c.bytes+len(pkt.Payload) > c.maxBytes {
+		c.packets = nil
+		c.bytes = 0
+		c.valid = false
+		return
+	}
+
+	// 转发协程复用读缓冲区，这里必须深拷贝
+	copied := &rtp.Packet{Header: pkt.Header.Clone(), Payload: append([]byte(nil), pkt.Payload...)}
+	c.packets = append(c.packets, copied)
+	c.bytes += len(copied.Payload)
+}
+
+// invalidate 丢弃当前 GOP，直到下一个关键帧
+func (c *keyframeCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packets = nil
+	c.bytes = 0
+	c.valid = false
+}
+
+func (c *keyframeCache) ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.valid && len(c.packets) > 0
+}
+
+// snapshot 返回当前 GOP 的只读快照（包本身不会再被修改）
+func (c *keyframeCache) snapshot() []*rtp.Packet {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.valid || len(c.packets) == 0 {
+		return nil
+	}
+	out := make([]*rtp.Packet, len(c.packets))
+	copy(out, c.packets)
+	return out
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingWriter 记录写入订阅者的 RTP 包；failures>0 时接下来的写入返回错误（模拟 RTPSender 尚未就绪）
+type recordingWriter struct {
+	headers  []rtp.Header
+	payloads [][]byte
+	failures int
+}
+
+func (w *recordingWriter) WriteRTP(header *rtp.Header, payload []byte) (int, error) {
+	if w.failures > 0 {
+		w.failures--
+		return 0, errors.New("sender not ready")
+	}
+	w.headers = append(w.headers, *header)
+	w.payloads = append(w.payloads, append([]byte(nil), payload...))
+	return len(payload), nil
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+// fakeTrackLocalContext 模拟 PeerConnection 协商完成后的绑定上下文
+type fakeTrackLocalContext struct {
+	webrtc.TrackLocalContext
+	id     string
+	ssrc   webrtc.SSRC
+	codecs []webrtc.RTPCodecParameters
+	exts   []webrtc.RTPHeaderExtensionParameter
+	writer *recordingWriter
+}
+
+func (c *fakeTrackLocalContext) ID() string                                   { return c.id }
+func (c *fakeTrackLocalContext) SSRC() webrtc.SSRC                            { return c.ssrc }
+func (c *fakeTrackLocalContext) CodecParameters() []webrtc.RTPCodecParameters { return c.codecs }
+func (c *fakeTrackLocalContext) WriteStream() webrtc.TrackLocalWriter         { return c.writer }
+func (c *fakeTrackLocalContext) HeaderExtensions() []webrtc.RTPHeaderExtensionParameter {
+	return c.exts
+}
+
+func newFakeVP8Context(id string, ssrc webrtc.SSRC) *fakeTrackLocalContext {
+	return &fakeTrackLocalContext{
+		id:   id,
+		ssrc: ssrc,
+		codecs: []webrtc.RTPCodecParameters{{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+			PayloadType:        100,
+		}},
+		writer: &recordingWriter{},
+	}
+}
+
+// vp8Packet 构造 VP8 RTP 包：keyframe=true 时为关键帧起始包
+func vp8Packet(seq uint16, ts uint32, keyframe bool) *rtp.Packet {
+	payloadHeader := byte(0x01) // P=1：帧间帧
+	if keyframe {
+		payloadHeader = 0x00
+	}
+	return &rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: seq, Timestamp: ts, SSRC: 1234},
+		Payload: []byte{0x10, payloadHeader, 0xAA},
+	}
+}
+
+func TestIsKeyframeStart(t *testing.T) {
+	// VP8: S=1 PID=0，P=0
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeVP8, []byte{0x10, 0x00}))
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeVP8, []byte{0x10, 0x01}))
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeVP8, []byte{0x00, 0x00}), "非分区起始包")
+	// VP8 带扩展字段：X=1, I=1, 15 位 PictureID
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeVP8, []byte{0x90, 0x80, 0x81, 0x23, 0x00}))
+
+	// VP9: B=1 P=0
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeVP9, []byte{0x08}))
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeVP9, []byte{0x48}))
+
+	// H264: IDR / SPS / STAP-A(SPS) / FU-A(IDR 首片)
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeH264, []byte{0x65}))
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeH264, []byte{0x67}))
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeH264, []byte{0x78, 0x00, 0x02, 0x67, 0x42, 0x00, 0x01, 0x68}))
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeH264, []byte{0x7C, 0x85}))
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeH264, []byte{0x7C, 0x05}), "FU-A 非首片")
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeH264, []byte{0x41}))
+
+	// AV1: N=1
+	assert.True(t, isKeyframeStart(webrtc.MimeTypeAV1, []byte{0x08}))
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeAV1, []byte{0x10}))
+
+	assert.False(t, isKeyframeStart(webrtc.MimeTypeOpus, []byte{0xFF}))
+}
+
+// TestForwardingTrack_ReplaysKeyframeToNewSubscriber 新订阅者先收到缓存的 GOP，沿用原始序列号（保留丢包间隔）并衔接实时流
+func TestForwardingTrack_ReplaysKeyframeToNewSubscriber(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "video", "stream", 0)
+
+	early := newFakeVP8Context("early", 1111)
+	_, err := track.Bind(early)
+	require.NoError(t, err)
+
+	require.NoError(t, track.WriteRTP(vp8Packet(100, 9000, true)))
+	require.NoError(t, track.WriteRTP(vp8Packet(101, 12000, false)))
+	// 103 丢失
+	require.NoError(t, track.WriteRTP(vp8Packet(102, 15000, false)))
+	assert.True(t, track.HasKeyframe())
+
+	late := newFakeVP8Context("late", 2222)
+	codec, err := track.Bind(late)
+	require.NoError(t, err)
+	assert.Equal(t, webrtc.PayloadType(100), codec.PayloadType)
+
+	require.NoError(t, track.WriteRTP(vp8Packet(104, 18000, false)))
+
+	// 早期订阅者只收到实时包
+	require.Len(t, early.writer.headers, 4)
+	assert.Equal(t, uint16(104), early.writer.headers[3].SequenceNumber)
+
+	// 新订阅者：3 个回放包 + 1 个实时包，103 的丢包对订阅者仍然可见
+	require.Len(t, late.writer.headers, 4)
+	for i, h := range late.writer.headers {
+		assert.Equal(t, []uint16{100, 101, 102, 104}[i], h.SequenceNumber)
+		assert.Equal(t, uint32(2222), h.SSRC)
+		assert.Equal(t, uint8(100), h.PayloadType)
+	}
+	assert.Equal(t, uint32(9000), late.writer.headers[0].Timestamp, "回放从关键帧开始")
+	assert.Equal(t, []byte{0x10, 0x00, 0xAA}, late.writer.payloads[0])
+}
+
+// TestForwardingTrack_ReplayRetryResumes 回放中途失败后下一个包从失败处继续，已发送的包不会重复发送
+func TestForwardingTrack_ReplayRetryResumes(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "video", "stream", 0)
+	require.NoError(t, track.WriteRTP(vp8Packet(200, 9000, true)))
+	require.NoError(t, track.WriteRTP(vp8Packet(202, 12000, false)))
+	require.NoError(t, track.WriteRTP(vp8Packet(201, 12000, false)))
+
+	late := newFakeVP8Context("late", 2222)
+	_, err := track.Bind(late)
+	require.NoError(t, err)
+
+	// 第二个回放包写入失败：本轮只发出 200，实时包 203 等回放完成后才发送
+	late.writer.failures = 1
+	require.NoError(t, track.WriteRTP(vp8Packet(203, 15000, false)))
+	require.NoError(t, track.WriteRTP(vp8Packet(204, 18000, false)))
+
+	seqs := make([]uint16, 0, len(late.writer.headers))
+	for _, h := range late.writer.headers {
+		seqs = append(seqs, h.SequenceNumber)
+	}
+	assert.Equal(t, []uint16{200, 202, 201, 203, 204}, seqs, "乱序保留，重试不重复")
+}
+
+// TestForwardingTrack_NewGOPResetsCache 新关键帧开启新的 GOP，同一关键帧的多个起始包不重复开启
+func TestForwardingTrack_NewGOPResetsCache(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "video", "stream", 0)
+
+	require.NoError(t, track.WriteRTP(vp8Packet(1, 1000, true)))
+	require.NoError(t, track.WriteRTP(vp8Packet(2, 1000, true)))
+	require.NoError(t, track.WriteRTP(vp8Packet(3, 4000, false)))
+	assert.Len(t, track.keyframes.snapshot(), 3)
+
+	require.NoError(t, track.WriteRTP(vp8Packet(4, 7000, true)))
+	snapshot := track.keyframes.snapshot()
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, uint16(4), snapshot[0].SequenceNumber)
+}
+
+// TestForwardingTrack_CacheOverflowInvalidates 超过上限后缓存失效，直到下一个关键帧
+func TestForwardingTrack_CacheOverflowInvalidates(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "video", "stream", 2)
+
+	require.NoError(t, track.WriteRTP(vp8Packet(1, 1000, true)))
+	require.NoError(t, track.WriteRTP(vp8Packet(2, 2000, false)))
+	assert.True(t, track.HasKeyframe())
+
+	require.NoError(t, track.WriteRTP(vp8Packet(3, 3000, false)))
+	assert.False(t, track.HasKeyframe())
+
+	late := newFakeVP8Context("late", 3333)
+	_, err := track.Bind(late)
+	require.NoError(t, err)
+	require.NoError(t, track.WriteRTP(vp8Packet(4, 4000, false)))
+	require.Len(t, late.writer.headers, 1, "缓存不可用时只转发实时包")
+
+	require.NoError(t, track.WriteRTP(vp8Packet(5, 5000, true)))
+	assert.True(t, track.HasKeyframe())
+}
+
+// TestForwardingTrack_AudioHasNoCache 音频轨道不缓存关键帧
+func TestForwardingTrack_AudioHasNoCache(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2}, "audio", "stream", 0)
+	assert.Nil(t, track.keyframes)
+	assert.False(t, track.HasKeyframe())
+	assert.Equal(t, webrtc.RTPCodecTypeAudio, track.Kind())
+
+	ctx := newFakeVP8Context("sub", 1)
+	_, err := track.Bind(ctx)
+	assert.ErrorIs(t, err, errForwardingTrackUnsupportedCodec)
+}
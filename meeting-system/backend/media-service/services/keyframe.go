@@ -0,0 +1,108 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// isKeyframeStart 判断 RTP payload 是否为一个关键帧的起始包
+// SFU 不解码媒体，只解析各编码的 RTP payload 头部：
+//   - VP8: payload descriptor S=1 且 PID=0，VP8 payload header 的 P 位为 0
+//   - VP9: payload descriptor B=1 且 P=0（非帧间预测帧）
+//   - H264: IDR(5) / SPS(7)，包括 STAP-A 聚合包与 FU-A 分片的首片
+//   - AV1: aggregation header 的 N 位（新的编码视频序列）
+func isKeyframeStart(mimeType string, payload []byte) bool {
+	if len(payload) == 0 {
+		return false
+	}
+
+	switch strings.ToLower(mimeType) {
+	case strings.ToLower(webrtc.MimeTypeVP8):
+		return isVP8KeyframeStart(payload)
+	case strings.ToLower(webrtc.MimeTypeVP9):
+		return isVP9KeyframeStart(payload)
+	case strings.ToLower(webrtc.MimeTypeH264):
+		return isH264KeyframeStart(payload)
+	case strings.ToLower(webrtc.MimeTypeAV1):
+		return payload[0]&0x08 != 0
+	default:
+		return false
+	}
+}
+
+func isVP8KeyframeStart(payload []byte) bool {
+	// RFC 7741 payload descriptor
+	b0 := payload[0]
+	if b0&0x10 == 0 || b0&0x07 != 0 { // S=1, PID=0
+		return false
+	}
+
+	idx := 1
+	if b0&0x80 != 0 { // X
+		if len(payload) <= idx {
+			return false
+		}
+		ext := payload[idx]
+		idx++
+		if ext&0x80 != 0 { // I: PictureID
+			if len(payload) <= idx {
+				return false
+			}
+			if payload[idx]&0x80 != 0 {
+				idx += 2
+			} else {
+				idx++
+			}
+		}
+		if ext&0x40 != 0 { // L: TL0PICIDX
+			idx++
+		}
+		if ext&0x30 != 0 { // T/K: TID/KEYIDX
+			idx++
+		}
+	}
+
+	if len(payload) <= idx {
+		return false
+	}
+	// VP8 payload header: P 位为 0 表示关键帧
+	return payload[idx]&0x01 == 0
+}
+
+func isVP9KeyframeStart(payload []byte) bool {
+	// draft-ietf-payload-vp9: |I|P|L|F|B|E|V|Z|
+	b0 := payload[0]
+	return b0&0x40 == 0 && b0&0x08 != 0
+}
+
+func isH264KeyframeStart(payload []byte) bool {
+	nalType := payload[0] & 0x1F
+	switch nalType {
+	case 5, 7:
+		return true
+	case 24: // STAP-A
+		idx := 1
+		for idx+2 < len(payload) {
+			size := int(payload[idx])<<8 | int(payload[idx+1])
+			idx += 2
+			if size == 0 || idx+size > len(payload) {
+				return false
+			}
+			if t := payload[idx] & 0x1F; t == 5 || t == 7 {
+				return true
+			}
+			idx += size
+		}
+		return false
+	case 28: // FU-A
+		if len(payload) < 2 {
+			return false
+		}
+		fuHeader := payload[1]
+		t := fuHeader & 0x1F
+		return fuHeader&0x80 != 0 && (t == 5 || t == 7)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/logger"
+	sharedmodels "meeting-system/shared/models"
+)
+
+// mediaMuted 用户的某类媒体是否被强制静音
+func (r *Room) mediaMuted(userID, mediaType string) bool {
+	r.moderationMux.RLock()
+	defer r.moderationMux.RUnlock()
+	return r.mutedMedia[moderationKey(userID, mediaType)]
+}
+
+func moderationKey(userID, mediaType string) string {
+	return userID + "/" + mediaType
+}
+
+// trackModerationMedia 转发轨道对应的管控媒体类型：音频、摄像头或屏幕共享
+func trackModerationMedia(kind webrtc.RTPCodecType, screenShare bool) string {
+	switch {
+	case kind == webrtc.RTPCodecTypeAudio:
+		return sharedmodels.ModerationMediaAudio
+	case screenShare:
+		return sharedmodels.ModerationMediaScreen
+	default:
+		return sharedmodels.ModerationMediaVideo
+	}
+}
+
+// SetMediaModeration 强制静音（muted=true）或允许取消静音：对用户在房间内已发布及之后发布的对应轨道生效，
+// 被静音的轨道由 forwardRTP 丢弃（不转发、不混音、不送 AI）。返回状态发生变化的轨道数。
+func (s *WebRTCService) SetMediaModeration(roomID, userID, mediaType string, muted bool) (int, error) {
+	if !sharedmodels.ValidModerationMedia(mediaType) {
+		return 0, fmt.Errorf("invalid media type: %s", mediaType)
+	}
+
+	s.roomsMux.RLock()
+	room, exists := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if !exists {
+		return 0, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	room.moderationMux.Lock()
+	if muted {
+		if room.mutedMedia == nil {
+			room.mutedMedia = make(map[string]bool)
+		}
+		room.mutedMedia[moderationKey(userID, mediaType)] = true
+	} else {
+		delete(room.mutedMedia, moderationKey(userID, mediaType))
+	}
+	room.moderationMux.Unlock()
+
+	affected := 0
+	for _, ft := range room.userTracks(userID) {
+		if trackModerationMedia(ft.LocalTrack.Kind(), ft.LocalTrack.IsScreenShare()) != mediaType {
+			continue
+		}
+		if !ft.LocalTrack.SetMuted(muted) {
+			continue
+		}
+		affected++
+		if !ft.LocalTrack.Suppressed() && ft.LocalTrack.Kind() == webrtc.RTPCodecTypeVideo {
+			// 静音期间订阅者没有收到任何画面，恢复时从关键帧开始
+			s.sendPLI(ft.SenderPeer, ft.RemoteSSRC)
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Media moderation applied (room=%s, user=%s, media=%s, muted=%t, tracks=%d)",
+		roomID, userID, mediaType, muted, affected))
+	return affected, nil
+}
+
+// userTracks 用户在房间内（所有 Peer）发布的转发轨道
+func (r *Room) userTracks(userID string) []*ForwardedTrack {
+	peerIDs := make(map[string]struct{})
+	r.PeersMux.RLock()
+	for peerID, peer := range r.Peers {
+		if peer != nil && peer.UserID == userID {
+			peerIDs[peerID] = struct{}{}
+		}
+	}
+	r.PeersMux.RUnlock()
+
+	r.TracksMux.RLock()
+	defer r.TracksMux.RUnlock()
+	tracks := make([]*ForwardedTrack, 0, 2)
+	for _, ft := range r.Tracks {
+		if ft == nil || ft.LocalTrack == nil {
+			continue
+		}
+		if _, ok := peerIDs[ft.SenderPeer]; ok {
+			tracks = append(tracks, ft)
+		}
+	}
+	return tracks
+}
+
+// SetMeetingMediaModeration 处理信令服务的媒体管控事件（按会议找到本节点的房间）；
+// 媒体服务不直接对外提供管控接口，主持人权限由信令服务校验
+func (s *WebRTCService) SetMeetingMediaModeration(meetingID, userID uint, mediaType string, muted bool) (int, error) {
+	return s.SetMediaModeration(s.meetingRoomID(meetingID), strconv.FormatUint(uint64(userID), 10), mediaType, muted)
+}
+
+// RemoveMeetingUserPeers 处理信令服务的移出/封禁事件：关闭该用户在会议房间内的所有 Peer，返回关闭的 Peer 数
+func (s *WebRTCService) RemoveMeetingUserPeers(meetingID, userID uint) (int, error) {
+	roomID := s.meetingRoomID(meetingID)
+	s.roomsMux.RLock()
+	room := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if room == nil {
+		return 0, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	uid := strconv.FormatUint(uint64(userID), 10)
+	room.PeersMux.RLock()
+	var peerIDs []string
+	for peerID, peer := range room.Peers {
+		if peer != nil && peer.UserID == uid {
+			peerIDs = append(peerIDs, peerID)
+		}
+	}
+	room.PeersMux.RUnlock()
+
+	for _, peerID := range peerIDs {
+		s.cleanupPeer(peerID, "removed_by_host")
+	}
+	return len(peerIDs), nil
+}
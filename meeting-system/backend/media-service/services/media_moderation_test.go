@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+	sharedmodels "meeting-system/shared/models"
+)
+
+// TestForwardingTrack_MutedDropsPackets 静音期间不转发，解除后订阅者侧序列号连续
+func TestForwardingTrack_MutedDropsPackets(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "video", "stream", 0)
+	sub := newFakeVP8Context("sub", 1111)
+	_, err := track.Bind(sub)
+	require.NoError(t, err)
+
+	require.NoError(t, track.WriteRTP(vp8Packet(100, 9000, true)))
+	assert.True(t, track.SetMuted(true))
+	assert.False(t, track.SetMuted(true), "重复静音不算状态变化")
+	assert.False(t, track.HasKeyframe(), "静音前的画面不回放给新订阅者")
+
+	require.NoError(t, track.WriteRTP(vp8Packet(101, 12000, false)))
+	require.NoError(t, track.WriteRTP(vp8Packet(102, 15000, false)))
+	require.Len(t, sub.writer.headers, 1)
+
+	assert.True(t, track.SetMuted(false))
+	require.NoError(t, track.WriteRTP(vp8Packet(103, 18000, true)))
+	require.Len(t, sub.writer.headers, 2)
+	assert.Equal(t, uint16(101), sub.writer.headers[1].SequenceNumber)
+}
+
+func TestWebRTCService_SetMediaModeration(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	peer := registerTestPeer(t, svc, "room-mod", "7")
+	other := registerTestPeer(t, svc, "room-mod", "8")
+
+	opus := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2}
+	vp8 := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}
+	audio, err := svc.publishExternalTrack("room-mod", peer.ID, "audio", opus)
+	require.NoError(t, err)
+	camera, err := svc.publishExternalTrack("room-mod", peer.ID, "camera", vp8)
+	require.NoError(t, err)
+	otherAudio, err := svc.publishExternalTrack("room-mod", other.ID, "audio", opus)
+	require.NoError(t, err)
+
+	affected, err := svc.SetMediaModeration("room-mod", "7", sharedmodels.ModerationMediaAudio, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, affected)
+	assert.True(t, audio.Muted())
+	assert.False(t, camera.Muted(), "只管控指定的媒体类型")
+	assert.False(t, otherAudio.Muted(), "只管控指定的用户")
+
+	// 被静音的用户重新发布（例如重新入会）的音频轨道继承管控状态
+	republished, err := svc.publishExternalTrack("room-mod", peer.ID, "audio-2", opus)
+	require.NoError(t, err)
+	assert.True(t, republished.Muted())
+	// 屏幕共享单独管控
+	screen, err := svc.publishExternalTrack("room-mod", peer.ID, "screen-share", vp8)
+	require.NoError(t, err)
+	assert.False(t, screen.Muted())
+
+	affected, err = svc.SetMediaModeration("room-mod", "7", sharedmodels.ModerationMediaAudio, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, affected)
+	assert.False(t, audio.Muted())
+	assert.False(t, republished.Muted())
+
+	affected, err = svc.SetMediaModeration("room-mod", "7", sharedmodels.ModerationMediaScreen, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, affected)
+	assert.True(t, screen.Muted())
+	assert.False(t, camera.Muted())
+
+	_, err = svc.SetMediaModeration("room-mod", "7", "microphone", true)
+	assert.Error(t, err)
+	_, err = svc.SetMediaModeration("missing", "7", sharedmodels.ModerationMediaAudio, true)
+	assert.Error(t, err)
+}
+
+// TestWebRTCService_RemoveMeetingUserPeers 被移出的用户的 Peer 被关闭，其轨道从房间下线
+func TestWebRTCService_RemoveMeetingUserPeers(t *testing.T) {
+	svc := newICERestartTestService(t)
+	removed := registerTestPeer(t, svc, "1", "7")
+	registerTestPeer(t, svc, "1", "8")
+	_, err := svc.publishExternalTrack("1", removed.ID, "audio", webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2})
+	require.NoError(t, err)
+
+	n, err := svc.RemoveMeetingUserPeers(1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	svc.peersMux.RLock()
+	_, exists := svc.peers[removed.ID]
+	svc.peersMux.RUnlock()
+	assert.False(t, exists)
+	assert.Empty(t, roomTrackKeys(svc, "1"))
+	assert.Equal(t, webrtc.PeerConnectionStateClosed, removed.Connection.ConnectionState())
+
+	n, err = svc.RemoveMeetingUserPeers(1, 7)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	_, err = svc.RemoveMeetingUserPeers(2, 7)
+	assert.Error(t, err, "会议房间不在本节点")
+}
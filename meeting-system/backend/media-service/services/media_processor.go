@@ -3,11 +3,15 @@ package services
 import (
 	"context"
 	"fmt"
+	"os/exec"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3"
 	"meeting-system/shared/config"
+	pb "meeting-system/shared/grpc"
 	"meeting-system/shared/logger"
 )
 
@@ -23,6 +27,35 @@ type MediaProcessor struct {
 	workers         int
 	useGRPC         bool // 是否使用 gRPC
 	aiGRPCAddr      string
+
+	// 视频抽帧：解码在独立协程中进行，避免阻塞转发线程
+	videoSampling config.AIVideoSamplingConfig
+	videoDecoder  videoFrameDecoder
+	videoQueue    chan *videoFrameJob
+	frameLimiter  *roomFrameLimiter
+
+	// AI 流式结果经事件总线发给信令服务推送到前端（未设置时只记录日志）
+	resultPublisher AIResultPublisher
+}
+
+// videoFrameJob 待解码的视频抽样帧
+type videoFrameJob struct {
+	stream *StreamProcessor
+	sample *VideoSample
+}
+
+// roomFrameLimiter 按房间限制送 AI 的视频帧率（令牌桶）
+type roomFrameLimiter struct {
+	mu    sync.Mutex
+	rate  float64
+	burst float64
+	rooms map[string]*frameBucket
+	now   func() time.Time
+}
+
+type frameBucket struct {
+	tokens float64
+	last   time.Time
 }
 
 // StreamProcessor 流处理器
@@ -30,15 +63,30 @@ type StreamProcessor struct {
 	StreamID       string
 	UserID         string
 	RoomID         string
+	MeetingID      uint      // 房间所属会议，AI 结果按会议推送
+	PeerID         string    // 发布者 Peer，AI 结果据此标注说话人
+	TrackID        string    // 上行轨道 ID
+	TrackKind      string    // audio, video
+	StartedAt      time.Time // 注册时间，区分同一流 ID 的多次注册
 	AudioTrack     *webrtc.TrackRemote
 	VideoTrack     *webrtc.TrackRemote
-	AudioBuffer    *CircularBuffer
-	VideoBuffer    *CircularBuffer
+	AudioBuffer    *CircularBuffer // 16kHz 单声道 S16LE PCM
+	AudioTap       *AudioTap       // Opus RTP -> PCM
+	VideoTap       *VideoTap       // VP8/H.264 RTP -> 抽样帧
 	LastProcessed  time.Time
-	IsActive       bool
+	IsActive       atomic.Bool        // 注销时置 false，采集/调度协程并发读取
 	AITasks        []string           // 需要执行的AI任务
 	AIStreamClient *AudioStreamClient // gRPC 流客户端
 	Sequence       int32              // 音频片段序列号
+
+	AIVideoStreamClient *VideoStreamClient // gRPC 视频流客户端（首帧时建立）
+	VideoSequence       int32              // 视频帧序列号
+	videoMux            sync.Mutex
+	pendingVideo        *VideoData // HTTP 批处理模式下待发送的最新抽样帧
+
+	resultMux     sync.Mutex
+	deliveredSeqs map[string]int32 // result_type -> 已推送的最大序列号
+	presence      map[string]bool  // result_type -> 上次推送的在场状态
 }
 
 // ProcessingTask 处理任务
@@ -100,7 +148,23 @@ func NewMediaProcessor(config *config.Config, aiClient *AIClient, ffmpegService
 		processingQueue: make(chan *ProcessingTask, 1000),
 		workers:         4,     // 默认4个工作协程
 		useGRPC:         false, // 默认使用 HTTP，可通过环境变量切换
+		videoSampling:   config.WebRTC.AIVideoSampling,
+		videoQueue:      make(chan *videoFrameJob, 16),
+	}
+
+	// H.264 与 VP8 非关键帧需要 ffmpeg 解码；没有 ffmpeg 时只抽 VP8 关键帧
+	decoder := &sampleDecoder{}
+	if _, err := exec.LookPath("ffmpeg"); err == nil && ffmpegService != nil {
+		decoder.ffmpeg = ffmpegService
+	} else {
+		logger.Warn("ffmpeg not found, AI video sampling limited to VP8 keyframes")
 	}
+	processor.videoDecoder = decoder
+	roomFPS := processor.videoSampling.RoomMaxFPS
+	if roomFPS <= 0 {
+		roomFPS = defaultAIVideoRoomMaxFPS
+	}
+	processor.frameLimiter = newRoomFrameLimiter(roomFPS)
 
 	// 尝试初始化 gRPC 客户端
 	aiHost := config.Services.AIService.Host
@@ -142,6 +206,7 @@ func NewMediaProcessor(config *config.Config, aiClient *AIClient, ffmpegService
 	for i := 0; i < processor.workers; i++ {
 		go processor.worker()
 	}
+	go processor.videoWorker()
 
 	return processor
 }
@@ -188,22 +253,36 @@ func (p *MediaProcessor) RegisterStream(streamID, userID, roomID string, audioTr
 	logger.Info(fmt.Sprintf("Registering stream: %s for user %s in room %s", streamID, userID, roomID))
 
 	// 创建缓冲区
-	audioBuffer := NewCircularBuffer(1024*1024, 5*time.Second)   // 5秒音频缓冲
-	videoBuffer := NewCircularBuffer(5*1024*1024, 2*time.Second) // 2秒视频缓冲
+	audioBuffer := NewCircularBuffer(1024*1024, 5*time.Second) // 5秒音频缓冲
 
 	streamProcessor := &StreamProcessor{
 		StreamID:      streamID,
 		UserID:        userID,
 		RoomID:        roomID,
+		StartedAt:     time.Now(),
 		AudioTrack:    audioTrack,
 		VideoTrack:    videoTrack,
 		AudioBuffer:   audioBuffer,
-		VideoBuffer:   videoBuffer,
 		LastProcessed: time.Now(),
-		IsActive:      true,
 		AITasks:       aiTasks,
 		Sequence:      0,
 	}
+	streamProcessor.IsActive.Store(true)
+
+	audioTap, err := NewAudioTap()
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to create audio tap for stream %s: %v", streamID, err))
+	} else {
+		streamProcessor.AudioTap = audioTap
+	}
+	if videoTrack != nil {
+		videoTap, err := p.newVideoTap(videoTrack.Codec().MimeType)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("AI video sampling disabled for stream %s: %v", streamID, err))
+		} else {
+			streamProcessor.VideoTap = videoTap
+		}
+	}
 
 	// 如果使用 gRPC，启动流式连接
 	if p.useGRPC && p.aiGRPCClient != nil && len(aiTasks) > 0 {
@@ -245,41 +324,199 @@ func (p *MediaProcessor) RegisterStream(streamID, userID, roomID string, audioTr
 }
 
 // IngestRTPPayload 将RTP payload 写入对应缓冲区（用于 SFU：只读一次 TrackRemote，并把数据分发给转发+AI）
-func (p *MediaProcessor) IngestRTPPayload(streamID string, kind webrtc.RTPCodecType, payload []byte) {
-	if streamID == "" || len(payload) == 0 {
+// 音频 payload 必须是 Opus，经 AudioTap 重排序、解码后以 PCM 写入；header 提供序列号与时间戳。
+func (p *MediaProcessor) IngestRTPPayload(streamID string, kind webrtc.RTPCodecType, header *rtp.Header, payload []byte) {
+	if streamID == "" || header == nil || len(payload) == 0 {
 		return
 	}
 
 	p.streamsMux.RLock()
 	stream, exists := p.activeStreams[streamID]
 	p.streamsMux.RUnlock()
-	if !exists || stream == nil || !stream.IsActive {
+	if !exists || stream == nil || !stream.IsActive.Load() {
 		return
 	}
 
 	switch kind {
 	case webrtc.RTPCodecTypeAudio:
-		if stream.AudioBuffer != nil {
-			stream.AudioBuffer.Write(payload)
-		}
+		p.ingestAudio(stream, header, payload)
 	case webrtc.RTPCodecTypeVideo:
-		if stream.VideoBuffer != nil {
-			stream.VideoBuffer.Write(payload)
+		p.ingestVideo(stream, header, payload)
+	}
+}
+
+// EnableVideoSampling 为流开启视频抽帧（只支持 VP8/H.264），需要在转发开始前调用
+func (p *MediaProcessor) EnableVideoSampling(streamID, mimeType string) error {
+	p.streamsMux.RLock()
+	stream, exists := p.activeStreams[streamID]
+	p.streamsMux.RUnlock()
+	if !exists || stream == nil {
+		return fmt.Errorf("stream not found: %s", streamID)
+	}
+
+	tap, err := p.newVideoTap(mimeType)
+	if err != nil {
+		return err
+	}
+	stream.VideoTap = tap
+	return nil
+}
+
+// newVideoTap 按配置与可用的解码能力创建抽帧器
+func (p *MediaProcessor) newVideoTap(mimeType string) (*VideoTap, error) {
+	interval := p.videoSampling.FrameInterval
+	if decoder, ok := p.videoDecoder.(*sampleDecoder); ok {
+		if !decoder.canDecode(mimeType, true) {
+			return nil, fmt.Errorf("%w: %s", ErrVideoDecoderUnavailable, mimeType)
+		}
+		if !decoder.canDecode(mimeType, false) {
+			interval = 0
 		}
 	}
+	return NewVideoTap(mimeType, interval)
+}
+
+// ingestVideo 抽帧并按房间限速后交给解码协程；队列满时丢弃
+func (p *MediaProcessor) ingestVideo(stream *StreamProcessor, header *rtp.Header, payload []byte) {
+	if stream.VideoTap == nil {
+		return
+	}
+	sample := stream.VideoTap.Push(header, payload)
+	if sample == nil || !p.frameLimiter.Allow(stream.RoomID) {
+		return
+	}
+
+	select {
+	case p.videoQueue <- &videoFrameJob{stream: stream, sample: sample}:
+	default:
+		logger.Debug(fmt.Sprintf("Video frame queue is full, dropping sample for stream %s", stream.StreamID))
+	}
+}
+
+// videoWorker 解码抽样帧并发送给 AI 服务
+func (p *MediaProcessor) videoWorker() {
+	for job := range p.videoQueue {
+		if !job.stream.IsActive.Load() {
+			continue
+		}
+		frame, err := p.decodeVideoSample(job.sample)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to decode video sample for stream %s: %v", job.stream.StreamID, err))
+			continue
+		}
+		p.deliverVideoFrame(job.stream, frame)
+	}
+}
+
+// decodeVideoSample 解码并编码为配置的 AI 输入格式
+func (p *MediaProcessor) decodeVideoSample(sample *VideoSample) (*VideoData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	img, err := p.videoDecoder.DecodeSample(ctx, sample)
+	if err != nil {
+		return nil, err
+	}
+	return encodeAIFrame(img, p.videoSampling.Format, p.videoSampling.MaxWidth, p.videoSampling.JPEGQuality)
+}
+
+// deliverVideoFrame gRPC 模式下通过 StreamVideoProcessing 发送；否则保留最新一帧给 HTTP 批处理
+func (p *MediaProcessor) deliverVideoFrame(stream *StreamProcessor, frame *VideoData) {
+	stream.videoMux.Lock()
+	defer stream.videoMux.Unlock()
+
+	if !p.useGRPC || p.aiGRPCClient == nil {
+		stream.pendingVideo = frame
+		return
+	}
+
+	if stream.AIVideoStreamClient == nil {
+		streamClient, err := p.aiGRPCClient.StartVideoStream(context.Background(), stream.StreamID, stream.AITasks)
+		if err != nil {
+			logger.Error("Failed to start AI video stream",
+				logger.String("stream_id", stream.StreamID),
+				logger.Err(err))
+			return
+		}
+		stream.AIVideoStreamClient = streamClient
+		go p.consumeAIResults(stream, streamClient.ResultChan, streamClient.ErrorChan, streamClient.Done)
+	}
+
+	stream.VideoSequence++
+	if err := p.aiGRPCClient.SendVideoFrame(stream.StreamID, stream.VideoSequence, frame, stream.AITasks, false); err != nil {
+		logger.Error("Failed to send video frame",
+			logger.String("stream_id", stream.StreamID),
+			logger.Err(err))
+	}
+}
+
+// takePendingVideo 取出 HTTP 批处理模式下待发送的视频帧
+func (stream *StreamProcessor) takePendingVideo() *VideoData {
+	stream.videoMux.Lock()
+	defer stream.videoMux.Unlock()
+
+	frame := stream.pendingVideo
+	stream.pendingVideo = nil
+	return frame
+}
+
+// closeVideoStream 关闭 AI 视频流
+func (p *MediaProcessor) closeVideoStream(stream *StreamProcessor) {
+	stream.videoMux.Lock()
+	defer stream.videoMux.Unlock()
+
+	if stream.AIVideoStreamClient != nil && p.aiGRPCClient != nil {
+		_ = p.aiGRPCClient.CloseVideoStream(stream.StreamID)
+		stream.AIVideoStreamClient = nil
+	}
+}
+
+// ingestAudio 解码 Opus 并写入 PCM 缓冲区
+func (p *MediaProcessor) ingestAudio(stream *StreamProcessor, header *rtp.Header, payload []byte) {
+	if stream.AudioTap == nil || stream.AudioBuffer == nil {
+		return
+	}
+	if pcm := stream.AudioTap.Push(header.SequenceNumber, header.Timestamp, payload); len(pcm) > 0 {
+		stream.AudioBuffer.Write(pcm)
+	}
+}
+
+// flushAudio 流结束时把重排序缓冲中剩余的音频写入 PCM 缓冲区
+func (p *MediaProcessor) flushAudio(stream *StreamProcessor) {
+	if stream.AudioTap == nil || stream.AudioBuffer == nil {
+		return
+	}
+	if pcm := stream.AudioTap.Flush(); len(pcm) > 0 {
+		stream.AudioBuffer.Write(pcm)
+	}
 }
 
 // UnregisterStream 注销音视频流
 func (p *MediaProcessor) UnregisterStream(streamID string) error {
 	p.streamsMux.Lock()
-	defer p.streamsMux.Unlock()
+	stream, exists := p.activeStreams[streamID]
+	if !exists {
+		p.streamsMux.Unlock()
+		return nil
+	}
+	stream.IsActive.Store(false)
+	delete(p.activeStreams, streamID)
 
-	if stream, exists := p.activeStreams[streamID]; exists {
-		stream.IsActive = false
-		delete(p.activeStreams, streamID)
-		logger.Info(fmt.Sprintf("Unregistered stream: %s", streamID))
+	roomActive := false
+	for _, other := range p.activeStreams {
+		if other.RoomID == stream.RoomID {
+			roomActive = true
+			break
+		}
+	}
+	if !roomActive {
+		p.frameLimiter.Forget(stream.RoomID)
 	}
+	p.streamsMux.Unlock()
 
+	// 视频协程可能在 videoMux 下阻塞于 gRPC 调用，释放 streamsMux 后再关闭，避免阻塞其它流的注册与查找
+	p.closeVideoStream(stream)
+	logger.Info(fmt.Sprintf("Unregistered stream: %s", streamID))
 	return nil
 }
 
@@ -287,18 +524,18 @@ func (p *MediaProcessor) UnregisterStream(streamID string) error {
 func (p *MediaProcessor) collectAudioData(stream *StreamProcessor) {
 	logger.Debug(fmt.Sprintf("Starting audio collection for stream: %s", stream.StreamID))
 
-	for stream.IsActive {
+	for stream.IsActive.Load() {
 		// 读取音频RTP包
 		rtpPacket, _, err := stream.AudioTrack.ReadRTP()
 		if err != nil {
-			if stream.IsActive {
+			if stream.IsActive.Load() {
 				logger.Error(fmt.Sprintf("Failed to read audio RTP: %v", err))
 			}
 			break
 		}
 
-		// 将RTP包数据写入缓冲区
-		stream.AudioBuffer.Write(rtpPacket.Payload)
+		// 解码后写入缓冲区
+		p.ingestAudio(stream, &rtpPacket.Header, rtpPacket.Payload)
 	}
 
 	logger.Debug(fmt.Sprintf("Audio collection stopped for stream: %s", stream.StreamID))
@@ -308,18 +545,18 @@ func (p *MediaProcessor) collectAudioData(stream *StreamProcessor) {
 func (p *MediaProcessor) collectVideoData(stream *StreamProcessor) {
 	logger.Debug(fmt.Sprintf("Starting video collection for stream: %s", stream.StreamID))
 
-	for stream.IsActive {
+	for stream.IsActive.Load() {
 		// 读取视频RTP包
 		rtpPacket, _, err := stream.VideoTrack.ReadRTP()
 		if err != nil {
-			if stream.IsActive {
+			if stream.IsActive.Load() {
 				logger.Error(fmt.Sprintf("Failed to read video RTP: %v", err))
 			}
 			break
 		}
 
-		// 将RTP包数据写入缓冲区
-		stream.VideoBuffer.Write(rtpPacket.Payload)
+		// 抽帧后交给解码协程
+		p.ingestVideo(stream, &rtpPacket.Header, rtpPacket.Payload)
 	}
 
 	logger.Debug(fmt.Sprintf("Video collection stopped for stream: %s", stream.StreamID))
@@ -330,7 +567,7 @@ func (p *MediaProcessor) scheduleProcessing(stream *StreamProcessor) {
 	ticker := time.NewTicker(3 * time.Second) // 每3秒处理一次
 	defer ticker.Stop()
 
-	for stream.IsActive {
+	for stream.IsActive.Load() {
 		select {
 		case <-ticker.C:
 			p.processStream(stream)
@@ -344,40 +581,23 @@ func (p *MediaProcessor) processStream(stream *StreamProcessor) {
 		return // 避免过于频繁的处理
 	}
 
-	// 提取音频数据（SFU 模式：不进行格式转换）
+	// 提取音频数据（AudioTap 已解码为 16kHz 单声道 PCM）
 	var audioData *AudioData
 	if stream.AudioBuffer.Count() > 0 {
-		audioBytes := stream.AudioBuffer.ReadAll()
+		audioBytes := stream.AudioBuffer.Drain()
 		if len(audioBytes) > 0 {
-			// SFU 架构：直接使用原始音频数据，不进行格式转换
-			// AI 服务应该能够处理原始 PCM 或 Opus 格式
 			audioData = &AudioData{
 				Data:       audioBytes,
-				Format:     "pcm", // 原始 PCM 格式
-				SampleRate: 48000, // WebRTC 默认采样率
-				Channels:   2,     // 立体声
-				Duration:   3000,  // 3秒
+				Format:     aiAudioFormat,
+				SampleRate: aiAudioSampleRate,
+				Channels:   aiAudioChannels,
+				Duration:   pcmDurationMs(len(audioBytes)),
 			}
 		}
 	}
 
-	// 提取视频数据（SFU 模式：不进行格式转换）
-	var videoData *VideoData
-	if stream.VideoBuffer.Count() > 0 {
-		videoBytes := stream.VideoBuffer.ReadAll()
-		if len(videoBytes) > 0 {
-			// SFU 架构：直接使用原始视频数据，不进行格式转换
-			// AI 服务应该能够处理原始 H264/VP8 格式
-			videoData = &VideoData{
-				Data:     videoBytes,
-				Format:   "h264", // 原始 H264 格式
-				Width:    640,
-				Height:   480,
-				FPS:      30,
-				Duration: 2000, // 2秒
-			}
-		}
-	}
+	// 提取最新的视频抽样帧
+	videoData := stream.takePendingVideo()
 
 	// 如果有数据，创建处理任务
 	if audioData != nil || videoData != nil {
@@ -549,6 +769,26 @@ func (cb *CircularBuffer) ReadAll() []byte {
 	return result
 }
 
+// Drain 读取并清空缓冲区
+func (cb *CircularBuffer) Drain() []byte {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.count == 0 {
+		return nil
+	}
+
+	result := make([]byte, cb.count)
+	for i := 0; i < cb.count; i++ {
+		result[i] = cb.buffer[(cb.tail+i)%cb.maxSize]
+	}
+	cb.head = 0
+	cb.tail = 0
+	cb.count = 0
+
+	return result
+}
+
 // Count 获取缓冲区数据量
 func (cb *CircularBuffer) Count() int {
 	cb.mutex.RLock()
@@ -570,12 +810,12 @@ func (p *MediaProcessor) streamProcessing(stream *StreamProcessor) {
 	ticker := time.NewTicker(500 * time.Millisecond) // 每 500ms 发送一次
 	defer ticker.Stop()
 
-	for stream.IsActive {
+	for stream.IsActive.Load() {
 		select {
 		case <-ticker.C:
-			// 提取音频数据
+			// 提取音频数据（已发送的数据从缓冲区移除）
 			if stream.AudioBuffer.Count() > 0 {
-				audioBytes := stream.AudioBuffer.ReadAll()
+				audioBytes := stream.AudioBuffer.Drain()
 				if len(audioBytes) > 0 {
 					// 发送音频片段到 AI 服务
 					stream.Sequence++
@@ -583,9 +823,9 @@ func (p *MediaProcessor) streamProcessing(stream *StreamProcessor) {
 						stream.StreamID,
 						stream.Sequence,
 						audioBytes,
-						"pcm", // 原始 PCM 格式
-						48000, // 48kHz
-						2,     // 2 channels
+						aiAudioFormat,
+						aiAudioSampleRate,
+						aiAudioChannels,
 						stream.AITasks,
 						false, // 不是最后一个片段
 					)
@@ -601,17 +841,18 @@ func (p *MediaProcessor) streamProcessing(stream *StreamProcessor) {
 
 	// 流结束时发送最后一个片段
 	if stream.AIStreamClient != nil {
+		p.flushAudio(stream)
 		if stream.AudioBuffer.Count() > 0 {
-			audioBytes := stream.AudioBuffer.ReadAll()
+			audioBytes := stream.AudioBuffer.Drain()
 			if len(audioBytes) > 0 {
 				stream.Sequence++
 				p.aiGRPCClient.SendAudioChunk(
 					stream.StreamID,
 					stream.Sequence,
 					audioBytes,
-					"pcm",
-					48000,
-					2,
+					aiAudioFormat,
+					aiAudioSampleRate,
+					aiAudioChannels,
 					stream.AITasks,
 					true, // 最后一个片段
 				)
@@ -628,34 +869,84 @@ func (p *MediaProcessor) receiveAIResults(stream *StreamProcessor) {
 	if stream.AIStreamClient == nil {
 		return
 	}
+	p.consumeAIResults(stream, stream.AIStreamClient.ResultChan, stream.AIStreamClient.ErrorChan, stream.AIStreamClient.Done)
+}
 
+// consumeAIResults 处理音频/视频流返回的 AI 结果
+func (p *MediaProcessor) consumeAIResults(stream *StreamProcessor, resultChan <-chan *pb.AIStreamResult, errorChan <-chan error, done <-chan struct{}) {
 	for {
 		select {
-		case result := <-stream.AIStreamClient.ResultChan:
-			// 处理 AI 结果
-			logger.Info("Received AI result",
+		case result := <-resultChan:
+			if result == nil {
+				continue
+			}
+			logger.Debug("Received AI result",
 				logger.String("stream_id", stream.StreamID),
 				logger.String("result_type", result.ResultType),
 				logger.Float64("confidence", result.Confidence),
 				logger.Int32("sequence", result.Sequence))
 
-			// TODO: 将结果发送到前端或存储
-			// 可以通过 WebSocket 或其他方式通知客户端
+			p.publishAIResult(stream, result)
 
-		case err := <-stream.AIStreamClient.ErrorChan:
+		case err := <-errorChan:
 			logger.Error("AI stream error",
 				logger.String("stream_id", stream.StreamID),
 				logger.Err(err))
 			return
 
-		case <-stream.AIStreamClient.Done:
+		case <-done:
 			logger.Info("AI stream done",
 				logger.String("stream_id", stream.StreamID))
 			return
 		}
 
-		if !stream.IsActive {
+		if !stream.IsActive.Load() {
 			return
 		}
 	}
 }
+
+// newRoomFrameLimiter 创建按房间的帧率限制器，突发量为 1 秒的配额
+func newRoomFrameLimiter(fps float64) *roomFrameLimiter {
+	burst := fps
+	if burst < 1 {
+		burst = 1
+	}
+	return &roomFrameLimiter{
+		rate:  fps,
+		burst: burst,
+		rooms: make(map[string]*frameBucket),
+		now:   time.Now,
+	}
+}
+
+// Allow 房间是否还有配额发送一帧
+func (l *roomFrameLimiter) Allow(roomID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.rooms[roomID]
+	if !ok {
+		bucket = &frameBucket{tokens: l.burst, last: now}
+		l.rooms[roomID] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// Forget 房间没有流后释放其令牌桶
+func (l *roomFrameLimiter) Forget(roomID string) {
+	l.mu.Lock()
+	delete(l.rooms, roomID)
+	l.mu.Unlock()
+}
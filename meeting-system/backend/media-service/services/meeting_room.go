@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"meeting-system/shared/logger"
+	sharedmodels "meeting-system/shared/models"
+)
+
+// ErrParticipantNotAdmitted 参与者在等候室中、被拒绝或被封禁，不能建立媒体连接
+var ErrParticipantNotAdmitted = errors.New("participant is not admitted to the meeting")
+
+// checkParticipantAdmitted 会议服务记录的参与状态为等候中/已拒绝/已封禁时拒绝加入媒体房间；
+// 没有参与记录或无法查询时放行（信令服务同样把关）
+func (s *WebRTCService) checkParticipantAdmitted(roomID, userID string) error {
+	if s.mediaService == nil || s.mediaService.db == nil {
+		return nil
+	}
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	meetingID := s.roomMeetingID(roomID)
+	if meetingID == 0 {
+		return nil
+	}
+
+	var participant sharedmodels.MeetingParticipant
+	err = s.mediaService.db.Select("status").
+		Where("meeting_id = ? AND user_id = ?", meetingID, uid).
+		Take(&participant).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn(fmt.Sprintf("Failed to load participant status for user %s in room %s: %v", userID, roomID, err))
+		}
+		return nil
+	}
+	switch participant.Status {
+	case sharedmodels.ParticipantStatusWaiting, sharedmodels.ParticipantStatusRejected, sharedmodels.ParticipantStatusBanned:
+		return ErrParticipantNotAdmitted
+	}
+	return nil
+}
+
+// isMeetingHostFromDB 会议创建者，或角色为主办人/主持人的参与者
+func (s *WebRTCService) isMeetingHostFromDB(meetingID, userID uint) (bool, error) {
+	if s.mediaService == nil || s.mediaService.db == nil {
+		return false, errors.New("database not available")
+	}
+	var meeting sharedmodels.Meeting
+	if err := s.mediaService.db.Select("creator_id").First(&meeting, meetingID).Error; err != nil {
+		return false, err
+	}
+	if meeting.CreatorID == userID {
+		return true, nil
+	}
+
+	var count int64
+	err := s.mediaService.db.Model(&sharedmodels.MeetingParticipant{}).
+		Where("meeting_id = ? AND user_id = ? AND role IN ?", meetingID, userID,
+			[]sharedmodels.ParticipantRole{sharedmodels.ParticipantRoleHost, sharedmodels.ParticipantRoleModerator}).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// roomMeetingID 通过 meeting_rooms 找到房间所属会议；未建表或查询不到时按房间 ID 格式推断
+// （会议服务生成的房间 ID 为 room_<meetingID>_<seed>_<rand>，测试与旧客户端可能直接用会议 ID 作为房间 ID）
+func (s *WebRTCService) roomMeetingID(roomID string) uint {
+	if s.mediaService != nil && s.mediaService.db != nil {
+		var room sharedmodels.MeetingRoom
+		err := s.mediaService.db.Select("meeting_id").Where("room_id = ?", roomID).Take(&room).Error
+		if err == nil {
+			return room.MeetingID
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn(fmt.Sprintf("Failed to resolve meeting for room %s: %v", roomID, err))
+		}
+	}
+	return meetingIDFromRoomID(roomID)
+}
+
+// meetingIDFromRoomID 从房间 ID 推断会议 ID，无法推断时返回 0
+func meetingIDFromRoomID(roomID string) uint {
+	if id, err := strconv.ParseUint(roomID, 10, 64); err == nil {
+		return uint(id)
+	}
+	if rest, ok := strings.CutPrefix(roomID, "room_"); ok {
+		if idx := strings.IndexByte(rest, '_'); idx > 0 {
+			if id, err := strconv.ParseUint(rest[:idx], 10, 64); err == nil {
+				return uint(id)
+			}
+		}
+	}
+	return 0
+}
+
+// meetingRoomID 找到会议对应的媒体房间：优先本节点已有的房间，其次 meeting_rooms 中未关闭的最新房间，
+// 都没有时以会议 ID 作为房间 ID（与 meetingIDFromRoomID 的推断规则一致）
+func (s *WebRTCService) meetingRoomID(meetingID uint) string {
+	s.roomsMux.RLock()
+	roomIDs := make([]string, 0, len(s.rooms))
+	for roomID := range s.rooms {
+		roomIDs = append(roomIDs, roomID)
+	}
+	s.roomsMux.RUnlock()
+	for _, roomID := range roomIDs {
+		if s.roomMeetingID(roomID) == meetingID {
+			return roomID
+		}
+	}
+
+	if s.mediaService != nil && s.mediaService.db != nil {
+		var room sharedmodels.MeetingRoom
+		err := s.mediaService.db.Select("room_id").
+			Where("meeting_id = ? AND status <> ?", meetingID, sharedmodels.RoomStatusClosed).
+			Order("created_at DESC").Take(&room).Error
+		if err == nil {
+			return room.RoomID
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn(fmt.Sprintf("Failed to resolve room for meeting %d: %v", meetingID, err))
+		}
+	}
+	return strconv.FormatUint(uint64(meetingID), 10)
+}
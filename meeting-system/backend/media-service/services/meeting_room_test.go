@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"meeting-system/shared/config"
+	sharedmodels "meeting-system/shared/models"
+)
+
+// TestWebRTCService_RejectsParticipantsNotAdmitted 等候室中、被拒绝或被封禁的用户不能加入媒体房间
+func TestWebRTCService_RejectsParticipantsNotAdmitted(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&sharedmodels.MeetingRoom{}, &sharedmodels.MeetingParticipant{}))
+	for userID, status := range map[uint]sharedmodels.ParticipantStatus{
+		7: sharedmodels.ParticipantStatusJoined,
+		8: sharedmodels.ParticipantStatusWaiting,
+		9: sharedmodels.ParticipantStatusBanned,
+	} {
+		require.NoError(t, db.Create(&sharedmodels.MeetingParticipant{MeetingID: 1, UserID: userID, Status: status}).Error)
+	}
+
+	svc := NewWebRTCService(&config.Config{}, &MediaService{db: db}, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	_, err = svc.JoinRoom("room_1_1700000000000000000_1", "7")
+	assert.NoError(t, err)
+	_, err = svc.JoinRoom("1", "42")
+	assert.NoError(t, err, "没有参与记录时由信令服务把关")
+
+	for _, userID := range []string{"8", "9"} {
+		_, err = svc.JoinRoom("1", userID)
+		assert.ErrorIs(t, err, ErrParticipantNotAdmitted, "user %s", userID)
+		_, _, err = svc.CreateAnswer("1", userID, &webrtc.SessionDescription{Type: webrtc.SDPTypeOffer})
+		assert.ErrorIs(t, err, ErrParticipantNotAdmitted, "user %s", userID)
+	}
+}
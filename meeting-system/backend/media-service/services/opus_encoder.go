@@ -0,0 +1,26 @@
+//go:build opus
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/hraban/opus"
+)
+
+// OpusEncoderAvailable 是否以 opus 构建标签编译
+const OpusEncoderAvailable = true
+
+// newOpusEncoder 基于 libopus 的编码器。需要 libopus 开发包与 CGO，构建示例：
+//
+//	go build -tags "opus nolibopusfile" .
+func newOpusEncoder(sampleRate, channels int) (opusFrameEncoder, error) {
+	enc, err := opus.NewEncoder(sampleRate, channels, opus.AppVoIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create opus encoder: %w", err)
+	}
+	// 混音流在弱网下也需要可恢复，开启带内 FEC
+	_ = enc.SetInBandFEC(true)
+	_ = enc.SetPacketLossPerc(10)
+	return enc, nil
+}
@@ -0,0 +1,10 @@
+//go:build !opus
+
+package services
+
+// OpusEncoderAvailable 是否以 opus 构建标签编译
+const OpusEncoderAvailable = false
+
+// 在未启用 opus 构建标签或缺少 libopus 时的占位实现：混音仍可供内部 PCM 消费者（录制、SIP 等）使用，
+// 但无法输出 Opus 轨道，订阅房间混音返回 ErrOpusEncoderUnavailable。Docker 镜像默认以 opus 标签构建。
+func newOpusEncoder(_, _ int) (opusFrameEncoder, error) { return nil, ErrOpusEncoderUnavailable }
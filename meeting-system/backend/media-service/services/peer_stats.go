@@ -0,0 +1,571 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/stats"
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/metrics"
+)
+
+const (
+	defaultStatsInterval         = 5 * time.Second
+	defaultStatsHistoryRetention = 30 * time.Minute
+	// maxStatsHistorySamples 单个 Peer 历史样本数上限（保留时长/采集周期过大时兜底）
+	maxStatsHistorySamples = 2048
+	statsMetricsService    = "media-service"
+
+	StatsDirectionInbound  = "inbound"  // 发布者 -> SFU
+	StatsDirectionOutbound = "outbound" // SFU -> 订阅者
+)
+
+// RTPStreamStats 单个 RTP 流的累计统计与瞬时质量。inbound 的 NACK/PLI/FIR 为 SFU 发给发布者的请求数，
+// outbound 的为订阅者发来的请求数；outbound 的丢包/抖动/RTT 来自订阅者的 RTCP RR
+type RTPStreamStats struct {
+	Direction       string  `json:"direction"`
+	TrackKey        string  `json:"track_key"`
+	TrackID         string  `json:"track_id"`
+	PublisherPeerID string  `json:"publisher_peer_id"`
+	Kind            string  `json:"kind"`
+	Codec           string  `json:"codec"`
+	SSRC            uint32  `json:"ssrc"`
+	Packets         uint64  `json:"packets"`
+	Bytes           uint64  `json:"bytes"`
+	PacketsLost     int64   `json:"packets_lost"`
+	FractionLost    float64 `json:"fraction_lost"`
+	JitterMs        float64 `json:"jitter_ms"`
+	RTTMs           float64 `json:"rtt_ms,omitempty"`
+	NACKCount       uint32  `json:"nack_count"`
+	PLICount        uint32  `json:"pli_count"`
+	FIRCount        uint32  `json:"fir_count"`
+	BitrateBps      uint64  `json:"bitrate_bps"`
+	Frames          uint64  `json:"frames,omitempty"`
+}
+
+// PeerStats Peer 在某一时刻的媒体质量快照
+type PeerStats struct {
+	PeerID    string    `json:"peer_id"`
+	UserID    string    `json:"user_id"`
+	RoomID    string    `json:"room_id"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	// RTTMs 订阅方向各流 RTT 的平均值（0 表示尚无 RR）
+	RTTMs              float64          `json:"rtt_ms"`
+	InboundBitrateBps  uint64           `json:"inbound_bitrate_bps"`
+	OutboundBitrateBps uint64           `json:"outbound_bitrate_bps"`
+	PacketsLost        int64            `json:"packets_lost"`
+	Inbound            []RTPStreamStats `json:"inbound"`
+	Outbound           []RTPStreamStats `json:"outbound"`
+}
+
+// PeerStatsHistory Peer 的滚动统计历史；Peer 离开后保留到保留期结束，便于事后排查
+type PeerStatsHistory struct {
+	PeerID   string      `json:"peer_id"`
+	UserID   string      `json:"user_id"`
+	RoomID   string      `json:"room_id"`
+	ClosedAt *time.Time  `json:"closed_at,omitempty"`
+	Samples  []PeerStats `json:"samples"`
+}
+
+// streamCounters 上一次提交的累计值，用于计算码率与 Prometheus 计数器增量
+type streamCounters struct {
+	packets, bytes, lost uint64
+	nacks, plis, firs    uint64
+	frames               uint64
+	at                   time.Time
+}
+
+// peerStatsCollector 持有每个 PeerConnection 的统计拦截器 Getter、上次采样的累计值与滚动历史
+type peerStatsCollector struct {
+	mu         sync.Mutex
+	getters    map[*webrtc.PeerConnection]stats.Getter
+	last       map[string]streamCounters
+	histories  map[string]*PeerStatsHistory
+	rooms      map[string]struct{}
+	retention  time.Duration
+	maxSamples int
+}
+
+func newPeerStatsCollector(interval, retention time.Duration) *peerStatsCollector {
+	maxSamples := maxStatsHistorySamples
+	if interval > 0 {
+		if n := int(retention / interval); n > 0 && n < maxSamples {
+			maxSamples = n
+		}
+	}
+	return &peerStatsCollector{
+		getters:    make(map[*webrtc.PeerConnection]stats.Getter),
+		last:       make(map[string]streamCounters),
+		histories:  make(map[string]*PeerStatsHistory),
+		rooms:      make(map[string]struct{}),
+		retention:  retention,
+		maxSamples: maxSamples,
+	}
+}
+
+func (c *peerStatsCollector) register(pc *webrtc.PeerConnection, getter stats.Getter) {
+	c.mu.Lock()
+	c.getters[pc] = getter
+	c.mu.Unlock()
+}
+
+func (c *peerStatsCollector) getter(pc *webrtc.PeerConnection) stats.Getter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getters[pc]
+}
+
+func streamCounterKey(peerID, direction string, ssrc uint32) string {
+	return peerID + "/" + direction + "/" + strconv.FormatUint(uint64(ssrc), 10)
+}
+
+// bitrate 相对上次提交的平均码率（尚未提交过时为 0）
+func (c *peerStatsCollector) bitrate(peerID string, st *RTPStreamStats, now time.Time) uint64 {
+	c.mu.Lock()
+	prev, ok := c.last[streamCounterKey(peerID, st.Direction, st.SSRC)]
+	c.mu.Unlock()
+	if !ok || st.Bytes < prev.bytes {
+		return 0
+	}
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return uint64(float64(st.Bytes-prev.bytes) * 8 / elapsed)
+}
+
+func counterDelta(cur, prev uint64) uint64 {
+	if cur < prev {
+		// SSRC 复用或统计重置
+		return cur
+	}
+	return cur - prev
+}
+
+func nonNegative(v int64) uint64 {
+	if v < 0 {
+		return 0
+	}
+	return uint64(v)
+}
+
+// commit 把快照写入历史并更新累计值，返回本周期各流的增量（用于 Prometheus）
+func (c *peerStatsCollector) commit(sample PeerStats) []metrics.RTPStreamSample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deltas := make([]metrics.RTPStreamSample, 0, len(sample.Inbound)+len(sample.Outbound))
+	for _, streams := range [][]RTPStreamStats{sample.Inbound, sample.Outbound} {
+		for _, st := range streams {
+			cur := streamCounters{
+				packets: st.Packets,
+				bytes:   st.Bytes,
+				lost:    nonNegative(st.PacketsLost),
+				nacks:   uint64(st.NACKCount),
+				plis:    uint64(st.PLICount),
+				firs:    uint64(st.FIRCount),
+				frames:  st.Frames,
+				at:      sample.Timestamp,
+			}
+			key := streamCounterKey(sample.PeerID, st.Direction, st.SSRC)
+			prev := c.last[key]
+			c.last[key] = cur
+
+			d := metrics.RTPStreamSample{
+				Direction:     st.Direction,
+				Kind:          st.Kind,
+				Packets:       counterDelta(cur.packets, prev.packets),
+				Bytes:         counterDelta(cur.bytes, prev.bytes),
+				PacketsLost:   counterDelta(cur.lost, prev.lost),
+				NACKs:         counterDelta(cur.nacks, prev.nacks),
+				PLIs:          counterDelta(cur.plis, prev.plis),
+				FIRs:          counterDelta(cur.firs, prev.firs),
+				Frames:        counterDelta(cur.frames, prev.frames),
+				JitterSeconds: st.JitterMs / 1000,
+				FractionLost:  -1,
+			}
+			if st.Direction == StatsDirectionOutbound {
+				d.FractionLost = st.FractionLost
+			}
+			deltas = append(deltas, d)
+		}
+	}
+
+	h := c.histories[sample.PeerID]
+	if h == nil {
+		h = &PeerStatsHistory{PeerID: sample.PeerID, UserID: sample.UserID, RoomID: sample.RoomID}
+		c.histories[sample.PeerID] = h
+	}
+	h.Samples = append(h.Samples, sample)
+	if len(h.Samples) > c.maxSamples {
+		h.Samples = append([]PeerStats(nil), h.Samples[len(h.Samples)-c.maxSamples:]...)
+	}
+	return deltas
+}
+
+// closePeer Peer 离开：释放 Getter 与累计值，历史保留到保留期结束
+func (c *peerStatsCollector) closePeer(peerID string, pc *webrtc.PeerConnection, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pc != nil {
+		delete(c.getters, pc)
+	}
+	prefix := peerID + "/"
+	for key := range c.last {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.last, key)
+		}
+	}
+	if h := c.histories[peerID]; h != nil && h.ClosedAt == nil {
+		closedAt := now
+		h.ClosedAt = &closedAt
+	}
+}
+
+// prune 丢弃超出保留期的样本与已离开 Peer 的历史，返回已不再活跃、需要删除指标的房间
+func (c *peerStatsCollector) prune(now time.Time, activeRooms map[string]struct{}) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := now.Add(-c.retention)
+	for peerID, h := range c.histories {
+		if h.ClosedAt != nil && h.ClosedAt.Before(cutoff) {
+			delete(c.histories, peerID)
+			continue
+		}
+		drop := sort.Search(len(h.Samples), func(i int) bool { return !h.Samples[i].Timestamp.Before(cutoff) })
+		if drop > 0 {
+			h.Samples = append([]PeerStats(nil), h.Samples[drop:]...)
+		}
+	}
+
+	var stale []string
+	for roomID := range c.rooms {
+		if _, ok := activeRooms[roomID]; !ok {
+			stale = append(stale, roomID)
+			delete(c.rooms, roomID)
+		}
+	}
+	for roomID := range activeRooms {
+		c.rooms[roomID] = struct{}{}
+	}
+	return stale
+}
+
+func copyHistory(h *PeerStatsHistory, since time.Time) PeerStatsHistory {
+	out := *h
+	out.Samples = make([]PeerStats, 0, len(h.Samples))
+	for _, s := range h.Samples {
+		if !s.Timestamp.Before(since) {
+			out.Samples = append(out.Samples, s)
+		}
+	}
+	return out
+}
+
+func (c *peerStatsCollector) history(peerID string, since time.Time) (PeerStatsHistory, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.histories[peerID]
+	if !ok {
+		return PeerStatsHistory{}, false
+	}
+	return copyHistory(h, since), true
+}
+
+func (c *peerStatsCollector) roomHistory(roomID string, since time.Time) []PeerStatsHistory {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]PeerStatsHistory, 0)
+	for _, h := range c.histories {
+		if h.RoomID == roomID {
+			out = append(out, copyHistory(h, since))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PeerID < out[j].PeerID })
+	return out
+}
+
+func (s *WebRTCService) statsInterval() time.Duration {
+	if s.config != nil && s.config.WebRTC.Stats.Interval > 0 {
+		return time.Duration(s.config.WebRTC.Stats.Interval) * time.Second
+	}
+	return defaultStatsInterval
+}
+
+func (s *WebRTCService) statsHistoryRetention() time.Duration {
+	if s.config != nil && s.config.WebRTC.Stats.HistoryRetention > 0 {
+		return time.Duration(s.config.WebRTC.Stats.HistoryRetention) * time.Minute
+	}
+	return defaultStatsHistoryRetention
+}
+
+// newStatsInterceptors 统计拦截器（为每个 PeerConnection 记录 RTP/RTCP 统计）与 SR/RR 生成拦截器（RTT 与
+// 订阅者丢包/抖动依赖 SFU 发送 SR、发布者收到 RR）。OnNewPeerConnection 在 NewPeerConnection 内同步回调，
+// 由 createPeerConnection 在 pcCreateMux 下取走对应的 Getter。
+func (s *WebRTCService) newStatsInterceptors() (*interceptor.Registry, error) {
+	registry := &interceptor.Registry{}
+	statsFactory, err := stats.NewInterceptor()
+	if err != nil {
+		return nil, err
+	}
+	statsFactory.OnNewPeerConnection(func(_ string, getter stats.Getter) {
+		s.pendingStatsGetter = getter
+	})
+	registry.Add(statsFactory)
+	if err := webrtc.ConfigureRTCPReports(registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// readReceiverRTCP 读取发布者发来的 RTCP（SR/SDES），驱动拦截器链记录发布方向的统计
+func readReceiverRTCP(receiver *webrtc.RTPReceiver) {
+	buf := make([]byte, 1500)
+	for {
+		if _, _, err := receiver.Read(buf); err != nil {
+			return
+		}
+	}
+}
+
+// startStatsCollection 周期性采集所有 Peer 的统计
+func (s *WebRTCService) startStatsCollection() {
+	ticker := time.NewTicker(s.statsInterval())
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		s.collectPeerStats(now)
+	}
+}
+
+// collectPeerStats 采集一次所有 Peer 的快照：写入历史，按房间导出 Prometheus 指标，并清理已关闭房间的时间序列
+func (s *WebRTCService) collectPeerStats(now time.Time) {
+	s.roomsMux.RLock()
+	rooms := make([]*Room, 0, len(s.rooms))
+	for _, room := range s.rooms {
+		if room != nil {
+			rooms = append(rooms, room)
+		}
+	}
+	s.roomsMux.RUnlock()
+
+	active := make(map[string]struct{}, len(rooms))
+	for _, room := range rooms {
+		active[room.ID] = struct{}{}
+		bitrates := make(map[[2]string]uint64)
+		for _, peer := range room.peerList() {
+			sample, ok := s.samplePeer(room, peer, now)
+			if !ok {
+				continue
+			}
+			for _, d := range s.peerStats.commit(sample) {
+				metrics.RecordWebRTCStream(statsMetricsService, room.ID, d)
+			}
+			for _, st := range append(sample.Inbound, sample.Outbound...) {
+				bitrates[[2]string{st.Direction, st.Kind}] += st.BitrateBps
+			}
+			if sample.RTTMs > 0 {
+				metrics.RecordWebRTCRoundTripTime(statsMetricsService, room.ID, sample.RTTMs/1000)
+			}
+		}
+		for key, bps := range bitrates {
+			metrics.UpdateWebRTCBitrate(statsMetricsService, room.ID, key[0], key[1], float64(bps))
+		}
+	}
+
+	for _, roomID := range s.peerStats.prune(now, active) {
+		metrics.DeleteWebRTCRoomMetrics(statsMetricsService, roomID)
+	}
+}
+
+func (r *Room) peerList() []*Peer {
+	r.PeersMux.RLock()
+	defer r.PeersMux.RUnlock()
+	peers := make([]*Peer, 0, len(r.Peers))
+	for _, peer := range r.Peers {
+		if peer != nil {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// samplePeer 读取 Peer 当前的累计统计（不写入历史）。没有 PeerConnection 统计的 Peer（SIP 呼叫等）返回 false
+func (s *WebRTCService) samplePeer(room *Room, peer *Peer, now time.Time) (PeerStats, bool) {
+	if peer.Connection == nil {
+		return PeerStats{}, false
+	}
+	getter := s.peerStats.getter(peer.Connection)
+	if getter == nil {
+		return PeerStats{}, false
+	}
+
+	sample := PeerStats{
+		PeerID:    peer.ID,
+		UserID:    peer.UserID,
+		RoomID:    room.ID,
+		Status:    peer.Status,
+		Timestamp: now,
+		Inbound:   make([]RTPStreamStats, 0, 2),
+		Outbound:  make([]RTPStreamStats, 0, 4),
+	}
+
+	room.TracksMux.RLock()
+	for _, ft := range room.Tracks {
+		if ft == nil || ft.LocalTrack == nil {
+			continue
+		}
+		base := RTPStreamStats{
+			TrackKey:        ft.Key,
+			TrackID:         ft.LocalTrack.ID(),
+			PublisherPeerID: ft.SenderPeer,
+			Kind:            ft.LocalTrack.Kind().String(),
+			Codec:           ft.LocalTrack.Codec().MimeType,
+		}
+		if ft.SenderPeer == peer.ID && ft.RemoteTrack != nil {
+			if st := getter.Get(ft.RemoteSSRC); st != nil {
+				sample.Inbound = append(sample.Inbound, inboundStreamStats(base, ft.RemoteSSRC, st, ft.LocalTrack))
+			}
+		}
+		if ssrc, ok := senderSSRC(ft.SubscriberSenders[peer.ID]); ok {
+			if st := getter.Get(uint32(ssrc)); st != nil {
+				sample.Outbound = append(sample.Outbound, outboundStreamStats(base, uint32(ssrc), st))
+			}
+		}
+	}
+	room.TracksMux.RUnlock()
+
+	sort.Slice(sample.Inbound, func(i, j int) bool { return sample.Inbound[i].TrackKey < sample.Inbound[j].TrackKey })
+	sort.Slice(sample.Outbound, func(i, j int) bool { return sample.Outbound[i].TrackKey < sample.Outbound[j].TrackKey })
+
+	var rttSum float64
+	rttCount := 0
+	for i := range sample.Inbound {
+		st := &sample.Inbound[i]
+		st.BitrateBps = s.peerStats.bitrate(peer.ID, st, now)
+		sample.InboundBitrateBps += st.BitrateBps
+		sample.PacketsLost += st.PacketsLost
+	}
+	for i := range sample.Outbound {
+		st := &sample.Outbound[i]
+		st.BitrateBps = s.peerStats.bitrate(peer.ID, st, now)
+		sample.OutboundBitrateBps += st.BitrateBps
+		sample.PacketsLost += st.PacketsLost
+		if st.RTTMs > 0 {
+			rttSum += st.RTTMs
+			rttCount++
+		}
+	}
+	if rttCount > 0 {
+		sample.RTTMs = rttSum / float64(rttCount)
+	}
+	return sample, true
+}
+
+func inboundStreamStats(base RTPStreamStats, ssrc uint32, st *stats.Stats, track *ForwardingTrack) RTPStreamStats {
+	base.Direction = StatsDirectionInbound
+	base.SSRC = ssrc
+	base.Packets = st.InboundRTPStreamStats.PacketsReceived
+	base.Bytes = st.InboundRTPStreamStats.BytesReceived
+	base.PacketsLost = st.InboundRTPStreamStats.PacketsLost
+	if received := float64(base.Packets) + float64(base.PacketsLost); base.PacketsLost > 0 && received > 0 {
+		base.FractionLost = float64(base.PacketsLost) / received
+	}
+	// 接收方向的抖动以 RTP 时钟单位累计
+	if clockRate := track.Codec().ClockRate; clockRate > 0 {
+		base.JitterMs = st.InboundRTPStreamStats.Jitter / float64(clockRate) * 1000
+	}
+	base.RTTMs = float64(st.RemoteOutboundRTPStreamStats.RoundTripTime) / float64(time.Millisecond)
+	base.NACKCount = st.InboundRTPStreamStats.NACKCount
+	base.PLICount = st.InboundRTPStreamStats.PLICount
+	base.FIRCount = st.InboundRTPStreamStats.FIRCount
+	base.Frames = track.Frames()
+	return base
+}
+
+func outboundStreamStats(base RTPStreamStats, ssrc uint32, st *stats.Stats) RTPStreamStats {
+	base.Direction = StatsDirectionOutbound
+	base.SSRC = ssrc
+	base.Packets = st.OutboundRTPStreamStats.PacketsSent
+	base.Bytes = st.OutboundRTPStreamStats.BytesSent
+	base.PacketsLost = st.RemoteInboundRTPStreamStats.PacketsLost
+	base.FractionLost = st.RemoteInboundRTPStreamStats.FractionLost
+	base.JitterMs = st.RemoteInboundRTPStreamStats.Jitter * 1000
+	base.RTTMs = float64(st.RemoteInboundRTPStreamStats.RoundTripTime) / float64(time.Millisecond)
+	base.NACKCount = st.OutboundRTPStreamStats.NACKCount
+	base.PLICount = st.OutboundRTPStreamStats.PLICount
+	base.FIRCount = st.OutboundRTPStreamStats.FIRCount
+	return base
+}
+
+// findPeerRoom 查找 Peer 及其房间
+func (s *WebRTCService) findPeerRoom(peerID string) (*Peer, *Room, error) {
+	s.peersMux.RLock()
+	peer, exists := s.peers[peerID]
+	s.peersMux.RUnlock()
+	if !exists || peer == nil {
+		return nil, nil, fmt.Errorf("peer not found: %s", peerID)
+	}
+
+	s.roomsMux.RLock()
+	room, exists := s.rooms[peer.RoomID]
+	s.roomsMux.RUnlock()
+	if !exists || room == nil {
+		return nil, nil, fmt.Errorf("room not found: %s", peer.RoomID)
+	}
+	return peer, room, nil
+}
+
+// GetPeerStats Peer 当前的媒体质量统计（码率为距上次周期采集的平均值）
+func (s *WebRTCService) GetPeerStats(peerID string) (*PeerStats, error) {
+	peer, room, err := s.findPeerRoom(peerID)
+	if err != nil {
+		return nil, err
+	}
+	sample, ok := s.samplePeer(room, peer, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("stats not available for peer: %s", peerID)
+	}
+	return &sample, nil
+}
+
+// GetRoomPeerStats 房间内所有 Peer 当前的媒体质量统计
+func (s *WebRTCService) GetRoomPeerStats(roomID string) ([]PeerStats, error) {
+	s.roomsMux.RLock()
+	room, exists := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if !exists || room == nil {
+		return nil, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	now := time.Now()
+	out := make([]PeerStats, 0)
+	for _, peer := range room.peerList() {
+		if sample, ok := s.samplePeer(room, peer, now); ok {
+			out = append(out, sample)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PeerID < out[j].PeerID })
+	return out, nil
+}
+
+// GetPeerStatsHistory Peer 自 since 起的统计历史（Peer 离开后在保留期内仍可查询）
+func (s *WebRTCService) GetPeerStatsHistory(peerID string, since time.Time) (*PeerStatsHistory, error) {
+	h, ok := s.peerStats.history(peerID, since)
+	if !ok {
+		return nil, fmt.Errorf("no stats history for peer: %s", peerID)
+	}
+	return &h, nil
+}
+
+// GetRoomStatsHistory 房间内所有 Peer（含已离开的）自 since 起的统计历史
+func (s *WebRTCService) GetRoomStatsHistory(roomID string, since time.Time) []PeerStatsHistory {
+	return s.peerStats.roomHistory(roomID, since)
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/interceptor/pkg/stats"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+)
+
+type fakeStatsGetter map[uint32]*stats.Stats
+
+func (g fakeStatsGetter) Get(ssrc uint32) *stats.Stats { return g[ssrc] }
+
+func TestForwardingTrack_CountsFrames(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "camera", "stream", 0)
+	for seq := uint16(1); seq <= 6; seq++ {
+		pkt := vp8Packet(seq, uint32(seq/2)*3000, seq == 1)
+		pkt.Marker = seq%2 == 0
+		require.NoError(t, track.WriteRTP(pkt))
+	}
+	track.SetMuted(true)
+	pkt := vp8Packet(7, 12000, false)
+	pkt.Marker = true
+	require.NoError(t, track.WriteRTP(pkt))
+	assert.Equal(t, uint64(4), track.Frames(), "暂停转发期间仍统计发布者的帧")
+
+	audio := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000}, "mic", "stream", 0)
+	require.NoError(t, audio.WriteRTP(&rtp.Packet{Header: rtp.Header{Version: 2, Marker: true}, Payload: []byte{1}}))
+	assert.Zero(t, audio.Frames())
+}
+
+// TestWebRTCService_PeerStatsHistory 周期采集计算码率并写入历史，Peer 离开后历史保留到保留期结束
+func TestWebRTCService_PeerStatsHistory(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.WebRTC.Stats.Interval = 5
+	cfg.WebRTC.Stats.HistoryRetention = 1
+	svc := NewWebRTCService(cfg, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	alice := registerTestPeer(t, svc, "room-stats", "7")
+	bob := registerTestPeer(t, svc, "room-stats", "8")
+
+	vp8 := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}
+	local := NewForwardingTrack(vp8, "camera", alice.ID, 0)
+	sender, err := bob.Connection.AddTrack(local)
+	require.NoError(t, err)
+	ssrc, ok := senderSSRC(sender)
+	require.True(t, ok)
+
+	svc.roomsMux.RLock()
+	room := svc.rooms["room-stats"]
+	svc.roomsMux.RUnlock()
+	room.TracksMux.Lock()
+	room.Tracks["peer-7:camera"] = &ForwardedTrack{
+		Key:               "peer-7:camera",
+		SenderPeer:        alice.ID,
+		RemoteTrack:       &webrtc.TrackRemote{},
+		RemoteSSRC:        1111,
+		LocalTrack:        local,
+		SubscriberSenders: map[string]*webrtc.RTPSender{bob.ID: sender},
+		CreatedAt:         time.Now(),
+	}
+	room.TracksMux.Unlock()
+
+	inbound := &stats.Stats{}
+	inbound.InboundRTPStreamStats.PacketsReceived = 100
+	inbound.InboundRTPStreamStats.BytesReceived = 100000
+	inbound.InboundRTPStreamStats.PacketsLost = 2
+	inbound.InboundRTPStreamStats.Jitter = 900 // 90kHz 时钟 -> 10ms
+	inbound.InboundRTPStreamStats.PLICount = 1
+	outbound := &stats.Stats{}
+	outbound.OutboundRTPStreamStats.PacketsSent = 90
+	outbound.OutboundRTPStreamStats.BytesSent = 90000
+	outbound.OutboundRTPStreamStats.NACKCount = 3
+	outbound.RemoteInboundRTPStreamStats.RoundTripTime = 80 * time.Millisecond
+	outbound.RemoteInboundRTPStreamStats.FractionLost = 0.05
+	outbound.RemoteInboundRTPStreamStats.Jitter = 0.02
+	svc.peerStats.register(alice.Connection, fakeStatsGetter{1111: inbound})
+	svc.peerStats.register(bob.Connection, fakeStatsGetter{uint32(ssrc): outbound})
+
+	start := time.Now()
+	svc.collectPeerStats(start)
+	inbound.InboundRTPStreamStats.BytesReceived += 625000 // 5 秒 1Mbps
+	outbound.OutboundRTPStreamStats.BytesSent += 312500
+	svc.collectPeerStats(start.Add(5 * time.Second))
+
+	history, err := svc.GetPeerStatsHistory(alice.ID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, history.Samples, 2)
+	latest := history.Samples[1]
+	require.Len(t, latest.Inbound, 1)
+	assert.Empty(t, latest.Outbound)
+	in := latest.Inbound[0]
+	assert.Equal(t, StatsDirectionInbound, in.Direction)
+	assert.Equal(t, "video", in.Kind)
+	assert.Equal(t, uint64(1000000), in.BitrateBps)
+	assert.InDelta(t, 10.0, in.JitterMs, 0.001)
+	assert.InDelta(t, 2.0/102, in.FractionLost, 0.0001)
+	assert.Equal(t, uint32(1), in.PLICount)
+	assert.Zero(t, history.Samples[0].InboundBitrateBps, "首个样本没有码率基准")
+
+	bobStats, err := svc.GetPeerStats(bob.ID)
+	require.NoError(t, err)
+	require.Len(t, bobStats.Outbound, 1)
+	out := bobStats.Outbound[0]
+	assert.Equal(t, alice.ID, out.PublisherPeerID)
+	assert.InDelta(t, 80.0, out.RTTMs, 0.001)
+	assert.InDelta(t, 20.0, out.JitterMs, 0.001)
+	assert.Equal(t, uint32(3), out.NACKCount)
+	assert.InDelta(t, 80.0, bobStats.RTTMs, 0.001)
+
+	roomStats, err := svc.GetRoomPeerStats("room-stats")
+	require.NoError(t, err)
+	assert.Len(t, roomStats, 2)
+
+	// 离开后历史仍可查询，超出保留期后清理
+	svc.cleanupPeer(alice.ID, "test")
+	history, err = svc.GetPeerStatsHistory(alice.ID, time.Time{})
+	require.NoError(t, err)
+	require.NotNil(t, history.ClosedAt)
+	assert.Len(t, svc.GetRoomStatsHistory("room-stats", time.Time{}), 2)
+	assert.Len(t, svc.GetRoomStatsHistory("room-stats", start.Add(time.Second)), 2, "since 只过滤样本")
+
+	svc.collectPeerStats(time.Now().Add(2 * time.Minute))
+	_, err = svc.GetPeerStatsHistory(alice.ID, time.Time{})
+	assert.Error(t, err)
+}
+
+func TestPeerStatsCollector_CapsSamples(t *testing.T) {
+	c := newPeerStatsCollector(time.Second, 3*time.Second)
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		c.commit(PeerStats{PeerID: "p", RoomID: "r", Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+	h, ok := c.history("p", time.Time{})
+	require.True(t, ok)
+	require.Len(t, h.Samples, 3)
+	assert.Equal(t, now.Add(2*time.Second), h.Samples[0].Timestamp)
+
+	assert.Empty(t, c.prune(now, map[string]struct{}{"r": {}}))
+	assert.Equal(t, []string{"r"}, c.prune(now, nil), "房间关闭后返回以删除指标")
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+)
+
+const (
+	// mimeTypeAudioRED RFC 2198 冗余音频
+	mimeTypeAudioRED = "audio/red"
+	// audioREDPayloadType 与 Chrome 一致的 RED PayloadType，冗余编码为默认 Opus(111)
+	audioREDPayloadType = 63
+)
+
+// isAudioRED 判断编码是否为音频 RED
+func isAudioRED(mimeType string) bool {
+	return strings.EqualFold(mimeType, mimeTypeAudioRED)
+}
+
+// audioREDCodec SFU 注册的 RED 编码（封装 Opus）
+func audioREDCodec() webrtc.RTPCodecParameters {
+	return webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeAudioRED,
+			ClockRate:   48000,
+			Channels:    2,
+			SDPFmtpLine: "111/111",
+		},
+		PayloadType: audioREDPayloadType,
+	}
+}
+
+// redBlockPayloadType 从 RED 的 fmtp（如 "111/111"）中取出被冗余编码的 PayloadType
+func redBlockPayloadType(fmtpLine string) uint8 {
+	first := strings.TrimSpace(strings.SplitN(fmtpLine, "/", 2)[0])
+	pt, err := strconv.ParseUint(first, 10, 7)
+	if err != nil {
+		return 0
+	}
+	return uint8(pt)
+}
+
+// parseREDHeaders 解析 RED 头部，返回头部总长度与各冗余块长度（不含主编码块）
+//
+//	 0                   1                   2                   3
+//	|F|   block PT  |  timestamp offset         |   block length    |
+//
+// 最后一个头部 F=0，只有 1 字节（主编码的 block PT），主编码数据位于所有冗余块之后。
+func parseREDHeaders(payload []byte) (headerLen int, blockLens []int, ok bool) {
+	idx := 0
+	for {
+		if idx >= len(payload) {
+			return 0, nil, false
+		}
+		if payload[idx]&0x80 == 0 {
+			idx++
+			break
+		}
+		if idx+4 > len(payload) {
+			return 0, nil, false
+		}
+		blockLens = append(blockLens, int(payload[idx+2]&0x03)<<8|int(payload[idx+3]))
+		idx += 4
+	}
+
+	total := idx
+	for _, l := range blockLens {
+		total += l
+	}
+	if total > len(payload) {
+		return 0, nil, false
+	}
+	return idx, blockLens, true
+}
+
+// redPrimaryPayload 剥离 RED 封装，返回主编码（Opus）数据；解析失败返回 false
+func redPrimaryPayload(payload []byte) ([]byte, bool) {
+	headerLen, blockLens, ok := parseREDHeaders(payload)
+	if !ok {
+		return nil, false
+	}
+	offset := headerLen
+	for _, l := range blockLens {
+		offset += l
+	}
+	return payload[offset:], true
+}
+
+// rewriteREDBlockPayloadType 将 RED 头部中的 block PT 改写为订阅者协商的 Opus PayloadType
+// payload 由多个订阅者共享，需要改写时返回副本。
+func rewriteREDBlockPayloadType(payload []byte, pt uint8) []byte {
+	headerLen, _, ok := parseREDHeaders(payload)
+	if !ok {
+		return payload
+	}
+
+	var out []byte
+	for idx := 0; idx < headerLen; {
+		if payload[idx]&0x7F != pt {
+			if out == nil {
+				out = append([]byte(nil), payload...)
+			}
+			out[idx] = payload[idx]&0x80 | pt
+		}
+		if payload[idx]&0x80 == 0 {
+			break
+		}
+		idx += 4
+	}
+	if out == nil {
+		return payload
+	}
+	return out
+}
+
+// preferAudioRED 将 RED 移到音频编码偏好的首位，使支持 RED 的发布者以 RED 发送
+func preferAudioRED(codecs []webrtc.RTPCodecParameters) []webrtc.RTPCodecParameters {
+	out := make([]webrtc.RTPCodecParameters, 0, len(codecs))
+	for _, c := range codecs {
+		if isAudioRED(c.MimeType) {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return codecs
+	}
+	for _, c := range codecs {
+		if !isAudioRED(c.MimeType) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
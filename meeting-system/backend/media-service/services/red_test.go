@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+)
+
+// redPayload 构造一个冗余块 + 主编码块的 RED payload
+func redPayload(blockPT uint8, redundant, primary []byte) []byte {
+	out := []byte{
+		0x80 | blockPT, 0x03, 0xC0 | byte(len(redundant)>>8), byte(len(redundant)), // F=1, ts offset=960
+		blockPT, // F=0
+	}
+	out = append(out, redundant...)
+	return append(out, primary...)
+}
+
+func TestRedPrimaryPayload(t *testing.T) {
+	payload := redPayload(111, []byte{0x01, 0x02}, []byte{0xAA, 0xBB, 0xCC})
+	primary, ok := redPrimaryPayload(payload)
+	require.True(t, ok)
+	assert.Equal(t, []byte{0xAA, 0xBB, 0xCC}, primary)
+
+	// 只有主编码块
+	primary, ok = redPrimaryPayload([]byte{111, 0xAA})
+	require.True(t, ok)
+	assert.Equal(t, []byte{0xAA}, primary)
+
+	// 冗余块长度越界
+	_, ok = redPrimaryPayload([]byte{0x80 | 111, 0x03, 0xC0, 0x10, 111, 0x01})
+	assert.False(t, ok)
+	_, ok = redPrimaryPayload(nil)
+	assert.False(t, ok)
+}
+
+func TestRewriteREDBlockPayloadType(t *testing.T) {
+	payload := redPayload(111, []byte{0x01}, []byte{0xAA})
+	rewritten := rewriteREDBlockPayloadType(payload, 109)
+	assert.Equal(t, byte(0x80|109), rewritten[0])
+	assert.Equal(t, byte(109), rewritten[4])
+	assert.Equal(t, byte(0x80|111), payload[0], "共享的原始 payload 不应被修改")
+
+	assert.Equal(t, &payload[0], &rewriteREDBlockPayloadType(payload, 111)[0], "PT 一致时不复制")
+}
+
+func TestPreferAudioRED(t *testing.T) {
+	codecs := []webrtc.RTPCodecParameters{
+		{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, PayloadType: 111},
+		audioREDCodec(),
+	}
+	preferred := preferAudioRED(codecs)
+	require.Len(t, preferred, 2)
+	assert.Equal(t, webrtc.PayloadType(audioREDPayloadType), preferred[0].PayloadType)
+	assert.Equal(t, webrtc.PayloadType(111), preferred[1].PayloadType)
+}
+
+func newFakeAudioContext(id string, ssrc webrtc.SSRC, withRED bool) *fakeTrackLocalContext {
+	codecs := []webrtc.RTPCodecParameters{{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2, SDPFmtpLine: "minptime=10;useinbandfec=1"},
+		PayloadType:        109,
+	}}
+	if withRED {
+		codecs = append(codecs, webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: mimeTypeAudioRED, ClockRate: 48000, Channels: 2, SDPFmtpLine: "109/109"},
+			PayloadType:        62,
+		})
+	}
+	return &fakeTrackLocalContext{id: id, ssrc: ssrc, codecs: codecs, writer: &recordingWriter{}}
+}
+
+// TestForwardingTrack_REDPassthroughAndStrip 协商 RED 的订阅者收到 RED（改写内部 PT），未协商的收到剥离后的 Opus
+func TestForwardingTrack_REDPassthroughAndStrip(t *testing.T) {
+	track := NewForwardingTrack(audioREDCodec().RTPCodecCapability, "audio", "stream", 0)
+	assert.True(t, track.IsRED())
+
+	redSub := newFakeAudioContext("red", 1, true)
+	codec, err := track.Bind(redSub)
+	require.NoError(t, err)
+	assert.Equal(t, webrtc.PayloadType(62), codec.PayloadType)
+
+	opusSub := newFakeAudioContext("opus", 2, false)
+	codec, err = track.Bind(opusSub)
+	require.NoError(t, err)
+	assert.Equal(t, webrtc.MimeTypeOpus, codec.MimeType)
+
+	payload := redPayload(111, []byte{0x01, 0x02}, []byte{0xAA, 0xBB})
+	require.NoError(t, track.WriteRTP(&rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: audioREDPayloadType, SequenceNumber: 7, Timestamp: 960, SSRC: 99},
+		Payload: payload,
+	}))
+
+	require.Len(t, redSub.writer.headers, 1)
+	assert.Equal(t, uint8(62), redSub.writer.headers[0].PayloadType)
+	assert.Equal(t, redPayload(109, []byte{0x01, 0x02}, []byte{0xAA, 0xBB}), redSub.writer.payloads[0])
+
+	require.Len(t, opusSub.writer.headers, 1)
+	assert.Equal(t, uint8(109), opusSub.writer.headers[0].PayloadType)
+	assert.Equal(t, uint16(7), opusSub.writer.headers[0].SequenceNumber)
+	assert.Equal(t, []byte{0xAA, 0xBB}, opusSub.writer.payloads[0])
+}
+
+// TestCreateAnswer_PrefersAudioRED 启用 RED 时 Answer 中 RED 排在 Opus 之前，Opus 保留 useinbandfec
+func TestCreateAnswer_PrefersAudioRED(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.WebRTC.EnableAudioRED = true
+	svc := NewWebRTCService(cfg, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	mediaEngine := &webrtc.MediaEngine{}
+	require.NoError(t, mediaEngine.RegisterDefaultCodecs())
+	require.NoError(t, mediaEngine.RegisterCodec(audioREDCodec(), webrtc.RTPCodecTypeAudio))
+	client, err := webrtc.NewAPI(webrtc.WithMediaEngine(mediaEngine)).NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio)
+	require.NoError(t, err)
+	offer, err := client.CreateOffer(nil)
+	require.NoError(t, err)
+
+	answer, _, err := svc.CreateAnswer("room-red", "user-1", &offer)
+	require.NoError(t, err)
+
+	audio := answerCodecs(t, answer)["audio"]
+	require.GreaterOrEqual(t, len(audio), 2)
+	assert.Equal(t, "red", audio[0].Name)
+	for _, c := range audio {
+		if c.Name == "opus" {
+			assert.Contains(t, c.Fmtp, "useinbandfec=1")
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/logger"
+)
+
+// ErrRoomMixForbidden 只有 Peer 本人或会议主持人可以切换混音订阅，只有主持人可以调整混音增益
+var ErrRoomMixForbidden = errors.New("not allowed to control the room mix")
+
+// ErrPeerNotFound Peer 不存在或已离开
+var ErrPeerNotFound = errors.New("peer not found")
+
+// mixer 房间当前的混音器（无人订阅混音时为 nil，转发循环据此跳过解码）
+func (r *Room) mixer() *AudioMixer {
+	r.mixerMux.RLock()
+	defer r.mixerMux.RUnlock()
+	return r.audioMixer
+}
+
+// closeMixer 房间解散时停止混音
+func (r *Room) closeMixer() {
+	r.mixerMux.Lock()
+	mixer := r.audioMixer
+	r.audioMixer = nil
+	r.mixSenders = nil
+	r.mixerMux.Unlock()
+
+	if mixer != nil {
+		mixer.Close()
+	}
+}
+
+// RoomAudioMixer 获取房间混音器，不存在时创建；之后房间内的 Opus 音频轨道会在转发循环中送入混音。
+// 内部消费者（录制、转推、SIP 网关等）可用 Track(RoomMixListener) 或 Subscribe 获取混音。
+func (s *WebRTCService) RoomAudioMixer(roomID string) (*AudioMixer, error) {
+	s.roomsMux.RLock()
+	room := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if room == nil {
+		return nil, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	room.mixerMux.Lock()
+	defer room.mixerMux.Unlock()
+	if room.audioMixer == nil {
+		room.audioMixer = NewAudioMixer(roomID)
+		logger.Info(fmt.Sprintf("Audio mixer started for room %s", roomID))
+	}
+	return room.audioMixer, nil
+}
+
+// SubscribePeerToRoomMix 为 Peer 添加排除其自身声音的 N-1 混音轨道并触发重协商；
+// 混音已包含房间内的 Opus 音频，同时移除该 Peer 对这些音频轨道的逐路订阅，避免听到两遍
+func (s *WebRTCService) SubscribePeerToRoomMix(peerID string) error {
+	s.peersMux.RLock()
+	peer := s.peers[peerID]
+	s.peersMux.RUnlock()
+	if peer == nil || peer.Connection == nil {
+		return fmt.Errorf("peer not found: %s", peerID)
+	}
+
+	mixer, err := s.RoomAudioMixer(peer.RoomID)
+	if err != nil {
+		return err
+	}
+
+	s.roomsMux.RLock()
+	room := s.rooms[peer.RoomID]
+	s.roomsMux.RUnlock()
+	if room == nil {
+		return fmt.Errorf("room not found: %s", peer.RoomID)
+	}
+
+	room.mixerMux.Lock()
+	if _, ok := room.mixSenders[peerID]; ok {
+		room.mixerMux.Unlock()
+		return nil
+	}
+	track, err := mixer.Track(peerID)
+	if err != nil {
+		room.mixerMux.Unlock()
+		return err
+	}
+	rtpSender, err := peer.Connection.AddTrack(track)
+	if err != nil {
+		room.mixerMux.Unlock()
+		return fmt.Errorf("failed to add mix track: %w", err)
+	}
+	if room.mixSenders == nil {
+		room.mixSenders = make(map[string]*webrtc.RTPSender)
+	}
+	room.mixSenders[peerID] = rtpSender
+	room.mixerMux.Unlock()
+
+	for _, sender := range room.detachMixedAudio(peerID) {
+		if err := peer.Connection.RemoveTrack(sender); err != nil {
+			logger.Debug(fmt.Sprintf("RemoveTrack failed for mixed audio (peer=%s): %v", peerID, err))
+		}
+	}
+
+	// 混音轨道不需要处理订阅端 RTCP，但必须读取以免阻塞 interceptor
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			if _, _, err := rtpSender.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	s.RequestRenegotiation(peerID)
+	logger.Info(fmt.Sprintf("Peer %s subscribed to room mix (room=%s)", peerID, peer.RoomID))
+	return nil
+}
+
+// UnsubscribePeerFromRoomMix 移除 Peer 的混音轨道，恢复逐路订阅房间内的音频轨道
+func (s *WebRTCService) UnsubscribePeerFromRoomMix(peerID string) error {
+	s.peersMux.RLock()
+	peer := s.peers[peerID]
+	s.peersMux.RUnlock()
+	if peer == nil {
+		return fmt.Errorf("peer not found: %s", peerID)
+	}
+
+	s.roomsMux.RLock()
+	room := s.rooms[peer.RoomID]
+	s.roomsMux.RUnlock()
+	if room == nil {
+		return nil
+	}
+
+	sender := room.removeMixListener(peerID)
+	if sender == nil || peer.Connection == nil {
+		return nil
+	}
+	if err := peer.Connection.RemoveTrack(sender); err != nil {
+		logger.Debug(fmt.Sprintf("RemoveTrack failed for room mix (peer=%s): %v", peerID, err))
+	}
+	s.subscribePeerToExistingTracks(peer)
+	s.RequestRenegotiation(peerID)
+	return nil
+}
+
+// SetRoomMixGain 设置参与者（发布者 PeerID）在房间混音中的增益
+func (s *WebRTCService) SetRoomMixGain(roomID, participantID string, gain float64) (float64, error) {
+	mixer, err := s.RoomAudioMixer(roomID)
+	if err != nil {
+		return 0, err
+	}
+	mixer.SetGain(participantID, gain)
+	return mixer.Gain(participantID), nil
+}
+
+// AuthorizePeerRoomMix 校验用户能否为 Peer 订阅/取消订阅混音：Peer 本人或其所在会议的主持人
+func (s *WebRTCService) AuthorizePeerRoomMix(peerID string, userID uint) error {
+	s.peersMux.RLock()
+	peer := s.peers[peerID]
+	s.peersMux.RUnlock()
+	if peer == nil {
+		return fmt.Errorf("%w: %s", ErrPeerNotFound, peerID)
+	}
+	if peer.UserID == strconv.FormatUint(uint64(userID), 10) {
+		return nil
+	}
+	return s.requireRoomHost(peer.RoomID, userID)
+}
+
+// AuthorizeRoomMixGain 校验用户能否调整房间混音增益：只有房间所属会议的主持人
+func (s *WebRTCService) AuthorizeRoomMixGain(roomID string, userID uint) error {
+	return s.requireRoomHost(roomID, userID)
+}
+
+// requireRoomHost 房间无法对应到会议时一律拒绝
+func (s *WebRTCService) requireRoomHost(roomID string, userID uint) error {
+	meetingID := s.roomMeetingID(roomID)
+	if meetingID == 0 {
+		return ErrRoomMixForbidden
+	}
+	isHost, err := s.isMeetingHost(meetingID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check meeting host: %w", err)
+	}
+	if !isHost {
+		return ErrRoomMixForbidden
+	}
+	return nil
+}
+
+// onRoomMix Peer 是否订阅了 N-1 混音（此时不再逐路订阅可混音的音频轨道）
+func (r *Room) onRoomMix(peerID string) bool {
+	r.mixerMux.RLock()
+	defer r.mixerMux.RUnlock()
+	_, ok := r.mixSenders[peerID]
+	return ok
+}
+
+// detachMixedAudio 解除 Peer 对可混音音频轨道的逐路订阅，返回需要 RemoveTrack 的 sender
+func (r *Room) detachMixedAudio(peerID string) []*webrtc.RTPSender {
+	r.TracksMux.Lock()
+	defer r.TracksMux.Unlock()
+
+	var senders []*webrtc.RTPSender
+	for _, ft := range r.Tracks {
+		if ft == nil || ft.LocalTrack == nil || !mixableAudio(ft.LocalTrack) {
+			continue
+		}
+		if sender, ok := ft.SubscriberSenders[peerID]; ok {
+			delete(ft.SubscriberSenders, peerID)
+			if sender != nil {
+				senders = append(senders, sender)
+			}
+		}
+	}
+	return senders
+}
+
+// mixableAudio 可送入房间混音的音频轨道（Opus，或取主编码的 RED）
+func mixableAudio(track *ForwardingTrack) bool {
+	return track.Kind() == webrtc.RTPCodecTypeAudio && (track.IsRED() || isOpus(track.Codec().MimeType))
+}
+
+// removeMixListener 释放收听者的 N-1 混音，返回其混音轨道的 sender（未订阅时为 nil）
+func (r *Room) removeMixListener(peerID string) *webrtc.RTPSender {
+	r.mixerMux.Lock()
+	sender := r.mixSenders[peerID]
+	delete(r.mixSenders, peerID)
+	mixer := r.audioMixer
+	r.mixerMux.Unlock()
+
+	if mixer != nil {
+		mixer.RemoveListener(peerID)
+	}
+	return sender
+}
+
+// mixAudioPacket 把发布者的音频 RTP 送入房间混音（RED 取主编码）
+func mixAudioPacket(room *Room, trackKey, senderPeerID string, pkt *rtp.Packet, red bool) {
+	mixer := room.mixer()
+	if mixer == nil {
+		return
+	}
+	payload := pkt.Payload
+	if red {
+		payload, _ = redPrimaryPayload(payload)
+	}
+	if len(payload) > 0 {
+		mixer.PushRTP(trackKey, senderPeerID, pkt.SequenceNumber, pkt.Timestamp, payload)
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/logger"
+	sharedmodels "meeting-system/shared/models"
+)
+
+const (
+	// screenShareStreamSuffix 屏幕共享轨道的 MediaStream ID 后缀（与摄像头的 <peerID> 分开，订阅者单独渲染）
+	screenShareStreamSuffix = "_screen"
+	// defaultScreenShareBandwidthWeight 带宽分配时屏幕共享相对摄像头的默认权重
+	defaultScreenShareBandwidthWeight = 3.0
+	// defaultScreenShareKeyframeInterval 订阅者触发的屏幕共享关键帧请求的默认最小间隔
+	defaultScreenShareKeyframeInterval = 3 * time.Second
+)
+
+// isScreenShareTrack 发布者的视频轨道是否为屏幕共享：Peer 以 screen 媒体类型加入，
+// 或轨道/MediaStream ID 带有 "screen" 标记（客户端在 ScreenShareMessage 中声明同一 stream_id）
+func isScreenShareTrack(kind webrtc.RTPCodecType, peerMediaType, trackID, streamID string) bool {
+	if kind != webrtc.RTPCodecTypeVideo {
+		return false
+	}
+	if peerMediaType == "screen" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(trackID), "screen") || strings.Contains(strings.ToLower(streamID), "screen")
+}
+
+// trackPublishState 新发布轨道的屏幕共享标记与需要继承的房间状态
+type trackPublishState struct {
+	screenShare bool
+	contentHint string
+	muted       bool
+	paused      bool
+}
+
+func (s *WebRTCService) trackPublishState(room *Room, senderPeerID string, kind webrtc.RTPCodecType, trackID, streamID string) trackPublishState {
+	s.peersMux.RLock()
+	peer := s.peers[senderPeerID]
+	s.peersMux.RUnlock()
+	if peer == nil {
+		return trackPublishState{}
+	}
+
+	state := trackPublishState{screenShare: isScreenShareTrack(kind, peer.MediaType, trackID, streamID)}
+	state.muted = room.mediaMuted(peer.UserID, trackModerationMedia(kind, state.screenShare))
+	if state.screenShare {
+		room.screenMux.RLock()
+		state.contentHint = room.screenPresenters[peer.UserID]
+		room.screenMux.RUnlock()
+		state.paused = room.screenSharePaused(peer.UserID)
+	}
+	return state
+}
+
+// apply 在轨道开始转发前写入标记与暂停状态
+func (st trackPublishState) apply(t *ForwardingTrack) {
+	if st.screenShare {
+		t.MarkScreenShare(st.contentHint)
+	}
+	t.SetMuted(st.muted)
+	t.SetScreenSharePaused(st.paused)
+}
+
+// screenSharePaused 独占模式下有其他人正在共享时，该用户的屏幕轨道暂停转发
+func (r *Room) screenSharePaused(userID string) bool {
+	r.screenMux.RLock()
+	defer r.screenMux.RUnlock()
+	if !r.screenExclusive || len(r.screenPresenters) == 0 {
+		return false
+	}
+	_, presenting := r.screenPresenters[userID]
+	return !presenting
+}
+
+// SetScreenShare 处理屏幕共享开始/结束：exclusive 时只有最新的共享者的屏幕轨道被转发（接管），
+// contentHint 更新该用户屏幕轨道的内容提示。返回暂停状态发生变化的轨道数。
+func (s *WebRTCService) SetScreenShare(roomID, userID string, sharing, exclusive bool, contentHint string) (int, error) {
+	s.roomsMux.RLock()
+	room, exists := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if !exists {
+		return 0, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	room.screenMux.Lock()
+	room.screenExclusive = exclusive
+	switch {
+	case sharing && exclusive:
+		room.screenPresenters = map[string]string{userID: contentHint}
+	case sharing:
+		if room.screenPresenters == nil {
+			room.screenPresenters = make(map[string]string)
+		}
+		room.screenPresenters[userID] = contentHint
+	default:
+		delete(room.screenPresenters, userID)
+	}
+	room.screenMux.Unlock()
+
+	owners := make(map[string]string)
+	room.PeersMux.RLock()
+	for peerID, peer := range room.Peers {
+		if peer != nil {
+			owners[peerID] = peer.UserID
+		}
+	}
+	room.PeersMux.RUnlock()
+
+	room.TracksMux.RLock()
+	tracks := make([]*ForwardedTrack, 0, 2)
+	for _, ft := range room.Tracks {
+		if ft != nil && ft.LocalTrack != nil && ft.LocalTrack.IsScreenShare() {
+			tracks = append(tracks, ft)
+		}
+	}
+	room.TracksMux.RUnlock()
+
+	affected := 0
+	for _, ft := range tracks {
+		owner := owners[ft.SenderPeer]
+		if owner == userID && sharing {
+			ft.LocalTrack.SetContentHint(contentHint)
+		}
+		if !ft.LocalTrack.SetScreenSharePaused(room.screenSharePaused(owner)) {
+			continue
+		}
+		affected++
+		if !ft.LocalTrack.Suppressed() {
+			s.sendPLI(ft.SenderPeer, ft.RemoteSSRC)
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Screen share updated (room=%s, user=%s, sharing=%t, exclusive=%t, hint=%s, tracks=%d)",
+		roomID, userID, sharing, exclusive, contentHint, affected))
+	return affected, nil
+}
+
+// SetMeetingScreenShare 处理信令服务的屏幕共享事件（按会议找到本节点的房间）
+func (s *WebRTCService) SetMeetingScreenShare(meetingID, userID uint, sharing, exclusive bool, contentHint string) (int, error) {
+	return s.SetScreenShare(s.meetingRoomID(meetingID), strconv.FormatUint(uint64(userID), 10), sharing, exclusive, contentHint)
+}
+
+// videoBandwidthWeight 订阅者带宽在视频轨道间的分配权重：屏幕共享优先于摄像头，暂停转发的轨道不占带宽
+func (s *WebRTCService) videoBandwidthWeight(t *ForwardingTrack) float64 {
+	switch {
+	case t.Suppressed():
+		return 0
+	case t.IsScreenShare():
+		if s.config != nil && s.config.WebRTC.ScreenShare.BandwidthWeight > 0 {
+			return s.config.WebRTC.ScreenShare.BandwidthWeight
+		}
+		return defaultScreenShareBandwidthWeight
+	default:
+		return 1
+	}
+}
+
+func (s *WebRTCService) screenShareKeyframeInterval() time.Duration {
+	if s.config != nil && s.config.WebRTC.ScreenShare.KeyframeMinInterval > 0 {
+		return time.Duration(s.config.WebRTC.ScreenShare.KeyframeMinInterval) * time.Millisecond
+	}
+	return defaultScreenShareKeyframeInterval
+}
+
+// forwardKeyframeRequest 把订阅者的 PLI/FIR 转发给发布者。屏幕共享（内容提示为 motion 的除外）的关键帧很大，
+// 按最小间隔合并多个订阅者的请求，容忍更长的恢复延迟；新订阅者由关键帧缓存出首帧。
+func (s *WebRTCService) forwardKeyframeRequest(senderPeerID string, publisherSSRC uint32, localTrack *ForwardingTrack) {
+	if localTrack != nil && localTrack.IsScreenShare() && localTrack.ContentHint() != sharedmodels.ScreenShareHintMotion {
+		if !localTrack.AllowKeyframeRequest(time.Now(), s.screenShareKeyframeInterval()) {
+			return
+		}
+	}
+	s.sendPLI(senderPeerID, publisherSSRC)
+}
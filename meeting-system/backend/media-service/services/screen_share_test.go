@@ -0,0 +1,165 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+	sharedmodels "meeting-system/shared/models"
+)
+
+func TestIsScreenShareTrack(t *testing.T) {
+	assert.True(t, isScreenShareTrack(webrtc.RTPCodecTypeVideo, "screen", "video0", "stream"))
+	assert.True(t, isScreenShareTrack(webrtc.RTPCodecTypeVideo, "video", "Screen-Track", ""))
+	assert.True(t, isScreenShareTrack(webrtc.RTPCodecTypeVideo, "video", "v1", "user7-screen"))
+	assert.False(t, isScreenShareTrack(webrtc.RTPCodecTypeVideo, "video", "camera", "user7"))
+	assert.False(t, isScreenShareTrack(webrtc.RTPCodecTypeAudio, "screen", "screen-audio", ""))
+}
+
+// TestForwardingTrack_HoldsStack 强制静音与屏幕共享暂停叠加，全部解除后才恢复转发
+func TestForwardingTrack_HoldsStack(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "screen", "stream", 0)
+	sub := newFakeVP8Context("sub", 1111)
+	_, err := track.Bind(sub)
+	require.NoError(t, err)
+
+	assert.True(t, track.SetScreenSharePaused(true))
+	assert.True(t, track.SetMuted(true))
+	assert.True(t, track.SetScreenSharePaused(false))
+	assert.True(t, track.Suppressed(), "仍被强制静音")
+	require.NoError(t, track.WriteRTP(vp8Packet(100, 9000, true)))
+	assert.Empty(t, sub.writer.headers)
+
+	assert.True(t, track.SetMuted(false))
+	assert.False(t, track.Suppressed())
+	require.NoError(t, track.WriteRTP(vp8Packet(101, 12000, true)))
+	require.Len(t, sub.writer.headers, 1)
+}
+
+func TestForwardingTrack_AllowKeyframeRequest(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "screen", "stream", 0)
+	now := time.Now()
+
+	assert.True(t, track.AllowKeyframeRequest(now, 3*time.Second))
+	assert.False(t, track.AllowKeyframeRequest(now.Add(time.Second), 3*time.Second), "间隔内的请求被合并")
+	assert.True(t, track.AllowKeyframeRequest(now.Add(3*time.Second), 3*time.Second))
+	assert.True(t, track.AllowKeyframeRequest(now.Add(3*time.Second), 0), "摄像头轨道不限制")
+}
+
+func TestWebRTCService_VideoBandwidthWeight(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.WebRTC.ScreenShare.BandwidthWeight = 4
+	svc := NewWebRTCService(cfg, nil, nil)
+
+	vp8 := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}
+	camera := NewForwardingTrack(vp8, "camera", "stream", 0)
+	screen := NewForwardingTrack(vp8, "screen", "stream", 0)
+	screen.MarkScreenShare(sharedmodels.ScreenShareHintDetail)
+
+	assert.Equal(t, 1.0, svc.videoBandwidthWeight(camera))
+	assert.Equal(t, 4.0, svc.videoBandwidthWeight(screen))
+	camera.SetMuted(true)
+	assert.Equal(t, 0.0, svc.videoBandwidthWeight(camera), "暂停转发的轨道不占带宽")
+	assert.Equal(t, defaultScreenShareBandwidthWeight, NewWebRTCService(&config.Config{}, nil, nil).videoBandwidthWeight(screen))
+}
+
+// TestWebRTCService_SubscriberBandwidthShareNonSVC VP8 轨道同样按权重分配订阅者带宽（屏幕共享优先）
+func TestWebRTCService_SubscriberBandwidthShareNonSVC(t *testing.T) {
+	svc := newICERestartTestService(t)
+	presenter := registerTestPeer(t, svc, "1", "7")
+	viewer := registerTestPeer(t, svc, "1", "8")
+	vp8 := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}
+	camera, err := svc.publishExternalTrack("1", presenter.ID, "camera", vp8)
+	require.NoError(t, err)
+	screen, err := svc.publishExternalTrack("1", presenter.ID, "screen", vp8)
+	require.NoError(t, err)
+	screen.MarkScreenShare(sharedmodels.ScreenShareHintDetail)
+
+	assert.Equal(t, uint64(250_000), svc.subscriberBandwidthShare(viewer.ID, camera, 1_000_000))
+	assert.Equal(t, uint64(750_000), svc.subscriberBandwidthShare(viewer.ID, screen, 1_000_000))
+}
+
+// TestForwardingTrack_SubscriberBandwidthLimit 非 SVC 轨道按最慢订阅者的份额限制发布者码率，且限制发送频率
+func TestForwardingTrack_SubscriberBandwidthLimit(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "screen", "stream", 0)
+	for _, ctx := range []*fakeTrackLocalContext{newFakeVP8Context("a", 1), newFakeVP8Context("b", 2)} {
+		_, err := track.Bind(ctx)
+		require.NoError(t, err)
+	}
+
+	limit, ok := track.SetSubscriberBandwidth(1, 800_000)
+	require.True(t, ok)
+	assert.Equal(t, uint64(800_000), limit)
+	limit, _ = track.SetSubscriberBandwidth(2, 300_000)
+	assert.Equal(t, uint64(300_000), limit)
+	limit, _ = track.SetSubscriberBandwidth(2, 900_000)
+	assert.Equal(t, uint64(800_000), limit, "带宽恢复后放开限制")
+	_, ok = track.SetSubscriberBandwidth(3, 100_000)
+	assert.False(t, ok)
+
+	now := time.Now()
+	assert.True(t, track.AllowBitrateReport(now, time.Second))
+	assert.False(t, track.AllowBitrateReport(now.Add(500*time.Millisecond), time.Second))
+	assert.True(t, track.AllowBitrateReport(now.Add(time.Second), time.Second))
+}
+
+// TestWebRTCService_ExclusiveScreenShare 独占模式下接管者的屏幕轨道被转发，之前共享者的轨道暂停
+func TestWebRTCService_ExclusiveScreenShare(t *testing.T) {
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+
+	alice := registerTestPeer(t, svc, "room-screen", "7")
+	bob := registerTestPeer(t, svc, "room-screen", "8")
+
+	vp8 := webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}
+	aliceScreen, err := svc.publishExternalTrack("room-screen", alice.ID, "screen-1", vp8)
+	require.NoError(t, err)
+	aliceCamera, err := svc.publishExternalTrack("room-screen", alice.ID, "camera", vp8)
+	require.NoError(t, err)
+	assert.True(t, aliceScreen.IsScreenShare())
+	assert.False(t, aliceCamera.IsScreenShare())
+
+	_, err = svc.SetScreenShare("room-screen", "7", true, true, sharedmodels.ScreenShareHintText)
+	require.NoError(t, err)
+	assert.False(t, aliceScreen.Suppressed())
+	assert.Equal(t, sharedmodels.ScreenShareHintText, aliceScreen.ContentHint())
+
+	// bob 接管：之后发布的屏幕轨道继承当前共享者与内容提示
+	affected, err := svc.SetScreenShare("room-screen", "8", true, true, sharedmodels.ScreenShareHintMotion)
+	require.NoError(t, err)
+	assert.Equal(t, 1, affected)
+	assert.True(t, aliceScreen.Suppressed())
+	assert.False(t, aliceCamera.Suppressed(), "摄像头不受屏幕共享影响")
+
+	bobScreen, err := svc.publishExternalTrack("room-screen", bob.ID, "screen-1", vp8)
+	require.NoError(t, err)
+	assert.False(t, bobScreen.Suppressed())
+	assert.Equal(t, sharedmodels.ScreenShareHintMotion, bobScreen.ContentHint())
+
+	// 结束共享后不再限制
+	_, err = svc.SetScreenShare("room-screen", "8", false, true, "")
+	require.NoError(t, err)
+	assert.False(t, aliceScreen.Suppressed())
+
+	// 非独占模式下多人可同时共享
+	_, err = svc.SetScreenShare("room-screen", "7", true, false, "")
+	require.NoError(t, err)
+	_, err = svc.SetScreenShare("room-screen", "8", true, false, "")
+	require.NoError(t, err)
+	assert.False(t, aliceScreen.Suppressed())
+	assert.False(t, bobScreen.Suppressed())
+
+	stats, err := svc.GetRoomTrackStats("room-screen")
+	require.NoError(t, err)
+	screens := 0
+	for _, ts := range stats {
+		if ts.ScreenShare {
+			screens++
+		}
+	}
+	assert.Equal(t, 2, screens)
+}
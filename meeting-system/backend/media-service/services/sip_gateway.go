@@ -0,0 +1,641 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"meeting-system/media-service/sip"
+	"meeting-system/shared/config"
+	"meeting-system/shared/logger"
+	sharedmodels "meeting-system/shared/models"
+)
+
+const (
+	sipPeerPrefix   = "sip_"
+	sipAudioTrackID = "sip_audio"
+	// sipResampleRatio 混音 48kHz 与 G.711 8kHz 之间的倍数
+	sipResampleRatio = mixerSampleRate / 8000
+	// sipActivityInterval 电话参与者没有 PeerConnection，由桥接循环节流刷新 LastActivity
+	sipActivityInterval = 2 * time.Second
+	sipDialTimeout      = 60 * time.Second
+	// sipMaxDigits 单段 DTMF 输入的最大长度
+	sipMaxDigits     = 32
+	sipToneAmplitude = 6000
+)
+
+var (
+	// ErrSIPGatewayNotStarted SIP 网关未启动（配置未开启或启动失败）
+	ErrSIPGatewayNotStarted = errors.New("sip gateway not started")
+	// ErrSIPNotMeetingHost 只有会议主持人可以外呼
+	ErrSIPNotMeetingHost = errors.New("only the meeting host can dial out")
+	// ErrSIPCallNotFound 呼叫不存在或已结束
+	ErrSIPCallNotFound = errors.New("sip call not found")
+	// ErrSIPInvalidNumber 外呼号码格式不正确
+	ErrSIPInvalidNumber = errors.New("invalid phone number")
+	// ErrSIPMeetingNotJoinable 会议不存在、已结束或已取消
+	ErrSIPMeetingNotJoinable = errors.New("meeting is not joinable")
+
+	errSIPPINTimeout       = errors.New("dtmf input timeout")
+	errSIPTooManyAttempts  = errors.New("too many pin attempts")
+	errSIPTrunkUnavailable = errors.New("sip outbound trunk not configured")
+
+	sipNumberPattern = regexp.MustCompile(`^\+?[0-9*#]{1,32}$`)
+)
+
+// sipTone 提示音：频率为 0 表示静音段
+type sipTone struct {
+	freq     float64
+	duration time.Duration
+}
+
+var (
+	// sipPromptTone 请输入（会议号/密码，以 # 结束）
+	sipPromptTone = []sipTone{{440, 200 * time.Millisecond}}
+	// sipErrorTone 输入错误，三声短音
+	sipErrorTone = []sipTone{
+		{620, 150 * time.Millisecond}, {0, 100 * time.Millisecond},
+		{620, 150 * time.Millisecond}, {0, 100 * time.Millisecond},
+		{620, 150 * time.Millisecond},
+	}
+	// sipJoinedTone 已入会
+	sipJoinedTone = []sipTone{{660, 120 * time.Millisecond}, {880, 180 * time.Millisecond}}
+)
+
+// SIPCall 已桥接进会议房间的一路电话
+type SIPCall struct {
+	CallID    string        `json:"call_id"`
+	PeerID    string        `json:"peer_id"`
+	MeetingID uint          `json:"meeting_id"`
+	RoomID    string        `json:"room_id"`
+	Number    string        `json:"number"`
+	Direction sip.Direction `json:"direction"`
+	Codec     string        `json:"codec"`
+	StartedAt time.Time     `json:"started_at"`
+}
+
+type sipBridge struct {
+	info SIPCall
+	call *sip.Call
+}
+
+// SIPGateway 媒体服务的 SIP 网关模式：接受电话拨入（DTMF 输入会议号与密码）、由主持人从会议外呼，
+// 把 G.711/Opus 通话以普通参与者身份桥接进房间——主叫声音作为音频轨道发布并送入房间混音，
+// 主叫听到的是排除其自身声音的 N-1 房间混音。
+type SIPGateway struct {
+	cfg    config.SIPConfig
+	webrtc *WebRTCService
+
+	// lookupMeeting/isMeetingHost 默认查询数据库，可在测试中替换
+	lookupMeeting func(meetingID uint) (*sharedmodels.Meeting, error)
+	isMeetingHost func(meetingID, userID uint) (bool, error)
+
+	mu    sync.Mutex
+	ua    *sip.UserAgent
+	calls map[string]*sipBridge
+}
+
+// NewSIPGateway 创建 SIP 网关
+func NewSIPGateway(cfg config.SIPConfig, webrtcService *WebRTCService) *SIPGateway {
+	g := &SIPGateway{
+		cfg:    cfg,
+		webrtc: webrtcService,
+		calls:  make(map[string]*sipBridge),
+	}
+	g.lookupMeeting = g.lookupMeetingFromDB
+	g.isMeetingHost = webrtcService.isMeetingHostFromDB
+	return g
+}
+
+// Start 监听 SIP 端口并开始接受呼叫
+func (g *SIPGateway) Start() error {
+	codecs := []sip.Codec{sip.CodecPCMU, sip.CodecPCMA}
+	if _, err := newMixerEncoder(); err == nil {
+		// 只有能编码 Opus 时才提供宽带通话
+		codecs = append([]sip.Codec{sip.CodecOpus}, codecs...)
+	} else {
+		logger.Info("SIP gateway offers G.711 only: " + err.Error())
+	}
+
+	ua, err := sip.NewUserAgent(sip.Config{
+		ListenAddr:    g.cfg.ListenAddr,
+		PublicIP:      g.cfg.PublicIP,
+		OutboundProxy: g.cfg.OutboundProxy,
+		RTPPortMin:    g.cfg.RTPPortMin,
+		RTPPortMax:    g.cfg.RTPPortMax,
+		Codecs:        codecs,
+	})
+	if err != nil {
+		return err
+	}
+	ua.OnInvite(g.handleInbound)
+
+	g.mu.Lock()
+	g.ua = ua
+	g.mu.Unlock()
+
+	logger.Info(fmt.Sprintf("SIP gateway listening on %s", ua.Addr()))
+	return nil
+}
+
+// Stop 挂断所有呼叫并关闭 SIP 端口
+func (g *SIPGateway) Stop() {
+	g.mu.Lock()
+	ua := g.ua
+	g.ua = nil
+	g.mu.Unlock()
+
+	if ua != nil {
+		ua.Close()
+	}
+}
+
+// Addr 返回 SIP 监听地址，未启动时为 nil
+func (g *SIPGateway) Addr() *net.UDPAddr {
+	ua := g.userAgent()
+	if ua == nil {
+		return nil
+	}
+	return ua.Addr()
+}
+
+// Calls 返回当前已入会的电话，按接通时间排序
+func (g *SIPGateway) Calls() []SIPCall {
+	g.mu.Lock()
+	calls := make([]SIPCall, 0, len(g.calls))
+	for _, b := range g.calls {
+		calls = append(calls, b.info)
+	}
+	g.mu.Unlock()
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].StartedAt.Before(calls[j].StartedAt) })
+	return calls
+}
+
+// Hangup 会议主持人挂断一路电话（参与者随之离开房间）
+func (g *SIPGateway) Hangup(callID string, userID uint) error {
+	g.mu.Lock()
+	b := g.calls[callID]
+	g.mu.Unlock()
+	if b == nil {
+		return ErrSIPCallNotFound
+	}
+
+	isHost, err := g.isMeetingHost(b.info.MeetingID, userID)
+	if err != nil {
+		return err
+	}
+	if !isHost {
+		return ErrSIPNotMeetingHost
+	}
+	b.call.Hangup()
+	return nil
+}
+
+// DialOut 会议主持人从会议外呼 number，对方接听后直接入会（不需要输入会议号）
+func (g *SIPGateway) DialOut(ctx context.Context, meetingID, userID uint, number string) (*SIPCall, error) {
+	ua := g.userAgent()
+	if ua == nil {
+		return nil, ErrSIPGatewayNotStarted
+	}
+	if !sipNumberPattern.MatchString(number) {
+		return nil, ErrSIPInvalidNumber
+	}
+
+	isHost, err := g.isMeetingHost(meetingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isHost {
+		return nil, ErrSIPNotMeetingHost
+	}
+	meeting, err := g.lookupMeeting(meetingID)
+	if err != nil || !sipMeetingJoinable(meeting) {
+		return nil, ErrSIPMeetingNotJoinable
+	}
+
+	target, err := g.dialTarget(number)
+	if err != nil {
+		return nil, err
+	}
+	callerID := g.cfg.CallerID
+	if callerID == "" {
+		callerID = "meeting"
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, sipDialTimeout)
+	defer cancel()
+	call, err := ua.Dial(dialCtx, target, callerID)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s failed: %w", number, err)
+	}
+
+	info, err := g.bridge(call, meeting.ID)
+	if err != nil {
+		call.Hangup()
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("SIP dial-out %s joined meeting %d (host=%d, call=%s)", number, meeting.ID, userID, call.ID()))
+	return info, nil
+}
+
+func (g *SIPGateway) userAgent() *sip.UserAgent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.ua
+}
+
+// dialTarget 外呼被叫 URI：sip:<号码>@<OutboundDomain 或中继主机>
+func (g *SIPGateway) dialTarget(number string) (string, error) {
+	domain := g.cfg.OutboundDomain
+	if domain == "" && g.cfg.OutboundProxy != "" {
+		domain = g.cfg.OutboundProxy
+		if host, _, err := net.SplitHostPort(domain); err == nil {
+			domain = host
+		}
+	}
+	if domain == "" {
+		return "", errSIPTrunkUnavailable
+	}
+	return fmt.Sprintf("sip:%s@%s", number, domain), nil
+}
+
+// handleInbound 拨入：接听后依次收集会议号和密码（以 # 结束），校验通过后入会
+func (g *SIPGateway) handleInbound(call *sip.Call) {
+	if err := call.Answer(); err != nil {
+		return
+	}
+
+	meeting, err := g.collectMeeting(call)
+	if err != nil {
+		logger.Info(fmt.Sprintf("SIP call %s from %s not admitted: %v", call.ID(), call.RemoteUser(), err))
+		call.Hangup()
+		return
+	}
+
+	if _, err := g.bridge(call, meeting.ID); err != nil {
+		logger.Warn(fmt.Sprintf("SIP call %s failed to join meeting %d: %v", call.ID(), meeting.ID, err))
+		g.playTones(call, sipErrorTone)
+		call.Hangup()
+		return
+	}
+	logger.Info(fmt.Sprintf("SIP caller %s joined meeting %d (call=%s)", call.RemoteUser(), meeting.ID, call.ID()))
+}
+
+// collectMeeting 会议号与密码共用 MaxPINAttempts 次错误机会；会议号正确后只重输密码
+func (g *SIPGateway) collectMeeting(call *sip.Call) (*sharedmodels.Meeting, error) {
+	maxAttempts := g.cfg.MaxPINAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	var meeting *sharedmodels.Meeting
+	for failures := 0; failures < maxAttempts; {
+		g.playTones(call, sipPromptTone)
+		digits, err := g.readDigits(call)
+		if err != nil {
+			return nil, err
+		}
+
+		if meeting == nil {
+			id, err := strconv.ParseUint(digits, 10, 64)
+			if err == nil && id > 0 {
+				meeting, err = g.lookupMeeting(uint(id))
+				if err != nil || !sipMeetingJoinable(meeting) {
+					meeting = nil
+				}
+			}
+			if meeting == nil {
+				failures++
+				g.playTones(call, sipErrorTone)
+				continue
+			}
+			if meeting.Password == "" {
+				return meeting, nil
+			}
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(digits), []byte(meeting.Password)) == 1 {
+			return meeting, nil
+		}
+		failures++
+		g.playTones(call, sipErrorTone)
+	}
+	return nil, errSIPTooManyAttempts
+}
+
+// readDigits 读取一段以 # 结束的按键，* 清空重输
+func (g *SIPGateway) readDigits(call *sip.Call) (string, error) {
+	timeout := time.Duration(g.cfg.PINTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var digits []rune
+	for {
+		select {
+		case d := <-call.DTMF():
+			switch {
+			case d == '#':
+				return string(digits), nil
+			case d == '*':
+				digits = digits[:0]
+			case len(digits) < sipMaxDigits:
+				digits = append(digits, d)
+			}
+		case <-timer.C:
+			return "", errSIPPINTimeout
+		case <-call.Done():
+			return "", sip.ErrCallEnded
+		}
+	}
+}
+
+// playTones 按实时节奏向电话端播放提示音
+func (g *SIPGateway) playTones(call *sip.Call, tones []sipTone) {
+	sink, err := newSIPAudioSink(call)
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(mixerFrameDuration)
+	defer ticker.Stop()
+	var phase float64
+	frame := make([]int16, mixerFrameSamples)
+	for _, tone := range tones {
+		step := 2 * math.Pi * tone.freq / mixerSampleRate
+		for n := int(tone.duration / mixerFrameDuration); n > 0; n-- {
+			for i := range frame {
+				if tone.freq == 0 {
+					frame[i] = 0
+					continue
+				}
+				frame[i] = int16(sipToneAmplitude * math.Sin(phase))
+				phase += step
+			}
+			if err := sink.writeFrame(frame); err != nil {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-call.Done():
+				return
+			}
+		}
+	}
+}
+
+// bridge 把已接通的呼叫作为参与者加入会议房间
+func (g *SIPGateway) bridge(call *sip.Call, meetingID uint) (*SIPCall, error) {
+	codec := call.Codec()
+	capability, source, err := sipCodecBridge(codec)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := newSIPAudioSink(call)
+	if err != nil {
+		return nil, err
+	}
+
+	s := g.webrtc
+	roomID := s.meetingRoomID(meetingID)
+	now := time.Now()
+	peer := &Peer{
+		ID:           sipPeerPrefix + uuid.New().String(),
+		UserID:       "sip:" + call.RemoteUser(),
+		RoomID:       roomID,
+		MediaType:    "audio",
+		Status:       "connected",
+		CreatedAt:    now,
+		LastActivity: now,
+		// 参与者被移出房间（主持人踢人、超时清理等）时挂断电话；BYE 可能等待对端响应，不阻塞清理
+		onCleanup: func(string) { go call.Hangup() },
+	}
+
+	s.peersMux.Lock()
+	s.peers[peer.ID] = peer
+	s.peersMux.Unlock()
+	s.addPeerToRoom(roomID, peer)
+
+	localTrack, err := s.publishExternalTrack(roomID, peer.ID, sipAudioTrackID, capability)
+	if err != nil {
+		s.cleanupPeer(peer.ID, "sip_bridge_failed")
+		return nil, err
+	}
+	mixer, err := s.RoomAudioMixer(roomID)
+	if err != nil {
+		s.cleanupPeer(peer.ID, "sip_bridge_failed")
+		return nil, err
+	}
+	frames, unsubscribe, err := mixer.Subscribe(peer.ID)
+	if err != nil {
+		s.cleanupPeer(peer.ID, "sip_bridge_failed")
+		return nil, err
+	}
+
+	trackKey := fmt.Sprintf("%s:%s", peer.ID, sipAudioTrackID)
+	call.OnAudio(func(pkt *rtp.Packet) {
+		if err := localTrack.WriteRTP(pkt); err != nil {
+			logger.Debug(fmt.Sprintf("SIP RTP write warning (peer=%s): %v", peer.ID, err))
+		}
+		if localTrack.Suppressed() {
+			return
+		}
+		if source != nil {
+			mixer.PushPCM(trackKey, peer.ID, source.decode(pkt.Payload))
+		} else {
+			mixer.PushRTP(trackKey, peer.ID, pkt.SequenceNumber, pkt.Timestamp, pkt.Payload)
+		}
+	})
+
+	b := &sipBridge{
+		call: call,
+		info: SIPCall{
+			CallID:    call.ID(),
+			PeerID:    peer.ID,
+			MeetingID: meetingID,
+			RoomID:    roomID,
+			Number:    call.RemoteUser(),
+			Direction: call.Direction(),
+			Codec:     codec.Name,
+			StartedAt: now,
+		},
+	}
+	g.mu.Lock()
+	g.calls[call.ID()] = b
+	g.mu.Unlock()
+
+	g.playTones(call, sipJoinedTone)
+	go g.runBridge(b, peer, mixer, frames, unsubscribe, sink)
+	return &b.info, nil
+}
+
+// runBridge 把 N-1 房间混音送给电话端，直到呼叫结束
+func (g *SIPGateway) runBridge(b *sipBridge, peer *Peer, mixer *AudioMixer, frames <-chan []int16, unsubscribe func(), sink *sipAudioSink) {
+	defer func() {
+		unsubscribe()
+		b.call.OnAudio(nil)
+		mixer.RemoveTrack(fmt.Sprintf("%s:%s", peer.ID, sipAudioTrackID))
+
+		g.mu.Lock()
+		delete(g.calls, b.info.CallID)
+		g.mu.Unlock()
+
+		g.webrtc.cleanupPeer(peer.ID, "sip_hangup")
+		logger.Info(fmt.Sprintf("SIP call %s left meeting %d (reason=%s)", b.info.CallID, b.info.MeetingID, b.call.EndReason()))
+	}()
+
+	lastTouch := time.Now()
+	for {
+		select {
+		case <-b.call.Done():
+			return
+		case frame, ok := <-frames:
+			if !ok {
+				// 混音已释放（房间解散或参与者被移出），等待挂断完成
+				frames = nil
+				continue
+			}
+			if err := sink.writeFrame(frame); err != nil && !errors.Is(err, sip.ErrCallEnded) {
+				logger.Debug(fmt.Sprintf("SIP audio write failed (call=%s): %v", b.info.CallID, err))
+			}
+			if now := time.Now(); now.Sub(lastTouch) > sipActivityInterval {
+				lastTouch = now
+				peer.LastActivity = now
+			}
+		}
+	}
+}
+
+// lookupMeetingFromDB 查询会议（密码、状态、创建者）
+func (g *SIPGateway) lookupMeetingFromDB(meetingID uint) (*sharedmodels.Meeting, error) {
+	if g.webrtc == nil || g.webrtc.mediaService == nil || g.webrtc.mediaService.db == nil {
+		return nil, errors.New("database not available")
+	}
+	var meeting sharedmodels.Meeting
+	if err := g.webrtc.mediaService.db.First(&meeting, meetingID).Error; err != nil {
+		return nil, err
+	}
+	return &meeting, nil
+}
+
+func sipMeetingJoinable(meeting *sharedmodels.Meeting) bool {
+	return meeting != nil &&
+		meeting.Status != sharedmodels.MeetingStatusEnded &&
+		meeting.Status != sharedmodels.MeetingStatusCancelled
+}
+
+// sipCodecBridge 通话编解码器对应的房间发布轨道格式；G.711 需要解码后送入混音，Opus 直接交给混音器解码
+func sipCodecBridge(codec sip.Codec) (webrtc.RTPCodecCapability, *sipAudioSource, error) {
+	switch {
+	case strings.EqualFold(codec.Name, sip.CodecPCMU.Name):
+		return webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypePCMU, ClockRate: 8000},
+			&sipAudioSource{g711: sip.MulawDecode}, nil
+	case strings.EqualFold(codec.Name, sip.CodecPCMA.Name):
+		return webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypePCMA, ClockRate: 8000},
+			&sipAudioSource{g711: sip.AlawDecode}, nil
+	case strings.EqualFold(codec.Name, sip.CodecOpus.Name):
+		return webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2, SDPFmtpLine: "minptime=10;useinbandfec=1"},
+			nil, nil
+	}
+	return webrtc.RTPCodecCapability{}, nil, fmt.Errorf("unsupported sip codec %s", codec.Name)
+}
+
+// sipAudioSource 电话端 G.711 -> 48kHz 单声道 PCM
+type sipAudioSource struct {
+	g711   func(dst []int16, data []byte) []int16
+	narrow []int16
+	wide   []int16
+	last   int16
+}
+
+func (s *sipAudioSource) decode(payload []byte) []int16 {
+	s.narrow = s.g711(s.narrow[:0], payload)
+	s.wide = upsampleTo48k(s.wide[:0], s.narrow, &s.last)
+	return s.wide
+}
+
+// sipAudioSink 48kHz 单声道 PCM -> 按通话编解码器编码后发给电话端
+type sipAudioSink struct {
+	call    *sip.Call
+	g711    func(dst []byte, pcm []int16) []byte
+	opus    opusFrameEncoder
+	narrow  []int16
+	encoded []byte
+}
+
+func newSIPAudioSink(call *sip.Call) (*sipAudioSink, error) {
+	sink := &sipAudioSink{call: call}
+	switch codec := call.Codec(); {
+	case strings.EqualFold(codec.Name, sip.CodecPCMU.Name):
+		sink.g711 = sip.MulawEncode
+	case strings.EqualFold(codec.Name, sip.CodecPCMA.Name):
+		sink.g711 = sip.AlawEncode
+	case strings.EqualFold(codec.Name, sip.CodecOpus.Name):
+		encoder, err := newMixerEncoder()
+		if err != nil {
+			return nil, err
+		}
+		sink.opus = encoder
+		sink.encoded = make([]byte, mixerEncodeBufferSize)
+	default:
+		return nil, fmt.Errorf("unsupported sip codec %s", codec.Name)
+	}
+	return sink, nil
+}
+
+// writeFrame 发送一帧 20ms 混音
+func (s *sipAudioSink) writeFrame(pcm []int16) error {
+	if s.opus != nil {
+		n, err := s.opus.Encode(pcm, s.encoded)
+		if err != nil {
+			return err
+		}
+		return s.call.WriteAudio(s.encoded[:n], uint32(len(pcm)))
+	}
+
+	s.narrow = downsampleTo8k(s.narrow[:0], pcm)
+	s.encoded = s.g711(s.encoded[:0], s.narrow)
+	return s.call.WriteAudio(s.encoded, uint32(len(s.narrow)))
+}
+
+// downsampleTo8k 48kHz -> 8kHz：每 6 个样本取均值（简单低通，抑制混叠）
+func downsampleTo8k(dst, src []int16) []int16 {
+	for i := 0; i+sipResampleRatio <= len(src); i += sipResampleRatio {
+		var sum int32
+		for _, v := range src[i : i+sipResampleRatio] {
+			sum += int32(v)
+		}
+		dst = append(dst, int16(sum/sipResampleRatio))
+	}
+	return dst
+}
+
+// upsampleTo48k 8kHz -> 48kHz 线性插值；last 为上一包最后一个样本，保证包间连续
+func upsampleTo48k(dst, src []int16, last *int16) []int16 {
+	prev := int32(*last)
+	for _, v := range src {
+		cur := int32(v)
+		for k := int32(1); k <= sipResampleRatio; k++ {
+			dst = append(dst, int16(prev+(cur-prev)*k/sipResampleRatio))
+		}
+		prev = cur
+	}
+	*last = int16(prev)
+	return dst
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/media-service/sip"
+	"meeting-system/shared/config"
+	sharedmodels "meeting-system/shared/models"
+)
+
+// newTestSIPGateway 本地回环上的网关：会议 42（密码 9876），用户 7 是主持人；只提供 G.711
+func newTestSIPGateway(t *testing.T, cfg config.SIPConfig) (*SIPGateway, *WebRTCService) {
+	t.Helper()
+	useFakeMixerCodecs(t, nil, errors.New("opus unavailable"))
+
+	cfg.ListenAddr = "127.0.0.1:0"
+	svc := NewWebRTCService(&config.Config{}, nil, nil)
+	g := NewSIPGateway(cfg, svc)
+	g.lookupMeeting = func(meetingID uint) (*sharedmodels.Meeting, error) {
+		if meetingID != 42 {
+			return nil, errors.New("record not found")
+		}
+		return &sharedmodels.Meeting{ID: 42, CreatorID: 7, Password: "9876", Status: sharedmodels.MeetingStatusOngoing}, nil
+	}
+	g.isMeetingHost = func(meetingID, userID uint) (bool, error) {
+		return meetingID == 42 && userID == 7, nil
+	}
+	require.NoError(t, g.Start())
+	t.Cleanup(g.Stop)
+	return g, svc
+}
+
+func newTestPhone(t *testing.T) *sip.UserAgent {
+	t.Helper()
+	ua, err := sip.NewUserAgent(sip.Config{ListenAddr: "127.0.0.1:0", Codecs: []sip.Codec{sip.CodecPCMU}})
+	require.NoError(t, err)
+	t.Cleanup(ua.Close)
+	return ua
+}
+
+func sendDigits(t *testing.T, call *sip.Call, digits string) {
+	t.Helper()
+	for _, d := range digits {
+		require.NoError(t, call.SendDTMF(d))
+	}
+}
+
+// phoneAudio 以 20ms 节奏向网关发送恒定幅度的 μ-law 音频，直到 ctx 取消
+func phoneAudio(ctx context.Context, call *sip.Call, level int16) {
+	pcm := make([]int16, 160)
+	for i := range pcm {
+		pcm[i] = level
+	}
+	payload := sip.MulawEncode(nil, pcm)
+	ticker := time.NewTicker(mixerFrameDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = call.WriteAudio(payload, 160)
+		}
+	}
+}
+
+// isLevel 帧内所有样本都接近 level（G.711 量化误差内）
+func isLevel(pcm []int16, level int16) bool {
+	if len(pcm) == 0 {
+		return false
+	}
+	for _, v := range pcm {
+		if d := int(v) - int(level); d > 100 || d < -100 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSIPGateway_DialInWithPIN(t *testing.T) {
+	g, svc := newTestSIPGateway(t, config.SIPConfig{PINTimeout: 5, MaxPINAttempts: 3})
+	phone := newTestPhone(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	call, err := phone.Dial(ctx, "sip:1000@"+g.Addr().String(), "13800000000")
+	require.NoError(t, err)
+
+	earFrames := make(chan []int16, 500)
+	call.OnAudio(func(pkt *rtp.Packet) {
+		select {
+		case earFrames <- sip.MulawDecode(nil, pkt.Payload):
+		default:
+		}
+	})
+
+	// 错误的会议号消耗一次机会，之后输入正确的会议号与密码
+	sendDigits(t, call, "7#")
+	sendDigits(t, call, "42#")
+	assert.Empty(t, g.Calls(), "password is still required")
+	sendDigits(t, call, "9876#")
+
+	require.Eventually(t, func() bool { return len(g.Calls()) == 1 }, 5*time.Second, 20*time.Millisecond)
+	info := g.Calls()[0]
+	assert.Equal(t, uint(42), info.MeetingID)
+	assert.Equal(t, "42", info.RoomID)
+	assert.Equal(t, "13800000000", info.Number)
+	assert.Equal(t, sip.DirectionInbound, info.Direction)
+	assert.Equal(t, "PCMU", info.Codec)
+
+	peers, err := svc.GetRoomPeers("42")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, info.PeerID, peers[0].ID)
+	assert.Equal(t, "sip:13800000000", peers[0].UserID)
+
+	svc.roomsMux.RLock()
+	room := svc.rooms["42"]
+	svc.roomsMux.RUnlock()
+	room.TracksMux.RLock()
+	published := room.Tracks[info.PeerID+":"+sipAudioTrackID]
+	room.TracksMux.RUnlock()
+	require.NotNil(t, published, "caller audio is published to the room")
+	assert.Equal(t, "audio/PCMU", published.LocalTrack.Codec().MimeType)
+
+	mixer, err := svc.RoomAudioMixer("42")
+	require.NoError(t, err)
+
+	// 电话端声音进入房间混音
+	roomMix, unsubscribe, err := mixer.Subscribe(RoomMixListener)
+	require.NoError(t, err)
+	defer unsubscribe()
+	audioCtx, stopAudio := context.WithCancel(ctx)
+	go phoneAudio(audioCtx, call, 2000)
+	heard := false
+	for deadline := time.After(3 * time.Second); !heard; {
+		select {
+		case frame := <-roomMix:
+			heard = isLevel(frame, 2000)
+		case <-deadline:
+			t.Fatal("caller audio not mixed into the room")
+		}
+	}
+	stopAudio()
+
+	// 其它参与者的声音经 N-1 混音送到电话端
+	otherCtx, stopOther := context.WithCancel(ctx)
+	defer stopOther()
+	go func() {
+		frame := make([]int16, mixerFrameSamples)
+		for i := range frame {
+			frame[i] = -3000
+		}
+		ticker := time.NewTicker(mixerFrameDuration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-otherCtx.Done():
+				return
+			case <-ticker.C:
+				mixer.PushPCM("other:audio", "other", frame)
+			}
+		}
+	}()
+	heard = false
+	for deadline := time.After(3 * time.Second); !heard; {
+		select {
+		case pcm := <-earFrames:
+			heard = isLevel(pcm, -3000)
+		case <-deadline:
+			t.Fatal("room mix not delivered to the caller")
+		}
+	}
+
+	call.Hangup()
+	require.Eventually(t, func() bool { return len(g.Calls()) == 0 }, 3*time.Second, 20*time.Millisecond)
+	svc.peersMux.RLock()
+	_, stillThere := svc.peers[info.PeerID]
+	svc.peersMux.RUnlock()
+	assert.False(t, stillThere)
+}
+
+func TestSIPGateway_HangsUpAfterMaxPINAttempts(t *testing.T) {
+	g, _ := newTestSIPGateway(t, config.SIPConfig{PINTimeout: 5, MaxPINAttempts: 2})
+	phone := newTestPhone(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	call, err := phone.Dial(ctx, "sip:1000@"+g.Addr().String(), "13800000000")
+	require.NoError(t, err)
+
+	sendDigits(t, call, "42#")
+	sendDigits(t, call, "1111#")
+	sendDigits(t, call, "2222#")
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("gateway did not hang up")
+	}
+	assert.Equal(t, "remote_hangup", call.EndReason())
+	assert.Empty(t, g.Calls())
+}
+
+func TestSIPGateway_DialOut(t *testing.T) {
+	phone := newTestPhone(t)
+	answered := make(chan *sip.Call, 1)
+	phone.OnInvite(func(c *sip.Call) {
+		require.NoError(t, c.Answer())
+		answered <- c
+	})
+	g, svc := newTestSIPGateway(t, config.SIPConfig{
+		OutboundProxy: phone.Addr().String(),
+		CallerID:      "4000",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := g.DialOut(ctx, 42, 8, "13800000000")
+	assert.ErrorIs(t, err, ErrSIPNotMeetingHost)
+	_, err = g.DialOut(ctx, 42, 7, "138-0000")
+	assert.ErrorIs(t, err, ErrSIPInvalidNumber)
+
+	info, err := g.DialOut(ctx, 42, 7, "13800000000")
+	require.NoError(t, err)
+	assert.Equal(t, sip.DirectionOutbound, info.Direction)
+	assert.Equal(t, "13800000000", info.Number)
+
+	var remote *sip.Call
+	select {
+	case remote = <-answered:
+	case <-time.After(time.Second):
+		t.Fatal("phone did not ring")
+	}
+	assert.Equal(t, "4000", remote.RemoteUser())
+	assert.Equal(t, "13800000000", remote.LocalUser())
+
+	peers, err := svc.GetRoomPeers("42")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+
+	assert.ErrorIs(t, g.Hangup(info.CallID, 8), ErrSIPNotMeetingHost)
+	assert.ErrorIs(t, g.Hangup("missing", 7), ErrSIPCallNotFound)
+
+	// 电话参与者被移出房间时挂断
+	svc.cleanupPeer(info.PeerID, "removed_by_host")
+	select {
+	case <-remote.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("phone call not hung up")
+	}
+	assert.Equal(t, "remote_hangup", remote.EndReason())
+	require.Eventually(t, func() bool { return len(g.Calls()) == 0 }, 3*time.Second, 20*time.Millisecond)
+}
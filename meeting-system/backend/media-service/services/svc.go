@@ -0,0 +1,525 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+)
+
+const (
+	// av1DependencyDescriptorURI AV1 RTP 规范附录 A 的 Dependency Descriptor 头扩展
+	av1DependencyDescriptorURI = "https://aomediacodec.github.io/av1-rtp-spec/#dependency-descriptor-rtp-header-extension"
+
+	// svcMaxLayers 跟踪的空间/时间层数上限（更高的层按最高层处理）
+	svcMaxLayers = 4
+	// svcBitrateWindow 分层码率统计窗口
+	svcBitrateWindow = time.Second
+)
+
+var errDependencyDescriptorTruncated = errors.New("dependency descriptor truncated")
+
+// SVCLayer 空间层/时间层编号（从 0 开始）
+type SVCLayer struct {
+	Spatial  int `json:"spatial"`
+	Temporal int `json:"temporal"`
+}
+
+// SVCResolution 空间层分辨率
+type SVCResolution struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// SVCLayerStats 轨道当前可用的 SVC 分层
+type SVCLayerStats struct {
+	SpatialLayers  int             `json:"spatial_layers"`
+	TemporalLayers int             `json:"temporal_layers"`
+	Resolutions    []SVCResolution `json:"resolutions,omitempty"`
+	// LayerBitrates [spatial][temporal] 各层码率（bps，不含下层）
+	LayerBitrates [][]uint64 `json:"layer_bitrates"`
+}
+
+// svcPacketInfo 单个 RTP 包的分层信息
+type svcPacketInfo struct {
+	hasLayers    bool
+	layer        SVCLayer
+	startOfFrame bool
+	endOfFrame   bool
+	// keyframe 关键帧（画面内无帧间依赖）的起始包，可在此切换到更高空间层
+	keyframe    bool
+	resolutions []SVCResolution
+
+	// dependencyDescriptor 发布者包中的 AV1 Dependency Descriptor（转发时按订阅者协商的扩展 ID 重新写入）
+	dependencyDescriptor []byte
+}
+
+// isSVCCodec VP9 与 AV1 支持 SVC 分层转发
+func isSVCCodec(mimeType string) bool {
+	return strings.EqualFold(mimeType, webrtc.MimeTypeVP9) || strings.EqualFold(mimeType, webrtc.MimeTypeAV1)
+}
+
+// parseVP9SVC 解析 VP9 payload descriptor 中的分层信息与 scalability structure
+//
+//	|I|P|L|F|B|E|V|Z|  [PictureID]  [|TID|U| SID |D|] [TL0PICIDX] [P_DIFF...] [SS]
+func parseVP9SVC(payload []byte) (svcPacketInfo, bool) {
+	var info svcPacketInfo
+	if len(payload) == 0 {
+		return info, false
+	}
+
+	b0 := payload[0]
+	interPicture := b0&0x40 != 0
+	flexible := b0&0x10 != 0
+	info.startOfFrame = b0&0x08 != 0
+	info.endOfFrame = b0&0x04 != 0
+
+	idx := 1
+	if b0&0x80 != 0 { // I: PictureID
+		if len(payload) <= idx {
+			return info, false
+		}
+		if payload[idx]&0x80 != 0 {
+			idx += 2
+		} else {
+			idx++
+		}
+	}
+	if b0&0x20 != 0 { // L: layer indices
+		if len(payload) <= idx {
+			return info, false
+		}
+		info.hasLayers = true
+		info.layer.Temporal = int(payload[idx] >> 5)
+		info.layer.Spatial = int(payload[idx]>>1) & 0x07
+		idx++
+		if !flexible {
+			idx++ // TL0PICIDX
+		}
+	}
+	if flexible && interPicture {
+		for n := 0; n < 3; n++ {
+			if len(payload) <= idx {
+				return info, false
+			}
+			more := payload[idx]&0x01 != 0
+			idx++
+			if !more {
+				break
+			}
+		}
+	}
+	if b0&0x02 != 0 { // V: scalability structure
+		if len(payload) <= idx {
+			return info, false
+		}
+		spatialCount := int(payload[idx]>>5) + 1
+		hasResolutions := payload[idx]&0x10 != 0
+		idx++
+		if hasResolutions {
+			for s := 0; s < spatialCount; s++ {
+				if len(payload) < idx+4 {
+					return info, false
+				}
+				info.resolutions = append(info.resolutions, SVCResolution{
+					Width:  int(payload[idx])<<8 | int(payload[idx+1]),
+					Height: int(payload[idx+2])<<8 | int(payload[idx+3]),
+				})
+				idx += 4
+			}
+		}
+	}
+	if idx > len(payload) {
+		return info, false
+	}
+
+	info.keyframe = !interPicture && info.startOfFrame && info.layer.Spatial == 0
+	return info, true
+}
+
+// ddStructure Dependency Descriptor 的模板依赖结构（随关键帧下发）
+type ddStructure struct {
+	templateIDOffset int
+	templateLayers   []SVCLayer
+	resolutions      []SVCResolution
+}
+
+// dependencyDescriptorParser 有状态的 DD 解析器：模板结构只在关键帧携带，后续包通过模板 ID 查表得到分层
+type dependencyDescriptorParser struct {
+	structure *ddStructure
+}
+
+// parse 解析 DD；install=false 时不更新模板结构（用于回放缓存包）
+func (p *dependencyDescriptorParser) parse(data []byte, install bool) (svcPacketInfo, bool) {
+	var info svcPacketInfo
+	r := &bitReader{data: data}
+
+	startOfFrame, err1 := r.bits(1)
+	endOfFrame, err2 := r.bits(1)
+	templateID, err3 := r.bits(6)
+	_, err4 := r.bits(16) // frame_number
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return info, false
+	}
+	info.startOfFrame = startOfFrame == 1
+	info.endOfFrame = endOfFrame == 1
+
+	structure := p.structure
+	if len(data) > 3 {
+		structurePresent, err := r.bits(1)
+		if err != nil {
+			return info, false
+		}
+		// active_decode_targets_present / custom_dtis / custom_fdiffs / custom_chains 与分层无关
+		if _, err := r.bits(4); err != nil {
+			return info, false
+		}
+		if structurePresent == 1 {
+			parsed, err := parseDDStructure(r)
+			if err != nil {
+				return info, false
+			}
+			structure = parsed
+			info.keyframe = info.startOfFrame
+			info.resolutions = parsed.resolutions
+			if install {
+				p.structure = parsed
+			}
+		}
+	}
+	if structure == nil {
+		return info, false
+	}
+
+	idx := (int(templateID) + 64 - structure.templateIDOffset) % 64
+	if idx >= len(structure.templateLayers) {
+		return info, false
+	}
+	info.hasLayers = true
+	info.layer = structure.templateLayers[idx]
+	return info, true
+}
+
+func parseDDStructure(r *bitReader) (*ddStructure, error) {
+	offset, err := r.bits(6)
+	if err != nil {
+		return nil, err
+	}
+	dtCntMinusOne, err := r.bits(5)
+	if err != nil {
+		return nil, err
+	}
+	s := &ddStructure{templateIDOffset: int(offset)}
+	dtCnt := int(dtCntMinusOne) + 1
+
+	// template_layers
+	var layer SVCLayer
+	maxSpatial := 0
+	for {
+		s.templateLayers = append(s.templateLayers, layer)
+		next, err := r.bits(2)
+		if err != nil {
+			return nil, err
+		}
+		if next == 3 {
+			break
+		}
+		switch next {
+		case 1:
+			layer.Temporal++
+		case 2:
+			layer.Temporal = 0
+			layer.Spatial++
+			maxSpatial = layer.Spatial
+		}
+		if len(s.templateLayers) >= 64 {
+			return nil, errDependencyDescriptorTruncated
+		}
+	}
+	templateCnt := len(s.templateLayers)
+
+	// template_dtis
+	if _, err := r.bits(2 * dtCnt * templateCnt); err != nil {
+		return nil, err
+	}
+	// template_fdiffs
+	for i := 0; i < templateCnt; i++ {
+		for {
+			follows, err := r.bits(1)
+			if err != nil {
+				return nil, err
+			}
+			if follows == 0 {
+				break
+			}
+			if _, err := r.bits(4); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// template_chains
+	chainCnt, err := r.ns(dtCnt + 1)
+	if err != nil {
+		return nil, err
+	}
+	if chainCnt > 0 {
+		for i := 0; i < dtCnt; i++ {
+			if _, err := r.ns(int(chainCnt)); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := r.bits(4 * templateCnt * int(chainCnt)); err != nil {
+			return nil, err
+		}
+	}
+	// decode_target_layers 可由 template_dtis 推导，无需读取
+	resolutionsPresent, err := r.bits(1)
+	if err != nil {
+		return nil, err
+	}
+	if resolutionsPresent == 1 {
+		for i := 0; i <= maxSpatial; i++ {
+			w, err1 := r.bits(16)
+			h, err2 := r.bits(16)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, err
+			}
+			s.resolutions = append(s.resolutions, SVCResolution{Width: int(w) + 1, Height: int(h) + 1})
+		}
+	}
+	return s, nil
+}
+
+// bitReader 按位读取（MSB 优先）
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+// bits 读取 n 位；n 可以超过 32（只用于跳过），此时返回值无意义
+func (r *bitReader) bits(n int) (uint32, error) {
+	if r.pos+n > len(r.data)*8 {
+		return 0, errDependencyDescriptorTruncated
+	}
+	var v uint32
+	for i := 0; i < n; i++ {
+		bit := (r.data[r.pos/8] >> (7 - uint(r.pos%8))) & 0x01
+		v = v<<1 | uint32(bit)
+		r.pos++
+	}
+	return v, nil
+}
+
+// ns 非对称无符号编码 ns(n)
+func (r *bitReader) ns(n int) (uint32, error) {
+	w := 0
+	for x := n; x != 0; x >>= 1 {
+		w++
+	}
+	m := uint32(1<<w) - uint32(n)
+	v, err := r.bits(w - 1)
+	if err != nil {
+		return 0, err
+	}
+	if v < m {
+		return v, nil
+	}
+	extra, err := r.bits(1)
+	if err != nil {
+		return 0, err
+	}
+	return v<<1 - m + extra, nil
+}
+
+// svcTracker 记录一个 VP9/AV1 转发轨道的分层结构与各层码率（parse 只由转发协程调用）
+type svcTracker struct {
+	mimeType string
+	dd       dependencyDescriptorParser
+
+	mu          sync.RWMutex
+	maxLayer    SVCLayer
+	seen        bool
+	resolutions []SVCResolution
+	windowStart time.Time
+	windowMax   SVCLayer
+	windowBytes [svcMaxLayers][svcMaxLayers]uint64
+	bitrates    [svcMaxLayers][svcMaxLayers]uint64
+}
+
+func newSVCTracker(mimeType string) *svcTracker {
+	return &svcTracker{mimeType: mimeType}
+}
+
+// parse 解析包的分层信息；live=true 时同时更新分层结构与码率统计
+func (t *svcTracker) parse(pkt *rtp.Packet, info *svcPacketInfo, live bool) {
+	var parsed svcPacketInfo
+	var ok bool
+	if strings.EqualFold(t.mimeType, webrtc.MimeTypeVP9) {
+		parsed, ok = parseVP9SVC(pkt.Payload)
+	} else if len(info.dependencyDescriptor) > 0 {
+		parsed, ok = t.dd.parse(info.dependencyDescriptor, live)
+	}
+	if !ok || !parsed.hasLayers {
+		return
+	}
+	parsed.layer = clampSVCLayer(parsed.layer)
+	parsed.dependencyDescriptor = info.dependencyDescriptor
+	*info = parsed
+
+	if !live {
+		return
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seen = true
+	t.maxLayer = maxSVCLayer(t.maxLayer, parsed.layer)
+	t.windowMax = maxSVCLayer(t.windowMax, parsed.layer)
+	if len(parsed.resolutions) > 0 {
+		t.resolutions = parsed.resolutions
+	}
+
+	if t.windowStart.IsZero() {
+		t.windowStart = now
+	}
+	t.windowBytes[parsed.layer.Spatial][parsed.layer.Temporal] += uint64(len(pkt.Payload))
+	if elapsed := now.Sub(t.windowStart); elapsed >= svcBitrateWindow {
+		for s := range t.windowBytes {
+			for tid := range t.windowBytes[s] {
+				t.bitrates[s][tid] = uint64(float64(t.windowBytes[s][tid]*8) / elapsed.Seconds())
+				t.windowBytes[s][tid] = 0
+			}
+		}
+		// 发布者因带宽下降减少层数后，可用分层在一个统计窗口内收敛
+		t.maxLayer = t.windowMax
+		t.windowMax = SVCLayer{}
+		t.windowStart = now
+	}
+}
+
+// available 当前观测到的最高分层；尚未收到分层信息时返回 false
+func (t *svcTracker) available() (SVCLayer, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.maxLayer, t.seen
+}
+
+// stats 轨道可用分层与各层码率
+func (t *svcTracker) stats() *SVCLayerStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if !t.seen {
+		return nil
+	}
+
+	stats := &SVCLayerStats{
+		SpatialLayers:  t.maxLayer.Spatial + 1,
+		TemporalLayers: t.maxLayer.Temporal + 1,
+		Resolutions:    append([]SVCResolution(nil), t.resolutions...),
+		LayerBitrates:  make([][]uint64, t.maxLayer.Spatial+1),
+	}
+	for s := 0; s <= t.maxLayer.Spatial; s++ {
+		stats.LayerBitrates[s] = append([]uint64(nil), t.bitrates[s][:t.maxLayer.Temporal+1]...)
+	}
+	return stats
+}
+
+// spatialForHeight 不超过 maxHeight 的最高空间层；没有分辨率信息时不限制
+func (t *svcTracker) spatialForHeight(maxHeight int) int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if maxHeight <= 0 || len(t.resolutions) == 0 {
+		return svcMaxLayers - 1
+	}
+	spatial := 0
+	for s, res := range t.resolutions {
+		if res.Height <= maxHeight {
+			spatial = s
+		}
+	}
+	return spatial
+}
+
+// layerForBitrate 在 limit 以内选择累计码率不超过 bps 的最高分层（优先空间层）
+func (t *svcTracker) layerForBitrate(bps uint64, limit SVCLayer) SVCLayer {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	cumulative := func(layer SVCLayer) uint64 {
+		var sum uint64
+		for s := 0; s <= layer.Spatial; s++ {
+			for tid := 0; tid <= layer.Temporal; tid++ {
+				sum += t.bitrates[s][tid]
+			}
+		}
+		return sum
+	}
+
+	limit = clampSVCLayer(limit)
+	if t.seen {
+		// 只在源实际发送的分层中选择，避免未出现的高层按 0 码率被选中
+		limit = minSVCLayer(limit, t.maxLayer)
+	}
+	for s := limit.Spatial; s >= 0; s-- {
+		for tid := limit.Temporal; tid >= 0; tid-- {
+			candidate := SVCLayer{Spatial: s, Temporal: tid}
+			if cumulative(candidate) <= bps {
+				return candidate
+			}
+		}
+	}
+	return SVCLayer{}
+}
+
+func clampSVCLayer(l SVCLayer) SVCLayer {
+	if l.Spatial >= svcMaxLayers {
+		l.Spatial = svcMaxLayers - 1
+	}
+	if l.Temporal >= svcMaxLayers {
+		l.Temporal = svcMaxLayers - 1
+	}
+	if l.Spatial < 0 {
+		l.Spatial = 0
+	}
+	if l.Temporal < 0 {
+		l.Temporal = 0
+	}
+	return l
+}
+
+func maxSVCLayer(a, b SVCLayer) SVCLayer {
+	if b.Spatial > a.Spatial {
+		a.Spatial = b.Spatial
+	}
+	if b.Temporal > a.Temporal {
+		a.Temporal = b.Temporal
+	}
+	return a
+}
+
+func minSVCLayer(a, b SVCLayer) SVCLayer {
+	if b.Spatial < a.Spatial {
+		a.Spatial = b.Spatial
+	}
+	if b.Temporal < a.Temporal {
+		a.Temporal = b.Temporal
+	}
+	return a
+}
+
+// svcAllLayers 不限制分层
+var svcAllLayers = SVCLayer{Spatial: svcMaxLayers - 1, Temporal: svcMaxLayers - 1}
+
+// headerExtensionID 查找指定 URI 的头扩展 ID，未协商返回 0
+func headerExtensionID(exts []webrtc.RTPHeaderExtensionParameter, uri string) uint8 {
+	for _, ext := range exts {
+		if ext.URI == uri && ext.ID > 0 && ext.ID < 256 {
+			return uint8(ext.ID)
+		}
+	}
+	return 0
+}
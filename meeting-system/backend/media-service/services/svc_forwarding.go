@@ -0,0 +1,252 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3"
+	"meeting-system/shared/logger"
+)
+
+// publisherREMBInterval 非 SVC 轨道向发布者发送码率上限的最小间隔
+const publisherREMBInterval = time.Second
+
+// VideoLayerConstraints 订阅者对视频轨道的分层约束（通常来自渲染窗口大小）
+type VideoLayerConstraints struct {
+	// TrackID 为空表示该订阅者订阅的全部 SVC 轨道；可以是发布者的 track ID 或房间内的 track key
+	TrackID string `json:"track_id"`
+	// MaxHeight 渲染高度（像素），按轨道各空间层分辨率选择不超过该高度的最高层；0 表示不限制
+	MaxHeight int `json:"max_height"`
+	// MaxSpatialLayer/MaxTemporalLayer 直接指定分层上限，优先于 MaxHeight
+	MaxSpatialLayer  *int `json:"max_spatial_layer,omitempty"`
+	MaxTemporalLayer *int `json:"max_temporal_layer,omitempty"`
+}
+
+// TrackStats 房间内转发轨道的统计
+type TrackStats struct {
+	TrackKey        string         `json:"track_key"`
+	TrackID         string         `json:"track_id"`
+	PublisherPeerID string         `json:"publisher_peer_id"`
+	Kind            string         `json:"kind"`
+	Codec           string         `json:"codec"`
+	Subscribers     int            `json:"subscribers"`
+	ScreenShare     bool           `json:"screen_share,omitempty"`
+	ContentHint     string         `json:"content_hint,omitempty"`
+	Suppressed      bool           `json:"suppressed,omitempty"`
+	SVC             *SVCLayerStats `json:"svc,omitempty"`
+	// SubscriberLayers 订阅者 peer_id -> 当前转发的分层
+	SubscriberLayers map[string]SVCLayer `json:"subscriber_layers,omitempty"`
+}
+
+// SetSubscriberVideoConstraints 设置订阅者请求的分层上限，返回受影响的轨道数
+func (s *WebRTCService) SetSubscriberVideoConstraints(peerID string, constraints VideoLayerConstraints) (int, error) {
+	s.peersMux.RLock()
+	peer, exists := s.peers[peerID]
+	s.peersMux.RUnlock()
+	if !exists || peer == nil {
+		return 0, fmt.Errorf("peer not found: %s", peerID)
+	}
+
+	s.roomsMux.RLock()
+	room, exists := s.rooms[peer.RoomID]
+	s.roomsMux.RUnlock()
+	if !exists || room == nil {
+		return 0, fmt.Errorf("room not found: %s", peer.RoomID)
+	}
+
+	type subscription struct {
+		track         *ForwardedTrack
+		sender        *webrtc.RTPSender
+		publisherSSRC uint32
+	}
+	room.TracksMux.RLock()
+	subs := make([]subscription, 0, len(room.Tracks))
+	for key, t := range room.Tracks {
+		if t == nil || t.LocalTrack == nil || t.LocalTrack.svc == nil {
+			continue
+		}
+		if constraints.TrackID != "" && constraints.TrackID != key && constraints.TrackID != t.LocalTrack.ID() {
+			continue
+		}
+		if sender := t.SubscriberSenders[peerID]; sender != nil {
+			subs = append(subs, subscription{track: t, sender: sender, publisherSSRC: t.RemoteSSRC})
+		}
+	}
+	room.TracksMux.RUnlock()
+
+	temporal := -1
+	if constraints.MaxTemporalLayer != nil {
+		temporal = *constraints.MaxTemporalLayer
+	}
+
+	updated := 0
+	for _, sub := range subs {
+		ssrc, ok := senderSSRC(sub.sender)
+		if !ok {
+			continue
+		}
+
+		var needsKeyframe bool
+		if constraints.MaxSpatialLayer != nil {
+			limit := SVCLayer{Spatial: *constraints.MaxSpatialLayer, Temporal: svcMaxLayers - 1}
+			if temporal >= 0 {
+				limit.Temporal = temporal
+			}
+			needsKeyframe, ok = sub.track.LocalTrack.SetSubscriberMaxLayer(ssrc, limit)
+		} else {
+			needsKeyframe, ok = sub.track.LocalTrack.SetSubscriberMaxHeight(ssrc, constraints.MaxHeight, temporal)
+		}
+		if !ok {
+			continue
+		}
+		updated++
+		if needsKeyframe {
+			s.sendPLI(sub.track.SenderPeer, sub.publisherSSRC)
+		}
+	}
+
+	logger.Debug(fmt.Sprintf("Video layer constraints updated for peer %s: %d tracks", peerID, updated))
+	return updated, nil
+}
+
+// applySubscriberBitrate 按订阅者 REMB 分配视频带宽：REMB 是整个 PeerConnection 的估计，
+// 按订阅的视频轨道加权分配（屏幕共享优先于摄像头）。SVC 轨道按份额选择分层；
+// VP8/H.264 等无法在 SFU 降层，以各订阅者份额的最小值通过 REMB 限制发布者码率
+func (s *WebRTCService) applySubscriberBitrate(subscriberPeerID string, rtpSender *webrtc.RTPSender, senderPeerID string, publisherSSRC uint32, localTrack *ForwardingTrack, bitrate float32) {
+	if localTrack == nil || localTrack.Kind() != webrtc.RTPCodecTypeVideo || bitrate <= 0 {
+		return
+	}
+	ssrc, ok := senderSSRC(rtpSender)
+	if !ok {
+		return
+	}
+
+	share := s.subscriberBandwidthShare(subscriberPeerID, localTrack, uint64(bitrate))
+	if localTrack.svc == nil {
+		s.capPublisherBitrate(senderPeerID, publisherSSRC, localTrack, ssrc, share)
+		return
+	}
+	if needsKeyframe, ok := localTrack.SetSubscriberBitrate(ssrc, share); ok && needsKeyframe {
+		s.sendPLI(senderPeerID, publisherSSRC)
+	}
+}
+
+// subscriberBandwidthShare 订阅者带宽中分给该视频轨道的部分（按 videoBandwidthWeight 加权）
+func (s *WebRTCService) subscriberBandwidthShare(subscriberPeerID string, localTrack *ForwardingTrack, bps uint64) uint64 {
+	s.peersMux.RLock()
+	subPeer := s.peers[subscriberPeerID]
+	s.peersMux.RUnlock()
+	if subPeer == nil || subPeer.Connection == nil {
+		return bps
+	}
+
+	own := s.videoBandwidthWeight(localTrack)
+	var total float64
+	for _, sender := range subPeer.Connection.GetSenders() {
+		track := sender.Track()
+		if track == nil || track.Kind() != webrtc.RTPCodecTypeVideo {
+			continue
+		}
+		if ft, ok := track.(*ForwardingTrack); ok {
+			total += s.videoBandwidthWeight(ft)
+		} else {
+			total++
+		}
+	}
+	if total > own && own > 0 {
+		return uint64(float64(bps) * own / total)
+	}
+	return bps
+}
+
+// capPublisherBitrate 非 SVC 轨道：向发布者发送 REMB，把码率限制在各订阅者份额的最小值
+func (s *WebRTCService) capPublisherBitrate(senderPeerID string, publisherSSRC uint32, localTrack *ForwardingTrack, ssrc webrtc.SSRC, share uint64) {
+	limit, ok := localTrack.SetSubscriberBandwidth(ssrc, share)
+	if !ok || limit == 0 || publisherSSRC == 0 || !localTrack.AllowBitrateReport(time.Now(), publisherREMBInterval) {
+		return
+	}
+
+	s.peersMux.RLock()
+	peer := s.peers[senderPeerID]
+	s.peersMux.RUnlock()
+	if peer == nil || peer.Connection == nil {
+		return
+	}
+	if err := peer.Connection.WriteRTCP([]rtcp.Packet{
+		&rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: float32(limit), SSRCs: []uint32{publisherSSRC}},
+	}); err != nil {
+		logger.Debug(fmt.Sprintf("Failed to send REMB to peer %s: %v", senderPeerID, err))
+	}
+}
+
+// GetRoomTrackStats 获取房间内转发轨道的统计（含 SVC 可用分层与各订阅者当前分层）
+func (s *WebRTCService) GetRoomTrackStats(roomID string) ([]TrackStats, error) {
+	s.roomsMux.RLock()
+	room, exists := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if !exists || room == nil {
+		return nil, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	type snapshot struct {
+		key     string
+		track   *ForwardedTrack
+		senders map[string]*webrtc.RTPSender
+	}
+	room.TracksMux.RLock()
+	tracks := make([]snapshot, 0, len(room.Tracks))
+	for key, t := range room.Tracks {
+		if t == nil || t.LocalTrack == nil {
+			continue
+		}
+		senders := make(map[string]*webrtc.RTPSender, len(t.SubscriberSenders))
+		for peerID, sender := range t.SubscriberSenders {
+			senders[peerID] = sender
+		}
+		tracks = append(tracks, snapshot{key: key, track: t, senders: senders})
+	}
+	room.TracksMux.RUnlock()
+
+	stats := make([]TrackStats, 0, len(tracks))
+	for _, snap := range tracks {
+		local := snap.track.LocalTrack
+		ts := TrackStats{
+			TrackKey:        snap.key,
+			TrackID:         local.ID(),
+			PublisherPeerID: snap.track.SenderPeer,
+			Kind:            local.Kind().String(),
+			Codec:           local.Codec().MimeType,
+			Subscribers:     len(snap.senders),
+			ScreenShare:     local.IsScreenShare(),
+			ContentHint:     local.ContentHint(),
+			Suppressed:      local.Suppressed(),
+			SVC:             local.SVCStats(),
+		}
+
+		if layers := local.SubscriberLayers(); len(layers) > 0 {
+			ts.SubscriberLayers = make(map[string]SVCLayer, len(layers))
+			for peerID, sender := range snap.senders {
+				if ssrc, ok := senderSSRC(sender); ok {
+					if layer, ok := layers[ssrc]; ok {
+						ts.SubscriberLayers[peerID] = layer
+					}
+				}
+			}
+		}
+		stats = append(stats, ts)
+	}
+	return stats, nil
+}
+
+// senderSSRC 订阅者侧 RTPSender 的 SSRC（即 ForwardingTrack 绑定的 SSRC）
+func senderSSRC(sender *webrtc.RTPSender) (webrtc.SSRC, bool) {
+	if sender == nil {
+		return 0, false
+	}
+	encodings := sender.GetParameters().Encodings
+	if len(encodings) == 0 {
+		return 0, false
+	}
+	return encodings[0].SSRC, true
+}
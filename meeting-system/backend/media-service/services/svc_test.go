@@ -0,0 +1,325 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vp9SVCPacket 构造非 flexible 模式的 VP9 SVC 包；keyframe 时附带两层分辨率的 SS
+func vp9SVCPacket(seq uint16, ts uint32, sid, tid int, keyframe, start, end bool) *rtp.Packet {
+	b0 := byte(0x20) // L=1
+	if !keyframe {
+		b0 |= 0x40 // P
+	}
+	if start {
+		b0 |= 0x08
+	}
+	if end {
+		b0 |= 0x04
+	}
+	payload := []byte{b0, byte(tid<<5 | sid<<1), 0x00}
+	if keyframe && sid == 0 && start {
+		payload[0] |= 0x02 // V
+		payload = append(payload,
+			byte(1<<5|0x10),        // N_S=2, Y=1
+			0x01, 0x40, 0x00, 0xB4, // 320x180
+			0x02, 0x80, 0x01, 0x68, // 640x360
+		)
+	}
+	payload = append(payload, 0xAA)
+	return &rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: 98, SequenceNumber: seq, Timestamp: ts, SSRC: 4321, Marker: end && sid == 1},
+		Payload: payload,
+	}
+}
+
+func newFakeVP9Context(id string, ssrc webrtc.SSRC) *fakeTrackLocalContext {
+	return &fakeTrackLocalContext{
+		id:   id,
+		ssrc: ssrc,
+		codecs: []webrtc.RTPCodecParameters{{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP9, ClockRate: 90000, SDPFmtpLine: "profile-id=0"},
+			PayloadType:        98,
+		}},
+		writer: &recordingWriter{},
+	}
+}
+
+func TestParseVP9SVC(t *testing.T) {
+	info, ok := parseVP9SVC(vp9SVCPacket(1, 0, 0, 0, true, true, false).Payload)
+	require.True(t, ok)
+	assert.True(t, info.hasLayers)
+	assert.True(t, info.keyframe)
+	assert.Equal(t, SVCLayer{}, info.layer)
+	assert.Equal(t, []SVCResolution{{Width: 320, Height: 180}, {Width: 640, Height: 360}}, info.resolutions)
+
+	info, ok = parseVP9SVC(vp9SVCPacket(2, 0, 1, 2, false, false, true).Payload)
+	require.True(t, ok)
+	assert.False(t, info.keyframe)
+	assert.True(t, info.endOfFrame)
+	assert.Equal(t, SVCLayer{Spatial: 1, Temporal: 2}, info.layer)
+
+	// 非 SVC 的 VP9（L=0）不带分层信息
+	info, ok = parseVP9SVC([]byte{0x08, 0xAA})
+	require.True(t, ok)
+	assert.False(t, info.hasLayers)
+}
+
+// bitWriter 测试用按位写入
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *bitWriter) write(v uint32, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if v>>uint(i)&0x01 == 1 {
+			w.data[len(w.data)-1] |= 0x80 >> uint(w.n%8)
+		}
+		w.n++
+	}
+}
+
+// ddWithStructure 构造携带 L2T2 模板结构的 DD（模板：S0T0, S0T1, S1T0, S1T1）
+func ddWithStructure(templateID uint32) []byte {
+	w := &bitWriter{}
+	w.write(1, 1)          // start_of_frame
+	w.write(0, 1)          // end_of_frame
+	w.write(templateID, 6) // template_id
+	w.write(7, 16)         // frame_number
+	w.write(1, 1)          // template_dependency_structure_present
+	w.write(0, 4)          // 其它 flag
+	w.write(10, 6)         // template_id_offset
+	w.write(0, 5)          // dt_cnt_minus_one
+	for _, idc := range []uint32{1, 2, 1, 3} {
+		w.write(idc, 2)
+	}
+	w.write(0, 2*4) // template_dtis
+	w.write(0, 4)   // template_fdiffs: 每个模板都没有 fdiff
+	w.write(0, 1)   // chain_cnt = ns(2) -> 0
+	w.write(1, 1)   // resolutions_present
+	w.write(319, 16)
+	w.write(179, 16)
+	w.write(639, 16)
+	w.write(359, 16)
+	return w.data
+}
+
+// ddMandatory 只包含必选字段的 DD
+func ddMandatory(templateID uint32, end bool) []byte {
+	w := &bitWriter{}
+	w.write(0, 1)
+	if end {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+	w.write(templateID, 6)
+	w.write(8, 16)
+	return w.data
+}
+
+func TestDependencyDescriptorParser(t *testing.T) {
+	var parser dependencyDescriptorParser
+
+	_, ok := parser.parse(ddMandatory(13, true), true)
+	assert.False(t, ok, "没有模板结构时无法确定分层")
+
+	info, ok := parser.parse(ddWithStructure(10), true)
+	require.True(t, ok)
+	assert.True(t, info.keyframe)
+	assert.Equal(t, SVCLayer{}, info.layer)
+	assert.Equal(t, []SVCResolution{{Width: 320, Height: 180}, {Width: 640, Height: 360}}, info.resolutions)
+
+	info, ok = parser.parse(ddMandatory(13, true), true)
+	require.True(t, ok)
+	assert.Equal(t, SVCLayer{Spatial: 1, Temporal: 1}, info.layer)
+	assert.True(t, info.endOfFrame)
+	assert.False(t, info.keyframe)
+
+	info, ok = parser.parse(ddMandatory(11, false), true)
+	require.True(t, ok)
+	assert.Equal(t, SVCLayer{Spatial: 0, Temporal: 1}, info.layer)
+}
+
+// TestForwardingTrack_SVCDropsSpatialLayer 订阅者限制为空间层 0 时丢弃 S1，序列号连续且 S0 结束画面
+func TestForwardingTrack_SVCDropsSpatialLayer(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP9, ClockRate: 90000}, "video", "stream", 0)
+
+	full := newFakeVP9Context("full", 1)
+	_, err := track.Bind(full)
+	require.NoError(t, err)
+	low := newFakeVP9Context("low", 2)
+	_, err = track.Bind(low)
+	require.NoError(t, err)
+
+	// 关键帧画面：S0 + S1
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(10, 3000, 0, 0, true, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(11, 3000, 1, 0, true, true, true)))
+
+	stats := track.SVCStats()
+	require.NotNil(t, stats)
+	assert.Equal(t, 2, stats.SpatialLayers)
+	assert.Len(t, stats.Resolutions, 2)
+
+	needsKeyframe, ok := track.SetSubscriberMaxHeight(2, 200, -1)
+	require.True(t, ok)
+	assert.False(t, needsKeyframe, "降层无需关键帧")
+
+	// 帧间画面：S0 + S1
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(12, 6000, 0, 0, false, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(13, 6000, 1, 0, false, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(14, 9000, 0, 0, false, true, true)))
+
+	assert.Len(t, full.writer.headers, 5)
+
+	require.Len(t, low.writer.headers, 4)
+	assert.Equal(t, uint16(12), low.writer.headers[2].SequenceNumber)
+	assert.True(t, low.writer.headers[2].Marker, "S1 被丢弃后由 S0 结束画面")
+	assert.Equal(t, uint16(13), low.writer.headers[3].SequenceNumber, "丢包后序列号保持连续")
+	assert.Equal(t, uint32(9000), low.writer.headers[3].Timestamp)
+
+	layers := track.SubscriberLayers()
+	assert.Equal(t, SVCLayer{Spatial: 0, Temporal: 0}, layers[2])
+	assert.Equal(t, SVCLayer{Spatial: 1, Temporal: 0}, layers[1])
+
+	// 升层需要关键帧：帧间画面仍只有 S0，关键帧后恢复 S1
+	needsKeyframe, ok = track.SetSubscriberMaxHeight(2, 0, -1)
+	require.True(t, ok)
+	assert.True(t, needsKeyframe)
+
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(15, 12000, 0, 0, false, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(16, 12000, 1, 0, false, true, true)))
+	require.Len(t, low.writer.headers, 5)
+
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(17, 15000, 0, 0, true, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(18, 15000, 1, 0, true, true, true)))
+	require.Len(t, low.writer.headers, 7)
+	assert.Equal(t, uint16(15), low.writer.headers[5].SequenceNumber)
+	assert.Equal(t, uint16(16), low.writer.headers[6].SequenceNumber)
+}
+
+// TestForwardingTrack_SubscriberLayersConcurrent 转发协程切换分层时其它协程读取生效分层（-race）
+func TestForwardingTrack_SubscriberLayersConcurrent(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP9, ClockRate: 90000}, "video", "stream", 0)
+	sub := newFakeVP9Context("sub", 3)
+	_, err := track.Bind(sub)
+	require.NoError(t, err)
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(1, 0, 0, 0, true, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(2, 0, 1, 0, true, true, true)))
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				track.SubscriberLayers()
+			}
+		}
+	}()
+	// 每个关键帧画面在 S0/S1 之间切换
+	for i := uint16(0); i < 100; i++ {
+		track.SetSubscriberMaxHeight(3, 180*int(i%2), -1)
+		require.NoError(t, track.WriteRTP(vp9SVCPacket(3+2*i, uint32(i)*3000, 0, 0, true, true, true)))
+		require.NoError(t, track.WriteRTP(vp9SVCPacket(4+2*i, uint32(i)*3000, 1, 0, true, true, true)))
+	}
+	close(stop)
+	<-done
+	assert.Equal(t, SVCLayer{Spatial: 0, Temporal: 0}, track.SubscriberLayers()[3])
+}
+
+// TestForwardingTrack_SVCTemporalByBitrate 带宽不足时按累计码率降到低时间层
+func TestForwardingTrack_SVCTemporalByBitrate(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP9, ClockRate: 90000}, "video", "stream", 0)
+	sub := newFakeVP9Context("sub", 7)
+	_, err := track.Bind(sub)
+	require.NoError(t, err)
+
+	track.svc.seen = true
+	track.svc.maxLayer = SVCLayer{Spatial: 1, Temporal: 1}
+	track.svc.bitrates[0][0] = 300_000
+	track.svc.bitrates[0][1] = 200_000
+	track.svc.bitrates[1][0] = 600_000
+	track.svc.bitrates[1][1] = 400_000
+
+	assert.Equal(t, SVCLayer{Spatial: 1, Temporal: 0}, track.svc.layerForBitrate(1_200_000, svcAllLayers))
+	assert.Equal(t, SVCLayer{Spatial: 0, Temporal: 1}, track.svc.layerForBitrate(600_000, svcAllLayers))
+	assert.Equal(t, SVCLayer{}, track.svc.layerForBitrate(100_000, svcAllLayers))
+
+	_, ok := track.SetSubscriberBitrate(7, 400_000)
+	require.True(t, ok)
+
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(1, 0, 0, 0, true, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(2, 3000, 0, 1, false, true, true)))
+	require.NoError(t, track.WriteRTP(vp9SVCPacket(3, 6000, 0, 0, false, true, true)))
+
+	require.Len(t, sub.writer.headers, 2, "T1 被丢弃")
+	assert.Equal(t, uint16(2), sub.writer.headers[1].SequenceNumber)
+
+	_, ok = track.SetSubscriberBitrate(99, 400_000)
+	assert.False(t, ok, "未知订阅者")
+}
+
+// TestForwardingTrack_DependencyDescriptorRewrite DD 按订阅者协商的扩展 ID 重新写入，其它扩展被移除
+func TestForwardingTrack_DependencyDescriptorRewrite(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeAV1, ClockRate: 90000}, "video", "stream", 0)
+	track.SetSourceHeaderExtensions([]webrtc.RTPHeaderExtensionParameter{{URI: av1DependencyDescriptorURI, ID: 5}})
+
+	sub := &fakeTrackLocalContext{
+		id:   "sub",
+		ssrc: 9,
+		codecs: []webrtc.RTPCodecParameters{{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeAV1, ClockRate: 90000},
+			PayloadType:        45,
+		}},
+		exts:   []webrtc.RTPHeaderExtensionParameter{{URI: av1DependencyDescriptorURI, ID: 8}},
+		writer: &recordingWriter{},
+	}
+	_, err := track.Bind(sub)
+	require.NoError(t, err)
+
+	dd := ddWithStructure(10)
+	pkt := &rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 35, SequenceNumber: 1, Timestamp: 100}, Payload: []byte{0x18, 0xAA}}
+	require.NoError(t, pkt.Header.SetExtension(5, dd))
+	require.NoError(t, pkt.Header.SetExtension(3, []byte{0x01}))
+	require.NoError(t, track.WriteRTP(pkt))
+
+	require.Len(t, sub.writer.headers, 1)
+	h := sub.writer.headers[0]
+	assert.Equal(t, dd, h.GetExtension(8))
+	assert.Nil(t, h.GetExtension(5))
+	assert.Nil(t, h.GetExtension(3))
+
+	stats := track.SVCStats()
+	require.NotNil(t, stats)
+	assert.Equal(t, []SVCResolution{{Width: 320, Height: 180}, {Width: 640, Height: 360}}, stats.Resolutions)
+}
+
+// TestForwardingTrack_NonSVCKeepsHeaderExtensions 非 SVC 轨道原样转发头扩展（abs-send-time、TWCC 等）
+func TestForwardingTrack_NonSVCKeepsHeaderExtensions(t *testing.T) {
+	track := NewForwardingTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}, "video", "stream", 0)
+	sub := newFakeVP8Context("sub", 4)
+	_, err := track.Bind(sub)
+	require.NoError(t, err)
+
+	pkt := vp8Packet(1, 1000, true)
+	require.NoError(t, pkt.Header.SetExtension(3, []byte{0x01, 0x02, 0x03}))
+	require.NoError(t, pkt.Header.SetExtension(5, []byte{0x00, 0x07}))
+	require.NoError(t, track.WriteRTP(pkt))
+
+	require.Len(t, sub.writer.headers, 1)
+	h := sub.writer.headers[0]
+	assert.Equal(t, []byte{0x01, 0x02, 0x03}, h.GetExtension(3))
+	assert.Equal(t, []byte{0x00, 0x07}, h.GetExtension(5))
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vp8"
+)
+
+const (
+	aiVideoFormatJPEG  = "jpeg"
+	aiVideoFormatRGB24 = "rgb24"
+
+	defaultAIVideoJPEGQuality = 80
+	defaultAIVideoRoomMaxFPS  = 2
+)
+
+// ErrVideoDecoderUnavailable 需要 ffmpeg 解码（H.264 或 VP8 非关键帧）但 ffmpeg 不可用
+var ErrVideoDecoderUnavailable = errors.New("ffmpeg not available for video frame decoding")
+
+// videoFrameDecoder 把抽样帧解码为图像
+type videoFrameDecoder interface {
+	DecodeSample(ctx context.Context, sample *VideoSample) (image.Image, error)
+}
+
+// sampleDecoder 默认解码器：VP8 关键帧用纯 Go 解码，H.264 与 VP8 非关键帧（需整段 GOP）交给 ffmpeg
+type sampleDecoder struct {
+	ffmpeg *FFmpegService // nil 表示 ffmpeg 不可用
+}
+
+// canDecode 是否能解码该编码的关键帧 / 非关键帧
+func (d *sampleDecoder) canDecode(mimeType string, keyframe bool) bool {
+	if d.ffmpeg != nil {
+		return true
+	}
+	return keyframe && strings.EqualFold(mimeType, webrtc.MimeTypeVP8)
+}
+
+func (d *sampleDecoder) DecodeSample(ctx context.Context, sample *VideoSample) (image.Image, error) {
+	if len(sample.Frames) == 0 {
+		return nil, fmt.Errorf("empty video sample")
+	}
+
+	isVP8 := strings.EqualFold(sample.MimeType, webrtc.MimeTypeVP8)
+	if isVP8 && sample.Keyframe {
+		return decodeVP8Keyframe(sample.Frames[len(sample.Frames)-1])
+	}
+	if d.ffmpeg == nil {
+		return nil, ErrVideoDecoderUnavailable
+	}
+
+	var (
+		input  []byte
+		format string
+		err    error
+	)
+	if isVP8 {
+		format = "ivf"
+		input, err = buildIVF(sample.Frames)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		format = "h264"
+		input = bytes.Join(sample.Frames, nil)
+	}
+
+	data, err := d.ffmpeg.DecodeVideoFrame(ctx, format, input, len(sample.Frames)-1)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(data))
+}
+
+// decodeVP8Keyframe 纯 Go 解码 VP8 关键帧
+func decodeVP8Keyframe(frame []byte) (image.Image, error) {
+	dec := vp8.NewDecoder()
+	dec.Init(bytes.NewReader(frame), len(frame))
+	if _, err := dec.DecodeFrameHeader(); err != nil {
+		return nil, fmt.Errorf("failed to decode vp8 frame header: %w", err)
+	}
+	img, err := dec.DecodeFrame()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vp8 keyframe: %w", err)
+	}
+	return img, nil
+}
+
+// encodeAIFrame 按最大宽度等比缩放后编码为 AI 输入格式（jpeg 或 rgb24 原始像素）
+func encodeAIFrame(img image.Image, format string, maxWidth, quality int) (*VideoData, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("empty video frame")
+	}
+	if maxWidth > 0 && width > maxWidth {
+		height = height * maxWidth / width
+		if height < 1 {
+			height = 1
+		}
+		width = maxWidth
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	} else {
+		draw.ApproxBiLinear.Scale(rgba, rgba.Bounds(), img, bounds, draw.Src, nil)
+	}
+
+	frame := &VideoData{Width: width, Height: height}
+	switch strings.ToLower(format) {
+	case "", aiVideoFormatJPEG, "jpg":
+		if quality <= 0 || quality > 100 {
+			quality = defaultAIVideoJPEGQuality
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		frame.Format = aiVideoFormatJPEG
+		frame.Data = buf.Bytes()
+	case aiVideoFormatRGB24, "rgb":
+		data := make([]byte, 0, width*height*3)
+		for i := 0; i < len(rgba.Pix); i += 4 {
+			data = append(data, rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2])
+		}
+		frame.Format = aiVideoFormatRGB24
+		frame.Data = data
+	default:
+		return nil, fmt.Errorf("unsupported ai video format: %s", format)
+	}
+	return frame, nil
+}
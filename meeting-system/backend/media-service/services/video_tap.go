@@ -0,0 +1,270 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v3"
+)
+
+// videoTapMaxGOPBytes 为解码非关键帧缓存的 GOP 上限，超过后只抽关键帧直到下一个关键帧
+const videoTapMaxGOPBytes = 4 * 1024 * 1024
+
+// ErrVideoCodecNotSampled 视频编码不支持抽帧（只支持 VP8/H.264）
+var ErrVideoCodecNotSampled = fmt.Errorf("video codec not supported for AI frame sampling")
+
+// VideoSample 待解码的抽样帧：Frames 为从最近关键帧到抽样帧的完整编码帧序列
+type VideoSample struct {
+	MimeType  string
+	Frames    [][]byte
+	Keyframe  bool
+	Timestamp uint32
+}
+
+// isVideoSamplingSupported 是否支持从该编码抽帧
+func isVideoSamplingSupported(mimeType string) bool {
+	return strings.EqualFold(mimeType, webrtc.MimeTypeVP8) || strings.EqualFold(mimeType, webrtc.MimeTypeH264)
+}
+
+// videoFrameAssembler 把 RTP 包按时间戳重组为完整编码帧（VP8 帧数据 / H.264 Annex-B 访问单元）
+// 不做重排序：帧内出现序号缺口即丢弃该帧。
+type videoFrameAssembler struct {
+	mimeType  string
+	h264      codecs.H264Packet
+	buf       []byte
+	timestamp uint32
+	lastSeq   uint16
+	hasSeq    bool
+	inFrame   bool
+	started   bool // 已收到帧的首包
+	broken    bool // 当前帧不完整
+	lost      bool // 自上一个输出帧以来有丢包
+}
+
+// push 写入一个包；帧结束（marker）时返回完整帧，lost 表示该帧之前发生过丢包（参考链断裂）
+func (a *videoFrameAssembler) push(header *rtp.Header, payload []byte) (frame []byte, lost bool, ok bool) {
+	gap := false
+	if a.hasSeq && header.SequenceNumber != a.lastSeq+1 {
+		if int16(header.SequenceNumber-a.lastSeq) <= 0 {
+			// 重复或乱序迟到的包
+			return nil, false, false
+		}
+		gap = true
+	}
+	a.lastSeq = header.SequenceNumber
+	a.hasSeq = true
+
+	newFrame := !a.inFrame || header.Timestamp != a.timestamp
+	if newFrame {
+		if a.inFrame {
+			// 上一帧没有收到 marker
+			a.lost = true
+		}
+		a.startFrame(header.Timestamp)
+	}
+	if gap {
+		a.lost = true
+		if !newFrame {
+			a.broken = true
+		}
+	}
+
+	data, start, err := a.depacketize(payload)
+	if err != nil {
+		a.broken = true
+	} else if !a.started && !start {
+		// 帧的首包丢失
+		a.broken = true
+	}
+	a.started = true
+	a.buf = append(a.buf, data...)
+
+	if !header.Marker {
+		return nil, false, false
+	}
+	a.inFrame = false
+	lost = a.lost || a.broken
+	a.lost = false
+	if a.broken || len(a.buf) == 0 {
+		a.broken = false
+		return nil, true, false
+	}
+	return a.buf, lost, true
+}
+
+func (a *videoFrameAssembler) startFrame(timestamp uint32) {
+	a.timestamp = timestamp
+	a.inFrame = true
+	a.started = false
+	a.broken = false
+	a.buf = nil
+	// FU-A 分片不跨帧，丢弃上一帧残留的分片
+	a.h264 = codecs.H264Packet{}
+}
+
+func (a *videoFrameAssembler) depacketize(payload []byte) (data []byte, start bool, err error) {
+	if strings.EqualFold(a.mimeType, webrtc.MimeTypeVP8) {
+		var vp8 codecs.VP8Packet
+		data, err = vp8.Unmarshal(payload)
+		return data, vp8.S == 1 && vp8.PID == 0, err
+	}
+	// FU-A 只有首个分片（S 位）可以作为帧起始；中间分片在收齐前返回空数据
+	start = true
+	if len(payload) > 1 && payload[0]&0x1F == 28 {
+		start = payload[1]&0x80 != 0
+	}
+	data, err = a.h264.Unmarshal(payload)
+	return data, start, err
+}
+
+// isEncodedKeyframe 判断完整编码帧是否为关键帧
+func isEncodedKeyframe(mimeType string, frame []byte) bool {
+	if len(frame) == 0 {
+		return false
+	}
+	if strings.EqualFold(mimeType, webrtc.MimeTypeVP8) {
+		// VP8 frame tag: P 位为 0 表示关键帧
+		return frame[0]&0x01 == 0
+	}
+	for _, nalu := range splitAnnexB(frame) {
+		if len(nalu) > 0 && nalu[0]&0x1F == 5 {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAnnexB 按起始码拆分 H.264 Annex-B 数据
+func splitAnnexB(data []byte) [][]byte {
+	var nalus [][]byte
+	for len(data) > 0 {
+		i := bytes.Index(data, []byte{0x00, 0x00, 0x01})
+		if i < 0 {
+			nalus = append(nalus, data)
+			break
+		}
+		if nalu := bytes.TrimRight(data[:i], "\x00"); len(nalu) > 0 {
+			nalus = append(nalus, nalu)
+		}
+		data = data[i+3:]
+	}
+	return nalus
+}
+
+// VideoTap 从发布者的视频 RTP 中抽帧：关键帧总是抽取，另外每 frameInterval 帧抽一帧
+// （非关键帧需要附带从关键帧开始的 GOP 才能解码）。
+type VideoTap struct {
+	mu            sync.Mutex
+	mimeType      string
+	frameInterval int
+	assembler     videoFrameAssembler
+	gop           [][]byte
+	gopBytes      int
+	gopValid      bool
+	sinceSample   int
+}
+
+// NewVideoTap 创建视频抽帧器
+func NewVideoTap(mimeType string, frameInterval int) (*VideoTap, error) {
+	if !isVideoSamplingSupported(mimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrVideoCodecNotSampled, mimeType)
+	}
+	return &VideoTap{
+		mimeType:      mimeType,
+		frameInterval: frameInterval,
+		assembler:     videoFrameAssembler{mimeType: mimeType},
+	}, nil
+}
+
+// Push 写入一个 RTP 包，需要抽帧时返回待解码的样本
+func (t *VideoTap) Push(header *rtp.Header, payload []byte) *VideoSample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frame, lost, ok := t.assembler.push(header, payload)
+	if lost {
+		// GOP 断裂，等待下一个关键帧
+		t.gopValid = false
+		t.gop = nil
+		t.gopBytes = 0
+	}
+	if !ok {
+		return nil
+	}
+
+	keyframe := isEncodedKeyframe(t.mimeType, frame)
+	if keyframe {
+		t.gop = t.gop[:0]
+		t.gopBytes = 0
+		t.gopValid = true
+	}
+	if t.gopValid {
+		t.gop = append(t.gop, frame)
+		t.gopBytes += len(frame)
+		if t.gopBytes > videoTapMaxGOPBytes {
+			t.gopValid = false
+			t.gop = nil
+			t.gopBytes = 0
+		}
+	}
+	t.sinceSample++
+
+	switch {
+	case keyframe:
+		t.sinceSample = 0
+		return &VideoSample{MimeType: t.mimeType, Frames: [][]byte{frame}, Keyframe: true, Timestamp: header.Timestamp}
+	case t.frameInterval > 0 && t.sinceSample >= t.frameInterval && t.gopValid:
+		t.sinceSample = 0
+		return &VideoSample{MimeType: t.mimeType, Frames: append([][]byte(nil), t.gop...), Timestamp: header.Timestamp}
+	default:
+		return nil
+	}
+}
+
+// vp8KeyframeSize 从 VP8 关键帧头读取分辨率
+func vp8KeyframeSize(frame []byte) (width, height int, ok bool) {
+	if len(frame) < 10 || frame[0]&0x01 != 0 || frame[3] != 0x9D || frame[4] != 0x01 || frame[5] != 0x2A {
+		return 0, 0, false
+	}
+	width = int(binary.LittleEndian.Uint16(frame[6:8]) & 0x3FFF)
+	height = int(binary.LittleEndian.Uint16(frame[8:10]) & 0x3FFF)
+	return width, height, true
+}
+
+// buildIVF 把 VP8 帧序列封装为 IVF，供 ffmpeg 解码
+func buildIVF(frames [][]byte) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames")
+	}
+	width, height, ok := vp8KeyframeSize(frames[0])
+	if !ok {
+		return nil, fmt.Errorf("ivf stream must start with a vp8 keyframe")
+	}
+
+	size := 32
+	for _, f := range frames {
+		size += 12 + len(f)
+	}
+	out := make([]byte, 0, size)
+	out = append(out, "DKIF"...)
+	out = binary.LittleEndian.AppendUint16(out, 0)  // version
+	out = binary.LittleEndian.AppendUint16(out, 32) // header size
+	out = append(out, "VP80"...)
+	out = binary.LittleEndian.AppendUint16(out, uint16(width))
+	out = binary.LittleEndian.AppendUint16(out, uint16(height))
+	out = binary.LittleEndian.AppendUint32(out, 30) // time base
+	out = binary.LittleEndian.AppendUint32(out, 1)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(frames)))
+	out = binary.LittleEndian.AppendUint32(out, 0)
+	for i, f := range frames {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(f)))
+		out = binary.LittleEndian.AppendUint64(out, uint64(i))
+		out = append(out, f...)
+	}
+	return out, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vp8Frame 构造 VP8 帧数据；关键帧带 320x180 的起始码与尺寸
+func vp8Frame(keyframe bool, fill byte, size int) []byte {
+	frame := make([]byte, size)
+	for i := range frame {
+		frame[i] = fill
+	}
+	if keyframe {
+		copy(frame, []byte{0x00, 0x00, 0x00, 0x9D, 0x01, 0x2A, 0x40, 0x01, 0xB4, 0x00})
+	} else {
+		frame[0] = 0x01
+	}
+	return frame
+}
+
+// packetizeVP8 把一帧拆成多个 RTP 包（最简 payload descriptor）
+func packetizeVP8(seq *uint16, ts uint32, frame []byte, mtu int) []*rtp.Packet {
+	var pkts []*rtp.Packet
+	for off := 0; off < len(frame); off += mtu {
+		end := off + mtu
+		if end > len(frame) {
+			end = len(frame)
+		}
+		descriptor := byte(0x00)
+		if off == 0 {
+			descriptor = 0x10 // S=1, PID=0
+		}
+		pkts = append(pkts, &rtp.Packet{
+			Header:  rtp.Header{Version: 2, SequenceNumber: *seq, Timestamp: ts, Marker: end == len(frame)},
+			Payload: append([]byte{descriptor}, frame[off:end]...),
+		})
+		*seq++
+	}
+	return pkts
+}
+
+func pushAll(tap *VideoTap, pkts []*rtp.Packet) []*VideoSample {
+	var samples []*VideoSample
+	for _, pkt := range pkts {
+		if s := tap.Push(&pkt.Header, pkt.Payload); s != nil {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+func TestVideoTap_VP8KeyframeAndInterval(t *testing.T) {
+	tap, err := NewVideoTap(webrtc.MimeTypeVP8, 3)
+	require.NoError(t, err)
+
+	var seq uint16 = 10
+	key := vp8Frame(true, 0x11, 2500)
+	samples := pushAll(tap, packetizeVP8(&seq, 0, key, 1000))
+	require.Len(t, samples, 1)
+	assert.True(t, samples[0].Keyframe)
+	assert.Equal(t, [][]byte{key}, samples[0].Frames)
+
+	// 第 3 个非关键帧触发抽样，附带从关键帧开始的 GOP
+	for i := 1; i <= 3; i++ {
+		samples = pushAll(tap, packetizeVP8(&seq, uint32(i*3000), vp8Frame(false, byte(i), 1200), 1000))
+	}
+	require.Len(t, samples, 1)
+	assert.False(t, samples[0].Keyframe)
+	assert.Len(t, samples[0].Frames, 4)
+	assert.Equal(t, key, samples[0].Frames[0])
+
+	// 帧内丢包后 GOP 失效，直到下一个关键帧都不再抽非关键帧
+	lossy := packetizeVP8(&seq, 12000, vp8Frame(false, 4, 2500), 1000)
+	assert.Empty(t, pushAll(tap, append(lossy[:1:1], lossy[2:]...)))
+	for i := 5; i <= 8; i++ {
+		assert.Empty(t, pushAll(tap, packetizeVP8(&seq, uint32(i*3000), vp8Frame(false, byte(i), 500), 1000)))
+	}
+
+	samples = pushAll(tap, packetizeVP8(&seq, 27000, vp8Frame(true, 0x22, 800), 1000))
+	require.Len(t, samples, 1)
+	assert.True(t, samples[0].Keyframe)
+}
+
+func TestVideoTap_H264AccessUnit(t *testing.T) {
+	tap, err := NewVideoTap(webrtc.MimeTypeH264, 0)
+	require.NoError(t, err)
+
+	sps := []byte{0x67, 0x42, 0xC0, 0x1F}
+	pps := []byte{0x68, 0xCE, 0x3C, 0x80}
+	stapA := []byte{0x78}
+	for _, nalu := range [][]byte{sps, pps} {
+		stapA = binary.BigEndian.AppendUint16(stapA, uint16(len(nalu)))
+		stapA = append(stapA, nalu...)
+	}
+	// IDR 分为两个 FU-A 分片
+	fuStart := []byte{0x7C, 0x85, 0xAA, 0xBB}
+	fuEnd := []byte{0x7C, 0x45, 0xCC}
+
+	pkts := []*rtp.Packet{
+		{Header: rtp.Header{SequenceNumber: 1, Timestamp: 90}, Payload: stapA},
+		{Header: rtp.Header{SequenceNumber: 2, Timestamp: 90}, Payload: fuStart},
+		{Header: rtp.Header{SequenceNumber: 3, Timestamp: 90, Marker: true}, Payload: fuEnd},
+	}
+	samples := pushAll(tap, pkts)
+	require.Len(t, samples, 1)
+	assert.True(t, samples[0].Keyframe)
+
+	nalus := splitAnnexB(samples[0].Frames[0])
+	require.Len(t, nalus, 3)
+	assert.Equal(t, sps, nalus[0])
+	assert.Equal(t, pps, nalus[1])
+	assert.Equal(t, []byte{0x65, 0xAA, 0xBB, 0xCC}, nalus[2])
+
+	// 首个 FU-A 分片丢失的帧被丢弃
+	pkts = []*rtp.Packet{
+		{Header: rtp.Header{SequenceNumber: 5, Timestamp: 180, Marker: true}, Payload: fuEnd},
+	}
+	assert.Empty(t, pushAll(tap, pkts))
+
+	_, err = NewVideoTap(webrtc.MimeTypeVP9, 0)
+	assert.ErrorIs(t, err, ErrVideoCodecNotSampled)
+}
+
+func TestBuildIVF(t *testing.T) {
+	frames := [][]byte{vp8Frame(true, 0, 16), vp8Frame(false, 0, 8)}
+	ivf, err := buildIVF(frames)
+	require.NoError(t, err)
+	assert.Equal(t, "DKIF", string(ivf[:4]))
+	assert.Equal(t, "VP80", string(ivf[8:12]))
+	assert.Equal(t, uint16(320), binary.LittleEndian.Uint16(ivf[12:14]))
+	assert.Equal(t, uint16(180), binary.LittleEndian.Uint16(ivf[14:16]))
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(ivf[24:28]))
+	assert.Len(t, ivf, 32+12+16+12+8)
+
+	_, err = buildIVF(frames[1:])
+	assert.Error(t, err, "必须以关键帧开始")
+}
+
+// readVP8Keyframe 从 lossy WebP 测试文件中取出 VP8 关键帧
+func readVP8Keyframe(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/vp8-keyframe.webp")
+	require.NoError(t, err)
+	for off := 12; off+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		if string(data[off:off+4]) == "VP8 " {
+			return data[off+8 : off+8+size]
+		}
+		off += 8 + size + size%2
+	}
+	t.Fatal("VP8 chunk not found")
+	return nil
+}
+
+func TestSampleDecoder_VP8KeyframeWithoutFFmpeg(t *testing.T) {
+	decoder := &sampleDecoder{}
+	assert.True(t, decoder.canDecode(webrtc.MimeTypeVP8, true))
+	assert.False(t, decoder.canDecode(webrtc.MimeTypeVP8, false))
+	assert.False(t, decoder.canDecode(webrtc.MimeTypeH264, true))
+
+	img, err := decoder.DecodeSample(context.Background(), &VideoSample{
+		MimeType: webrtc.MimeTypeVP8,
+		Frames:   [][]byte{readVP8Keyframe(t)},
+		Keyframe: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 150, img.Bounds().Dx())
+	assert.Equal(t, 100, img.Bounds().Dy())
+
+	_, err = decoder.DecodeSample(context.Background(), &VideoSample{MimeType: webrtc.MimeTypeH264, Frames: [][]byte{{0x65}}, Keyframe: true})
+	assert.ErrorIs(t, err, ErrVideoDecoderUnavailable)
+}
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	return img
+}
+
+func TestEncodeAIFrame(t *testing.T) {
+	frame, err := encodeAIFrame(testImage(1280, 720), "jpeg", 640, 0)
+	require.NoError(t, err)
+	assert.Equal(t, aiVideoFormatJPEG, frame.Format)
+	assert.Equal(t, 640, frame.Width)
+	assert.Equal(t, 360, frame.Height)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(frame.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 640, cfg.Width)
+
+	frame, err = encodeAIFrame(testImage(4, 2), aiVideoFormatRGB24, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4*2*3, len(frame.Data))
+	assert.Equal(t, []byte{200, 100, 50}, frame.Data[:3])
+
+	_, err = encodeAIFrame(testImage(4, 2), "bmp", 0, 0)
+	assert.Error(t, err)
+}
+
+func TestRoomFrameLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRoomFrameLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.Allow("room-1"))
+	assert.True(t, limiter.Allow("room-1"))
+	assert.False(t, limiter.Allow("room-1"), "突发配额用尽")
+	assert.True(t, limiter.Allow("room-2"), "房间之间互不影响")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.Allow("room-1"))
+	assert.False(t, limiter.Allow("room-1"))
+}
+
+// fakeVideoDecoder CPU 测试替身：按抽样帧数生成纯色图像
+type fakeVideoDecoder struct{}
+
+func (fakeVideoDecoder) DecodeSample(_ context.Context, sample *VideoSample) (image.Image, error) {
+	return testImage(320, 180), nil
+}
+
+// TestMediaProcessor_VideoSamplingRateLimited 同一房间超过帧率配额的抽样帧被丢弃，解码后的帧留给 HTTP 批处理
+func TestMediaProcessor_VideoSamplingRateLimited(t *testing.T) {
+	p := &MediaProcessor{
+		activeStreams: make(map[string]*StreamProcessor),
+		videoDecoder:  fakeVideoDecoder{},
+		videoQueue:    make(chan *videoFrameJob, 16),
+		frameLimiter:  newRoomFrameLimiter(1),
+	}
+	p.videoSampling.Format = aiVideoFormatJPEG
+	require.NoError(t, p.RegisterStream("peer_video", "user-1", "room-1", nil, nil, []string{"synthesis_detection"}))
+	t.Cleanup(func() { _ = p.UnregisterStream("peer_video") })
+
+	require.NoError(t, p.EnableVideoSampling("peer_video", webrtc.MimeTypeVP8))
+	assert.Error(t, p.EnableVideoSampling("missing", webrtc.MimeTypeVP8))
+
+	var seq uint16
+	for i := 0; i < 3; i++ {
+		for _, pkt := range packetizeVP8(&seq, uint32(i*3000), vp8Frame(true, 0, 300), 1000) {
+			p.IngestRTPPayload("peer_video", webrtc.RTPCodecTypeVideo, &pkt.Header, pkt.Payload)
+		}
+	}
+	require.Len(t, p.videoQueue, 1)
+
+	job := <-p.videoQueue
+	frame, err := p.decodeVideoSample(job.sample)
+	require.NoError(t, err)
+	p.deliverVideoFrame(job.stream, frame)
+
+	pending := job.stream.takePendingVideo()
+	require.NotNil(t, pending)
+	assert.Equal(t, aiVideoFormatJPEG, pending.Format)
+	assert.Equal(t, 320, pending.Width)
+	assert.Nil(t, job.stream.takePendingVideo())
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"meeting-system/shared/config"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// newICERestartTestService 创建宽限期为 1 秒的 WebRTC 服务
+func newICERestartTestService(t *testing.T) *WebRTCService {
+	cfg := &config.Config{}
+	cfg.WebRTC.ICERestartGracePeriod = 1
+	svc := NewWebRTCService(cfg, nil, nil)
+	require.NoError(t, svc.Initialize())
+	t.Cleanup(svc.Stop)
+	return svc
+}
+
+// registerTestPeer 注册一个未建立连接的 Peer（仅用于状态机测试）
+func registerTestPeer(t *testing.T, svc *WebRTCService, roomID, userID string) *Peer {
+	pc, err := svc.createPeerConnection()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+
+	peer := &Peer{
+		ID:           "peer-" + userID,
+		UserID:       userID,
+		RoomID:       roomID,
+		Connection:   pc,
+		Status:       "connected",
+		CreatedAt:    time.Now(),
+		LastActivity: time.Now(),
+	}
+	svc.peersMux.Lock()
+	svc.peers[peer.ID] = peer
+	svc.peersMux.Unlock()
+	svc.addPeerToRoom(roomID, peer)
+	return peer
+}
+
+func (s *WebRTCService) hasPeer(peerID string) bool {
+	s.peersMux.RLock()
+	defer s.peersMux.RUnlock()
+	_, ok := s.peers[peerID]
+	return ok
+}
+
+// TestICERestart_FailedPeerKeptDuringGracePeriod 连接失败后在宽限期内保留 Peer，超时后清理
+func TestICERestart_FailedPeerKeptDuringGracePeriod(t *testing.T) {
+	svc := newICERestartTestService(t)
+	peer := registerTestPeer(t, svc, "room-grace", "user-1")
+
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateDisconnected)
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateFailed)
+
+	assert.True(t, svc.hasPeer(peer.ID), "宽限期内不应清理 Peer")
+	assert.Equal(t, "reconnecting", peer.Status)
+
+	assert.Eventually(t, func() bool { return !svc.hasPeer(peer.ID) }, 3*time.Second, 50*time.Millisecond,
+		"宽限期到期后应清理 Peer")
+}
+
+// TestICERestart_RecoveredPeerNotCleanedUp 宽限期内恢复 connected 后不应被清理
+func TestICERestart_RecoveredPeerNotCleanedUp(t *testing.T) {
+	svc := newICERestartTestService(t)
+	peer := registerTestPeer(t, svc, "room-recover", "user-1")
+	peer.subscribedExistingTracks = true
+
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateFailed)
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateConnected)
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.True(t, svc.hasPeer(peer.ID), "恢复后的 Peer 不应被宽限期定时器清理")
+	assert.Equal(t, "connected", peer.Status)
+}
+
+// TestICERestart_NotifiesClientThroughSignaling 连接中断时经信令事件通道发布类型化的 ICE restart 通知（disconnected -> failed 只通知一次）
+func TestICERestart_NotifiesClientThroughSignaling(t *testing.T) {
+	svc := newICERestartTestService(t)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	peer := registerTestPeer(t, svc, "room_42_1_000001", "7")
+
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateDisconnected)
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateFailed)
+
+	require.Eventually(t, func() bool {
+		_, messages := publisher.published()
+		return len(messages) > 0
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	channels, messages := publisher.published()
+	require.Len(t, messages, 1)
+	assert.Equal(t, queue.ChannelSignalingEvents, channels[0])
+	msg := messages[0]
+	assert.Equal(t, queue.EventICERestart, msg.Type)
+	assert.Equal(t, uint(42), msg.Payload["meeting_id"])
+	assert.Equal(t, uint(7), msg.Payload["user_id"])
+	assert.Equal(t, models.ICERestartMessage{
+		PeerID:        peer.ID,
+		RoomID:        "room_42_1_000001",
+		Reason:        webrtc.PeerConnectionStateDisconnected.String(),
+		GracePeriodMs: 1000,
+	}, msg.Payload["message"])
+}
+
+// blockingPublisher 发布一直阻塞到 release 关闭，模拟事件总线不可用
+type blockingPublisher struct {
+	release chan struct{}
+}
+
+func (b *blockingPublisher) Publish(ctx context.Context, _ string, _ *queue.PubSubMessage) error {
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TestICERestart_NotificationDoesNotBlockStateCallback 事件总线阻塞时连接状态回调也应立即返回
+func TestICERestart_NotificationDoesNotBlockStateCallback(t *testing.T) {
+	svc := newICERestartTestService(t)
+	publisher := &blockingPublisher{release: make(chan struct{})}
+	t.Cleanup(func() { close(publisher.release) })
+	svc.SetEventPublisher(publisher)
+	peer := registerTestPeer(t, svc, "room_42_1_000001", "7")
+
+	returned := make(chan struct{})
+	go func() {
+		svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateFailed)
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("状态回调被 ICE restart 通知的发布阻塞")
+	}
+}
+
+// TestICERestart_ClosedPeerCleanedImmediately closed 状态不进入恢复流程
+func TestICERestart_ClosedPeerCleanedImmediately(t *testing.T) {
+	svc := newICERestartTestService(t)
+	peer := registerTestPeer(t, svc, "room-closed", "user-1")
+
+	svc.handleConnectionStateChange(peer.ID, webrtc.PeerConnectionStateClosed)
+
+	assert.Eventually(t, func() bool { return !svc.hasPeer(peer.ID) }, time.Second, 20*time.Millisecond)
+}
+
+// TestICERestart_RestartOfferOnExistingPeer 客户端在同一 Peer 上完成 ICE restart，Peer ID 与房间关系不变
+func TestICERestart_RestartOfferOnExistingPeer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICE restart loopback test in short mode")
+	}
+
+	svc := newICERestartTestService(t)
+
+	client, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	require.NoError(t, err)
+	defer client.Close()
+
+	connected := make(chan struct{}, 4)
+	client.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		if state == webrtc.PeerConnectionStateConnected {
+			connected <- struct{}{}
+		}
+	})
+	_, err = client.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio)
+	require.NoError(t, err)
+
+	negotiate := func(options *webrtc.OfferOptions, submit func(*webrtc.SessionDescription) (*webrtc.SessionDescription, string)) string {
+		offer, err := client.CreateOffer(options)
+		require.NoError(t, err)
+		gathered := webrtc.GatheringCompletePromise(client)
+		require.NoError(t, client.SetLocalDescription(offer))
+		<-gathered
+
+		answer, peerID := submit(client.LocalDescription())
+		require.NoError(t, client.SetRemoteDescription(*answer))
+
+		// 拉取服务端 trickle ICE 候选
+		go func() {
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				candidates, complete, err := svc.DrainLocalICECandidates(peerID)
+				if err != nil {
+					return
+				}
+				for _, c := range candidates {
+					_ = client.AddICECandidate(c)
+				}
+				if complete {
+					return
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+		}()
+		return peerID
+	}
+
+	peerID := negotiate(nil, func(offer *webrtc.SessionDescription) (*webrtc.SessionDescription, string) {
+		answer, peerID, err := svc.CreateAnswer("room-restart", "user-1", offer)
+		require.NoError(t, err)
+		return answer, peerID
+	})
+
+	select {
+	case <-connected:
+	case <-time.After(10 * time.Second):
+		t.Fatal("initial connection timed out")
+	}
+
+	restartedPeerID := negotiate(&webrtc.OfferOptions{ICERestart: true}, func(offer *webrtc.SessionDescription) (*webrtc.SessionDescription, string) {
+		answer, err := svc.RestartICE(peerID, offer)
+		require.NoError(t, err)
+		return answer, peerID
+	})
+	assert.Equal(t, peerID, restartedPeerID)
+
+	assert.Eventually(t, func() bool {
+		return client.ICEConnectionState() == webrtc.ICEConnectionStateConnected ||
+			client.ICEConnectionState() == webrtc.ICEConnectionStateCompleted
+	}, 10*time.Second, 50*time.Millisecond, "ICE restart 后应重新连通")
+
+	peers, err := svc.GetRoomPeers("room-restart")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, peerID, peers[0].ID, "ICE restart 不应创建新的 Peer")
+}
+
+// TestICERestart_RejectsNonOffer restart 接口只接受 Offer
+func TestICERestart_RejectsNonOffer(t *testing.T) {
+	svc := newICERestartTestService(t)
+	peer := registerTestPeer(t, svc, "room-invalid", "user-1")
+
+	_, err := svc.RestartICE(peer.ID, &webrtc.SessionDescription{Type: webrtc.SDPTypeAnswer, SDP: "v=0"})
+	assert.Error(t, err)
+
+	_, err = svc.RestartICE("missing-peer", &webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: "v=0"})
+	assert.Error(t, err)
+}
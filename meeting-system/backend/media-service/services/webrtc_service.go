@@ -1,18 +1,35 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pion/interceptor/pkg/stats"
 	"github.com/pion/rtcp"
 	"github.com/pion/webrtc/v3"
 	"meeting-system/media-service/models"
 	"meeting-system/shared/config"
 	"meeting-system/shared/logger"
+	sharedmodels "meeting-system/shared/models"
+	"meeting-system/shared/queue"
 )
 
+// defaultICERestartGracePeriod 未配置时，连接中断后等待客户端 ICE restart 的宽限期
+const defaultICERestartGracePeriod = 15 * time.Second
+
+// eventPublishTimeout 向事件总线发布单条事件的超时
+const eventPublishTimeout = 2 * time.Second
+
+// EventPublisher 事件发布接口（生产环境为 Kafka 事件总线）
+type EventPublisher interface {
+	Publish(ctx context.Context, channel string, msg *queue.PubSubMessage) error
+}
+
 // WebRTCService WebRTC服务
 type WebRTCService struct {
 	config         *config.Config
@@ -23,6 +40,22 @@ type WebRTCService struct {
 	roomsMux       sync.RWMutex
 	peers          map[string]*Peer
 	peersMux       sync.RWMutex
+
+	// codecPolicies roomID -> 会议编解码策略（nil 表示使用默认编解码器）
+	codecPolicies    map[string]*sharedmodels.CodecPolicy
+	codecPoliciesMux sync.RWMutex
+
+	// isMeetingHost 混音控制的主持人校验，默认查询数据库，可在测试中替换
+	isMeetingHost func(meetingID, userID uint) (bool, error)
+
+	// peerStats 每个 Peer 的 RTP/RTCP 统计与滚动历史；pcCreateMux 串行化 PeerConnection 的创建，
+	// 以便从统计拦截器的同步回调（pendingStatsGetter）取到新连接对应的 Getter
+	peerStats          *peerStatsCollector
+	pcCreateMux        sync.Mutex
+	pendingStatsGetter stats.Getter
+
+	// events 通过信令服务通知客户端（ICE restart 等）
+	events EventPublisher
 }
 
 // Room WebRTC房间
@@ -36,6 +69,20 @@ type Room struct {
 	CreatedAt   time.Time
 	IsRecording bool
 	RecordingID string
+
+	// audioMixer 房间混音（首次有人订阅混音时创建），mixSenders 为订阅了 N-1 混音的 Peer
+	mixerMux   sync.RWMutex
+	audioMixer *AudioMixer
+	mixSenders map[string]*webrtc.RTPSender
+
+	// mutedMedia 主持人强制静音的媒体（"<userID>/<mediaType>"），对之后发布的轨道同样生效
+	moderationMux sync.RWMutex
+	mutedMedia    map[string]bool
+
+	// screenPresenters 正在共享屏幕的用户 -> 内容提示；screenExclusive 时其他用户的屏幕轨道暂停转发
+	screenMux        sync.RWMutex
+	screenExclusive  bool
+	screenPresenters map[string]string
 }
 
 // Peer WebRTC对等连接
@@ -46,7 +93,7 @@ type Peer struct {
 	Connection   *webrtc.PeerConnection
 	DataChannel  *webrtc.DataChannel
 	MediaType    string // audio, video, screen
-	Status       string // connecting, connected, disconnected
+	Status       string // connecting, connected, reconnecting, disconnected
 	CreatedAt    time.Time
 	LastActivity time.Time
 
@@ -59,6 +106,15 @@ type Peer struct {
 	isNegotiating              bool
 	needsRenegotiationAfterAck bool
 	subscribedExistingTracks   bool
+
+	// ICE 重启恢复：连接 disconnected/failed 后不立即清理，保留 Peer 及其转发/订阅关系，
+	// 等待客户端在同一 PeerConnection 上发起 ICE restart；宽限期到期仍未恢复才 cleanupPeer。
+	recoveryMux     sync.Mutex
+	recoveryTimer   *time.Timer
+	recoveringSince time.Time
+
+	// onCleanup 非 WebRTC 参与者（SIP 呼叫等没有 PeerConnection）被清理时的回调，用于挂断外部会话
+	onCleanup func(reason string)
 }
 
 // ForwardedTrack 房间内转发的媒体轨道（一个 remote track -> 一个本地 track，多 PeerConnection 绑定）
@@ -66,14 +122,24 @@ type ForwardedTrack struct {
 	Key         string
 	SenderPeer  string
 	RemoteTrack *webrtc.TrackRemote
-	// RemoteSSRC 是发布者向 SFU 发送该轨道的 SSRC（订阅者侧的 SSRC 会被 TrackLocalStaticRTP 重写）
+	// RemoteSSRC 是发布者向 SFU 发送该轨道的 SSRC（订阅者侧的 SSRC 会被 ForwardingTrack 重写）
 	RemoteSSRC uint32
-	LocalTrack  *webrtc.TrackLocalStaticRTP
+	// LocalTrack 负责 fan-out 与新订阅者的关键帧缓存回放
+	LocalTrack  *ForwardingTrack
 	// subscriberPeerID -> sender(本地 rtp sender)
 	// 用于在发布者离线/轨道结束时，能从订阅者 PeerConnection 中 RemoveTrack 并触发 renegotiation，
 	// 否则浏览器端会一直保留“僵尸轨道/僵尸窗口”，并造成资源泄漏与卡顿。
 	SubscriberSenders map[string]*webrtc.RTPSender
 	CreatedAt   time.Time
+
+	// roomID 轨道当前所在的房间（分组讨论迁移发布者时随之变更），转发循环据此混音与清理
+	roomID atomic.Value
+}
+
+// RoomID 轨道当前所在的房间
+func (ft *ForwardedTrack) RoomID() string {
+	roomID, _ := ft.roomID.Load().(string)
+	return roomID
 }
 
 // WebRTCMessage WebRTC消息
@@ -105,13 +171,17 @@ type ICECandidateData struct {
 
 // NewWebRTCService 创建WebRTC服务
 func NewWebRTCService(config *config.Config, mediaService *MediaService, mediaProcessor *MediaProcessor) *WebRTCService {
-	return &WebRTCService{
+	s := &WebRTCService{
 		config:         config,
 		mediaService:   mediaService,
 		mediaProcessor: mediaProcessor,
 		rooms:          make(map[string]*Room),
 		peers:          make(map[string]*Peer),
+		codecPolicies:  make(map[string]*sharedmodels.CodecPolicy),
 	}
+	s.isMeetingHost = s.isMeetingHostFromDB
+	s.peerStats = newPeerStatsCollector(s.statsInterval(), s.statsHistoryRetention())
+	return s
 }
 
 // Initialize 初始化WebRTC服务
@@ -123,12 +193,27 @@ func (s *WebRTCService) Initialize() error {
 	if err := mediaEngine.RegisterDefaultCodecs(); err != nil {
 		return fmt.Errorf("failed to register codecs: %w", err)
 	}
+	if s.audioREDEnabled() {
+		if err := mediaEngine.RegisterCodec(audioREDCodec(), webrtc.RTPCodecTypeAudio); err != nil {
+			return fmt.Errorf("failed to register audio RED codec: %w", err)
+		}
+	}
+	// AV1 SVC 的分层信息在 Dependency Descriptor 头扩展中
+	if err := mediaEngine.RegisterHeaderExtension(webrtc.RTPHeaderExtensionCapability{URI: av1DependencyDescriptorURI}, webrtc.RTPCodecTypeVideo); err != nil {
+		return fmt.Errorf("failed to register dependency descriptor extension: %w", err)
+	}
+
+	interceptors, err := s.newStatsInterceptors()
+	if err != nil {
+		return fmt.Errorf("failed to register stats interceptors: %w", err)
+	}
 
 	// 创建API实例
-	s.api = webrtc.NewAPI(webrtc.WithMediaEngine(mediaEngine))
+	s.api = webrtc.NewAPI(webrtc.WithMediaEngine(mediaEngine), webrtc.WithInterceptorRegistry(interceptors))
 
 	// 启动清理任务
 	go s.startCleanupTask()
+	go s.startStatsCollection()
 
 	logger.Info("WebRTC service initialized successfully")
 	return nil
@@ -161,6 +246,10 @@ func (s *WebRTCService) Stop() {
 
 // CreateAnswer 创建SDP Answer（响应客户端的Offer）
 func (s *WebRTCService) CreateAnswer(roomID, userID string, offer *webrtc.SessionDescription) (*webrtc.SessionDescription, string, error) {
+	if err := s.checkParticipantAdmitted(roomID, userID); err != nil {
+		return nil, "", err
+	}
+
 	// 创建对等连接
 	peerConnection, err := s.createPeerConnection()
 	if err != nil {
@@ -203,6 +292,13 @@ func (s *WebRTCService) CreateAnswer(roomID, userID string, offer *webrtc.Sessio
 		return nil, "", fmt.Errorf("failed to set remote description: %w", err)
 	}
 
+	// 按会议编解码策略设置 transceiver 编码偏好（同一会议内所有 Peer 一致，保证转发后可解码），
+	// 并优先选择 RED 使支持的发布者发送冗余音频
+	if err := applyCodecPolicy(peerConnection, s.roomCodecPolicy(roomID), s.audioREDEnabled()); err != nil {
+		peerConnection.Close()
+		return nil, "", err
+	}
+
 	// 创建Answer
 	answer, err := peerConnection.CreateAnswer(nil)
 	if err != nil {
@@ -245,7 +341,7 @@ func (s *WebRTCService) HandleAnswer(peerID string, answer *webrtc.SessionDescri
 	peer, exists := s.peers[peerID]
 	s.peersMux.RUnlock()
 
-	if !exists {
+	if !exists || peer.Connection == nil {
 		return fmt.Errorf("peer not found: %s", peerID)
 	}
 
@@ -267,7 +363,7 @@ func (s *WebRTCService) HandleICECandidate(peerID string, candidate *webrtc.ICEC
 	peer, exists := s.peers[peerID]
 	s.peersMux.RUnlock()
 
-	if !exists {
+	if !exists || peer.Connection == nil {
 		return fmt.Errorf("peer not found: %s", peerID)
 	}
 
@@ -292,6 +388,13 @@ func (p *Peer) markLocalICEGatheringCompleted() {
 	p.localICECandidatesMux.Unlock()
 }
 
+func (p *Peer) resetLocalICECandidates() {
+	p.localICECandidatesMux.Lock()
+	p.localICECandidates = nil
+	p.localICEGatheringCompleted = false
+	p.localICECandidatesMux.Unlock()
+}
+
 func (p *Peer) drainLocalICECandidates() ([]webrtc.ICECandidateInit, bool) {
 	p.localICECandidatesMux.Lock()
 	candidates := make([]webrtc.ICECandidateInit, len(p.localICECandidates))
@@ -304,6 +407,10 @@ func (p *Peer) drainLocalICECandidates() ([]webrtc.ICECandidateInit, bool) {
 
 // JoinRoom 加入房间
 func (s *WebRTCService) JoinRoom(roomID, userID string) (*Room, error) {
+	if err := s.checkParticipantAdmitted(roomID, userID); err != nil {
+		return nil, err
+	}
+
 	s.roomsMux.Lock()
 	defer s.roomsMux.Unlock()
 
@@ -374,6 +481,12 @@ func (s *WebRTCService) GetRoomPeers(roomID string) ([]*Peer, error) {
 	return peers, nil
 }
 
+// GetPeer 获取对等连接
+func (s *WebRTCService) GetPeer(peerID string) (*Peer, error) {
+	peer, _, err := s.findPeerRoom(peerID)
+	return peer, err
+}
+
 // createPeerConnection 创建对等连接
 func (s *WebRTCService) createPeerConnection() (*webrtc.PeerConnection, error) {
 	iceServers := make([]webrtc.ICEServer, 0, 4)
@@ -400,7 +513,17 @@ func (s *WebRTCService) createPeerConnection() (*webrtc.PeerConnection, error) {
 		ICEServers: iceServers,
 	}
 
-	return s.api.NewPeerConnection(config)
+	s.pcCreateMux.Lock()
+	defer s.pcCreateMux.Unlock()
+	s.pendingStatsGetter = nil
+	pc, err := s.api.NewPeerConnection(config)
+	if err != nil {
+		return nil, err
+	}
+	if s.pendingStatsGetter != nil {
+		s.peerStats.register(pc, s.pendingStatsGetter)
+	}
+	return pc, nil
 }
 
 // addMediaTracks 添加媒体轨道
@@ -449,6 +572,15 @@ func (s *WebRTCService) handleConnectionStateChange(peerID string, state webrtc.
 		peer.Status = "connected"
 		logger.Info(fmt.Sprintf("Peer %s connected", peerID))
 
+		if recoveredAfter, recovered := s.finishPeerRecovery(peer); recovered {
+			logger.Info(fmt.Sprintf("Peer %s recovered via ICE restart after %s", peerID, recoveredAfter.Round(time.Millisecond)))
+			// 链路中断期间丢包较多：双向请求关键帧，避免订阅端/被订阅端长时间花屏或黑屏。
+			go func(roomID string) {
+				s.requestRoomKeyFrames(roomID, peerID, false)
+				s.requestPublisherKeyFrames(roomID, peerID)
+			}(peer.RoomID)
+		}
+
 		peer.negotiationMux.Lock()
 		shouldSubscribe := !peer.subscribedExistingTracks
 		if shouldSubscribe {
@@ -461,7 +593,11 @@ func (s *WebRTCService) handleConnectionStateChange(peerID string, state webrtc.
 			// 避免在首轮 Answer 还未被客户端应用时覆盖本地描述导致 ICE 失败。
 			s.subscribePeerToExistingTracks(peer)
 		}
-	case webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
+	case webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateFailed:
+		// 网络切换（Wi-Fi -> LTE 等）会先进入 disconnected/failed：保留 Peer、ForwardedTrack 与 SubscriberSenders，
+		// 通知客户端发起 ICE restart；宽限期内未恢复才清理，避免全房间因一次网络抖动而重新协商。
+		s.beginPeerRecovery(peer, state)
+	case webrtc.PeerConnectionStateClosed:
 		peer.Status = "disconnected"
 		logger.Info(fmt.Sprintf("Peer %s disconnected", peerID))
 
@@ -475,6 +611,173 @@ func (s *WebRTCService) handleConnectionStateChange(peerID string, state webrtc.
 	go s.updatePeerStatus(peerID, peer.Status)
 }
 
+// keyframeCacheMaxPackets 每个视频转发轨道关键帧缓存的包数上限
+func (s *WebRTCService) keyframeCacheMaxPackets() int {
+	if s.config != nil {
+		return s.config.WebRTC.KeyframeCacheMaxPackets
+	}
+	return 0
+}
+
+// audioREDEnabled 是否协商音频 RED
+func (s *WebRTCService) audioREDEnabled() bool {
+	return s.config != nil && s.config.WebRTC.EnableAudioRED
+}
+
+// iceRestartGracePeriod 连接中断后等待 ICE restart 的宽限期
+func (s *WebRTCService) iceRestartGracePeriod() time.Duration {
+	if s.config != nil && s.config.WebRTC.ICERestartGracePeriod > 0 {
+		return time.Duration(s.config.WebRTC.ICERestartGracePeriod) * time.Second
+	}
+	return defaultICERestartGracePeriod
+}
+
+// beginPeerRecovery 进入 ICE 恢复状态：启动宽限期定时器并通知客户端发起 ICE restart
+func (s *WebRTCService) beginPeerRecovery(peer *Peer, state webrtc.PeerConnectionState) {
+	grace := s.iceRestartGracePeriod()
+	peerID := peer.ID
+
+	peer.recoveryMux.Lock()
+	if peer.recoveryTimer != nil {
+		// disconnected -> failed 只计一次宽限期
+		peer.recoveryMux.Unlock()
+		return
+	}
+	peer.recoveringSince = time.Now()
+	peer.recoveryTimer = time.AfterFunc(grace, func() {
+		s.cleanupPeer(peerID, "ice_restart_timeout")
+	})
+	peer.recoveryMux.Unlock()
+
+	peer.Status = "reconnecting"
+	logger.Info(fmt.Sprintf("Peer %s %s; waiting %s for ICE restart", peerID, state.String(), grace))
+
+	// 在 pion 的状态回调中执行：发布可能阻塞到超时，放到单独协程，避免拖住 PeerConnection 的回调队列
+	go s.notifyICERestart(peer, state.String(), grace)
+}
+
+// finishPeerRecovery 结束 ICE 恢复状态，返回恢复耗时与此前是否处于恢复中
+func (s *WebRTCService) finishPeerRecovery(peer *Peer) (time.Duration, bool) {
+	peer.recoveryMux.Lock()
+	defer peer.recoveryMux.Unlock()
+
+	if peer.recoveryTimer == nil {
+		return 0, false
+	}
+	peer.recoveryTimer.Stop()
+	peer.recoveryTimer = nil
+	elapsed := time.Since(peer.recoveringSince)
+	peer.recoveringSince = time.Time{}
+	return elapsed, true
+}
+
+// SetEventPublisher 设置事件发布器，经信令服务把 ICE restart 等通知推送给客户端
+func (s *WebRTCService) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+// notifyICERestart 通过信令服务通知客户端在原 Peer 上发起 ICE restart
+func (s *WebRTCService) notifyICERestart(peer *Peer, reason string, grace time.Duration) {
+	if s.events == nil {
+		return
+	}
+	meetingID := s.roomMeetingID(peer.RoomID)
+	userID, err := strconv.ParseUint(peer.UserID, 10, 64)
+	if meetingID == 0 || err != nil || userID == 0 {
+		logger.Warn(fmt.Sprintf("Skip ICE restart notification for peer %s: meeting or user unknown", peer.ID))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventPublishTimeout)
+	defer cancel()
+	if err := s.events.Publish(ctx, queue.ChannelSignalingEvents, &queue.PubSubMessage{
+		Type: queue.EventICERestart,
+		Payload: map[string]interface{}{
+			"meeting_id": meetingID,
+			"user_id":    uint(userID),
+			"message": sharedmodels.ICERestartMessage{
+				PeerID:        peer.ID,
+				RoomID:        peer.RoomID,
+				Reason:        reason,
+				GracePeriodMs: grace.Milliseconds(),
+			},
+		},
+		Source: "media-service",
+	}); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to notify ICE restart for peer %s: %v", peer.ID, err))
+	}
+}
+
+// RestartICE 处理客户端在已有 Peer 上发起的 ICE restart Offer
+// 复用原 PeerConnection：Peer、ForwardedTrack 与 SubscriberSenders 均保持不变，房间内其他用户无需 renegotiation。
+func (s *WebRTCService) RestartICE(peerID string, offer *webrtc.SessionDescription) (*webrtc.SessionDescription, error) {
+	if offer == nil || offer.Type != webrtc.SDPTypeOffer {
+		return nil, fmt.Errorf("ice restart requires an offer")
+	}
+
+	s.peersMux.RLock()
+	peer, exists := s.peers[peerID]
+	s.peersMux.RUnlock()
+	if !exists || peer == nil || peer.Connection == nil {
+		return nil, fmt.Errorf("peer not found: %s", peerID)
+	}
+
+	peer.negotiationMux.Lock()
+	defer peer.negotiationMux.Unlock()
+
+	if peer.isNegotiating {
+		return nil, fmt.Errorf("peer %s is negotiating, retry ice restart later", peerID)
+	}
+
+	// 服务端 renegotiation Offer 尚未被应答时与客户端 restart Offer 冲突（glare）：
+	// 回滚本地 Offer，restart 完成后再重新发起 renegotiation。
+	if peer.pendingOffer != nil {
+		if peer.Connection.SignalingState() == webrtc.SignalingStateHaveLocalOffer {
+			if err := peer.Connection.SetLocalDescription(webrtc.SessionDescription{
+				Type: webrtc.SDPTypeRollback,
+				SDP:  peer.pendingOffer.SDP,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to rollback pending offer: %w", err)
+			}
+		}
+		peer.pendingOffer = nil
+		peer.needsRenegotiationAfterAck = true
+	}
+
+	if err := peer.Connection.SetRemoteDescription(*offer); err != nil {
+		return nil, fmt.Errorf("failed to set remote description: %w", err)
+	}
+
+	answer, err := peer.Connection.CreateAnswer(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create answer: %w", err)
+	}
+
+	// 新一轮 ICE gathering：丢弃旧 ufrag 下的候选，客户端重新拉取
+	peer.resetLocalICECandidates()
+
+	if err := peer.Connection.SetLocalDescription(answer); err != nil {
+		return nil, fmt.Errorf("failed to set local description: %w", err)
+	}
+
+	finalAnswer := peer.Connection.LocalDescription()
+	if finalAnswer == nil {
+		finalAnswer = &answer
+	}
+
+	shouldRenegotiate := peer.needsRenegotiationAfterAck
+	peer.needsRenegotiationAfterAck = false
+	peer.LastActivity = time.Now()
+
+	go s.updatePeerInDB(peerID, finalAnswer.SDP)
+	if shouldRenegotiate {
+		go s.RequestRenegotiation(peerID)
+	}
+
+	logger.Info(fmt.Sprintf("ICE restart answered for peer %s (room=%s)", peerID, peer.RoomID))
+	return finalAnswer, nil
+}
+
 // handleICECandidate 处理ICE候选
 func (s *WebRTCService) handleICECandidate(peer *Peer, candidate *webrtc.ICECandidate) {
 	if candidate == nil {
@@ -585,9 +888,9 @@ func (s *WebRTCService) HandleRenegotiationAnswer(peerID string, answer *webrtc.
 		s.RequestRenegotiation(peerID)
 	}
 
-	// renegotiation 完成后，主动请求房间内所有视频发布者发送关键帧，
-	// 避免新订阅者长时间黑屏（等待自然关键帧或用户切换屏幕共享才出现画面）。
-	go s.requestRoomKeyFrames(roomID, peerID)
+	// renegotiation 完成后，新订阅者需要关键帧才能出画面：有关键帧缓存的轨道由 ForwardingTrack 直接回放，
+	// 只对缓存不可用的视频发布者发送 PLI，避免每次有人加入都向全房间发布者触发 PLI 风暴。
+	go s.requestRoomKeyFrames(roomID, peerID, true)
 
 	return nil
 }
@@ -627,9 +930,14 @@ func (s *WebRTCService) removePeerFromRoom(roomID, peerID string) {
 	delete(room.Peers, peerID)
 	room.PeersMux.Unlock()
 
-	// 如果房间为空，删除房间
+	// 如果房间为空，删除房间；下次有人加入时重新加载会议编解码策略
 	if len(room.Peers) == 0 {
 		delete(s.rooms, roomID)
+		room.closeMixer()
+
+		s.codecPoliciesMux.Lock()
+		delete(s.codecPolicies, roomID)
+		s.codecPoliciesMux.Unlock()
 	}
 }
 
@@ -645,6 +953,9 @@ func (s *WebRTCService) cleanupPeer(peerID string, reason string) {
 		return
 	}
 
+	s.finishPeerRecovery(peer)
+	s.peerStats.closePeer(peerID, peer.Connection, time.Now())
+
 	roomID := peer.RoomID
 
 	// 先从房间 peers 中移除，避免后续订阅/转发继续向该 peer AddTrack。
@@ -670,20 +981,11 @@ func (s *WebRTCService) cleanupPeer(peerID string, reason string) {
 
 		// 2) 下线该 peer 发布的轨道，并对订阅者执行 RemoveTrack + renegotiation
 		s.unpublishPeerTracks(roomID, peerID, reason)
+		room.removeMixListener(peerID)
 
 		// 房间已空则删除（避免 map 膨胀）
 		if roomEmpty {
-			s.roomsMux.Lock()
-			// double-check
-			if current, ok := s.rooms[roomID]; ok && current != nil {
-				current.PeersMux.RLock()
-				empty := len(current.Peers) == 0
-				current.PeersMux.RUnlock()
-				if empty {
-					delete(s.rooms, roomID)
-				}
-			}
-			s.roomsMux.Unlock()
+			s.deleteRoomIfEmpty(roomID)
 		}
 	}
 
@@ -691,6 +993,9 @@ func (s *WebRTCService) cleanupPeer(peerID string, reason string) {
 	if peer.Connection != nil {
 		_ = peer.Connection.Close()
 	}
+	if peer.onCleanup != nil {
+		peer.onCleanup(reason)
+	}
 
 	peer.Status = "disconnected"
 	peer.LastActivity = time.Now()
@@ -699,6 +1004,22 @@ func (s *WebRTCService) cleanupPeer(peerID string, reason string) {
 	logger.Info(fmt.Sprintf("Cleaned up peer %s (room=%s, reason=%s)", peerID, roomID, reason))
 }
 
+// deleteRoomIfEmpty 房间已没有 Peer 时删除并停止混音
+func (s *WebRTCService) deleteRoomIfEmpty(roomID string) {
+	s.roomsMux.Lock()
+	defer s.roomsMux.Unlock()
+	// double-check
+	if current, ok := s.rooms[roomID]; ok && current != nil {
+		current.PeersMux.RLock()
+		empty := len(current.Peers) == 0
+		current.PeersMux.RUnlock()
+		if empty {
+			delete(s.rooms, roomID)
+			current.closeMixer()
+		}
+	}
+}
+
 func (s *WebRTCService) unpublishPeerTracks(roomID string, senderPeerID string, reason string) {
 	if roomID == "" || senderPeerID == "" {
 		return
@@ -761,11 +1082,12 @@ func (s *WebRTCService) removeForwardedTrack(roomID string, trackKey string, rea
 	ft.SubscriberSenders = nil
 	room.TracksMux.Unlock()
 
-	if len(subscribers) == 0 {
-		logger.Info(fmt.Sprintf("Removed forwarded track %s (room=%s, sender=%s, reason=%s, subscribers=0)", trackKey, roomID, senderPeerID, reason))
-		return
-	}
+	s.detachTrackSubscribers(trackKey, senderPeerID, subscribers)
+	logger.Info(fmt.Sprintf("Removed forwarded track %s (room=%s, sender=%s, reason=%s, subscribers=%d)", trackKey, roomID, senderPeerID, reason, len(subscribers)))
+}
 
+// detachTrackSubscribers 从订阅者 PeerConnection 中 RemoveTrack 并触发 renegotiation
+func (s *WebRTCService) detachTrackSubscribers(trackKey, senderPeerID string, subscribers map[string]*webrtc.RTPSender) {
 	for subPeerID, sender := range subscribers {
 		if subPeerID == "" || sender == nil {
 			continue
@@ -783,8 +1105,6 @@ func (s *WebRTCService) removeForwardedTrack(roomID string, trackKey string, rea
 		}
 		s.RequestRenegotiation(subPeerID)
 	}
-
-	logger.Info(fmt.Sprintf("Removed forwarded track %s (room=%s, sender=%s, reason=%s, subscribers=%d)", trackKey, roomID, senderPeerID, reason, len(subscribers)))
 }
 
 // savePeerToDB 保存Peer到数据库
@@ -909,12 +1229,13 @@ func (s *WebRTCService) handleTrack(peerID string, track *webrtc.TrackRemote, re
 		if err := s.mediaProcessor.RegisterStream(streamID, peer.UserID, peer.RoomID, nil, nil, aiTasks); err != nil {
 			logger.Error(fmt.Sprintf("Failed to register stream: %v", err))
 		} else {
+			s.mediaProcessor.SetStreamSource(streamID, s.roomMeetingID(peer.RoomID), peerID, track.ID(), track.Kind())
 			logger.Info(fmt.Sprintf("Stream registered for AI processing: %s", streamID))
 		}
 	}
 
 	// 转发轨道到房间内其他用户（SFU模式）
-	s.forwardTrackToRoom(peer.RoomID, peerID, track)
+	s.forwardTrackToRoom(peer.RoomID, peerID, track, receiver)
 }
 
 func (s *WebRTCService) subscribePeerToExistingTracks(peer *Peer) {
@@ -929,6 +1250,7 @@ func (s *WebRTCService) subscribePeerToExistingTracks(peer *Peer) {
 		return
 	}
 
+	onMix := room.onRoomMix(peer.ID)
 	room.TracksMux.RLock()
 	tracks := make([]*ForwardedTrack, 0, len(room.Tracks))
 	for _, t := range room.Tracks {
@@ -939,6 +1261,10 @@ func (s *WebRTCService) subscribePeerToExistingTracks(peer *Peer) {
 		if t.SenderPeer == peer.ID {
 			continue
 		}
+		// 订阅了 N-1 混音的 Peer 从混音中收听该音频
+		if onMix && mixableAudio(t.LocalTrack) {
+			continue
+		}
 		tracks = append(tracks, t)
 	}
 	room.TracksMux.RUnlock()
@@ -994,7 +1320,7 @@ func (s *WebRTCService) subscribePeerToExistingTracks(peer *Peer) {
 		cur.SubscriberSenders[peer.ID] = rtpSender
 		room.TracksMux.Unlock()
 
-		go s.processRTCP(peer.ID, rtpSender, senderPeerID, publisherSSRC)
+		go s.processRTCP(peer.ID, rtpSender, senderPeerID, publisherSSRC, localTrack)
 		added = true
 	}
 
@@ -1030,7 +1356,7 @@ func (s *WebRTCService) determineAITasks(roomID string, trackKind webrtc.RTPCode
 }
 
 // forwardTrackToRoom 转发轨道到房间内其他用户
-func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *webrtc.TrackRemote) {
+func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
 	s.roomsMux.RLock()
 	room, exists := s.rooms[roomID]
 	s.roomsMux.RUnlock()
@@ -1050,19 +1376,25 @@ func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *w
 	trackKey := fmt.Sprintf("%s:%s", senderPeerID, track.ID())
 	aiStreamID := fmt.Sprintf("%s_%s", senderPeerID, track.ID())
 
+	publish := s.trackPublishState(room, senderPeerID, track.Kind(), track.ID(), track.StreamID())
+	if publish.screenShare {
+		// 屏幕共享使用独立的 MediaStream，订阅者据此与摄像头画面分开渲染
+		streamID = senderPeerID + screenShareStreamSuffix
+	}
+
 	// 为每个 remote track 创建一个本地 track（可绑定到多个 PeerConnection），并启动单一 RTP 转发循环
 	room.TracksMux.Lock()
 	ft, ok := room.Tracks[trackKey]
 	if !ok {
-		localTrack, err := webrtc.NewTrackLocalStaticRTP(
+		localTrack := NewForwardingTrack(
 			track.Codec().RTPCodecCapability,
 			track.ID(),
 			streamID,
+			s.keyframeCacheMaxPackets(),
 		)
-		if err != nil {
-			room.TracksMux.Unlock()
-			logger.Error(fmt.Sprintf("Failed to create local track: %v", err))
-			return
+		publish.apply(localTrack)
+		if receiver != nil {
+			localTrack.SetSourceHeaderExtensions(receiver.GetParameters().HeaderExtensions)
 		}
 
 		ft = &ForwardedTrack{
@@ -1074,14 +1406,56 @@ func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *w
 			SubscriberSenders: make(map[string]*webrtc.RTPSender),
 			CreatedAt:   time.Now(),
 		}
+		ft.roomID.Store(roomID)
 		room.Tracks[trackKey] = ft
 
-		go s.forwardRTP(roomID, trackKey, senderPeerID, track, localTrack, aiStreamID, track.Kind())
+		go s.forwardRTP(ft, senderPeerID, track, localTrack, aiStreamID, track.Kind())
+		if receiver != nil {
+			go readReceiverRTCP(receiver)
+		}
 	}
 	localTrack := ft.LocalTrack
 	room.TracksMux.Unlock()
 
-	// 绑定到房间内其它 PeerConnection（由本地 track 负责 fan-out）
+	s.bindTrackToRoomPeers(room, trackKey, senderPeerID, uint32(track.SSRC()), localTrack)
+}
+
+// publishExternalTrack 发布非 WebRTC 来源（SIP 呼叫等）的媒体轨道：调用方向返回的本地 track 写入 RTP，
+// 房间内其它 PeerConnection 与普通发布轨道一样订阅，发布者清理时随 unpublishPeerTracks 下线
+func (s *WebRTCService) publishExternalTrack(roomID, senderPeerID, trackID string, codec webrtc.RTPCodecCapability) (*ForwardingTrack, error) {
+	s.roomsMux.RLock()
+	room, exists := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("room not found: %s", roomID)
+	}
+
+	trackKey := fmt.Sprintf("%s:%s", senderPeerID, trackID)
+	localTrack := NewForwardingTrack(codec, trackID, senderPeerID, s.keyframeCacheMaxPackets())
+	s.trackPublishState(room, senderPeerID, localTrack.Kind(), trackID, "").apply(localTrack)
+
+	room.TracksMux.Lock()
+	if _, ok := room.Tracks[trackKey]; ok {
+		room.TracksMux.Unlock()
+		return nil, fmt.Errorf("track already published: %s", trackKey)
+	}
+	ft := &ForwardedTrack{
+		Key:               trackKey,
+		SenderPeer:        senderPeerID,
+		LocalTrack:        localTrack,
+		SubscriberSenders: make(map[string]*webrtc.RTPSender),
+		CreatedAt:         time.Now(),
+	}
+	ft.roomID.Store(roomID)
+	room.Tracks[trackKey] = ft
+	room.TracksMux.Unlock()
+
+	s.bindTrackToRoomPeers(room, trackKey, senderPeerID, 0, localTrack)
+	return localTrack, nil
+}
+
+// bindTrackToRoomPeers 把转发轨道绑定到房间内其它已连接的 PeerConnection（由本地 track 负责 fan-out）
+func (s *WebRTCService) bindTrackToRoomPeers(room *Room, trackKey, senderPeerID string, publisherSSRC uint32, localTrack *ForwardingTrack) {
 	room.PeersMux.RLock()
 	peers := make([]*Peer, 0, len(room.Peers))
 	for peerID, peer := range room.Peers {
@@ -1100,6 +1474,10 @@ func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *w
 			// 连接尚未完成时不做 AddTrack/renegotiation，等 connected 后统一订阅已有轨道
 			continue
 		}
+		if mixableAudio(localTrack) && room.onRoomMix(peer.ID) {
+			// 订阅了 N-1 混音的 Peer 从混音中收听该音频
+			continue
+		}
 
 		// 预留订阅位置，避免重复 AddTrack
 		room.TracksMux.Lock()
@@ -1144,7 +1522,7 @@ func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *w
 		cur.SubscriberSenders[peer.ID] = rtpSender
 		room.TracksMux.Unlock()
 
-		go s.processRTCP(peer.ID, rtpSender, senderPeerID, uint32(track.SSRC()))
+		go s.processRTCP(peer.ID, rtpSender, senderPeerID, publisherSSRC, localTrack)
 		s.RequestRenegotiation(peer.ID)
 
 		logger.Debug(fmt.Sprintf("Track forwarded from %s to %s", senderPeerID, peer.ID))
@@ -1152,18 +1530,54 @@ func (s *WebRTCService) forwardTrackToRoom(roomID, senderPeerID string, track *w
 }
 
 // forwardRTP 转发RTP包（单读 TrackRemote -> fan-out 到各 PeerConnection，并可选分发给 AI 缓冲区）
-func (s *WebRTCService) forwardRTP(roomID string, trackKey string, senderPeerID string, remoteTrack *webrtc.TrackRemote, localTrack *webrtc.TrackLocalStaticRTP, aiStreamID string, kind webrtc.RTPCodecType) {
+func (s *WebRTCService) forwardRTP(ft *ForwardedTrack, senderPeerID string, remoteTrack *webrtc.TrackRemote, localTrack *ForwardingTrack, aiStreamID string, kind webrtc.RTPCodecType) {
+	trackKey := ft.Key
+
+	// Opus 音频同时送入房间混音（仅在有人订阅混音、混音器存在时解码）；发布者被迁移到其他房间后改送新房间的混音
+	mixable := mixableAudio(localTrack)
+	var mixRoom *Room
+	var mixRoomID string
+	resolveMixRoom := func() {
+		if roomID := ft.RoomID(); roomID != mixRoomID {
+			mixRoomID = roomID
+			s.roomsMux.RLock()
+			mixRoom = s.rooms[roomID]
+			s.roomsMux.RUnlock()
+		}
+	}
+	if mixable {
+		resolveMixRoom()
+	}
+
 	defer func() {
 		if s.mediaProcessor != nil && aiStreamID != "" {
 			_ = s.mediaProcessor.UnregisterStream(aiStreamID)
 		}
 		// 轨道结束后，必须从房间 tracks 中移除并对订阅者执行 RemoveTrack + renegotiation，
 		// 否则浏览器端会一直保留“僵尸窗口/僵尸轨道”，并累积造成卡顿。
-		if roomID != "" && trackKey != "" {
+		if roomID := ft.RoomID(); roomID != "" && trackKey != "" {
 			s.removeForwardedTrack(roomID, trackKey, "rtp_end")
 		}
+		if mixRoom != nil {
+			if mixer := mixRoom.mixer(); mixer != nil {
+				mixer.RemoveTrack(trackKey)
+			}
+		}
 	}()
 
+	// AI 音频分流只支持 Opus（RED 取主编码），视频抽帧只支持 VP8/H.264
+	aiIngest := s.mediaProcessor != nil && aiStreamID != ""
+	if aiIngest && kind == webrtc.RTPCodecTypeAudio && !localTrack.IsRED() && !isOpus(localTrack.Codec().MimeType) {
+		logger.Warn(fmt.Sprintf("AI audio tap skipped for %s: unsupported codec %s", aiStreamID, localTrack.Codec().MimeType))
+		aiIngest = false
+	}
+	if aiIngest && kind == webrtc.RTPCodecTypeVideo {
+		if err := s.mediaProcessor.EnableVideoSampling(aiStreamID, localTrack.Codec().MimeType); err != nil {
+			logger.Warn(fmt.Sprintf("AI video sampling skipped for %s: %v", aiStreamID, err))
+			aiIngest = false
+		}
+	}
+
 	lastTouch := time.Now()
 	for {
 		// 读取RTP包
@@ -1184,13 +1598,33 @@ func (s *WebRTCService) forwardRTP(roomID string, trackKey string, senderPeerID
 			}
 		}
 
-		if s.mediaProcessor != nil && aiStreamID != "" {
-			s.mediaProcessor.IngestRTPPayload(aiStreamID, kind, rtpPacket.Payload)
+		if localTrack.Suppressed() {
+			// 强制静音或屏幕共享被接管：不送 AI、不混音，WriteRTP 丢弃该包并保持订阅者侧序列号连续
+			_ = localTrack.WriteRTP(rtpPacket)
+			continue
+		}
+
+		if aiIngest {
+			payload := rtpPacket.Payload
+			if localTrack.IsRED() {
+				// AI 只需要主编码 Opus
+				payload, _ = redPrimaryPayload(payload)
+			}
+			if len(payload) > 0 {
+				s.mediaProcessor.IngestRTPPayload(aiStreamID, kind, &rtpPacket.Header, payload)
+			}
+		}
+
+		if mixable {
+			resolveMixRoom()
+		}
+		if mixRoom != nil {
+			mixAudioPacket(mixRoom, trackKey, senderPeerID, rtpPacket, localTrack.IsRED())
 		}
 
 		// 写入到本地轨道
 		if err := localTrack.WriteRTP(rtpPacket); err != nil {
-			// 注意：TrackLocalStaticRTP 可能会在某个 PeerConnection 写入失败时返回 error，
+			// 注意：ForwardingTrack 可能会在某个 PeerConnection 写入失败时返回 error，
 			// 但仍然会继续向其它 PeerConnection 写入；这里不应停止整个转发循环。
 			logger.Warn(fmt.Sprintf("RTP write warning: %v", err))
 		}
@@ -1198,7 +1632,7 @@ func (s *WebRTCService) forwardRTP(roomID string, trackKey string, senderPeerID
 }
 
 // processRTCP 处理RTCP包
-func (s *WebRTCService) processRTCP(subscriberPeerID string, rtpSender *webrtc.RTPSender, senderPeerID string, publisherSSRC uint32) {
+func (s *WebRTCService) processRTCP(subscriberPeerID string, rtpSender *webrtc.RTPSender, senderPeerID string, publisherSSRC uint32, localTrack *ForwardingTrack) {
 	rtcpBuf := make([]byte, 1500)
 	for {
 		n, _, err := rtpSender.Read(rtcpBuf)
@@ -1222,13 +1656,16 @@ func (s *WebRTCService) processRTCP(subscriberPeerID string, rtpSender *webrtc.R
 		for _, pkt := range pkts {
 			switch p := pkt.(type) {
 			case *rtcp.PictureLossIndication:
-				// TrackLocalStaticRTP 会为每个订阅者重写 SSRC，RTCP PLI 的 MediaSSRC 不能直接转发给发布者端。
+				// ForwardingTrack 会为每个订阅者重写 SSRC，RTCP PLI 的 MediaSSRC 不能直接转发给发布者端。
 				// 必须使用发布者 remote track 的 SSRC 才能触发正确的关键帧请求。
-				s.sendPLI(senderPeerID, publisherSSRC)
+				s.forwardKeyframeRequest(senderPeerID, publisherSSRC, localTrack)
 			case *rtcp.FullIntraRequest:
 				// FIR 更常见于视频流；这里统一用 PLI 触发关键帧即可
 				_ = p
-				s.sendPLI(senderPeerID, publisherSSRC)
+				s.forwardKeyframeRequest(senderPeerID, publisherSSRC, localTrack)
+			case *rtcp.ReceiverEstimatedMaximumBitrate:
+				// 按订阅者带宽为 SVC 轨道选择分层，或限制非 SVC 发布者的码率
+				s.applySubscriberBitrate(subscriberPeerID, rtpSender, senderPeerID, publisherSSRC, localTrack, p.Bitrate)
 			}
 		}
 	}
@@ -1253,7 +1690,39 @@ func (s *WebRTCService) sendPLI(senderPeerID string, mediaSSRC uint32) {
 	}
 }
 
-func (s *WebRTCService) requestRoomKeyFrames(roomID, excludePeerID string) {
+// requestPublisherKeyFrames 请求指定发布者的所有视频轨道发送关键帧
+func (s *WebRTCService) requestPublisherKeyFrames(roomID, senderPeerID string) {
+	if roomID == "" || senderPeerID == "" {
+		return
+	}
+
+	s.roomsMux.RLock()
+	room, exists := s.rooms[roomID]
+	s.roomsMux.RUnlock()
+	if !exists || room == nil {
+		return
+	}
+
+	room.TracksMux.RLock()
+	ssrcs := make([]uint32, 0, 2)
+	for _, t := range room.Tracks {
+		if t == nil || t.SenderPeer != senderPeerID {
+			continue
+		}
+		if t.RemoteTrack == nil || t.RemoteTrack.Kind() != webrtc.RTPCodecTypeVideo {
+			continue
+		}
+		ssrcs = append(ssrcs, t.RemoteSSRC)
+	}
+	room.TracksMux.RUnlock()
+
+	for _, ssrc := range ssrcs {
+		s.sendPLI(senderPeerID, ssrc)
+	}
+}
+
+// requestRoomKeyFrames 请求房间内视频发布者发送关键帧；skipCached 时跳过关键帧缓存可用的轨道
+func (s *WebRTCService) requestRoomKeyFrames(roomID, excludePeerID string, skipCached bool) {
 	if roomID == "" {
 		return
 	}
@@ -1282,6 +1751,9 @@ func (s *WebRTCService) requestRoomKeyFrames(roomID, excludePeerID string) {
 		if t.RemoteTrack == nil || t.RemoteTrack.Kind() != webrtc.RTPCodecTypeVideo {
 			continue
 		}
+		if skipCached && t.LocalTrack != nil && t.LocalTrack.HasKeyframe() {
+			continue
+		}
 		s.sendPLI(t.SenderPeer, t.RemoteSSRC)
 	}
 }
@@ -0,0 +1,290 @@
+package sip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+// Direction 呼叫方向
+type Direction string
+
+const (
+	DirectionInbound  Direction = "inbound"
+	DirectionOutbound Direction = "outbound"
+)
+
+// CallState 呼叫状态
+type CallState string
+
+const (
+	CallStateRinging   CallState = "ringing"
+	CallStateAnswered  CallState = "answered"
+	CallStateConfirmed CallState = "confirmed"
+	CallStateEnded     CallState = "ended"
+)
+
+// ErrCallEnded 呼叫已结束
+var ErrCallEnded = errors.New("call ended")
+
+// Call 一路 SIP 呼叫（一个对话 + 一路 RTP 会话）
+type Call struct {
+	ua        *UserAgent
+	id        string
+	direction Direction
+	// remoteSignal 对端信令地址（入呼叫为 INVITE 的源地址，外呼为目标/代理地址）
+	remoteSignal *net.UDPAddr
+	session      *rtpSession
+
+	mu         sync.Mutex
+	state      CallState
+	localURI   string // 对话中本端的 From/To 值（不含 tag）
+	remoteURI  string
+	localTag   string
+	remoteTag  string
+	remoteTgt  string // 对端 Contact，作为对话内请求的 Request-URI
+	localSeq   int
+	invite     *Message
+	lastResp   *Message
+	codec      Codec
+	ackCh      chan struct{}
+	endReason  string
+	endOnce    sync.Once
+	done       chan struct{}
+	answerOnce sync.Once
+}
+
+// ID 返回 Call-ID
+func (c *Call) ID() string { return c.id }
+
+// Direction 返回呼叫方向
+func (c *Call) Direction() Direction { return c.direction }
+
+// RemoteUser 返回对端号码（入呼叫为主叫，外呼为被叫）
+func (c *Call) RemoteUser() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return URIUser(addressURI(c.remoteURI))
+}
+
+// LocalUser 返回本端号码（入呼叫为被叫号码）
+func (c *Call) LocalUser() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return URIUser(addressURI(c.localURI))
+}
+
+// Codec 返回协商出的音频编解码器
+func (c *Call) Codec() Codec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.codec
+}
+
+// State 返回呼叫状态
+func (c *Call) State() CallState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Done 呼叫结束时关闭
+func (c *Call) Done() <-chan struct{} { return c.done }
+
+// EndReason 呼叫结束原因
+func (c *Call) EndReason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endReason
+}
+
+// DTMF 对端按键（RFC 4733）
+func (c *Call) DTMF() <-chan rune { return c.session.dtmf }
+
+// OnAudio 设置对端音频 RTP 回调（不含 telephone-event）
+func (c *Call) OnAudio(fn func(*rtp.Packet)) { c.session.setAudioHandler(fn) }
+
+// WriteAudio 向对端发送一帧已编码音频，samples 为编解码器时钟下的样本数
+func (c *Call) WriteAudio(payload []byte, samples uint32) error {
+	return c.session.writeAudio(payload, samples)
+}
+
+// SendDTMF 向对端发送按键
+func (c *Call) SendDTMF(digit rune) error {
+	return c.session.sendDTMF(digit, 100*time.Millisecond)
+}
+
+// Answer 以 200 OK 接听入呼叫，并在收到 ACK 前按 RFC 3261 17.2.1 重传
+func (c *Call) Answer() error {
+	if c.direction != DirectionInbound {
+		return errors.New("only inbound calls can be answered")
+	}
+
+	var err error
+	c.answerOnce.Do(func() {
+		c.mu.Lock()
+		if c.state != CallStateRinging {
+			c.mu.Unlock()
+			err = ErrCallEnded
+			return
+		}
+		codec := c.codec
+		resp := c.responseLocked(200, "OK")
+		resp.AddHeader("Contact", c.ua.contact())
+		resp.AddHeader("Content-Type", "application/sdp")
+		resp.Body = buildSDP(c.ua.mediaIP(), c.session.port(), c.ua.sessionID(), []Codec{codec}, c.dtmfAnswerPT())
+		c.state = CallStateAnswered
+		c.lastResp = resp
+		c.mu.Unlock()
+
+		c.ua.send(resp, c.remoteSignal)
+		go c.retransmitAnswer(resp)
+	})
+	return err
+}
+
+// Reject 以最终错误响应拒绝尚未接听的入呼叫
+func (c *Call) Reject(code int, reason string) {
+	c.mu.Lock()
+	if c.direction != DirectionInbound || c.state != CallStateRinging {
+		c.mu.Unlock()
+		return
+	}
+	resp := c.responseLocked(code, reason)
+	c.lastResp = resp
+	c.mu.Unlock()
+
+	c.ua.send(resp, c.remoteSignal)
+	c.end(fmt.Sprintf("rejected_%d", code))
+}
+
+// Hangup 挂断：已接通发送 BYE，未接听的入呼叫回 480
+func (c *Call) Hangup() {
+	c.mu.Lock()
+	state := c.state
+	c.mu.Unlock()
+
+	switch state {
+	case CallStateEnded:
+		return
+	case CallStateRinging:
+		c.Reject(480, "Temporarily Unavailable")
+		return
+	}
+
+	bye := c.inDialogRequest(MethodBye)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// 对端未响应 BYE 也结束本地呼叫
+	_, _ = c.ua.roundTrip(ctx, bye, c.remoteSignal)
+	c.end("local_hangup")
+}
+
+func (c *Call) dtmfAnswerPT() int {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	return c.session.dtmfPT
+}
+
+// responseLocked 对 INVITE 的响应（调用方持有 c.mu）
+func (c *Call) responseLocked(code int, reason string) *Message {
+	resp := NewResponse(c.invite, code, reason)
+	if code > 100 {
+		resp.SetHeader("To", withTag(c.invite.Header("To"), c.localTag))
+	}
+	return resp
+}
+
+func (c *Call) retransmitAnswer(resp *Message) {
+	interval := timerT1
+	deadline := time.NewTimer(64 * timerT1)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-c.ackCh:
+			return
+		case <-c.done:
+			return
+		case <-deadline.C:
+			// 一直没有 ACK，放弃呼叫
+			c.end("ack_timeout")
+			return
+		case <-time.After(interval):
+			c.ua.send(resp, c.remoteSignal)
+			interval *= 2
+			if interval > timerT2 {
+				interval = timerT2
+			}
+		}
+	}
+}
+
+// inDialogRequest 构造对话内请求（BYE 等）
+func (c *Call) inDialogRequest(method string) *Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.localSeq++
+	target := c.remoteTgt
+	if target == "" {
+		target = addressURI(c.remoteURI)
+	}
+	req := NewRequest(method, target)
+	req.AddHeader("Via", c.ua.via(newBranch()))
+	req.AddHeader("Max-Forwards", "70")
+	req.AddHeader("From", withTag(c.localURI, c.localTag))
+	req.AddHeader("To", withTag(c.remoteURI, c.remoteTag))
+	req.AddHeader("Call-ID", c.id)
+	req.AddHeader("CSeq", fmt.Sprintf("%d %s", c.localSeq, method))
+	req.AddHeader("User-Agent", c.ua.cfg.UserAgent)
+	return req
+}
+
+// confirm 收到 ACK（入呼叫）或发送 ACK（外呼）后对话确认
+func (c *Call) confirm() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == CallStateAnswered {
+		c.state = CallStateConfirmed
+		close(c.ackCh)
+	}
+}
+
+func (c *Call) end(reason string) {
+	c.endOnce.Do(func() {
+		c.mu.Lock()
+		c.state = CallStateEnded
+		c.endReason = reason
+		c.mu.Unlock()
+
+		c.session.close()
+		c.ua.removeCall(c.id)
+		close(c.done)
+	})
+}
+
+// withTag 给 From/To 头域值附加 tag 参数（已有 tag 时保持不变）
+func withTag(value, tag string) string {
+	if tag == "" || headerParam(value, "tag") != "" {
+		return value
+	}
+	return value + ";tag=" + tag
+}
+
+// stripTag 去掉 From/To 头域中的 tag 参数
+func stripTag(value string) string {
+	idx := strings.LastIndex(strings.ToLower(value), ";tag=")
+	if idx < 0 || idx < strings.LastIndexByte(value, '>') {
+		return value
+	}
+	if end := strings.IndexByte(value[idx+1:], ';'); end >= 0 {
+		return value[:idx] + value[idx+1+end:]
+	}
+	return value[:idx]
+}
@@ -0,0 +1,115 @@
+package sip
+
+// G.711 μ-law / A-law 编解码（ITU-T G.711），8kHz 单声道，每个样本 1 字节
+
+const (
+	ulawBias = 0x84
+	ulawClip = 32635
+)
+
+// MulawEncode 把 16bit PCM 编码为 μ-law，追加到 out
+func MulawEncode(out []byte, pcm []int16) []byte {
+	for _, s := range pcm {
+		out = append(out, linearToUlaw(s))
+	}
+	return out
+}
+
+// MulawDecode 把 μ-law 解码为 16bit PCM，追加到 out
+func MulawDecode(out []int16, data []byte) []int16 {
+	for _, b := range data {
+		out = append(out, ulawToLinear(b))
+	}
+	return out
+}
+
+// AlawEncode 把 16bit PCM 编码为 A-law，追加到 out
+func AlawEncode(out []byte, pcm []int16) []byte {
+	for _, s := range pcm {
+		out = append(out, linearToAlaw(s))
+	}
+	return out
+}
+
+// AlawDecode 把 A-law 解码为 16bit PCM，追加到 out
+func AlawDecode(out []int16, data []byte) []int16 {
+	for _, b := range data {
+		out = append(out, alawToLinear(b))
+	}
+	return out
+}
+
+func linearToUlaw(sample int16) byte {
+	s := int32(sample)
+	sign := byte(0)
+	if s < 0 {
+		s = -s
+		sign = 0x80
+	}
+	if s > ulawClip {
+		s = ulawClip
+	}
+	s += ulawBias
+
+	exponent := byte(7)
+	for mask := int32(0x4000); s&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := byte(s>>(exponent+3)) & 0x0f
+	return ^(sign | exponent<<4 | mantissa)
+}
+
+func ulawToLinear(b byte) int16 {
+	b = ^b
+	sign := b & 0x80
+	exponent := (b >> 4) & 0x07
+	mantissa := b & 0x0f
+	s := ((int32(mantissa) << 3) + ulawBias) << exponent
+	s -= ulawBias
+	if sign != 0 {
+		s = -s
+	}
+	return int16(s)
+}
+
+func linearToAlaw(sample int16) byte {
+	s := int32(sample)
+	mask := byte(0xd5)
+	if s < 0 {
+		s = -s - 1
+		mask = 0x55
+	}
+	if s > 32767 {
+		s = 32767
+	}
+
+	var out byte
+	if s < 256 {
+		out = byte(s >> 4)
+	} else {
+		exponent := byte(7)
+		for bit := int32(0x4000); s&bit == 0 && exponent > 1; bit >>= 1 {
+			exponent--
+		}
+		out = exponent<<4 | byte(s>>(exponent+3))&0x0f
+	}
+	return out ^ mask
+}
+
+func alawToLinear(b byte) int16 {
+	b ^= 0x55
+	sign := b & 0x80
+	exponent := (b >> 4) & 0x07
+	mantissa := int32(b & 0x0f)
+
+	var s int32
+	if exponent == 0 {
+		s = mantissa<<4 + 8
+	} else {
+		s = (mantissa<<4 + 0x108) << (exponent - 1)
+	}
+	if sign == 0 {
+		s = -s
+	}
+	return int16(s)
+}
@@ -0,0 +1,335 @@
+package sip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 只实现网关需要的 SIP 子集（RFC 3261 over UDP）：INVITE/ACK/BYE/CANCEL/OPTIONS
+
+const (
+	MethodInvite  = "INVITE"
+	MethodAck     = "ACK"
+	MethodBye     = "BYE"
+	MethodCancel  = "CANCEL"
+	MethodOptions = "OPTIONS"
+
+	sipVersion = "SIP/2.0"
+	// branchMagicCookie RFC 3261 事务分支标识前缀
+	branchMagicCookie = "z9hG4bK"
+)
+
+var errMalformedMessage = errors.New("malformed sip message")
+
+// compactHeaders 紧凑头域名 -> 完整头域名
+var compactHeaders = map[string]string{
+	"v": "Via",
+	"f": "From",
+	"t": "To",
+	"i": "Call-ID",
+	"m": "Contact",
+	"l": "Content-Length",
+	"c": "Content-Type",
+}
+
+type header struct {
+	name  string
+	value string
+}
+
+// Message SIP 请求或响应（Method 非空为请求）
+type Message struct {
+	Method     string
+	RequestURI string
+
+	StatusCode int
+	Reason     string
+
+	headers []header
+	Body    []byte
+}
+
+// NewRequest 创建请求
+func NewRequest(method, uri string) *Message {
+	return &Message{Method: method, RequestURI: uri}
+}
+
+// NewResponse 按 RFC 3261 8.2.6 从请求复制 Via/From/To/Call-ID/CSeq 生成响应
+func NewResponse(req *Message, code int, reason string) *Message {
+	resp := &Message{StatusCode: code, Reason: reason}
+	for _, v := range req.HeaderValues("Via") {
+		resp.AddHeader("Via", v)
+	}
+	for _, name := range []string{"From", "To", "Call-ID", "CSeq"} {
+		if v := req.Header(name); v != "" {
+			resp.AddHeader(name, v)
+		}
+	}
+	return resp
+}
+
+// IsRequest 是否为请求
+func (m *Message) IsRequest() bool {
+	return m.Method != ""
+}
+
+// Header 返回头域的第一个值（大小写不敏感，支持紧凑形式）
+func (m *Message) Header(name string) string {
+	name = canonicalHeader(name)
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, name) {
+			return h.value
+		}
+	}
+	return ""
+}
+
+// HeaderValues 返回头域的全部值（按出现顺序）
+func (m *Message) HeaderValues(name string) []string {
+	name = canonicalHeader(name)
+	var values []string
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, name) {
+			values = append(values, h.value)
+		}
+	}
+	return values
+}
+
+// AddHeader 追加头域
+func (m *Message) AddHeader(name, value string) {
+	m.headers = append(m.headers, header{name: canonicalHeader(name), value: value})
+}
+
+// SetHeader 替换头域的全部值
+func (m *Message) SetHeader(name, value string) {
+	name = canonicalHeader(name)
+	out := m.headers[:0]
+	replaced := false
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, name) {
+			if !replaced {
+				out = append(out, header{name: name, value: value})
+				replaced = true
+			}
+			continue
+		}
+		out = append(out, h)
+	}
+	if !replaced {
+		out = append(out, header{name: name, value: value})
+	}
+	m.headers = out
+}
+
+// CSeq 返回 CSeq 的序号与方法
+func (m *Message) CSeq() (int, string) {
+	fields := strings.Fields(m.Header("CSeq"))
+	if len(fields) != 2 {
+		return 0, ""
+	}
+	seq, _ := strconv.Atoi(fields[0])
+	return seq, strings.ToUpper(fields[1])
+}
+
+// CallID 返回 Call-ID
+func (m *Message) CallID() string {
+	return m.Header("Call-ID")
+}
+
+// Branch 返回顶层 Via 的 branch 参数
+func (m *Message) Branch() string {
+	return headerParam(m.Header("Via"), "branch")
+}
+
+// Bytes 序列化消息（自动写入 Content-Length）
+func (m *Message) Bytes() []byte {
+	var buf bytes.Buffer
+	if m.IsRequest() {
+		fmt.Fprintf(&buf, "%s %s %s\r\n", m.Method, m.RequestURI, sipVersion)
+	} else {
+		fmt.Fprintf(&buf, "%s %d %s\r\n", sipVersion, m.StatusCode, m.Reason)
+	}
+	for _, h := range m.headers {
+		if strings.EqualFold(h.name, "Content-Length") {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.name, h.value)
+	}
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(m.Body))
+	buf.Write(m.Body)
+	return buf.Bytes()
+}
+
+// Parse 解析一个 UDP 数据报中的 SIP 消息
+func Parse(data []byte) (*Message, error) {
+	headerEnd := bytes.Index(data, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return nil, errMalformedMessage
+	}
+	lines := strings.Split(string(data[:headerEnd]), "\r\n")
+	if len(lines) == 0 {
+		return nil, errMalformedMessage
+	}
+
+	m := &Message{}
+	start := strings.SplitN(lines[0], " ", 3)
+	if len(start) != 3 {
+		return nil, errMalformedMessage
+	}
+	if start[0] == sipVersion {
+		code, err := strconv.Atoi(start[1])
+		if err != nil || code < 100 || code > 699 {
+			return nil, errMalformedMessage
+		}
+		m.StatusCode, m.Reason = code, start[2]
+	} else {
+		if start[2] != sipVersion {
+			return nil, errMalformedMessage
+		}
+		m.Method, m.RequestURI = strings.ToUpper(start[0]), start[1]
+	}
+
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(m.headers) > 0 {
+			// 折行续接上一个头域
+			last := &m.headers[len(m.headers)-1]
+			last.value += " " + strings.TrimSpace(line)
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, errMalformedMessage
+		}
+		name = canonicalHeader(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if name == "Via" || name == "Contact" {
+			// 逗号合并的多值头域拆开存储
+			for _, v := range splitHeaderValues(value) {
+				m.headers = append(m.headers, header{name: name, value: v})
+			}
+			continue
+		}
+		m.headers = append(m.headers, header{name: name, value: value})
+	}
+
+	body := data[headerEnd+4:]
+	if cl := m.Header("Content-Length"); cl != "" {
+		n, err := strconv.Atoi(cl)
+		if err != nil || n < 0 || n > len(body) {
+			return nil, errMalformedMessage
+		}
+		body = body[:n]
+	}
+	if len(body) > 0 {
+		m.Body = append([]byte(nil), body...)
+	}
+
+	if m.CallID() == "" || m.Header("CSeq") == "" {
+		return nil, errMalformedMessage
+	}
+	return m, nil
+}
+
+func canonicalHeader(name string) string {
+	if full, ok := compactHeaders[strings.ToLower(name)]; ok {
+		return full
+	}
+	switch strings.ToLower(name) {
+	case "call-id":
+		return "Call-ID"
+	case "cseq":
+		return "CSeq"
+	case "www-authenticate":
+		return "WWW-Authenticate"
+	}
+	parts := strings.Split(strings.ToLower(name), "-")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// splitHeaderValues 按不在尖括号/引号内的逗号拆分
+func splitHeaderValues(value string) []string {
+	var out []string
+	depth, quoted, start := 0, false, 0
+	for i, r := range value {
+		switch r {
+		case '"':
+			quoted = !quoted
+		case '<':
+			if !quoted {
+				depth++
+			}
+		case '>':
+			if !quoted && depth > 0 {
+				depth--
+			}
+		case ',':
+			if !quoted && depth == 0 {
+				out = append(out, strings.TrimSpace(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(out, strings.TrimSpace(value[start:]))
+}
+
+// headerParam 读取头域值中 ;name=value 形式的参数（尖括号内的 URI 参数不计）
+func headerParam(value, name string) string {
+	if idx := strings.LastIndexByte(value, '>'); idx >= 0 {
+		value = value[idx+1:]
+	}
+	for _, part := range strings.Split(value, ";")[1:] {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// addressURI 从 name-addr（"Alice" <sip:a@b>;tag=x）或 addr-spec 中取出 URI
+func addressURI(value string) string {
+	if start := strings.IndexByte(value, '<'); start >= 0 {
+		if end := strings.IndexByte(value[start:], '>'); end > 0 {
+			return value[start+1 : start+end]
+		}
+	}
+	uri, _, _ := strings.Cut(value, ";")
+	return strings.TrimSpace(uri)
+}
+
+// URIUser 返回 sip:user@host 中的 user 部分（电话号码）
+func URIUser(uri string) string {
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "sips:"), "sip:")
+	if user, _, ok := strings.Cut(uri, "@"); ok {
+		user, _, _ = strings.Cut(user, ";")
+		user, _, _ = strings.Cut(user, ":")
+		return user
+	}
+	return ""
+}
+
+// uriHostPort 返回 URI 的 host:port（缺省端口 5060）
+func uriHostPort(uri string) string {
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "sips:"), "sip:")
+	if _, host, ok := strings.Cut(uri, "@"); ok {
+		uri = host
+	}
+	uri, _, _ = strings.Cut(uri, ";")
+	uri, _, _ = strings.Cut(uri, "?")
+	if !strings.Contains(uri, ":") {
+		uri += ":5060"
+	}
+	return uri
+}
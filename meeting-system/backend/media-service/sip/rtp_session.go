@@ -0,0 +1,277 @@
+package sip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	rtpReadBufferSize = 1500
+	// dtmfEndRepeats RFC 4733 建议事件结束包重复发送 3 次
+	dtmfEndRepeats     = 3
+	dtmfPacketInterval = 20 * time.Millisecond
+	dtmfVolume         = 10
+)
+
+var errSessionClosed = errors.New("rtp session closed")
+
+// dtmfEvents RFC 4733 事件码 -> 按键
+var dtmfEvents = []rune("0123456789*#ABCD")
+
+// rtpSession 一路通话的 RTP（UDP），支持对称 RTP：以收到的第一个包的源地址为准发送
+type rtpSession struct {
+	conn *net.UDPConn
+
+	mu        sync.Mutex
+	remote    *net.UDPAddr
+	latched   bool
+	codec     Codec
+	dtmfPT    int
+	ssrc      uint32
+	seq       uint16
+	timestamp uint32
+	started   bool
+	onAudio   func(*rtp.Packet)
+
+	dtmf        chan rune
+	lastDTMF    uint32
+	hasLastDTMF bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// listenRTP 在 [portMin, portMax] 中取一个偶数端口；范围为 0 时由系统分配
+func listenRTP(ip string, portMin, portMax int) (*net.UDPConn, error) {
+	bindIP := net.ParseIP(ip)
+	if portMin <= 0 || portMax < portMin {
+		return net.ListenUDP("udp", &net.UDPAddr{IP: bindIP})
+	}
+
+	span := (portMax - portMin) / 2
+	offset := 0
+	if span > 0 {
+		offset = rand.Intn(span + 1)
+	}
+	for i := 0; i <= span; i++ {
+		port := portMin + ((offset+i)%(span+1))*2
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: bindIP, Port: port})
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, fmt.Errorf("no free rtp port in %d-%d", portMin, portMax)
+}
+
+func newRTPSession(conn *net.UDPConn) *rtpSession {
+	return &rtpSession{
+		conn:      conn,
+		dtmfPT:    -1,
+		ssrc:      rand.Uint32(),
+		seq:       uint16(rand.Uint32()),
+		timestamp: rand.Uint32(),
+		dtmf:      make(chan rune, 32),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (s *rtpSession) port() int {
+	return s.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// configure 协商完成后设置对端地址与编解码器
+func (s *rtpSession) configure(remote *net.UDPAddr, codec Codec, dtmfPT int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.latched {
+		s.remote = remote
+	}
+	s.codec = codec
+	s.dtmfPT = dtmfPT
+}
+
+func (s *rtpSession) setAudioHandler(fn func(*rtp.Packet)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAudio = fn
+}
+
+func (s *rtpSession) start() {
+	go s.readLoop()
+}
+
+func (s *rtpSession) readLoop() {
+	buf := make([]byte, rtpReadBufferSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		pkt := &rtp.Packet{}
+		if err := pkt.Unmarshal(append([]byte(nil), buf[:n]...)); err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		if !s.latched {
+			s.remote = addr
+			s.latched = true
+		}
+		dtmfPT := s.dtmfPT
+		onAudio := s.onAudio
+		s.mu.Unlock()
+
+		if dtmfPT >= 0 && int(pkt.PayloadType) == dtmfPT {
+			s.handleDTMF(pkt)
+			continue
+		}
+		if onAudio != nil {
+			onAudio(pkt)
+		}
+	}
+}
+
+// handleDTMF 每个事件（同一 RTP 时间戳）只上报一次按键
+func (s *rtpSession) handleDTMF(pkt *rtp.Packet) {
+	if len(pkt.Payload) < 4 {
+		return
+	}
+	event := int(pkt.Payload[0])
+	if event >= len(dtmfEvents) {
+		return
+	}
+
+	s.mu.Lock()
+	duplicate := s.hasLastDTMF && s.lastDTMF == pkt.Timestamp
+	s.lastDTMF, s.hasLastDTMF = pkt.Timestamp, true
+	s.mu.Unlock()
+	if duplicate {
+		return
+	}
+
+	select {
+	case s.dtmf <- dtmfEvents[event]:
+	default:
+	}
+}
+
+// writeAudio 发送一帧已编码音频，samples 为该帧在编解码器时钟下的样本数
+func (s *rtpSession) writeAudio(payload []byte, samples uint32) error {
+	s.mu.Lock()
+	remote := s.remote
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         !s.started,
+			PayloadType:    s.codec.PayloadType,
+			SequenceNumber: s.seq,
+			Timestamp:      s.timestamp,
+			SSRC:           s.ssrc,
+		},
+		Payload: payload,
+	}
+	s.started = true
+	s.seq++
+	s.timestamp += samples
+	s.mu.Unlock()
+
+	return s.send(remote, pkt)
+}
+
+// sendDTMF 按 RFC 4733 发送一个按键事件
+func (s *rtpSession) sendDTMF(digit rune, duration time.Duration) error {
+	event := -1
+	for i, d := range dtmfEvents {
+		if d == digit {
+			event = i
+			break
+		}
+	}
+	if event < 0 {
+		return fmt.Errorf("invalid dtmf digit %q", digit)
+	}
+
+	s.mu.Lock()
+	pt := s.dtmfPT
+	remote := s.remote
+	eventTS := s.timestamp
+	s.mu.Unlock()
+	if pt < 0 {
+		return errors.New("peer does not support telephone-event")
+	}
+
+	steps := int(duration / dtmfPacketInterval)
+	if steps < 1 {
+		steps = 1
+	}
+	for i := 1; i <= steps+dtmfEndRepeats-1; i++ {
+		end := i >= steps
+		step := i
+		if step > steps {
+			step = steps
+		}
+		payload := make([]byte, 4)
+		payload[0] = byte(event)
+		payload[1] = dtmfVolume
+		if end {
+			payload[1] |= 0x80
+		}
+		binary.BigEndian.PutUint16(payload[2:], uint16(step*int(dtmfPacketInterval/time.Millisecond)*8))
+
+		s.mu.Lock()
+		pkt := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         i == 1,
+				PayloadType:    uint8(pt),
+				SequenceNumber: s.seq,
+				Timestamp:      eventTS,
+				SSRC:           s.ssrc,
+			},
+			Payload: payload,
+		}
+		s.seq++
+		s.mu.Unlock()
+
+		if err := s.send(remote, pkt); err != nil {
+			return err
+		}
+		time.Sleep(dtmfPacketInterval)
+	}
+
+	s.mu.Lock()
+	s.timestamp += uint32(steps * int(dtmfPacketInterval/time.Millisecond) * 8)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *rtpSession) send(remote *net.UDPAddr, pkt *rtp.Packet) error {
+	select {
+	case <-s.closed:
+		return errSessionClosed
+	default:
+	}
+	if remote == nil {
+		return errors.New("rtp remote address unknown")
+	}
+	data, err := pkt.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.WriteToUDP(data, remote)
+	return err
+}
+
+func (s *rtpSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		_ = s.conn.Close()
+	})
+}
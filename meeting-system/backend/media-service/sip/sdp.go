@@ -0,0 +1,173 @@
+package sip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pion/sdp/v3"
+)
+
+// Codec 通话音频编解码器
+type Codec struct {
+	Name        string
+	PayloadType uint8
+	ClockRate   int
+	Channels    int
+}
+
+var (
+	CodecPCMU = Codec{Name: "PCMU", PayloadType: 0, ClockRate: 8000, Channels: 1}
+	CodecPCMA = Codec{Name: "PCMA", PayloadType: 8, ClockRate: 8000, Channels: 1}
+	CodecOpus = Codec{Name: "opus", PayloadType: 111, ClockRate: 48000, Channels: 2}
+)
+
+// telephoneEventPayloadType 本端提供的 RFC 4733 telephone-event 负载类型
+const telephoneEventPayloadType = 101
+
+var errNoCommonCodec = errors.New("no common audio codec")
+
+// mediaOffer 对端 SDP 中的音频描述
+type mediaOffer struct {
+	addr    *net.UDPAddr
+	codecs  []Codec
+	dtmfPT  int // -1 表示对端不支持 telephone-event
+	rtpmaps map[uint8]string
+}
+
+// buildSDP 生成只含一路音频的 SDP（offer 或 answer）
+func buildSDP(ip string, port int, sessionID uint64, codecs []Codec, dtmfPT int) []byte {
+	addrType := "IP4"
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		addrType = "IP6"
+	}
+
+	formats := make([]string, 0, len(codecs)+1)
+	for _, c := range codecs {
+		formats = append(formats, strconv.Itoa(int(c.PayloadType)))
+	}
+	if dtmfPT >= 0 {
+		formats = append(formats, strconv.Itoa(dtmfPT))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "v=0\r\n")
+	fmt.Fprintf(&b, "o=meeting-sip %d %d IN %s %s\r\n", sessionID, sessionID, addrType, ip)
+	fmt.Fprintf(&b, "s=meeting\r\n")
+	fmt.Fprintf(&b, "c=IN %s %s\r\n", addrType, ip)
+	fmt.Fprintf(&b, "t=0 0\r\n")
+	fmt.Fprintf(&b, "m=audio %d RTP/AVP %s\r\n", port, strings.Join(formats, " "))
+	for _, c := range codecs {
+		if c.Channels > 1 {
+			fmt.Fprintf(&b, "a=rtpmap:%d %s/%d/%d\r\n", c.PayloadType, c.Name, c.ClockRate, c.Channels)
+		} else {
+			fmt.Fprintf(&b, "a=rtpmap:%d %s/%d\r\n", c.PayloadType, c.Name, c.ClockRate)
+		}
+		if strings.EqualFold(c.Name, CodecOpus.Name) {
+			fmt.Fprintf(&b, "a=fmtp:%d useinbandfec=1\r\n", c.PayloadType)
+		}
+	}
+	if dtmfPT >= 0 {
+		fmt.Fprintf(&b, "a=rtpmap:%d telephone-event/8000\r\n", dtmfPT)
+		fmt.Fprintf(&b, "a=fmtp:%d 0-15\r\n", dtmfPT)
+	}
+	fmt.Fprintf(&b, "a=ptime:20\r\n")
+	fmt.Fprintf(&b, "a=sendrecv\r\n")
+	return []byte(b.String())
+}
+
+// parseSDP 解析对端 SDP 的第一路音频
+func parseSDP(body []byte) (*mediaOffer, error) {
+	var desc sdp.SessionDescription
+	if err := desc.Unmarshal(body); err != nil {
+		return nil, fmt.Errorf("invalid sdp: %w", err)
+	}
+
+	for _, md := range desc.MediaDescriptions {
+		if md.MediaName.Media != "audio" || md.MediaName.Port.Value == 0 {
+			continue
+		}
+
+		conn := md.ConnectionInformation
+		if conn == nil {
+			conn = desc.ConnectionInformation
+		}
+		if conn == nil || conn.Address == nil {
+			return nil, errors.New("sdp has no connection address")
+		}
+		ip := net.ParseIP(conn.Address.Address)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid sdp connection address %q", conn.Address.Address)
+		}
+
+		offer := &mediaOffer{
+			addr:    &net.UDPAddr{IP: ip, Port: md.MediaName.Port.Value},
+			dtmfPT:  -1,
+			rtpmaps: make(map[uint8]string),
+		}
+		for _, attr := range md.Attributes {
+			if attr.Key != "rtpmap" {
+				continue
+			}
+			pt, encoding, ok := strings.Cut(attr.Value, " ")
+			if !ok {
+				continue
+			}
+			if n, err := strconv.Atoi(pt); err == nil && n >= 0 && n < 128 {
+				offer.rtpmaps[uint8(n)] = encoding
+			}
+		}
+
+		for _, format := range md.MediaName.Formats {
+			n, err := strconv.Atoi(format)
+			if err != nil || n < 0 || n >= 128 {
+				continue
+			}
+			pt := uint8(n)
+			encoding, ok := offer.rtpmaps[pt]
+			if !ok {
+				// 静态负载类型可以省略 rtpmap
+				switch pt {
+				case CodecPCMU.PayloadType:
+					encoding = "PCMU/8000"
+				case CodecPCMA.PayloadType:
+					encoding = "PCMA/8000"
+				default:
+					continue
+				}
+			}
+			parts := strings.Split(encoding, "/")
+			name := parts[0]
+			if strings.EqualFold(name, "telephone-event") {
+				offer.dtmfPT = n
+				continue
+			}
+			codec := Codec{Name: name, PayloadType: pt, Channels: 1}
+			if len(parts) > 1 {
+				codec.ClockRate, _ = strconv.Atoi(parts[1])
+			}
+			if len(parts) > 2 {
+				codec.Channels, _ = strconv.Atoi(parts[2])
+			}
+			offer.codecs = append(offer.codecs, codec)
+		}
+		return offer, nil
+	}
+	return nil, errors.New("sdp has no audio media")
+}
+
+// negotiate 按本端偏好顺序选出双方都支持的编解码器，负载类型沿用对端的
+func negotiate(local []Codec, remote []Codec) (Codec, error) {
+	for _, l := range local {
+		for _, r := range remote {
+			if strings.EqualFold(l.Name, r.Name) && l.ClockRate == r.ClockRate {
+				c := l
+				c.PayloadType = r.PayloadType
+				return c, nil
+			}
+		}
+	}
+	return Codec{}, errNoCommonCodec
+}
@@ -0,0 +1,586 @@
+package sip
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"meeting-system/shared/logger"
+)
+
+const (
+	// timerT1/timerT2 RFC 3261 UDP 重传定时器
+	timerT1 = 500 * time.Millisecond
+	timerT2 = 4 * time.Second
+
+	sipReadBufferSize = 65535
+	defaultUserAgent  = "meeting-system-sip-gateway"
+)
+
+// Config SIP 用户代理配置
+type Config struct {
+	// ListenAddr SIP UDP 监听地址，如 0.0.0.0:5060
+	ListenAddr string
+	// PublicIP 写入 Via/Contact/SDP 的对外地址，为空时使用监听地址（监听全部地址时自动探测）
+	PublicIP string
+	// OutboundProxy 外呼发往的 SIP 中继（host:port），为空时直接发往被叫 URI 的主机
+	OutboundProxy string
+	// RTPPortMin/RTPPortMax 通话 RTP 端口范围，0 表示由系统分配
+	RTPPortMin int
+	RTPPortMax int
+	// Codecs 本端支持的音频编解码器，按偏好排序
+	Codecs    []Codec
+	UserAgent string
+}
+
+// StatusError 外呼收到的最终错误响应
+type StatusError struct {
+	Code   int
+	Reason string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sip: %d %s", e.Code, e.Reason)
+}
+
+// UserAgent 基于 UDP 的 SIP 用户代理：接受 INVITE 入呼叫，也可以向中继/终端外呼
+type UserAgent struct {
+	cfg  Config
+	conn *net.UDPConn
+	host string
+	port int
+
+	mu       sync.Mutex
+	calls    map[string]*Call
+	clientTx map[string]chan *Message // Via branch -> 响应
+	onInvite func(*Call)
+	// acks 外呼 Call-ID -> 已发送的 ACK（对端重传 200 OK 时重发）
+	acks map[string]*Message
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewUserAgent 绑定 SIP 端口并开始接收消息
+func NewUserAgent(cfg Config) (*UserAgent, error) {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+	if len(cfg.Codecs) == 0 {
+		cfg.Codecs = []Codec{CodecPCMU, CodecPCMA}
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", cfg.ListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sip listen address: %w", err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen sip: %w", err)
+	}
+
+	local := conn.LocalAddr().(*net.UDPAddr)
+	host := cfg.PublicIP
+	if host == "" {
+		host = advertisedIP(local.IP)
+	}
+
+	ua := &UserAgent{
+		cfg:      cfg,
+		conn:     conn,
+		host:     host,
+		port:     local.Port,
+		calls:    make(map[string]*Call),
+		clientTx: make(map[string]chan *Message),
+		acks:     make(map[string]*Message),
+		closed:   make(chan struct{}),
+	}
+	go ua.readLoop()
+	return ua, nil
+}
+
+// Addr 返回 SIP 监听地址
+func (ua *UserAgent) Addr() *net.UDPAddr {
+	return ua.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// OnInvite 设置入呼叫回调；回调在独立 goroutine 中执行，必须调用 Answer 或 Reject
+func (ua *UserAgent) OnInvite(fn func(*Call)) {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	ua.onInvite = fn
+}
+
+// Close 挂断所有呼叫并关闭端口
+func (ua *UserAgent) Close() {
+	ua.mu.Lock()
+	calls := make([]*Call, 0, len(ua.calls))
+	for _, c := range ua.calls {
+		calls = append(calls, c)
+	}
+	ua.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range calls {
+		wg.Add(1)
+		go func(c *Call) {
+			defer wg.Done()
+			c.Hangup()
+		}(c)
+	}
+	wg.Wait()
+
+	ua.closeOnce.Do(func() {
+		close(ua.closed)
+		_ = ua.conn.Close()
+	})
+}
+
+// Dial 外呼 target（如 sip:13800000000@trunk.example.com），fromUser 为主叫号码。
+// 收到 2xx 后发送 ACK 并返回已接通的呼叫；ctx 取消时发送 CANCEL。
+func (ua *UserAgent) Dial(ctx context.Context, target, fromUser string) (*Call, error) {
+	destAddr := ua.cfg.OutboundProxy
+	if destAddr == "" {
+		destAddr = uriHostPort(target)
+	}
+	dest, err := net.ResolveUDPAddr("udp", destAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", destAddr, err)
+	}
+
+	conn, err := listenRTP(ua.bindIP(), ua.cfg.RTPPortMin, ua.cfg.RTPPortMax)
+	if err != nil {
+		return nil, err
+	}
+	session := newRTPSession(conn)
+
+	c := &Call{
+		ua:           ua,
+		id:           randomHex(16) + "@" + ua.host,
+		direction:    DirectionOutbound,
+		remoteSignal: dest,
+		session:      session,
+		state:        CallStateRinging,
+		localURI:     fmt.Sprintf("<sip:%s@%s>", fromUser, ua.host),
+		remoteURI:    "<" + target + ">",
+		localTag:     randomHex(8),
+		localSeq:     1,
+		ackCh:        make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	invite := NewRequest(MethodInvite, target)
+	branch := newBranch()
+	invite.AddHeader("Via", ua.via(branch))
+	invite.AddHeader("Max-Forwards", "70")
+	invite.AddHeader("From", withTag(c.localURI, c.localTag))
+	invite.AddHeader("To", c.remoteURI)
+	invite.AddHeader("Call-ID", c.id)
+	invite.AddHeader("CSeq", "1 INVITE")
+	invite.AddHeader("Contact", ua.contact())
+	invite.AddHeader("User-Agent", ua.cfg.UserAgent)
+	invite.AddHeader("Content-Type", "application/sdp")
+	invite.Body = buildSDP(ua.mediaIP(), session.port(), ua.sessionID(), ua.cfg.Codecs, telephoneEventPayloadType)
+	c.invite = invite
+
+	ua.mu.Lock()
+	ua.calls[c.id] = c
+	ua.mu.Unlock()
+
+	resp, err := ua.transaction(ctx, invite, dest, true)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			ua.cancelInvite(invite, dest)
+		}
+		c.end("dial_failed")
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		ua.send(ackFor(invite, resp), dest)
+		c.end(fmt.Sprintf("rejected_%d", resp.StatusCode))
+		return nil, &StatusError{Code: resp.StatusCode, Reason: resp.Reason}
+	}
+
+	var codec Codec
+	answer, err := parseSDP(resp.Body)
+	if err == nil {
+		codec, err = negotiate(ua.cfg.Codecs, answer.codecs)
+		if err == nil {
+			session.configure(answer.addr, codec, answer.dtmfPT)
+		}
+	}
+
+	c.mu.Lock()
+	c.codec = codec
+	c.remoteTag = headerParam(resp.Header("To"), "tag")
+	c.remoteURI = stripTag(resp.Header("To"))
+	c.remoteTgt = addressURI(resp.Header("Contact"))
+	ack := c.ackLocked()
+	c.state = CallStateConfirmed
+	close(c.ackCh)
+	c.mu.Unlock()
+
+	ua.mu.Lock()
+	ua.acks[c.id] = ack
+	ua.mu.Unlock()
+	ua.send(ack, dest)
+
+	if err != nil {
+		// 对端接听但 SDP 不可用：按 RFC 3261 先 ACK 再 BYE
+		c.Hangup()
+		return nil, err
+	}
+
+	session.start()
+	return c, nil
+}
+
+// ackLocked 2xx 的 ACK 是对话内的新事务（调用方持有 c.mu）
+func (c *Call) ackLocked() *Message {
+	target := c.remoteTgt
+	if target == "" {
+		target = c.invite.RequestURI
+	}
+	ack := NewRequest(MethodAck, target)
+	ack.AddHeader("Via", c.ua.via(newBranch()))
+	ack.AddHeader("Max-Forwards", "70")
+	ack.AddHeader("From", withTag(c.localURI, c.localTag))
+	ack.AddHeader("To", withTag(c.remoteURI, c.remoteTag))
+	ack.AddHeader("Call-ID", c.id)
+	ack.AddHeader("CSeq", "1 ACK")
+	return ack
+}
+
+// ackFor 非 2xx 最终响应的 ACK 属于 INVITE 事务本身（同一 branch）
+func ackFor(invite, resp *Message) *Message {
+	ack := NewRequest(MethodAck, invite.RequestURI)
+	ack.AddHeader("Via", invite.Header("Via"))
+	ack.AddHeader("Max-Forwards", "70")
+	ack.AddHeader("From", invite.Header("From"))
+	ack.AddHeader("To", resp.Header("To"))
+	ack.AddHeader("Call-ID", invite.CallID())
+	seq, _ := invite.CSeq()
+	ack.AddHeader("CSeq", fmt.Sprintf("%d ACK", seq))
+	return ack
+}
+
+func (ua *UserAgent) cancelInvite(invite *Message, dest *net.UDPAddr) {
+	cancel := NewRequest(MethodCancel, invite.RequestURI)
+	cancel.AddHeader("Via", invite.Header("Via"))
+	cancel.AddHeader("Max-Forwards", "70")
+	cancel.AddHeader("From", invite.Header("From"))
+	cancel.AddHeader("To", invite.Header("To"))
+	cancel.AddHeader("Call-ID", invite.CallID())
+	seq, _ := invite.CSeq()
+	cancel.AddHeader("CSeq", fmt.Sprintf("%d CANCEL", seq))
+	ua.send(cancel, dest)
+}
+
+// roundTrip 非 INVITE 请求的客户端事务
+func (ua *UserAgent) roundTrip(ctx context.Context, req *Message, dest *net.UDPAddr) (*Message, error) {
+	return ua.transaction(ctx, req, dest, false)
+}
+
+// transaction 发送请求并按 T1 指数退避重传，直到收到最终响应；INVITE 收到临时响应后停止重传
+func (ua *UserAgent) transaction(ctx context.Context, req *Message, dest *net.UDPAddr, invite bool) (*Message, error) {
+	branch := req.Branch()
+	responses := make(chan *Message, 8)
+	ua.mu.Lock()
+	ua.clientTx[branch] = responses
+	ua.mu.Unlock()
+	defer func() {
+		ua.mu.Lock()
+		delete(ua.clientTx, branch)
+		ua.mu.Unlock()
+	}()
+
+	ua.send(req, dest)
+	interval := timerT1
+	retransmit := time.NewTimer(interval)
+	defer retransmit.Stop()
+	deadline := time.NewTimer(64 * timerT1)
+	defer deadline.Stop()
+	provisional := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ua.closed:
+			return nil, errors.New("user agent closed")
+		case <-deadline.C:
+			if !provisional {
+				return nil, &StatusError{Code: 408, Reason: "Request Timeout"}
+			}
+		case <-retransmit.C:
+			if invite && provisional {
+				continue
+			}
+			ua.send(req, dest)
+			interval *= 2
+			if !invite && interval > timerT2 {
+				interval = timerT2
+			}
+			retransmit.Reset(interval)
+		case resp := <-responses:
+			if resp.StatusCode < 200 {
+				provisional = true
+				continue
+			}
+			return resp, nil
+		}
+	}
+}
+
+func (ua *UserAgent) readLoop() {
+	buf := make([]byte, sipReadBufferSize)
+	for {
+		n, addr, err := ua.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-ua.closed:
+				return
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		if n <= 4 {
+			// 保活包（CRLF）
+			continue
+		}
+
+		msg, err := Parse(buf[:n])
+		if err != nil {
+			logger.Debug(fmt.Sprintf("Dropped malformed SIP message from %s: %v", addr, err))
+			continue
+		}
+		if msg.IsRequest() {
+			ua.handleRequest(msg, addr)
+		} else {
+			ua.handleResponse(msg)
+		}
+	}
+}
+
+func (ua *UserAgent) handleResponse(resp *Message) {
+	ua.mu.Lock()
+	tx := ua.clientTx[resp.Branch()]
+	ack := ua.acks[resp.CallID()]
+	call := ua.calls[resp.CallID()]
+	ua.mu.Unlock()
+
+	if tx != nil {
+		select {
+		case tx <- resp:
+		default:
+		}
+		return
+	}
+
+	// INVITE 事务结束后对端仍在重传 200 OK：重发 ACK
+	if _, method := resp.CSeq(); method == MethodInvite && resp.StatusCode >= 200 && resp.StatusCode < 300 && ack != nil && call != nil {
+		ua.send(ack, call.remoteSignal)
+	}
+}
+
+func (ua *UserAgent) handleRequest(req *Message, addr *net.UDPAddr) {
+	ua.mu.Lock()
+	call := ua.calls[req.CallID()]
+	ua.mu.Unlock()
+
+	switch req.Method {
+	case MethodInvite:
+		if call != nil {
+			ua.handleReinvite(call, req, addr)
+			return
+		}
+		ua.handleInvite(req, addr)
+	case MethodAck:
+		if call != nil {
+			call.confirm()
+		}
+	case MethodBye:
+		if call == nil {
+			ua.send(NewResponse(req, 481, "Call/Transaction Does Not Exist"), addr)
+			return
+		}
+		ua.send(NewResponse(req, 200, "OK"), addr)
+		call.end("remote_hangup")
+	case MethodCancel:
+		if call == nil {
+			ua.send(NewResponse(req, 481, "Call/Transaction Does Not Exist"), addr)
+			return
+		}
+		ua.send(NewResponse(req, 200, "OK"), addr)
+		call.mu.Lock()
+		ringing := call.state == CallStateRinging
+		var resp *Message
+		if ringing {
+			resp = call.responseLocked(487, "Request Terminated")
+			call.lastResp = resp
+		}
+		call.mu.Unlock()
+		if ringing {
+			ua.send(resp, call.remoteSignal)
+			call.end("cancelled")
+		}
+	case MethodOptions:
+		resp := NewResponse(req, 200, "OK")
+		resp.AddHeader("Allow", "INVITE, ACK, BYE, CANCEL, OPTIONS")
+		resp.AddHeader("Accept", "application/sdp")
+		ua.send(resp, addr)
+	default:
+		ua.send(NewResponse(req, 501, "Not Implemented"), addr)
+	}
+}
+
+func (ua *UserAgent) handleInvite(req *Message, addr *net.UDPAddr) {
+	offer, err := parseSDP(req.Body)
+	if err != nil {
+		ua.send(NewResponse(req, 400, "Bad Request"), addr)
+		return
+	}
+	codec, err := negotiate(ua.cfg.Codecs, offer.codecs)
+	if err != nil {
+		resp := NewResponse(req, 488, "Not Acceptable Here")
+		ua.send(resp, addr)
+		return
+	}
+	conn, err := listenRTP(ua.bindIP(), ua.cfg.RTPPortMin, ua.cfg.RTPPortMax)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("SIP call %s rejected: %v", req.CallID(), err))
+		ua.send(NewResponse(req, 503, "Service Unavailable"), addr)
+		return
+	}
+
+	session := newRTPSession(conn)
+	session.configure(offer.addr, codec, offer.dtmfPT)
+
+	c := &Call{
+		ua:           ua,
+		id:           req.CallID(),
+		direction:    DirectionInbound,
+		remoteSignal: addr,
+		session:      session,
+		state:        CallStateRinging,
+		localURI:     stripTag(req.Header("To")),
+		remoteURI:    stripTag(req.Header("From")),
+		localTag:     randomHex(8),
+		remoteTag:    headerParam(req.Header("From"), "tag"),
+		remoteTgt:    addressURI(req.Header("Contact")),
+		invite:       req,
+		codec:        codec,
+		ackCh:        make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	trying := NewResponse(req, 100, "Trying")
+	c.lastResp = trying
+
+	ua.mu.Lock()
+	ua.calls[c.id] = c
+	onInvite := ua.onInvite
+	ua.mu.Unlock()
+
+	ua.send(trying, addr)
+	session.start()
+
+	if onInvite == nil {
+		c.Reject(480, "Temporarily Unavailable")
+		return
+	}
+	go onInvite(c)
+}
+
+// handleReinvite 处理 INVITE 重传与对话内 re-INVITE（保持会话参数不变，只回当前 SDP）
+func (ua *UserAgent) handleReinvite(c *Call, req *Message, addr *net.UDPAddr) {
+	seq, _ := req.CSeq()
+	c.mu.Lock()
+	inviteSeq, _ := c.invite.CSeq()
+	last := c.lastResp
+	direction := c.direction
+	c.mu.Unlock()
+
+	if direction == DirectionInbound && seq == inviteSeq {
+		if last != nil {
+			ua.send(last, addr)
+		}
+		return
+	}
+
+	resp := NewResponse(req, 200, "OK")
+	resp.AddHeader("Contact", ua.contact())
+	resp.AddHeader("Content-Type", "application/sdp")
+	codec := c.Codec()
+	resp.Body = buildSDP(ua.mediaIP(), c.session.port(), ua.sessionID(), []Codec{codec}, c.dtmfAnswerPT())
+	ua.send(resp, addr)
+}
+
+func (ua *UserAgent) removeCall(id string) {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	delete(ua.calls, id)
+	delete(ua.acks, id)
+}
+
+func (ua *UserAgent) send(msg *Message, dest *net.UDPAddr) {
+	if dest == nil {
+		return
+	}
+	if _, err := ua.conn.WriteToUDP(msg.Bytes(), dest); err != nil {
+		logger.Debug(fmt.Sprintf("Failed to send SIP message to %s: %v", dest, err))
+	}
+}
+
+func (ua *UserAgent) via(branch string) string {
+	return fmt.Sprintf("SIP/2.0/UDP %s;branch=%s;rport", net.JoinHostPort(ua.host, strconv.Itoa(ua.port)), branch)
+}
+
+func (ua *UserAgent) contact() string {
+	return fmt.Sprintf("<sip:gateway@%s>", net.JoinHostPort(ua.host, strconv.Itoa(ua.port)))
+}
+
+func (ua *UserAgent) mediaIP() string {
+	return ua.host
+}
+
+// bindIP RTP 与 SIP 绑定同一个本地地址
+func (ua *UserAgent) bindIP() string {
+	return ua.Addr().IP.String()
+}
+
+func (ua *UserAgent) sessionID() uint64 {
+	return uint64(time.Now().UnixNano() / int64(time.Millisecond))
+}
+
+// advertisedIP 监听全部地址时，取默认路由出口的地址作为对外地址
+func advertisedIP(ip net.IP) string {
+	if ip != nil && !ip.IsUnspecified() {
+		return ip.String()
+	}
+	conn, err := net.Dial("udp", "192.0.2.1:9")
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
+
+func newBranch() string {
+	return branchMagicCookie + randomHex(8)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,170 @@
+package sip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUA(t *testing.T, codecs ...Codec) *UserAgent {
+	t.Helper()
+	ua, err := NewUserAgent(Config{ListenAddr: "127.0.0.1:0", Codecs: codecs})
+	require.NoError(t, err)
+	t.Cleanup(ua.Close)
+	return ua
+}
+
+func waitDone(t *testing.T, c *Call) {
+	t.Helper()
+	select {
+	case <-c.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("call did not end")
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	raw := "INVITE sip:1000@gw.example.com SIP/2.0\r\n" +
+		"v: SIP/2.0/UDP 10.0.0.1:5060;branch=z9hG4bKabc;rport, SIP/2.0/UDP 10.0.0.2:5060;branch=z9hG4bKdef\r\n" +
+		"f: \"Alice\" <sip:13800000000@10.0.0.1>;tag=123\r\n" +
+		"t: <sip:1000@gw.example.com>\r\n" +
+		"i: call-1@10.0.0.1\r\n" +
+		"CSeq: 7 INVITE\r\n" +
+		"Subject: multi\r\n line\r\n" +
+		"l: 4\r\n\r\nbodyEXTRA"
+
+	msg, err := Parse([]byte(raw))
+	require.NoError(t, err)
+	assert.True(t, msg.IsRequest())
+	assert.Equal(t, MethodInvite, msg.Method)
+	assert.Equal(t, "call-1@10.0.0.1", msg.CallID())
+	assert.Len(t, msg.HeaderValues("Via"), 2)
+	assert.Equal(t, "z9hG4bKabc", msg.Branch())
+	assert.Equal(t, "123", headerParam(msg.Header("From"), "tag"))
+	assert.Equal(t, "13800000000", URIUser(addressURI(msg.Header("From"))))
+	assert.Equal(t, "multi line", msg.Header("subject"))
+	seq, method := msg.CSeq()
+	assert.Equal(t, 7, seq)
+	assert.Equal(t, MethodInvite, method)
+	assert.Equal(t, []byte("body"), msg.Body)
+
+	resp := NewResponse(msg, 486, "Busy Here")
+	parsed, err := Parse(resp.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 486, parsed.StatusCode)
+	assert.Len(t, parsed.HeaderValues("Via"), 2)
+	assert.Equal(t, msg.Header("From"), parsed.Header("From"))
+
+	_, err = Parse([]byte("garbage\r\n\r\n"))
+	assert.Error(t, err)
+}
+
+func TestG711RoundTrip(t *testing.T) {
+	samples := []int16{0, 100, -100, 1000, -1000, 12000, -12000, 32767, -32768}
+	ulaw := MulawDecode(nil, MulawEncode(nil, samples))
+	alaw := AlawDecode(nil, AlawEncode(nil, samples))
+	for i, s := range samples {
+		// G.711 对数量化误差约为幅度的 1/16
+		tolerance := float64(abs(int(s)))/16 + 16
+		assert.InDelta(t, float64(s), float64(ulaw[i]), tolerance, "μ-law %d", s)
+		assert.InDelta(t, float64(s), float64(alaw[i]), tolerance, "A-law %d", s)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// TestCallLifecycle 本地两个用户代理完成 INVITE/200/ACK、DTMF、RTP 音频与 BYE
+func TestCallLifecycle(t *testing.T) {
+	callee := newTestUA(t, CodecPCMA, CodecPCMU)
+	caller := newTestUA(t, CodecPCMU)
+
+	inbound := make(chan *Call, 1)
+	callee.OnInvite(func(c *Call) {
+		require.NoError(t, c.Answer())
+		inbound <- c
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	out, err := caller.Dial(ctx, "sip:1000@"+callee.Addr().String(), "13800000000")
+	require.NoError(t, err)
+	assert.Equal(t, CallStateConfirmed, out.State())
+	assert.Equal(t, "PCMU", out.Codec().Name)
+
+	var in *Call
+	select {
+	case in = <-inbound:
+	case <-time.After(time.Second):
+		t.Fatal("callee did not receive INVITE")
+	}
+	assert.Equal(t, DirectionInbound, in.Direction())
+	assert.Equal(t, "13800000000", in.RemoteUser())
+	assert.Equal(t, "1000", in.LocalUser())
+	assert.Equal(t, "PCMU", in.Codec().Name, "按对端 offer 协商")
+	require.Eventually(t, func() bool { return in.State() == CallStateConfirmed }, time.Second, 10*time.Millisecond)
+
+	audio := make(chan *rtp.Packet, 4)
+	in.OnAudio(func(pkt *rtp.Packet) { audio <- pkt })
+	require.NoError(t, out.WriteAudio(MulawEncode(nil, make([]int16, 160)), 160))
+	select {
+	case pkt := <-audio:
+		assert.Equal(t, uint8(0), pkt.PayloadType)
+		assert.Len(t, pkt.Payload, 160)
+	case <-time.After(time.Second):
+		t.Fatal("no audio received")
+	}
+
+	for _, d := range "12#" {
+		require.NoError(t, out.SendDTMF(d))
+	}
+	var digits []rune
+	for len(digits) < 3 {
+		select {
+		case d := <-in.DTMF():
+			digits = append(digits, d)
+		case <-time.After(time.Second):
+			t.Fatalf("dtmf missing, got %q", string(digits))
+		}
+	}
+	assert.Equal(t, "12#", string(digits))
+
+	out.Hangup()
+	waitDone(t, in)
+	assert.Equal(t, "remote_hangup", in.EndReason())
+	assert.Equal(t, CallStateEnded, out.State())
+}
+
+func TestDialRejected(t *testing.T) {
+	callee := newTestUA(t)
+	caller := newTestUA(t)
+	callee.OnInvite(func(c *Call) { c.Reject(486, "Busy Here") })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := caller.Dial(ctx, "sip:1000@"+callee.Addr().String(), "gateway")
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 486, statusErr.Code)
+}
+
+func TestInviteWithoutCommonCodec(t *testing.T) {
+	callee := newTestUA(t, CodecPCMA)
+	caller := newTestUA(t, CodecPCMU)
+	callee.OnInvite(func(c *Call) { _ = c.Answer() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := caller.Dial(ctx, "sip:1000@"+callee.Addr().String(), "gateway")
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 488, statusErr.Code)
+}
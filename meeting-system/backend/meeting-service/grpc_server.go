@@ -117,6 +117,20 @@ func (s *MeetingGRPCServer) ValidateUserAccess(ctx context.Context, req *pb.Vali
 		}, nil
 	}
 
+	// 被主办人拒绝的用户不能连接；等候中的用户允许连接，由信令服务置于等候室
+	if participant.Status == models.ParticipantStatusRejected {
+		return &pb.ValidateUserAccessResponse{
+			HasAccess: false,
+			Error:     "admission denied",
+		}, nil
+	}
+	if participant.Status == models.ParticipantStatusBanned {
+		return &pb.ValidateUserAccessResponse{
+			HasAccess: false,
+			Error:     "participant banned",
+		}, nil
+	}
+
 	response := &pb.ValidateUserAccessResponse{
 		HasAccess: true,
 		Role:      participant.Role.String(),
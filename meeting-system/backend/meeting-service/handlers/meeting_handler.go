@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -51,6 +55,11 @@ func (h *MeetingHandler) CreateMeeting(c *gin.Context) {
 		return
 	}
 
+	if err := req.Settings.Codecs.Validate(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
 	meeting, err := h.meetingService.CreateMeeting(&req)
 	if err != nil {
 		logger.Error("Failed to create meeting", logger.Err(err))
@@ -107,6 +116,13 @@ func (h *MeetingHandler) UpdateMeeting(c *gin.Context) {
 		return
 	}
 
+	if req.Settings != nil {
+		if err := req.Settings.Codecs.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	userID, _ := c.Get("user_id")
 
 	meeting, err := h.meetingService.UpdateMeeting(uint(meetingID), userID.(uint), &req)
@@ -177,6 +193,22 @@ func (h *MeetingHandler) JoinMeeting(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
 			return
 		}
+		if err.Error() == "admission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admission denied by host"})
+			return
+		}
+		if err.Error() == "participant banned" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You have been removed from this meeting"})
+			return
+		}
+		if err.Error() == "meeting is locked" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Meeting is locked"})
+			return
+		}
+		if err.Error() == "breakout not assigned" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not assigned to this breakout room"})
+			return
+		}
 		if err.Error() == "record not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Meeting not found"})
 			return
@@ -186,8 +218,12 @@ func (h *MeetingHandler) JoinMeeting(c *gin.Context) {
 		return
 	}
 
+	message := "Joined meeting successfully"
+	if response.Waiting {
+		message = "Waiting for host approval"
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Joined meeting successfully",
+		"message": message,
 		"data":    response,
 	})
 }
@@ -240,6 +276,111 @@ func (h *MeetingHandler) GetParticipants(c *gin.Context) {
 	})
 }
 
+// GetLobby 获取等候室中的用户
+func (h *MeetingHandler) GetLobby(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	participants, err := h.meetingService.GetLobby(uint(meetingID), userID.(uint))
+	if err != nil {
+		if err.Error() == "access denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		logger.Error("Failed to get lobby", logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get lobby"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": participants,
+	})
+}
+
+// AdmitParticipant 准入等候室中的用户
+func (h *MeetingHandler) AdmitParticipant(c *gin.Context) {
+	h.decideAdmission(c, true)
+}
+
+// DenyParticipant 拒绝等候室中的用户
+func (h *MeetingHandler) DenyParticipant(c *gin.Context) {
+	h.decideAdmission(c, false)
+}
+
+func (h *MeetingHandler) decideAdmission(c *gin.Context, admit bool) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+	targetID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if admit {
+		err = h.meetingService.AdmitParticipant(uint(meetingID), userID.(uint), uint(targetID))
+	} else {
+		err = h.meetingService.DenyParticipant(uint(meetingID), userID.(uint), uint(targetID))
+	}
+	if err != nil {
+		if err.Error() == "access denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		if err.Error() == "participant not waiting" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Participant is not waiting"})
+			return
+		}
+		logger.Error("Failed to update lobby admission", logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lobby admission"})
+		return
+	}
+
+	message := "Participant denied"
+	if admit {
+		message = "Participant admitted"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
+
+// AdmitAllParticipants 准入等候室中的所有用户
+func (h *MeetingHandler) AdmitAllParticipants(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	admitted, err := h.meetingService.AdmitAllParticipants(uint(meetingID), userID.(uint))
+	if err != nil {
+		if err.Error() == "access denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		logger.Error("Failed to admit lobby", logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to admit lobby"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Participants admitted",
+		"data":    admitted,
+	})
+}
+
 // ListMeetings 获取会议列表
 func (h *MeetingHandler) ListMeetings(c *gin.Context) {
 	var req models.MeetingListRequest
@@ -295,22 +436,411 @@ func (h *MeetingHandler) AddParticipant(c *gin.Context) {
 	})
 }
 
-// RemoveParticipant 移除参与者
+// RemoveParticipant 移除参与者（?ban=true 时封禁，禁止再次加入）
 func (h *MeetingHandler) RemoveParticipant(c *gin.Context) {
-	// TODO: 实现移除参与者逻辑
+	meetingID, targetID, ok := parseParticipantPath(c)
+	if !ok {
+		return
+	}
+	ban, _ := strconv.ParseBool(c.DefaultQuery("ban", "false"))
+
+	err := h.meetingService.RemoveParticipant(meetingID, operatorFrom(c), targetID, ban)
+	if err != nil {
+		respondHostControlError(c, err, "Failed to remove participant")
+		return
+	}
+
+	message := "Participant removed"
+	if ban {
+		message = "Participant banned"
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Remove participant - TODO",
+		"message": message,
 	})
 }
 
 // UpdateParticipantRole 更新参与者角色
 func (h *MeetingHandler) UpdateParticipantRole(c *gin.Context) {
-	// TODO: 实现更新参与者角色逻辑
+	meetingID, targetID, ok := parseParticipantPath(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Role models.ParticipantRole `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.meetingService.UpdateParticipantRole(meetingID, operatorFrom(c), targetID, req.Role)
+	if err != nil {
+		respondHostControlError(c, err, "Failed to update participant role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Participant role updated",
+		"data":    gin.H{"user_id": targetID, "role": req.Role},
+	})
+}
+
+// MuteAll 全员静音
+func (h *MeetingHandler) MuteAll(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req struct {
+		AllowSelfUnmute bool `json:"allow_self_unmute"`
+	}
+	c.ShouldBindJSON(&req)
+
+	if err := h.meetingService.MuteAll(uint(meetingID), operatorFrom(c), req.AllowSelfUnmute); err != nil {
+		respondHostControlError(c, err, "Failed to mute participants")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All participants muted",
+	})
+}
+
+// LockMeeting 锁定会议，禁止新用户加入
+func (h *MeetingHandler) LockMeeting(c *gin.Context) {
+	h.setMeetingLocked(c, true)
+}
+
+// UnlockMeeting 解锁会议
+func (h *MeetingHandler) UnlockMeeting(c *gin.Context) {
+	h.setMeetingLocked(c, false)
+}
+
+func (h *MeetingHandler) setMeetingLocked(c *gin.Context, locked bool) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	if err := h.meetingService.SetMeetingLocked(uint(meetingID), operatorFrom(c), locked); err != nil {
+		respondHostControlError(c, err, "Failed to update meeting lock")
+		return
+	}
+
+	message := "Meeting unlocked"
+	if locked {
+		message = "Meeting locked"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
+
+// ListBreakouts 获取会议当前的分组讨论房间
+func (h *MeetingHandler) ListBreakouts(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	breakouts, err := h.meetingService.ListBreakouts(uint(meetingID), userID.(uint))
+	if err != nil {
+		respondBreakoutError(c, err, "Failed to get breakouts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": breakouts,
+	})
+}
+
+// CreateBreakouts 创建分组讨论房间
+func (h *MeetingHandler) CreateBreakouts(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req models.CreateBreakoutsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	breakouts, err := h.meetingService.CreateBreakouts(uint(meetingID), operatorFrom(c), &req)
+	if err != nil {
+		respondBreakoutError(c, err, "Failed to create breakouts")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Breakouts created",
+		"data":    breakouts,
+	})
+}
+
+// AssignBreakouts 手动或随机分配分组
+func (h *MeetingHandler) AssignBreakouts(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req models.AssignBreakoutsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	breakouts, err := h.meetingService.AssignBreakouts(uint(meetingID), operatorFrom(c), &req)
+	if err != nil {
+		respondBreakoutError(c, err, "Failed to assign breakouts")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Update participant role - TODO",
+		"message": "Breakouts assigned",
+		"data":    breakouts,
 	})
 }
 
+// OpenBreakouts 开启分组，可设置自动关闭的时长
+func (h *MeetingHandler) OpenBreakouts(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req models.OpenBreakoutsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	breakouts, err := h.meetingService.OpenBreakouts(uint(meetingID), operatorFrom(c), time.Duration(req.DurationMinutes)*time.Minute)
+	if err != nil {
+		respondBreakoutError(c, err, "Failed to open breakouts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Breakouts opened",
+		"data":    breakouts,
+	})
+}
+
+// BroadcastToBreakouts 向所有分组广播消息
+func (h *MeetingHandler) BroadcastToBreakouts(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req models.BreakoutBroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.meetingService.BroadcastToBreakouts(uint(meetingID), operatorFrom(c), req.Message); err != nil {
+		respondBreakoutError(c, err, "Failed to broadcast to breakouts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message broadcast to breakouts",
+	})
+}
+
+// CloseBreakouts 关闭所有分组，参与者回到主会议
+func (h *MeetingHandler) CloseBreakouts(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	if err := h.meetingService.CloseBreakouts(uint(meetingID), operatorFrom(c)); err != nil {
+		respondBreakoutError(c, err, "Failed to close breakouts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Breakouts closed",
+	})
+}
+
+// respondBreakoutError 把分组讨论的业务错误映射为 HTTP 状态码
+func respondBreakoutError(c *gin.Context, err error, message string) {
+	msg := err.Error()
+	switch {
+	case msg == "access denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case msg == "record not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meeting not found"})
+	case msg == "no breakouts":
+		c.JSON(http.StatusNotFound, gin.H{"error": "No breakouts"})
+	case msg == "breakouts already exist", msg == "breakouts already open", msg == "breakouts not open":
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case msg == "cannot nest breakouts", msg == "meeting is not active", msg == "no assignments",
+		strings.HasPrefix(msg, "invalid breakout"), strings.HasPrefix(msg, "user "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	default:
+		logger.Error(message, logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// ListPolls 获取会议投票及结果
+func (h *MeetingHandler) ListPolls(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	polls, err := h.meetingService.ListPolls(uint(meetingID), userID.(uint))
+	if err != nil {
+		respondPollError(c, err, "Failed to get polls")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": polls,
+	})
+}
+
+// ExportPolls 导出投票结果 CSV
+func (h *MeetingHandler) ExportPolls(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var buf bytes.Buffer
+	if err := h.meetingService.ExportPollsCSV(uint(meetingID), userID.(uint), &buf); err != nil {
+		respondPollError(c, err, "Failed to export polls")
+		return
+	}
+
+	filename := fmt.Sprintf("meeting_%d_polls.csv", meetingID)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ListQuestions 获取会议问答
+func (h *MeetingHandler) ListQuestions(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	questions, err := h.meetingService.ListQuestions(uint(meetingID), userID.(uint))
+	if err != nil {
+		respondPollError(c, err, "Failed to get questions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": questions,
+	})
+}
+
+// ExportQuestions 导出问答 CSV
+func (h *MeetingHandler) ExportQuestions(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var buf bytes.Buffer
+	if err := h.meetingService.ExportQuestionsCSV(uint(meetingID), userID.(uint), &buf); err != nil {
+		respondPollError(c, err, "Failed to export questions")
+		return
+	}
+
+	filename := fmt.Sprintf("meeting_%d_questions.csv", meetingID)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// respondPollError 把投票与问答查询的业务错误映射为 HTTP 状态码
+func respondPollError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "access denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case "record not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meeting not found"})
+	default:
+		logger.Error(message, logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// parseParticipantPath 解析 /:id/participants/:user_id 路径参数
+func parseParticipantPath(c *gin.Context) (uint, uint, bool) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return 0, 0, false
+	}
+	targetID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, 0, false
+	}
+	return uint(meetingID), uint(targetID), true
+}
+
+// operatorFrom 从请求上下文提取操作者信息（用于审计）
+func operatorFrom(c *gin.Context) services.Operator {
+	userID, _ := c.Get("user_id")
+	return services.Operator{
+		UserID:    userID.(uint),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// respondHostControlError 把主持控制的业务错误映射为 HTTP 状态码
+func respondHostControlError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "access denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case "cannot control yourself", "cannot control the host":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot perform this action on the target participant"})
+	case "invalid role":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+	case "record not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Participant not found"})
+	default:
+		logger.Error(message, logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // GetMeetingRoom 获取会议室信息
 func (h *MeetingHandler) GetMeetingRoom(c *gin.Context) {
 	// TODO: 实现获取会议室信息逻辑
@@ -360,23 +890,132 @@ func (h *MeetingHandler) GetRecordings(c *gin.Context) {
 	})
 }
 
-// GetChatMessages 获取聊天消息
+// GetChatMessages 分页获取聊天历史（cursor 取自上一页的 next_cursor，thread_id 查询话题回复）
 func (h *MeetingHandler) GetChatMessages(c *gin.Context) {
-	// TODO: 实现获取聊天消息逻辑
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	history, err := h.meetingService.ListChatMessages(uint(meetingID), userID.(uint), c.Query("cursor"), limit, c.Query("thread_id"))
+	if err != nil {
+		respondChatError(c, err, "Failed to get chat messages")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Get chat messages - TODO",
-		"data":    []interface{}{},
+		"data":        history.Messages,
+		"next_cursor": history.NextCursor,
 	})
 }
 
-// SendChatMessage 发送聊天消息
+// SendChatMessage 发送聊天消息（to_user_id 私聊，reply_to_id 回复话题）
 func (h *MeetingHandler) SendChatMessage(c *gin.Context) {
-	// TODO: 实现发送聊天消息逻辑
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req models.ChatMessage
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	message, err := h.meetingService.SendChatMessage(uint(meetingID), userID.(uint), c.GetString("username"), req)
+	if err != nil {
+		respondChatError(c, err, "Failed to send chat message")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Chat message sent",
+		"data":    message,
+	})
+}
+
+// EditChatMessage 编辑自己发送的聊天消息
+func (h *MeetingHandler) EditChatMessage(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	var req models.ChatMessage
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	message, err := h.meetingService.EditChatMessage(uint(meetingID), userID.(uint), c.Param("message_id"), req.Content)
+	if err != nil {
+		respondChatError(c, err, "Failed to edit chat message")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Chat message edited",
+		"data":    message,
+	})
+}
+
+// DeleteChatMessage 删除聊天消息（作者，或主办人/主持人删除公开消息）
+func (h *MeetingHandler) DeleteChatMessage(c *gin.Context) {
+	meetingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	message, err := h.meetingService.DeleteChatMessage(uint(meetingID), userID.(uint), c.Param("message_id"))
+	if err != nil {
+		respondChatError(c, err, "Failed to delete chat message")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Send chat message - TODO",
+		"message": "Chat message deleted",
+		"data":    message,
 	})
 }
 
+// respondChatError 把聊天的业务错误映射为 HTTP 状态码
+func respondChatError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "access denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, models.ErrChatNotAuthor), errors.Is(err, models.ErrChatDeleteDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrChatMessageNotFound), err.Error() == "recipient is not in the meeting":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrChatDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrChatContentRequired), errors.Is(err, models.ErrChatContentTooLong),
+		errors.Is(err, models.ErrChatSelfMessage), errors.Is(err, models.ErrChatPublicReply),
+		errors.Is(err, models.ErrChatReplyRecipient), errors.Is(err, models.ErrChatInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(message, logger.Err(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // GetMyMeetings 获取我的会议
 func (h *MeetingHandler) GetMyMeetings(c *gin.Context) {
 	// TODO: 实现获取我的会议逻辑
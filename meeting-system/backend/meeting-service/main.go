@@ -132,9 +132,17 @@ func main() {
 		registerMeetingTaskHandlers(queueManager)
 	}
 
-	// 跳过MongoDB初始化（可选功能）
-	logger.Info("Skipping MongoDB initialization (optional feature)")
-	log.Println("Skipping MongoDB initialization (optional feature)")
+	// MongoDB 仅在聊天存储配置为 mongodb 时需要
+	if cfg.Chat.Store == config.ChatStoreMongoDB {
+		logger.Info("Initializing MongoDB for chat storage...")
+		if err := database.InitMongoDB(cfg.MongoDB); err != nil {
+			logger.Fatal("Failed to initialize MongoDB for chat storage: " + err.Error())
+		}
+		defer database.CloseMongoDB()
+	} else {
+		logger.Info("Skipping MongoDB initialization (optional feature)")
+		log.Println("Skipping MongoDB initialization (optional feature)")
+	}
 
 	// 跳过自动迁移（表已存在）
 	logger.Info("Skipping database migration (tables already exist)")
@@ -158,6 +166,16 @@ func main() {
 
 	// 初始化服务
 	meetingService := services.NewMeetingService()
+	chatStore, err := database.NewChatStore(cfg.Chat)
+	if err != nil {
+		logger.Fatal("Failed to initialize chat store: " + err.Error())
+	}
+	meetingService.SetChatStore(chatStore)
+	if queueManager != nil {
+		if pubsub := queueManager.GetKafkaEventBus(); pubsub != nil {
+			meetingService.SetEventPublisher(pubsub)
+		}
+	}
 	meetingHandler := handlers.NewMeetingHandler(meetingService)
 
 	// 注册路由
@@ -329,6 +347,31 @@ func setupRoutes(r *gin.Engine, meetingHandler *handlers.MeetingHandler) {
 			meetings.DELETE("/:id/participants/:user_id", meetingHandler.RemoveParticipant)
 			meetings.PUT("/:id/participants/:user_id/role", meetingHandler.UpdateParticipantRole)
 
+			// 等候室
+			meetings.GET("/:id/lobby", meetingHandler.GetLobby)
+			meetings.POST("/:id/lobby/admit-all", meetingHandler.AdmitAllParticipants)
+			meetings.POST("/:id/lobby/:user_id/admit", meetingHandler.AdmitParticipant)
+			meetings.POST("/:id/lobby/:user_id/deny", meetingHandler.DenyParticipant)
+
+			// 主持控制
+			meetings.POST("/:id/mute-all", meetingHandler.MuteAll)
+			meetings.POST("/:id/lock", meetingHandler.LockMeeting)
+			meetings.POST("/:id/unlock", meetingHandler.UnlockMeeting)
+
+			// 分组讨论
+			meetings.GET("/:id/breakouts", meetingHandler.ListBreakouts)
+			meetings.POST("/:id/breakouts", meetingHandler.CreateBreakouts)
+			meetings.PUT("/:id/breakouts/assignments", meetingHandler.AssignBreakouts)
+			meetings.POST("/:id/breakouts/open", meetingHandler.OpenBreakouts)
+			meetings.POST("/:id/breakouts/broadcast", meetingHandler.BroadcastToBreakouts)
+			meetings.POST("/:id/breakouts/close", meetingHandler.CloseBreakouts)
+
+			// 投票与问答（会后查询与导出）
+			meetings.GET("/:id/polls", meetingHandler.ListPolls)
+			meetings.GET("/:id/polls/export", meetingHandler.ExportPolls)
+			meetings.GET("/:id/questions", meetingHandler.ListQuestions)
+			meetings.GET("/:id/questions/export", meetingHandler.ExportQuestions)
+
 			// 会议室管理
 			meetings.GET("/:id/room", meetingHandler.GetMeetingRoom)
 			meetings.POST("/:id/room", meetingHandler.CreateMeetingRoom)
@@ -342,6 +385,8 @@ func setupRoutes(r *gin.Engine, meetingHandler *handlers.MeetingHandler) {
 			// 聊天消息
 			meetings.GET("/:id/messages", meetingHandler.GetChatMessages)
 			meetings.POST("/:id/messages", meetingHandler.SendChatMessage)
+			meetings.PUT("/:id/messages/:message_id", meetingHandler.EditChatMessage)
+			meetings.DELETE("/:id/messages/:message_id", meetingHandler.DeleteChatMessage)
 		}
 
 		// 我的会议
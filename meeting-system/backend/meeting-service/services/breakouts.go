@@ -0,0 +1,496 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// CreateBreakouts 为会议创建 count 个分组讨论房间（子会议），已有未关闭的分组时拒绝
+func (s *MeetingService) CreateBreakouts(meetingID uint, op Operator, req *models.CreateBreakoutsRequest) ([]*models.BreakoutRoomResponse, error) {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	var parent models.Meeting
+	if err := s.db.First(&parent, meetingID).Error; err != nil {
+		return nil, err
+	}
+	if parent.IsBreakout() {
+		return nil, fmt.Errorf("cannot nest breakouts")
+	}
+	if !parent.IsActive() {
+		return nil, fmt.Errorf("meeting is not active")
+	}
+	current, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) > 0 {
+		return nil, fmt.Errorf("breakouts already exist")
+	}
+
+	// 分组沿用主会议设置，但不需要审批、不锁定：参与者由主办人直接分配
+	settings := jsonToSettings(parent.Settings)
+	settings.RequireApproval = false
+	settings.Locked = false
+
+	breakouts := make([]models.Meeting, 0, req.Count)
+	for i := 0; i < req.Count; i++ {
+		title := fmt.Sprintf("%s - 分组 %d", parent.Title, i+1)
+		if i < len(req.Names) && strings.TrimSpace(req.Names[i]) != "" {
+			title = strings.TrimSpace(req.Names[i])
+		}
+		breakouts = append(breakouts, models.Meeting{
+			Title:           title,
+			CreatorID:       parent.CreatorID,
+			StartTime:       time.Now(),
+			EndTime:         parent.EndTime,
+			MaxParticipants: parent.MaxParticipants,
+			Status:          models.MeetingStatusScheduled,
+			MeetingType:     parent.MeetingType,
+			Settings:        settingsToJSON(settings),
+			ParentMeetingID: &parent.ID,
+		})
+	}
+	if err := s.db.Create(&breakouts).Error; err != nil {
+		return nil, err
+	}
+
+	s.recordOperation(op, meetingID, models.OperationCreateBreakouts, map[string]interface{}{
+		"count": req.Count,
+	})
+	return s.ListBreakouts(meetingID, op.UserID)
+}
+
+// ListBreakouts 获取会议当前（未关闭）的分组及其成员
+func (s *MeetingService) ListBreakouts(meetingID uint, userID uint) ([]*models.BreakoutRoomResponse, error) {
+	if !s.canAccessMeeting(meetingID, userID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	breakouts, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.breakoutMembers(breakouts)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.BreakoutRoomResponse, 0, len(breakouts))
+	for i := range breakouts {
+		breakout := &breakouts[i]
+		resp := &models.BreakoutRoomResponse{
+			ID:      breakout.ID,
+			Title:   breakout.Title,
+			Status:  breakout.Status,
+			UserIDs: members[breakout.ID],
+		}
+		if resp.UserIDs == nil {
+			resp.UserIDs = []uint{}
+		}
+		if breakout.IsStarted() {
+			closesAt := breakout.EndTime
+			resp.ClosesAt = &closesAt
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// AssignBreakouts 手动或随机分配参与者；分组已开启时信令服务立即迁移被重新分配的连接
+func (s *MeetingService) AssignBreakouts(meetingID uint, op Operator, req *models.AssignBreakoutsRequest) ([]*models.BreakoutRoomResponse, error) {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	breakouts, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if len(breakouts) == 0 {
+		return nil, fmt.Errorf("no breakouts")
+	}
+	breakoutIDs := meetingIDs(breakouts)
+
+	var parent models.Meeting
+	if err := s.db.First(&parent, meetingID).Error; err != nil {
+		return nil, err
+	}
+	var participants []models.MeetingParticipant
+	if err := s.db.Where("meeting_id = ?", meetingID).Find(&participants).Error; err != nil {
+		return nil, err
+	}
+	roles := make(map[uint]models.ParticipantRole, len(participants))
+	for _, p := range participants {
+		if p.Status == models.ParticipantStatusBanned || p.Status == models.ParticipantStatusRejected {
+			continue
+		}
+		roles[p.UserID] = p.Role
+	}
+	roles[parent.CreatorID] = models.ParticipantRoleHost
+
+	assignments := req.Assignments
+	if req.Random {
+		// 主办人留在主会议，其余已加入的参与者随机均分
+		candidates := make([]uint, 0, len(participants))
+		for _, p := range participants {
+			if p.Status == models.ParticipantStatusJoined && p.UserID != parent.CreatorID && p.Role != models.ParticipantRoleHost {
+				candidates = append(candidates, p.UserID)
+			}
+		}
+		assignments = assignRandomly(candidates, breakoutIDs, rand.New(rand.NewSource(time.Now().UnixNano())))
+	} else if err := validateAssignments(assignments, breakoutIDs, roles); err != nil {
+		return nil, err
+	}
+
+	open := breakouts[0].IsStarted()
+	status := models.ParticipantStatusInvited
+	if open {
+		status = models.ParticipantStatusJoined
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		clear := tx.Unscoped().Where("meeting_id IN ?", breakoutIDs)
+		if !req.Random {
+			clear = clear.Where("user_id IN ?", assignmentUsers(assignments))
+		}
+		if err := clear.Delete(&models.MeetingParticipant{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, a := range assignments {
+			participant := &models.MeetingParticipant{
+				MeetingID: a.BreakoutID,
+				UserID:    a.UserID,
+				Role:      roles[a.UserID],
+				Status:    status,
+			}
+			if open {
+				participant.JoinedAt = &now
+			}
+			if err := tx.Create(participant).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordOperation(op, meetingID, models.OperationAssignBreakouts, map[string]interface{}{
+		"random":      req.Random,
+		"assignments": len(assignments),
+	})
+	if open && len(assignments) > 0 {
+		s.publishBreakoutEvent(queue.EventBreakoutOpened, meetingID, op.UserID, breakoutIDs, map[string]interface{}{
+			"assignments": assignmentsPayload(assignments),
+			"closes_at":   breakouts[0].EndTime.Unix(),
+		})
+	}
+	return s.ListBreakouts(meetingID, op.UserID)
+}
+
+// OpenBreakouts 开启分组：信令服务把已分配的连接迁移到各分组房间；duration 大于 0 时到期自动关闭
+func (s *MeetingService) OpenBreakouts(meetingID uint, op Operator, duration time.Duration) ([]*models.BreakoutRoomResponse, error) {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	breakouts, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if len(breakouts) == 0 {
+		return nil, fmt.Errorf("no breakouts")
+	}
+	if breakouts[0].IsStarted() {
+		return nil, fmt.Errorf("breakouts already open")
+	}
+	breakoutIDs := meetingIDs(breakouts)
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": models.MeetingStatusOngoing}
+	closesAt := breakouts[0].EndTime
+	if duration > 0 {
+		closesAt = now.Add(duration)
+		updates["end_time"] = closesAt
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Meeting{}).Where("id IN ?", breakoutIDs).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.MeetingParticipant{}).
+			Where("meeting_id IN ?", breakoutIDs).
+			Updates(map[string]interface{}{"status": models.ParticipantStatusJoined, "joined_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var participants []models.MeetingParticipant
+	if err := s.db.Where("meeting_id IN ?", breakoutIDs).Find(&participants).Error; err != nil {
+		return nil, err
+	}
+	assignments := make([]models.BreakoutAssignment, 0, len(participants))
+	for _, p := range participants {
+		assignments = append(assignments, models.BreakoutAssignment{UserID: p.UserID, BreakoutID: p.MeetingID})
+	}
+
+	if duration > 0 {
+		s.scheduleBreakoutClose(meetingID, breakoutIDs, closesAt)
+	}
+	s.recordOperation(op, meetingID, models.OperationOpenBreakouts, map[string]interface{}{
+		"duration_seconds": int(duration.Seconds()),
+	})
+	s.publishBreakoutEvent(queue.EventBreakoutOpened, meetingID, op.UserID, breakoutIDs, map[string]interface{}{
+		"assignments": assignmentsPayload(assignments),
+		"closes_at":   closesAt.Unix(),
+	})
+	return s.ListBreakouts(meetingID, op.UserID)
+}
+
+// BroadcastToBreakouts 主办人/主持人向所有已开启的分组广播消息
+func (s *MeetingService) BroadcastToBreakouts(meetingID uint, op Operator, message string) error {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return fmt.Errorf("access denied")
+	}
+
+	breakouts, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return err
+	}
+	if len(breakouts) == 0 || !breakouts[0].IsStarted() {
+		return fmt.Errorf("breakouts not open")
+	}
+
+	s.recordOperation(op, meetingID, models.OperationBroadcastBreakout, map[string]interface{}{
+		"message": message,
+	})
+	s.publishBreakoutEvent(queue.EventBreakoutBroadcast, meetingID, op.UserID, meetingIDs(breakouts), map[string]interface{}{
+		"message": message,
+	})
+	return nil
+}
+
+// CloseBreakouts 关闭所有分组，信令服务把分组房间中的连接迁回主会议
+func (s *MeetingService) CloseBreakouts(meetingID uint, op Operator) error {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return fmt.Errorf("access denied")
+	}
+
+	closed, err := s.closeBreakouts(meetingID, op.UserID)
+	if err != nil {
+		return err
+	}
+	s.recordOperation(op, meetingID, models.OperationCloseBreakouts, map[string]interface{}{
+		"breakouts": closed,
+	})
+	return nil
+}
+
+// closeBreakouts 结束分组并通知信令服务，返回关闭的分组数量
+func (s *MeetingService) closeBreakouts(meetingID uint, byUserID uint) (int, error) {
+	s.cancelBreakoutClose(meetingID)
+
+	breakouts, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return 0, err
+	}
+	return s.endBreakouts(meetingID, byUserID, meetingIDs(breakouts))
+}
+
+// endBreakouts 结束指定的分组（已被关闭的分组不再处理）并通知信令服务
+func (s *MeetingService) endBreakouts(meetingID uint, byUserID uint, breakoutIDs []uint) (int, error) {
+	if len(breakoutIDs) == 0 {
+		return 0, fmt.Errorf("no breakouts")
+	}
+
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Meeting{}).
+			Where("id IN ? AND status IN ?", breakoutIDs, []models.MeetingStatus{models.MeetingStatusScheduled, models.MeetingStatusOngoing}).
+			Updates(map[string]interface{}{"status": models.MeetingStatusEnded, "end_time": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("no breakouts")
+		}
+		return tx.Model(&models.MeetingParticipant{}).
+			Where("meeting_id IN ? AND status = ?", breakoutIDs, models.ParticipantStatusJoined).
+			Updates(map[string]interface{}{"status": models.ParticipantStatusLeft, "left_at": now}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.publishBreakoutEvent(queue.EventBreakoutClosed, meetingID, byUserID, breakoutIDs, map[string]interface{}{})
+	return len(breakoutIDs), nil
+}
+
+// scheduleBreakoutClose 分组计时：到期自动关闭本次开启的分组（服务重启后计时丢失，需主办人手动关闭）。
+// 计时只存在于开启分组的副本上，到期时按分组 ID 与数据库中的结束时间确认，不会提前关闭之后经其他副本开启的分组
+func (s *MeetingService) scheduleBreakoutClose(meetingID uint, breakoutIDs []uint, closesAt time.Time) {
+	s.breakoutMux.Lock()
+	defer s.breakoutMux.Unlock()
+
+	if s.breakoutTimers == nil {
+		s.breakoutTimers = make(map[uint]*time.Timer)
+	}
+	if timer := s.breakoutTimers[meetingID]; timer != nil {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(closesAt), func() {
+		s.breakoutMux.Lock()
+		if s.breakoutTimers[meetingID] == timer {
+			delete(s.breakoutTimers, meetingID)
+		}
+		s.breakoutMux.Unlock()
+
+		closed, err := s.closeExpiredBreakouts(meetingID, breakoutIDs)
+		if err != nil && err.Error() != "no breakouts" {
+			logger.Error("Failed to close breakouts on timer", logger.Uint("meeting_id", meetingID), logger.Err(err))
+			return
+		}
+		if closed > 0 {
+			logger.Info("Breakouts closed on timer", logger.Uint("meeting_id", meetingID))
+		}
+	})
+	s.breakoutTimers[meetingID] = timer
+}
+
+// closeExpiredBreakouts 计时到期：仍是计时对应的那组分组且已到数据库中的结束时间才关闭
+func (s *MeetingService) closeExpiredBreakouts(meetingID uint, breakoutIDs []uint) (int, error) {
+	breakouts, err := s.currentBreakouts(meetingID)
+	if err != nil {
+		return 0, err
+	}
+	if len(breakouts) == 0 || !slices.Equal(meetingIDs(breakouts), breakoutIDs) || time.Now().Before(breakouts[0].EndTime) {
+		return 0, nil
+	}
+	return s.endBreakouts(meetingID, 0, breakoutIDs)
+}
+
+func (s *MeetingService) cancelBreakoutClose(meetingID uint) {
+	s.breakoutMux.Lock()
+	defer s.breakoutMux.Unlock()
+
+	if timer := s.breakoutTimers[meetingID]; timer != nil {
+		timer.Stop()
+		delete(s.breakoutTimers, meetingID)
+	}
+}
+
+// currentBreakouts 会议当前未关闭的分组（按创建顺序）
+func (s *MeetingService) currentBreakouts(meetingID uint) ([]models.Meeting, error) {
+	var breakouts []models.Meeting
+	if err := s.db.Where("parent_meeting_id = ? AND status IN ?", meetingID,
+		[]models.MeetingStatus{models.MeetingStatusScheduled, models.MeetingStatusOngoing}).
+		Order("id ASC").
+		Find(&breakouts).Error; err != nil {
+		return nil, err
+	}
+	return breakouts, nil
+}
+
+// breakoutMembers 各分组已分配的用户
+func (s *MeetingService) breakoutMembers(breakouts []models.Meeting) (map[uint][]uint, error) {
+	members := make(map[uint][]uint, len(breakouts))
+	if len(breakouts) == 0 {
+		return members, nil
+	}
+	var participants []models.MeetingParticipant
+	if err := s.db.Where("meeting_id IN ?", meetingIDs(breakouts)).Order("user_id ASC").Find(&participants).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range participants {
+		members[p.MeetingID] = append(members[p.MeetingID], p.UserID)
+	}
+	return members, nil
+}
+
+// publishBreakoutEvent 通知信令服务迁移连接或广播分组消息
+func (s *MeetingService) publishBreakoutEvent(eventType string, meetingID uint, byUserID uint, breakoutIDs []uint, payload map[string]interface{}) {
+	payload["meeting_id"] = meetingID
+	payload["by_user_id"] = byUserID
+	payload["breakout_ids"] = breakoutIDs
+	s.publishMeetingEvent(eventType, payload)
+}
+
+// assignRandomly 打乱后轮流分配，各分组人数相差不超过 1
+func assignRandomly(userIDs []uint, breakoutIDs []uint, rnd *rand.Rand) []models.BreakoutAssignment {
+	if len(breakoutIDs) == 0 {
+		return nil
+	}
+	shuffled := append([]uint(nil), userIDs...)
+	rnd.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	assignments := make([]models.BreakoutAssignment, 0, len(shuffled))
+	for i, userID := range shuffled {
+		assignments = append(assignments, models.BreakoutAssignment{UserID: userID, BreakoutID: breakoutIDs[i%len(breakoutIDs)]})
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].UserID < assignments[j].UserID })
+	return assignments
+}
+
+// validateAssignments 手动分配：分组必须属于该会议，用户必须是会议参与者且只能分到一个分组
+func validateAssignments(assignments []models.BreakoutAssignment, breakoutIDs []uint, members map[uint]models.ParticipantRole) error {
+	if len(assignments) == 0 {
+		return fmt.Errorf("no assignments")
+	}
+	valid := make(map[uint]bool, len(breakoutIDs))
+	for _, id := range breakoutIDs {
+		valid[id] = true
+	}
+	seen := make(map[uint]bool, len(assignments))
+	for _, a := range assignments {
+		if !valid[a.BreakoutID] {
+			return fmt.Errorf("invalid breakout: %d", a.BreakoutID)
+		}
+		if _, ok := members[a.UserID]; !ok {
+			return fmt.Errorf("user %d is not in the meeting", a.UserID)
+		}
+		if seen[a.UserID] {
+			return fmt.Errorf("user %d assigned more than once", a.UserID)
+		}
+		seen[a.UserID] = true
+	}
+	return nil
+}
+
+func meetingIDs(meetings []models.Meeting) []uint {
+	ids := make([]uint, 0, len(meetings))
+	for _, m := range meetings {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func assignmentUsers(assignments []models.BreakoutAssignment) []uint {
+	users := make([]uint, 0, len(assignments))
+	for _, a := range assignments {
+		users = append(users, a.UserID)
+	}
+	return users
+}
+
+func assignmentsPayload(assignments []models.BreakoutAssignment) []map[string]interface{} {
+	payload := make([]map[string]interface{}, 0, len(assignments))
+	for _, a := range assignments {
+		payload = append(payload, map[string]interface{}{"user_id": a.UserID, "breakout_id": a.BreakoutID})
+	}
+	return payload
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+func TestAssignRandomly_BalancedAcrossBreakouts(t *testing.T) {
+	users := []uint{11, 12, 13, 14, 15, 16, 17}
+	breakouts := []uint{101, 102, 103}
+
+	assignments := assignRandomly(users, breakouts, rand.New(rand.NewSource(1)))
+
+	require.Len(t, assignments, len(users))
+	sizes := make(map[uint]int)
+	assigned := make([]uint, 0, len(assignments))
+	for _, a := range assignments {
+		sizes[a.BreakoutID]++
+		assigned = append(assigned, a.UserID)
+	}
+	assert.Equal(t, users, assigned, "每个用户分配一次，按用户 ID 排序")
+	for _, id := range breakouts {
+		assert.GreaterOrEqual(t, sizes[id], 2)
+		assert.LessOrEqual(t, sizes[id], 3)
+	}
+	assert.Empty(t, assignRandomly(users, nil, rand.New(rand.NewSource(1))))
+}
+
+func TestValidateAssignments(t *testing.T) {
+	members := map[uint]models.ParticipantRole{
+		7: models.ParticipantRoleHost,
+		9: models.ParticipantRoleParticipant,
+	}
+	breakouts := []uint{101, 102}
+
+	cases := []struct {
+		name        string
+		assignments []models.BreakoutAssignment
+		wantErr     string
+	}{
+		{"valid", []models.BreakoutAssignment{{UserID: 7, BreakoutID: 101}, {UserID: 9, BreakoutID: 102}}, ""},
+		{"empty", nil, "no assignments"},
+		{"foreign breakout", []models.BreakoutAssignment{{UserID: 9, BreakoutID: 200}}, "invalid breakout: 200"},
+		{"not a participant", []models.BreakoutAssignment{{UserID: 42, BreakoutID: 101}}, "user 42 is not in the meeting"},
+		{"duplicate user", []models.BreakoutAssignment{{UserID: 9, BreakoutID: 101}, {UserID: 9, BreakoutID: 102}}, "user 9 assigned more than once"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateAssignments(tc.assignments, breakouts, members)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+// TestCloseExpiredBreakouts_OnlyClosesScheduledSet 其他副本上的旧计时到期不会关闭新开启的分组，未到结束时间也不关闭
+func TestCloseExpiredBreakouts_OnlyClosesScheduledSet(t *testing.T) {
+	s, events := newHostControlService(t)
+	parentID := uint(1)
+	now := time.Now()
+	require.NoError(t, s.db.Create(&models.Meeting{ID: 101, Title: "分组 1", CreatorID: 7, ParentMeetingID: &parentID,
+		StartTime: now, EndTime: now, Status: models.MeetingStatusEnded}).Error)
+	require.NoError(t, s.db.Create(&models.Meeting{ID: 102, Title: "分组 1", CreatorID: 7, ParentMeetingID: &parentID,
+		StartTime: now, EndTime: now.Add(time.Minute), Status: models.MeetingStatusOngoing}).Error)
+	status := func(id uint) models.MeetingStatus {
+		var meeting models.Meeting
+		require.NoError(t, s.db.First(&meeting, id).Error)
+		return meeting.Status
+	}
+
+	closed, err := s.closeExpiredBreakouts(1, []uint{101})
+	require.NoError(t, err)
+	assert.Zero(t, closed, "旧的一组分组已关闭")
+
+	closed, err = s.closeExpiredBreakouts(1, []uint{102})
+	require.NoError(t, err)
+	assert.Zero(t, closed, "未到结束时间")
+	assert.Equal(t, models.MeetingStatusOngoing, status(102))
+	assert.Empty(t, events.messages)
+
+	require.NoError(t, s.db.Model(&models.Meeting{}).Where("id = ?", 102).Update("end_time", now.Add(-time.Second)).Error)
+	closed, err = s.closeExpiredBreakouts(1, []uint{102})
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	assert.Equal(t, models.MeetingStatusEnded, status(102))
+	require.Len(t, events.messages, 1)
+	assert.Equal(t, queue.EventBreakoutClosed, events.messages[0].Type)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"meeting-system/shared/database"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+const (
+	defaultChatPageSize = 50
+	maxChatPageSize     = 100
+	chatStoreTimeout    = 5 * time.Second
+)
+
+// SetChatStore 设置聊天存储（chat.store 为 mongodb 时使用 MongoDB）
+func (s *MeetingService) SetChatStore(store database.ChatStore) {
+	s.chat = store
+}
+
+// ListChatMessages 分页获取聊天历史（按时间正序的一页，NextCursor 指向更早的消息）；
+// 只返回公开消息与该用户收发的私聊，threadID 非空时只返回该话题的回复
+func (s *MeetingService) ListChatMessages(meetingID, userID uint, cursor string, limit int, threadID string) (models.ChatHistoryMessage, error) {
+	if !s.canAccessMeeting(meetingID, userID) {
+		return models.ChatHistoryMessage{}, fmt.Errorf("access denied")
+	}
+	if limit <= 0 {
+		limit = defaultChatPageSize
+	}
+	if limit > maxChatPageSize {
+		limit = maxChatPageSize
+	}
+
+	query, err := models.ChatHistoryQuery{MeetingID: meetingID, ViewerID: userID, ThreadID: threadID}.WithCursor(cursor)
+	if err != nil {
+		return models.ChatHistoryMessage{}, err
+	}
+	query.Limit = limit + 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+	records, err := s.chat.ListChatMessages(ctx, query)
+	if err != nil {
+		return models.ChatHistoryMessage{}, err
+	}
+	return models.NewChatHistory(records, limit), nil
+}
+
+// SendChatMessage 发送聊天消息（公开、私聊或回复话题），由信令服务推送给在线参与者
+func (s *MeetingService) SendChatMessage(meetingID, userID uint, username string, req models.ChatMessage) (models.ChatMessage, error) {
+	if !s.canAccessMeeting(meetingID, userID) {
+		return models.ChatMessage{}, fmt.Errorf("access denied")
+	}
+	if req.ToUserID != 0 && req.ToUserID != userID && !s.canAccessMeeting(meetingID, req.ToUserID) {
+		return models.ChatMessage{}, fmt.Errorf("recipient is not in the meeting")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+
+	var parent *models.MeetingChatMessage
+	if req.ReplyToID != "" {
+		var err error
+		if parent, err = s.chat.GetChatMessage(ctx, req.ReplyToID); err != nil {
+			return models.ChatMessage{}, err
+		}
+	}
+	record, err := models.NewMeetingChatMessage(meetingID, userID, username, req, parent)
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+	if err := s.chat.SaveChatMessage(ctx, record); err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	payload := record.Payload()
+	s.publishChatMessage(payload, models.ChatActionSend, userID)
+	return payload, nil
+}
+
+// EditChatMessage 作者编辑聊天消息
+func (s *MeetingService) EditChatMessage(meetingID, userID uint, messageID, content string) (models.ChatMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+
+	record, err := s.meetingChatMessage(ctx, meetingID, userID, messageID)
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+	if err := record.Edit(userID, content, time.Now()); err != nil {
+		return models.ChatMessage{}, err
+	}
+	if err := s.chat.UpdateChatMessage(ctx, record); err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	payload := record.Payload()
+	s.publishChatMessage(payload, models.ChatActionEdit, userID)
+	return payload, nil
+}
+
+// DeleteChatMessage 删除聊天消息（作者，或主办人/主持人删除公开消息），保留墓碑
+func (s *MeetingService) DeleteChatMessage(meetingID, userID uint, messageID string) (models.ChatMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+
+	record, err := s.meetingChatMessage(ctx, meetingID, userID, messageID)
+	if err != nil {
+		return models.ChatMessage{}, err
+	}
+	if err := record.Delete(userID, s.canModerateMeeting(meetingID, userID), time.Now()); err != nil {
+		return models.ChatMessage{}, err
+	}
+	if err := s.chat.UpdateChatMessage(ctx, record); err != nil {
+		return models.ChatMessage{}, err
+	}
+
+	payload := record.Payload()
+	s.publishChatMessage(payload, models.ChatActionDelete, userID)
+	return payload, nil
+}
+
+// meetingChatMessage 查询本会议内对该用户可见的消息
+func (s *MeetingService) meetingChatMessage(ctx context.Context, meetingID, userID uint, messageID string) (*models.MeetingChatMessage, error) {
+	if !s.canAccessMeeting(meetingID, userID) {
+		return nil, fmt.Errorf("access denied")
+	}
+	record, err := s.chat.GetChatMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if record.MeetingID != meetingID || !record.VisibleTo(userID) {
+		return nil, models.ErrChatMessageNotFound
+	}
+	return record, nil
+}
+
+// publishChatMessage 通知信令服务按可见范围推送聊天消息
+func (s *MeetingService) publishChatMessage(payload models.ChatMessage, action string, byUserID uint) {
+	s.publishMeetingEvent(queue.EventChatMessage, map[string]interface{}{
+		"meeting_id": payload.MeetingID,
+		"by_user_id": byUserID,
+		"action":     action,
+		"message":    payload,
+	})
+}
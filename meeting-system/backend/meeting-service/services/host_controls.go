@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operator 主持控制的操作者（权限校验与审计）
+type Operator struct {
+	UserID    uint
+	IP        string
+	UserAgent string
+}
+
+// MuteAll 全员静音（由信令服务执行），allowSelfUnmute 为 false 时由 SFU 强制静音
+func (s *MeetingService) MuteAll(meetingID uint, op Operator, allowSelfUnmute bool) error {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return fmt.Errorf("access denied")
+	}
+
+	s.recordOperation(op, meetingID, models.OperationMuteAll, map[string]interface{}{
+		"allow_self_unmute": allowSelfUnmute,
+	})
+	s.publishHostControl(meetingID, op.UserID, models.HostControlMessage{
+		Action:          models.HostControlMuteAll,
+		AllowSelfUnmute: allowSelfUnmute,
+	})
+	return nil
+}
+
+// SetMeetingLocked 锁定或解锁会议
+func (s *MeetingService) SetMeetingLocked(meetingID uint, op Operator, locked bool) error {
+	if !s.canModerateMeeting(meetingID, op.UserID) {
+		return fmt.Errorf("access denied")
+	}
+
+	// 在行锁下读改写会议设置，避免与信令服务的并发修改互相覆盖
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var meeting models.Meeting
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&meeting, meetingID).Error; err != nil {
+			return err
+		}
+		settings := jsonToSettings(meeting.Settings)
+		settings.Locked = locked
+		return tx.Model(&meeting).Update("settings", settingsToJSON(settings)).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteMeetingFromCache(meetingID)
+
+	operation, action := models.OperationUnlockMeeting, models.HostControlUnlock
+	if locked {
+		operation, action = models.OperationLockMeeting, models.HostControlLock
+	}
+	s.recordOperation(op, meetingID, operation, map[string]interface{}{})
+	s.publishHostControl(meetingID, op.UserID, models.HostControlMessage{Action: action})
+	return nil
+}
+
+// RemoveParticipant 移出参与者并断开其信令连接；ban 为 true 时禁止再次加入
+func (s *MeetingService) RemoveParticipant(meetingID uint, op Operator, targetID uint, ban bool) error {
+	role, err := s.participantRole(meetingID, op.UserID)
+	if err != nil || !role.CanModerate() {
+		return fmt.Errorf("access denied")
+	}
+
+	participant, err := s.controlTarget(meetingID, op.UserID, targetID)
+	if err != nil {
+		return err
+	}
+	if !role.CanControl(participant.Role) {
+		return fmt.Errorf("cannot control the host")
+	}
+
+	now := time.Now()
+	participant.Status = models.ParticipantStatusLeft
+	operation, action := models.OperationRemoveParticipant, models.HostControlRemove
+	if ban {
+		participant.Status = models.ParticipantStatusBanned
+		operation, action = models.OperationBanParticipant, models.HostControlBan
+	}
+	participant.LeftAt = &now
+	if err := s.db.Save(participant).Error; err != nil {
+		return err
+	}
+
+	s.recordOperation(op, meetingID, operation, map[string]interface{}{
+		"target_user_id": targetID,
+	})
+	s.publishHostControl(meetingID, op.UserID, models.HostControlMessage{Action: action, TargetUserID: targetID})
+	return nil
+}
+
+// UpdateParticipantRole 变更参与者角色（仅主办人），信令服务实时广播新角色
+func (s *MeetingService) UpdateParticipantRole(meetingID uint, op Operator, targetID uint, newRole models.ParticipantRole) error {
+	role, err := s.participantRole(meetingID, op.UserID)
+	if err != nil || role != models.ParticipantRoleHost {
+		return fmt.Errorf("access denied")
+	}
+	if !newRole.Assignable() {
+		return fmt.Errorf("invalid role")
+	}
+
+	participant, err := s.controlTarget(meetingID, op.UserID, targetID)
+	if err != nil {
+		return err
+	}
+	if participant.Role == models.ParticipantRoleHost {
+		return fmt.Errorf("cannot control the host")
+	}
+
+	previous := participant.Role
+	if err := s.db.Model(participant).Update("role", newRole).Error; err != nil {
+		return err
+	}
+
+	s.recordOperation(op, meetingID, models.OperationChangeRole, map[string]interface{}{
+		"target_user_id": targetID,
+		"role":           newRole.String(),
+		"previous_role":  previous.String(),
+	})
+	s.publishHostControl(meetingID, op.UserID, models.HostControlMessage{
+		Action:       models.HostControlSetRole,
+		TargetUserID: targetID,
+		Role:         newRole,
+	})
+	return nil
+}
+
+// controlTarget 查询主持控制的目标参与者：不能控制自己与会议创建者
+func (s *MeetingService) controlTarget(meetingID uint, operatorID uint, targetID uint) (*models.MeetingParticipant, error) {
+	if targetID == operatorID {
+		return nil, fmt.Errorf("cannot control yourself")
+	}
+
+	var meeting models.Meeting
+	if err := s.db.First(&meeting, meetingID).Error; err != nil {
+		return nil, err
+	}
+	if meeting.CreatorID == targetID {
+		return nil, fmt.Errorf("cannot control the host")
+	}
+
+	var participant models.MeetingParticipant
+	if err := s.db.Where("meeting_id = ? AND user_id = ?", meetingID, targetID).First(&participant).Error; err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// publishHostControl 通知信令服务执行主持控制
+func (s *MeetingService) publishHostControl(meetingID uint, operatorID uint, control models.HostControlMessage) {
+	s.publishMeetingEvent(queue.EventHostControl, map[string]interface{}{
+		"meeting_id":        meetingID,
+		"by_user_id":        operatorID,
+		"action":            control.Action,
+		"target_user_id":    control.TargetUserID,
+		"allow_self_unmute": control.AllowSelfUnmute,
+		"role":              int(control.Role),
+	})
+}
+
+// recordOperation 写入审计日志；失败只记录日志，不影响操作结果
+func (s *MeetingService) recordOperation(op Operator, meetingID uint, operation string, details map[string]interface{}) {
+	details["source"] = "rest"
+	data, err := json.Marshal(details)
+	if err != nil {
+		data = []byte("{}")
+	}
+
+	entry := &models.OperationLog{
+		UserID:       op.UserID,
+		Operation:    operation,
+		ResourceType: "meeting",
+		ResourceID:   meetingID,
+		Details:      string(data),
+		UserAgent:    op.UserAgent,
+	}
+	if op.IP != "" {
+		ip := op.IP
+		entry.IPAddress = &ip
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		logger.Error("Failed to record operation log",
+			logger.String("operation", operation),
+			logger.Uint("meeting_id", meetingID),
+			logger.Uint("user_id", op.UserID),
+			logger.Err(err))
+	}
+}
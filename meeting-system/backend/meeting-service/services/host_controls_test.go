@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// recordingPublisher 记录发布的会议事件
+type recordingPublisher struct {
+	messages []queue.PubSubMessage
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, channel string, msg *queue.PubSubMessage) error {
+	p.messages = append(p.messages, *msg)
+	return nil
+}
+
+// newHostControlService 会议 1 由 7 创建，8 为主持人，9、10 为普通参与者
+func newHostControlService(t *testing.T) (*MeetingService, *recordingPublisher) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Meeting{}, &models.MeetingParticipant{}, &models.MeetingRoom{}, &models.OperationLog{}))
+
+	now := time.Now()
+	require.NoError(t, db.Create(&models.Meeting{ID: 1, Title: "周会", CreatorID: 7, StartTime: now, EndTime: now.Add(time.Hour),
+		Status: models.MeetingStatusOngoing, Settings: settingsToJSON(models.MeetingSettings{})}).Error)
+	for userID, role := range map[uint]models.ParticipantRole{
+		7:  models.ParticipantRoleHost,
+		8:  models.ParticipantRoleModerator,
+		9:  models.ParticipantRoleParticipant,
+		10: models.ParticipantRoleParticipant,
+	} {
+		require.NoError(t, db.Create(&models.MeetingParticipant{MeetingID: 1, UserID: userID, Role: role, Status: models.ParticipantStatusJoined}).Error)
+	}
+
+	mr := miniredis.RunT(t)
+	events := &recordingPublisher{}
+	s := &MeetingService{db: db, redis: redis.NewClient(&redis.Options{Addr: mr.Addr()}), events: events}
+	return s, events
+}
+
+func participantOf(t *testing.T, s *MeetingService, userID uint) models.MeetingParticipant {
+	t.Helper()
+	var participant models.MeetingParticipant
+	require.NoError(t, s.db.Where("meeting_id = ? AND user_id = ?", 1, userID).First(&participant).Error)
+	return participant
+}
+
+func TestRemoveParticipant_BanBlocksRejoin(t *testing.T) {
+	s, events := newHostControlService(t)
+
+	assert.Error(t, s.RemoveParticipant(1, Operator{UserID: 9}, 10, false), "普通参与者不能移出他人")
+	assert.Error(t, s.RemoveParticipant(1, Operator{UserID: 8}, 7, true), "不能移出主办人")
+	assert.Error(t, s.RemoveParticipant(1, Operator{UserID: 8}, 8, false), "不能移出自己")
+	assert.Empty(t, events.messages)
+
+	require.NoError(t, s.RemoveParticipant(1, Operator{UserID: 8}, 9, false))
+	assert.Equal(t, models.ParticipantStatusLeft, participantOf(t, s, 9).Status)
+	_, err := s.JoinMeeting(1, 9, "")
+	assert.NoError(t, err, "仅移出可以重新加入")
+
+	require.NoError(t, s.RemoveParticipant(1, Operator{UserID: 7}, 10, true))
+	banned := participantOf(t, s, 10)
+	assert.Equal(t, models.ParticipantStatusBanned, banned.Status)
+	assert.NotNil(t, banned.LeftAt)
+	_, err = s.JoinMeeting(1, 10, "")
+	assert.EqualError(t, err, "participant banned")
+
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, queue.EventHostControl, events.messages[1].Type)
+	assert.Equal(t, models.HostControlBan, events.messages[1].Payload["action"])
+	assert.Equal(t, uint(10), events.messages[1].Payload["target_user_id"])
+
+	var logs int64
+	require.NoError(t, s.db.Model(&models.OperationLog{}).Where("operation = ?", models.OperationBanParticipant).Count(&logs).Error)
+	assert.Equal(t, int64(1), logs)
+}
+
+func TestSetMeetingLocked_BlocksNewParticipants(t *testing.T) {
+	s, events := newHostControlService(t)
+
+	assert.Error(t, s.SetMeetingLocked(1, Operator{UserID: 9}, true), "普通参与者不能锁定会议")
+	require.NoError(t, s.SetMeetingLocked(1, Operator{UserID: 8}, true))
+
+	_, err := s.JoinMeeting(1, 42, "")
+	assert.EqualError(t, err, "meeting is locked")
+	_, err = s.JoinMeeting(1, 9, "")
+	assert.NoError(t, err, "已有参与者仍可加入")
+
+	require.NoError(t, s.SetMeetingLocked(1, Operator{UserID: 7}, false))
+	_, err = s.JoinMeeting(1, 42, "")
+	assert.NoError(t, err)
+
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, models.HostControlLock, events.messages[0].Payload["action"])
+	assert.Equal(t, models.HostControlUnlock, events.messages[1].Payload["action"])
+}
+
+func TestUpdateParticipantRole_HostOnly(t *testing.T) {
+	s, events := newHostControlService(t)
+
+	assert.Error(t, s.UpdateParticipantRole(1, Operator{UserID: 8}, 9, models.ParticipantRoleModerator), "主持人不能变更角色")
+	assert.Error(t, s.UpdateParticipantRole(1, Operator{UserID: 7}, 9, models.ParticipantRoleHost), "不能授予主办人")
+	assert.Error(t, s.UpdateParticipantRole(1, Operator{UserID: 7}, 7, models.ParticipantRoleParticipant), "不能变更自己")
+	assert.Empty(t, events.messages)
+
+	require.NoError(t, s.UpdateParticipantRole(1, Operator{UserID: 7}, 9, models.ParticipantRolePresenter))
+	assert.Equal(t, models.ParticipantRolePresenter, participantOf(t, s, 9).Role)
+	require.NoError(t, s.UpdateParticipantRole(1, Operator{UserID: 7}, 8, models.ParticipantRoleParticipant))
+	assert.Error(t, s.RemoveParticipant(1, Operator{UserID: 8}, 10, false), "降级后失去主持权限")
+
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, models.HostControlSetRole, events.messages[0].Payload["action"])
+	assert.Equal(t, int(models.ParticipantRolePresenter), events.messages[0].Payload["role"])
+}
+
+func TestMuteAll_RequiresModerator(t *testing.T) {
+	s, events := newHostControlService(t)
+
+	assert.Error(t, s.MuteAll(1, Operator{UserID: 9}, true))
+	assert.Error(t, s.MuteAll(1, Operator{UserID: 42}, true), "非参与者")
+	assert.Empty(t, events.messages)
+
+	require.NoError(t, s.MuteAll(1, Operator{UserID: 8}, false))
+	require.Len(t, events.messages, 1)
+	assert.Equal(t, models.HostControlMuteAll, events.messages[0].Payload["action"])
+	assert.Equal(t, false, events.messages[0].Payload["allow_self_unmute"])
+}
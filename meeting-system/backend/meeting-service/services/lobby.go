@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// meetingEventTimeout 发布会议事件的超时
+const meetingEventTimeout = 3 * time.Second
+
+// EventPublisher 事件发布接口（生产环境为 Kafka 事件总线）
+type EventPublisher interface {
+	Publish(ctx context.Context, channel string, msg *queue.PubSubMessage) error
+}
+
+// SetEventPublisher 设置事件发布器，用于通知信令服务执行等候室准入/拒绝与主持控制
+func (s *MeetingService) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+// requiresApproval 会议开启入会审批时，新用户与仍在等候的用户需要进入等候室；
+// 创建者、主办人/主持人、已被邀请或曾经准入（已加入/已离开）的参与者直接入会
+func requiresApproval(meeting *models.Meeting, userID uint, participant *models.MeetingParticipant, exists bool) bool {
+	if meeting.CreatorID == userID || !jsonToSettings(meeting.Settings).RequireApproval {
+		return false
+	}
+	if !exists {
+		return true
+	}
+	if participant.Role.CanModerate() {
+		return false
+	}
+	return participant.Status == models.ParticipantStatusWaiting
+}
+
+// enterLobby 把用户登记为等候者，返回等候中的加入响应（不创建会议室、不自动开始会议）
+func (s *MeetingService) enterLobby(meeting *models.Meeting, userID uint, participant *models.MeetingParticipant, exists bool) (*models.JoinMeetingResponse, error) {
+	if !exists {
+		*participant = models.MeetingParticipant{
+			MeetingID: meeting.ID,
+			UserID:    userID,
+			Role:      models.ParticipantRoleParticipant,
+			Status:    models.ParticipantStatusWaiting,
+		}
+		if err := s.db.Create(participant).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.JoinMeetingResponse{
+		MeetingID:     meeting.ID,
+		ParticipantID: participant.ID,
+		Role:          participant.Role,
+		Waiting:       true,
+	}, nil
+}
+
+// GetLobby 获取等候室中的用户（仅主办人/主持人）
+func (s *MeetingService) GetLobby(meetingID uint, userID uint) ([]*models.ParticipantResponse, error) {
+	if !s.canModerateMeeting(meetingID, userID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	var participants []models.MeetingParticipant
+	if err := s.db.Where("meeting_id = ? AND status = ?", meetingID, models.ParticipantStatusWaiting).
+		Order("created_at ASC").
+		Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]*models.ParticipantResponse, 0, len(participants))
+	for _, p := range participants {
+		responses = append(responses, &models.ParticipantResponse{
+			ID:        p.ID,
+			UserID:    p.UserID,
+			MeetingID: p.MeetingID,
+			Role:      p.Role,
+			Status:    p.Status,
+		})
+	}
+
+	return responses, nil
+}
+
+// AdmitParticipant 准入等候室中的用户
+func (s *MeetingService) AdmitParticipant(meetingID uint, hostID uint, userID uint) error {
+	_, err := s.decideAdmission(meetingID, hostID, []uint{userID}, true)
+	return err
+}
+
+// DenyParticipant 拒绝等候室中的用户
+func (s *MeetingService) DenyParticipant(meetingID uint, hostID uint, userID uint) error {
+	_, err := s.decideAdmission(meetingID, hostID, []uint{userID}, false)
+	return err
+}
+
+// AdmitAllParticipants 准入等候室中的所有用户，返回被准入的用户ID
+func (s *MeetingService) AdmitAllParticipants(meetingID uint, hostID uint) ([]uint, error) {
+	return s.decideAdmission(meetingID, hostID, nil, true)
+}
+
+// decideAdmission 更新等候者的准入状态并通知信令服务；userIDs 为 nil 表示全部等候者
+func (s *MeetingService) decideAdmission(meetingID uint, hostID uint, userIDs []uint, admit bool) ([]uint, error) {
+	if !s.canModerateMeeting(meetingID, hostID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	query := s.db.Model(&models.MeetingParticipant{}).
+		Where("meeting_id = ? AND status = ?", meetingID, models.ParticipantStatusWaiting)
+	if userIDs != nil {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+
+	var waiting []uint
+	if err := query.Pluck("user_id", &waiting).Error; err != nil {
+		return nil, err
+	}
+	if len(waiting) == 0 {
+		if userIDs != nil {
+			return nil, fmt.Errorf("participant not waiting")
+		}
+		return waiting, nil
+	}
+
+	updates := map[string]interface{}{"status": models.ParticipantStatusRejected}
+	if admit {
+		updates = map[string]interface{}{"status": models.ParticipantStatusJoined, "joined_at": time.Now()}
+	}
+	if err := s.db.Model(&models.MeetingParticipant{}).
+		Where("meeting_id = ? AND status = ? AND user_id IN ?", meetingID, models.ParticipantStatusWaiting, waiting).
+		Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	s.publishLobbyDecision(meetingID, hostID, waiting, admit)
+	return waiting, nil
+}
+
+// publishLobbyDecision 通知信令服务把等候中的连接加入房间或断开
+func (s *MeetingService) publishLobbyDecision(meetingID uint, hostID uint, userIDs []uint, admit bool) {
+	eventType := queue.EventLobbyDenied
+	if admit {
+		eventType = queue.EventLobbyAdmitted
+	}
+
+	s.publishMeetingEvent(eventType, map[string]interface{}{
+		"meeting_id": meetingID,
+		"user_ids":   userIDs,
+		"by_user_id": hostID,
+	})
+}
+
+// publishMeetingEvent 向信令服务发布会议事件
+func (s *MeetingService) publishMeetingEvent(eventType string, payload map[string]interface{}) {
+	if s.events == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), meetingEventTimeout)
+	defer cancel()
+	if err := s.events.Publish(ctx, queue.ChannelMeetingEvents, &queue.PubSubMessage{
+		Type:    eventType,
+		Payload: payload,
+		Source:  "meeting-service",
+	}); err != nil {
+		logger.Warn("Failed to publish meeting event",
+			logger.String("event", eventType),
+			logger.Any("meeting_id", payload["meeting_id"]),
+			logger.Err(err))
+	}
+}
+
+// canModerateMeeting 检查用户是否可以管理会议（创建者、主办人或主持人）
+func (s *MeetingService) canModerateMeeting(meetingID uint, userID uint) bool {
+	role, err := s.participantRole(meetingID, userID)
+	return err == nil && role.CanModerate()
+}
+
+// participantRole 获取用户在会议中的角色（会议创建者视为主办人）
+func (s *MeetingService) participantRole(meetingID uint, userID uint) (models.ParticipantRole, error) {
+	var meeting models.Meeting
+	if err := s.db.First(&meeting, meetingID).Error; err != nil {
+		return 0, err
+	}
+	if meeting.CreatorID == userID {
+		return models.ParticipantRoleHost, nil
+	}
+
+	var participant models.MeetingParticipant
+	if err := s.db.Where("meeting_id = ? AND user_id = ?", meetingID, userID).First(&participant).Error; err != nil {
+		return 0, err
+	}
+	return participant.Role, nil
+}
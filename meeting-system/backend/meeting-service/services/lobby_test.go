@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-system/shared/models"
+)
+
+func TestRequiresApproval(t *testing.T) {
+	approval := &models.Meeting{ID: 1, CreatorID: 7, Settings: settingsToJSON(models.MeetingSettings{RequireApproval: true})}
+	open := &models.Meeting{ID: 1, CreatorID: 7, Settings: settingsToJSON(models.MeetingSettings{})}
+
+	participant := func(role models.ParticipantRole, status models.ParticipantStatus) *models.MeetingParticipant {
+		return &models.MeetingParticipant{MeetingID: 1, UserID: 9, Role: role, Status: status}
+	}
+
+	cases := []struct {
+		name        string
+		meeting     *models.Meeting
+		userID      uint
+		participant *models.MeetingParticipant
+		exists      bool
+		want        bool
+	}{
+		{"approval disabled", open, 9, &models.MeetingParticipant{}, false, false},
+		{"creator", approval, 7, &models.MeetingParticipant{}, false, false},
+		{"new user", approval, 9, &models.MeetingParticipant{}, false, true},
+		{"still waiting", approval, 9, participant(models.ParticipantRoleParticipant, models.ParticipantStatusWaiting), true, true},
+		{"invited", approval, 9, participant(models.ParticipantRoleParticipant, models.ParticipantStatusInvited), true, false},
+		{"admitted before", approval, 9, participant(models.ParticipantRoleParticipant, models.ParticipantStatusLeft), true, false},
+		{"moderator", approval, 9, participant(models.ParticipantRoleModerator, models.ParticipantStatusWaiting), true, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, requiresApproval(tc.meeting, tc.userID, tc.participant, tc.exists))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -15,17 +16,24 @@ import (
 	"meeting-system/shared/database"
 	"meeting-system/shared/logger"
 	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
 )
 
 type MeetingService struct {
-	db    *gorm.DB
-	redis *redis.Client
+	db     *gorm.DB
+	redis  *redis.Client
+	events EventPublisher // 跨服务事件发布（通知信令服务等候室准入结果与主持控制）
+	chat   database.ChatStore
+
+	breakoutMux    sync.Mutex
+	breakoutTimers map[uint]*time.Timer // meetingID -> 分组自动关闭计时
 }
 
 func NewMeetingService() *MeetingService {
 	return &MeetingService{
 		db:    database.GetDB(),
 		redis: database.GetRedis(),
+		chat:  database.NewSQLChatStore(database.GetDB()),
 	}
 }
 
@@ -202,6 +210,13 @@ func (s *MeetingService) UpdateMeeting(meetingID uint, userID uint, req *models.
 	// 更新缓存
 	s.cacheMeeting(&meeting)
 
+	// 设置变更后媒体服务需重新加载编解码策略
+	if req.Settings != nil {
+		s.publishMeetingEvent(queue.EventMeetingSettingsUpdated, map[string]interface{}{
+			"meeting_id": meeting.ID,
+		})
+	}
+
 	return &meeting, nil
 }
 
@@ -235,10 +250,13 @@ func (s *MeetingService) JoinMeeting(meetingID uint, userID uint, password strin
 		return nil, err
 	}
 
-	// 检查会议状态
+	// 检查会议状态（分组讨论房间由主办人开启，不能直接加入未开启的分组）
 	if meeting.Status != models.MeetingStatusOngoing && meeting.Status != models.MeetingStatusScheduled {
 		return nil, fmt.Errorf("meeting is not available")
 	}
+	if meeting.IsBreakout() && !meeting.IsStarted() {
+		return nil, fmt.Errorf("meeting is not available")
+	}
 
 	// 检查密码
 	if meeting.Password != "" && meeting.Password != password {
@@ -248,8 +266,31 @@ func (s *MeetingService) JoinMeeting(meetingID uint, userID uint, password strin
 	// 检查参与者是否已存在
 	var participant models.MeetingParticipant
 	err := s.db.Where("meeting_id = ? AND user_id = ?", meetingID, userID).First(&participant).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	exists := err == nil
+
+	// 入会审批：被拒绝者不能再次加入，需审批者进入等候室
+	if exists && participant.Status == models.ParticipantStatusRejected {
+		return nil, fmt.Errorf("admission denied")
+	}
+	if exists && participant.Status == models.ParticipantStatusBanned {
+		return nil, fmt.Errorf("participant banned")
+	}
+	// 分组讨论房间只有被分配的参与者与主办人可以加入
+	if !exists && meeting.CreatorID != userID && meeting.IsBreakout() {
+		return nil, fmt.Errorf("breakout not assigned")
+	}
+	// 会议已锁定：只有创建者与已有参与者可以加入
+	if !exists && meeting.CreatorID != userID && jsonToSettings(meeting.Settings).Locked {
+		return nil, fmt.Errorf("meeting is locked")
+	}
+	if requiresApproval(&meeting, userID, &participant, exists) {
+		return s.enterLobby(&meeting, userID, &participant, exists)
+	}
 
-	if err == gorm.ErrRecordNotFound {
+	if !exists {
 		// 创建新参与者
 		participant = models.MeetingParticipant{
 			MeetingID: meetingID,
@@ -264,8 +305,6 @@ func (s *MeetingService) JoinMeeting(meetingID uint, userID uint, password strin
 		if err := s.db.Create(&participant).Error; err != nil {
 			return nil, err
 		}
-	} else if err != nil {
-		return nil, err
 	} else {
 		// 更新参与者状态
 		now := time.Now()
@@ -361,7 +400,8 @@ func (s *MeetingService) ListMeetings(userID uint, req *models.MeetingListReques
 
 	filtered := s.db.Model(&models.Meeting{}).
 		Joins("LEFT JOIN meeting_participants mp ON mp.meeting_id = meetings.id AND mp.deleted_at IS NULL").
-		Where("meetings.creator_id = ? OR mp.user_id = ?", userID, userID)
+		Where("meetings.creator_id = ? OR mp.user_id = ?", userID, userID).
+		Where("meetings.parent_meeting_id IS NULL")
 
 	if req.Status != 0 {
 		filtered = filtered.Where("meetings.status = ?", req.Status)
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"meeting-system/shared/models"
+)
+
+// ListPolls 获取会议的全部投票及结果；投票进行中且隐藏结果时，非主办人/主持人只能看到选项
+func (s *MeetingService) ListPolls(meetingID uint, userID uint) ([]models.PollState, error) {
+	if !s.canAccessMeeting(meetingID, userID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	states, err := s.pollStates(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	canModerate := s.canModerateMeeting(meetingID, userID)
+	for i := range states {
+		poll := models.Poll{ResultsVisibility: states[i].ResultsVisibility, Status: states[i].Status}
+		if !poll.ResultsVisibleTo(canModerate) {
+			states[i] = states[i].WithoutResults()
+		}
+	}
+	return states, nil
+}
+
+// ListQuestions 获取会议问答，按点赞数与提问时间排序；待审核与已忽略的问题只有主办人/主持人与提问者可见
+func (s *MeetingService) ListQuestions(meetingID uint, userID uint) ([]models.MeetingQuestion, error) {
+	if !s.canAccessMeeting(meetingID, userID) {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	questions, err := s.meetingQuestions(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	canModerate := s.canModerateMeeting(meetingID, userID)
+	visible := make([]models.MeetingQuestion, 0, len(questions))
+	for i := range questions {
+		if questions[i].VisibleTo(userID, canModerate) {
+			visible = append(visible, questions[i])
+		}
+	}
+	return visible, nil
+}
+
+// ExportPollsCSV 导出会议投票结果（仅主办人/主持人）
+func (s *MeetingService) ExportPollsCSV(meetingID uint, userID uint, w io.Writer) error {
+	if !s.canModerateMeeting(meetingID, userID) {
+		return fmt.Errorf("access denied")
+	}
+	states, err := s.pollStates(meetingID)
+	if err != nil {
+		return err
+	}
+	return writePollsCSV(w, states)
+}
+
+// ExportQuestionsCSV 导出会议问答（仅主办人/主持人）
+func (s *MeetingService) ExportQuestionsCSV(meetingID uint, userID uint, w io.Writer) error {
+	if !s.canModerateMeeting(meetingID, userID) {
+		return fmt.Errorf("access denied")
+	}
+	questions, err := s.meetingQuestions(meetingID)
+	if err != nil {
+		return err
+	}
+	return writeQuestionsCSV(w, questions)
+}
+
+// pollStates 按创建顺序汇总会议内每个投票的结果
+func (s *MeetingService) pollStates(meetingID uint) ([]models.PollState, error) {
+	var meeting models.Meeting
+	if err := s.db.First(&meeting, meetingID).Error; err != nil {
+		return nil, err
+	}
+
+	var polls []models.Poll
+	if err := s.db.Preload("Options").Where("meeting_id = ?", meetingID).Order("created_at ASC, id ASC").Find(&polls).Error; err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return []models.PollState{}, nil
+	}
+
+	pollIDs := make([]uint, len(polls))
+	for i := range polls {
+		pollIDs[i] = polls[i].ID
+	}
+	var votes []models.PollVote
+	if err := s.db.Where("poll_id IN ?", pollIDs).Order("id ASC").Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	votesByPoll := make(map[uint][]models.PollVote, len(polls))
+	for _, vote := range votes {
+		votesByPoll[vote.PollID] = append(votesByPoll[vote.PollID], vote)
+	}
+
+	states := make([]models.PollState, 0, len(polls))
+	for i := range polls {
+		states = append(states, polls[i].State(votesByPoll[polls[i].ID]))
+	}
+	return states, nil
+}
+
+func (s *MeetingService) meetingQuestions(meetingID uint) ([]models.MeetingQuestion, error) {
+	var meeting models.Meeting
+	if err := s.db.First(&meeting, meetingID).Error; err != nil {
+		return nil, err
+	}
+
+	var questions []models.MeetingQuestion
+	if err := s.db.Where("meeting_id = ?", meetingID).Order("upvotes DESC, created_at ASC, id ASC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// writePollsCSV 每个选项一行；匿名投票不输出投票人
+func writePollsCSV(w io.Writer, polls []models.PollState) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"poll_id", "question", "status", "multiple_choice", "anonymous", "option", "votes", "voter_ids", "total_voters", "created_at", "closed_at"}); err != nil {
+		return err
+	}
+	for _, poll := range polls {
+		for _, option := range poll.Options {
+			voters := make([]string, len(option.VoterIDs))
+			for i, id := range option.VoterIDs {
+				voters[i] = strconv.FormatUint(uint64(id), 10)
+			}
+			record := []string{
+				strconv.FormatUint(uint64(poll.ID), 10),
+				csvText(poll.Question),
+				poll.Status,
+				strconv.FormatBool(poll.MultipleChoice),
+				strconv.FormatBool(poll.Anonymous),
+				csvText(option.Text),
+				strconv.Itoa(option.Votes),
+				strings.Join(voters, ";"),
+				strconv.Itoa(poll.TotalVoters),
+				formatCSVTime(&poll.CreatedAt),
+				formatCSVTime(poll.ClosedAt),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeQuestionsCSV 每个问题一行
+func writeQuestionsCSV(w io.Writer, questions []models.MeetingQuestion) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"question_id", "user_id", "username", "text", "status", "upvotes", "answer", "answered_by", "created_at", "answered_at"}); err != nil {
+		return err
+	}
+	for _, question := range questions {
+		answeredBy := ""
+		if question.AnsweredBy != nil {
+			answeredBy = strconv.FormatUint(uint64(*question.AnsweredBy), 10)
+		}
+		record := []string{
+			strconv.FormatUint(uint64(question.ID), 10),
+			strconv.FormatUint(uint64(question.UserID), 10),
+			csvText(question.Username),
+			csvText(question.Text),
+			question.Status,
+			strconv.Itoa(question.Upvotes),
+			csvText(question.Answer),
+			answeredBy,
+			formatCSVTime(&question.CreatedAt),
+			formatCSVTime(question.AnsweredAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText 用户输入的文本以 =、+、-、@ 等开头时加单引号前缀，避免导出文件在表格软件中被当作公式执行
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
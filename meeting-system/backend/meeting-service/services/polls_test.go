@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+func TestWritePollsCSV(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	closedAt := createdAt.Add(5 * time.Minute)
+	named := models.Poll{ID: 1, Question: "午饭吃什么", Status: models.PollStatusClosed, CreatedAt: createdAt, ClosedAt: &closedAt,
+		Options: []models.PollOption{{ID: 11, Position: 0, Text: "面"}, {ID: 12, Position: 1, Text: "饭, 或者粥"}}}
+	anonymous := models.Poll{ID: 2, Question: "满意度", Anonymous: true, Status: models.PollStatusOpen, CreatedAt: createdAt,
+		Options: []models.PollOption{{ID: 21, Text: "满意"}, {ID: 22, Position: 1, Text: "不满意"}}}
+	polls := []models.PollState{
+		named.State([]models.PollVote{{UserID: 9, OptionID: 12}, {UserID: 10, OptionID: 12}}),
+		anonymous.State([]models.PollVote{{UserID: 9, OptionID: 21}}),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writePollsCSV(&buf, polls))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	assert.Equal(t, []string{"poll_id", "question", "status", "multiple_choice", "anonymous", "option", "votes", "voter_ids", "total_voters", "created_at", "closed_at"}, records[0])
+	assert.Equal(t, []string{"1", "午饭吃什么", "closed", "false", "false", "面", "0", "", "2", "2026-10-18T09:00:00Z", "2026-10-18T09:05:00Z"}, records[1])
+	assert.Equal(t, "饭, 或者粥", records[2][5], "含逗号的选项正确转义")
+	assert.Equal(t, "9;10", records[2][7])
+	assert.Equal(t, []string{"2", "满意度", "open", "false", "true", "满意", "1", "", "1", "2026-10-18T09:00:00Z", ""}, records[3])
+}
+
+func TestWriteQuestionsCSV(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	answeredAt := createdAt.Add(time.Minute)
+	answeredBy := uint(7)
+	questions := []models.MeetingQuestion{
+		{ID: 3, UserID: 9, Username: "alice", Text: "能分享\n幻灯片吗？", Status: models.QuestionStatusAnswered, Upvotes: 2,
+			Answer: "会后发到群里", AnsweredBy: &answeredBy, AnsweredAt: &answeredAt, CreatedAt: createdAt},
+		{ID: 4, UserID: 10, Username: "bob", Text: "待审核", Status: models.QuestionStatusPending, CreatedAt: createdAt},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeQuestionsCSV(&buf, questions))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"3", "9", "alice", "能分享\n幻灯片吗？", "answered", "2", "会后发到群里", "7", "2026-10-18T09:00:00Z", "2026-10-18T09:01:00Z"}, records[1])
+	assert.Equal(t, []string{"4", "10", "bob", "待审核", "pending", "0", "", "", "2026-10-18T09:00:00Z", ""}, records[2])
+}
+
+func TestCSVExport_EscapesFormulas(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	poll := models.Poll{ID: 1, Question: "=HYPERLINK(\"http://evil\")", Status: models.PollStatusOpen, CreatedAt: createdAt,
+		Options: []models.PollOption{{ID: 11, Text: "+1"}, {ID: 12, Position: 1, Text: "1+1"}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writePollsCSV(&buf, []models.PollState{poll.State(nil)}))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "'=HYPERLINK(\"http://evil\")", records[1][1])
+	assert.Equal(t, "'+1", records[1][5])
+	assert.Equal(t, "1+1", records[2][5], "只处理开头的字符")
+
+	buf.Reset()
+	require.NoError(t, writeQuestionsCSV(&buf, []models.MeetingQuestion{
+		{ID: 3, UserID: 9, Username: "@alice", Text: "-2 怎么算", Answer: "=1+1", Status: models.QuestionStatusAnswered, CreatedAt: createdAt},
+	}))
+	records, err = csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"'@alice", "'-2 怎么算", "answered", "0", "'=1+1"}, records[1][2:7])
+}
@@ -19,6 +19,7 @@ type Config struct {
 	Redis          RedisConfig          `mapstructure:"redis"`
 	Kafka          KafkaConfig          `mapstructure:"kafka"`
 	MongoDB        MongoConfig          `mapstructure:"mongodb"`
+	Chat           ChatConfig           `mapstructure:"chat"`
 	MinIO          MinIOConfig          `mapstructure:"minio"`
 	JWT            JWTConfig            `mapstructure:"jwt"`
 	ZMQ            ZMQConfig            `mapstructure:"zmq"`
@@ -27,6 +28,7 @@ type Config struct {
 	WebSocket      WebSocketConfig      `mapstructure:"websocket"`
 	Signaling      SignalingConfig      `mapstructure:"signaling"`
 	WebRTC         WebRTCConfig         `mapstructure:"webrtc"`
+	SIP            SIPConfig            `mapstructure:"sip"`
 	Services       ServicesConfig       `mapstructure:"services"`
 	Etcd           EtcdConfig           `mapstructure:"etcd"`
 	MessageQueue   MessageQueueConfig   `mapstructure:"message_queue"`
@@ -108,6 +110,20 @@ type MongoConfig struct {
 	Timeout  int    `mapstructure:"timeout"`
 }
 
+// 聊天存储后端
+const (
+	ChatStorePostgres = "postgres"
+	ChatStoreMongoDB  = "mongodb"
+)
+
+// ChatConfig 会议聊天配置（信令服务与会议服务共用同一存储）
+type ChatConfig struct {
+	// Store 聊天消息存储：postgres（默认）或 mongodb
+	Store string `mapstructure:"store"`
+	// HistoryOnJoin 入会时推送的最近聊天条数，<=0 不推送
+	HistoryOnJoin int `mapstructure:"history_on_join"`
+}
+
 // MinIOConfig MinIO对象存储配置
 type MinIOConfig struct {
 	Endpoint        string `mapstructure:"endpoint"`
@@ -144,6 +160,9 @@ type AIModelsConfig struct {
 	ASR       AIModelConfig `mapstructure:"asr"`
 	Emotion   AIModelConfig `mapstructure:"emotion"`
 	Synthesis AIModelConfig `mapstructure:"synthesis"`
+	// 视频模型
+	Deepfake      AIModelConfig `mapstructure:"deepfake"`
+	FaceDetection AIModelConfig `mapstructure:"face_detection"`
 }
 
 // AIRuntimeConfig defines inference runtime settings.
@@ -166,22 +185,27 @@ type TritonConfig struct {
 }
 
 type AIModelConfig struct {
-	ModelName          string   `mapstructure:"model_name"`
-	ModelPath          string   `mapstructure:"model_path"`
-	InputName          string   `mapstructure:"input_name"`
-	OutputNames        []string `mapstructure:"output_names"`
-	InputType          string   `mapstructure:"input_type"`
-	DecoderPath        string   `mapstructure:"decoder_path"`
-	DecoderInputNames  []string `mapstructure:"decoder_input_names"`
-	DecoderOutputNames []string `mapstructure:"decoder_output_names"`
-	TokenizerPath      string   `mapstructure:"tokenizer_path"`
-	SpecialTokensPath  string   `mapstructure:"special_tokens_path"`
-	ConfigPath         string   `mapstructure:"config_path"`
-	LabelsPath         string   `mapstructure:"labels_path"`
-	SampleRate         int      `mapstructure:"sample_rate"`
-	Channels           int      `mapstructure:"channels"`
-	Timeout            int      `mapstructure:"timeout"`        // 秒
-	MaxConcurrent      int      `mapstructure:"max_concurrent"` // 并发上限（预留）
+	ModelName          string    `mapstructure:"model_name"`
+	ModelPath          string    `mapstructure:"model_path"`
+	InputName          string    `mapstructure:"input_name"`
+	OutputNames        []string  `mapstructure:"output_names"`
+	InputType          string    `mapstructure:"input_type"`
+	DecoderPath        string    `mapstructure:"decoder_path"`
+	DecoderInputNames  []string  `mapstructure:"decoder_input_names"`
+	DecoderOutputNames []string  `mapstructure:"decoder_output_names"`
+	TokenizerPath      string    `mapstructure:"tokenizer_path"`
+	SpecialTokensPath  string    `mapstructure:"special_tokens_path"`
+	ConfigPath         string    `mapstructure:"config_path"`
+	LabelsPath         string    `mapstructure:"labels_path"`
+	SampleRate         int       `mapstructure:"sample_rate"`
+	Channels           int       `mapstructure:"channels"`
+	InputWidth         int       `mapstructure:"input_width"`     // 图像模型输入宽度
+	InputHeight        int       `mapstructure:"input_height"`    // 图像模型输入高度
+	Mean               []float64 `mapstructure:"mean"`            // 按 RGB 通道归一化均值（0-1 像素值）
+	Std                []float64 `mapstructure:"std"`             // 按 RGB 通道归一化标准差
+	ScoreThreshold     float64   `mapstructure:"score_threshold"` // 人脸检测置信度阈值
+	Timeout            int       `mapstructure:"timeout"`         // 秒
+	MaxConcurrent      int       `mapstructure:"max_concurrent"`  // 并发上限（预留）
 }
 
 type AIRequestConfig struct {
@@ -233,6 +257,48 @@ type SignalingConfig struct {
 	Message    MessageConfig `mapstructure:"message"`
 	ICEServers []ICEServer   `mapstructure:"ice_servers"`
 	Media      MediaConfig   `mapstructure:"media"`
+	AILive     AILiveConfig  `mapstructure:"ai_live"`
+	Cluster    ClusterConfig `mapstructure:"cluster"`
+	// MessagePolicy 客户端上行消息的限流、大小与载荷校验
+	MessagePolicy MessagePolicyConfig `mapstructure:"message_policy"`
+}
+
+// MessagePolicyConfig 上行消息策略：按会话、按消息类型令牌桶限流，违规按 警告 → 限流 → 断开 逐级升级
+type MessagePolicyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ViolationWindow 违规计数窗口（秒）
+	ViolationWindow int `mapstructure:"violation_window"`
+	// ThrottleAfter 窗口内违规达到该次数后限流会话 ThrottleDuration 秒，期间除心跳外的消息直接丢弃
+	ThrottleAfter    int `mapstructure:"throttle_after"`
+	ThrottleDuration int `mapstructure:"throttle_duration"`
+	// DisconnectAfter 窗口内违规达到该次数后断开连接（不可续传）
+	DisconnectAfter int `mapstructure:"disconnect_after"`
+	// Limits 按消息类型名（如 chat、media-control）覆盖内置限额
+	Limits map[string]MessageLimitConfig `mapstructure:"limits"`
+}
+
+// MessageLimitConfig 单个消息类型的限额；为 0 的字段沿用内置值
+type MessageLimitConfig struct {
+	Rate    float64 `mapstructure:"rate"`     // 每秒补充的令牌数
+	Burst   int     `mapstructure:"burst"`    // 令牌桶容量
+	MaxSize int     `mapstructure:"max_size"` // 单条消息最大字节数
+}
+
+// ClusterConfig 信令服务多实例部署：房间成员与在线状态存 Redis，房间消息经事件总线扇出到所有节点
+type ClusterConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// NodeID 节点标识（为空时使用主机名），Kafka 扇出时同时作为消费组后缀，应在重启间保持稳定
+	NodeID string `mapstructure:"node_id"`
+	// Transport 节点间扇出通道：redis（PUB/SUB，默认）或 kafka
+	Transport string `mapstructure:"transport"`
+	// PresenceTTL 节点存活键的过期时间（秒），节点宕机后其会话在过期后从房间成员中剔除，<=0 使用默认值
+	PresenceTTL int `mapstructure:"presence_ttl"`
+}
+
+// AILiveConfig 会议 AI Live 配置
+type AILiveConfig struct {
+	// Mode client: 由一个参与者浏览器申请领导者并调用 AI；server: 由媒体服务对会议内所有音频轨道运行 AI，信令服务广播结果
+	Mode string `mapstructure:"mode"`
 }
 
 // RoomConfig 房间配置
@@ -244,9 +310,14 @@ type RoomConfig struct {
 
 // SessionConfig 会话配置
 type SessionConfig struct {
-	HeartbeatInterval    int `mapstructure:"heartbeat_interval"`
-	ConnectionTimeout    int `mapstructure:"connection_timeout"`
+	HeartbeatInterval int `mapstructure:"heartbeat_interval"`
+	ConnectionTimeout int `mapstructure:"connection_timeout"`
+	// MaxReconnectAttempts 单个会话允许续传的次数，<=0 关闭断线续传
 	MaxReconnectAttempts int `mapstructure:"max_reconnect_attempts"`
+	// ResumeTimeout 断线后会话保留（等待续传）的时间（秒），超时后才广播离开
+	ResumeTimeout int `mapstructure:"resume_timeout"`
+	// ReplayBufferSize 每个会话保留的最近下发消息条数
+	ReplayBufferSize int `mapstructure:"replay_buffer_size"`
 }
 
 // MessageConfig 消息配置
@@ -267,6 +338,69 @@ type ICEServer struct {
 // WebRTCConfig WebRTC（SFU/ICE）配置
 type WebRTCConfig struct {
 	ICEServers []WebRTCICEServer `mapstructure:"ice_servers"`
+	// ICERestartGracePeriod 连接 disconnected/failed 后等待客户端 ICE restart 的宽限期（秒），超时才清理 Peer
+	ICERestartGracePeriod int `mapstructure:"ice_restart_grace_period"`
+	// KeyframeCacheMaxPackets 每个视频转发轨道缓存的最近 GOP 包数上限（用于新订阅者快速出首帧，<=0 使用默认值）
+	KeyframeCacheMaxPackets int `mapstructure:"keyframe_cache_max_packets"`
+	// EnableAudioRED 协商 RFC 2198 冗余音频（RED），未协商 RED 的订阅者由 SFU 剥离为纯 Opus
+	EnableAudioRED bool `mapstructure:"enable_audio_red"`
+	// AIVideoSampling 视频抽帧送 AI 的配置
+	AIVideoSampling AIVideoSamplingConfig `mapstructure:"ai_video_sampling"`
+	// ScreenShare 屏幕共享轨道的转发策略
+	ScreenShare ScreenShareConfig `mapstructure:"screen_share"`
+	// Stats 每个 Peer 的 RTP/RTCP 质量统计采集
+	Stats WebRTCStatsConfig `mapstructure:"stats"`
+}
+
+// WebRTCStatsConfig 媒体质量统计的采集周期与历史保留时长
+type WebRTCStatsConfig struct {
+	// Interval 采集周期（秒），<=0 使用默认值
+	Interval int `mapstructure:"interval"`
+	// HistoryRetention Peer 统计历史的保留时长（分钟，Peer 离开后同样保留），<=0 使用默认值
+	HistoryRetention int `mapstructure:"history_retention"`
+}
+
+// ScreenShareConfig 屏幕共享轨道相对摄像头视频的转发策略
+type ScreenShareConfig struct {
+	// BandwidthWeight 订阅者带宽在视频轨道间分配时屏幕共享的权重（摄像头为 1），<=0 使用默认值
+	BandwidthWeight float64 `mapstructure:"bandwidth_weight"`
+	// KeyframeMinInterval 订阅者触发的关键帧请求（PLI/FIR）转发给共享者的最小间隔（毫秒），<=0 使用默认值；
+	// 屏幕内容的关键帧很大，容忍更长的首帧/恢复延迟以免频繁关键帧挤占带宽
+	KeyframeMinInterval int `mapstructure:"keyframe_min_interval"`
+}
+
+// AIVideoSamplingConfig 从转发的 VP8/H.264 RTP 中抽帧、解码后送 AI 服务
+type AIVideoSamplingConfig struct {
+	// FrameInterval 每隔多少帧抽取一帧（关键帧总会抽取）；<=0 只抽关键帧
+	FrameInterval int `mapstructure:"frame_interval"`
+	// Format 输出格式：jpeg 或 rgb24
+	Format string `mapstructure:"format"`
+	// MaxWidth 输出最大宽度（等比缩放），<=0 不缩放
+	MaxWidth    int `mapstructure:"max_width"`
+	JPEGQuality int `mapstructure:"jpeg_quality"`
+	// RoomMaxFPS 每个房间送 AI 的最大帧率（所有发布者合计），<=0 使用默认值
+	RoomMaxFPS float64 `mapstructure:"room_max_fps"`
+}
+
+// SIPConfig 媒体服务的 SIP 网关模式（电话拨入/外呼，G.711/Opus 桥接进会议房间）
+type SIPConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ListenAddr SIP UDP 监听地址
+	ListenAddr string `mapstructure:"listen_addr"`
+	// PublicIP 写入 Via/Contact/SDP 的对外地址（NAT 后部署时必须配置）
+	PublicIP string `mapstructure:"public_ip"`
+	// OutboundProxy 外呼使用的 SIP 中继 host:port，为空时直接发往被叫 URI 的主机
+	OutboundProxy string `mapstructure:"outbound_proxy"`
+	// OutboundDomain 外呼被叫 URI 的域名（sip:<号码>@<domain>），为空时使用 OutboundProxy 的主机
+	OutboundDomain string `mapstructure:"outbound_domain"`
+	// CallerID 外呼主叫号码
+	CallerID   string `mapstructure:"caller_id"`
+	RTPPortMin int    `mapstructure:"rtp_port_min"`
+	RTPPortMax int    `mapstructure:"rtp_port_max"`
+	// PINTimeout 拨入后每段 DTMF（会议号、密码，以 # 结束）的输入超时（秒）
+	PINTimeout int `mapstructure:"pin_timeout"`
+	// MaxPINAttempts 会议号/密码错误的最大尝试次数
+	MaxPINAttempts int `mapstructure:"max_pin_attempts"`
 }
 
 // WebRTCICEServer WebRTC ICE server 配置（支持 urls 为数组）
@@ -480,6 +614,10 @@ func setDefaults() {
 	viper.SetDefault("mongodb.database", "meeting_system")
 	viper.SetDefault("mongodb.timeout", 30)
 
+	// 聊天默认配置
+	viper.SetDefault("chat.store", ChatStorePostgres)
+	viper.SetDefault("chat.history_on_join", 50)
+
 	// MinIO默认配置
 	viper.SetDefault("minio.endpoint", "localhost:9000")
 	viper.SetDefault("minio.access_key_id", "minioadmin")
@@ -543,6 +681,23 @@ func setDefaults() {
 	viper.SetDefault("ai.models.synthesis.channels", 1)
 	viper.SetDefault("ai.models.synthesis.timeout", 20)
 	viper.SetDefault("ai.models.synthesis.max_concurrent", 15)
+	viper.SetDefault("ai.models.deepfake.model_name", "deepfake")
+	viper.SetDefault("ai.models.deepfake.input_name", "image_input")
+	viper.SetDefault("ai.models.deepfake.output_names", []string{"logits"})
+	viper.SetDefault("ai.models.deepfake.input_width", 224)
+	viper.SetDefault("ai.models.deepfake.input_height", 224)
+	viper.SetDefault("ai.models.deepfake.mean", []float64{0.485, 0.456, 0.406})
+	viper.SetDefault("ai.models.deepfake.std", []float64{0.229, 0.224, 0.225})
+	viper.SetDefault("ai.models.deepfake.timeout", 20)
+	viper.SetDefault("ai.models.deepfake.max_concurrent", 4)
+	viper.SetDefault("ai.models.face_detection.model_name", "face_detection")
+	viper.SetDefault("ai.models.face_detection.input_name", "image_input")
+	viper.SetDefault("ai.models.face_detection.output_names", []string{"detections"})
+	viper.SetDefault("ai.models.face_detection.input_width", 320)
+	viper.SetDefault("ai.models.face_detection.input_height", 320)
+	viper.SetDefault("ai.models.face_detection.score_threshold", 0.5)
+	viper.SetDefault("ai.models.face_detection.timeout", 10)
+	viper.SetDefault("ai.models.face_detection.max_concurrent", 8)
 	viper.SetDefault("ai.request.max_retries", 3)
 	viper.SetDefault("ai.request.retry_delay", 1000)
 	viper.SetDefault("ai.request.timeout", 30)
@@ -576,6 +731,27 @@ func setDefaults() {
 	viper.SetDefault("services.ai_service.grpc_port", 9085)
 	viper.SetDefault("services.ai_service.timeout", "10s")
 
+	// 信令默认配置
+	viper.SetDefault("signaling.ai_live.mode", "client")
+
+	// WebRTC 默认配置
+	viper.SetDefault("webrtc.ice_restart_grace_period", 15)
+	viper.SetDefault("webrtc.enable_audio_red", true)
+	viper.SetDefault("webrtc.ai_video_sampling.frame_interval", 30)
+	viper.SetDefault("webrtc.ai_video_sampling.format", "jpeg")
+	viper.SetDefault("webrtc.ai_video_sampling.max_width", 640)
+	viper.SetDefault("webrtc.ai_video_sampling.jpeg_quality", 80)
+	viper.SetDefault("webrtc.ai_video_sampling.room_max_fps", 2)
+
+	// SIP 网关默认配置
+	viper.SetDefault("sip.enabled", false)
+	viper.SetDefault("sip.listen_addr", "0.0.0.0:5060")
+	viper.SetDefault("sip.caller_id", "meeting")
+	viper.SetDefault("sip.rtp_port_min", 30000)
+	viper.SetDefault("sip.rtp_port_max", 30100)
+	viper.SetDefault("sip.pin_timeout", 30)
+	viper.SetDefault("sip.max_pin_attempts", 3)
+
 	// etcd默认配置
 	viper.SetDefault("etcd.endpoints", []string{"localhost:2379"})
 	viper.SetDefault("etcd.dial_timeout", 5)
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/models"
+)
+
+// ChatStore 会议聊天消息存储（PostgreSQL 或 MongoDB，由 chat.store 配置选择）
+type ChatStore interface {
+	// SaveChatMessage 保存新消息，ID 与 CreatedAt 为空时由存储填写
+	SaveChatMessage(ctx context.Context, message *models.MeetingChatMessage) error
+	// GetChatMessage 不存在时返回 models.ErrChatMessageNotFound
+	GetChatMessage(ctx context.Context, id string) (*models.MeetingChatMessage, error)
+	// UpdateChatMessage 保存编辑与删除（内容、EditedAt、DeletedAt）
+	UpdateChatMessage(ctx context.Context, message *models.MeetingChatMessage) error
+	// ListChatMessages 按 (CreatedAt, ID) 倒序返回最多 query.Limit 条
+	ListChatMessages(ctx context.Context, query models.ChatHistoryQuery) ([]models.MeetingChatMessage, error)
+}
+
+// NewChatStore 按配置创建聊天存储，所需的数据库连接须已初始化
+func NewChatStore(cfg config.ChatConfig) (ChatStore, error) {
+	switch cfg.Store {
+	case "", config.ChatStorePostgres:
+		if GetDB() == nil {
+			return nil, fmt.Errorf("chat store %q: database not initialized", config.ChatStorePostgres)
+		}
+		return NewSQLChatStore(GetDB()), nil
+	case config.ChatStoreMongoDB:
+		if GetMongoDB() == nil {
+			return nil, fmt.Errorf("chat store %q: MongoDB not initialized", config.ChatStoreMongoDB)
+		}
+		return NewMongoChatStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported chat store: %s", cfg.Store)
+	}
+}
+
+// prepareChatMessage 填写 ID 与创建时间；时间截断到毫秒，保证两种存储读回的值与分页游标一致
+func prepareChatMessage(message *models.MeetingChatMessage) {
+	if message.ID == "" {
+		message.ID = uuid.NewString()
+	}
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	message.CreatedAt = message.CreatedAt.UTC().Truncate(time.Millisecond)
+}
+
+// SQLChatStore 基于 GORM 的聊天存储（meeting_chat_messages 表）
+type SQLChatStore struct {
+	db *gorm.DB
+}
+
+// NewSQLChatStore 创建 SQL 聊天存储
+func NewSQLChatStore(db *gorm.DB) *SQLChatStore {
+	return &SQLChatStore{db: db}
+}
+
+func (s *SQLChatStore) SaveChatMessage(ctx context.Context, message *models.MeetingChatMessage) error {
+	prepareChatMessage(message)
+	return s.db.WithContext(ctx).Create(message).Error
+}
+
+func (s *SQLChatStore) GetChatMessage(ctx context.Context, id string) (*models.MeetingChatMessage, error) {
+	var message models.MeetingChatMessage
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrChatMessageNotFound
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (s *SQLChatStore) UpdateChatMessage(ctx context.Context, message *models.MeetingChatMessage) error {
+	return s.db.WithContext(ctx).Model(&models.MeetingChatMessage{ID: message.ID}).
+		Select("content", "edited_at", "deleted_at").
+		Updates(message).Error
+}
+
+func (s *SQLChatStore) ListChatMessages(ctx context.Context, query models.ChatHistoryQuery) ([]models.MeetingChatMessage, error) {
+	db := s.db.WithContext(ctx).
+		Where("meeting_id = ?", query.MeetingID).
+		Where("(to_user_id IS NULL OR user_id = ? OR to_user_id = ?)", query.ViewerID, query.ViewerID)
+	if query.ThreadID != "" {
+		db = db.Where("reply_to_id = ?", query.ThreadID)
+	}
+	if !query.BeforeAt.IsZero() {
+		db = db.Where("(created_at < ? OR (created_at = ? AND id < ?))", query.BeforeAt, query.BeforeAt, query.BeforeID)
+	}
+
+	var messages []models.MeetingChatMessage
+	if err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MongoChatStore 基于 MongoDB 的聊天存储（chat_messages 集合）
+type MongoChatStore struct {
+	repo *ChatMessageRepository
+}
+
+// NewMongoChatStore 创建 MongoDB 聊天存储
+func NewMongoChatStore() *MongoChatStore {
+	return &MongoChatStore{repo: NewChatMessageRepository()}
+}
+
+func (s *MongoChatStore) SaveChatMessage(ctx context.Context, message *models.MeetingChatMessage) error {
+	prepareChatMessage(message)
+	_, err := s.repo.Insert(ctx, chatDocument(message))
+	return err
+}
+
+func (s *MongoChatStore) GetChatMessage(ctx context.Context, id string) (*models.MeetingChatMessage, error) {
+	var doc ChatMessage
+	if err := s.repo.FindOne(ctx, bson.M{"_id": id}, &doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, models.ErrChatMessageNotFound
+		}
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+func (s *MongoChatStore) UpdateChatMessage(ctx context.Context, message *models.MeetingChatMessage) error {
+	_, err := s.repo.UpdateOne(ctx, bson.M{"_id": message.ID}, bson.M{"$set": bson.M{
+		"content":    message.Content,
+		"edited_at":  message.EditedAt,
+		"deleted_at": message.DeletedAt,
+	}})
+	return err
+}
+
+func (s *MongoChatStore) ListChatMessages(ctx context.Context, query models.ChatHistoryQuery) ([]models.MeetingChatMessage, error) {
+	viewer := formatID(query.ViewerID)
+	conditions := bson.A{
+		bson.M{"meeting_id": formatID(query.MeetingID)},
+		bson.M{"$or": bson.A{
+			bson.M{"to_user_id": bson.M{"$exists": false}},
+			bson.M{"user_id": viewer},
+			bson.M{"to_user_id": viewer},
+		}},
+	}
+	if query.ThreadID != "" {
+		conditions = append(conditions, bson.M{"reply_to_id": query.ThreadID})
+	}
+	if !query.BeforeAt.IsZero() {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"timestamp": bson.M{"$lt": query.BeforeAt}},
+			bson.M{"timestamp": query.BeforeAt, "_id": bson.M{"$lt": query.BeforeID}},
+		}})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit))
+	cursor, err := s.repo.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []ChatMessage
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	messages := make([]models.MeetingChatMessage, 0, len(docs))
+	for i := range docs {
+		messages = append(messages, *docs[i].toModel())
+	}
+	return messages, nil
+}
+
+// chatDocument 聊天消息转换为 chat_messages 文档（ID 字段沿用文档的字符串格式）
+func chatDocument(message *models.MeetingChatMessage) *ChatMessage {
+	doc := &ChatMessage{
+		ID:          message.ID,
+		MeetingID:   formatID(message.MeetingID),
+		UserID:      formatID(message.UserID),
+		Username:    message.Username,
+		MessageType: "text",
+		Content:     message.Content,
+		Timestamp:   message.CreatedAt,
+		ReplyToID:   message.ReplyToID,
+		EditedAt:    message.EditedAt,
+		DeletedAt:   message.DeletedAt,
+	}
+	if message.ToUserID != nil {
+		doc.ToUserID = formatID(*message.ToUserID)
+	}
+	return doc
+}
+
+func (doc *ChatMessage) toModel() *models.MeetingChatMessage {
+	message := &models.MeetingChatMessage{
+		ID:        doc.ID,
+		MeetingID: parseID(doc.MeetingID),
+		UserID:    parseID(doc.UserID),
+		Username:  doc.Username,
+		ReplyToID: doc.ReplyToID,
+		Content:   doc.Content,
+		CreatedAt: doc.Timestamp.UTC(),
+		EditedAt:  doc.EditedAt,
+		DeletedAt: doc.DeletedAt,
+	}
+	if doc.ToUserID != "" {
+		to := parseID(doc.ToUserID)
+		message.ToUserID = &to
+	}
+	return message
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func parseID(id string) uint {
+	value, _ := strconv.ParseUint(id, 10, 64)
+	return uint(value)
+}
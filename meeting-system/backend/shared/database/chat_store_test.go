@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"meeting-system/shared/models"
+)
+
+func newTestSQLChatStore(t *testing.T) *SQLChatStore {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.MeetingChatMessage{}))
+	return NewSQLChatStore(db)
+}
+
+func TestSQLChatStore_CursorPaginationAndVisibility(t *testing.T) {
+	store := newTestSQLChatStore(t)
+	ctx := context.Background()
+	base := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	bob := uint(2)
+
+	// 5 条公开消息，其中两条时间相同；1 条 alice -> bob 的私聊；1 条 carol -> dave 的私聊
+	for i := 0; i < 5; i++ {
+		at := base.Add(time.Duration(i) * time.Second)
+		if i == 4 {
+			at = base.Add(3 * time.Second)
+		}
+		require.NoError(t, store.SaveChatMessage(ctx, &models.MeetingChatMessage{
+			ID: fmt.Sprintf("public-%d", i), MeetingID: 1, UserID: 1, Content: fmt.Sprintf("m%d", i), CreatedAt: at,
+		}))
+	}
+	require.NoError(t, store.SaveChatMessage(ctx, &models.MeetingChatMessage{
+		ID: "dm-bob", MeetingID: 1, UserID: 1, ToUserID: &bob, Content: "hi bob", CreatedAt: base.Add(10 * time.Second),
+	}))
+	dave := uint(4)
+	require.NoError(t, store.SaveChatMessage(ctx, &models.MeetingChatMessage{
+		ID: "dm-dave", MeetingID: 1, UserID: 3, ToUserID: &dave, Content: "hi dave", CreatedAt: base.Add(11 * time.Second),
+	}))
+	require.NoError(t, store.SaveChatMessage(ctx, &models.MeetingChatMessage{
+		ID: "other-meeting", MeetingID: 2, UserID: 1, Content: "elsewhere", CreatedAt: base,
+	}))
+
+	var pages [][]string
+	query := models.ChatHistoryQuery{MeetingID: 1, ViewerID: bob}
+	cursor := ""
+	for {
+		var err error
+		query, err = query.WithCursor(cursor)
+		require.NoError(t, err)
+		query.Limit = 3
+		records, err := store.ListChatMessages(ctx, query)
+		require.NoError(t, err)
+		page := models.NewChatHistory(records, 2)
+
+		ids := make([]string, 0, len(page.Messages))
+		for _, msg := range page.Messages {
+			ids = append(ids, msg.ID)
+		}
+		pages = append(pages, ids)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, [][]string{
+		{"public-4", "dm-bob"},
+		{"public-2", "public-3"},
+		{"public-0", "public-1"},
+	}, pages, "每页按时间正序，翻页不重复不遗漏，别人的私聊不可见")
+}
+
+func TestSQLChatStore_ThreadsEditsAndTombstones(t *testing.T) {
+	store := newTestSQLChatStore(t)
+	ctx := context.Background()
+
+	root := &models.MeetingChatMessage{MeetingID: 1, UserID: 1, Content: "root"}
+	require.NoError(t, store.SaveChatMessage(ctx, root))
+	assert.NotEmpty(t, root.ID)
+	assert.Equal(t, root.CreatedAt, root.CreatedAt.Truncate(time.Millisecond))
+
+	reply := &models.MeetingChatMessage{MeetingID: 1, UserID: 2, Content: "reply", ReplyToID: root.ID}
+	require.NoError(t, store.SaveChatMessage(ctx, reply))
+
+	now := time.Now()
+	require.NoError(t, reply.Edit(2, "edited", now))
+	require.NoError(t, store.UpdateChatMessage(ctx, reply))
+	require.NoError(t, root.Delete(1, false, now))
+	require.NoError(t, store.UpdateChatMessage(ctx, root))
+
+	thread, err := store.ListChatMessages(ctx, models.ChatHistoryQuery{MeetingID: 1, ViewerID: 3, ThreadID: root.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, thread, 1)
+	assert.Equal(t, "edited", thread[0].Content)
+	assert.NotNil(t, thread[0].EditedAt)
+
+	stored, err := store.GetChatMessage(ctx, root.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsDeleted())
+	assert.Empty(t, stored.Content)
+	assert.True(t, stored.Payload().Deleted)
+
+	_, err = store.GetChatMessage(ctx, "missing")
+	assert.ErrorIs(t, err, models.ErrChatMessageNotFound)
+}
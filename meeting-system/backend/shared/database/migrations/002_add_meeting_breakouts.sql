@@ -0,0 +1,16 @@
+-- 分组讨论：子会议关联主会议
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'meetings' AND column_name = 'parent_meeting_id'
+    ) THEN
+        ALTER TABLE meetings ADD COLUMN parent_meeting_id INTEGER REFERENCES meetings(id) ON DELETE CASCADE;
+        COMMENT ON COLUMN meetings.parent_meeting_id IS '分组讨论房间所属的主会议（普通会议为 NULL）';
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_meetings_parent_meeting_id ON meetings(parent_meeting_id);
+
+COMMIT;
@@ -0,0 +1,64 @@
+-- 会议投票与问答
+
+-- 会议投票表
+CREATE TABLE IF NOT EXISTS polls (
+    id SERIAL PRIMARY KEY,
+    meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    creator_id INTEGER NOT NULL REFERENCES users(id),
+    question VARCHAR(500) NOT NULL,
+    multiple_choice BOOLEAN DEFAULT FALSE,
+    anonymous BOOLEAN DEFAULT FALSE,
+    results_visibility VARCHAR(16) DEFAULT 'live', -- live:实时公开, hidden:结束后公开
+    status VARCHAR(16) DEFAULT 'open', -- open, closed
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_polls_meeting_id ON polls(meeting_id);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INTEGER DEFAULT 0,
+    text VARCHAR(200) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_options_poll_id ON poll_options(poll_id);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    option_id INTEGER NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_votes_poll_user_option ON poll_votes(poll_id, user_id, option_id);
+
+-- 会议问答表
+CREATE TABLE IF NOT EXISTS meeting_questions (
+    id SERIAL PRIMARY KEY,
+    meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    username VARCHAR(100),
+    text TEXT NOT NULL,
+    status VARCHAR(16) DEFAULT 'open', -- pending:待审核, open:待回答, answered:已回答, dismissed:已忽略
+    upvotes INTEGER DEFAULT 0,
+    answer TEXT,
+    answered_by INTEGER REFERENCES users(id),
+    answered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_questions_meeting_id ON meeting_questions(meeting_id);
+
+CREATE TABLE IF NOT EXISTS question_upvotes (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES meeting_questions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_upvotes_question_user ON question_upvotes(question_id, user_id);
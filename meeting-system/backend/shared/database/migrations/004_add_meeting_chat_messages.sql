@@ -0,0 +1,19 @@
+-- 会议聊天持久化：私聊、编辑与删除、回复话题
+
+-- 会议聊天消息表（chat.store 为 postgres 时使用；删除时保留墓碑：清空内容并记录 deleted_at）
+CREATE TABLE IF NOT EXISTS meeting_chat_messages (
+    id VARCHAR(36) PRIMARY KEY,
+    meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    username VARCHAR(100),
+    to_user_id INTEGER REFERENCES users(id), -- 私聊收件人，NULL 为公开消息
+    reply_to_id VARCHAR(36), -- 所属话题的首条消息
+    content TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_chat_messages_meeting_created ON meeting_chat_messages(meeting_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_meeting_chat_messages_to_user_id ON meeting_chat_messages(to_user_id);
+CREATE INDEX IF NOT EXISTS idx_meeting_chat_messages_reply_to_id ON meeting_chat_messages(reply_to_id);
@@ -92,6 +92,11 @@ func createMongoIndexes(ctx context.Context, db *mongo.Database) error {
 				"message_type": 1,
 			},
 		},
+		{
+			Keys: map[string]interface{}{
+				"reply_to_id": 1,
+			},
+		},
 	}
 	if _, err := chatCollection.Indexes().CreateMany(ctx, chatIndexes); err != nil {
 		return fmt.Errorf("failed to create chat_messages indexes: %w", err)
@@ -160,6 +165,10 @@ type ChatMessage struct {
 	FileInfo    map[string]interface{} `bson:"file_info,omitempty" json:"file_info,omitempty"`
 	Timestamp   time.Time              `bson:"timestamp" json:"timestamp"`
 	Metadata    map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	ToUserID    string                 `bson:"to_user_id,omitempty" json:"to_user_id,omitempty"`   // 私聊对象，公开消息不设置
+	ReplyToID   string                 `bson:"reply_to_id,omitempty" json:"reply_to_id,omitempty"` // 所属话题的首条消息
+	EditedAt    *time.Time             `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	DeletedAt   *time.Time             `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 }
 
 // AIAnalysisResult AI分析结果文档结构
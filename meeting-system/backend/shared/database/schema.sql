@@ -48,6 +48,7 @@ CREATE TABLE IF NOT EXISTS meetings (
     password VARCHAR(50),
     recording_url VARCHAR(500),
     settings JSONB, -- 会议设置
+    parent_meeting_id INTEGER REFERENCES meetings(id) ON DELETE CASCADE, -- 分组讨论房间所属的主会议
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
@@ -59,6 +60,7 @@ CREATE INDEX IF NOT EXISTS idx_meetings_status ON meetings(status);
 CREATE INDEX IF NOT EXISTS idx_meetings_start_time ON meetings(start_time);
 CREATE INDEX IF NOT EXISTS idx_meetings_end_time ON meetings(end_time);
 CREATE INDEX IF NOT EXISTS idx_meetings_deleted_at ON meetings(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_meetings_parent_meeting_id ON meetings(parent_meeting_id);
 
 -- 会议参与者表
 CREATE TABLE IF NOT EXISTS meeting_participants (
@@ -66,7 +68,7 @@ CREATE TABLE IF NOT EXISTS meeting_participants (
     meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
     user_id INTEGER NOT NULL REFERENCES users(id),
     role INTEGER DEFAULT 1, -- 1:参与者, 2:主持人, 3:演示者
-    status INTEGER DEFAULT 1, -- 1:已邀请, 2:已加入, 3:已离开, 4:已拒绝
+    status INTEGER DEFAULT 1, -- 1:已邀请, 2:已加入, 3:已离开, 4:已拒绝, 5:等候中, 6:已封禁
     joined_at TIMESTAMP,
     left_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -185,6 +187,87 @@ CREATE INDEX IF NOT EXISTS idx_signaling_messages_from_user_id ON signaling_mess
 CREATE INDEX IF NOT EXISTS idx_signaling_messages_to_user_id ON signaling_messages(to_user_id);
 CREATE INDEX IF NOT EXISTS idx_signaling_messages_deleted_at ON signaling_messages(deleted_at);
 
+-- 会议投票表
+CREATE TABLE IF NOT EXISTS polls (
+    id SERIAL PRIMARY KEY,
+    meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    creator_id INTEGER NOT NULL REFERENCES users(id),
+    question VARCHAR(500) NOT NULL,
+    multiple_choice BOOLEAN DEFAULT FALSE,
+    anonymous BOOLEAN DEFAULT FALSE,
+    results_visibility VARCHAR(16) DEFAULT 'live', -- live:实时公开, hidden:结束后公开
+    status VARCHAR(16) DEFAULT 'open', -- open, closed
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_polls_meeting_id ON polls(meeting_id);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INTEGER DEFAULT 0,
+    text VARCHAR(200) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_options_poll_id ON poll_options(poll_id);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    option_id INTEGER NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_votes_poll_user_option ON poll_votes(poll_id, user_id, option_id);
+
+-- 会议问答表
+CREATE TABLE IF NOT EXISTS meeting_questions (
+    id SERIAL PRIMARY KEY,
+    meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    username VARCHAR(100),
+    text TEXT NOT NULL,
+    status VARCHAR(16) DEFAULT 'open', -- pending:待审核, open:待回答, answered:已回答, dismissed:已忽略
+    upvotes INTEGER DEFAULT 0,
+    answer TEXT,
+    answered_by INTEGER REFERENCES users(id),
+    answered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_questions_meeting_id ON meeting_questions(meeting_id);
+
+CREATE TABLE IF NOT EXISTS question_upvotes (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES meeting_questions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_upvotes_question_user ON question_upvotes(question_id, user_id);
+
+-- 会议聊天消息表（chat.store 为 postgres 时使用；删除时保留墓碑：清空内容并记录 deleted_at）
+CREATE TABLE IF NOT EXISTS meeting_chat_messages (
+    id VARCHAR(36) PRIMARY KEY,
+    meeting_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    username VARCHAR(100),
+    to_user_id INTEGER REFERENCES users(id), -- 私聊收件人，NULL 为公开消息
+    reply_to_id VARCHAR(36), -- 所属话题的首条消息
+    content TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_chat_messages_meeting_created ON meeting_chat_messages(meeting_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_meeting_chat_messages_to_user_id ON meeting_chat_messages(to_user_id);
+CREATE INDEX IF NOT EXISTS idx_meeting_chat_messages_reply_to_id ON meeting_chat_messages(reply_to_id);
+
 -- AI分析任务表
 CREATE TABLE IF NOT EXISTS ai_tasks (
     id SERIAL PRIMARY KEY,
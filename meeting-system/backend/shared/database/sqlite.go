@@ -69,6 +69,7 @@ func InitSQLite(config config.DatabaseConfig) error {
 		&models.MeetingRecording{},
 		&models.MediaStream{},
 		&models.MeetingRoom{},
+		&models.OperationLog{},
 	)
 
 	if err != nil {
@@ -0,0 +1,2528 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v3.21.12
+// source: signaling.proto
+
+package grpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 信令消息类型（数值与 models.MessageType 相同）
+type SignalType int32
+
+const (
+	SignalType_SIGNAL_TYPE_UNSPECIFIED      SignalType = 0
+	SignalType_SIGNAL_TYPE_OFFER            SignalType = 1
+	SignalType_SIGNAL_TYPE_ANSWER           SignalType = 2
+	SignalType_SIGNAL_TYPE_ICE_CANDIDATE    SignalType = 3
+	SignalType_SIGNAL_TYPE_JOIN_ROOM        SignalType = 4
+	SignalType_SIGNAL_TYPE_LEAVE_ROOM       SignalType = 5
+	SignalType_SIGNAL_TYPE_USER_JOINED      SignalType = 6
+	SignalType_SIGNAL_TYPE_USER_LEFT        SignalType = 7
+	SignalType_SIGNAL_TYPE_CHAT             SignalType = 8
+	SignalType_SIGNAL_TYPE_SCREEN_SHARE     SignalType = 9
+	SignalType_SIGNAL_TYPE_MEDIA_CONTROL    SignalType = 10
+	SignalType_SIGNAL_TYPE_PING             SignalType = 11
+	SignalType_SIGNAL_TYPE_PONG             SignalType = 12
+	SignalType_SIGNAL_TYPE_ERROR            SignalType = 13
+	SignalType_SIGNAL_TYPE_ROOM_INFO        SignalType = 14
+	SignalType_SIGNAL_TYPE_AI_LIVE_CLAIM    SignalType = 15
+	SignalType_SIGNAL_TYPE_AI_LIVE_STATUS   SignalType = 16
+	SignalType_SIGNAL_TYPE_AI_LIVE_RESULT   SignalType = 17
+	SignalType_SIGNAL_TYPE_ICE_RESTART      SignalType = 18
+	SignalType_SIGNAL_TYPE_AI_STREAM_RESULT SignalType = 19
+	SignalType_SIGNAL_TYPE_MODERATE_MEDIA   SignalType = 20
+	SignalType_SIGNAL_TYPE_MEDIA_MODERATED  SignalType = 21
+	SignalType_SIGNAL_TYPE_SESSION_RESUMED  SignalType = 22
+	SignalType_SIGNAL_TYPE_LOBBY_STATUS     SignalType = 23
+	SignalType_SIGNAL_TYPE_LOBBY_UPDATE     SignalType = 24
+	SignalType_SIGNAL_TYPE_LOBBY_ACTION     SignalType = 25
+	SignalType_SIGNAL_TYPE_HOST_CONTROL     SignalType = 26
+	SignalType_SIGNAL_TYPE_RAISE_HAND       SignalType = 27
+	SignalType_SIGNAL_TYPE_REACTION         SignalType = 28
+	SignalType_SIGNAL_TYPE_FEEDBACK         SignalType = 29
+	SignalType_SIGNAL_TYPE_BREAKOUT         SignalType = 30
+	SignalType_SIGNAL_TYPE_POLL             SignalType = 31
+	SignalType_SIGNAL_TYPE_QUESTION         SignalType = 32
+	SignalType_SIGNAL_TYPE_CHAT_HISTORY     SignalType = 33
+)
+
+// Enum value maps for SignalType.
+var (
+	SignalType_name = map[int32]string{
+		0:  "SIGNAL_TYPE_UNSPECIFIED",
+		1:  "SIGNAL_TYPE_OFFER",
+		2:  "SIGNAL_TYPE_ANSWER",
+		3:  "SIGNAL_TYPE_ICE_CANDIDATE",
+		4:  "SIGNAL_TYPE_JOIN_ROOM",
+		5:  "SIGNAL_TYPE_LEAVE_ROOM",
+		6:  "SIGNAL_TYPE_USER_JOINED",
+		7:  "SIGNAL_TYPE_USER_LEFT",
+		8:  "SIGNAL_TYPE_CHAT",
+		9:  "SIGNAL_TYPE_SCREEN_SHARE",
+		10: "SIGNAL_TYPE_MEDIA_CONTROL",
+		11: "SIGNAL_TYPE_PING",
+		12: "SIGNAL_TYPE_PONG",
+		13: "SIGNAL_TYPE_ERROR",
+		14: "SIGNAL_TYPE_ROOM_INFO",
+		15: "SIGNAL_TYPE_AI_LIVE_CLAIM",
+		16: "SIGNAL_TYPE_AI_LIVE_STATUS",
+		17: "SIGNAL_TYPE_AI_LIVE_RESULT",
+		18: "SIGNAL_TYPE_ICE_RESTART",
+		19: "SIGNAL_TYPE_AI_STREAM_RESULT",
+		20: "SIGNAL_TYPE_MODERATE_MEDIA",
+		21: "SIGNAL_TYPE_MEDIA_MODERATED",
+		22: "SIGNAL_TYPE_SESSION_RESUMED",
+		23: "SIGNAL_TYPE_LOBBY_STATUS",
+		24: "SIGNAL_TYPE_LOBBY_UPDATE",
+		25: "SIGNAL_TYPE_LOBBY_ACTION",
+		26: "SIGNAL_TYPE_HOST_CONTROL",
+		27: "SIGNAL_TYPE_RAISE_HAND",
+		28: "SIGNAL_TYPE_REACTION",
+		29: "SIGNAL_TYPE_FEEDBACK",
+		30: "SIGNAL_TYPE_BREAKOUT",
+		31: "SIGNAL_TYPE_POLL",
+		32: "SIGNAL_TYPE_QUESTION",
+		33: "SIGNAL_TYPE_CHAT_HISTORY",
+	}
+	SignalType_value = map[string]int32{
+		"SIGNAL_TYPE_UNSPECIFIED":      0,
+		"SIGNAL_TYPE_OFFER":            1,
+		"SIGNAL_TYPE_ANSWER":           2,
+		"SIGNAL_TYPE_ICE_CANDIDATE":    3,
+		"SIGNAL_TYPE_JOIN_ROOM":        4,
+		"SIGNAL_TYPE_LEAVE_ROOM":       5,
+		"SIGNAL_TYPE_USER_JOINED":      6,
+		"SIGNAL_TYPE_USER_LEFT":        7,
+		"SIGNAL_TYPE_CHAT":             8,
+		"SIGNAL_TYPE_SCREEN_SHARE":     9,
+		"SIGNAL_TYPE_MEDIA_CONTROL":    10,
+		"SIGNAL_TYPE_PING":             11,
+		"SIGNAL_TYPE_PONG":             12,
+		"SIGNAL_TYPE_ERROR":            13,
+		"SIGNAL_TYPE_ROOM_INFO":        14,
+		"SIGNAL_TYPE_AI_LIVE_CLAIM":    15,
+		"SIGNAL_TYPE_AI_LIVE_STATUS":   16,
+		"SIGNAL_TYPE_AI_LIVE_RESULT":   17,
+		"SIGNAL_TYPE_ICE_RESTART":      18,
+		"SIGNAL_TYPE_AI_STREAM_RESULT": 19,
+		"SIGNAL_TYPE_MODERATE_MEDIA":   20,
+		"SIGNAL_TYPE_MEDIA_MODERATED":  21,
+		"SIGNAL_TYPE_SESSION_RESUMED":  22,
+		"SIGNAL_TYPE_LOBBY_STATUS":     23,
+		"SIGNAL_TYPE_LOBBY_UPDATE":     24,
+		"SIGNAL_TYPE_LOBBY_ACTION":     25,
+		"SIGNAL_TYPE_HOST_CONTROL":     26,
+		"SIGNAL_TYPE_RAISE_HAND":       27,
+		"SIGNAL_TYPE_REACTION":         28,
+		"SIGNAL_TYPE_FEEDBACK":         29,
+		"SIGNAL_TYPE_BREAKOUT":         30,
+		"SIGNAL_TYPE_POLL":             31,
+		"SIGNAL_TYPE_QUESTION":         32,
+		"SIGNAL_TYPE_CHAT_HISTORY":     33,
+	}
+)
+
+func (x SignalType) Enum() *SignalType {
+	p := new(SignalType)
+	*p = x
+	return p
+}
+
+func (x SignalType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SignalType) Descriptor() protoreflect.EnumDescriptor {
+	return file_signaling_proto_enumTypes[0].Descriptor()
+}
+
+func (SignalType) Type() protoreflect.EnumType {
+	return &file_signaling_proto_enumTypes[0]
+}
+
+func (x SignalType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SignalType.Descriptor instead.
+func (SignalType) EnumDescriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{0}
+}
+
+// 信令消息封装
+type SignalEnvelope struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type       SignalType             `protobuf:"varint,2,opt,name=type,proto3,enum=grpc.SignalType" json:"type,omitempty"`
+	FromUserId uint32                 `protobuf:"varint,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`
+	ToUserId   *uint32                `protobuf:"varint,4,opt,name=to_user_id,json=toUserId,proto3,oneof" json:"to_user_id,omitempty"`
+	MeetingId  uint32                 `protobuf:"varint,5,opt,name=meeting_id,json=meetingId,proto3" json:"meeting_id,omitempty"`
+	SessionId  string                 `protobuf:"bytes,6,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PeerId     string                 `protobuf:"bytes,7,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Timestamp  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Seq        uint64                 `protobuf:"varint,9,opt,name=seq,proto3" json:"seq,omitempty"` // 服务端下发消息的会话内序号
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*SignalEnvelope_Sdp
+	//	*SignalEnvelope_IceCandidate
+	//	*SignalEnvelope_Chat
+	//	*SignalEnvelope_Participant
+	//	*SignalEnvelope_RoomInfo
+	//	*SignalEnvelope_Error
+	//	*SignalEnvelope_MediaControl
+	//	*SignalEnvelope_AiLiveClaim
+	//	*SignalEnvelope_AiLiveStatus
+	//	*SignalEnvelope_AiLiveResult
+	//	*SignalEnvelope_ModerateMedia
+	//	*SignalEnvelope_MediaModeration
+	//	*SignalEnvelope_ScreenShareRequest
+	//	*SignalEnvelope_ScreenShareEvent
+	//	*SignalEnvelope_IceRestart
+	//	*SignalEnvelope_SessionResumed
+	//	*SignalEnvelope_JsonPayload
+	Payload       isSignalEnvelope_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalEnvelope) Reset() {
+	*x = SignalEnvelope{}
+	mi := &file_signaling_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalEnvelope) ProtoMessage() {}
+
+func (x *SignalEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalEnvelope.ProtoReflect.Descriptor instead.
+func (*SignalEnvelope) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SignalEnvelope) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SignalEnvelope) GetType() SignalType {
+	if x != nil {
+		return x.Type
+	}
+	return SignalType_SIGNAL_TYPE_UNSPECIFIED
+}
+
+func (x *SignalEnvelope) GetFromUserId() uint32 {
+	if x != nil {
+		return x.FromUserId
+	}
+	return 0
+}
+
+func (x *SignalEnvelope) GetToUserId() uint32 {
+	if x != nil && x.ToUserId != nil {
+		return *x.ToUserId
+	}
+	return 0
+}
+
+func (x *SignalEnvelope) GetMeetingId() uint32 {
+	if x != nil {
+		return x.MeetingId
+	}
+	return 0
+}
+
+func (x *SignalEnvelope) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SignalEnvelope) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SignalEnvelope) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *SignalEnvelope) GetPayload() isSignalEnvelope_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetSdp() *SignalSessionDescription {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_Sdp); ok {
+			return x.Sdp
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetIceCandidate() *SignalICECandidate {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_IceCandidate); ok {
+			return x.IceCandidate
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetChat() *SignalChat {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_Chat); ok {
+			return x.Chat
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetParticipant() *SignalParticipant {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_Participant); ok {
+			return x.Participant
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetRoomInfo() *SignalRoomInfo {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_RoomInfo); ok {
+			return x.RoomInfo
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetError() *SignalError {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetMediaControl() *SignalMediaControl {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_MediaControl); ok {
+			return x.MediaControl
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetAiLiveClaim() *SignalAILiveClaim {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_AiLiveClaim); ok {
+			return x.AiLiveClaim
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetAiLiveStatus() *SignalAILiveStatus {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_AiLiveStatus); ok {
+			return x.AiLiveStatus
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetAiLiveResult() *SignalAILiveResult {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_AiLiveResult); ok {
+			return x.AiLiveResult
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetModerateMedia() *SignalModerateMedia {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_ModerateMedia); ok {
+			return x.ModerateMedia
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetMediaModeration() *SignalMediaModeration {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_MediaModeration); ok {
+			return x.MediaModeration
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetScreenShareRequest() *SignalScreenShareRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_ScreenShareRequest); ok {
+			return x.ScreenShareRequest
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetScreenShareEvent() *SignalScreenShareEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_ScreenShareEvent); ok {
+			return x.ScreenShareEvent
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetIceRestart() *SignalICERestart {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_IceRestart); ok {
+			return x.IceRestart
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetSessionResumed() *SignalSessionResumed {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_SessionResumed); ok {
+			return x.SessionResumed
+		}
+	}
+	return nil
+}
+
+func (x *SignalEnvelope) GetJsonPayload() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*SignalEnvelope_JsonPayload); ok {
+			return x.JsonPayload
+		}
+	}
+	return nil
+}
+
+type isSignalEnvelope_Payload interface {
+	isSignalEnvelope_Payload()
+}
+
+type SignalEnvelope_Sdp struct {
+	Sdp *SignalSessionDescription `protobuf:"bytes,10,opt,name=sdp,proto3,oneof"` // offer / answer
+}
+
+type SignalEnvelope_IceCandidate struct {
+	IceCandidate *SignalICECandidate `protobuf:"bytes,11,opt,name=ice_candidate,json=iceCandidate,proto3,oneof"`
+}
+
+type SignalEnvelope_Chat struct {
+	Chat *SignalChat `protobuf:"bytes,12,opt,name=chat,proto3,oneof"`
+}
+
+type SignalEnvelope_Participant struct {
+	Participant *SignalParticipant `protobuf:"bytes,13,opt,name=participant,proto3,oneof"` // user joined / left
+}
+
+type SignalEnvelope_RoomInfo struct {
+	RoomInfo *SignalRoomInfo `protobuf:"bytes,14,opt,name=room_info,json=roomInfo,proto3,oneof"`
+}
+
+type SignalEnvelope_Error struct {
+	Error *SignalError `protobuf:"bytes,15,opt,name=error,proto3,oneof"`
+}
+
+type SignalEnvelope_MediaControl struct {
+	MediaControl *SignalMediaControl `protobuf:"bytes,16,opt,name=media_control,json=mediaControl,proto3,oneof"`
+}
+
+type SignalEnvelope_AiLiveClaim struct {
+	AiLiveClaim *SignalAILiveClaim `protobuf:"bytes,17,opt,name=ai_live_claim,json=aiLiveClaim,proto3,oneof"`
+}
+
+type SignalEnvelope_AiLiveStatus struct {
+	AiLiveStatus *SignalAILiveStatus `protobuf:"bytes,18,opt,name=ai_live_status,json=aiLiveStatus,proto3,oneof"`
+}
+
+type SignalEnvelope_AiLiveResult struct {
+	AiLiveResult *SignalAILiveResult `protobuf:"bytes,19,opt,name=ai_live_result,json=aiLiveResult,proto3,oneof"`
+}
+
+type SignalEnvelope_ModerateMedia struct {
+	ModerateMedia *SignalModerateMedia `protobuf:"bytes,20,opt,name=moderate_media,json=moderateMedia,proto3,oneof"`
+}
+
+type SignalEnvelope_MediaModeration struct {
+	MediaModeration *SignalMediaModeration `protobuf:"bytes,21,opt,name=media_moderation,json=mediaModeration,proto3,oneof"`
+}
+
+type SignalEnvelope_ScreenShareRequest struct {
+	ScreenShareRequest *SignalScreenShareRequest `protobuf:"bytes,22,opt,name=screen_share_request,json=screenShareRequest,proto3,oneof"`
+}
+
+type SignalEnvelope_ScreenShareEvent struct {
+	ScreenShareEvent *SignalScreenShareEvent `protobuf:"bytes,23,opt,name=screen_share_event,json=screenShareEvent,proto3,oneof"`
+}
+
+type SignalEnvelope_IceRestart struct {
+	IceRestart *SignalICERestart `protobuf:"bytes,24,opt,name=ice_restart,json=iceRestart,proto3,oneof"`
+}
+
+type SignalEnvelope_SessionResumed struct {
+	SessionResumed *SignalSessionResumed `protobuf:"bytes,25,opt,name=session_resumed,json=sessionResumed,proto3,oneof"`
+}
+
+type SignalEnvelope_JsonPayload struct {
+	JsonPayload []byte `protobuf:"bytes,100,opt,name=json_payload,json=jsonPayload,proto3,oneof"` // 尚无类型化结构的载荷（如 AI 流式结果）按 JSON 透传
+}
+
+func (*SignalEnvelope_Sdp) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_IceCandidate) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_Chat) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_Participant) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_RoomInfo) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_Error) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_MediaControl) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_AiLiveClaim) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_AiLiveStatus) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_AiLiveResult) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_ModerateMedia) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_MediaModeration) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_ScreenShareRequest) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_ScreenShareEvent) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_IceRestart) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_SessionResumed) isSignalEnvelope_Payload() {}
+
+func (*SignalEnvelope_JsonPayload) isSignalEnvelope_Payload() {}
+
+type SignalSessionDescription struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sdp           string                 `protobuf:"bytes,1,opt,name=sdp,proto3" json:"sdp,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // "offer" / "answer"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalSessionDescription) Reset() {
+	*x = SignalSessionDescription{}
+	mi := &file_signaling_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalSessionDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalSessionDescription) ProtoMessage() {}
+
+func (x *SignalSessionDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalSessionDescription.ProtoReflect.Descriptor instead.
+func (*SignalSessionDescription) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SignalSessionDescription) GetSdp() string {
+	if x != nil {
+		return x.Sdp
+	}
+	return ""
+}
+
+func (x *SignalSessionDescription) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type SignalICECandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidate     string                 `protobuf:"bytes,1,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	SdpMid        string                 `protobuf:"bytes,2,opt,name=sdp_mid,json=sdpMid,proto3" json:"sdp_mid,omitempty"`
+	SdpMlineIndex int32                  `protobuf:"varint,3,opt,name=sdp_mline_index,json=sdpMlineIndex,proto3" json:"sdp_mline_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalICECandidate) Reset() {
+	*x = SignalICECandidate{}
+	mi := &file_signaling_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalICECandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalICECandidate) ProtoMessage() {}
+
+func (x *SignalICECandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalICECandidate.ProtoReflect.Descriptor instead.
+func (*SignalICECandidate) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SignalICECandidate) GetCandidate() string {
+	if x != nil {
+		return x.Candidate
+	}
+	return ""
+}
+
+func (x *SignalICECandidate) GetSdpMid() string {
+	if x != nil {
+		return x.SdpMid
+	}
+	return ""
+}
+
+func (x *SignalICECandidate) GetSdpMlineIndex() int32 {
+	if x != nil {
+		return x.SdpMlineIndex
+	}
+	return 0
+}
+
+type SignalChat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	UserId        uint32                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	MeetingId     uint32                 `protobuf:"varint,4,opt,name=meeting_id,json=meetingId,proto3" json:"meeting_id,omitempty"`
+	Id            string                 `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	Action        string                 `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`                          // send（默认）、edit、delete
+	ToUserId      uint32                 `protobuf:"varint,7,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`   // 私聊对象，0 表示发给全员
+	ReplyToId     string                 `protobuf:"bytes,8,opt,name=reply_to_id,json=replyToId,proto3" json:"reply_to_id,omitempty"` // 所属话题的首条消息
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	EditedAt      *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	Deleted       bool                   `protobuf:"varint,11,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalChat) Reset() {
+	*x = SignalChat{}
+	mi := &file_signaling_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalChat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalChat) ProtoMessage() {}
+
+func (x *SignalChat) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalChat.ProtoReflect.Descriptor instead.
+func (*SignalChat) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SignalChat) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SignalChat) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalChat) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SignalChat) GetMeetingId() uint32 {
+	if x != nil {
+		return x.MeetingId
+	}
+	return 0
+}
+
+func (x *SignalChat) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SignalChat) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *SignalChat) GetToUserId() uint32 {
+	if x != nil {
+		return x.ToUserId
+	}
+	return 0
+}
+
+func (x *SignalChat) GetReplyToId() string {
+	if x != nil {
+		return x.ReplyToId
+	}
+	return ""
+}
+
+func (x *SignalChat) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SignalChat) GetEditedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EditedAt
+	}
+	return nil
+}
+
+func (x *SignalChat) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type SignalParticipant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	PeerId        string                 `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	MeetingId     uint32                 `protobuf:"varint,4,opt,name=meeting_id,json=meetingId,proto3" json:"meeting_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalParticipant) Reset() {
+	*x = SignalParticipant{}
+	mi := &file_signaling_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalParticipant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalParticipant) ProtoMessage() {}
+
+func (x *SignalParticipant) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalParticipant.ProtoReflect.Descriptor instead.
+func (*SignalParticipant) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SignalParticipant) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalParticipant) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SignalParticipant) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SignalParticipant) GetMeetingId() uint32 {
+	if x != nil {
+		return x.MeetingId
+	}
+	return 0
+}
+
+type SignalRoomParticipant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PeerId        string                 `protobuf:"bytes,4,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	JoinedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
+	LastActiveAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"`
+	IsSelf        bool                   `protobuf:"varint,7,opt,name=is_self,json=isSelf,proto3" json:"is_self,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalRoomParticipant) Reset() {
+	*x = SignalRoomParticipant{}
+	mi := &file_signaling_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalRoomParticipant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalRoomParticipant) ProtoMessage() {}
+
+func (x *SignalRoomParticipant) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalRoomParticipant.ProtoReflect.Descriptor instead.
+func (*SignalRoomParticipant) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SignalRoomParticipant) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalRoomParticipant) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SignalRoomParticipant) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SignalRoomParticipant) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SignalRoomParticipant) GetJoinedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.JoinedAt
+	}
+	return nil
+}
+
+func (x *SignalRoomParticipant) GetLastActiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActiveAt
+	}
+	return nil
+}
+
+func (x *SignalRoomParticipant) GetIsSelf() bool {
+	if x != nil {
+		return x.IsSelf
+	}
+	return false
+}
+
+type SignalICEServer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Urls          string                 `protobuf:"bytes,1,opt,name=urls,proto3" json:"urls,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Credential    string                 `protobuf:"bytes,3,opt,name=credential,proto3" json:"credential,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalICEServer) Reset() {
+	*x = SignalICEServer{}
+	mi := &file_signaling_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalICEServer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalICEServer) ProtoMessage() {}
+
+func (x *SignalICEServer) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalICEServer.ProtoReflect.Descriptor instead.
+func (*SignalICEServer) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SignalICEServer) GetUrls() string {
+	if x != nil {
+		return x.Urls
+	}
+	return ""
+}
+
+func (x *SignalICEServer) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SignalICEServer) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+type SignalRoomInfo struct {
+	state            protoimpl.MessageState    `protogen:"open.v1"`
+	MeetingId        uint32                    `protobuf:"varint,1,opt,name=meeting_id,json=meetingId,proto3" json:"meeting_id,omitempty"`
+	ParticipantCount int32                     `protobuf:"varint,2,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
+	SessionId        string                    `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PeerId           string                    `protobuf:"bytes,4,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	IceServers       []*SignalICEServer        `protobuf:"bytes,5,rep,name=ice_servers,json=iceServers,proto3" json:"ice_servers,omitempty"`
+	Participants     []*SignalRoomParticipant  `protobuf:"bytes,6,rep,name=participants,proto3" json:"participants,omitempty"`
+	AiLive           *SignalAILiveStatus       `protobuf:"bytes,7,opt,name=ai_live,json=aiLive,proto3" json:"ai_live,omitempty"`
+	Moderation       []*SignalMediaModeration  `protobuf:"bytes,8,rep,name=moderation,proto3" json:"moderation,omitempty"`
+	ScreenShares     []*SignalScreenShareState `protobuf:"bytes,9,rep,name=screen_shares,json=screenShares,proto3" json:"screen_shares,omitempty"`
+	ResumeToken      string                    `protobuf:"bytes,10,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	RaisedHands      []*SignalRaisedHand       `protobuf:"bytes,11,rep,name=raised_hands,json=raisedHands,proto3" json:"raised_hands,omitempty"`
+	Feedback         []*SignalFeedbackState    `protobuf:"bytes,12,rep,name=feedback,proto3" json:"feedback,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SignalRoomInfo) Reset() {
+	*x = SignalRoomInfo{}
+	mi := &file_signaling_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalRoomInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalRoomInfo) ProtoMessage() {}
+
+func (x *SignalRoomInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalRoomInfo.ProtoReflect.Descriptor instead.
+func (*SignalRoomInfo) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SignalRoomInfo) GetMeetingId() uint32 {
+	if x != nil {
+		return x.MeetingId
+	}
+	return 0
+}
+
+func (x *SignalRoomInfo) GetParticipantCount() int32 {
+	if x != nil {
+		return x.ParticipantCount
+	}
+	return 0
+}
+
+func (x *SignalRoomInfo) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SignalRoomInfo) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SignalRoomInfo) GetIceServers() []*SignalICEServer {
+	if x != nil {
+		return x.IceServers
+	}
+	return nil
+}
+
+func (x *SignalRoomInfo) GetParticipants() []*SignalRoomParticipant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+func (x *SignalRoomInfo) GetAiLive() *SignalAILiveStatus {
+	if x != nil {
+		return x.AiLive
+	}
+	return nil
+}
+
+func (x *SignalRoomInfo) GetModeration() []*SignalMediaModeration {
+	if x != nil {
+		return x.Moderation
+	}
+	return nil
+}
+
+func (x *SignalRoomInfo) GetScreenShares() []*SignalScreenShareState {
+	if x != nil {
+		return x.ScreenShares
+	}
+	return nil
+}
+
+func (x *SignalRoomInfo) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *SignalRoomInfo) GetRaisedHands() []*SignalRaisedHand {
+	if x != nil {
+		return x.RaisedHands
+	}
+	return nil
+}
+
+func (x *SignalRoomInfo) GetFeedback() []*SignalFeedbackState {
+	if x != nil {
+		return x.Feedback
+	}
+	return nil
+}
+
+type SignalError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Details       string                 `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalError) Reset() {
+	*x = SignalError{}
+	mi := &file_signaling_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalError) ProtoMessage() {}
+
+func (x *SignalError) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalError.ProtoReflect.Descriptor instead.
+func (*SignalError) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SignalError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SignalError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SignalError) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+type SignalMediaControl struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	MediaType     string                 `protobuf:"bytes,2,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+	UserId        uint32                 `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PeerId        string                 `protobuf:"bytes,4,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalMediaControl) Reset() {
+	*x = SignalMediaControl{}
+	mi := &file_signaling_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalMediaControl) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalMediaControl) ProtoMessage() {}
+
+func (x *SignalMediaControl) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalMediaControl.ProtoReflect.Descriptor instead.
+func (*SignalMediaControl) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SignalMediaControl) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *SignalMediaControl) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+func (x *SignalMediaControl) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalMediaControl) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+type SignalAILiveClaim struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enable        bool                   `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalAILiveClaim) Reset() {
+	*x = SignalAILiveClaim{}
+	mi := &file_signaling_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalAILiveClaim) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalAILiveClaim) ProtoMessage() {}
+
+func (x *SignalAILiveClaim) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalAILiveClaim.ProtoReflect.Descriptor instead.
+func (*SignalAILiveClaim) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SignalAILiveClaim) GetEnable() bool {
+	if x != nil {
+		return x.Enable
+	}
+	return false
+}
+
+type SignalAILiveStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Enabled         bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	LeaderUserId    uint32                 `protobuf:"varint,2,opt,name=leader_user_id,json=leaderUserId,proto3" json:"leader_user_id,omitempty"`
+	LeaderSessionId string                 `protobuf:"bytes,3,opt,name=leader_session_id,json=leaderSessionId,proto3" json:"leader_session_id,omitempty"`
+	LeaderUsername  string                 `protobuf:"bytes,4,opt,name=leader_username,json=leaderUsername,proto3" json:"leader_username,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SignalAILiveStatus) Reset() {
+	*x = SignalAILiveStatus{}
+	mi := &file_signaling_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalAILiveStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalAILiveStatus) ProtoMessage() {}
+
+func (x *SignalAILiveStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalAILiveStatus.ProtoReflect.Descriptor instead.
+func (*SignalAILiveStatus) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SignalAILiveStatus) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SignalAILiveStatus) GetLeaderUserId() uint32 {
+	if x != nil {
+		return x.LeaderUserId
+	}
+	return 0
+}
+
+func (x *SignalAILiveStatus) GetLeaderSessionId() string {
+	if x != nil {
+		return x.LeaderSessionId
+	}
+	return ""
+}
+
+func (x *SignalAILiveStatus) GetLeaderUsername() string {
+	if x != nil {
+		return x.LeaderUsername
+	}
+	return ""
+}
+
+func (x *SignalAILiveStatus) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type SignalAILiveTag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalAILiveTag) Reset() {
+	*x = SignalAILiveTag{}
+	mi := &file_signaling_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalAILiveTag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalAILiveTag) ProtoMessage() {}
+
+func (x *SignalAILiveTag) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalAILiveTag.ProtoReflect.Descriptor instead.
+func (*SignalAILiveTag) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SignalAILiveTag) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SignalAILiveTag) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type SignalAILiveResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LineId        string                 `protobuf:"bytes,1,opt,name=line_id,json=lineId,proto3" json:"line_id,omitempty"`
+	SpeakerKey    string                 `protobuf:"bytes,2,opt,name=speaker_key,json=speakerKey,proto3" json:"speaker_key,omitempty"`
+	SpeakerLabel  string                 `protobuf:"bytes,3,opt,name=speaker_label,json=speakerLabel,proto3" json:"speaker_label,omitempty"`
+	TimestampMs   int64                  `protobuf:"varint,4,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	Text          string                 `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	Tags          []*SignalAILiveTag     `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalAILiveResult) Reset() {
+	*x = SignalAILiveResult{}
+	mi := &file_signaling_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalAILiveResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalAILiveResult) ProtoMessage() {}
+
+func (x *SignalAILiveResult) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalAILiveResult.ProtoReflect.Descriptor instead.
+func (*SignalAILiveResult) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SignalAILiveResult) GetLineId() string {
+	if x != nil {
+		return x.LineId
+	}
+	return ""
+}
+
+func (x *SignalAILiveResult) GetSpeakerKey() string {
+	if x != nil {
+		return x.SpeakerKey
+	}
+	return ""
+}
+
+func (x *SignalAILiveResult) GetSpeakerLabel() string {
+	if x != nil {
+		return x.SpeakerLabel
+	}
+	return ""
+}
+
+func (x *SignalAILiveResult) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *SignalAILiveResult) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SignalAILiveResult) GetTags() []*SignalAILiveTag {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type SignalModerateMedia struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetUserId  uint32                 `protobuf:"varint,1,opt,name=target_user_id,json=targetUserId,proto3" json:"target_user_id,omitempty"`
+	MediaType     string                 `protobuf:"bytes,2,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+	Muted         bool                   `protobuf:"varint,3,opt,name=muted,proto3" json:"muted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalModerateMedia) Reset() {
+	*x = SignalModerateMedia{}
+	mi := &file_signaling_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalModerateMedia) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalModerateMedia) ProtoMessage() {}
+
+func (x *SignalModerateMedia) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalModerateMedia.ProtoReflect.Descriptor instead.
+func (*SignalModerateMedia) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SignalModerateMedia) GetTargetUserId() uint32 {
+	if x != nil {
+		return x.TargetUserId
+	}
+	return 0
+}
+
+func (x *SignalModerateMedia) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+func (x *SignalModerateMedia) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+type SignalMediaModeration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MediaType     string                 `protobuf:"bytes,2,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+	Muted         bool                   `protobuf:"varint,3,opt,name=muted,proto3" json:"muted,omitempty"`
+	ByUserId      uint32                 `protobuf:"varint,4,opt,name=by_user_id,json=byUserId,proto3" json:"by_user_id,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalMediaModeration) Reset() {
+	*x = SignalMediaModeration{}
+	mi := &file_signaling_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalMediaModeration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalMediaModeration) ProtoMessage() {}
+
+func (x *SignalMediaModeration) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalMediaModeration.ProtoReflect.Descriptor instead.
+func (*SignalMediaModeration) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SignalMediaModeration) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalMediaModeration) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+func (x *SignalMediaModeration) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+func (x *SignalMediaModeration) GetByUserId() uint32 {
+	if x != nil {
+		return x.ByUserId
+	}
+	return 0
+}
+
+func (x *SignalMediaModeration) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type SignalScreenShareRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	StreamId      string                 `protobuf:"bytes,2,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	TrackId       string                 `protobuf:"bytes,3,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	ContentHint   string                 `protobuf:"bytes,4,opt,name=content_hint,json=contentHint,proto3" json:"content_hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalScreenShareRequest) Reset() {
+	*x = SignalScreenShareRequest{}
+	mi := &file_signaling_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalScreenShareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalScreenShareRequest) ProtoMessage() {}
+
+func (x *SignalScreenShareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalScreenShareRequest.ProtoReflect.Descriptor instead.
+func (*SignalScreenShareRequest) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SignalScreenShareRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *SignalScreenShareRequest) GetStreamId() string {
+	if x != nil {
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *SignalScreenShareRequest) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *SignalScreenShareRequest) GetContentHint() string {
+	if x != nil {
+		return x.ContentHint
+	}
+	return ""
+}
+
+type SignalScreenShareState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	PeerId        string                 `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	StreamId      string                 `protobuf:"bytes,4,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	TrackId       string                 `protobuf:"bytes,5,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	ContentHint   string                 `protobuf:"bytes,6,opt,name=content_hint,json=contentHint,proto3" json:"content_hint,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalScreenShareState) Reset() {
+	*x = SignalScreenShareState{}
+	mi := &file_signaling_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalScreenShareState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalScreenShareState) ProtoMessage() {}
+
+func (x *SignalScreenShareState) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalScreenShareState.ProtoReflect.Descriptor instead.
+func (*SignalScreenShareState) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SignalScreenShareState) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalScreenShareState) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SignalScreenShareState) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SignalScreenShareState) GetStreamId() string {
+	if x != nil {
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *SignalScreenShareState) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *SignalScreenShareState) GetContentHint() string {
+	if x != nil {
+		return x.ContentHint
+	}
+	return ""
+}
+
+func (x *SignalScreenShareState) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+type SignalRaisedHand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	RaisedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=raised_at,json=raisedAt,proto3" json:"raised_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalRaisedHand) Reset() {
+	*x = SignalRaisedHand{}
+	mi := &file_signaling_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalRaisedHand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalRaisedHand) ProtoMessage() {}
+
+func (x *SignalRaisedHand) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalRaisedHand.ProtoReflect.Descriptor instead.
+func (*SignalRaisedHand) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SignalRaisedHand) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalRaisedHand) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SignalRaisedHand) GetRaisedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RaisedAt
+	}
+	return nil
+}
+
+type SignalFeedbackState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Feedback      string                 `protobuf:"bytes,2,opt,name=feedback,proto3" json:"feedback,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalFeedbackState) Reset() {
+	*x = SignalFeedbackState{}
+	mi := &file_signaling_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalFeedbackState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalFeedbackState) ProtoMessage() {}
+
+func (x *SignalFeedbackState) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalFeedbackState.ProtoReflect.Descriptor instead.
+func (*SignalFeedbackState) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SignalFeedbackState) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SignalFeedbackState) GetFeedback() string {
+	if x != nil {
+		return x.Feedback
+	}
+	return ""
+}
+
+func (x *SignalFeedbackState) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type SignalScreenShareEvent struct {
+	state          protoimpl.MessageState  `protogen:"open.v1"`
+	Event          string                  `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Share          *SignalScreenShareState `protobuf:"bytes,2,opt,name=share,proto3" json:"share,omitempty"`
+	PreviousUserId uint32                  `protobuf:"varint,3,opt,name=previous_user_id,json=previousUserId,proto3" json:"previous_user_id,omitempty"`
+	ByUserId       uint32                  `protobuf:"varint,4,opt,name=by_user_id,json=byUserId,proto3" json:"by_user_id,omitempty"`
+	Exclusive      bool                    `protobuf:"varint,5,opt,name=exclusive,proto3" json:"exclusive,omitempty"`
+	Timestamp      *timestamppb.Timestamp  `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SignalScreenShareEvent) Reset() {
+	*x = SignalScreenShareEvent{}
+	mi := &file_signaling_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalScreenShareEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalScreenShareEvent) ProtoMessage() {}
+
+func (x *SignalScreenShareEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalScreenShareEvent.ProtoReflect.Descriptor instead.
+func (*SignalScreenShareEvent) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SignalScreenShareEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *SignalScreenShareEvent) GetShare() *SignalScreenShareState {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+func (x *SignalScreenShareEvent) GetPreviousUserId() uint32 {
+	if x != nil {
+		return x.PreviousUserId
+	}
+	return 0
+}
+
+func (x *SignalScreenShareEvent) GetByUserId() uint32 {
+	if x != nil {
+		return x.ByUserId
+	}
+	return 0
+}
+
+func (x *SignalScreenShareEvent) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *SignalScreenShareEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type SignalICERestart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	RoomId        string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	GracePeriodMs int64                  `protobuf:"varint,4,opt,name=grace_period_ms,json=gracePeriodMs,proto3" json:"grace_period_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalICERestart) Reset() {
+	*x = SignalICERestart{}
+	mi := &file_signaling_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalICERestart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalICERestart) ProtoMessage() {}
+
+func (x *SignalICERestart) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalICERestart.ProtoReflect.Descriptor instead.
+func (*SignalICERestart) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SignalICERestart) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SignalICERestart) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *SignalICERestart) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SignalICERestart) GetGracePeriodMs() int64 {
+	if x != nil {
+		return x.GracePeriodMs
+	}
+	return 0
+}
+
+type SignalSessionResumed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	ResumeToken   string                 `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	LastSeq       uint64                 `protobuf:"varint,3,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
+	Replayed      int32                  `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalSessionResumed) Reset() {
+	*x = SignalSessionResumed{}
+	mi := &file_signaling_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalSessionResumed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalSessionResumed) ProtoMessage() {}
+
+func (x *SignalSessionResumed) ProtoReflect() protoreflect.Message {
+	mi := &file_signaling_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalSessionResumed.ProtoReflect.Descriptor instead.
+func (*SignalSessionResumed) Descriptor() ([]byte, []int) {
+	return file_signaling_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SignalSessionResumed) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SignalSessionResumed) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *SignalSessionResumed) GetLastSeq() uint64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+func (x *SignalSessionResumed) GetReplayed() int32 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+var File_signaling_proto protoreflect.FileDescriptor
+
+const file_signaling_proto_rawDesc = "" +
+	"\n" +
+	"\x0fsignaling.proto\x12\x04grpc\x1a\x1fgoogle/protobuf/timestamp.proto\"\xdd\n" +
+	"\n" +
+	"\x0eSignalEnvelope\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x10.grpc.SignalTypeR\x04type\x12 \n" +
+	"\ffrom_user_id\x18\x03 \x01(\rR\n" +
+	"fromUserId\x12!\n" +
+	"\n" +
+	"to_user_id\x18\x04 \x01(\rH\x01R\btoUserId\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"meeting_id\x18\x05 \x01(\rR\tmeetingId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x06 \x01(\tR\tsessionId\x12\x17\n" +
+	"\apeer_id\x18\a \x01(\tR\x06peerId\x128\n" +
+	"\ttimestamp\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x10\n" +
+	"\x03seq\x18\t \x01(\x04R\x03seq\x122\n" +
+	"\x03sdp\x18\n" +
+	" \x01(\v2\x1e.grpc.SignalSessionDescriptionH\x00R\x03sdp\x12?\n" +
+	"\rice_candidate\x18\v \x01(\v2\x18.grpc.SignalICECandidateH\x00R\ficeCandidate\x12&\n" +
+	"\x04chat\x18\f \x01(\v2\x10.grpc.SignalChatH\x00R\x04chat\x12;\n" +
+	"\vparticipant\x18\r \x01(\v2\x17.grpc.SignalParticipantH\x00R\vparticipant\x123\n" +
+	"\troom_info\x18\x0e \x01(\v2\x14.grpc.SignalRoomInfoH\x00R\broomInfo\x12)\n" +
+	"\x05error\x18\x0f \x01(\v2\x11.grpc.SignalErrorH\x00R\x05error\x12?\n" +
+	"\rmedia_control\x18\x10 \x01(\v2\x18.grpc.SignalMediaControlH\x00R\fmediaControl\x12=\n" +
+	"\rai_live_claim\x18\x11 \x01(\v2\x17.grpc.SignalAILiveClaimH\x00R\vaiLiveClaim\x12@\n" +
+	"\x0eai_live_status\x18\x12 \x01(\v2\x18.grpc.SignalAILiveStatusH\x00R\faiLiveStatus\x12@\n" +
+	"\x0eai_live_result\x18\x13 \x01(\v2\x18.grpc.SignalAILiveResultH\x00R\faiLiveResult\x12B\n" +
+	"\x0emoderate_media\x18\x14 \x01(\v2\x19.grpc.SignalModerateMediaH\x00R\rmoderateMedia\x12H\n" +
+	"\x10media_moderation\x18\x15 \x01(\v2\x1b.grpc.SignalMediaModerationH\x00R\x0fmediaModeration\x12R\n" +
+	"\x14screen_share_request\x18\x16 \x01(\v2\x1e.grpc.SignalScreenShareRequestH\x00R\x12screenShareRequest\x12L\n" +
+	"\x12screen_share_event\x18\x17 \x01(\v2\x1c.grpc.SignalScreenShareEventH\x00R\x10screenShareEvent\x129\n" +
+	"\vice_restart\x18\x18 \x01(\v2\x16.grpc.SignalICERestartH\x00R\n" +
+	"iceRestart\x12E\n" +
+	"\x0fsession_resumed\x18\x19 \x01(\v2\x1a.grpc.SignalSessionResumedH\x00R\x0esessionResumed\x12#\n" +
+	"\fjson_payload\x18d \x01(\fH\x00R\vjsonPayloadB\t\n" +
+	"\apayloadB\r\n" +
+	"\v_to_user_id\"@\n" +
+	"\x18SignalSessionDescription\x12\x10\n" +
+	"\x03sdp\x18\x01 \x01(\tR\x03sdp\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"s\n" +
+	"\x12SignalICECandidate\x12\x1c\n" +
+	"\tcandidate\x18\x01 \x01(\tR\tcandidate\x12\x17\n" +
+	"\asdp_mid\x18\x02 \x01(\tR\x06sdpMid\x12&\n" +
+	"\x0fsdp_mline_index\x18\x03 \x01(\x05R\rsdpMlineIndex\"\xee\x02\n" +
+	"\n" +
+	"SignalChat\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\rR\x06userId\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x1d\n" +
+	"\n" +
+	"meeting_id\x18\x04 \x01(\rR\tmeetingId\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\tR\x02id\x12\x16\n" +
+	"\x06action\x18\x06 \x01(\tR\x06action\x12\x1c\n" +
+	"\n" +
+	"to_user_id\x18\a \x01(\rR\btoUserId\x12\x1e\n" +
+	"\vreply_to_id\x18\b \x01(\tR\treplyToId\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tedited_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\beditedAt\x12\x18\n" +
+	"\adeleted\x18\v \x01(\bR\adeleted\"\x80\x01\n" +
+	"\x11SignalParticipant\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x17\n" +
+	"\apeer_id\x18\x03 \x01(\tR\x06peerId\x12\x1d\n" +
+	"\n" +
+	"meeting_id\x18\x04 \x01(\rR\tmeetingId\"\x98\x02\n" +
+	"\x15SignalRoomParticipant\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x17\n" +
+	"\apeer_id\x18\x04 \x01(\tR\x06peerId\x127\n" +
+	"\tjoined_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bjoinedAt\x12@\n" +
+	"\x0elast_active_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\flastActiveAt\x12\x17\n" +
+	"\ais_self\x18\a \x01(\bR\x06isSelf\"a\n" +
+	"\x0fSignalICEServer\x12\x12\n" +
+	"\x04urls\x18\x01 \x01(\tR\x04urls\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x03 \x01(\tR\n" +
+	"credential\"\xd5\x04\n" +
+	"\x0eSignalRoomInfo\x12\x1d\n" +
+	"\n" +
+	"meeting_id\x18\x01 \x01(\rR\tmeetingId\x12+\n" +
+	"\x11participant_count\x18\x02 \x01(\x05R\x10participantCount\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x17\n" +
+	"\apeer_id\x18\x04 \x01(\tR\x06peerId\x126\n" +
+	"\vice_servers\x18\x05 \x03(\v2\x15.grpc.SignalICEServerR\n" +
+	"iceServers\x12?\n" +
+	"\fparticipants\x18\x06 \x03(\v2\x1b.grpc.SignalRoomParticipantR\fparticipants\x121\n" +
+	"\aai_live\x18\a \x01(\v2\x18.grpc.SignalAILiveStatusR\x06aiLive\x12;\n" +
+	"\n" +
+	"moderation\x18\b \x03(\v2\x1b.grpc.SignalMediaModerationR\n" +
+	"moderation\x12A\n" +
+	"\rscreen_shares\x18\t \x03(\v2\x1c.grpc.SignalScreenShareStateR\fscreenShares\x12!\n" +
+	"\fresume_token\x18\n" +
+	" \x01(\tR\vresumeToken\x129\n" +
+	"\fraised_hands\x18\v \x03(\v2\x16.grpc.SignalRaisedHandR\vraisedHands\x125\n" +
+	"\bfeedback\x18\f \x03(\v2\x19.grpc.SignalFeedbackStateR\bfeedback\"U\n" +
+	"\vSignalError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\adetails\x18\x03 \x01(\tR\adetails\"}\n" +
+	"\x12SignalMediaControl\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x1d\n" +
+	"\n" +
+	"media_type\x18\x02 \x01(\tR\tmediaType\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\rR\x06userId\x12\x17\n" +
+	"\apeer_id\x18\x04 \x01(\tR\x06peerId\"+\n" +
+	"\x11SignalAILiveClaim\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\"\xe4\x01\n" +
+	"\x12SignalAILiveStatus\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12$\n" +
+	"\x0eleader_user_id\x18\x02 \x01(\rR\fleaderUserId\x12*\n" +
+	"\x11leader_session_id\x18\x03 \x01(\tR\x0fleaderSessionId\x12'\n" +
+	"\x0fleader_username\x18\x04 \x01(\tR\x0eleaderUsername\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"9\n" +
+	"\x0fSignalAILiveTag\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\"\xd5\x01\n" +
+	"\x12SignalAILiveResult\x12\x17\n" +
+	"\aline_id\x18\x01 \x01(\tR\x06lineId\x12\x1f\n" +
+	"\vspeaker_key\x18\x02 \x01(\tR\n" +
+	"speakerKey\x12#\n" +
+	"\rspeaker_label\x18\x03 \x01(\tR\fspeakerLabel\x12!\n" +
+	"\ftimestamp_ms\x18\x04 \x01(\x03R\vtimestampMs\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\x12)\n" +
+	"\x04tags\x18\x06 \x03(\v2\x15.grpc.SignalAILiveTagR\x04tags\"p\n" +
+	"\x13SignalModerateMedia\x12$\n" +
+	"\x0etarget_user_id\x18\x01 \x01(\rR\ftargetUserId\x12\x1d\n" +
+	"\n" +
+	"media_type\x18\x02 \x01(\tR\tmediaType\x12\x14\n" +
+	"\x05muted\x18\x03 \x01(\bR\x05muted\"\xbe\x01\n" +
+	"\x15SignalMediaModeration\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1d\n" +
+	"\n" +
+	"media_type\x18\x02 \x01(\tR\tmediaType\x12\x14\n" +
+	"\x05muted\x18\x03 \x01(\bR\x05muted\x12\x1c\n" +
+	"\n" +
+	"by_user_id\x18\x04 \x01(\rR\bbyUserId\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x8d\x01\n" +
+	"\x18SignalScreenShareRequest\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x1b\n" +
+	"\tstream_id\x18\x02 \x01(\tR\bstreamId\x12\x19\n" +
+	"\btrack_id\x18\x03 \x01(\tR\atrackId\x12!\n" +
+	"\fcontent_hint\x18\x04 \x01(\tR\vcontentHint\"\xfc\x01\n" +
+	"\x16SignalScreenShareState\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x17\n" +
+	"\apeer_id\x18\x03 \x01(\tR\x06peerId\x12\x1b\n" +
+	"\tstream_id\x18\x04 \x01(\tR\bstreamId\x12\x19\n" +
+	"\btrack_id\x18\x05 \x01(\tR\atrackId\x12!\n" +
+	"\fcontent_hint\x18\x06 \x01(\tR\vcontentHint\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\"\x80\x01\n" +
+	"\x10SignalRaisedHand\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x127\n" +
+	"\traised_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\braisedAt\"\x85\x01\n" +
+	"\x13SignalFeedbackState\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1a\n" +
+	"\bfeedback\x18\x02 \x01(\tR\bfeedback\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x82\x02\n" +
+	"\x16SignalScreenShareEvent\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x122\n" +
+	"\x05share\x18\x02 \x01(\v2\x1c.grpc.SignalScreenShareStateR\x05share\x12(\n" +
+	"\x10previous_user_id\x18\x03 \x01(\rR\x0epreviousUserId\x12\x1c\n" +
+	"\n" +
+	"by_user_id\x18\x04 \x01(\rR\bbyUserId\x12\x1c\n" +
+	"\texclusive\x18\x05 \x01(\bR\texclusive\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x84\x01\n" +
+	"\x10SignalICERestart\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12\x17\n" +
+	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12&\n" +
+	"\x0fgrace_period_ms\x18\x04 \x01(\x03R\rgracePeriodMs\"\x8f\x01\n" +
+	"\x14SignalSessionResumed\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12!\n" +
+	"\fresume_token\x18\x02 \x01(\tR\vresumeToken\x12\x19\n" +
+	"\blast_seq\x18\x03 \x01(\x04R\alastSeq\x12\x1a\n" +
+	"\breplayed\x18\x04 \x01(\x05R\breplayed*\xc7\a\n" +
+	"\n" +
+	"SignalType\x12\x1b\n" +
+	"\x17SIGNAL_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11SIGNAL_TYPE_OFFER\x10\x01\x12\x16\n" +
+	"\x12SIGNAL_TYPE_ANSWER\x10\x02\x12\x1d\n" +
+	"\x19SIGNAL_TYPE_ICE_CANDIDATE\x10\x03\x12\x19\n" +
+	"\x15SIGNAL_TYPE_JOIN_ROOM\x10\x04\x12\x1a\n" +
+	"\x16SIGNAL_TYPE_LEAVE_ROOM\x10\x05\x12\x1b\n" +
+	"\x17SIGNAL_TYPE_USER_JOINED\x10\x06\x12\x19\n" +
+	"\x15SIGNAL_TYPE_USER_LEFT\x10\a\x12\x14\n" +
+	"\x10SIGNAL_TYPE_CHAT\x10\b\x12\x1c\n" +
+	"\x18SIGNAL_TYPE_SCREEN_SHARE\x10\t\x12\x1d\n" +
+	"\x19SIGNAL_TYPE_MEDIA_CONTROL\x10\n" +
+	"\x12\x14\n" +
+	"\x10SIGNAL_TYPE_PING\x10\v\x12\x14\n" +
+	"\x10SIGNAL_TYPE_PONG\x10\f\x12\x15\n" +
+	"\x11SIGNAL_TYPE_ERROR\x10\r\x12\x19\n" +
+	"\x15SIGNAL_TYPE_ROOM_INFO\x10\x0e\x12\x1d\n" +
+	"\x19SIGNAL_TYPE_AI_LIVE_CLAIM\x10\x0f\x12\x1e\n" +
+	"\x1aSIGNAL_TYPE_AI_LIVE_STATUS\x10\x10\x12\x1e\n" +
+	"\x1aSIGNAL_TYPE_AI_LIVE_RESULT\x10\x11\x12\x1b\n" +
+	"\x17SIGNAL_TYPE_ICE_RESTART\x10\x12\x12 \n" +
+	"\x1cSIGNAL_TYPE_AI_STREAM_RESULT\x10\x13\x12\x1e\n" +
+	"\x1aSIGNAL_TYPE_MODERATE_MEDIA\x10\x14\x12\x1f\n" +
+	"\x1bSIGNAL_TYPE_MEDIA_MODERATED\x10\x15\x12\x1f\n" +
+	"\x1bSIGNAL_TYPE_SESSION_RESUMED\x10\x16\x12\x1c\n" +
+	"\x18SIGNAL_TYPE_LOBBY_STATUS\x10\x17\x12\x1c\n" +
+	"\x18SIGNAL_TYPE_LOBBY_UPDATE\x10\x18\x12\x1c\n" +
+	"\x18SIGNAL_TYPE_LOBBY_ACTION\x10\x19\x12\x1c\n" +
+	"\x18SIGNAL_TYPE_HOST_CONTROL\x10\x1a\x12\x1a\n" +
+	"\x16SIGNAL_TYPE_RAISE_HAND\x10\x1b\x12\x18\n" +
+	"\x14SIGNAL_TYPE_REACTION\x10\x1c\x12\x18\n" +
+	"\x14SIGNAL_TYPE_FEEDBACK\x10\x1d\x12\x18\n" +
+	"\x14SIGNAL_TYPE_BREAKOUT\x10\x1e\x12\x14\n" +
+	"\x10SIGNAL_TYPE_POLL\x10\x1f\x12\x18\n" +
+	"\x14SIGNAL_TYPE_QUESTION\x10 \x12\x1c\n" +
+	"\x18SIGNAL_TYPE_CHAT_HISTORY\x10!B\x1cZ\x1ameeting-system/shared/grpcb\x06proto3"
+
+var (
+	file_signaling_proto_rawDescOnce sync.Once
+	file_signaling_proto_rawDescData []byte
+)
+
+func file_signaling_proto_rawDescGZIP() []byte {
+	file_signaling_proto_rawDescOnce.Do(func() {
+		file_signaling_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_signaling_proto_rawDesc), len(file_signaling_proto_rawDesc)))
+	})
+	return file_signaling_proto_rawDescData
+}
+
+var file_signaling_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_signaling_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_signaling_proto_goTypes = []any{
+	(SignalType)(0),                  // 0: grpc.SignalType
+	(*SignalEnvelope)(nil),           // 1: grpc.SignalEnvelope
+	(*SignalSessionDescription)(nil), // 2: grpc.SignalSessionDescription
+	(*SignalICECandidate)(nil),       // 3: grpc.SignalICECandidate
+	(*SignalChat)(nil),               // 4: grpc.SignalChat
+	(*SignalParticipant)(nil),        // 5: grpc.SignalParticipant
+	(*SignalRoomParticipant)(nil),    // 6: grpc.SignalRoomParticipant
+	(*SignalICEServer)(nil),          // 7: grpc.SignalICEServer
+	(*SignalRoomInfo)(nil),           // 8: grpc.SignalRoomInfo
+	(*SignalError)(nil),              // 9: grpc.SignalError
+	(*SignalMediaControl)(nil),       // 10: grpc.SignalMediaControl
+	(*SignalAILiveClaim)(nil),        // 11: grpc.SignalAILiveClaim
+	(*SignalAILiveStatus)(nil),       // 12: grpc.SignalAILiveStatus
+	(*SignalAILiveTag)(nil),          // 13: grpc.SignalAILiveTag
+	(*SignalAILiveResult)(nil),       // 14: grpc.SignalAILiveResult
+	(*SignalModerateMedia)(nil),      // 15: grpc.SignalModerateMedia
+	(*SignalMediaModeration)(nil),    // 16: grpc.SignalMediaModeration
+	(*SignalScreenShareRequest)(nil), // 17: grpc.SignalScreenShareRequest
+	(*SignalScreenShareState)(nil),   // 18: grpc.SignalScreenShareState
+	(*SignalRaisedHand)(nil),         // 19: grpc.SignalRaisedHand
+	(*SignalFeedbackState)(nil),      // 20: grpc.SignalFeedbackState
+	(*SignalScreenShareEvent)(nil),   // 21: grpc.SignalScreenShareEvent
+	(*SignalICERestart)(nil),         // 22: grpc.SignalICERestart
+	(*SignalSessionResumed)(nil),     // 23: grpc.SignalSessionResumed
+	(*timestamppb.Timestamp)(nil),    // 24: google.protobuf.Timestamp
+}
+var file_signaling_proto_depIdxs = []int32{
+	0,  // 0: grpc.SignalEnvelope.type:type_name -> grpc.SignalType
+	24, // 1: grpc.SignalEnvelope.timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 2: grpc.SignalEnvelope.sdp:type_name -> grpc.SignalSessionDescription
+	3,  // 3: grpc.SignalEnvelope.ice_candidate:type_name -> grpc.SignalICECandidate
+	4,  // 4: grpc.SignalEnvelope.chat:type_name -> grpc.SignalChat
+	5,  // 5: grpc.SignalEnvelope.participant:type_name -> grpc.SignalParticipant
+	8,  // 6: grpc.SignalEnvelope.room_info:type_name -> grpc.SignalRoomInfo
+	9,  // 7: grpc.SignalEnvelope.error:type_name -> grpc.SignalError
+	10, // 8: grpc.SignalEnvelope.media_control:type_name -> grpc.SignalMediaControl
+	11, // 9: grpc.SignalEnvelope.ai_live_claim:type_name -> grpc.SignalAILiveClaim
+	12, // 10: grpc.SignalEnvelope.ai_live_status:type_name -> grpc.SignalAILiveStatus
+	14, // 11: grpc.SignalEnvelope.ai_live_result:type_name -> grpc.SignalAILiveResult
+	15, // 12: grpc.SignalEnvelope.moderate_media:type_name -> grpc.SignalModerateMedia
+	16, // 13: grpc.SignalEnvelope.media_moderation:type_name -> grpc.SignalMediaModeration
+	17, // 14: grpc.SignalEnvelope.screen_share_request:type_name -> grpc.SignalScreenShareRequest
+	21, // 15: grpc.SignalEnvelope.screen_share_event:type_name -> grpc.SignalScreenShareEvent
+	22, // 16: grpc.SignalEnvelope.ice_restart:type_name -> grpc.SignalICERestart
+	23, // 17: grpc.SignalEnvelope.session_resumed:type_name -> grpc.SignalSessionResumed
+	24, // 18: grpc.SignalChat.created_at:type_name -> google.protobuf.Timestamp
+	24, // 19: grpc.SignalChat.edited_at:type_name -> google.protobuf.Timestamp
+	24, // 20: grpc.SignalRoomParticipant.joined_at:type_name -> google.protobuf.Timestamp
+	24, // 21: grpc.SignalRoomParticipant.last_active_at:type_name -> google.protobuf.Timestamp
+	7,  // 22: grpc.SignalRoomInfo.ice_servers:type_name -> grpc.SignalICEServer
+	6,  // 23: grpc.SignalRoomInfo.participants:type_name -> grpc.SignalRoomParticipant
+	12, // 24: grpc.SignalRoomInfo.ai_live:type_name -> grpc.SignalAILiveStatus
+	16, // 25: grpc.SignalRoomInfo.moderation:type_name -> grpc.SignalMediaModeration
+	18, // 26: grpc.SignalRoomInfo.screen_shares:type_name -> grpc.SignalScreenShareState
+	19, // 27: grpc.SignalRoomInfo.raised_hands:type_name -> grpc.SignalRaisedHand
+	20, // 28: grpc.SignalRoomInfo.feedback:type_name -> grpc.SignalFeedbackState
+	24, // 29: grpc.SignalAILiveStatus.updated_at:type_name -> google.protobuf.Timestamp
+	13, // 30: grpc.SignalAILiveResult.tags:type_name -> grpc.SignalAILiveTag
+	24, // 31: grpc.SignalMediaModeration.updated_at:type_name -> google.protobuf.Timestamp
+	24, // 32: grpc.SignalScreenShareState.started_at:type_name -> google.protobuf.Timestamp
+	24, // 33: grpc.SignalRaisedHand.raised_at:type_name -> google.protobuf.Timestamp
+	24, // 34: grpc.SignalFeedbackState.updated_at:type_name -> google.protobuf.Timestamp
+	18, // 35: grpc.SignalScreenShareEvent.share:type_name -> grpc.SignalScreenShareState
+	24, // 36: grpc.SignalScreenShareEvent.timestamp:type_name -> google.protobuf.Timestamp
+	37, // [37:37] is the sub-list for method output_type
+	37, // [37:37] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
+}
+
+func init() { file_signaling_proto_init() }
+func file_signaling_proto_init() {
+	if File_signaling_proto != nil {
+		return
+	}
+	file_signaling_proto_msgTypes[0].OneofWrappers = []any{
+		(*SignalEnvelope_Sdp)(nil),
+		(*SignalEnvelope_IceCandidate)(nil),
+		(*SignalEnvelope_Chat)(nil),
+		(*SignalEnvelope_Participant)(nil),
+		(*SignalEnvelope_RoomInfo)(nil),
+		(*SignalEnvelope_Error)(nil),
+		(*SignalEnvelope_MediaControl)(nil),
+		(*SignalEnvelope_AiLiveClaim)(nil),
+		(*SignalEnvelope_AiLiveStatus)(nil),
+		(*SignalEnvelope_AiLiveResult)(nil),
+		(*SignalEnvelope_ModerateMedia)(nil),
+		(*SignalEnvelope_MediaModeration)(nil),
+		(*SignalEnvelope_ScreenShareRequest)(nil),
+		(*SignalEnvelope_ScreenShareEvent)(nil),
+		(*SignalEnvelope_IceRestart)(nil),
+		(*SignalEnvelope_SessionResumed)(nil),
+		(*SignalEnvelope_JsonPayload)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_signaling_proto_rawDesc), len(file_signaling_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_signaling_proto_goTypes,
+		DependencyIndexes: file_signaling_proto_depIdxs,
+		EnumInfos:         file_signaling_proto_enumTypes,
+		MessageInfos:      file_signaling_proto_msgTypes,
+	}.Build()
+	File_signaling_proto = out.File
+	file_signaling_proto_goTypes = nil
+	file_signaling_proto_depIdxs = nil
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var signalingPolicyViolations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "signaling_message_policy_violations_total",
+		Help: "Total number of client signaling messages rejected by the message policy",
+	},
+	[]string{"service", "message_type", "reason", "action"},
+)
+
+func init() {
+	prometheus.MustRegister(signalingPolicyViolations)
+}
+
+// RecordSignalingPolicyViolation 记录一次上行消息策略违规
+// reason: rate_limited, oversized, invalid_payload, throttled；action: warn, throttle, disconnect, drop
+func RecordSignalingPolicyViolation(serviceName, messageType, reason, action string) {
+	signalingPolicyViolations.WithLabelValues(serviceName, messageType, reason, action).Inc()
+}
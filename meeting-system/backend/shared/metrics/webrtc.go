@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WebRTC 媒体质量指标（按房间标注，direction 为 inbound（发布者 -> SFU）或 outbound（SFU -> 订阅者））
+var (
+	webrtcRTPPackets = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webrtc_rtp_packets_total",
+			Help: "Total number of RTP packets per room",
+		},
+		[]string{"service", "room", "direction", "kind"},
+	)
+
+	webrtcRTPBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webrtc_rtp_bytes_total",
+			Help: "Total number of RTP payload bytes per room",
+		},
+		[]string{"service", "room", "direction", "kind"},
+	)
+
+	webrtcRTPPacketsLost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webrtc_rtp_packets_lost_total",
+			Help: "Total number of RTP packets lost per room",
+		},
+		[]string{"service", "room", "direction", "kind"},
+	)
+
+	webrtcRTCPFeedback = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webrtc_rtcp_feedback_total",
+			Help: "Total number of RTCP NACK/PLI/FIR feedback packets per room",
+		},
+		[]string{"service", "room", "direction", "type"},
+	)
+
+	webrtcFrames = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webrtc_video_frames_total",
+			Help: "Total number of video frames received per room",
+		},
+		[]string{"service", "room"},
+	)
+
+	webrtcBitrate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "webrtc_bitrate_bps",
+			Help: "Current aggregate bitrate per room in bits per second",
+		},
+		[]string{"service", "room", "direction", "kind"},
+	)
+
+	webrtcJitter = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "webrtc_jitter_seconds",
+			Help:    "RTP interarrival jitter per stream",
+			Buckets: []float64{.001, .0025, .005, .01, .02, .03, .05, .1, .2},
+		},
+		[]string{"service", "room", "direction", "kind"},
+	)
+
+	webrtcRoundTripTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "webrtc_round_trip_time_seconds",
+			Help:    "Round trip time between the SFU and peers",
+			Buckets: []float64{.01, .025, .05, .1, .15, .2, .3, .5, 1},
+		},
+		[]string{"service", "room"},
+	)
+
+	webrtcFractionLost = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "webrtc_fraction_lost",
+			Help:    "Fraction of packets lost reported by RTCP receiver reports",
+			Buckets: []float64{0, .005, .01, .02, .05, .1, .2, .5},
+		},
+		[]string{"service", "room", "direction", "kind"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		webrtcRTPPackets,
+		webrtcRTPBytes,
+		webrtcRTPPacketsLost,
+		webrtcRTCPFeedback,
+		webrtcFrames,
+		webrtcBitrate,
+		webrtcJitter,
+		webrtcRoundTripTime,
+		webrtcFractionLost,
+	)
+}
+
+// RTPStreamSample 单个 RTP 流在一个采集周期内的增量与瞬时值
+type RTPStreamSample struct {
+	Direction string
+	Kind      string
+	// 增量计数
+	Packets     uint64
+	Bytes       uint64
+	PacketsLost uint64
+	NACKs       uint64
+	PLIs        uint64
+	FIRs        uint64
+	Frames      uint64
+	// 瞬时值（<0 表示未知）
+	JitterSeconds float64
+	FractionLost  float64
+}
+
+// RecordWebRTCStream 记录一个 RTP 流的采样
+func RecordWebRTCStream(serviceName, room string, s RTPStreamSample) {
+	webrtcRTPPackets.WithLabelValues(serviceName, room, s.Direction, s.Kind).Add(float64(s.Packets))
+	webrtcRTPBytes.WithLabelValues(serviceName, room, s.Direction, s.Kind).Add(float64(s.Bytes))
+	webrtcRTPPacketsLost.WithLabelValues(serviceName, room, s.Direction, s.Kind).Add(float64(s.PacketsLost))
+	webrtcRTCPFeedback.WithLabelValues(serviceName, room, s.Direction, "nack").Add(float64(s.NACKs))
+	webrtcRTCPFeedback.WithLabelValues(serviceName, room, s.Direction, "pli").Add(float64(s.PLIs))
+	webrtcRTCPFeedback.WithLabelValues(serviceName, room, s.Direction, "fir").Add(float64(s.FIRs))
+	if s.Frames > 0 {
+		webrtcFrames.WithLabelValues(serviceName, room).Add(float64(s.Frames))
+	}
+	if s.JitterSeconds >= 0 {
+		webrtcJitter.WithLabelValues(serviceName, room, s.Direction, s.Kind).Observe(s.JitterSeconds)
+	}
+	if s.FractionLost >= 0 {
+		webrtcFractionLost.WithLabelValues(serviceName, room, s.Direction, s.Kind).Observe(s.FractionLost)
+	}
+}
+
+// UpdateWebRTCBitrate 更新房间某方向某类媒体的总码率
+func UpdateWebRTCBitrate(serviceName, room, direction, kind string, bps float64) {
+	webrtcBitrate.WithLabelValues(serviceName, room, direction, kind).Set(bps)
+}
+
+// RecordWebRTCRoundTripTime 记录 Peer 的往返时延
+func RecordWebRTCRoundTripTime(serviceName, room string, rtt float64) {
+	webrtcRoundTripTime.WithLabelValues(serviceName, room).Observe(rtt)
+}
+
+// DeleteWebRTCRoomMetrics 房间关闭后删除其时间序列，避免标签基数随房间数无限增长
+func DeleteWebRTCRoomMetrics(serviceName, room string) {
+	labels := prometheus.Labels{"service": serviceName, "room": room}
+	webrtcRTPPackets.DeletePartialMatch(labels)
+	webrtcRTPBytes.DeletePartialMatch(labels)
+	webrtcRTPPacketsLost.DeletePartialMatch(labels)
+	webrtcRTCPFeedback.DeletePartialMatch(labels)
+	webrtcFrames.DeletePartialMatch(labels)
+	webrtcBitrate.DeletePartialMatch(labels)
+	webrtcJitter.DeletePartialMatch(labels)
+	webrtcRoundTripTime.DeletePartialMatch(labels)
+	webrtcFractionLost.DeletePartialMatch(labels)
+}
@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxChatContentRunes 单条聊天消息的长度上限
+const MaxChatContentRunes = 2000
+
+// 聊天业务错误（信令服务回复错误码、会议服务映射 HTTP 状态码）
+var (
+	ErrChatMessageNotFound = errors.New("chat message not found")
+	ErrChatContentRequired = errors.New("chat content is required")
+	ErrChatContentTooLong  = fmt.Errorf("chat content exceeds %d characters", MaxChatContentRunes)
+	ErrChatSelfMessage     = errors.New("cannot send a private message to yourself")
+	ErrChatPublicReply     = errors.New("replies to a public message must be public")
+	ErrChatReplyRecipient  = errors.New("replies to a private message stay in that conversation")
+	ErrChatNotAuthor       = errors.New("only the author can edit this message")
+	ErrChatDeleteDenied    = errors.New("only the author or a moderator can delete this message")
+	ErrChatDeleted         = errors.New("chat message has been deleted")
+	ErrChatInvalidCursor   = errors.New("invalid cursor")
+)
+
+// MeetingChatMessage 持久化的会议聊天消息。ID 由服务端生成（UUID），
+// 删除时保留墓碑：清空内容并记录 DeletedAt，历史中仍占位以保持话题完整
+type MeetingChatMessage struct {
+	ID        string     `json:"id" gorm:"primaryKey;size:36"`
+	MeetingID uint       `json:"meeting_id" gorm:"not null;index:idx_meeting_chat_messages_meeting_created,priority:1"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	Username  string     `json:"username" gorm:"size:100"`
+	ToUserID  *uint      `json:"to_user_id,omitempty" gorm:"index"`
+	ReplyToID string     `json:"reply_to_id,omitempty" gorm:"size:36;index"`
+	Content   string     `json:"content" gorm:"type:text"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_meeting_chat_messages_meeting_created,priority:2"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (MeetingChatMessage) TableName() string {
+	return "meeting_chat_messages"
+}
+
+// IsPrivate 是否为私聊
+func (m *MeetingChatMessage) IsPrivate() bool {
+	return m.ToUserID != nil
+}
+
+// IsDeleted 是否已删除
+func (m *MeetingChatMessage) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// VisibleTo 公开消息对全员可见，私聊只对收发双方可见
+func (m *MeetingChatMessage) VisibleTo(userID uint) bool {
+	return !m.IsPrivate() || m.UserID == userID || *m.ToUserID == userID
+}
+
+// Payload 转换为下发给客户端的聊天载荷
+func (m *MeetingChatMessage) Payload() ChatMessage {
+	payload := ChatMessage{
+		Content:   m.Content,
+		UserID:    m.UserID,
+		Username:  m.Username,
+		MeetingID: m.MeetingID,
+		ID:        m.ID,
+		ReplyToID: m.ReplyToID,
+		EditedAt:  m.EditedAt,
+		Deleted:   m.IsDeleted(),
+	}
+	if m.ToUserID != nil {
+		payload.ToUserID = *m.ToUserID
+	}
+	createdAt := m.CreatedAt
+	payload.CreatedAt = &createdAt
+	if payload.Deleted {
+		payload.Content = ""
+	}
+	return payload
+}
+
+// NewMeetingChatMessage 校验发送请求并构造聊天消息。parent 为被回复的消息（可为 nil）：
+// 回复归入其所在话题的首条消息，并沿用该话题的可见范围（公开话题只能公开回复，私聊话题只在双方之间）
+func NewMeetingChatMessage(meetingID, userID uint, username string, req ChatMessage, parent *MeetingChatMessage) (*MeetingChatMessage, error) {
+	content, err := normalizeChatContent(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	if req.ToUserID == userID {
+		return nil, ErrChatSelfMessage
+	}
+
+	message := &MeetingChatMessage{
+		MeetingID: meetingID,
+		UserID:    userID,
+		Username:  username,
+		Content:   content,
+	}
+	if req.ToUserID != 0 {
+		to := req.ToUserID
+		message.ToUserID = &to
+	}
+
+	if parent == nil {
+		return message, nil
+	}
+	if parent.MeetingID != meetingID || !parent.VisibleTo(userID) {
+		return nil, ErrChatMessageNotFound
+	}
+	if parent.IsDeleted() {
+		return nil, ErrChatDeleted
+	}
+	message.ReplyToID = parent.ID
+	if parent.ReplyToID != "" {
+		message.ReplyToID = parent.ReplyToID
+	}
+
+	if !parent.IsPrivate() {
+		if message.IsPrivate() {
+			return nil, ErrChatPublicReply
+		}
+		return message, nil
+	}
+	peer := parent.UserID
+	if peer == userID {
+		peer = *parent.ToUserID
+	}
+	if req.ToUserID != 0 && req.ToUserID != peer {
+		return nil, ErrChatReplyRecipient
+	}
+	message.ToUserID = &peer
+	return message, nil
+}
+
+// Edit 作者修改消息内容
+func (m *MeetingChatMessage) Edit(byUserID uint, content string, now time.Time) error {
+	if m.UserID != byUserID {
+		return ErrChatNotAuthor
+	}
+	if m.IsDeleted() {
+		return ErrChatDeleted
+	}
+	content, err := normalizeChatContent(content)
+	if err != nil {
+		return err
+	}
+	m.Content = content
+	m.EditedAt = &now
+	return nil
+}
+
+// Delete 作者删除消息；主办人/主持人可以删除公开消息
+func (m *MeetingChatMessage) Delete(byUserID uint, canModerate bool, now time.Time) error {
+	if m.UserID != byUserID && (!canModerate || m.IsPrivate()) {
+		return ErrChatDeleteDenied
+	}
+	if m.IsDeleted() {
+		return ErrChatDeleted
+	}
+	m.Content = ""
+	m.DeletedAt = &now
+	return nil
+}
+
+func normalizeChatContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", ErrChatContentRequired
+	}
+	if utf8.RuneCountInString(content) > MaxChatContentRunes {
+		return "", ErrChatContentTooLong
+	}
+	return content, nil
+}
+
+// ChatHistoryQuery 聊天历史查询：按 (CreatedAt, ID) 倒序，从游标之前开始取
+type ChatHistoryQuery struct {
+	MeetingID uint
+	// ViewerID 只返回公开消息与该用户收发的私聊
+	ViewerID uint
+	// ThreadID 非空时只返回该话题的回复
+	ThreadID string
+	// BeforeAt/BeforeID 游标位置，BeforeAt 为零值时从最新消息开始
+	BeforeAt time.Time
+	BeforeID string
+	Limit    int
+}
+
+// WithCursor 解析分页游标
+func (q ChatHistoryQuery) WithCursor(cursor string) (ChatHistoryQuery, error) {
+	if cursor == "" {
+		return q, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return q, ErrChatInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return q, ErrChatInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return q, ErrChatInvalidCursor
+	}
+	q.BeforeAt = time.Unix(0, unixNano).UTC()
+	q.BeforeID = id
+	return q, nil
+}
+
+// ChatCursor 以消息位置生成分页游标（对客户端不透明）
+func ChatCursor(m *MeetingChatMessage) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", m.CreatedAt.UnixNano(), m.ID)))
+}
+
+// NewChatHistory 由倒序的查询结果（最多取 limit+1 条以判断是否还有更早的消息）生成按时间正序的一页
+func NewChatHistory(records []MeetingChatMessage, limit int) ChatHistoryMessage {
+	history := ChatHistoryMessage{Messages: []ChatMessage{}}
+	if len(records) > limit {
+		records = records[:limit]
+		if limit > 0 {
+			history.NextCursor = ChatCursor(&records[limit-1])
+		}
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		history.Messages = append(history.Messages, records[i].Payload())
+	}
+	return history
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -24,9 +26,13 @@ type Meeting struct {
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// ParentMeetingID 分组讨论房间所属的主会议（普通会议为 nil）
+	ParentMeetingID *uint `json:"parent_meeting_id,omitempty" gorm:"index"`
+
 	// 关联关系
 	Creator      User                 `json:"creator,omitempty" gorm:"foreignKey:CreatorID"`
 	Participants []MeetingParticipant `json:"participants,omitempty" gorm:"foreignKey:MeetingID"`
+	Breakouts    []Meeting            `json:"breakouts,omitempty" gorm:"foreignKey:ParentMeetingID"`
 }
 
 // MeetingStatus 会议状态
@@ -110,6 +116,24 @@ func (r ParticipantRole) String() string {
 	}
 }
 
+// CanModerate 主办人与主持人可以管控其他参与者的媒体
+func (r ParticipantRole) CanModerate() bool {
+	return r == ParticipantRoleHost || r == ParticipantRoleModerator
+}
+
+// CanControl 是否可以对目标角色执行移出、封禁等主持控制：主持人不能控制主办人
+func (r ParticipantRole) CanControl(target ParticipantRole) bool {
+	if !r.CanModerate() {
+		return false
+	}
+	return target != ParticipantRoleHost || r == ParticipantRoleHost
+}
+
+// Assignable 是否为可在会议中授予的角色（主办人只能是会议创建者）
+func (r ParticipantRole) Assignable() bool {
+	return r == ParticipantRoleParticipant || r == ParticipantRoleModerator || r == ParticipantRolePresenter
+}
+
 // ParticipantStatus 参与者状态
 type ParticipantStatus int
 
@@ -118,6 +142,8 @@ const (
 	ParticipantStatusJoined   ParticipantStatus = 2 // 已加入
 	ParticipantStatusLeft     ParticipantStatus = 3 // 已离开
 	ParticipantStatusRejected ParticipantStatus = 4 // 已拒绝
+	ParticipantStatusWaiting  ParticipantStatus = 5 // 等候室中，等待主办人/主持人准入
+	ParticipantStatusBanned   ParticipantStatus = 6 // 被主办人/主持人移出并禁止再次加入
 )
 
 // TableName 指定表名
@@ -163,6 +189,11 @@ func (m *Meeting) IsStarted() bool {
 	return m.Status == MeetingStatusStarted
 }
 
+// IsBreakout 是否为分组讨论房间（子会议）
+func (m *Meeting) IsBreakout() bool {
+	return m.ParentMeetingID != nil
+}
+
 // CanJoin 检查是否可以加入会议
 func (m *Meeting) CanJoin() bool {
 	now := time.Now()
@@ -223,6 +254,78 @@ type MeetingSettings struct {
 	EnableAI          bool `json:"enable_ai"`
 	MuteOnJoin        bool `json:"mute_on_join"`
 	RequireApproval   bool `json:"require_approval"`
+	// Locked 会议已锁定：新用户不能再加入，已在会议中的参与者可以重新连接
+	Locked bool `json:"locked,omitempty"`
+	// ScreenShareMode 屏幕共享模式：single（默认，同一时间只有一人共享，主办人/主持人可接管）或 multiple
+	ScreenShareMode string `json:"screen_share_mode,omitempty"`
+	// QAApproval 问答需要主办人/主持人审核后才对全员可见
+	QAApproval bool `json:"qa_approval,omitempty"`
+
+	// Codecs 会议级编解码策略，为空时使用媒体服务默认编解码器
+	Codecs *CodecPolicy `json:"codecs,omitempty"`
+}
+
+// 屏幕共享模式
+const (
+	ScreenShareModeSingle   = "single"
+	ScreenShareModeMultiple = "multiple"
+)
+
+// ExclusiveScreenShare 是否同一时间只允许一人共享屏幕
+func (s *MeetingSettings) ExclusiveScreenShare() bool {
+	return s == nil || s.ScreenShareMode != ScreenShareModeMultiple
+}
+
+// CodecPolicy 会议编解码策略
+// 同一会议内所有 PeerConnection 使用相同策略，保证 SFU 转发的发布者编码能被所有订阅者解码。
+type CodecPolicy struct {
+	// Video 允许的视频编码（按优先级排序），取值 VP8/VP9/H264/AV1；为空表示不限制
+	Video []string `json:"video,omitempty"`
+	// Audio 允许的音频编码（按优先级排序），如 opus/G722/PCMU/PCMA；为空表示不限制
+	Audio []string `json:"audio,omitempty"`
+	// H264ProfileLevelID 仅允许指定 profile-level-id 的 H.264（如 42e01f），为空表示不限制
+	H264ProfileLevelID string `json:"h264_profile_level_id,omitempty"`
+	// Opus Opus fmtp 参数，未设置的字段保持客户端协商结果
+	Opus *OpusCodecOptions `json:"opus,omitempty"`
+}
+
+// OpusCodecOptions Opus fmtp 选项
+type OpusCodecOptions struct {
+	DTX    *bool `json:"dtx,omitempty"`    // usedtx
+	FEC    *bool `json:"fec,omitempty"`    // useinbandfec
+	Stereo *bool `json:"stereo,omitempty"` // stereo / sprop-stereo
+}
+
+// Validate 校验编解码策略中的编码名称与 profile-level-id
+func (p *CodecPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, name := range p.Video {
+		switch strings.ToUpper(name) {
+		case "VP8", "VP9", "H264", "AV1":
+		default:
+			return fmt.Errorf("unsupported video codec: %s", name)
+		}
+	}
+	for _, name := range p.Audio {
+		switch strings.ToLower(name) {
+		case "opus", "g722", "pcmu", "pcma":
+		default:
+			return fmt.Errorf("unsupported audio codec: %s", name)
+		}
+	}
+	if id := p.H264ProfileLevelID; id != "" {
+		if len(id) != 6 {
+			return fmt.Errorf("invalid h264 profile-level-id: %s", id)
+		}
+		for _, c := range strings.ToLower(id) {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+				return fmt.Errorf("invalid h264 profile-level-id: %s", id)
+			}
+		}
+	}
+	return nil
 }
 
 // ===== 请求模型 =====
@@ -250,6 +353,28 @@ type UpdateMeetingRequest struct {
 	Settings        *MeetingSettings `json:"settings,omitempty"`
 }
 
+// CreateBreakoutsRequest 创建分组讨论房间请求，Names 可选（按顺序命名，缺省为“分组 N”）
+type CreateBreakoutsRequest struct {
+	Count int      `json:"count" binding:"required,min=1,max=50"`
+	Names []string `json:"names,omitempty" binding:"omitempty,max=50,dive,max=100"`
+}
+
+// AssignBreakoutsRequest 分配分组请求：Random 为 true 时把主会议中已加入的参与者随机均分到各分组
+type AssignBreakoutsRequest struct {
+	Assignments []BreakoutAssignment `json:"assignments"`
+	Random      bool                 `json:"random"`
+}
+
+// OpenBreakoutsRequest 开启分组请求，DurationMinutes 为 0 时不自动关闭
+type OpenBreakoutsRequest struct {
+	DurationMinutes int `json:"duration_minutes" binding:"min=0,max=240"`
+}
+
+// BreakoutBroadcastRequest 向所有分组广播消息请求
+type BreakoutBroadcastRequest struct {
+	Message string `json:"message" binding:"required,min=1,max=1000"`
+}
+
 // ===== 响应模型 =====
 
 // MeetingResponse 会议响应
@@ -281,6 +406,15 @@ type ParticipantResponse struct {
 	User      *UserProfile      `json:"user,omitempty"`
 }
 
+// BreakoutRoomResponse 分组讨论房间
+type BreakoutRoomResponse struct {
+	ID       uint          `json:"id"`
+	Title    string        `json:"title"`
+	Status   MeetingStatus `json:"status"`
+	ClosesAt *time.Time    `json:"closes_at,omitempty"`
+	UserIDs  []uint        `json:"user_ids"`
+}
+
 // RoomStatus 房间状态
 type RoomStatus string
 
@@ -316,6 +450,8 @@ type JoinMeetingResponse struct {
 	ParticipantID uint                `json:"participant_id"`
 	Role          ParticipantRole     `json:"role"`
 	SFUNode       string              `json:"sfu_node"`
+	// Waiting 会议开启了入会审批，用户需在等候室等待准入（此时不返回会议室信息）
+	Waiting bool `json:"waiting,omitempty"`
 }
 
 // MediaStream 媒体流模型
@@ -356,3 +492,35 @@ type MeetingRecording struct {
 	// 关联关系
 	Meeting Meeting `json:"meeting,omitempty" gorm:"foreignKey:MeetingID"`
 }
+
+// OperationLog 操作审计日志（对应 operation_logs 表，记录主持控制等敏感操作）
+type OperationLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"index"`
+	Operation    string    `json:"operation" gorm:"size:100;not null;index"`
+	ResourceType string    `json:"resource_type" gorm:"size:50"`
+	ResourceID   uint      `json:"resource_id"`
+	Details      string    `json:"details" gorm:"type:jsonb"` // JSON格式的操作详情
+	IPAddress    *string   `json:"ip_address,omitempty" gorm:"type:inet"`
+	UserAgent    string    `json:"user_agent,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (OperationLog) TableName() string {
+	return "operation_logs"
+}
+
+// 主持控制审计操作
+const (
+	OperationMuteAll           = "meeting.mute_all"
+	OperationLockMeeting       = "meeting.lock"
+	OperationUnlockMeeting     = "meeting.unlock"
+	OperationRemoveParticipant = "participant.remove"
+	OperationBanParticipant    = "participant.ban"
+	OperationChangeRole        = "participant.role_change"
+	OperationCreateBreakouts   = "breakout.create"
+	OperationAssignBreakouts   = "breakout.assign"
+	OperationOpenBreakouts     = "breakout.open"
+	OperationBroadcastBreakout = "breakout.broadcast"
+	OperationCloseBreakouts    = "breakout.close"
+)
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// 投票结果可见性
+const (
+	PollResultsLive   = "live"   // 投票期间结果实时对全员公开
+	PollResultsHidden = "hidden" // 投票期间只有主办人/主持人可见，结束后公开
+)
+
+// 投票状态
+const (
+	PollStatusOpen   = "open"
+	PollStatusClosed = "closed"
+)
+
+// Poll 会议内投票
+type Poll struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	MeetingID         uint       `json:"meeting_id" gorm:"not null;index"`
+	CreatorID         uint       `json:"creator_id" gorm:"not null"`
+	Question          string     `json:"question" gorm:"size:500;not null"`
+	MultipleChoice    bool       `json:"multiple_choice"`
+	Anonymous         bool       `json:"anonymous"`
+	ResultsVisibility string     `json:"results_visibility" gorm:"size:16;default:'live'"`
+	Status            string     `json:"status" gorm:"size:16;default:'open'"`
+	ClosedAt          *time.Time `json:"closed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	Options []PollOption `json:"options" gorm:"foreignKey:PollID"`
+}
+
+// PollOption 投票选项
+type PollOption struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	PollID   uint   `json:"poll_id" gorm:"not null;index"`
+	Position int    `json:"position"`
+	Text     string `json:"text" gorm:"size:200;not null"`
+}
+
+// PollVote 一个用户对一个选项的投票（多选时每个选项一行）
+type PollVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PollID    uint      `json:"poll_id" gorm:"not null;uniqueIndex:idx_poll_votes_poll_user_option"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_poll_votes_poll_user_option"`
+	OptionID  uint      `json:"option_id" gorm:"not null;uniqueIndex:idx_poll_votes_poll_user_option"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Poll) TableName() string {
+	return "polls"
+}
+
+func (PollOption) TableName() string {
+	return "poll_options"
+}
+
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
+
+// IsOpen 投票是否进行中
+func (p *Poll) IsOpen() bool {
+	return p.Status == PollStatusOpen
+}
+
+// ResultsVisibleTo 结果是否对该用户可见：实时公开或已结束的投票对全员可见，否则只对主办人/主持人可见
+func (p *Poll) ResultsVisibleTo(canModerate bool) bool {
+	return canModerate || p.ResultsVisibility != PollResultsHidden || !p.IsOpen()
+}
+
+// PollOptionResult 选项的得票；实名投票列出投票人
+type PollOptionResult struct {
+	ID       uint   `json:"id"`
+	Text     string `json:"text"`
+	Votes    int    `json:"votes"`
+	VoterIDs []uint `json:"voter_ids,omitempty"`
+}
+
+// PollState 投票及其结果（信令广播与会后查询共用）
+type PollState struct {
+	ID                uint               `json:"id"`
+	MeetingID         uint               `json:"meeting_id"`
+	CreatorID         uint               `json:"creator_id"`
+	Question          string             `json:"question"`
+	MultipleChoice    bool               `json:"multiple_choice"`
+	Anonymous         bool               `json:"anonymous"`
+	ResultsVisibility string             `json:"results_visibility"`
+	Status            string             `json:"status"`
+	Options           []PollOptionResult `json:"options"`
+	TotalVoters       int                `json:"total_voters"`
+	// ResultsHidden 结果对当前接收者隐藏（Votes 均为 0）
+	ResultsHidden bool       `json:"results_hidden,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+}
+
+// State 汇总投票结果，选项按 Position 排序；匿名投票不列出投票人
+func (p *Poll) State(votes []PollVote) PollState {
+	options := make([]PollOption, len(p.Options))
+	copy(options, p.Options)
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Position < options[j].Position })
+
+	results := make([]PollOptionResult, 0, len(options))
+	index := make(map[uint]int, len(options))
+	for _, option := range options {
+		index[option.ID] = len(results)
+		results = append(results, PollOptionResult{ID: option.ID, Text: option.Text})
+	}
+
+	voters := make(map[uint]struct{})
+	for _, vote := range votes {
+		i, ok := index[vote.OptionID]
+		if !ok {
+			continue
+		}
+		results[i].Votes++
+		if !p.Anonymous {
+			results[i].VoterIDs = append(results[i].VoterIDs, vote.UserID)
+		}
+		voters[vote.UserID] = struct{}{}
+	}
+
+	return PollState{
+		ID:                p.ID,
+		MeetingID:         p.MeetingID,
+		CreatorID:         p.CreatorID,
+		Question:          p.Question,
+		MultipleChoice:    p.MultipleChoice,
+		Anonymous:         p.Anonymous,
+		ResultsVisibility: p.ResultsVisibility,
+		Status:            p.Status,
+		Options:           results,
+		TotalVoters:       len(voters),
+		CreatedAt:         p.CreatedAt,
+		ClosedAt:          p.ClosedAt,
+	}
+}
+
+// WithoutResults 隐藏得票与投票人，保留选项与参与人数
+func (s PollState) WithoutResults() PollState {
+	options := make([]PollOptionResult, len(s.Options))
+	for i, option := range s.Options {
+		options[i] = PollOptionResult{ID: option.ID, Text: option.Text}
+	}
+	s.Options = options
+	s.ResultsHidden = true
+	return s
+}
+
+// 问题状态
+const (
+	QuestionStatusPending   = "pending"   // 待审核，只有主办人/主持人与提问者可见
+	QuestionStatusOpen      = "open"      // 待回答
+	QuestionStatusAnswered  = "answered"  // 已回答
+	QuestionStatusDismissed = "dismissed" // 已忽略
+)
+
+// MeetingQuestion 问答中的问题
+type MeetingQuestion struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	MeetingID  uint       `json:"meeting_id" gorm:"not null;index"`
+	UserID     uint       `json:"user_id" gorm:"not null"`
+	Username   string     `json:"username" gorm:"size:100"`
+	Text       string     `json:"text" gorm:"type:text;not null"`
+	Status     string     `json:"status" gorm:"size:16;default:'open'"`
+	Upvotes    int        `json:"upvotes" gorm:"default:0"`
+	Answer     string     `json:"answer,omitempty" gorm:"type:text"`
+	AnsweredBy *uint      `json:"answered_by,omitempty"`
+	AnsweredAt *time.Time `json:"answered_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// QuestionUpvote 问题点赞（每人每题一次）
+type QuestionUpvote struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	QuestionID uint      `json:"question_id" gorm:"not null;uniqueIndex:idx_question_upvotes_question_user"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_question_upvotes_question_user"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (MeetingQuestion) TableName() string {
+	return "meeting_questions"
+}
+
+func (QuestionUpvote) TableName() string {
+	return "question_upvotes"
+}
+
+// VisibleTo 问题是否对该用户可见：待审核与已忽略的问题只有主办人/主持人与提问者可见
+func (q *MeetingQuestion) VisibleTo(userID uint, canModerate bool) bool {
+	if canModerate || q.UserID == userID {
+		return true
+	}
+	return q.Status == QuestionStatusOpen || q.Status == QuestionStatusAnswered
+}
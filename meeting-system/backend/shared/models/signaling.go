@@ -65,23 +65,42 @@ type SignalingMessage struct {
 type MessageType int
 
 const (
-	MessageTypeOffer        MessageType = 1  // WebRTC Offer
-	MessageTypeAnswer       MessageType = 2  // WebRTC Answer
-	MessageTypeICECandidate MessageType = 3  // ICE候选
-	MessageTypeJoinRoom     MessageType = 4  // 加入房间
-	MessageTypeLeaveRoom    MessageType = 5  // 离开房间
-	MessageTypeUserJoined   MessageType = 6  // 用户加入通知
-	MessageTypeUserLeft     MessageType = 7  // 用户离开通知
-	MessageTypeChat         MessageType = 8  // 聊天消息
-	MessageTypeScreenShare  MessageType = 9  // 屏幕共享
-	MessageTypeMediaControl MessageType = 10 // 媒体控制（静音/取消静音等）
-	MessageTypePing         MessageType = 11 // 心跳
-	MessageTypePong         MessageType = 12 // 心跳响应
-	MessageTypeError        MessageType = 13 // 错误消息
-	MessageTypeRoomInfo     MessageType = 14 // 房间信息/加入确认
-	MessageTypeAILiveClaim  MessageType = 15 // AI Live 领导者申请/释放（会议内共享AI）
-	MessageTypeAILiveStatus MessageType = 16 // AI Live 状态广播
-	MessageTypeAILiveResult MessageType = 17 // AI Live 结果广播
+	MessageTypeOffer          MessageType = 1  // WebRTC Offer
+	MessageTypeAnswer         MessageType = 2  // WebRTC Answer
+	MessageTypeICECandidate   MessageType = 3  // ICE候选
+	MessageTypeJoinRoom       MessageType = 4  // 加入房间
+	MessageTypeLeaveRoom      MessageType = 5  // 离开房间
+	MessageTypeUserJoined     MessageType = 6  // 用户加入通知
+	MessageTypeUserLeft       MessageType = 7  // 用户离开通知
+	MessageTypeChat           MessageType = 8  // 聊天消息
+	MessageTypeScreenShare    MessageType = 9  // 屏幕共享
+	MessageTypeMediaControl   MessageType = 10 // 媒体控制（静音/取消静音等）
+	MessageTypePing           MessageType = 11 // 心跳
+	MessageTypePong           MessageType = 12 // 心跳响应
+	MessageTypeError          MessageType = 13 // 错误消息
+	MessageTypeRoomInfo       MessageType = 14 // 房间信息/加入确认
+	MessageTypeAILiveClaim    MessageType = 15 // AI Live 领导者申请/释放（会议内共享AI）
+	MessageTypeAILiveStatus   MessageType = 16 // AI Live 状态广播
+	MessageTypeAILiveResult   MessageType = 17 // AI Live 结果广播
+	MessageTypeICERestart     MessageType = 18 // ICE 重启通知（媒体链路中断，要求客户端在原 Peer 上发起 ICE restart）
+	MessageTypeAIStreamResult MessageType = 19 // 服务端 AI 流式结果推送（媒体服务实时分析，字幕/情绪/伪造告警）
+	MessageTypeModerateMedia  MessageType = 20 // 主持人强制静音/关闭画面/停止屏幕共享，或允许取消静音（SFU 执行）
+	MessageTypeMediaModerated MessageType = 21 // 媒体管控状态广播
+	MessageTypeSessionResumed MessageType = 22 // 断线续传成功（随后重放断线期间错过的消息）
+	MessageTypeLobbyStatus    MessageType = 23 // 等候室状态（发给等候者：等待中/已准入/被拒绝）
+	MessageTypeLobbyUpdate    MessageType = 24 // 等候室名单变化（发给主办人/主持人）
+	MessageTypeLobbyAction    MessageType = 25 // 主办人/主持人准入、拒绝或全部准入等候者
+	MessageTypeHostControl    MessageType = 26 // 主持控制：全员静音、移出/封禁、锁定会议、变更角色（请求与广播共用）
+	MessageTypeRaiseHand      MessageType = 27 // 举手/放下（请求与举手队列广播共用）
+	MessageTypeReaction       MessageType = 28 // 表情反应（上行单个反应，下行按时间窗聚合）
+	MessageTypeFeedback       MessageType = 29 // 非语言反馈：同意/反对/慢一点/快一点（请求与广播共用）
+	MessageTypeBreakout       MessageType = 30 // 分组讨论：开启/广播/关闭分组，连接在会议与分组房间之间迁移（仅服务端下发）
+	MessageTypePoll           MessageType = 31 // 投票：创建/投票/结束（请求与结果广播共用）
+	MessageTypeQuestion       MessageType = 32 // 问答：提问/点赞/审核/标记已回答或忽略（请求与广播共用）
+	MessageTypeChatHistory    MessageType = 33 // 聊天历史：入会时推送最近的聊天（仅服务端下发）
+
+	// MessageTypeMax 最大的消息类型，新增类型时同步更新
+	MessageTypeMax = MessageTypeChatHistory
 )
 
 // MessageStatus 消息状态
@@ -105,6 +124,7 @@ type WebSocketMessage struct {
 	PeerID     string      `json:"peer_id,omitempty"`    // Peer ID
 	Payload    interface{} `json:"payload"`              // 消息内容
 	Timestamp  time.Time   `json:"timestamp"`            // 时间戳
+	Seq        uint64      `json:"seq,omitempty"`        // 服务端下发消息在会话内单调递增的序号（断线续传依据）
 }
 
 // WebRTCOffer WebRTC Offer消息
@@ -156,12 +176,35 @@ type UserLeftNotification struct {
 	MeetingID uint   `json:"meeting_id"`
 }
 
-// ChatMessage 聊天消息
+// 聊天动作（ChatMessage.Action，为空时视为发送）
+const (
+	ChatActionSend   = "send"
+	ChatActionEdit   = "edit"
+	ChatActionDelete = "delete"
+)
+
+// ChatMessage 聊天消息（请求与下发共用）。
+// 旧客户端只发送 content；私聊、回复、编辑与删除使用其余字段，下发时 ID 与 CreatedAt 由服务端填写
 type ChatMessage struct {
 	Content   string `json:"content"`
 	UserID    uint   `json:"user_id"`
 	Username  string `json:"username"`
 	MeetingID uint   `json:"meeting_id"`
+
+	ID        string     `json:"id,omitempty"`
+	Action    string     `json:"action,omitempty"`
+	ToUserID  uint       `json:"to_user_id,omitempty"`  // 私聊对象，0 表示发给全员
+	ReplyToID string     `json:"reply_to_id,omitempty"` // 所属话题的首条消息
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	Deleted   bool       `json:"deleted,omitempty"` // 已删除（墓碑，content 为空）
+}
+
+// ChatHistoryMessage 聊天历史（MessageTypeChatHistory 与会议服务历史查询共用），消息按时间正序
+type ChatHistoryMessage struct {
+	Messages []ChatMessage `json:"messages"`
+	// NextCursor 更早消息的分页游标，为空表示没有更多
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // AILiveClaimRequest AI Live 领导者申请/释放
@@ -187,23 +230,93 @@ type AILiveStatusMessage struct {
 
 // AILiveResultMessage AI Live 结果广播（由领导者发送，服务端转发给全员）
 type AILiveResultMessage struct {
-	LineID       string     `json:"line_id"`
-	SpeakerKey   string     `json:"speaker_key,omitempty"`
-	SpeakerLabel string     `json:"speaker_label,omitempty"`
-	TimestampMs  int64      `json:"timestamp_ms,omitempty"`
-	Text         string     `json:"text,omitempty"`
+	LineID       string      `json:"line_id"`
+	SpeakerKey   string      `json:"speaker_key,omitempty"`
+	SpeakerLabel string      `json:"speaker_label,omitempty"`
+	TimestampMs  int64       `json:"timestamp_ms,omitempty"`
+	Text         string      `json:"text,omitempty"`
 	Tags         []AILiveTag `json:"tags,omitempty"`
 }
 
+// AI 流式结果类别（前端据此选择展示方式）
+const (
+	AIResultKindCaption  = "caption"  // 实时字幕
+	AIResultKindEmotion  = "emotion"  // 情绪指示
+	AIResultKindDeepfake = "deepfake" // 伪造告警（音频合成 / 视频换脸）
+	AIResultKindPresence = "presence" // 画面在场检测
+)
+
+// AIStreamResultMessage 服务端 AI 流式结果（媒体服务对上行轨道实时分析后经信令下发给全员）
+// 与 AILiveResultMessage 不同：结果由服务端产生，不依赖客户端领导者；ResultID 在同一流内唯一，用于去重。
+type AIStreamResultMessage struct {
+	ResultID      string          `json:"result_id"` // stream_id/epoch/result_type/sequence
+	Kind          string          `json:"kind"`      // caption, emotion, deepfake, presence
+	ResultType    string          `json:"result_type"`
+	SpeakerUserID uint            `json:"speaker_user_id"`
+	SpeakerPeerID string          `json:"speaker_peer_id,omitempty"`
+	TrackID       string          `json:"track_id,omitempty"`
+	TrackKind     string          `json:"track_kind,omitempty"` // audio, video
+	StreamID      string          `json:"stream_id"`
+	Sequence      int32           `json:"sequence"`
+	IsFinal       bool            `json:"is_final"`
+	Confidence    float64         `json:"confidence"`
+	Text          string          `json:"text,omitempty"`    // 字幕文本
+	Label         string          `json:"label,omitempty"`   // 情绪标签
+	Warning       bool            `json:"warning,omitempty"` // 伪造告警
+	Data          json.RawMessage `json:"data,omitempty"`    // AI 服务原始结果
+	TimestampMs   int64           `json:"timestamp_ms"`
+}
+
+// AIStreamResultRecord AI 流式结果持久化记录（会后检索字幕/告警）
+type AIStreamResultRecord struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ResultID      string    `json:"result_id" gorm:"uniqueIndex;size:255;not null"`
+	MeetingID     uint      `json:"meeting_id" gorm:"not null;index"`
+	SpeakerUserID uint      `json:"speaker_user_id" gorm:"index"`
+	SpeakerPeerID string    `json:"speaker_peer_id" gorm:"size:64"`
+	StreamID      string    `json:"stream_id" gorm:"size:191;index"`
+	TrackID       string    `json:"track_id" gorm:"size:128"`
+	TrackKind     string    `json:"track_kind" gorm:"size:16"`
+	Kind          string    `json:"kind" gorm:"size:32;index"`
+	ResultType    string    `json:"result_type" gorm:"size:64"`
+	Sequence      int32     `json:"sequence"`
+	Confidence    float64   `json:"confidence"`
+	Text          string    `json:"text" gorm:"type:text"`
+	Label         string    `json:"label" gorm:"size:64"`
+	Warning       bool      `json:"warning"`
+	Payload       string    `json:"payload" gorm:"type:text"` // 完整 AIStreamResultMessage JSON
+	ResultAt      time.Time `json:"result_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 // RoomInfoMessage 房间信息
 type RoomInfoMessage struct {
-	MeetingID        uint              `json:"meeting_id"`
-	ParticipantCount int               `json:"participant_count"`
-	SessionID        string            `json:"session_id"`
-	PeerID           string            `json:"peer_id"`
-	IceServers       []RoomICEServer   `json:"ice_servers"`
-	Participants     []RoomParticipant `json:"participants"`
+	MeetingID        uint                 `json:"meeting_id"`
+	ParticipantCount int                  `json:"participant_count"`
+	SessionID        string               `json:"session_id"`
+	PeerID           string               `json:"peer_id"`
+	IceServers       []RoomICEServer      `json:"ice_servers"`
+	Participants     []RoomParticipant    `json:"participants"`
 	AILive           *AILiveStatusMessage `json:"ai_live,omitempty"`
+	// Moderation 当前被强制静音的媒体，供新加入者同步管控状态
+	Moderation []MediaModerationState `json:"moderation,omitempty"`
+	// ScreenShares 正在进行的屏幕共享
+	ScreenShares []ScreenShareState `json:"screen_shares,omitempty"`
+	// RaisedHands 举手队列（按举手先后排序）
+	RaisedHands []RaisedHand `json:"raised_hands,omitempty"`
+	// Feedback 参与者当前的非语言反馈
+	Feedback []FeedbackState `json:"feedback,omitempty"`
+	// ResumeToken 断线后携带该令牌与最后连续收到的 seq 重连即可续传原会话
+	ResumeToken string `json:"resume_token,omitempty"`
+}
+
+// SessionResumedMessage 续传成功确认
+type SessionResumedMessage struct {
+	SessionID   string `json:"session_id"`
+	ResumeToken string `json:"resume_token"`
+	// LastSeq 客户端声明已收到的最后序号，其后的消息紧接着按序重放
+	LastSeq  uint64 `json:"last_seq"`
+	Replayed int    `json:"replayed"`
 }
 
 // RoomParticipant 房间参与者快照
@@ -232,6 +345,374 @@ type MediaControlMessage struct {
 	PeerID    string `json:"peer_id"`
 }
 
+// 服务端媒体管控的媒体类型
+const (
+	ModerationMediaAudio  = "audio"
+	ModerationMediaVideo  = "video"
+	ModerationMediaScreen = "screen"
+)
+
+// ValidModerationMedia 是否为可管控的媒体类型
+func ValidModerationMedia(mediaType string) bool {
+	switch mediaType {
+	case ModerationMediaAudio, ModerationMediaVideo, ModerationMediaScreen:
+		return true
+	}
+	return false
+}
+
+// ModerateMediaMessage 主持人/管理员的媒体管控请求：Muted=true 强制静音，false 允许对方取消静音
+type ModerateMediaMessage struct {
+	TargetUserID uint   `json:"target_user_id"`
+	MediaType    string `json:"media_type"` // "audio", "video", "screen"
+	Muted        bool   `json:"muted"`
+}
+
+// MediaModerationState 参与者某类媒体的管控状态（由信令服务广播给所有人）
+type MediaModerationState struct {
+	UserID    uint      `json:"user_id"`
+	MediaType string    `json:"media_type"`
+	Muted     bool      `json:"muted"`
+	ByUserID  uint      `json:"by_user_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 屏幕共享请求动作与广播事件
+const (
+	ScreenShareActionStart = "start"
+	ScreenShareActionStop  = "stop"
+
+	ScreenShareEventStarted  = "started"
+	ScreenShareEventStopped  = "stopped"
+	ScreenShareEventTakeover = "takeover"
+)
+
+// 屏幕共享内容提示（与 MediaStreamTrack.contentHint 一致）
+const (
+	ScreenShareHintMotion = "motion"
+	ScreenShareHintDetail = "detail"
+	ScreenShareHintText   = "text"
+)
+
+// ScreenShareMessage 客户端的屏幕共享请求（MessageTypeScreenShare）
+// StreamID 为屏幕共享 MediaStream 的 ID，SFU 据此（或 ID 中的 "screen" 标记）把轨道标记为屏幕共享
+type ScreenShareMessage struct {
+	Action      string `json:"action"` // "start", "stop"
+	StreamID    string `json:"stream_id,omitempty"`
+	TrackID     string `json:"track_id,omitempty"`
+	ContentHint string `json:"content_hint,omitempty"` // "motion", "detail", "text"
+}
+
+// ScreenShareState 正在进行的屏幕共享
+type ScreenShareState struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	PeerID      string    `json:"peer_id"`
+	StreamID    string    `json:"stream_id,omitempty"`
+	TrackID     string    `json:"track_id,omitempty"`
+	ContentHint string    `json:"content_hint,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// ScreenShareEvent 屏幕共享事件广播（MessageTypeScreenShare，由信令服务发出）
+type ScreenShareEvent struct {
+	Event string           `json:"event"` // "started", "stopped", "takeover"
+	Share ScreenShareState `json:"share"`
+	// PreviousUserID 被接管的共享者（仅 takeover）
+	PreviousUserID uint `json:"previous_user_id,omitempty"`
+	// ByUserID 触发事件的用户（接管者，或被动结束时为 0）
+	ByUserID  uint      `json:"by_user_id,omitempty"`
+	Exclusive bool      `json:"exclusive"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// 等候室准入动作、等候者状态与名单变化事件
+const (
+	LobbyActionAdmit    = "admit"
+	LobbyActionDeny     = "deny"
+	LobbyActionAdmitAll = "admit_all"
+
+	LobbyStateWaiting  = "waiting"
+	LobbyStateAdmitted = "admitted"
+	LobbyStateDenied   = "denied"
+
+	LobbyEventSnapshot = "snapshot"
+	LobbyEventJoined   = "joined"
+	LobbyEventLeft     = "left"
+	LobbyEventAdmitted = "admitted"
+	LobbyEventDenied   = "denied"
+)
+
+// LobbyActionMessage 主办人/主持人的等候室操作（admit_all 时忽略 UserID）
+type LobbyActionMessage struct {
+	Action string `json:"action"` // "admit", "deny", "admit_all"
+	UserID uint   `json:"user_id,omitempty"`
+}
+
+// LobbyStatusMessage 等候者自身的准入状态
+type LobbyStatusMessage struct {
+	State     string `json:"state"` // "waiting", "admitted", "denied"
+	MeetingID uint   `json:"meeting_id"`
+	ByUserID  uint   `json:"by_user_id,omitempty"`
+}
+
+// LobbyEntry 等候室中的用户
+type LobbyEntry struct {
+	UserID       uint      `json:"user_id"`
+	Username     string    `json:"username"`
+	WaitingSince time.Time `json:"waiting_since"`
+}
+
+// LobbyUpdateMessage 等候室名单变化（snapshot 时 Entries 为完整名单，其余事件为变化的用户）
+type LobbyUpdateMessage struct {
+	Event    string       `json:"event"` // "snapshot", "joined", "left", "admitted", "denied"
+	Entries  []LobbyEntry `json:"entries"`
+	ByUserID uint         `json:"by_user_id,omitempty"`
+}
+
+// 主持控制动作
+const (
+	HostControlMuteAll = "mute_all"
+	HostControlRemove  = "remove"
+	HostControlBan     = "ban"
+	HostControlLock    = "lock"
+	HostControlUnlock  = "unlock"
+	HostControlSetRole = "set_role"
+)
+
+// HostControlMessage 主办人/主持人的主持控制请求（MessageTypeHostControl）
+type HostControlMessage struct {
+	Action       string `json:"action"` // "mute_all", "remove", "ban", "lock", "unlock", "set_role"
+	TargetUserID uint   `json:"target_user_id,omitempty"`
+	// AllowSelfUnmute 全员静音后是否允许参与者自行取消静音（false 时由 SFU 强制静音）
+	AllowSelfUnmute bool            `json:"allow_self_unmute,omitempty"`
+	Role            ParticipantRole `json:"role,omitempty"` // set_role 的新角色
+}
+
+// HostControlEvent 主持控制结果广播（MessageTypeHostControl，由信令服务发出）
+type HostControlEvent struct {
+	Action          string          `json:"action"`
+	TargetUserID    uint            `json:"target_user_id,omitempty"`
+	AllowSelfUnmute bool            `json:"allow_self_unmute,omitempty"`
+	Role            ParticipantRole `json:"role,omitempty"`
+	Locked          bool            `json:"locked"`
+	ByUserID        uint            `json:"by_user_id"`
+	Timestamp       time.Time       `json:"timestamp"`
+}
+
+// 举手动作
+const (
+	RaiseHandActionRaise    = "raise"
+	RaiseHandActionLower    = "lower"
+	RaiseHandActionLowerAll = "lower_all"
+)
+
+// 举手队列事件
+const (
+	HandEventRaised     = "raised"
+	HandEventLowered    = "lowered"
+	HandEventLoweredAll = "lowered_all"
+)
+
+// RaiseHandMessage 举手/放下请求（MessageTypeRaiseHand）
+type RaiseHandMessage struct {
+	Action string `json:"action"`            // "raise", "lower", "lower_all"
+	UserID uint   `json:"user_id,omitempty"` // 主办人/主持人放下他人的手时指定
+}
+
+// RaisedHand 举手队列中的一项
+type RaisedHand struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	RaisedAt time.Time `json:"raised_at"`
+}
+
+// RaiseHandEvent 举手队列变化广播，Queue 为变化后的完整队列
+type RaiseHandEvent struct {
+	Event     string       `json:"event"` // "raised", "lowered", "lowered_all"
+	UserID    uint         `json:"user_id,omitempty"`
+	ByUserID  uint         `json:"by_user_id"`
+	Queue     []RaisedHand `json:"queue"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// 表情反应
+const (
+	ReactionThumbsUp  = "thumbs_up"
+	ReactionClap      = "clap"
+	ReactionHeart     = "heart"
+	ReactionLaugh     = "laugh"
+	ReactionSurprised = "surprised"
+	ReactionCelebrate = "celebrate"
+)
+
+// ValidReaction 是否为支持的表情反应
+func ValidReaction(reaction string) bool {
+	switch reaction {
+	case ReactionThumbsUp, ReactionClap, ReactionHeart, ReactionLaugh, ReactionSurprised, ReactionCelebrate:
+		return true
+	}
+	return false
+}
+
+// ReactionMessage 发送表情反应（MessageTypeReaction）
+type ReactionMessage struct {
+	Reaction string `json:"reaction"`
+}
+
+// ReactionCount 一个时间窗内某种反应的数量；数量较少时列出发送者
+type ReactionCount struct {
+	Reaction string `json:"reaction"`
+	Count    int    `json:"count"`
+	UserIDs  []uint `json:"user_ids,omitempty"`
+}
+
+// ReactionBurstMessage 表情反应广播：小房间逐条下发，大房间按时间窗聚合
+type ReactionBurstMessage struct {
+	Reactions  []ReactionCount `json:"reactions"`
+	Aggregated bool            `json:"aggregated"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
+
+// 非语言反馈
+const (
+	FeedbackYes    = "yes"
+	FeedbackNo     = "no"
+	FeedbackSlower = "slower"
+	FeedbackFaster = "faster"
+)
+
+// ValidFeedback 是否为支持的反馈（空字符串表示清除）
+func ValidFeedback(feedback string) bool {
+	switch feedback {
+	case "", FeedbackYes, FeedbackNo, FeedbackSlower, FeedbackFaster:
+		return true
+	}
+	return false
+}
+
+// FeedbackMessage 设置或清除非语言反馈（MessageTypeFeedback）
+type FeedbackMessage struct {
+	Feedback string `json:"feedback"` // 空字符串表示清除
+}
+
+// FeedbackState 参与者的非语言反馈（广播与 RoomInfo 同步共用，Feedback 为空表示已清除）
+type FeedbackState struct {
+	UserID    uint      `json:"user_id"`
+	Feedback  string    `json:"feedback"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 分组讨论事件
+const (
+	BreakoutEventOpened    = "opened"    // 分组已开启（广播到主会议房间，各节点迁移被分配的连接）
+	BreakoutEventMoved     = "moved"     // 本连接已迁移到 MeetingID/BreakoutID 对应的房间（发给被迁移的连接）
+	BreakoutEventBroadcast = "broadcast" // 主办人向所有分组广播的消息
+	BreakoutEventClosed    = "closed"    // 分组已关闭（广播到各分组房间，各节点把连接迁回主会议）
+)
+
+// BreakoutAssignment 参与者的分组分配
+type BreakoutAssignment struct {
+	UserID     uint `json:"user_id"`
+	BreakoutID uint `json:"breakout_id"`
+}
+
+// BreakoutEvent 分组讨论广播。MeetingID 为主会议，BreakoutID 为 moved 事件的目标房间（迁回主会议时等于 MeetingID）
+type BreakoutEvent struct {
+	Event       string               `json:"event"`
+	MeetingID   uint                 `json:"meeting_id"`
+	BreakoutID  uint                 `json:"breakout_id,omitempty"`
+	BreakoutIDs []uint               `json:"breakout_ids,omitempty"`
+	Assignments []BreakoutAssignment `json:"assignments,omitempty"`
+	Message     string               `json:"message,omitempty"`
+	ClosesAt    *time.Time           `json:"closes_at,omitempty"`
+	ByUserID    uint                 `json:"by_user_id"`
+	Timestamp   time.Time            `json:"timestamp"`
+}
+
+// 投票操作
+const (
+	PollActionCreate = "create"
+	PollActionVote   = "vote"
+	PollActionClose  = "close"
+)
+
+// 投票事件
+const (
+	PollEventCreated = "created" // 新投票（不含结果）
+	PollEventResults = "results" // 结果更新：实时公开的投票广播给全员，隐藏结果的投票只发给主办人/主持人
+	PollEventClosed  = "closed"  // 投票结束，结果对全员公开
+)
+
+// PollMessage 投票请求（MessageTypePoll）
+type PollMessage struct {
+	Action string `json:"action"` // "create", "vote", "close"
+	PollID uint   `json:"poll_id,omitempty"`
+
+	// create
+	Question          string   `json:"question,omitempty"`
+	Options           []string `json:"options,omitempty"`
+	MultipleChoice    bool     `json:"multiple_choice,omitempty"`
+	Anonymous         bool     `json:"anonymous,omitempty"`
+	ResultsVisibility string   `json:"results_visibility,omitempty"` // "live"（默认）或 "hidden"
+
+	// vote：单选时只能有一项，重复投票覆盖之前的选择
+	OptionIDs []uint `json:"option_ids,omitempty"`
+}
+
+// PollEvent 投票广播
+type PollEvent struct {
+	Event     string    `json:"event"`
+	Poll      PollState `json:"poll"`
+	ByUserID  uint      `json:"by_user_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// 问答操作
+const (
+	QuestionActionAsk     = "ask"
+	QuestionActionUpvote  = "upvote"
+	QuestionActionUnvote  = "unvote"
+	QuestionActionApprove = "approve"
+	QuestionActionAnswer  = "answer"
+	QuestionActionDismiss = "dismiss"
+)
+
+// 问答事件
+const (
+	QuestionEventAsked     = "asked"     // 新问题（待审核的问题只发给主办人/主持人与提问者）
+	QuestionEventUpvoted   = "upvoted"   // 点赞数变化
+	QuestionEventApproved  = "approved"  // 审核通过，对全员可见
+	QuestionEventAnswered  = "answered"  // 已回答
+	QuestionEventDismissed = "dismissed" // 已忽略
+)
+
+// QuestionMessage 问答请求（MessageTypeQuestion）
+type QuestionMessage struct {
+	Action     string `json:"action"` // "ask", "upvote", "unvote", "approve", "answer", "dismiss"
+	QuestionID uint   `json:"question_id,omitempty"`
+	Text       string `json:"text,omitempty"`   // ask
+	Answer     string `json:"answer,omitempty"` // answer：可选的文字回答
+}
+
+// QuestionEvent 问答广播
+type QuestionEvent struct {
+	Event     string          `json:"event"`
+	Question  MeetingQuestion `json:"question"`
+	ByUserID  uint            `json:"by_user_id"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// ICERestartMessage ICE 重启通知（由媒体服务在连接 disconnected/failed 时下发）
+// 客户端收到后应在原 PeerConnection 上 createOffer({iceRestart: true})，并提交到媒体服务的 restart 接口；
+// 宽限期内恢复则保留所有转发/订阅关系，超时未恢复则服务端清理该 Peer。
+type ICERestartMessage struct {
+	PeerID        string `json:"peer_id"`
+	RoomID        string `json:"room_id"`
+	Reason        string `json:"reason"`          // disconnected, failed
+	GracePeriodMs int64  `json:"grace_period_ms"` // 服务端等待恢复的时长
+}
+
 // ErrorMessage 错误消息
 type ErrorMessage struct {
 	Code    int    `json:"code"`
@@ -284,6 +765,44 @@ func (mt MessageType) String() string {
 		return "error"
 	case MessageTypeRoomInfo:
 		return "room-info"
+	case MessageTypeAILiveClaim:
+		return "ai-live-claim"
+	case MessageTypeAILiveStatus:
+		return "ai-live-status"
+	case MessageTypeAILiveResult:
+		return "ai-live-result"
+	case MessageTypeICERestart:
+		return "ice-restart"
+	case MessageTypeAIStreamResult:
+		return "ai-stream-result"
+	case MessageTypeModerateMedia:
+		return "moderate-media"
+	case MessageTypeMediaModerated:
+		return "media-moderated"
+	case MessageTypeSessionResumed:
+		return "session-resumed"
+	case MessageTypeLobbyStatus:
+		return "lobby-status"
+	case MessageTypeLobbyUpdate:
+		return "lobby-update"
+	case MessageTypeLobbyAction:
+		return "lobby-action"
+	case MessageTypeHostControl:
+		return "host-control"
+	case MessageTypeRaiseHand:
+		return "raise-hand"
+	case MessageTypeReaction:
+		return "reaction"
+	case MessageTypeFeedback:
+		return "feedback"
+	case MessageTypeBreakout:
+		return "breakout"
+	case MessageTypePoll:
+		return "poll"
+	case MessageTypeQuestion:
+		return "question"
+	case MessageTypeChatHistory:
+		return "chat-history"
 	default:
 		return "unknown"
 	}
@@ -30,6 +30,7 @@ const (
     ChannelMediaEvents    = "media_events"
     ChannelAIEvents       = "ai_events"
     ChannelSignalingEvents= "signaling_events"
+    ChannelSignalingCluster = "signaling_cluster" // 信令节点间的房间消息扇出（每个节点都需收到，不能按消费组分摊）
 
     // Meeting events
     EventMeetingCreated   = "meeting.created"
@@ -37,6 +38,14 @@ const (
     EventMeetingEnded     = "meeting.ended"
     EventUserJoined       = "meeting.user_joined"
     EventUserLeft         = "meeting.user_left"
+    EventLobbyAdmitted    = "meeting.lobby_admitted" // 等候室准入：信令服务把等候中的连接加入房间
+    EventLobbyDenied      = "meeting.lobby_denied"   // 等候室拒绝：信令服务通知并断开等候中的连接
+    EventHostControl      = "meeting.host_control"   // 主持控制（REST 发起）：信令服务执行全员静音、断开被移出者、广播锁定与角色变化
+    EventBreakoutOpened   = "meeting.breakout_opened"    // 分组讨论开启：信令服务把被分配的连接迁移到分组房间
+    EventBreakoutBroadcast = "meeting.breakout_broadcast" // 主办人向所有分组广播消息
+    EventBreakoutClosed   = "meeting.breakout_closed"    // 分组讨论关闭：信令服务把分组房间的连接迁回主会议
+    EventChatMessage      = "meeting.chat_message"       // 聊天消息（REST 发送、编辑或删除）：信令服务按可见范围推送
+    EventMeetingSettingsUpdated = "meeting.settings_updated" // 会议设置变更：媒体服务清除该会议房间缓存的编解码策略
 
     // Media events
     EventRecordingStarted = "recording.started"
@@ -48,5 +57,23 @@ const (
     EventASRCompleted       = "speech_recognition.completed"
     EventEmotionCompleted   = "emotion_detection.completed"
     EventDeepfakeCompleted  = "deepfake_detection.completed"
+    EventAIStreamResult     = "ai.stream_result" // 媒体服务实时 AI 结果，由信令服务推送给会议参与者
+
+    // Signaling events
+    EventAILiveEnabled      = "ai_live.enabled"  // 服务端 AI Live 开启：媒体服务对会议内所有音频轨道运行 AI
+    EventAILiveDisabled     = "ai_live.disabled"
+    EventMediaModeration    = "media.moderation" // 主持人媒体管控：媒体服务停止/恢复转发参与者的音频、视频或屏幕共享
+    EventScreenShareStarted = "screen_share.started" // 屏幕共享开始/接管：独占模式下媒体服务只转发当前共享者的屏幕轨道
+    EventScreenShareStopped = "screen_share.stopped"
+    EventBreakoutMoved      = "breakout.moved" // 连接迁移到分组/主会议房间：媒体服务把该用户的 Peer 迁移到新房间
+    EventParticipantRemoved = "participant.removed" // 主持人移出/封禁用户：媒体服务关闭该用户在会议房间内的 Peer
+    EventICERestart         = "webrtc.ice_restart" // 媒体链路中断（媒体服务发布）：信令服务通知该用户的客户端在原 Peer 上发起 ICE restart
+
+    // Signaling cluster events（仅在信令节点之间传递）
+    EventClusterRoomBroadcast = "cluster.room_broadcast" // 房间广播：各节点投递给本地连接
+    EventClusterUserForward   = "cluster.user_forward"   // 定向消息：各节点投递给目标用户在本地的会话
+    EventClusterRoomInfo      = "cluster.room_info"      // 房间成员变化：各节点向本地连接重发 RoomInfo
+    EventClusterModeratorBroadcast = "cluster.moderator_broadcast" // 房间内仅主办人/主持人可见的消息：各节点投递给本地的主办人/主持人
+    EventClusterSessionTakeover    = "cluster.session_takeover"    // 断线续传落到其他节点：原节点立即注销挂起的会话
 )
 
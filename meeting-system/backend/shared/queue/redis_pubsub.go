@@ -0,0 +1,166 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"meeting-system/shared/logger"
+)
+
+// RedisPubSubQueue 基于 Redis PUB/SUB 的事件总线。与 KafkaPubSub 的消费组不同，
+// 每个订阅的实例都会收到全部消息（不持久化），适合信令节点间的广播扇出
+type RedisPubSubQueue struct {
+	client *redis.Client
+
+	subscriptions map[string][]PubSubHandler
+	pubsubs       map[string]*redis.PubSub
+
+	subMutex sync.RWMutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	stats struct {
+		totalPublished uint64
+		totalReceived  uint64
+		totalProcessed uint64
+		totalFailed    uint64
+	}
+}
+
+// NewRedisPubSubQueue 创建 Redis 发布订阅实例
+func NewRedisPubSubQueue(client *redis.Client) *RedisPubSubQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisPubSubQueue{
+		client:        client,
+		subscriptions: make(map[string][]PubSubHandler),
+		pubsubs:       make(map[string]*redis.PubSub),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Subscribe 订阅频道（返回时订阅已生效，之后发布的消息都会收到）
+func (b *RedisPubSubQueue) Subscribe(channel string, handler PubSubHandler) error {
+	b.subMutex.Lock()
+	defer b.subMutex.Unlock()
+
+	if _, exists := b.pubsubs[channel]; !exists {
+		ps := b.client.Subscribe(b.ctx, channel)
+		if _, err := ps.Receive(b.ctx); err != nil {
+			_ = ps.Close()
+			return fmt.Errorf("failed to subscribe Redis channel %s: %w", channel, err)
+		}
+		b.pubsubs[channel] = ps
+
+		b.wg.Add(1)
+		go b.receiveLoop(channel, ps)
+	}
+	b.subscriptions[channel] = append(b.subscriptions[channel], handler)
+
+	logger.Info(fmt.Sprintf("Redis subscribed to channel: %s", channel))
+	return nil
+}
+
+// Publish 发布消息
+func (b *RedisPubSubQueue) Publish(ctx context.Context, channel string, msg *PubSubMessage) error {
+	if msg == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+	if msg.MessageID == "" {
+		msg.MessageID = generateMessageID()
+	}
+	if msg.Timestamp == 0 {
+		msg.Timestamp = time.Now().Unix()
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pubsub message: %w", err)
+	}
+	if err := b.client.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish Redis pubsub message: %w", err)
+	}
+
+	atomic.AddUint64(&b.stats.totalPublished, 1)
+	return nil
+}
+
+// Start 启动事件总线（订阅在 Subscribe 时建立）
+func (b *RedisPubSubQueue) Start() error {
+	logger.Info("Redis pubsub initialized")
+	return nil
+}
+
+// Stop 停止事件总线
+func (b *RedisPubSubQueue) Stop() error {
+	logger.Info("Stopping Redis pubsub...")
+	b.cancel()
+
+	b.subMutex.Lock()
+	for _, ps := range b.pubsubs {
+		_ = ps.Close()
+	}
+	b.subMutex.Unlock()
+	b.wg.Wait()
+
+	logger.Info("Redis pubsub stopped")
+	return nil
+}
+
+func (b *RedisPubSubQueue) receiveLoop(channel string, ps *redis.PubSub) {
+	defer b.wg.Done()
+
+	ch := ps.Channel()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case rMsg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var msg PubSubMessage
+			if err := json.Unmarshal([]byte(rMsg.Payload), &msg); err != nil {
+				logger.Error(fmt.Sprintf("Failed to decode Redis pubsub message: %v", err))
+				continue
+			}
+
+			atomic.AddUint64(&b.stats.totalReceived, 1)
+			b.dispatch(channel, &msg)
+		}
+	}
+}
+
+func (b *RedisPubSubQueue) dispatch(channel string, msg *PubSubMessage) {
+	b.subMutex.RLock()
+	handlers := b.subscriptions[channel]
+	b.subMutex.RUnlock()
+
+	for _, h := range handlers {
+		ctx, cancel := context.WithTimeout(b.ctx, 10*time.Second)
+		if err := h(ctx, msg); err != nil {
+			logger.Error(fmt.Sprintf("Redis pubsub handler error on %s: %v", channel, err))
+			atomic.AddUint64(&b.stats.totalFailed, 1)
+		} else {
+			atomic.AddUint64(&b.stats.totalProcessed, 1)
+		}
+		cancel()
+	}
+}
+
+// GetStats 返回统计信息
+func (b *RedisPubSubQueue) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"total_published": atomic.LoadUint64(&b.stats.totalPublished),
+		"total_received":  atomic.LoadUint64(&b.stats.totalReceived),
+		"total_processed": atomic.LoadUint64(&b.stats.totalProcessed),
+		"total_failed":    atomic.LoadUint64(&b.stats.totalFailed),
+	}
+}
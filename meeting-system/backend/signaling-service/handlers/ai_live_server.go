@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+const (
+	// aiLiveModeServer 服务端 AI Live：媒体服务对所有音频轨道运行 AI，信令服务以 AILiveResultMessage 广播结果
+	aiLiveModeServer = "server"
+	// serverAILiveSessionID 服务端模式下的领导者会话标识，不对应任何客户端，客户端据此进入跟随模式
+	serverAILiveSessionID = "server"
+	serverAILiveUsername  = "服务端 AI"
+	// aiLiveLineCapacity 每个房间保留的字幕行数，用于把同一片段的字幕/情绪/告警合并为一行
+	aiLiveLineCapacity = 200
+	aiLiveEventTimeout = 3 * time.Second
+)
+
+// EventPublisher 事件发布接口（生产环境为 Kafka 事件总线）
+type EventPublisher interface {
+	Publish(ctx context.Context, channel string, msg *queue.PubSubMessage) error
+}
+
+// aiLiveLine 服务端 AI Live 的一行结果，按标签类别累积
+type aiLiveLine struct {
+	result models.AILiveResultMessage
+	tags   map[string]models.AILiveTag
+}
+
+// aiLiveLineCache 房间内最近的 AI Live 行（超出容量时淘汰最早的行）
+type aiLiveLineCache struct {
+	lines map[string]*aiLiveLine
+	order []string
+}
+
+// aiLiveTagOrder 标签展示顺序与浏览器领导者模式一致
+var aiLiveTagOrder = []string{models.AIResultKindCaption, models.AIResultKindDeepfake, models.AIResultKindEmotion}
+
+// SetEventPublisher 设置事件发布器，用于通知媒体服务开启服务端 AI Live
+func (h *WebSocketHandler) SetEventPublisher(publisher EventPublisher) {
+	h.events = publisher
+}
+
+func serverAILiveConfigured() bool {
+	cfg := config.GlobalConfig
+	return cfg != nil && strings.EqualFold(cfg.Signaling.AILive.Mode, aiLiveModeServer)
+}
+
+func serverAILiveStatus() models.AILiveStatusMessage {
+	return models.AILiveStatusMessage{
+		Enabled:         true,
+		LeaderSessionID: serverAILiveSessionID,
+		LeaderUsername:  serverAILiveUsername,
+		UpdatedAt:       time.Now(),
+	}
+}
+
+// publishAILiveControl 通知媒体服务开启/关闭会议的服务端 AI Live
+func (h *WebSocketHandler) publishAILiveControl(meetingID uint, enabled bool) {
+	if h.events == nil {
+		return
+	}
+
+	eventType := queue.EventAILiveDisabled
+	if enabled {
+		eventType = queue.EventAILiveEnabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiLiveEventTimeout)
+	defer cancel()
+	if err := h.events.Publish(ctx, queue.ChannelSignalingEvents, &queue.PubSubMessage{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"meeting_id": meetingID,
+		},
+		Source: "signaling-service",
+	}); err != nil {
+		logger.Warn("Failed to publish AI Live control event",
+			logger.Uint("meeting_id", meetingID),
+			logger.String("event", eventType),
+			logger.Err(err))
+	}
+}
+
+// broadcastServerAILiveResult 服务端模式下把 AI 流式结果转换为 AILiveResultMessage 广播，客户端无需改动。
+// 同一音频片段的字幕、情绪和合成告警合并到同一行，每次广播完整的一行。
+func (h *WebSocketHandler) broadcastServerAILiveResult(meetingID uint, result *models.AIStreamResultMessage) {
+	tag, ok := serverAILiveTag(result)
+	if !ok {
+		return
+	}
+
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return
+	}
+
+	room.mutex.Lock()
+	speakerKey, speakerLabel := fmt.Sprintf("user_%d", result.SpeakerUserID), ""
+	for _, client := range room.Clients {
+		if client.UserID == result.SpeakerUserID {
+			speakerKey, speakerLabel = client.PeerID, client.Username
+			break
+		}
+	}
+	if speakerLabel == "" {
+		speakerLabel = fmt.Sprintf("user_%d", result.SpeakerUserID)
+	}
+
+	if room.aiLiveLines == nil {
+		room.aiLiveLines = &aiLiveLineCache{lines: make(map[string]*aiLiveLine)}
+	}
+	line := room.aiLiveLines.get(serverAILiveLineID(result), result.TimestampMs)
+	line.result.SpeakerKey = speakerKey
+	line.result.SpeakerLabel = speakerLabel
+	if result.Kind == models.AIResultKindCaption {
+		line.result.Text = result.Text
+	}
+	line.tags[result.Kind] = tag
+	line.result.Tags = line.result.Tags[:0]
+	for _, kind := range aiLiveTagOrder {
+		if t, ok := line.tags[kind]; ok {
+			line.result.Tags = append(line.result.Tags, t)
+		}
+	}
+	payload := line.result
+	payload.Tags = append([]models.AILiveTag(nil), line.result.Tags...)
+	room.mutex.Unlock()
+
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:        fmt.Sprintf("ai_live_result_%d", time.Now().UnixNano()),
+		Type:      models.MessageTypeAILiveResult,
+		MeetingID: meetingID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}, "")
+}
+
+// serverAILiveLineID 同一流、同一片段（序列号）的结果属于同一行
+func serverAILiveLineID(result *models.AIStreamResultMessage) string {
+	lineID := strings.Replace(result.ResultID, "/"+result.ResultType+"/", "/", 1)
+	return "server/" + lineID
+}
+
+// serverAILiveTag 与浏览器领导者模式相同的标签文案；在场检测不进入 AI Live
+func serverAILiveTag(result *models.AIStreamResultMessage) (models.AILiveTag, bool) {
+	percent := int(result.Confidence*100 + 0.5)
+	switch result.Kind {
+	case models.AIResultKindCaption:
+		return models.AILiveTag{Text: fmt.Sprintf("ASR %d%%", percent), Kind: "ok"}, true
+	case models.AIResultKindEmotion:
+		return models.AILiveTag{Text: fmt.Sprintf("情绪 %s %d%%", result.Label, percent), Kind: "ok"}, true
+	case models.AIResultKindDeepfake:
+		if !result.Warning {
+			return models.AILiveTag{}, false
+		}
+		return models.AILiveTag{Text: fmt.Sprintf("疑似合成 %d%%", percent), Kind: "warn"}, true
+	default:
+		return models.AILiveTag{}, false
+	}
+}
+
+// get 获取或创建一行；调用方持有房间锁
+func (c *aiLiveLineCache) get(lineID string, timestampMs int64) *aiLiveLine {
+	if line, ok := c.lines[lineID]; ok {
+		return line
+	}
+	if len(c.order) >= aiLiveLineCapacity {
+		delete(c.lines, c.order[0])
+		c.order = c.order[1:]
+	}
+	line := &aiLiveLine{
+		result: models.AILiveResultMessage{LineID: lineID, TimestampMs: timestampMs},
+		tags:   make(map[string]models.AILiveTag),
+	}
+	c.lines[lineID] = line
+	c.order = append(c.order, lineID)
+	return line
+}
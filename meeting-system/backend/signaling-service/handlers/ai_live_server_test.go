@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+type recordingEvents struct {
+	mu       sync.Mutex
+	messages []*queue.PubSubMessage
+}
+
+func (r *recordingEvents) Publish(_ context.Context, _ string, msg *queue.PubSubMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func newServerAILiveHandler(meetingID uint) (*WebSocketHandler, *Client) {
+	h := &WebSocketHandler{
+		clients:      make(map[string]*Client),
+		rooms:        make(map[uint]*Room),
+		aiResults:    newResultDeduper(aiResultDedupCapacity),
+		serverAILive: true,
+	}
+	client := &Client{ID: "session-1", UserID: 7, PeerID: "peer-7", Username: "alice", Send: make(chan []byte, 16)}
+	client.setMeetingID(meetingID)
+	h.clients[client.ID] = client
+	h.rooms[meetingID] = &Room{ID: meetingID, Clients: map[string]*Client{client.ID: client}, AILive: serverAILiveStatus()}
+	return h, client
+}
+
+func drainMessages(t *testing.T, client *Client) []models.WebSocketMessage {
+	t.Helper()
+	var out []models.WebSocketMessage
+	for {
+		select {
+		case data := <-client.Send:
+			var msg models.WebSocketMessage
+			require.NoError(t, json.Unmarshal(data, &msg))
+			out = append(out, msg)
+		default:
+			return out
+		}
+	}
+}
+
+// TestDeliverAIStreamResult_ServerAILive 服务端模式下结果按片段合并为 AILiveResultMessage，重复结果只推送一次
+func TestDeliverAIStreamResult_ServerAILive(t *testing.T) {
+	h, client := newServerAILiveHandler(1)
+
+	caption := &models.AIStreamResultMessage{
+		ResultID: "peer_audio/100/speech_recognition/3", ResultType: "speech_recognition",
+		Kind: models.AIResultKindCaption, SpeakerUserID: 7, Sequence: 3, Confidence: 0.93, Text: "你好", TimestampMs: 1000,
+	}
+	emotion := &models.AIStreamResultMessage{
+		ResultID: "peer_audio/100/emotion_detection/3", ResultType: "emotion_detection",
+		Kind: models.AIResultKindEmotion, SpeakerUserID: 7, Sequence: 3, Confidence: 0.8, Label: "happy", TimestampMs: 1200,
+	}
+
+	assert.True(t, h.DeliverAIStreamResult(1, caption))
+	assert.False(t, h.DeliverAIStreamResult(1, caption), "重复结果不再推送")
+	assert.True(t, h.DeliverAIStreamResult(1, emotion))
+
+	messages := drainMessages(t, client)
+	require.Len(t, messages, 4)
+	assert.Equal(t, models.MessageTypeAIStreamResult, messages[0].Type)
+	assert.Equal(t, models.MessageTypeAILiveResult, messages[1].Type)
+	assert.Equal(t, models.MessageTypeAIStreamResult, messages[2].Type)
+	assert.Equal(t, models.MessageTypeAILiveResult, messages[3].Type)
+
+	var first, merged models.AILiveResultMessage
+	data, _ := json.Marshal(messages[1].Payload)
+	require.NoError(t, json.Unmarshal(data, &first))
+	data, _ = json.Marshal(messages[3].Payload)
+	require.NoError(t, json.Unmarshal(data, &merged))
+
+	assert.Equal(t, "server/peer_audio/100/3", first.LineID)
+	assert.Equal(t, "peer-7", first.SpeakerKey)
+	assert.Equal(t, "alice", first.SpeakerLabel)
+	assert.Equal(t, int64(1000), first.TimestampMs)
+	assert.Equal(t, []models.AILiveTag{{Text: "ASR 93%", Kind: "ok"}}, first.Tags)
+
+	assert.Equal(t, first.LineID, merged.LineID)
+	assert.Equal(t, "你好", merged.Text)
+	assert.Equal(t, []models.AILiveTag{{Text: "ASR 93%", Kind: "ok"}, {Text: "情绪 happy 80%", Kind: "ok"}}, merged.Tags)
+}
+
+// TestHandleAILiveClaim_ServerMode 服务端模式下浏览器不能成为领导者
+func TestHandleAILiveClaim_ServerMode(t *testing.T) {
+	h, client := newServerAILiveHandler(1)
+	client.Handler = h
+
+	client.handleAILiveClaim(&models.WebSocketMessage{Type: models.MessageTypeAILiveClaim, Payload: map[string]interface{}{"enable": true}})
+
+	status := h.getAILiveStatus(1)
+	require.NotNil(t, status)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, serverAILiveSessionID, status.LeaderSessionID)
+
+	messages := drainMessages(t, client)
+	require.Len(t, messages, 1)
+	assert.Equal(t, models.MessageTypeAILiveStatus, messages[0].Type)
+}
+
+func TestPublishAILiveControl(t *testing.T) {
+	h, _ := newServerAILiveHandler(5)
+	events := &recordingEvents{}
+	h.SetEventPublisher(events)
+
+	h.publishAILiveControl(5, true)
+	h.publishAILiveControl(5, false)
+
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, queue.EventAILiveEnabled, events.messages[0].Type)
+	assert.Equal(t, uint(5), events.messages[0].Payload["meeting_id"])
+	assert.Equal(t, queue.EventAILiveDisabled, events.messages[1].Type)
+}
+
+func TestAILiveLineCacheEviction(t *testing.T) {
+	cache := &aiLiveLineCache{lines: make(map[string]*aiLiveLine)}
+	for i := 0; i < aiLiveLineCapacity+1; i++ {
+		cache.get(strconv.Itoa(i), 0)
+	}
+	assert.Len(t, cache.lines, aiLiveLineCapacity)
+	_, ok := cache.lines["0"]
+	assert.False(t, ok, "最早的行被淘汰")
+}
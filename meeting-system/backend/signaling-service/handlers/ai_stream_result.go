@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+// aiResultDedupCapacity 内存中记住的最近结果 ID 数量（持久化层另有唯一索引兜底）
+const aiResultDedupCapacity = 4096
+
+// resultDeduper 有界的结果 ID 集合，超出容量时淘汰最早的 ID
+type resultDeduper struct {
+	mu       sync.Mutex
+	capacity int
+	seen     map[string]struct{}
+	order    []string
+}
+
+func newResultDeduper(capacity int) *resultDeduper {
+	return &resultDeduper{
+		capacity: capacity,
+		seen:     make(map[string]struct{}, capacity),
+		order:    make([]string, 0, capacity),
+	}
+}
+
+// firstSeen 记录 ID，首次出现返回 true
+func (d *resultDeduper) firstSeen(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+	if len(d.order) >= d.capacity {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.seen[id] = struct{}{}
+	d.order = append(d.order, id)
+	return true
+}
+
+// DeliverAIStreamResult 将媒体服务产生的 AI 流式结果持久化并推送给会议全员。
+// 同一 ResultID 只推送一次（事件总线可能重复投递）；返回是否实际推送。
+func (h *WebSocketHandler) DeliverAIStreamResult(meetingID uint, result *models.AIStreamResultMessage) bool {
+	if meetingID == 0 || result == nil || result.ResultID == "" {
+		return false
+	}
+	if !h.aiResults.firstSeen(result.ResultID) {
+		return false
+	}
+
+	// 持久化由唯一索引保证同一结果只写一次；失败不影响实时推送
+	if h.signalingService != nil {
+		if _, err := h.signalingService.SaveAIStreamResult(meetingID, result); err != nil {
+			logger.Error("Failed to save AI stream result",
+				logger.Uint("meeting_id", meetingID),
+				logger.String("result_id", result.ResultID),
+				logger.Err(err))
+		}
+	}
+
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("ai_stream_result_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeAIStreamResult,
+		FromUserID: result.SpeakerUserID,
+		MeetingID:  meetingID,
+		PeerID:     result.SpeakerPeerID,
+		Payload:    result,
+		Timestamp:  time.Now(),
+	}, "")
+	if h.serverAILive {
+		h.broadcastServerAILiveResult(meetingID, result)
+	}
+	return true
+}
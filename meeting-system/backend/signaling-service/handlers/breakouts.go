@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// ApplyBreakoutOpened 会议服务开启分组（或开启后重新分配）：广播到主会议房间，并把被分配的连接迁移到分组房间
+func (h *WebSocketHandler) ApplyBreakoutOpened(meetingID uint, breakoutIDs []uint, assignments []models.BreakoutAssignment, closesAt *time.Time, byUserID uint) {
+	event := models.BreakoutEvent{
+		Event:       models.BreakoutEventOpened,
+		MeetingID:   meetingID,
+		BreakoutIDs: breakoutIDs,
+		Assignments: assignments,
+		ClosesAt:    closesAt,
+		ByUserID:    byUserID,
+		Timestamp:   time.Now(),
+	}
+
+	logger.Info("Breakouts opened",
+		logger.Uint("meeting_id", meetingID),
+		logger.Int("breakouts", len(breakoutIDs)),
+		logger.Int("assignments", len(assignments)))
+
+	h.broadcastToRoom(meetingID, breakoutMessage(meetingID, event), "")
+	h.enforceBreakout(event)
+}
+
+// ApplyBreakoutBroadcast 主办人向所有分组广播消息（主会议房间同样收到）
+func (h *WebSocketHandler) ApplyBreakoutBroadcast(meetingID uint, breakoutIDs []uint, message string, byUserID uint) {
+	event := models.BreakoutEvent{
+		Event:       models.BreakoutEventBroadcast,
+		MeetingID:   meetingID,
+		BreakoutIDs: breakoutIDs,
+		Message:     message,
+		ByUserID:    byUserID,
+		Timestamp:   time.Now(),
+	}
+
+	for _, roomID := range append([]uint{meetingID}, breakoutIDs...) {
+		h.broadcastToRoom(roomID, breakoutMessage(roomID, event), "")
+	}
+}
+
+// ApplyBreakoutClosed 分组已关闭：通知各分组房间，并把其中的连接迁回主会议
+func (h *WebSocketHandler) ApplyBreakoutClosed(meetingID uint, breakoutIDs []uint, byUserID uint) {
+	event := models.BreakoutEvent{
+		Event:       models.BreakoutEventClosed,
+		MeetingID:   meetingID,
+		BreakoutIDs: breakoutIDs,
+		ByUserID:    byUserID,
+		Timestamp:   time.Now(),
+	}
+
+	logger.Info("Breakouts closed",
+		logger.Uint("meeting_id", meetingID),
+		logger.Int("breakouts", len(breakoutIDs)))
+
+	for _, roomID := range append([]uint{meetingID}, breakoutIDs...) {
+		h.broadcastToRoom(roomID, breakoutMessage(roomID, event), "")
+	}
+	h.enforceBreakout(event)
+}
+
+func breakoutMessage(roomID uint, event models.BreakoutEvent) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		ID:         fmt.Sprintf("breakout_%s_%d", event.Event, time.Now().UnixNano()),
+		Type:       models.MessageTypeBreakout,
+		FromUserID: event.ByUserID,
+		MeetingID:  roomID,
+		Payload:    event,
+		Timestamp:  time.Now(),
+	}
+}
+
+// enforceBreakout 迁移本节点上的连接（集群中其他节点收到广播后同样执行，已迁移的连接会被跳过）
+func (h *WebSocketHandler) enforceBreakout(event models.BreakoutEvent) {
+	switch event.Event {
+	case models.BreakoutEventOpened:
+		// 连接可能在主会议，也可能在另一个分组（重新分配）
+		rooms := append([]uint{event.MeetingID}, event.BreakoutIDs...)
+		for _, assignment := range event.Assignments {
+			for _, client := range h.userClientsIn(rooms, assignment.UserID) {
+				h.moveClient(client, assignment.BreakoutID, event.MeetingID)
+			}
+		}
+	case models.BreakoutEventClosed:
+		for _, breakoutID := range event.BreakoutIDs {
+			for _, client := range h.roomClients(breakoutID) {
+				h.moveClient(client, event.MeetingID, event.MeetingID)
+			}
+		}
+	}
+}
+
+// enforceClusterBreakout 其他节点扇出的分组广播：迁移本节点上的连接
+func (h *WebSocketHandler) enforceClusterBreakout(data []byte) {
+	var message models.WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return
+	}
+	var event models.BreakoutEvent
+	if err := decodePayload(message.Payload, &event); err != nil {
+		return
+	}
+	h.enforceBreakout(event)
+}
+
+// moveClient 不断开连接，把连接从当前房间迁移到 toMeetingID 房间：
+// 原房间收到离开通知并释放其屏幕共享/举手/反馈，新房间收到加入通知与 RoomInfo，媒体服务随后迁移其 Peer
+func (h *WebSocketHandler) moveClient(client *Client, toMeetingID, parentMeetingID uint) bool {
+	h.mutex.Lock()
+	if current, exists := h.clients[client.ID]; !exists || current != client || client.waiting || client.MeetingID() == toMeetingID {
+		h.mutex.Unlock()
+		return false
+	}
+	departure := h.leaveRoomLocked(client)
+	client.setMeetingID(toMeetingID)
+	if toMeetingID == parentMeetingID {
+		client.breakoutOf.Store(0)
+	} else {
+		client.breakoutOf.Store(uint64(parentMeetingID))
+	}
+	h.addToRoomLocked(client)
+	h.mutex.Unlock()
+
+	fromMeetingID := departure.meetingID
+	h.leaveClusterRoom(client.ID, departure)
+	if h.cluster != nil {
+		if err := h.cluster.join(client); err != nil {
+			logger.Error("Failed to join cluster room", logger.Uint("meeting_id", toMeetingID), logger.Err(err))
+		}
+	}
+	if h.signalingService != nil {
+		if err := h.signalingService.MoveSession(client.ID, fromMeetingID, toMeetingID); err != nil {
+			logger.Error("Failed to move signaling session", logger.Err(err))
+		}
+	}
+
+	h.announceDeparture(client, departure)
+	h.sendToClient(client.ID, breakoutMessage(toMeetingID, models.BreakoutEvent{
+		Event:      models.BreakoutEventMoved,
+		MeetingID:  parentMeetingID,
+		BreakoutID: toMeetingID,
+		Timestamp:  time.Now(),
+	}))
+	client.sendChatHistory()
+	h.broadcastUserJoined(client)
+	h.publishBreakoutMoved(client, fromMeetingID, toMeetingID)
+
+	logger.Info("Client moved between rooms",
+		logger.String("session", client.ID),
+		logger.Uint("user_id", client.UserID),
+		logger.Uint("from_meeting_id", fromMeetingID),
+		logger.Uint("to_meeting_id", toMeetingID))
+	return true
+}
+
+// publishBreakoutMoved 通知媒体服务把该用户的 Peer 迁移到新房间
+func (h *WebSocketHandler) publishBreakoutMoved(client *Client, fromMeetingID, toMeetingID uint) {
+	if h.events == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiLiveEventTimeout)
+	defer cancel()
+	if err := h.events.Publish(ctx, queue.ChannelSignalingEvents, &queue.PubSubMessage{
+		Type: queue.EventBreakoutMoved,
+		Payload: map[string]interface{}{
+			"meeting_id":      toMeetingID,
+			"from_meeting_id": fromMeetingID,
+			"user_id":         client.UserID,
+		},
+		Source: "signaling-service",
+	}); err != nil {
+		logger.Warn("Failed to publish breakout move event",
+			logger.Uint("meeting_id", toMeetingID),
+			logger.Uint("user_id", client.UserID),
+			logger.Err(err))
+	}
+}
+
+// userClientsIn 本节点上该用户在 meetingIDs 任一房间中的连接（不含等候室）
+func (h *WebSocketHandler) userClientsIn(meetingIDs []uint, userID uint) []*Client {
+	rooms := make(map[uint]bool, len(meetingIDs))
+	for _, id := range meetingIDs {
+		rooms[id] = true
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	var clients []*Client
+	for _, client := range h.clients {
+		if client.UserID == userID && rooms[client.MeetingID()] && !client.waiting {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// roomClients 本节点上该房间的所有连接
+func (h *WebSocketHandler) roomClients(meetingID uint) []*Client {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return nil
+	}
+
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+	clients := make([]*Client, 0, len(room.Clients))
+	for _, client := range room.Clients {
+		clients = append(clients, client)
+	}
+	return clients
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+func roomMembers(h *WebSocketHandler, meetingID uint) []uint {
+	var users []uint
+	for _, participant := range h.collectRoomParticipants(meetingID) {
+		users = append(users, participant.UserID)
+	}
+	return users
+}
+
+func TestBreakout_OpenMovesAssignedClientsWithoutReconnect(t *testing.T) {
+	h, _, _, events, clients := newHostControlHandler(t)
+	clients[9].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+	drainAll(t, clients[7])
+
+	closesAt := time.Now().Add(10 * time.Minute)
+	h.ApplyBreakoutOpened(1, []uint{101, 102}, []models.BreakoutAssignment{
+		{UserID: 9, BreakoutID: 101},
+		{UserID: 10, BreakoutID: 102},
+	}, &closesAt, 7)
+
+	assert.ElementsMatch(t, []uint{7, 8}, roomMembers(h, 1))
+	assert.Equal(t, []uint{9}, roomMembers(h, 101))
+	assert.Equal(t, []uint{10}, roomMembers(h, 102))
+	assert.Equal(t, 4, h.GetClientCount(), "迁移不断开连接")
+	assert.Empty(t, h.getRaisedHands(1), "离开主会议时放下手")
+
+	messages, closed := drainAll(t, clients[9])
+	assert.False(t, closed)
+	moved := decodeEvents[models.BreakoutEvent](t, messages, models.MessageTypeBreakout)
+	require.Len(t, moved, 2)
+	assert.Equal(t, models.BreakoutEventOpened, moved[0].Event)
+	assert.Equal(t, models.BreakoutEventMoved, moved[1].Event)
+	assert.Equal(t, uint(1), moved[1].MeetingID)
+	assert.Equal(t, uint(101), moved[1].BreakoutID)
+	var info *models.WebSocketMessage
+	for i := range messages {
+		if messages[i].Type == models.MessageTypeRoomInfo {
+			info = &messages[i]
+		}
+	}
+	require.NotNil(t, info, "迁移后收到新房间的 RoomInfo")
+	assert.Equal(t, uint(101), info.MeetingID)
+
+	messages, _ = drainAll(t, clients[7])
+	left := 0
+	for _, msg := range messages {
+		if msg.Type == models.MessageTypeUserLeft {
+			left++
+		}
+	}
+	assert.Equal(t, 2, left)
+
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	var movedEvents []*queue.PubSubMessage
+	for _, msg := range events.messages {
+		if msg.Type == queue.EventBreakoutMoved {
+			movedEvents = append(movedEvents, msg)
+		}
+	}
+	require.Len(t, movedEvents, 2)
+	assert.Equal(t, uint(1), movedEvents[0].Payload["from_meeting_id"])
+}
+
+func TestBreakout_ReassignWhileOpenMovesBetweenBreakouts(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.ApplyBreakoutOpened(1, []uint{101, 102}, []models.BreakoutAssignment{{UserID: 9, BreakoutID: 101}}, nil, 7)
+	drainAll(t, clients[9])
+
+	h.ApplyBreakoutOpened(1, []uint{101, 102}, []models.BreakoutAssignment{{UserID: 9, BreakoutID: 102}}, nil, 7)
+
+	assert.Empty(t, roomMembers(h, 101))
+	assert.Equal(t, []uint{9}, roomMembers(h, 102))
+	messages, _ := drainAll(t, clients[9])
+	moved := decodeEvents[models.BreakoutEvent](t, messages, models.MessageTypeBreakout)
+	require.Len(t, moved, 1, "不在主会议，只收到迁移通知")
+	assert.Equal(t, uint(102), moved[0].BreakoutID)
+}
+
+func TestBreakout_BroadcastAndCloseReturnEveryone(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.ApplyBreakoutOpened(1, []uint{101, 102}, []models.BreakoutAssignment{
+		{UserID: 8, BreakoutID: 101},
+		{UserID: 9, BreakoutID: 101},
+		{UserID: 10, BreakoutID: 102},
+	}, nil, 7)
+	for _, client := range clients {
+		drainAll(t, client)
+	}
+
+	h.ApplyBreakoutBroadcast(1, []uint{101, 102}, "还剩 2 分钟", 7)
+	for userID, client := range clients {
+		messages, _ := drainAll(t, client)
+		received := decodeEvents[models.BreakoutEvent](t, messages, models.MessageTypeBreakout)
+		require.Len(t, received, 1, "user %d", userID)
+		assert.Equal(t, models.BreakoutEventBroadcast, received[0].Event)
+		assert.Equal(t, "还剩 2 分钟", received[0].Message)
+	}
+
+	h.ApplyBreakoutClosed(1, []uint{101, 102}, 7)
+
+	assert.ElementsMatch(t, []uint{7, 8, 9, 10}, roomMembers(h, 1))
+	assert.Empty(t, roomMembers(h, 101))
+	assert.Empty(t, roomMembers(h, 102))
+	messages, closed := drainAll(t, clients[10])
+	assert.False(t, closed)
+	received := decodeEvents[models.BreakoutEvent](t, messages, models.MessageTypeBreakout)
+	require.Len(t, received, 2)
+	assert.Equal(t, models.BreakoutEventClosed, received[0].Event)
+	assert.Equal(t, models.BreakoutEventMoved, received[1].Event)
+	assert.Equal(t, uint(1), received[1].BreakoutID)
+	assert.Equal(t, uint(1), clients[10].MeetingID())
+}
+
+// TestBreakout_MoveConcurrentWithClientMessages 事件协程迁移连接的同时，连接自己的读协程仍在处理消息
+func TestBreakout_MoveConcurrentWithClientMessages(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			clients[9].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+		}
+	}()
+	h.ApplyBreakoutOpened(1, []uint{101}, []models.BreakoutAssignment{{UserID: 9, BreakoutID: 101}}, nil, 7)
+	h.ApplyBreakoutClosed(1, []uint{101}, 7)
+	<-done
+
+	assert.Equal(t, uint(1), clients[9].MeetingID())
+	assert.Contains(t, roomMembers(h, 1), uint(9))
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+// ChatStore 聊天消息的持久化（生产环境为 SignalingService，底层按 chat.store 使用 PostgreSQL 或 MongoDB）
+type ChatStore interface {
+	SaveChatMessage(message *models.MeetingChatMessage) error
+	GetChatMessage(id string) (*models.MeetingChatMessage, error)
+	UpdateChatMessage(message *models.MeetingChatMessage) error
+	ListChatMessages(query models.ChatHistoryQuery) ([]models.MeetingChatMessage, error)
+}
+
+// handleChat 聊天：发送（公开、私聊、回复话题）、作者编辑、作者或主办人/主持人删除。
+// 消息先持久化再下发，公开消息广播到房间，私聊只发给收发双方的所有会话
+func (c *Client) handleChat(message *models.WebSocketMessage) {
+	var req models.ChatMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid chat message", err.Error())
+		return
+	}
+	if c.Handler.chat == nil {
+		c.sendErrorCode(503, "Chat unavailable", "chat storage unavailable")
+		return
+	}
+
+	switch req.Action {
+	case "", models.ChatActionSend:
+		c.sendChat(req)
+	case models.ChatActionEdit:
+		c.editChat(req)
+	case models.ChatActionDelete:
+		c.deleteChat(req)
+	default:
+		c.sendError("Invalid chat message", "action must be send, edit or delete")
+	}
+}
+
+func (c *Client) sendChat(req models.ChatMessage) {
+	h := c.Handler
+	if req.ToUserID != 0 && req.ToUserID != c.UserID && h.roles != nil {
+		if _, err := h.roles.GetParticipantRole(req.ToUserID, c.MeetingID()); err != nil {
+			c.sendErrorCode(404, "Chat failed", "recipient is not in the meeting")
+			return
+		}
+	}
+
+	var parent *models.MeetingChatMessage
+	if req.ReplyToID != "" {
+		var err error
+		if parent, err = h.chat.GetChatMessage(req.ReplyToID); err != nil {
+			c.sendChatError("Chat failed", err)
+			return
+		}
+	}
+	record, err := models.NewMeetingChatMessage(c.MeetingID(), c.UserID, c.Username, req, parent)
+	if err != nil {
+		c.sendChatError("Chat failed", err)
+		return
+	}
+	if err := h.chat.SaveChatMessage(record); err != nil {
+		c.sendChatError("Chat failed", err)
+		return
+	}
+	h.deliverChat(record.Payload(), models.ChatActionSend, c.UserID)
+}
+
+func (c *Client) editChat(req models.ChatMessage) {
+	record, ok := c.meetingChatMessage(req.ID)
+	if !ok {
+		return
+	}
+	if err := record.Edit(c.UserID, req.Content, time.Now()); err != nil {
+		c.sendChatError("Edit failed", err)
+		return
+	}
+	if err := c.Handler.chat.UpdateChatMessage(record); err != nil {
+		c.sendChatError("Edit failed", err)
+		return
+	}
+	c.Handler.deliverChat(record.Payload(), models.ChatActionEdit, c.UserID)
+}
+
+func (c *Client) deleteChat(req models.ChatMessage) {
+	record, ok := c.meetingChatMessage(req.ID)
+	if !ok {
+		return
+	}
+	if err := record.Delete(c.UserID, c.canModerate(), time.Now()); err != nil {
+		c.sendChatError("Delete failed", err)
+		return
+	}
+	if err := c.Handler.chat.UpdateChatMessage(record); err != nil {
+		c.sendChatError("Delete failed", err)
+		return
+	}
+	if record.UserID != c.UserID {
+		logger.Info("Chat message deleted by moderator",
+			logger.Uint("meeting_id", c.MeetingID()),
+			logger.String("message_id", record.ID),
+			logger.Uint("user_id", c.UserID))
+	}
+	c.Handler.deliverChat(record.Payload(), models.ChatActionDelete, c.UserID)
+}
+
+// meetingChatMessage 查询本会议内对当前用户可见的消息，否则回复 404
+func (c *Client) meetingChatMessage(id string) (*models.MeetingChatMessage, bool) {
+	if id == "" {
+		c.sendError("Invalid chat message", "id is required")
+		return nil, false
+	}
+	record, err := c.Handler.chat.GetChatMessage(id)
+	if err == nil && (record.MeetingID != c.MeetingID() || !record.VisibleTo(c.UserID)) {
+		err = models.ErrChatMessageNotFound
+	}
+	if err != nil {
+		c.sendChatError("Chat failed", err)
+		return nil, false
+	}
+	return record, true
+}
+
+// sendChatError 按聊天业务错误回复对应的错误码
+func (c *Client) sendChatError(message string, err error) {
+	switch {
+	case errors.Is(err, models.ErrChatMessageNotFound):
+		c.sendErrorCode(404, message, err.Error())
+	case errors.Is(err, models.ErrChatNotAuthor), errors.Is(err, models.ErrChatDeleteDenied):
+		c.sendErrorCode(403, message, err.Error())
+	case errors.Is(err, models.ErrChatDeleted):
+		c.sendErrorCode(409, message, err.Error())
+	case errors.Is(err, models.ErrChatContentRequired), errors.Is(err, models.ErrChatContentTooLong),
+		errors.Is(err, models.ErrChatSelfMessage), errors.Is(err, models.ErrChatPublicReply),
+		errors.Is(err, models.ErrChatReplyRecipient):
+		c.sendError(message, err.Error())
+	default:
+		logger.Error("Chat storage failed", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+		c.sendErrorCode(500, message, "chat storage failed")
+	}
+}
+
+// ApplyChatMessage 下发会议服务（REST 接口）发送、编辑或删除的聊天消息
+func (h *WebSocketHandler) ApplyChatMessage(payload models.ChatMessage, action string, byUserID uint) {
+	h.deliverChat(payload, action, byUserID)
+}
+
+// deliverChat 公开消息广播到房间；私聊只发给收发双方（含各自的其他会话）
+func (h *WebSocketHandler) deliverChat(payload models.ChatMessage, action string, byUserID uint) {
+	payload.Action = action
+	message := &models.WebSocketMessage{
+		ID:         fmt.Sprintf("chat_%s_%s_%d", action, payload.ID, time.Now().UnixNano()),
+		Type:       models.MessageTypeChat,
+		FromUserID: byUserID,
+		MeetingID:  payload.MeetingID,
+		Payload:    payload,
+		Timestamp:  time.Now(),
+	}
+
+	if payload.ToUserID == 0 {
+		h.broadcastToRoom(payload.MeetingID, message, "")
+		return
+	}
+	to := payload.ToUserID
+	message.ToUserID = &to
+	h.forwardToUser(payload.ToUserID, message)
+	h.forwardToUser(payload.UserID, message)
+}
+
+// sendChatHistory 入会时推送最近的聊天（含发给自己与自己发出的私聊），晚到的参与者也能看到之前的讨论
+func (c *Client) sendChatHistory() {
+	h := c.Handler
+	if h.chat == nil || h.chatBacklog <= 0 {
+		return
+	}
+	records, err := h.chat.ListChatMessages(models.ChatHistoryQuery{
+		MeetingID: c.MeetingID(),
+		ViewerID:  c.UserID,
+		Limit:     h.chatBacklog + 1,
+	})
+	if err != nil {
+		logger.Warn("Failed to load chat history", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(&models.WebSocketMessage{
+		ID:         fmt.Sprintf("chat_history_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeChatHistory,
+		FromUserID: 0, // 系统消息
+		MeetingID:  c.MeetingID(),
+		SessionID:  c.ID,
+		Payload:    models.NewChatHistory(records, h.chatBacklog),
+		Timestamp:  time.Now(),
+	})
+	if err != nil {
+		logger.Error("Failed to marshal chat history", logger.Err(err))
+		return
+	}
+	c.enqueue(data, "chat_history")
+}
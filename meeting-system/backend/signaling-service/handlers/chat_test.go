@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+// memoryChats 内存中的聊天消息，按保存顺序递增创建时间
+type memoryChats struct {
+	nextID   int
+	messages map[string]*models.MeetingChatMessage
+}
+
+func newMemoryChats() *memoryChats {
+	return &memoryChats{messages: make(map[string]*models.MeetingChatMessage)}
+}
+
+func (m *memoryChats) SaveChatMessage(message *models.MeetingChatMessage) error {
+	m.nextID++
+	message.ID = fmt.Sprintf("msg-%03d", m.nextID)
+	message.CreatedAt = time.Date(2026, 10, 18, 9, 0, m.nextID, 0, time.UTC)
+	stored := *message
+	m.messages[message.ID] = &stored
+	return nil
+}
+
+func (m *memoryChats) GetChatMessage(id string) (*models.MeetingChatMessage, error) {
+	message, ok := m.messages[id]
+	if !ok {
+		return nil, models.ErrChatMessageNotFound
+	}
+	copied := *message
+	return &copied, nil
+}
+
+func (m *memoryChats) UpdateChatMessage(message *models.MeetingChatMessage) error {
+	stored := *message
+	m.messages[message.ID] = &stored
+	return nil
+}
+
+func (m *memoryChats) ListChatMessages(query models.ChatHistoryQuery) ([]models.MeetingChatMessage, error) {
+	var records []models.MeetingChatMessage
+	for _, message := range m.messages {
+		if message.MeetingID != query.MeetingID || !message.VisibleTo(query.ViewerID) {
+			continue
+		}
+		if query.ThreadID != "" && message.ReplyToID != query.ThreadID {
+			continue
+		}
+		records = append(records, *message)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID > records[j].ID })
+	if len(records) > query.Limit {
+		records = records[:query.Limit]
+	}
+	return records, nil
+}
+
+func chatRequest(req models.ChatMessage) *models.WebSocketMessage {
+	return &models.WebSocketMessage{Type: models.MessageTypeChat, MeetingID: 1, Payload: req}
+}
+
+func TestChat_PublicMessagesPersistedAndBroadcast(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	chats := newMemoryChats()
+	h.chat = chats
+
+	clients[9].handleMessage(chatRequest(models.ChatMessage{Content: " 大家好 "}))
+	require.Len(t, chats.messages, 1)
+	for _, userID := range []uint{7, 8, 9, 10} {
+		received := decodeEvents[models.ChatMessage](t, mustDrain(t, clients[userID]), models.MessageTypeChat)
+		require.Len(t, received, 1, "user %d", userID)
+		assert.Equal(t, "大家好", received[0].Content)
+		assert.Equal(t, "msg-001", received[0].ID)
+		assert.Equal(t, models.ChatActionSend, received[0].Action)
+		assert.NotNil(t, received[0].CreatedAt)
+	}
+
+	clients[10].handleMessage(chatRequest(models.ChatMessage{Content: "回复", ReplyToID: "msg-001", ToUserID: 9}))
+	errs := drainErrors(t, clients[10])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 400, errs[0].Code, "公开消息不能私聊回复")
+
+	clients[10].handleMessage(chatRequest(models.ChatMessage{Content: "回复", ReplyToID: "msg-001"}))
+	received := decodeEvents[models.ChatMessage](t, mustDrain(t, clients[7]), models.MessageTypeChat)
+	require.Len(t, received, 1)
+	assert.Equal(t, "msg-001", received[0].ReplyToID)
+}
+
+func TestChat_PrivateMessagesOnlyReachBothParties(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.chat = newMemoryChats()
+
+	clients[9].handleMessage(chatRequest(models.ChatMessage{Content: "悄悄话", ToUserID: 10}))
+	for _, userID := range []uint{9, 10} {
+		received := decodeEvents[models.ChatMessage](t, mustDrain(t, clients[userID]), models.MessageTypeChat)
+		require.Len(t, received, 1, "user %d", userID)
+		assert.Equal(t, uint(10), received[0].ToUserID)
+	}
+	for _, userID := range []uint{7, 8} {
+		assert.Empty(t, decodeEvents[models.ChatMessage](t, mustDrain(t, clients[userID]), models.MessageTypeChat), "user %d", userID)
+	}
+
+	// 回复私聊自动发给对方
+	clients[10].handleMessage(chatRequest(models.ChatMessage{Content: "收到", ReplyToID: "msg-001"}))
+	received := decodeEvents[models.ChatMessage](t, mustDrain(t, clients[9]), models.MessageTypeChat)
+	require.Len(t, received, 1)
+	assert.Equal(t, uint(9), received[0].ToUserID)
+	assert.Empty(t, decodeEvents[models.ChatMessage](t, mustDrain(t, clients[7]), models.MessageTypeChat))
+
+	// 私聊对第三方不可见，也不能被主持人删除
+	clients[7].handleMessage(chatRequest(models.ChatMessage{Action: models.ChatActionDelete, ID: "msg-001"}))
+	errs := drainErrors(t, clients[7])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 404, errs[0].Code)
+
+	clients[9].handleMessage(chatRequest(models.ChatMessage{Content: "hi", ToUserID: 42}))
+	errs = drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 404, errs[0].Code, "收件人不在会议中")
+}
+
+func TestChat_EditByAuthorAndModeratorDeleteLeavesTombstone(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	chats := newMemoryChats()
+	h.chat = chats
+
+	clients[9].handleMessage(chatRequest(models.ChatMessage{Content: "初稿"}))
+	for _, client := range clients {
+		drainAll(t, client)
+	}
+
+	clients[10].handleMessage(chatRequest(models.ChatMessage{Action: models.ChatActionEdit, ID: "msg-001", Content: "篡改"}))
+	errs := drainErrors(t, clients[10])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+
+	clients[9].handleMessage(chatRequest(models.ChatMessage{Action: models.ChatActionEdit, ID: "msg-001", Content: "定稿"}))
+	edited := decodeEvents[models.ChatMessage](t, mustDrain(t, clients[10]), models.MessageTypeChat)
+	require.Len(t, edited, 1)
+	assert.Equal(t, models.ChatActionEdit, edited[0].Action)
+	assert.Equal(t, "定稿", edited[0].Content)
+	assert.NotNil(t, edited[0].EditedAt)
+
+	clients[8].handleMessage(chatRequest(models.ChatMessage{Action: models.ChatActionDelete, ID: "msg-001"}))
+	deleted := decodeEvents[models.ChatMessage](t, mustDrain(t, clients[10]), models.MessageTypeChat)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, models.ChatActionDelete, deleted[0].Action)
+	assert.True(t, deleted[0].Deleted)
+	assert.Empty(t, deleted[0].Content)
+	drainAll(t, clients[9])
+	assert.True(t, chats.messages["msg-001"].IsDeleted(), "删除保留墓碑")
+
+	clients[9].handleMessage(chatRequest(models.ChatMessage{Action: models.ChatActionEdit, ID: "msg-001", Content: "复活"}))
+	errs = drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 409, errs[0].Code)
+}
+
+func TestChat_AdmittedLateJoinerReceivesVisibleHistory(t *testing.T) {
+	h, _, clients := newLobbyHandler()
+	h.chat = newMemoryChats()
+	h.chatBacklog = 2
+	host, guest := clients[7], clients[9]
+
+	host.handleMessage(chatRequest(models.ChatMessage{Content: "第一条"}))
+	host.handleMessage(chatRequest(models.ChatMessage{Content: "只给 8", ToUserID: 8}))
+	host.handleMessage(chatRequest(models.ChatMessage{Content: "第二条"}))
+	host.handleMessage(chatRequest(models.ChatMessage{Content: "第三条"}))
+	drainAll(t, guest)
+
+	host.handleMessage(lobbyAction(models.LobbyActionAdmit, 9))
+	messages, _ := drainAll(t, guest)
+
+	var history *models.ChatHistoryMessage
+	for _, msg := range messages {
+		if msg.Type == models.MessageTypeChatHistory {
+			history = &models.ChatHistoryMessage{}
+			require.NoError(t, decodePayload(msg.Payload, history))
+		}
+	}
+	require.NotNil(t, history)
+	require.Len(t, history.Messages, 2)
+	assert.Equal(t, "第二条", history.Messages[0].Content, "按时间正序，不含别人的私聊")
+	assert.Equal(t, "第三条", history.Messages[1].Content)
+	assert.NotEmpty(t, history.NextCursor, "更早的消息可通过会议服务翻页")
+}
@@ -0,0 +1,795 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+const (
+	clusterKeyPrefix          = "signaling:cluster:"
+	defaultClusterPresenceTTL = 30 * time.Second
+	clusterOpTimeout          = 3 * time.Second
+)
+
+// ClusterBus 节点间消息总线：每个节点都必须收到全部消息（Redis PUB/SUB，或每节点独立消费组的 Kafka）
+type ClusterBus interface {
+	Publish(ctx context.Context, channel string, msg *queue.PubSubMessage) error
+	Subscribe(channel string, handler queue.PubSubHandler) error
+}
+
+// Cluster 信令服务多实例协调：房间成员与节点存活状态存 Redis，房间广播/定向消息经 ClusterBus
+// 扇出到其他节点，由各节点投递给本地连接；浏览器领导者模式的 AI Live 状态、媒体管控、屏幕共享、举手与反馈以 Redis 为准
+type Cluster struct {
+	nodeID      string
+	redis       *redis.Client
+	bus         ClusterBus
+	presenceTTL time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// clusterMember Redis 中的房间成员（一个 WebSocket 会话）
+type clusterMember struct {
+	models.RoomParticipant
+	NodeID string `json:"node_id"`
+}
+
+// clusterScreenShare Redis 中的屏幕共享，记录发起共享的会话及其所在节点
+type clusterScreenShare struct {
+	models.ScreenShareState
+	SessionID string `json:"session_id"`
+	NodeID    string `json:"node_id"`
+}
+
+// clusterRaisedHand Redis 中的举手记录，记录举手的会话及其所在节点
+type clusterRaisedHand struct {
+	models.RaisedHand
+	SessionID string `json:"session_id"`
+	NodeID    string `json:"node_id"`
+}
+
+// clusterResumeOwner Redis 中挂起待续传的会话及其所在节点（续传缓冲只在原节点内存中）
+type clusterResumeOwner struct {
+	NodeID    string `json:"node_id"`
+	SessionID string `json:"session_id"`
+	UserID    uint   `json:"user_id"`
+	MeetingID uint   `json:"meeting_id"`
+}
+
+// clusterFeedback Redis 中的非语言反馈，记录设置反馈的会话及其所在节点
+type clusterFeedback struct {
+	models.FeedbackState
+	SessionID string `json:"session_id"`
+	NodeID    string `json:"node_id"`
+}
+
+// NewCluster 创建集群协调器；presenceTTL<=0 使用默认值
+func NewCluster(nodeID string, client *redis.Client, bus ClusterBus, presenceTTL time.Duration) *Cluster {
+	if presenceTTL <= 0 {
+		presenceTTL = defaultClusterPresenceTTL
+	}
+	return &Cluster{
+		nodeID:      nodeID,
+		redis:       client,
+		bus:         bus,
+		presenceTTL: presenceTTL,
+		stop:        make(chan struct{}),
+	}
+}
+
+// NodeID 当前节点标识
+func (c *Cluster) NodeID() string {
+	return c.nodeID
+}
+
+func clusterNodeKey(nodeID string) string {
+	return clusterKeyPrefix + "node:" + nodeID
+}
+
+func clusterMembersKey(meetingID uint) string {
+	return fmt.Sprintf("%sroom:%d:members", clusterKeyPrefix, meetingID)
+}
+
+func clusterAILiveKey(meetingID uint) string {
+	return fmt.Sprintf("%sroom:%d:ai_live", clusterKeyPrefix, meetingID)
+}
+
+func clusterModerationKey(meetingID uint) string {
+	return fmt.Sprintf("%sroom:%d:moderation", clusterKeyPrefix, meetingID)
+}
+
+func clusterScreenSharesKey(meetingID uint) string {
+	return fmt.Sprintf("%sroom:%d:screen_shares", clusterKeyPrefix, meetingID)
+}
+
+func clusterHandsKey(meetingID uint) string {
+	return fmt.Sprintf("%sroom:%d:hands", clusterKeyPrefix, meetingID)
+}
+
+func clusterFeedbackKey(meetingID uint) string {
+	return fmt.Sprintf("%sroom:%d:feedback", clusterKeyPrefix, meetingID)
+}
+
+func clusterResumeKey(token string) string {
+	return clusterKeyPrefix + "resume:" + token
+}
+
+// start 写入节点存活键并定期续期；节点宕机后其会话在存活键过期后不再计入房间成员
+func (c *Cluster) start() error {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	err := c.redis.Set(ctx, clusterNodeKey(c.nodeID), time.Now().Unix(), c.presenceTTL).Err()
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to register cluster node: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.presenceTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+				if err := c.redis.Set(ctx, clusterNodeKey(c.nodeID), time.Now().Unix(), c.presenceTTL).Err(); err != nil {
+					logger.Warn("Failed to refresh cluster node presence", logger.String("node_id", c.nodeID), logger.Err(err))
+				}
+				cancel()
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown 停止续期并删除存活键，其他节点立即不再把本节点的会话计入房间
+func (c *Cluster) shutdown() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+		defer cancel()
+		_ = c.redis.Del(ctx, clusterNodeKey(c.nodeID)).Err()
+	})
+}
+
+func (c *Cluster) join(client *Client) error {
+	data, err := json.Marshal(clusterMember{
+		RoomParticipant: models.RoomParticipant{
+			UserID:       client.UserID,
+			Username:     client.Username,
+			SessionID:    client.ID,
+			PeerID:       client.PeerID,
+			JoinedAt:     client.JoinedAt,
+			LastActiveAt: client.LastPing,
+		},
+		NodeID: c.nodeID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	return c.redis.HSet(ctx, clusterMembersKey(client.MeetingID()), client.ID, data).Err()
+}
+
+// leave 移除会话，返回房间在整个集群内剩余的会话数
+func (c *Cluster) leave(meetingID uint, sessionID string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	err := c.redis.HDel(ctx, clusterMembersKey(meetingID), sessionID).Err()
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+	members, err := c.members(meetingID)
+	return len(members), err
+}
+
+// members 房间在整个集群内的会话（剔除存活键已过期节点上的会话）
+func (c *Cluster) members(meetingID uint) ([]clusterMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+
+	raw, err := c.redis.HGetAll(ctx, clusterMembersKey(meetingID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	alive := map[string]bool{c.nodeID: true}
+	members := make([]clusterMember, 0, len(raw))
+	var stale []string
+	for sessionID, data := range raw {
+		var member clusterMember
+		if err := json.Unmarshal([]byte(data), &member); err != nil {
+			stale = append(stale, sessionID)
+			continue
+		}
+		nodeAlive, checked := alive[member.NodeID]
+		if !checked {
+			n, err := c.redis.Exists(ctx, clusterNodeKey(member.NodeID)).Result()
+			if err != nil {
+				return nil, err
+			}
+			nodeAlive = n > 0
+			alive[member.NodeID] = nodeAlive
+		}
+		if !nodeAlive {
+			stale = append(stale, sessionID)
+			continue
+		}
+		members = append(members, member)
+	}
+	if len(stale) > 0 {
+		_ = c.redis.HDel(ctx, clusterMembersKey(meetingID), stale...).Err()
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].JoinedAt.Before(members[j].JoinedAt) })
+	return members, nil
+}
+
+// claimAILiveScript 无人占用、自己已是领导者或原领导者所在节点已下线时成为领导者
+var claimAILiveScript = redis.NewScript(`
+local leader = redis.call('HGET', KEYS[1], 'session')
+if leader and leader ~= ARGV[1] then
+  local node = redis.call('HGET', KEYS[1], 'node')
+  if node and redis.call('EXISTS', ARGV[4] .. node) == 1 then
+    return 0
+  end
+end
+redis.call('HSET', KEYS[1], 'session', ARGV[1], 'node', ARGV[2], 'status', ARGV[3])
+return 1
+`)
+
+// releaseAILiveScript 仅领导者会话可以释放
+var releaseAILiveScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'session') == ARGV[1] then
+  redis.call('DEL', KEYS[1])
+  return 1
+end
+return 0
+`)
+
+func (c *Cluster) claimAILive(meetingID uint, status models.AILiveStatusMessage) (bool, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	n, err := claimAILiveScript.Run(ctx, c.redis, []string{clusterAILiveKey(meetingID)},
+		status.LeaderSessionID, c.nodeID, string(data), clusterKeyPrefix+"node:").Int()
+	return n == 1, err
+}
+
+func (c *Cluster) releaseAILive(meetingID uint, sessionID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	n, err := releaseAILiveScript.Run(ctx, c.redis, []string{clusterAILiveKey(meetingID)}, sessionID).Int()
+	return n == 1, err
+}
+
+// aiLiveStatus 集群内的 AI Live 状态；领导者所在节点下线视为已释放
+func (c *Cluster) aiLiveStatus(meetingID uint) (models.AILiveStatusMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+
+	values, err := c.redis.HMGet(ctx, clusterAILiveKey(meetingID), "status", "node").Result()
+	if err != nil {
+		return models.AILiveStatusMessage{}, err
+	}
+	data, _ := values[0].(string)
+	node, _ := values[1].(string)
+	if data == "" {
+		return models.AILiveStatusMessage{}, nil
+	}
+	if node != c.nodeID {
+		n, err := c.redis.Exists(ctx, clusterNodeKey(node)).Result()
+		if err != nil {
+			return models.AILiveStatusMessage{}, err
+		}
+		if n == 0 {
+			_ = c.redis.Del(ctx, clusterAILiveKey(meetingID)).Err()
+			return models.AILiveStatusMessage{UpdatedAt: time.Now()}, nil
+		}
+	}
+
+	var status models.AILiveStatusMessage
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return models.AILiveStatusMessage{}, err
+	}
+	return status, nil
+}
+
+// clearRoomState 房间在整个集群内已无会话时清除管控、屏幕共享、举手与反馈状态（与单节点房间销毁一致）
+func (c *Cluster) clearRoomState(meetingID uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	return c.redis.Del(ctx, clusterModerationKey(meetingID), clusterScreenSharesKey(meetingID),
+		clusterHandsKey(meetingID), clusterFeedbackKey(meetingID)).Err()
+}
+
+func (c *Cluster) setMediaModeration(meetingID uint, state models.MediaModerationState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	field := moderationKey(state.UserID, state.MediaType)
+	if !state.Muted {
+		return c.redis.HDel(ctx, clusterModerationKey(meetingID), field).Err()
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return c.redis.HSet(ctx, clusterModerationKey(meetingID), field, data).Err()
+}
+
+func (c *Cluster) mediaModerated(meetingID, userID uint, mediaType string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	return c.redis.HExists(ctx, clusterModerationKey(meetingID), moderationKey(userID, mediaType)).Result()
+}
+
+func (c *Cluster) moderationStates(meetingID uint) ([]models.MediaModerationState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	values, err := c.redis.HVals(ctx, clusterModerationKey(meetingID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	states := make([]models.MediaModerationState, 0, len(values))
+	for _, data := range values {
+		var state models.MediaModerationState
+		if err := json.Unmarshal([]byte(data), &state); err == nil {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// claimScreenShareScript 独占模式下存在其他存活节点上的共享且不能接管时拒绝，否则移除其他共享并登记；
+// 返回 {"1"|"0", 被接管/阻止本次共享的共享...}
+var claimScreenShareScript = redis.NewScript(`
+local previous = {}
+if ARGV[3] == '1' then
+  local shares = redis.call('HGETALL', KEYS[1])
+  for i = 1, #shares, 2 do
+    if shares[i] ~= ARGV[1] then
+      local node = cjson.decode(shares[i + 1])['node_id']
+      if node and redis.call('EXISTS', ARGV[5] .. node) == 1 then
+        table.insert(previous, shares[i + 1])
+      else
+        redis.call('HDEL', KEYS[1], shares[i])
+      end
+    end
+  end
+  if #previous > 0 and ARGV[4] ~= '1' then
+    return {'0', unpack(previous)}
+  end
+  for i = 1, #shares, 2 do
+    if shares[i] ~= ARGV[1] then
+      redis.call('HDEL', KEYS[1], shares[i])
+    end
+  end
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return {'1', unpack(previous)}
+`)
+
+// removeSessionEntryScript 移除用户的记录（屏幕共享、举手、反馈）并返回原记录；ARGV[2] 非空时仅移除该会话登记的记录
+var removeSessionEntryScript = redis.NewScript(`
+local data = redis.call('HGET', KEYS[1], ARGV[1])
+if not data then
+  return false
+end
+if ARGV[2] ~= '' and cjson.decode(data)['session_id'] ~= ARGV[2] then
+  return false
+end
+redis.call('HDEL', KEYS[1], ARGV[1])
+return data
+`)
+
+// claimScreenShare 在集群范围内登记屏幕共享；claimed 为 false 时 previous 为正在进行、不能接管的共享
+func (c *Cluster) claimScreenShare(meetingID uint, share models.ScreenShareState, sessionID string, exclusive, takeover bool) (claimed bool, previous []models.ScreenShareState, err error) {
+	data, err := json.Marshal(clusterScreenShare{ScreenShareState: share, SessionID: sessionID, NodeID: c.nodeID})
+	if err != nil {
+		return false, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	result, err := claimScreenShareScript.Run(ctx, c.redis, []string{clusterScreenSharesKey(meetingID)},
+		strconv.FormatUint(uint64(share.UserID), 10), string(data), exclusive, takeover, clusterKeyPrefix+"node:").StringSlice()
+	if err != nil || len(result) == 0 {
+		return false, nil, err
+	}
+	for _, raw := range result[1:] {
+		var stored clusterScreenShare
+		if err := json.Unmarshal([]byte(raw), &stored); err == nil {
+			previous = append(previous, stored.ScreenShareState)
+		}
+	}
+	return result[0] == "1", previous, nil
+}
+
+// removeScreenShare 移除用户的屏幕共享；sessionID 非空时仅当共享由该会话发起
+func (c *Cluster) removeScreenShare(meetingID, userID uint, sessionID string) (models.ScreenShareState, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	data, removed, err := c.removeSessionEntry(ctx, clusterScreenSharesKey(meetingID), userID, sessionID)
+	if err != nil || !removed {
+		return models.ScreenShareState{}, false, err
+	}
+	var stored clusterScreenShare
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return models.ScreenShareState{}, false, err
+	}
+	return stored.ScreenShareState, true, nil
+}
+
+// screenShares 集群内正在进行的屏幕共享（剔除存活键已过期节点上的共享）
+func (c *Cluster) screenShares(meetingID uint) ([]models.ScreenShareState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+
+	entries, err := c.liveSessionEntries(ctx, clusterScreenSharesKey(meetingID))
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]models.ScreenShareState, 0, len(entries))
+	for _, data := range entries {
+		var stored clusterScreenShare
+		if err := json.Unmarshal([]byte(data), &stored); err == nil {
+			shares = append(shares, stored.ScreenShareState)
+		}
+	}
+	return shares, nil
+}
+
+// removeSessionEntry 从 key 哈希中移除用户的记录；sessionID 非空时仅当记录由该会话登记
+func (c *Cluster) removeSessionEntry(ctx context.Context, key string, userID uint, sessionID string) (string, bool, error) {
+	data, err := removeSessionEntryScript.Run(ctx, c.redis, []string{key},
+		strconv.FormatUint(uint64(userID), 10), sessionID).Text()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return data, true, nil
+}
+
+// liveSessionEntries 读取 key 哈希中的记录，剔除存活键已过期节点上登记的记录
+func (c *Cluster) liveSessionEntries(ctx context.Context, key string) ([]string, error) {
+	raw, err := c.redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	alive := map[string]bool{c.nodeID: true}
+	entries := make([]string, 0, len(raw))
+	var stale []string
+	for userID, data := range raw {
+		var stored struct {
+			NodeID string `json:"node_id"`
+		}
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			stale = append(stale, userID)
+			continue
+		}
+		nodeAlive, checked := alive[stored.NodeID]
+		if !checked {
+			n, err := c.redis.Exists(ctx, clusterNodeKey(stored.NodeID)).Result()
+			if err != nil {
+				return nil, err
+			}
+			nodeAlive = n > 0
+			alive[stored.NodeID] = nodeAlive
+		}
+		if !nodeAlive {
+			stale = append(stale, userID)
+			continue
+		}
+		entries = append(entries, data)
+	}
+	if len(stale) > 0 {
+		_ = c.redis.HDel(ctx, key, stale...).Err()
+	}
+	return entries, nil
+}
+
+// raiseHand 在集群范围内举手；已举手时返回 false
+func (c *Cluster) raiseHand(meetingID uint, hand models.RaisedHand, sessionID string) (bool, error) {
+	data, err := json.Marshal(clusterRaisedHand{RaisedHand: hand, SessionID: sessionID, NodeID: c.nodeID})
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	return c.redis.HSetNX(ctx, clusterHandsKey(meetingID), strconv.FormatUint(uint64(hand.UserID), 10), data).Result()
+}
+
+// lowerHand 放下用户的手；sessionID 非空时仅当由该会话举手（连接断开）
+func (c *Cluster) lowerHand(meetingID, userID uint, sessionID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	_, removed, err := c.removeSessionEntry(ctx, clusterHandsKey(meetingID), userID, sessionID)
+	return removed, err
+}
+
+// lowerAllHands 放下全部举手；队列原本为空时返回 false
+func (c *Cluster) lowerAllHands(meetingID uint) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	n, err := c.redis.Del(ctx, clusterHandsKey(meetingID)).Result()
+	return n > 0, err
+}
+
+// handQueue 集群内的举手队列（按举手先后）
+func (c *Cluster) handQueue(meetingID uint) ([]models.RaisedHand, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	entries, err := c.liveSessionEntries(ctx, clusterHandsKey(meetingID))
+	if err != nil {
+		return nil, err
+	}
+	queue := make([]models.RaisedHand, 0, len(entries))
+	for _, data := range entries {
+		var stored clusterRaisedHand
+		if err := json.Unmarshal([]byte(data), &stored); err == nil {
+			queue = append(queue, stored.RaisedHand)
+		}
+	}
+	sortHandQueue(queue)
+	return queue, nil
+}
+
+// swapFeedbackScript 设置（ARGV[2] 为空时清除）用户的反馈并返回原记录
+var swapFeedbackScript = redis.NewScript(`
+local previous = redis.call('HGET', KEYS[1], ARGV[1])
+if ARGV[2] == '' then
+  redis.call('HDEL', KEYS[1], ARGV[1])
+else
+  redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+return previous
+`)
+
+// setFeedback 在集群范围内设置或清除用户的反馈，返回反馈是否变化
+func (c *Cluster) setFeedback(meetingID uint, state models.FeedbackState, sessionID string) (bool, error) {
+	var data []byte
+	if state.Feedback != "" {
+		var err error
+		if data, err = json.Marshal(clusterFeedback{FeedbackState: state, SessionID: sessionID, NodeID: c.nodeID}); err != nil {
+			return false, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	previous, err := swapFeedbackScript.Run(ctx, c.redis, []string{clusterFeedbackKey(meetingID)},
+		strconv.FormatUint(uint64(state.UserID), 10), string(data)).Text()
+	if errors.Is(err, redis.Nil) {
+		return state.Feedback != "", nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var stored clusterFeedback
+	if err := json.Unmarshal([]byte(previous), &stored); err != nil {
+		return true, nil
+	}
+	return stored.Feedback != state.Feedback, nil
+}
+
+// releaseFeedback 连接断开时清除该会话设置的反馈
+func (c *Cluster) releaseFeedback(meetingID, userID uint, sessionID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	_, removed, err := c.removeSessionEntry(ctx, clusterFeedbackKey(meetingID), userID, sessionID)
+	return removed, err
+}
+
+// feedbackStates 集群内参与者当前的反馈
+func (c *Cluster) feedbackStates(meetingID uint) ([]models.FeedbackState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	entries, err := c.liveSessionEntries(ctx, clusterFeedbackKey(meetingID))
+	if err != nil {
+		return nil, err
+	}
+	states := make([]models.FeedbackState, 0, len(entries))
+	for _, data := range entries {
+		var stored clusterFeedback
+		if err := json.Unmarshal([]byte(data), &stored); err == nil {
+			states = append(states, stored.FeedbackState)
+		}
+	}
+	return states, nil
+}
+
+// claimResume 登记本节点挂起的会话，续传窗口过后自动失效
+func (c *Cluster) claimResume(token string, owner clusterResumeOwner, ttl time.Duration) error {
+	owner.NodeID = c.nodeID
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	return c.redis.Set(ctx, clusterResumeKey(token), data, ttl).Err()
+}
+
+// releaseResume 会话已续传或注销，删除登记
+func (c *Cluster) releaseResume(token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	return c.redis.Del(ctx, clusterResumeKey(token)).Err()
+}
+
+// resumeOwner 查询令牌对应的挂起会话；原节点已下线时视为不存在
+func (c *Cluster) resumeOwner(token string) (clusterResumeOwner, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	var owner clusterResumeOwner
+	data, err := c.redis.Get(ctx, clusterResumeKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return owner, false, nil
+	}
+	if err != nil {
+		return owner, false, err
+	}
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return owner, false, err
+	}
+	alive, err := c.redis.Exists(ctx, clusterNodeKey(owner.NodeID)).Result()
+	if err != nil {
+		return owner, false, err
+	}
+	return owner, alive > 0, nil
+}
+
+func (c *Cluster) publish(eventType string, payload map[string]interface{}) {
+	payload["node_id"] = c.nodeID
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	if err := c.bus.Publish(ctx, queue.ChannelSignalingCluster, &queue.PubSubMessage{
+		Type:    eventType,
+		Payload: payload,
+		Source:  "signaling-service",
+	}); err != nil {
+		logger.Warn("Failed to publish cluster message",
+			logger.String("node_id", c.nodeID),
+			logger.String("event", eventType),
+			logger.Err(err))
+	}
+}
+
+func (c *Cluster) publishRoomBroadcast(meetingID uint, data []byte, messageType int, excludeSessionID string) {
+	c.publish(queue.EventClusterRoomBroadcast, map[string]interface{}{
+		"meeting_id":      meetingID,
+		"message":         string(data),
+		"message_type":    messageType,
+		"exclude_session": excludeSessionID,
+	})
+}
+
+func (c *Cluster) publishUserForward(meetingID, userID uint, data []byte, messageType int) {
+	c.publish(queue.EventClusterUserForward, map[string]interface{}{
+		"meeting_id":   meetingID,
+		"user_id":      userID,
+		"message":      string(data),
+		"message_type": messageType,
+	})
+}
+
+func (c *Cluster) publishModeratorBroadcast(meetingID uint, data []byte, messageType int) {
+	c.publish(queue.EventClusterModeratorBroadcast, map[string]interface{}{
+		"meeting_id":   meetingID,
+		"message":      string(data),
+		"message_type": messageType,
+	})
+}
+
+// publishSessionTakeover 通知原节点立即注销挂起的会话（续传请求落到了本节点）
+func (c *Cluster) publishSessionTakeover(owner clusterResumeOwner, token string) {
+	c.publish(queue.EventClusterSessionTakeover, map[string]interface{}{
+		"meeting_id":   owner.MeetingID,
+		"target_node":  owner.NodeID,
+		"session_id":   owner.SessionID,
+		"resume_token": token,
+	})
+}
+
+func (c *Cluster) publishRoomInfo(meetingID uint) {
+	c.publish(queue.EventClusterRoomInfo, map[string]interface{}{
+		"meeting_id": meetingID,
+	})
+}
+
+// EnableCluster 开启集群模式：订阅节点间消息并登记本节点存活
+func (h *WebSocketHandler) EnableCluster(cluster *Cluster) error {
+	// 先于订阅设置，订阅协程读取 h.cluster 时无需加锁
+	h.cluster = cluster
+	if err := cluster.bus.Subscribe(queue.ChannelSignalingCluster, h.handleClusterMessage); err != nil {
+		h.cluster = nil
+		return fmt.Errorf("failed to subscribe cluster channel: %w", err)
+	}
+	if err := cluster.start(); err != nil {
+		h.cluster = nil
+		return err
+	}
+	return nil
+}
+
+func clusterPayloadUint(payload map[string]interface{}, key string) (uint, bool) {
+	switch v := payload[key].(type) {
+	case float64:
+		return uint(v), v > 0
+	case string:
+		n, err := strconv.ParseUint(v, 10, 32)
+		return uint(n), err == nil && n > 0
+	}
+	return 0, false
+}
+
+// handleClusterMessage 投递其他节点扇出的消息给本地连接（不再转发，避免环路）
+func (h *WebSocketHandler) handleClusterMessage(_ context.Context, msg *queue.PubSubMessage) error {
+	cluster := h.cluster
+	if cluster == nil || msg == nil {
+		return nil
+	}
+	if nodeID, _ := msg.Payload["node_id"].(string); nodeID == cluster.nodeID {
+		return nil
+	}
+
+	meetingID, ok := clusterPayloadUint(msg.Payload, "meeting_id")
+	if !ok {
+		return errors.New("cluster message missing meeting_id")
+	}
+	data, _ := msg.Payload["message"].(string)
+	messageType, _ := msg.Payload["message_type"].(float64)
+
+	switch msg.Type {
+	case queue.EventClusterRoomBroadcast:
+		exclude, _ := msg.Payload["exclude_session"].(string)
+		h.deliverToRoom(meetingID, []byte(data), int(messageType), exclude)
+		switch models.MessageType(messageType) {
+		case models.MessageTypeHostControl:
+			h.enforceClusterHostControl(meetingID, []byte(data))
+		case models.MessageTypeBreakout:
+			h.enforceClusterBreakout([]byte(data))
+		case models.MessageTypeScreenShare:
+			h.applyClusterScreenShare(meetingID, []byte(data))
+		}
+	case queue.EventClusterUserForward:
+		userID, ok := clusterPayloadUint(msg.Payload, "user_id")
+		if !ok {
+			return errors.New("cluster forward missing user_id")
+		}
+		h.deliverToUser(meetingID, userID, []byte(data), int(messageType))
+	case queue.EventClusterModeratorBroadcast:
+		h.deliverToModerators(meetingID, []byte(data), int(messageType))
+		if models.MessageType(messageType) == models.MessageTypeLobbyUpdate {
+			h.enforceClusterLobby(meetingID, []byte(data))
+		}
+	case queue.EventClusterRoomInfo:
+		h.sendRoomInfoToLocalClients(meetingID)
+	case queue.EventClusterSessionTakeover:
+		if target, _ := msg.Payload["target_node"].(string); target == cluster.nodeID {
+			token, _ := msg.Payload["resume_token"].(string)
+			sessionID, _ := msg.Payload["session_id"].(string)
+			h.endSuspendedSession(token, sessionID)
+		}
+	}
+	return nil
+}
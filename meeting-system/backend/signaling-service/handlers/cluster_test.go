@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+const clusterTestMeeting uint = 100
+
+// newClusterNode 启动一个连接到共享 miniredis 的信令节点
+func newClusterNode(t *testing.T, mr *miniredis.Miniredis, nodeID string) *WebSocketHandler {
+	t.Helper()
+	if config.GlobalConfig == nil {
+		config.GlobalConfig = &config.Config{}
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	bus := queue.NewRedisPubSubQueue(client)
+	h := &WebSocketHandler{
+		clients: make(map[string]*Client),
+		rooms:   make(map[uint]*Room),
+		events:  &recordingEvents{},
+	}
+	cluster := NewCluster(nodeID, client, bus, 3*time.Second)
+	require.NoError(t, h.EnableCluster(cluster))
+	t.Cleanup(func() {
+		cluster.shutdown()
+		_ = bus.Stop()
+		_ = client.Close()
+	})
+	return h
+}
+
+func joinClusterNode(h *WebSocketHandler, sessionID string, userID uint) *Client {
+	client := &Client{
+		ID:           sessionID,
+		UserID:       userID,
+		Username:     fmt.Sprintf("user_%d", userID),
+		PeerID:       "peer-" + sessionID,
+		Handler:      h,
+		Send:         make(chan []byte, 64),
+		PrioritySend: make(chan []byte, 16),
+	}
+	client.setMeetingID(clusterTestMeeting)
+	h.registerClient(client)
+	return client
+}
+
+// waitForMessages 等待客户端收到全部指定类型的消息（跨节点投递是异步的），按类型返回最后一条
+func waitForMessages(t *testing.T, client *Client, messageTypes ...models.MessageType) map[models.MessageType]models.WebSocketMessage {
+	t.Helper()
+	found := make(map[models.MessageType]models.WebSocketMessage)
+	require.Eventually(t, func() bool {
+		for _, msg := range drainMessages(t, client) {
+			found[msg.Type] = msg
+		}
+		for drained := false; !drained; {
+			select {
+			case data := <-client.PrioritySend: // RoomInfo 走优先队列
+				var msg models.WebSocketMessage
+				require.NoError(t, json.Unmarshal(data, &msg))
+				found[msg.Type] = msg
+			default:
+				drained = true
+			}
+		}
+		for _, messageType := range messageTypes {
+			if _, ok := found[messageType]; !ok {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 10*time.Millisecond, "messages %v not delivered", messageTypes)
+	return found
+}
+
+func TestCluster_FanOutAcrossNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+
+	alice := joinClusterNode(nodeA, "session-alice", 1)
+	bob := joinClusterNode(nodeB, "session-bob", 2)
+	nodeB.broadcastUserJoined(bob)
+
+	// 其他节点上的参与者收到加入通知和包含双方的房间信息
+	received := waitForMessages(t, alice, models.MessageTypeUserJoined, models.MessageTypeRoomInfo)
+	assert.Equal(t, bob.ID, received[models.MessageTypeUserJoined].SessionID)
+
+	info := received[models.MessageTypeRoomInfo]
+	var roomInfo models.RoomInfoMessage
+	require.NoError(t, decodePayload(info.Payload, &roomInfo))
+	assert.Equal(t, 2, roomInfo.ParticipantCount)
+	sessions := make([]string, 0, len(roomInfo.Participants))
+	for _, p := range roomInfo.Participants {
+		sessions = append(sessions, p.SessionID)
+	}
+	assert.ElementsMatch(t, []string{alice.ID, bob.ID}, sessions)
+	for _, msg := range drainMessages(t, bob) {
+		assert.NotEqual(t, models.MessageTypeUserJoined, msg.Type, "不向广播发起者回送")
+	}
+
+	// 定向消息送达其他节点上的目标用户
+	nodeA.forwardToUser(bob.UserID, &models.WebSocketMessage{
+		Type:       models.MessageTypeChat,
+		FromUserID: alice.UserID,
+		MeetingID:  clusterTestMeeting,
+		Payload:    map[string]interface{}{"content": "hi"},
+	})
+	chat := waitForMessages(t, bob, models.MessageTypeChat)[models.MessageTypeChat]
+	assert.Equal(t, alice.UserID, chat.FromUserID)
+
+	// 离开的通知同样跨节点送达
+	nodeA.unregisterClient(alice)
+	left := waitForMessages(t, bob, models.MessageTypeUserLeft)[models.MessageTypeUserLeft]
+	assert.Equal(t, alice.ID, left.SessionID)
+	participants := nodeB.collectRoomParticipants(clusterTestMeeting)
+	require.Len(t, participants, 1)
+	assert.Equal(t, bob.ID, participants[0].SessionID)
+}
+
+func TestCluster_AILiveLeaderIsClusterWide(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+
+	alice := joinClusterNode(nodeA, "session-alice", 1)
+	bob := joinClusterNode(nodeB, "session-bob", 2)
+
+	claim := &models.WebSocketMessage{Type: models.MessageTypeAILiveClaim, Payload: map[string]interface{}{"enable": true}}
+	alice.handleAILiveClaim(claim)
+	bob.handleAILiveClaim(claim)
+
+	status := nodeB.getAILiveStatus(clusterTestMeeting)
+	require.NotNil(t, status)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, alice.ID, status.LeaderSessionID, "其他节点上的申请不能抢占领导者")
+	broadcast := waitForMessages(t, bob, models.MessageTypeAILiveStatus)[models.MessageTypeAILiveStatus]
+	var fromBroadcast models.AILiveStatusMessage
+	require.NoError(t, decodePayload(broadcast.Payload, &fromBroadcast))
+	assert.Equal(t, alice.ID, fromBroadcast.LeaderSessionID)
+
+	// 领导者离开后释放，其他节点上的参与者可以接任
+	nodeA.unregisterClient(alice)
+	assert.False(t, nodeB.getAILiveStatus(clusterTestMeeting).Enabled)
+	drainMessages(t, bob)
+	bob.handleAILiveClaim(claim)
+	assert.Equal(t, bob.ID, nodeB.getAILiveStatus(clusterTestMeeting).LeaderSessionID)
+}
+
+func TestCluster_DeadNodeSessionsExpire(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+
+	alice := joinClusterNode(nodeA, "session-alice", 1)
+	bob := joinClusterNode(nodeB, "session-bob", 2)
+	bob.handleAILiveClaim(&models.WebSocketMessage{Type: models.MessageTypeAILiveClaim, Payload: map[string]interface{}{"enable": true}})
+	require.Len(t, nodeA.collectRoomParticipants(clusterTestMeeting), 2)
+
+	// 模拟节点 B 宕机：不再续期，存活键过期
+	nodeB.cluster.stopOnce.Do(func() { close(nodeB.cluster.stop) })
+	mr.Del(clusterNodeKey("node-b"))
+
+	participants := nodeA.collectRoomParticipants(clusterTestMeeting)
+	require.Len(t, participants, 1)
+	assert.Equal(t, alice.ID, participants[0].SessionID)
+	keys, err := mr.HKeys(clusterMembersKey(clusterTestMeeting))
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice.ID}, keys)
+	assert.False(t, nodeA.getAILiveStatus(clusterTestMeeting).Enabled, "领导者所在节点下线视为已释放")
+}
+
+// TestCluster_ResumeOnOtherNodeEndsSuspendedSession 续传缓冲只在原节点：续传请求落到其他节点时原节点立即注销挂起的会话
+func TestCluster_ResumeOnOtherNodeEndsSuspendedSession(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+	nodeA.resume = resumeOptions{maxAttempts: 2, timeout: time.Minute, bufferSize: 8}
+
+	alice := joinClusterNode(nodeA, "session-alice", 1)
+	bob := joinClusterNode(nodeB, "session-bob", 2)
+	token := alice.resumeToken()
+	require.NotEmpty(t, token)
+
+	nodeA.disconnectClient(alice)
+	require.True(t, alice.suspended())
+	require.True(t, mr.Exists(clusterResumeKey(token)), "挂起的会话登记到 Redis")
+
+	nodeB.takeOverRemoteSession(token, alice.UserID, clusterTestMeeting)
+
+	left := waitForMessages(t, bob, models.MessageTypeUserLeft)[models.MessageTypeUserLeft]
+	assert.Equal(t, alice.ID, left.SessionID)
+	assert.Nil(t, nodeA.lookupResumable(token, alice.UserID, clusterTestMeeting))
+	assert.False(t, mr.Exists(clusterResumeKey(token)))
+	participants := nodeB.collectRoomParticipants(clusterTestMeeting)
+	require.Len(t, participants, 1)
+	assert.Equal(t, bob.ID, participants[0].SessionID)
+}
+
+// TestCluster_ModerationAndScreenShareAreClusterWide 强制静音与独占屏幕共享在所有节点上生效
+func TestCluster_ModerationAndScreenShareAreClusterWide(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+	roles := staticRoles{1: models.ParticipantRoleHost, 2: models.ParticipantRoleParticipant, 3: models.ParticipantRoleParticipant}
+	nodeA.roles, nodeB.roles = roles, roles
+
+	host := joinClusterNode(nodeA, "session-host", 1)
+	carol := joinClusterNode(nodeA, "session-carol", 3)
+	bob := joinClusterNode(nodeB, "session-bob", 2)
+
+	host.handleModerateMedia(moderateMessage(bob.UserID, models.ModerationMediaAudio, true))
+	assert.True(t, nodeB.mediaModerated(clusterTestMeeting, bob.UserID, models.ModerationMediaAudio), "其他节点上不能自行解除")
+	require.Len(t, nodeB.getModerationStates(clusterTestMeeting), 1)
+
+	// 独占模式：其他节点上已有共享时普通参与者不能开始共享
+	bob.handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-bob", ""))
+	drainMessages(t, carol)
+	carol.handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-carol", ""))
+	errs := drainErrors(t, carol)
+	require.Len(t, errs, 1)
+	require.Len(t, nodeA.getScreenShares(clusterTestMeeting), 1)
+	assert.Equal(t, bob.UserID, nodeA.getScreenShares(clusterTestMeeting)[0].UserID)
+
+	// 主办人在另一节点禁止共享：集群状态与共享者节点上的本地共享都被清除
+	host.handleModerateMedia(moderateMessage(bob.UserID, models.ModerationMediaScreen, true))
+	assert.Empty(t, nodeB.getScreenShares(clusterTestMeeting))
+	waitForMessages(t, bob, models.MessageTypeScreenShare)
+	room := nodeB.rooms[clusterTestMeeting]
+	room.mutex.RLock()
+	assert.Empty(t, room.screenShares)
+	room.mutex.RUnlock()
+
+	carol.handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-carol", ""))
+	shares := nodeB.getScreenShares(clusterTestMeeting)
+	require.Len(t, shares, 1)
+	assert.Equal(t, carol.UserID, shares[0].UserID)
+
+	// 房间在集群内清空后状态一并清除（等其他节点投递完离开通知再断开，避免向已关闭的通道投递）
+	nodeB.unregisterClient(bob)
+	waitForMessages(t, host, models.MessageTypeUserLeft, models.MessageTypeRoomInfo)
+	waitForMessages(t, carol, models.MessageTypeUserLeft, models.MessageTypeRoomInfo)
+	nodeA.unregisterClient(host)
+	nodeA.unregisterClient(carol)
+	assert.False(t, mr.Exists(clusterModerationKey(clusterTestMeeting)))
+	assert.False(t, mr.Exists(clusterScreenSharesKey(clusterTestMeeting)))
+}
+
+// TestCluster_HandsAndFeedbackAreClusterWide 举手队列与反馈以 Redis 为准：任一节点可见，全部放下与断开释放跨节点生效
+func TestCluster_HandsAndFeedbackAreClusterWide(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+	roles := staticRoles{1: models.ParticipantRoleHost, 2: models.ParticipantRoleParticipant, 3: models.ParticipantRoleParticipant}
+	nodeA.roles, nodeB.roles = roles, roles
+
+	host := joinClusterNode(nodeA, "session-host", 1)
+	carol := joinClusterNode(nodeA, "session-carol", 3)
+	bob := joinClusterNode(nodeB, "session-bob", 2)
+
+	bob.handleRaiseHand(raiseHand(models.RaiseHandActionRaise, 0))
+	carol.handleRaiseHand(raiseHand(models.RaiseHandActionRaise, 0))
+	assert.Equal(t, []uint{2, 3}, queueUsers(nodeA.getRaisedHands(clusterTestMeeting)))
+	assert.Equal(t, []uint{2, 3}, queueUsers(nodeB.getRaisedHands(clusterTestMeeting)))
+	waitForMessages(t, bob, models.MessageTypeRaiseHand)
+
+	// 在另一节点全部放下，举手者所在节点同样清空
+	host.handleRaiseHand(raiseHand(models.RaiseHandActionLowerAll, 0))
+	assert.Empty(t, nodeB.getRaisedHands(clusterTestMeeting))
+	lowered := waitForMessages(t, bob, models.MessageTypeRaiseHand)[models.MessageTypeRaiseHand]
+	var event models.RaiseHandEvent
+	require.NoError(t, decodePayload(lowered.Payload, &event))
+	assert.Equal(t, models.HandEventLoweredAll, event.Event)
+
+	bob.handleRaiseHand(raiseHand(models.RaiseHandActionRaise, 0))
+	bob.handleFeedback(&models.WebSocketMessage{Type: models.MessageTypeFeedback, Payload: models.FeedbackMessage{Feedback: models.FeedbackYes}})
+	require.Len(t, nodeA.getFeedback(clusterTestMeeting), 1)
+	assert.Equal(t, models.FeedbackYes, nodeA.getFeedback(clusterTestMeeting)[0].Feedback)
+	waitForMessages(t, host, models.MessageTypeRaiseHand, models.MessageTypeFeedback)
+
+	// 断开连接的节点释放举手与反馈，其他节点收到最新队列
+	nodeB.unregisterClient(bob)
+	assert.Empty(t, nodeA.getRaisedHands(clusterTestMeeting))
+	assert.Empty(t, nodeA.getFeedback(clusterTestMeeting))
+	received := waitForMessages(t, host, models.MessageTypeUserLeft, models.MessageTypeRoomInfo, models.MessageTypeRaiseHand, models.MessageTypeFeedback)
+	require.NoError(t, decodePayload(received[models.MessageTypeRaiseHand].Payload, &event))
+	assert.Equal(t, models.HandEventLowered, event.Event)
+	assert.Equal(t, bob.UserID, event.UserID)
+	assert.Empty(t, event.Queue)
+
+	waitForMessages(t, carol, models.MessageTypeUserLeft, models.MessageTypeRoomInfo)
+	carol.handleRaiseHand(raiseHand(models.RaiseHandActionRaise, 0))
+	nodeA.unregisterClient(host)
+	nodeA.unregisterClient(carol)
+	assert.False(t, mr.Exists(clusterHandsKey(clusterTestMeeting)))
+	assert.False(t, mr.Exists(clusterFeedbackKey(clusterTestMeeting)))
+}
+
+// TestCluster_LobbyDecisionReachesWaitingNode 等候者与主办人连接在不同节点：名单变化与准入结果跨节点生效
+func TestCluster_LobbyDecisionReachesWaitingNode(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := newClusterNode(t, mr, "node-a")
+	nodeB := newClusterNode(t, mr, "node-b")
+	roles := staticRoles{1: models.ParticipantRoleHost, 2: models.ParticipantRoleParticipant}
+	admissions := &memoryAdmissions{status: map[uint]models.ParticipantStatus{
+		1: models.ParticipantStatusJoined,
+		2: models.ParticipantStatusWaiting,
+	}}
+	for _, h := range []*WebSocketHandler{nodeA, nodeB} {
+		h.roles, h.admissions = roles, admissions
+	}
+
+	host := joinClusterNode(nodeA, "session-host", 1)
+	guest := &Client{
+		ID:           "session-guest",
+		UserID:       2,
+		Username:     "user_2",
+		Handler:      nodeB,
+		Send:         make(chan []byte, 64),
+		PrioritySend: make(chan []byte, 16),
+	}
+	guest.setMeetingID(clusterTestMeeting)
+	waiting, err := nodeB.needsAdmission(guest.UserID, guest.MeetingID())
+	require.NoError(t, err)
+	require.True(t, waiting)
+	nodeB.enterLobby(guest)
+	joined := waitForMessages(t, host, models.MessageTypeLobbyUpdate)[models.MessageTypeLobbyUpdate]
+	var update models.LobbyUpdateMessage
+	require.NoError(t, decodePayload(joined.Payload, &update))
+	assert.Equal(t, models.LobbyEventJoined, update.Event)
+
+	host.handleLobbyAction(lobbyAction(models.LobbyActionAdmit, guest.UserID))
+	received := waitForMessages(t, guest, models.MessageTypeLobbyStatus, models.MessageTypeRoomInfo)
+	var status models.LobbyStatusMessage
+	require.NoError(t, decodePayload(received[models.MessageTypeLobbyStatus].Payload, &status))
+	assert.Equal(t, models.LobbyStateAdmitted, status.State)
+	assert.Equal(t, host.UserID, status.ByUserID)
+	assert.False(t, nodeB.inLobby(guest))
+	assert.Len(t, nodeA.collectRoomParticipants(clusterTestMeeting), 2)
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// MeetingControlStore 主持控制的持久化与审计（生产环境为 SignalingService）
+type MeetingControlStore interface {
+	SetMeetingLocked(meetingID uint, locked bool) error
+	SetParticipantRole(userID, meetingID uint, role models.ParticipantRole) error
+	RecordOperation(entry *models.OperationLog) error
+}
+
+// handleHostControl 主办人/主持人的主持控制：全员静音、移出/封禁、锁定/解锁会议、变更角色（仅主办人）。
+// 持久化并审计后在本节点执行，结果广播给房间内所有人
+func (c *Client) handleHostControl(message *models.WebSocketMessage) {
+	var req models.HostControlMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid host control", err.Error())
+		return
+	}
+
+	h := c.Handler
+	if h.roles == nil {
+		c.sendErrorCode(403, "Host control denied", "participant roles unavailable")
+		return
+	}
+	role, err := h.roles.GetParticipantRole(c.UserID, c.MeetingID())
+	if err != nil || !role.CanModerate() {
+		c.sendErrorCode(403, "Host control denied", "only host or moderator can control the meeting")
+		return
+	}
+
+	var operation string
+	details := map[string]interface{}{}
+	switch req.Action {
+	case models.HostControlMuteAll:
+		operation = models.OperationMuteAll
+		details["allow_self_unmute"] = req.AllowSelfUnmute
+
+	case models.HostControlRemove, models.HostControlBan:
+		if !c.checkControlTarget(req.TargetUserID, func(target models.ParticipantRole) bool { return role.CanControl(target) }) {
+			return
+		}
+		status := models.ParticipantStatusLeft
+		operation = models.OperationRemoveParticipant
+		if req.Action == models.HostControlBan {
+			status = models.ParticipantStatusBanned
+			operation = models.OperationBanParticipant
+		}
+		if h.admissions != nil {
+			if err := h.admissions.SetParticipantStatus(req.TargetUserID, c.MeetingID(), status); err != nil {
+				c.sendErrorCode(500, "Host control failed", err.Error())
+				return
+			}
+		}
+		details["target_user_id"] = req.TargetUserID
+
+	case models.HostControlLock, models.HostControlUnlock:
+		locked := req.Action == models.HostControlLock
+		operation = models.OperationUnlockMeeting
+		if locked {
+			operation = models.OperationLockMeeting
+		}
+		if h.controls != nil {
+			if err := h.controls.SetMeetingLocked(c.MeetingID(), locked); err != nil {
+				c.sendErrorCode(500, "Host control failed", err.Error())
+				return
+			}
+		}
+
+	case models.HostControlSetRole:
+		if role != models.ParticipantRoleHost {
+			c.sendErrorCode(403, "Host control denied", "only the host can change roles")
+			return
+		}
+		if !req.Role.Assignable() {
+			c.sendError("Invalid host control", "role must be participant, moderator or presenter")
+			return
+		}
+		var previous models.ParticipantRole
+		if !c.checkControlTarget(req.TargetUserID, func(target models.ParticipantRole) bool {
+			previous = target
+			return target != models.ParticipantRoleHost
+		}) {
+			return
+		}
+		if h.controls != nil {
+			if err := h.controls.SetParticipantRole(req.TargetUserID, c.MeetingID(), req.Role); err != nil {
+				c.sendErrorCode(500, "Host control failed", err.Error())
+				return
+			}
+		}
+		operation = models.OperationChangeRole
+		details["target_user_id"] = req.TargetUserID
+		details["role"] = req.Role.String()
+		details["previous_role"] = previous.String()
+
+	default:
+		c.sendError("Invalid host control", "action must be mute_all, remove, ban, lock, unlock or set_role")
+		return
+	}
+
+	c.recordOperation(operation, details)
+	h.applyHostControl(c.MeetingID(), req, c.UserID)
+}
+
+// checkControlTarget 校验主持控制的目标：不能是自己，必须是会议参与者且 allowed 允许控制其角色
+func (c *Client) checkControlTarget(targetUserID uint, allowed func(target models.ParticipantRole) bool) bool {
+	if targetUserID == 0 {
+		c.sendError("Invalid host control", "target_user_id is required")
+		return false
+	}
+	if targetUserID == c.UserID {
+		c.sendErrorCode(403, "Host control denied", "cannot target yourself")
+		return false
+	}
+	targetRole, err := c.Handler.roles.GetParticipantRole(targetUserID, c.MeetingID())
+	if err != nil {
+		c.sendErrorCode(404, "Host control failed", "user is not in the meeting")
+		return false
+	}
+	if !allowed(targetRole) {
+		c.sendErrorCode(403, "Host control denied", "cannot control the host")
+		return false
+	}
+	return true
+}
+
+// recordOperation 写入审计日志；失败只记录日志，不影响操作结果
+func (c *Client) recordOperation(operation string, details map[string]interface{}) {
+	h := c.Handler
+	if h.controls == nil {
+		return
+	}
+
+	details["source"] = "signaling"
+	details["session_id"] = c.ID
+	data, err := json.Marshal(details)
+	if err != nil {
+		data = []byte("{}")
+	}
+	entry := &models.OperationLog{
+		UserID:       c.UserID,
+		Operation:    operation,
+		ResourceType: "meeting",
+		ResourceID:   c.MeetingID(),
+		Details:      string(data),
+	}
+	if c.Conn != nil {
+		if host, _, err := net.SplitHostPort(c.Conn.RemoteAddr().String()); err == nil {
+			entry.IPAddress = &host
+		}
+	}
+	if err := h.controls.RecordOperation(entry); err != nil {
+		logger.Error("Failed to record operation log",
+			logger.String("operation", operation),
+			logger.Uint("meeting_id", c.MeetingID()),
+			logger.Uint("user_id", c.UserID),
+			logger.Err(err))
+	}
+}
+
+// ApplyHostControl 执行会议服务发布的主持控制（已由会议服务持久化并审计）
+func (h *WebSocketHandler) ApplyHostControl(meetingID uint, control models.HostControlMessage, byUserID uint) {
+	h.applyHostControl(meetingID, control, byUserID)
+}
+
+// applyHostControl 广播主持控制结果并在本节点执行：强制静音非主持成员，断开被移出/封禁用户的连接
+func (h *WebSocketHandler) applyHostControl(meetingID uint, control models.HostControlMessage, byUserID uint) {
+	event := models.HostControlEvent{
+		Action:          control.Action,
+		TargetUserID:    control.TargetUserID,
+		AllowSelfUnmute: control.AllowSelfUnmute,
+		Role:            control.Role,
+		Locked:          control.Action == models.HostControlLock,
+		ByUserID:        byUserID,
+		Timestamp:       time.Now(),
+	}
+
+	logger.Info("Host control applied",
+		logger.Uint("meeting_id", meetingID),
+		logger.String("action", control.Action),
+		logger.Uint("target_user_id", control.TargetUserID),
+		logger.Uint("by_user_id", byUserID))
+
+	if control.Action == models.HostControlMuteAll && !control.AllowSelfUnmute {
+		h.muteAll(meetingID, byUserID)
+	}
+	if control.Action == models.HostControlRemove || control.Action == models.HostControlBan {
+		h.publishParticipantRemoved(meetingID, control.TargetUserID, byUserID)
+	}
+
+	h.broadcastToRoom(meetingID, hostControlMessage(meetingID, event), "")
+
+	h.enforceHostControl(meetingID, event)
+}
+
+func hostControlMessage(meetingID uint, event models.HostControlEvent) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		ID:         fmt.Sprintf("host_control_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeHostControl,
+		FromUserID: event.ByUserID,
+		MeetingID:  meetingID,
+		Payload:    event,
+		Timestamp:  time.Now(),
+	}
+}
+
+// muteAll 强制静音房间内所有非主办人/主持人成员的音频
+func (h *WebSocketHandler) muteAll(meetingID, byUserID uint) {
+	seen := make(map[uint]bool)
+	for _, participant := range h.collectRoomParticipants(meetingID) {
+		userID := participant.UserID
+		if seen[userID] || userID == byUserID {
+			continue
+		}
+		seen[userID] = true
+		if h.roles != nil {
+			if role, err := h.roles.GetParticipantRole(userID, meetingID); err == nil && role.CanModerate() {
+				continue
+			}
+		}
+		h.applyMediaModeration(meetingID, models.MediaModerationState{
+			UserID:    userID,
+			MediaType: models.ModerationMediaAudio,
+			Muted:     true,
+			ByUserID:  byUserID,
+			UpdatedAt: time.Now(),
+		})
+	}
+}
+
+// enforceHostControl 在本节点执行需要操作连接的主持控制（集群中其他节点收到广播后同样执行）
+func (h *WebSocketHandler) enforceHostControl(meetingID uint, event models.HostControlEvent) {
+	switch event.Action {
+	case models.HostControlRemove, models.HostControlBan:
+		removedFrom := make(map[uint]bool)
+		for _, client := range h.userClients(meetingID, event.TargetUserID) {
+			if roomID := client.MeetingID(); roomID != meetingID {
+				// 分组房间收不到主会议的广播：单独通知被移出者，并让媒体服务关闭其在分组房间中的 Peer
+				h.sendToClient(client.ID, hostControlMessage(meetingID, event))
+				if !removedFrom[roomID] {
+					removedFrom[roomID] = true
+					h.publishParticipantRemoved(roomID, event.TargetUserID, event.ByUserID)
+				}
+			}
+			h.unregisterClient(client)
+		}
+	case models.HostControlSetRole:
+		// 被提升为主持人后收到等候室名单
+		for _, client := range h.userClients(meetingID, event.TargetUserID) {
+			if !h.inLobby(client) {
+				client.sendLobbySnapshot()
+			}
+		}
+	}
+}
+
+// publishParticipantRemoved 通知媒体服务关闭被移出/封禁用户的 Peer（只由发起节点发布一次）
+func (h *WebSocketHandler) publishParticipantRemoved(meetingID, userID, byUserID uint) {
+	if h.events == nil || userID == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiLiveEventTimeout)
+	defer cancel()
+	if err := h.events.Publish(ctx, queue.ChannelSignalingEvents, &queue.PubSubMessage{
+		Type: queue.EventParticipantRemoved,
+		Payload: map[string]interface{}{
+			"meeting_id": meetingID,
+			"user_id":    userID,
+			"by_user_id": byUserID,
+		},
+		Source: "signaling-service",
+	}); err != nil {
+		logger.Warn("Failed to publish participant removed event",
+			logger.Uint("meeting_id", meetingID),
+			logger.Uint("user_id", userID),
+			logger.Err(err))
+	}
+}
+
+// enforceClusterHostControl 其他节点扇出的主持控制广播：断开本节点上的目标连接
+func (h *WebSocketHandler) enforceClusterHostControl(meetingID uint, data []byte) {
+	var message models.WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return
+	}
+	var event models.HostControlEvent
+	if err := decodePayload(message.Payload, &event); err != nil {
+		return
+	}
+	h.enforceHostControl(meetingID, event)
+}
+
+// userClients 本节点上该用户在会议中的所有连接（含等候室与该会议的分组房间）
+func (h *WebSocketHandler) userClients(meetingID, userID uint) []*Client {
+	if userID == 0 {
+		return nil
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	var clients []*Client
+	for _, client := range h.clients {
+		if client.UserID != userID {
+			continue
+		}
+		if client.MeetingID() == meetingID || client.parentMeetingID() == meetingID {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
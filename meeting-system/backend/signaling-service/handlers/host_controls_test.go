@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// memoryControls 内存中的会议锁定状态、角色与审计日志
+type memoryControls struct {
+	roles      staticRoles
+	locked     bool
+	operations []*models.OperationLog
+}
+
+func (m *memoryControls) SetMeetingLocked(_ uint, locked bool) error {
+	m.locked = locked
+	return nil
+}
+
+func (m *memoryControls) SetParticipantRole(userID, _ uint, role models.ParticipantRole) error {
+	m.roles[userID] = role
+	return nil
+}
+
+func (m *memoryControls) RecordOperation(entry *models.OperationLog) error {
+	m.operations = append(m.operations, entry)
+	return nil
+}
+
+// newHostControlHandler 会议 1：用户 7 主办人，8 主持人，9、10 普通参与者，均已在房间中
+func newHostControlHandler(t *testing.T) (*WebSocketHandler, *memoryControls, *memoryAdmissions, *recordingEvents, map[uint]*Client) {
+	h := newResumeHandler(0, 0, 0)
+	roles := staticRoles{
+		7:  models.ParticipantRoleHost,
+		8:  models.ParticipantRoleModerator,
+		9:  models.ParticipantRoleParticipant,
+		10: models.ParticipantRoleParticipant,
+	}
+	controls := &memoryControls{roles: roles}
+	admissions := &memoryAdmissions{status: map[uint]models.ParticipantStatus{
+		7: models.ParticipantStatusJoined, 8: models.ParticipantStatusJoined,
+		9: models.ParticipantStatusJoined, 10: models.ParticipantStatusJoined,
+	}}
+	events := &recordingEvents{}
+	h.roles = roles
+	h.controls = controls
+	h.admissions = admissions
+	h.events = events
+
+	clients := make(map[uint]*Client)
+	for _, userID := range []uint{7, 8, 9, 10} {
+		clients[userID] = joinResumeHandler(h, fmt.Sprintf("session-%d", userID), userID)
+	}
+	for _, client := range clients {
+		drainAll(t, client)
+	}
+	return h, controls, admissions, events, clients
+}
+
+func hostControl(control models.HostControlMessage) *models.WebSocketMessage {
+	return &models.WebSocketMessage{Type: models.MessageTypeHostControl, Payload: control}
+}
+
+func TestHostControl_MuteAllForcesNonModerators(t *testing.T) {
+	h, controls, _, events, clients := newHostControlHandler(t)
+
+	clients[8].handleMessage(hostControl(models.HostControlMessage{Action: models.HostControlMuteAll}))
+
+	states := h.getModerationStates(1)
+	require.Len(t, states, 2, "只强制静音普通参与者")
+	assert.Equal(t, uint(9), states[0].UserID)
+	assert.Equal(t, uint(10), states[1].UserID)
+	assert.Equal(t, models.ModerationMediaAudio, states[0].MediaType)
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, queue.EventMediaModeration, events.messages[0].Type)
+
+	for _, client := range clients {
+		messages, _ := drainAll(t, client)
+		controlEvents := decodeEvents[models.HostControlEvent](t, messages, models.MessageTypeHostControl)
+		require.Len(t, controlEvents, 1)
+		assert.Equal(t, models.HostControlMuteAll, controlEvents[0].Action)
+		assert.Equal(t, uint(8), controlEvents[0].ByUserID)
+	}
+
+	require.Len(t, controls.operations, 1)
+	assert.Equal(t, models.OperationMuteAll, controls.operations[0].Operation)
+	assert.Equal(t, uint(8), controls.operations[0].UserID)
+	assert.Equal(t, uint(1), controls.operations[0].ResourceID)
+}
+
+func TestHostControl_MuteAllAllowingSelfUnmuteOnlyNotifies(t *testing.T) {
+	h, _, _, events, clients := newHostControlHandler(t)
+
+	clients[7].handleMessage(hostControl(models.HostControlMessage{Action: models.HostControlMuteAll, AllowSelfUnmute: true}))
+
+	assert.Empty(t, h.getModerationStates(1))
+	assert.Empty(t, events.messages)
+	messages, _ := drainAll(t, clients[9])
+	controlEvents := decodeEvents[models.HostControlEvent](t, messages, models.MessageTypeHostControl)
+	require.Len(t, controlEvents, 1)
+	assert.True(t, controlEvents[0].AllowSelfUnmute)
+}
+
+func TestHostControl_BanDisconnectsAndBlocksRejoin(t *testing.T) {
+	h, controls, admissions, _, clients := newHostControlHandler(t)
+
+	clients[8].handleMessage(hostControl(models.HostControlMessage{Action: models.HostControlBan, TargetUserID: 9}))
+
+	assert.Equal(t, models.ParticipantStatusBanned, admissions.status[9])
+	messages, closed := drainAll(t, clients[9])
+	assert.True(t, closed, "被封禁后连接关闭")
+	controlEvents := decodeEvents[models.HostControlEvent](t, messages, models.MessageTypeHostControl)
+	require.Len(t, controlEvents, 1)
+	assert.Equal(t, uint(9), controlEvents[0].TargetUserID)
+	assert.Equal(t, 3, h.GetClientCount())
+
+	messages, _ = drainAll(t, clients[10])
+	assert.Subset(t, messageTypes(messages), []models.MessageType{models.MessageTypeHostControl, models.MessageTypeUserLeft})
+
+	require.Len(t, controls.operations, 1)
+	assert.Equal(t, models.OperationBanParticipant, controls.operations[0].Operation)
+	var details map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(controls.operations[0].Details), &details))
+	assert.Equal(t, float64(9), details["target_user_id"])
+}
+
+func TestHostControl_PermissionChecks(t *testing.T) {
+	_, controls, admissions, _, clients := newHostControlHandler(t)
+
+	cases := []struct {
+		name    string
+		from    uint
+		control models.HostControlMessage
+		code    int
+	}{
+		{"participant cannot mute all", 9, models.HostControlMessage{Action: models.HostControlMuteAll}, 403},
+		{"moderator cannot remove host", 8, models.HostControlMessage{Action: models.HostControlRemove, TargetUserID: 7}, 403},
+		{"cannot remove yourself", 7, models.HostControlMessage{Action: models.HostControlRemove, TargetUserID: 7}, 403},
+		{"moderator cannot change roles", 8, models.HostControlMessage{Action: models.HostControlSetRole, TargetUserID: 9, Role: models.ParticipantRoleModerator}, 403},
+		{"unknown target", 7, models.HostControlMessage{Action: models.HostControlBan, TargetUserID: 42}, 404},
+		{"host role not assignable", 7, models.HostControlMessage{Action: models.HostControlSetRole, TargetUserID: 9, Role: models.ParticipantRoleHost}, 400},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clients[tc.from].handleMessage(hostControl(tc.control))
+			errs := drainErrors(t, clients[tc.from])
+			require.Len(t, errs, 1)
+			assert.Equal(t, tc.code, errs[0].Code)
+		})
+	}
+
+	assert.Empty(t, controls.operations)
+	assert.Equal(t, models.ParticipantStatusJoined, admissions.status[7])
+}
+
+func TestHostControl_LockAndRoleChange(t *testing.T) {
+	_, controls, _, _, clients := newHostControlHandler(t)
+
+	clients[8].handleMessage(hostControl(models.HostControlMessage{Action: models.HostControlLock}))
+	assert.True(t, controls.locked)
+	messages, _ := drainAll(t, clients[9])
+	controlEvents := decodeEvents[models.HostControlEvent](t, messages, models.MessageTypeHostControl)
+	require.Len(t, controlEvents, 1)
+	assert.True(t, controlEvents[0].Locked)
+
+	// 被提升为主持人后实时生效，并收到等候室名单
+	clients[7].handleMessage(hostControl(models.HostControlMessage{Action: models.HostControlSetRole, TargetUserID: 9, Role: models.ParticipantRoleModerator}))
+	assert.Equal(t, models.ParticipantRoleModerator, controls.roles[9])
+	messages, _ = drainAll(t, clients[9])
+	controlEvents = decodeEvents[models.HostControlEvent](t, messages, models.MessageTypeHostControl)
+	require.Len(t, controlEvents, 1)
+	assert.Equal(t, models.ParticipantRoleModerator, controlEvents[0].Role)
+	assert.Contains(t, messageTypes(messages), models.MessageTypeLobbyUpdate)
+
+	clients[8].handleMessage(hostControl(models.HostControlMessage{Action: models.HostControlUnlock}))
+	assert.False(t, controls.locked)
+
+	require.Len(t, controls.operations, 3)
+	assert.Equal(t, models.OperationLockMeeting, controls.operations[0].Operation)
+	assert.Equal(t, models.OperationChangeRole, controls.operations[1].Operation)
+	assert.Equal(t, models.OperationUnlockMeeting, controls.operations[2].Operation)
+}
+
+func TestHostControl_ApplyFromMeetingService(t *testing.T) {
+	h, controls, admissions, events, clients := newHostControlHandler(t)
+
+	h.ApplyHostControl(1, models.HostControlMessage{Action: models.HostControlRemove, TargetUserID: 10}, 7)
+
+	_, closed := drainAll(t, clients[10])
+	assert.True(t, closed)
+	require.Len(t, events.messages, 1, "媒体服务关闭被移出者的 Peer")
+	assert.Equal(t, queue.EventParticipantRemoved, events.messages[0].Type)
+	assert.Equal(t, uint(10), events.messages[0].Payload["user_id"])
+	assert.Equal(t, models.ParticipantStatusJoined, admissions.status[10], "状态由会议服务持久化")
+	assert.Empty(t, controls.operations, "审计由会议服务记录")
+}
+
+func TestHostControl_RemoveReachesBreakoutRooms(t *testing.T) {
+	h, _, _, events, clients := newHostControlHandler(t)
+	require.True(t, h.moveClient(clients[10], 101, 1))
+	drainAll(t, clients[10])
+	events.messages = nil
+
+	h.ApplyHostControl(1, models.HostControlMessage{Action: models.HostControlRemove, TargetUserID: 10}, 7)
+
+	messages, closed := drainAll(t, clients[10])
+	assert.True(t, closed, "分组房间中的连接同样断开")
+	controlEvents := decodeEvents[models.HostControlEvent](t, messages, models.MessageTypeHostControl)
+	require.Len(t, controlEvents, 1, "分组房间收不到主会议广播，单独通知")
+	assert.Equal(t, uint(10), controlEvents[0].TargetUserID)
+	assert.Equal(t, 3, h.GetClientCount())
+
+	removedFrom := make([]interface{}, 0, len(events.messages))
+	for _, msg := range events.messages {
+		if msg.Type == queue.EventParticipantRemoved {
+			removedFrom = append(removedFrom, msg.Payload["meeting_id"])
+		}
+	}
+	assert.ElementsMatch(t, []interface{}{uint(1), uint(101)}, removedFrom, "媒体服务关闭主会议与分组房间中的 Peer")
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"meeting-system/shared/models"
+)
+
+// DeliverICERestart 把媒体服务的 ICE restart 通知推送给该用户在会议中的会话（集群内所有节点）
+func (h *WebSocketHandler) DeliverICERestart(meetingID, userID uint, restart *models.ICERestartMessage) {
+	if meetingID == 0 || userID == 0 || restart == nil || restart.PeerID == "" {
+		return
+	}
+	to := userID
+	h.forwardToUser(userID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("ice_restart_%s_%d", restart.PeerID, time.Now().UnixNano()),
+		Type:       models.MessageTypeICERestart,
+		FromUserID: 0, // 系统消息
+		ToUserID:   &to,
+		MeetingID:  meetingID,
+		PeerID:     restart.PeerID,
+		Payload:    restart,
+		Timestamp:  time.Now(),
+	})
+}
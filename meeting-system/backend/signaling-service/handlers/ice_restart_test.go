@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+func TestDeliverICERestart_ReachesOnlyTargetUser(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+
+	h.DeliverICERestart(1, 9, &models.ICERestartMessage{PeerID: "peer-9", RoomID: "room_1_1_000001", Reason: "failed", GracePeriodMs: 15000})
+
+	messages := mustDrain(t, clients[9])
+	require.Len(t, messages, 1)
+	assert.Equal(t, models.MessageTypeICERestart, messages[0].Type)
+	assert.Equal(t, "peer-9", messages[0].PeerID)
+	var restart models.ICERestartMessage
+	require.NoError(t, decodePayload(messages[0].Payload, &restart))
+	assert.Equal(t, models.ICERestartMessage{PeerID: "peer-9", RoomID: "room_1_1_000001", Reason: "failed", GracePeriodMs: 15000}, restart)
+
+	for _, userID := range []uint{7, 8, 10} {
+		assert.Empty(t, mustDrain(t, clients[userID]), "user %d", userID)
+	}
+
+	h.DeliverICERestart(2, 9, &models.ICERestartMessage{PeerID: "peer-9"})
+	assert.Empty(t, mustDrain(t, clients[9]), "只投递给该会议内的会话")
+}
@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+// AdmissionStore 等候室准入状态（生产环境为 SignalingService，持久化在参与者表，主办人重连或节点重启后不丢失）
+type AdmissionStore interface {
+	GetParticipantStatus(userID, meetingID uint) (models.ParticipantStatus, error)
+	SetParticipantStatus(userID, meetingID uint, status models.ParticipantStatus) error
+	ListWaitingParticipants(meetingID uint) ([]models.LobbyEntry, error)
+}
+
+// needsAdmission 会议服务把需要审批的用户登记为等候中，这些用户连接后进入等候室。
+// 查询失败时返回错误，调用方必须拒绝连接而不是放行（否则可绕过等候室）
+func (h *WebSocketHandler) needsAdmission(userID, meetingID uint) (bool, error) {
+	if h.admissions == nil {
+		return false, nil
+	}
+	status, err := h.admissions.GetParticipantStatus(userID, meetingID)
+	if err != nil {
+		return false, err
+	}
+	return status == models.ParticipantStatusWaiting, nil
+}
+
+// inLobby 连接是否仍在等候室中
+func (h *WebSocketHandler) inLobby(client *Client) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return client.waiting
+}
+
+// enterLobby 注册等候中的连接：不加入房间，收不到房间成员、媒体与广播；主办人/主持人收到等候通知
+func (h *WebSocketHandler) enterLobby(client *Client) {
+	h.mutex.Lock()
+	client.waiting = true
+	h.clients[client.ID] = client
+	if h.lobby == nil {
+		h.lobby = make(map[uint]map[string]*Client)
+	}
+	waiting, exists := h.lobby[client.MeetingID()]
+	if !exists {
+		waiting = make(map[string]*Client)
+		h.lobby[client.MeetingID()] = waiting
+	}
+	waiting[client.ID] = client
+	h.mutex.Unlock()
+
+	if h.signalingService != nil {
+		if err := h.signalingService.CreateSession(client.ID, client.UserID, client.MeetingID(), client.PeerID); err != nil {
+			logger.Error("Failed to create signaling session", logger.Err(err))
+		}
+	}
+
+	logger.Info("Client entered lobby",
+		logger.String("session", client.ID),
+		logger.Uint("user_id", client.UserID),
+		logger.Uint("meeting_id", client.MeetingID()))
+
+	client.sendLobbyStatus(models.LobbyStateWaiting, 0)
+	h.notifyLobby(client.MeetingID(), models.LobbyEventJoined, []models.LobbyEntry{lobbyEntryOf(client)}, 0)
+}
+
+// leaveLobbyLocked 从等候室移除连接，返回该用户是否已没有其他等候中的连接（调用方需持有 h.mutex）
+func (h *WebSocketHandler) leaveLobbyLocked(client *Client) bool {
+	waiting := h.lobby[client.MeetingID()]
+	if _, exists := waiting[client.ID]; !exists {
+		return false
+	}
+	delete(waiting, client.ID)
+	if len(waiting) == 0 {
+		delete(h.lobby, client.MeetingID())
+	}
+	for _, other := range waiting {
+		if other.UserID == client.UserID {
+			return false
+		}
+	}
+	return true
+}
+
+// unregisterWaitingLocked 注销等候中的连接（调用方持有 h.mutex，本函数负责解锁）
+func (h *WebSocketHandler) unregisterWaitingLocked(client *Client) {
+	lastSession := h.leaveLobbyLocked(client)
+	h.mutex.Unlock()
+
+	client.closeSend()
+
+	if h.signalingService != nil {
+		if err := h.signalingService.DisconnectSession(client.ID); err != nil {
+			logger.Error("Failed to disconnect signaling session", logger.Err(err))
+		}
+	}
+
+	if lastSession {
+		h.notifyLobby(client.MeetingID(), models.LobbyEventLeft, []models.LobbyEntry{lobbyEntryOf(client)}, 0)
+	}
+
+	logger.Info(fmt.Sprintf("WebSocket lobby client disconnected: %s", client.ID))
+}
+
+// takeFromLobby 取出用户在本节点上等候中的连接
+func (h *WebSocketHandler) takeFromLobby(meetingID, userID uint) []*Client {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	waiting := h.lobby[meetingID]
+	var clients []*Client
+	for sessionID, client := range waiting {
+		if client.UserID == userID {
+			clients = append(clients, client)
+			delete(waiting, sessionID)
+		}
+	}
+	if waiting != nil && len(waiting) == 0 {
+		delete(h.lobby, meetingID)
+	}
+	return clients
+}
+
+// lobbyEntries 等候室名单：优先读取持久化状态（包含尚未连接信令的等候者），失败时使用本节点的连接
+func (h *WebSocketHandler) lobbyEntries(meetingID uint) []models.LobbyEntry {
+	if h.admissions != nil {
+		entries, err := h.admissions.ListWaitingParticipants(meetingID)
+		if err == nil {
+			return entries
+		}
+		logger.Warn("Failed to load waiting participants; using local lobby",
+			logger.Uint("meeting_id", meetingID),
+			logger.Err(err))
+	}
+
+	h.mutex.RLock()
+	byUser := make(map[uint]models.LobbyEntry)
+	for _, client := range h.lobby[meetingID] {
+		entry, exists := byUser[client.UserID]
+		if !exists || client.JoinedAt.Before(entry.WaitingSince) {
+			byUser[client.UserID] = lobbyEntryOf(client)
+		}
+	}
+	h.mutex.RUnlock()
+
+	entries := make([]models.LobbyEntry, 0, len(byUser))
+	for _, entry := range byUser {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].WaitingSince.Equal(entries[j].WaitingSince) {
+			return entries[i].WaitingSince.Before(entries[j].WaitingSince)
+		}
+		return entries[i].UserID < entries[j].UserID
+	})
+	return entries
+}
+
+func lobbyEntryOf(client *Client) models.LobbyEntry {
+	return models.LobbyEntry{
+		UserID:       client.UserID,
+		Username:     client.Username,
+		WaitingSince: client.JoinedAt,
+	}
+}
+
+// handleLobbyAction 主办人/主持人准入、拒绝或全部准入等候者
+func (c *Client) handleLobbyAction(message *models.WebSocketMessage) {
+	var req models.LobbyActionMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid lobby action", err.Error())
+		return
+	}
+
+	h := c.Handler
+	if h.roles == nil {
+		c.sendErrorCode(403, "Lobby action denied", "participant roles unavailable")
+		return
+	}
+	role, err := h.roles.GetParticipantRole(c.UserID, c.MeetingID())
+	if err != nil || !role.CanModerate() {
+		c.sendErrorCode(403, "Lobby action denied", "only host or moderator can manage the lobby")
+		return
+	}
+
+	var userIDs []uint
+	switch req.Action {
+	case models.LobbyActionAdmit, models.LobbyActionDeny:
+		if req.UserID == 0 || !h.isWaiting(c.MeetingID(), req.UserID) {
+			c.sendErrorCode(404, "Lobby action failed", "user is not waiting")
+			return
+		}
+		userIDs = []uint{req.UserID}
+	case models.LobbyActionAdmitAll:
+		for _, entry := range h.lobbyEntries(c.MeetingID()) {
+			userIDs = append(userIDs, entry.UserID)
+		}
+	default:
+		c.sendError("Invalid lobby action", "action must be admit, deny or admit_all")
+		return
+	}
+
+	h.decideLobby(c.MeetingID(), userIDs, req.Action != models.LobbyActionDeny, c.UserID, true)
+}
+
+// isWaiting 用户是否在等候室中（以持久化状态为准）
+func (h *WebSocketHandler) isWaiting(meetingID, userID uint) bool {
+	if h.admissions != nil {
+		status, err := h.admissions.GetParticipantStatus(userID, meetingID)
+		return err == nil && status == models.ParticipantStatusWaiting
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for _, client := range h.lobby[meetingID] {
+		if client.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyLobbyDecision 执行会议服务发布的准入/拒绝结果（状态已由会议服务持久化）
+func (h *WebSocketHandler) ApplyLobbyDecision(meetingID uint, userIDs []uint, admitted bool, byUserID uint) {
+	h.decideLobby(meetingID, userIDs, admitted, byUserID, false)
+}
+
+// decideLobby 准入或拒绝一批等候者：persist 为 true 时写入参与状态，
+// 随后处理本节点上这些用户等候中的连接，并通知主办人/主持人名单变化（集群中其他节点收到通知后处理各自的连接）
+func (h *WebSocketHandler) decideLobby(meetingID uint, userIDs []uint, admit bool, byUserID uint, persist bool) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	known := make(map[uint]models.LobbyEntry)
+	if persist {
+		for _, entry := range h.lobbyEntries(meetingID) {
+			known[entry.UserID] = entry
+		}
+	}
+
+	status := models.ParticipantStatusRejected
+	if admit {
+		status = models.ParticipantStatusJoined
+	}
+
+	entries := make([]models.LobbyEntry, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if persist && h.admissions != nil {
+			if err := h.admissions.SetParticipantStatus(userID, meetingID, status); err != nil {
+				logger.Error("Failed to update lobby admission",
+					logger.Uint("meeting_id", meetingID),
+					logger.Uint("user_id", userID),
+					logger.Err(err))
+				continue
+			}
+		}
+
+		clients := h.takeFromLobby(meetingID, userID)
+		entry, exists := known[userID]
+		if !exists {
+			entry = models.LobbyEntry{UserID: userID, Username: fmt.Sprintf("user_%d", userID)}
+			if len(clients) > 0 {
+				entry = lobbyEntryOf(clients[0])
+			}
+		}
+		entries = append(entries, entry)
+		h.enforceLobbyDecision(clients, admit, byUserID)
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+
+	event := models.LobbyEventDenied
+	if admit {
+		event = models.LobbyEventAdmitted
+	}
+	logger.Info("Lobby decision applied",
+		logger.Uint("meeting_id", meetingID),
+		logger.String("event", event),
+		logger.Int("users", len(entries)),
+		logger.Uint("by_user_id", byUserID))
+	h.notifyLobby(meetingID, event, entries, byUserID)
+}
+
+// enforceLobbyDecision 准入或拒绝已从等候室取出的连接
+func (h *WebSocketHandler) enforceLobbyDecision(clients []*Client, admit bool, byUserID uint) {
+	for _, client := range clients {
+		if admit {
+			h.admitClient(client, byUserID)
+		} else {
+			client.sendLobbyStatus(models.LobbyStateDenied, byUserID)
+			h.unregisterClient(client)
+		}
+	}
+}
+
+// enforceClusterLobby 其他节点扇出的准入/拒绝通知：处理本节点上这些用户等候中的连接
+func (h *WebSocketHandler) enforceClusterLobby(meetingID uint, data []byte) {
+	var message models.WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return
+	}
+	var update models.LobbyUpdateMessage
+	if err := decodePayload(message.Payload, &update); err != nil {
+		return
+	}
+	if update.Event != models.LobbyEventAdmitted && update.Event != models.LobbyEventDenied {
+		return
+	}
+	for _, entry := range update.Entries {
+		h.enforceLobbyDecision(h.takeFromLobby(meetingID, entry.UserID), update.Event == models.LobbyEventAdmitted, update.ByUserID)
+	}
+}
+
+// admitClient 把等候中的连接加入房间，随后与正常入会一样下发 RoomInfo 并通知房间成员
+func (h *WebSocketHandler) admitClient(client *Client, byUserID uint) {
+	if h.cluster != nil {
+		if err := h.cluster.join(client); err != nil {
+			logger.Error("Failed to join cluster room", logger.Uint("meeting_id", client.MeetingID()), logger.Err(err))
+		}
+	}
+
+	h.mutex.Lock()
+	if current, exists := h.clients[client.ID]; !exists || current != client {
+		// 准入前连接已断开
+		h.mutex.Unlock()
+		if h.cluster != nil {
+			if _, err := h.cluster.leave(client.MeetingID(), client.ID); err != nil {
+				logger.Error("Failed to leave cluster room", logger.Uint("meeting_id", client.MeetingID()), logger.Err(err))
+			}
+		}
+		return
+	}
+	client.waiting = false
+	client.JoinedAt = time.Now()
+	h.attachSession(client)
+	h.addToRoomLocked(client)
+	h.mutex.Unlock()
+
+	if h.signalingService != nil {
+		if err := h.signalingService.UpdateSessionStatus(client.ID, models.SessionStatusConnected); err != nil {
+			logger.Error("Failed to update session status", logger.Err(err))
+		}
+	}
+
+	client.sendLobbyStatus(models.LobbyStateAdmitted, byUserID)
+	client.sendRoomInfo()
+	client.sendChatHistory()
+	h.broadcastUserJoined(client)
+}
+
+// handleWaitingMessage 等候中的连接只能发送心跳与离开；加入房间请求返回当前等候状态
+func (c *Client) handleWaitingMessage(message *models.WebSocketMessage) {
+	switch message.Type {
+	case models.MessageTypePing:
+		c.handlePing(message)
+	case models.MessageTypeLeaveRoom:
+		c.handleLeaveRoom(message)
+	case models.MessageTypeJoinRoom:
+		c.sendLobbyStatus(models.LobbyStateWaiting, 0)
+	default:
+		c.sendErrorCode(403, "Waiting for host approval", fmt.Sprintf("%s is not allowed in the lobby", message.Type))
+	}
+}
+
+// sendLobbySnapshot 主办人/主持人入会（含重连）时下发完整的等候室名单
+func (c *Client) sendLobbySnapshot() {
+	h := c.Handler
+	if h.roles == nil {
+		return
+	}
+	if role, err := h.roles.GetParticipantRole(c.UserID, c.MeetingID()); err != nil || !role.CanModerate() {
+		return
+	}
+
+	c.enqueueLobbyUpdate(models.LobbyUpdateMessage{
+		Event:   models.LobbyEventSnapshot,
+		Entries: h.lobbyEntries(c.MeetingID()),
+	})
+}
+
+// notifyLobby 通知房间内的主办人/主持人等候室名单变化（集群模式下扇出到所有节点）
+func (h *WebSocketHandler) notifyLobby(meetingID uint, event string, entries []models.LobbyEntry, byUserID uint) {
+	h.broadcastToModerators(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("lobby_update_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeLobbyUpdate,
+		FromUserID: 0, // 系统消息
+		MeetingID:  meetingID,
+		Payload:    models.LobbyUpdateMessage{Event: event, Entries: entries, ByUserID: byUserID},
+		Timestamp:  time.Now(),
+	})
+}
+
+func (c *Client) enqueueLobbyUpdate(update models.LobbyUpdateMessage) {
+	message := &models.WebSocketMessage{
+		ID:         fmt.Sprintf("lobby_update_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeLobbyUpdate,
+		FromUserID: 0, // 系统消息
+		MeetingID:  c.MeetingID(),
+		SessionID:  c.ID,
+		Payload:    update,
+		Timestamp:  time.Now(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Failed to marshal lobby update", logger.Err(err))
+		return
+	}
+	c.enqueue(data, "lobby_update")
+}
+
+// sendLobbyStatus 向等候者下发自身的准入状态
+func (c *Client) sendLobbyStatus(state string, byUserID uint) {
+	message := &models.WebSocketMessage{
+		ID:         fmt.Sprintf("lobby_status_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeLobbyStatus,
+		FromUserID: 0, // 系统消息
+		MeetingID:  c.MeetingID(),
+		SessionID:  c.ID,
+		PeerID:     c.PeerID,
+		Payload: models.LobbyStatusMessage{
+			State:     state,
+			MeetingID: c.MeetingID(),
+			ByUserID:  byUserID,
+		},
+		Timestamp: time.Now(),
+	}
+
+	data, _ := json.Marshal(message)
+	if !c.enqueuePriority(data, "lobby_status") {
+		c.enqueue(data, "lobby_status_fallback")
+	}
+}
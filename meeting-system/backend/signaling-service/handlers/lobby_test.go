@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+// memoryAdmissions 内存中的参与状态
+type memoryAdmissions struct {
+	mu     sync.Mutex
+	status map[uint]models.ParticipantStatus
+}
+
+func (m *memoryAdmissions) GetParticipantStatus(userID, _ uint) (models.ParticipantStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.status[userID]
+	if !ok {
+		return 0, errors.New("user not in meeting")
+	}
+	return status, nil
+}
+
+func (m *memoryAdmissions) SetParticipantStatus(userID, _ uint, status models.ParticipantStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status[userID] = status
+	return nil
+}
+
+func (m *memoryAdmissions) ListWaitingParticipants(_ uint) ([]models.LobbyEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []models.LobbyEntry
+	for userID, status := range m.status {
+		if status == models.ParticipantStatusWaiting {
+			entries = append(entries, models.LobbyEntry{UserID: userID, Username: fmt.Sprintf("user_%d", userID)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].UserID < entries[j].UserID })
+	return entries, nil
+}
+
+// newLobbyHandler 会议 1：用户 7 主办人与 8 普通参与者已在房间中，9、10 等候准入
+func newLobbyHandler() (*WebSocketHandler, *memoryAdmissions, map[uint]*Client) {
+	h := newResumeHandler(0, 0, 0)
+	h.roles = staticRoles{
+		7:  models.ParticipantRoleHost,
+		8:  models.ParticipantRoleParticipant,
+		9:  models.ParticipantRoleParticipant,
+		10: models.ParticipantRoleParticipant,
+	}
+	admissions := &memoryAdmissions{status: map[uint]models.ParticipantStatus{
+		7:  models.ParticipantStatusJoined,
+		8:  models.ParticipantStatusJoined,
+		9:  models.ParticipantStatusWaiting,
+		10: models.ParticipantStatusWaiting,
+	}}
+	h.admissions = admissions
+
+	clients := make(map[uint]*Client)
+	for _, userID := range []uint{7, 8, 9, 10} {
+		client := &Client{
+			ID:           fmt.Sprintf("session-%d", userID),
+			UserID:       userID,
+			Username:     fmt.Sprintf("user_%d", userID),
+			Handler:      h,
+			Send:         make(chan []byte, 64),
+			PrioritySend: make(chan []byte, 16),
+			JoinedAt:     time.Now(),
+		}
+		client.setMeetingID(1)
+		if waiting, _ := h.needsAdmission(userID, 1); waiting {
+			h.enterLobby(client)
+		} else {
+			h.registerClient(client)
+		}
+		clients[userID] = client
+	}
+	return h, admissions, clients
+}
+
+// drainAll 按优先队列、普通队列的顺序读取下发消息；通道已关闭时返回 closed=true
+func drainAll(t *testing.T, client *Client) (messages []models.WebSocketMessage, closed bool) {
+	t.Helper()
+	for _, ch := range []chan []byte{client.PrioritySend, client.Send} {
+		for drained := false; !drained; {
+			select {
+			case data, ok := <-ch:
+				if !ok {
+					closed = true
+					drained = true
+					continue
+				}
+				var msg models.WebSocketMessage
+				require.NoError(t, json.Unmarshal(data, &msg))
+				messages = append(messages, msg)
+			default:
+				drained = true
+			}
+		}
+	}
+	return messages, closed
+}
+
+// mustDrain 取出客户端的全部消息，连接不应被关闭
+func mustDrain(t *testing.T, client *Client) []models.WebSocketMessage {
+	t.Helper()
+	messages, closed := drainAll(t, client)
+	require.False(t, closed)
+	return messages
+}
+
+// decodeEvents 解码 messages 中 messageType 类型消息的载荷
+func decodeEvents[T any](t *testing.T, messages []models.WebSocketMessage, messageType models.MessageType) []T {
+	t.Helper()
+	var events []T
+	for _, msg := range messages {
+		if msg.Type != messageType {
+			continue
+		}
+		var event T
+		require.NoError(t, decodePayload(msg.Payload, &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func lobbyState(t *testing.T, msg models.WebSocketMessage) string {
+	t.Helper()
+	require.Equal(t, models.MessageTypeLobbyStatus, msg.Type)
+	var status models.LobbyStatusMessage
+	require.NoError(t, decodePayload(msg.Payload, &status))
+	return status.State
+}
+
+func lobbyAction(action string, userID uint) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		Type:    models.MessageTypeLobbyAction,
+		Payload: map[string]interface{}{"action": action, "user_id": userID},
+	}
+}
+
+func TestLobby_WaitingClientIsolatedUntilAdmitted(t *testing.T) {
+	h, admissions, clients := newLobbyHandler()
+	host, guest := clients[7], clients[9]
+
+	messages, _ := drainAll(t, guest)
+	require.Len(t, messages, 1)
+	assert.Equal(t, models.LobbyStateWaiting, lobbyState(t, messages[0]))
+
+	// 只有主办人收到等候通知
+	messages, _ = drainAll(t, host)
+	updates := decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate)
+	require.Len(t, updates, 2)
+	assert.Equal(t, models.LobbyEventJoined, updates[0].Event)
+	assert.Equal(t, uint(9), updates[0].Entries[0].UserID)
+	messages, _ = drainAll(t, clients[8])
+	assert.Empty(t, decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate))
+
+	// 等候者不在房间中，收不到广播与定向信令
+	for _, participant := range h.collectRoomParticipants(1) {
+		assert.NotEqual(t, uint(9), participant.UserID)
+	}
+	h.broadcastToRoom(1, chatFrom(host, "hello"), "")
+	h.forwardToUser(9, &models.WebSocketMessage{Type: models.MessageTypeOffer, MeetingID: 1, Payload: models.WebRTCOffer{SDP: "v=0"}})
+	messages, _ = drainAll(t, guest)
+	assert.Empty(t, messages)
+
+	// 等候中只能发送心跳
+	guest.handleMessage(chatFrom(guest, "let me in"))
+	errs := drainErrors(t, guest)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+
+	host.handleMessage(lobbyAction(models.LobbyActionAdmit, 9))
+	assert.Equal(t, models.ParticipantStatusJoined, admissions.status[9])
+
+	messages, _ = drainAll(t, guest)
+	require.GreaterOrEqual(t, len(messages), 2)
+	assert.Equal(t, models.LobbyStateAdmitted, lobbyState(t, messages[0]))
+	assert.Equal(t, models.MessageTypeRoomInfo, messages[1].Type)
+	assert.False(t, h.inLobby(guest))
+
+	messages, _ = drainAll(t, host)
+	assert.Contains(t, messageTypes(messages), models.MessageTypeUserJoined)
+	updates = decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate)
+	require.Len(t, updates, 1)
+	assert.Equal(t, models.LobbyEventAdmitted, updates[0].Event)
+	assert.Equal(t, uint(7), updates[0].ByUserID)
+
+	h.broadcastToRoom(1, chatFrom(host, "welcome"), host.ID)
+	messages, _ = drainAll(t, guest)
+	assert.Equal(t, []models.MessageType{models.MessageTypeChat}, messageTypes(messages))
+}
+
+func TestLobby_DenyDisconnectsWaitingClient(t *testing.T) {
+	h, admissions, clients := newLobbyHandler()
+	host, guest := clients[7], clients[10]
+	drainAll(t, host)
+	drainAll(t, guest)
+
+	host.handleMessage(lobbyAction(models.LobbyActionDeny, 10))
+	assert.Equal(t, models.ParticipantStatusRejected, admissions.status[10])
+
+	messages, closed := drainAll(t, guest)
+	require.Len(t, messages, 1)
+	assert.Equal(t, models.LobbyStateDenied, lobbyState(t, messages[0]))
+	assert.True(t, closed, "被拒绝后连接关闭")
+	assert.Equal(t, 3, h.GetClientCount())
+
+	// 主办人只收到拒绝通知，房间内没有离开广播
+	messages, _ = drainAll(t, host)
+	assert.Equal(t, []models.MessageType{models.MessageTypeLobbyUpdate}, messageTypes(messages))
+	updates := decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate)
+	assert.Equal(t, models.LobbyEventDenied, updates[0].Event)
+	assert.Equal(t, uint(10), updates[0].Entries[0].UserID)
+}
+
+func TestLobby_OnlyModeratorsCanAct(t *testing.T) {
+	h, admissions, clients := newLobbyHandler()
+	drainAll(t, clients[8])
+
+	clients[8].handleMessage(lobbyAction(models.LobbyActionAdmitAll, 0))
+	errs := drainErrors(t, clients[8])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+	assert.Equal(t, models.ParticipantStatusWaiting, admissions.status[9])
+	assert.True(t, h.inLobby(clients[9]))
+
+	// 不在等候室中的用户不能被准入
+	clients[7].handleMessage(lobbyAction(models.LobbyActionAdmit, 8))
+	errs = drainErrors(t, clients[7])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 404, errs[0].Code)
+}
+
+func TestLobby_HostReconnectReceivesSnapshotAndAdmitsAll(t *testing.T) {
+	h, admissions, clients := newLobbyHandler()
+
+	// 主办人断开重连：等候室状态保持，新会话收到完整名单
+	h.unregisterClient(clients[7])
+	assert.True(t, h.inLobby(clients[9]))
+	host := joinResumeHandler(h, "session-7-reconnected", 7)
+	host.sendLobbySnapshot()
+
+	messages, _ := drainAll(t, host)
+	updates := decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate)
+	require.Len(t, updates, 1)
+	assert.Equal(t, models.LobbyEventSnapshot, updates[0].Event)
+	require.Len(t, updates[0].Entries, 2)
+	assert.Equal(t, uint(9), updates[0].Entries[0].UserID)
+	assert.Equal(t, uint(10), updates[0].Entries[1].UserID)
+
+	host.handleMessage(lobbyAction(models.LobbyActionAdmitAll, 0))
+	assert.Equal(t, models.ParticipantStatusJoined, admissions.status[9])
+	assert.Equal(t, models.ParticipantStatusJoined, admissions.status[10])
+	assert.Len(t, h.collectRoomParticipants(1), 4)
+
+	messages, _ = drainAll(t, host)
+	updates = decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate)
+	require.Len(t, updates, 1)
+	assert.Len(t, updates[0].Entries, 2)
+}
+
+func TestLobby_WaitingClientLeavingNotifiesHosts(t *testing.T) {
+	h, _, clients := newLobbyHandler()
+	drainAll(t, clients[7])
+
+	clients[9].handleMessage(&models.WebSocketMessage{Type: models.MessageTypeLeaveRoom})
+	require.Eventually(t, func() bool { return h.GetClientCount() == 3 }, time.Second, 5*time.Millisecond)
+
+	messages, _ := drainAll(t, clients[7])
+	assert.Equal(t, []models.MessageType{models.MessageTypeLobbyUpdate}, messageTypes(messages))
+	updates := decodeEvents[models.LobbyUpdateMessage](t, messages, models.MessageTypeLobbyUpdate)
+	assert.Equal(t, models.LobbyEventLeft, updates[0].Event)
+	assert.Equal(t, uint(9), updates[0].Entries[0].UserID)
+}
+
+func TestLobby_ApplyLobbyDecisionFromMeetingService(t *testing.T) {
+	h, admissions, clients := newLobbyHandler()
+	drainAll(t, clients[9])
+
+	h.ApplyLobbyDecision(1, []uint{9}, true, 7)
+
+	assert.False(t, h.inLobby(clients[9]))
+	assert.Equal(t, models.ParticipantStatusWaiting, admissions.status[9], "状态由会议服务持久化")
+	messages, _ := drainAll(t, clients[9])
+	require.NotEmpty(t, messages)
+	assert.Equal(t, models.LobbyStateAdmitted, lobbyState(t, messages[0]))
+}
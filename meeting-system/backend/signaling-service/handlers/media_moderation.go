@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// RoleResolver 查询用户在会议中的角色（生产环境为 SignalingService）
+type RoleResolver interface {
+	GetParticipantRole(userID, meetingID uint) (models.ParticipantRole, error)
+}
+
+// decodePayload 把 WebSocket 消息中已解析为 map 的 payload 转为具体结构
+func decodePayload(payload interface{}, out interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func moderationKey(userID uint, mediaType string) string {
+	return fmt.Sprintf("%d/%s", userID, mediaType)
+}
+
+// handleModerateMedia 主持人/管理员强制静音、关闭画面或停止屏幕共享（或允许取消静音）：
+// 状态保存在房间内并广播给所有人，由媒体服务在 SFU 停止转发对应轨道
+func (c *Client) handleModerateMedia(message *models.WebSocketMessage) {
+	var req models.ModerateMediaMessage
+	if err := decodePayload(message.Payload, &req); err != nil || req.TargetUserID == 0 {
+		c.sendError("Invalid moderation request", "target_user_id is required")
+		return
+	}
+	if !models.ValidModerationMedia(req.MediaType) {
+		c.sendError("Invalid moderation request", "media_type must be audio, video or screen")
+		return
+	}
+
+	h := c.Handler
+	if h.roles == nil {
+		c.sendError("Moderation denied", "participant roles unavailable")
+		return
+	}
+	role, err := h.roles.GetParticipantRole(c.UserID, c.MeetingID())
+	if err != nil || !role.CanModerate() {
+		c.sendError("Moderation denied", "only host or moderator can moderate media")
+		return
+	}
+	if req.TargetUserID != c.UserID {
+		// 管理员不能管控主办人
+		if targetRole, err := h.roles.GetParticipantRole(req.TargetUserID, c.MeetingID()); err == nil &&
+			targetRole == models.ParticipantRoleHost && role != models.ParticipantRoleHost {
+			c.sendError("Moderation denied", "cannot moderate the host")
+			return
+		}
+	}
+
+	state := models.MediaModerationState{
+		UserID:    req.TargetUserID,
+		MediaType: req.MediaType,
+		Muted:     req.Muted,
+		ByUserID:  c.UserID,
+		UpdatedAt: time.Now(),
+	}
+	if !h.applyMediaModeration(c.MeetingID(), state) {
+		c.sendError("Room not found", "meeting room not available")
+	}
+}
+
+// applyMediaModeration 保存房间内的管控状态，通知媒体服务并广播给所有人；本节点房间不存在且未开启集群时返回 false
+func (h *WebSocketHandler) applyMediaModeration(meetingID uint, state models.MediaModerationState) bool {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil && h.cluster == nil {
+		return false
+	}
+
+	if room != nil {
+		room.mutex.Lock()
+		if state.Muted {
+			if room.moderation == nil {
+				room.moderation = make(map[string]models.MediaModerationState)
+			}
+			room.moderation[moderationKey(state.UserID, state.MediaType)] = state
+		} else {
+			delete(room.moderation, moderationKey(state.UserID, state.MediaType))
+		}
+		room.mutex.Unlock()
+	}
+	// 集群中以 Redis 为准，其他节点上的连接同样不能自行解除
+	if h.cluster != nil {
+		if err := h.cluster.setMediaModeration(meetingID, state); err != nil {
+			logger.Error("Failed to store cluster media moderation", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+	}
+
+	h.publishMediaModeration(meetingID, state)
+
+	logger.Info("Media moderated",
+		logger.Uint("meeting_id", meetingID),
+		logger.Uint("target_user_id", state.UserID),
+		logger.String("media_type", state.MediaType),
+		logger.Bool("muted", state.Muted),
+		logger.Uint("by_user_id", state.ByUserID))
+
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("media_moderated_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeMediaModerated,
+		FromUserID: state.ByUserID,
+		MeetingID:  meetingID,
+		Payload:    state,
+		Timestamp:  time.Now(),
+	}, "")
+
+	// 禁止屏幕共享时同时结束正在进行的共享
+	if state.Muted && state.MediaType == models.ModerationMediaScreen {
+		h.stopUserScreenShare(meetingID, state.UserID, state.ByUserID)
+	}
+	return true
+}
+
+// publishMediaModeration 通知媒体服务在 SFU 执行管控
+func (h *WebSocketHandler) publishMediaModeration(meetingID uint, state models.MediaModerationState) {
+	if h.events == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiLiveEventTimeout)
+	defer cancel()
+	if err := h.events.Publish(ctx, queue.ChannelSignalingEvents, &queue.PubSubMessage{
+		Type: queue.EventMediaModeration,
+		Payload: map[string]interface{}{
+			"meeting_id": meetingID,
+			"user_id":    state.UserID,
+			"media_type": state.MediaType,
+			"muted":      state.Muted,
+		},
+		Source: "signaling-service",
+	}); err != nil {
+		logger.Warn("Failed to publish media moderation event",
+			logger.Uint("meeting_id", meetingID),
+			logger.Uint("user_id", state.UserID),
+			logger.Err(err))
+	}
+}
+
+// mediaModerated 用户的某类媒体是否被强制静音
+func (h *WebSocketHandler) mediaModerated(meetingID, userID uint, mediaType string) bool {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return false
+	}
+
+	if h.cluster != nil {
+		muted, err := h.cluster.mediaModerated(meetingID, userID, mediaType)
+		if err == nil {
+			return muted
+		}
+		logger.Error("Failed to load cluster media moderation", logger.Uint("meeting_id", meetingID), logger.Err(err))
+	}
+
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+	_, muted := room.moderation[moderationKey(userID, mediaType)]
+	return muted
+}
+
+// getModerationStates 房间内当前的管控状态快照（按用户、媒体类型排序）
+func (h *WebSocketHandler) getModerationStates(meetingID uint) []models.MediaModerationState {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return nil
+	}
+
+	var states []models.MediaModerationState
+	if h.cluster != nil {
+		var err error
+		if states, err = h.cluster.moderationStates(meetingID); err != nil {
+			logger.Error("Failed to load cluster media moderation", logger.Uint("meeting_id", meetingID), logger.Err(err))
+			states = nil
+		}
+	}
+	if states == nil {
+		room.mutex.RLock()
+		states = make([]models.MediaModerationState, 0, len(room.moderation))
+		for _, state := range room.moderation {
+			states = append(states, state)
+		}
+		room.mutex.RUnlock()
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].UserID != states[j].UserID {
+			return states[i].UserID < states[j].UserID
+		}
+		return states[i].MediaType < states[j].MediaType
+	})
+	return states
+}
+
+// mediaControlUnmutes 客户端的媒体控制是否为恢复某类媒体（取消静音/开启画面/开始屏幕共享），返回对应的管控媒体类型
+func mediaControlUnmutes(control models.MediaControlMessage) (string, bool) {
+	switch control.Action {
+	case "unmute":
+		if control.MediaType == "" {
+			return models.ModerationMediaAudio, true
+		}
+		return control.MediaType, true
+	case "video_on":
+		return models.ModerationMediaVideo, true
+	case "screen_on", "screen_start":
+		return models.ModerationMediaScreen, true
+	}
+	return "", false
+}
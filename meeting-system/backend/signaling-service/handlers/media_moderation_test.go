@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+type staticRoles map[uint]models.ParticipantRole
+
+func (r staticRoles) GetParticipantRole(userID, _ uint) (models.ParticipantRole, error) {
+	role, ok := r[userID]
+	if !ok {
+		return 0, errors.New("user not in meeting")
+	}
+	return role, nil
+}
+
+// newModerationHandler 会议 1：用户 7 主办人，8 管理员，9 普通参与者
+func newModerationHandler() (*WebSocketHandler, map[uint]*Client, *recordingEvents) {
+	events := &recordingEvents{}
+	h := &WebSocketHandler{
+		clients: make(map[string]*Client),
+		rooms:   make(map[uint]*Room),
+		events:  events,
+		roles: staticRoles{
+			7: models.ParticipantRoleHost,
+			8: models.ParticipantRoleModerator,
+			9: models.ParticipantRoleParticipant,
+		},
+	}
+	room := &Room{ID: 1, Clients: make(map[string]*Client)}
+	clients := make(map[uint]*Client)
+	for _, userID := range []uint{7, 8, 9} {
+		client := &Client{ID: "session-" + string(rune('0'+userID)), UserID: userID, Handler: h, Send: make(chan []byte, 16)}
+		client.setMeetingID(1)
+		h.clients[client.ID] = client
+		room.Clients[client.ID] = client
+		clients[userID] = client
+	}
+	h.rooms[1] = room
+	return h, clients, events
+}
+
+func moderateMessage(target uint, mediaType string, muted bool) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		Type:    models.MessageTypeModerateMedia,
+		Payload: map[string]interface{}{"target_user_id": target, "media_type": mediaType, "muted": muted},
+	}
+}
+
+func TestModerateMedia_HostMutesParticipant(t *testing.T) {
+	h, clients, events := newModerationHandler()
+
+	clients[7].handleModerateMedia(moderateMessage(9, models.ModerationMediaAudio, true))
+
+	for _, client := range clients {
+		messages := drainMessages(t, client)
+		require.Len(t, messages, 1, "所有人都收到管控状态")
+		assert.Equal(t, models.MessageTypeMediaModerated, messages[0].Type)
+		payload := messages[0].Payload.(map[string]interface{})
+		assert.Equal(t, float64(9), payload["user_id"])
+		assert.Equal(t, true, payload["muted"])
+		assert.Equal(t, float64(7), payload["by_user_id"])
+	}
+
+	require.Len(t, events.messages, 1)
+	assert.Equal(t, queue.EventMediaModeration, events.messages[0].Type)
+	assert.Equal(t, uint(9), events.messages[0].Payload["user_id"])
+	assert.Equal(t, "audio", events.messages[0].Payload["media_type"])
+	assert.Equal(t, true, events.messages[0].Payload["muted"])
+
+	states := h.getModerationStates(1)
+	require.Len(t, states, 1)
+	assert.Equal(t, uint(9), states[0].UserID)
+
+	// 被静音者不能自行取消静音，但可以操作未被管控的媒体
+	clients[9].handleMediaControl(&models.WebSocketMessage{
+		Type:    models.MessageTypeMediaControl,
+		Payload: map[string]interface{}{"action": "unmute", "media_type": "audio", "user_id": 9},
+	})
+	messages := drainMessages(t, clients[9])
+	require.Len(t, messages, 1)
+	assert.Equal(t, models.MessageTypeError, messages[0].Type)
+	assert.Empty(t, drainMessages(t, clients[7]))
+
+	clients[9].handleMediaControl(&models.WebSocketMessage{
+		Type:    models.MessageTypeMediaControl,
+		Payload: map[string]interface{}{"action": "video_on", "media_type": "video", "user_id": 9},
+	})
+	assert.Len(t, drainMessages(t, clients[7]), 1)
+
+	// 主持人允许取消静音后恢复
+	clients[8].handleModerateMedia(moderateMessage(9, models.ModerationMediaAudio, false))
+	assert.Empty(t, h.getModerationStates(1))
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, false, events.messages[1].Payload["muted"])
+	drainMessages(t, clients[9])
+	clients[9].handleMediaControl(&models.WebSocketMessage{
+		Type:    models.MessageTypeMediaControl,
+		Payload: map[string]interface{}{"action": "unmute", "media_type": "audio", "user_id": 9},
+	})
+	assert.Empty(t, drainMessages(t, clients[9]))
+	assert.Len(t, drainMessages(t, clients[7]), 2)
+}
+
+func TestModerateMedia_Permissions(t *testing.T) {
+	h, clients, events := newModerationHandler()
+
+	cases := []struct {
+		name  string
+		actor uint
+		msg   *models.WebSocketMessage
+	}{
+		{"participant cannot moderate", 9, moderateMessage(8, models.ModerationMediaAudio, true)},
+		{"moderator cannot moderate host", 8, moderateMessage(7, models.ModerationMediaVideo, true)},
+		{"invalid media type", 7, moderateMessage(9, "microphone", true)},
+		{"missing target", 7, moderateMessage(0, models.ModerationMediaAudio, true)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clients[tc.actor].handleModerateMedia(tc.msg)
+			messages := drainMessages(t, clients[tc.actor])
+			require.Len(t, messages, 1)
+			assert.Equal(t, models.MessageTypeError, messages[0].Type)
+		})
+	}
+
+	assert.Empty(t, events.messages)
+	assert.Empty(t, h.getModerationStates(1))
+
+	// 管理员可以停止普通参与者的屏幕共享
+	clients[8].handleModerateMedia(moderateMessage(9, models.ModerationMediaScreen, true))
+	assert.True(t, h.mediaModerated(1, 9, models.ModerationMediaScreen))
+	assert.False(t, h.mediaModerated(1, 9, models.ModerationMediaVideo))
+}
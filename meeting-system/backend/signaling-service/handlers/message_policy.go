@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/logger"
+	"meeting-system/shared/metrics"
+	"meeting-system/shared/models"
+)
+
+const policyMetricsService = "signaling-service"
+
+const (
+	defaultViolationWindow  = time.Minute
+	defaultThrottleAfter    = 5
+	defaultThrottleDuration = 10 * time.Second
+	defaultDisconnectAfter  = 20
+
+	maxChatContentRunes   = models.MaxChatContentRunes
+	maxAILiveTextRunes    = 2000
+	maxAILiveTags         = 16
+	maxMediaControlAction = 32
+)
+
+// 违规原因（指标标签）
+const (
+	violationRateLimited    = "rate_limited"
+	violationOversized      = "oversized"
+	violationInvalidPayload = "invalid_payload"
+	violationThrottled      = "throttled"
+)
+
+// 违规处理动作（指标标签）
+const (
+	policyActionWarn       = "warn"
+	policyActionThrottle   = "throttle"
+	policyActionDisconnect = "disconnect"
+	policyActionDrop       = "drop"
+)
+
+type policyVerdict int
+
+const (
+	policyAllow policyVerdict = iota
+	policyDrop
+	policyDisconnect
+)
+
+// messageLimit 单个消息类型的限额；rate<=0 不限速，maxSize<=0 不限大小
+type messageLimit struct {
+	rate    float64
+	burst   int
+	maxSize int
+}
+
+// defaultMessageLimits 客户端可发送的消息类型的内置限额（ICE 候选在协商时成批到达，容量较大）
+var defaultMessageLimits = map[models.MessageType]messageLimit{
+	models.MessageTypeOffer:         {rate: 2, burst: 10, maxSize: 64 * 1024},
+	models.MessageTypeAnswer:        {rate: 2, burst: 10, maxSize: 64 * 1024},
+	models.MessageTypeICECandidate:  {rate: 50, burst: 200, maxSize: 4 * 1024},
+	models.MessageTypeJoinRoom:      {rate: 0.2, burst: 3, maxSize: 1024},
+	models.MessageTypeLeaveRoom:     {rate: 0.2, burst: 3, maxSize: 1024},
+	models.MessageTypeChat:          {rate: 2, burst: 10, maxSize: 8 * 1024},
+	models.MessageTypeScreenShare:   {rate: 0.5, burst: 4, maxSize: 1024},
+	models.MessageTypeMediaControl:  {rate: 2, burst: 10, maxSize: 1024},
+	models.MessageTypePing:          {rate: 1, burst: 5, maxSize: 1024},
+	models.MessageTypeAILiveClaim:   {rate: 0.5, burst: 3, maxSize: 1024},
+	models.MessageTypeAILiveResult:  {rate: 10, burst: 30, maxSize: 8 * 1024},
+	models.MessageTypeModerateMedia: {rate: 2, burst: 10, maxSize: 1024},
+	models.MessageTypeLobbyAction:   {rate: 2, burst: 10, maxSize: 1024},
+	models.MessageTypeHostControl:   {rate: 1, burst: 5, maxSize: 1024},
+	models.MessageTypeRaiseHand:     {rate: 1, burst: 5, maxSize: 512},
+	models.MessageTypeReaction:      {rate: 2, burst: 6, maxSize: 512},
+	models.MessageTypeFeedback:      {rate: 1, burst: 5, maxSize: 512},
+	models.MessageTypePoll:          {rate: 2, burst: 10, maxSize: 8 * 1024},
+	models.MessageTypeQuestion:      {rate: 1, burst: 5, maxSize: 8 * 1024},
+}
+
+// fallbackMessageLimit 其他类型（服务端下行类型或未知类型）的限额
+var fallbackMessageLimit = messageLimit{rate: 1, burst: 5, maxSize: 4 * 1024}
+
+// messagePolicy 上行消息策略；enabled=false 时全部放行
+type messagePolicy struct {
+	enabled         bool
+	limits          map[models.MessageType]messageLimit
+	window          time.Duration
+	throttleAfter   int
+	throttleFor     time.Duration
+	disconnectAfter int
+}
+
+func messagePolicyFromConfig(cfg config.MessagePolicyConfig) messagePolicy {
+	policy := messagePolicy{
+		enabled:         cfg.Enabled,
+		limits:          make(map[models.MessageType]messageLimit, len(defaultMessageLimits)),
+		window:          time.Duration(cfg.ViolationWindow) * time.Second,
+		throttleAfter:   cfg.ThrottleAfter,
+		throttleFor:     time.Duration(cfg.ThrottleDuration) * time.Second,
+		disconnectAfter: cfg.DisconnectAfter,
+	}
+	if policy.window <= 0 {
+		policy.window = defaultViolationWindow
+	}
+	if policy.throttleAfter <= 0 {
+		policy.throttleAfter = defaultThrottleAfter
+	}
+	if policy.throttleFor <= 0 {
+		policy.throttleFor = defaultThrottleDuration
+	}
+	if policy.disconnectAfter <= 0 {
+		policy.disconnectAfter = defaultDisconnectAfter
+	}
+	for messageType, limit := range defaultMessageLimits {
+		policy.limits[messageType] = limit
+	}
+
+	for name, override := range cfg.Limits {
+		messageType, ok := messageTypeByName(name)
+		if !ok {
+			logger.Warn("Ignoring message policy limit for unknown message type", logger.String("type", name))
+			continue
+		}
+		limit := policy.limitFor(messageType)
+		if override.Rate > 0 {
+			limit.rate = override.Rate
+		}
+		if override.Burst > 0 {
+			limit.burst = override.Burst
+		}
+		if override.MaxSize > 0 {
+			limit.maxSize = override.MaxSize
+		}
+		policy.limits[messageType] = limit
+	}
+	return policy
+}
+
+func messageTypeByName(name string) (models.MessageType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for messageType := models.MessageTypeOffer; messageType <= models.MessageTypeMax; messageType++ {
+		if messageType.String() == name {
+			return messageType, true
+		}
+	}
+	return 0, false
+}
+
+func (p messagePolicy) limitFor(messageType models.MessageType) messageLimit {
+	if limit, ok := p.limits[messageType]; ok {
+		return limit
+	}
+	return fallbackMessageLimit
+}
+
+// messageBucket 令牌桶（令牌按时间连续补充，支持每秒不足一个的速率）
+type messageBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *messageBucket) allow(limit messageLimit, now time.Time) bool {
+	if limit.rate <= 0 {
+		return true
+	}
+	burst := float64(limit.burst)
+	if burst < 1 {
+		burst = 1
+	}
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * limit.rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sessionLimiter 会话的限流与违规状态，续传后由新连接沿用
+type sessionLimiter struct {
+	mu             sync.Mutex
+	buckets        map[models.MessageType]*messageBucket
+	violations     int
+	windowStart    time.Time
+	throttledUntil time.Time
+}
+
+func newSessionLimiter() *sessionLimiter {
+	return &sessionLimiter{buckets: make(map[models.MessageType]*messageBucket)}
+}
+
+func (l *sessionLimiter) bucket(messageType models.MessageType) *messageBucket {
+	bucket, ok := l.buckets[messageType]
+	if !ok {
+		bucket = &messageBucket{}
+		l.buckets[messageType] = bucket
+	}
+	return bucket
+}
+
+// recordViolationLocked 累计一次违规并返回升级后的处理动作
+func (l *sessionLimiter) recordViolationLocked(policy messagePolicy, now time.Time) string {
+	if now.Sub(l.windowStart) > policy.window {
+		l.windowStart = now
+		l.violations = 0
+	}
+	l.violations++
+	switch {
+	case l.violations >= policy.disconnectAfter:
+		return policyActionDisconnect
+	case l.violations >= policy.throttleAfter:
+		l.throttledUntil = now.Add(policy.throttleFor)
+		return policyActionThrottle
+	default:
+		return policyActionWarn
+	}
+}
+
+// admitMessage 在分发前对客户端消息执行限流、大小与载荷校验；size 为原始帧字节数
+func (c *Client) admitMessage(message *models.WebSocketMessage, size int) policyVerdict {
+	policy := c.Handler.policy
+	if !policy.enabled {
+		return policyAllow
+	}
+	if c.limiter == nil {
+		c.limiter = newSessionLimiter()
+	}
+
+	limit := policy.limitFor(message.Type)
+	now := time.Now()
+
+	c.limiter.mu.Lock()
+	var reason, details string
+	switch {
+	case limit.maxSize > 0 && size > limit.maxSize:
+		reason = violationOversized
+		details = fmt.Sprintf("%s message exceeds %d bytes", message.Type, limit.maxSize)
+	case !c.limiter.bucket(message.Type).allow(limit, now):
+		reason = violationRateLimited
+		details = fmt.Sprintf("too many %s messages", message.Type)
+	default:
+		if err := validateMessagePayload(message, c.UserID); err != nil {
+			reason = violationInvalidPayload
+			details = err.Error()
+		}
+	}
+
+	if reason == "" {
+		throttled := now.Before(c.limiter.throttledUntil) && message.Type != models.MessageTypePing
+		c.limiter.mu.Unlock()
+		if throttled {
+			// 限流期间未超速的消息静默丢弃，继续超速仍按违规累计
+			metrics.RecordSignalingPolicyViolation(policyMetricsService, message.Type.String(), violationThrottled, policyActionDrop)
+			return policyDrop
+		}
+		return policyAllow
+	}
+
+	action := c.limiter.recordViolationLocked(policy, now)
+	violations := c.limiter.violations
+	c.limiter.mu.Unlock()
+
+	metrics.RecordSignalingPolicyViolation(policyMetricsService, message.Type.String(), reason, action)
+	logger.Warn("Signaling message policy violation",
+		logger.String("session", c.ID),
+		logger.Uint("user_id", c.UserID),
+		logger.String("type", message.Type.String()),
+		logger.String("reason", reason),
+		logger.String("action", action),
+		logger.Int("violations", violations),
+	)
+
+	code := 400
+	switch reason {
+	case violationOversized:
+		code = 413
+	case violationRateLimited:
+		code = 429
+	}
+	switch action {
+	case policyActionDisconnect:
+		c.sendErrorCode(code, "Message policy violation", details+"; disconnecting")
+		return policyDisconnect
+	case policyActionThrottle:
+		c.sendErrorCode(code, "Rate limited", fmt.Sprintf("%s; messages dropped for %s", details, policy.throttleFor))
+	default:
+		c.sendErrorCode(code, "Message rejected", details)
+	}
+	return policyDrop
+}
+
+// validateMessagePayload 按消息类型校验载荷结构与取值范围
+func validateMessagePayload(message *models.WebSocketMessage, senderID uint) error {
+	switch message.Type {
+	case models.MessageTypeOffer, models.MessageTypeAnswer:
+		var sdp models.WebRTCOffer
+		if err := decodePayload(message.Payload, &sdp); err != nil {
+			return fmt.Errorf("invalid session description: %w", err)
+		}
+		if strings.TrimSpace(sdp.SDP) == "" {
+			return errors.New("sdp is required")
+		}
+		if expected := message.Type.String(); sdp.Type != "" && sdp.Type != expected {
+			return fmt.Errorf("sdp type must be %s", expected)
+		}
+	case models.MessageTypeICECandidate:
+		var candidate models.ICECandidate
+		if err := decodePayload(message.Payload, &candidate); err != nil {
+			return fmt.Errorf("invalid ice candidate: %w", err)
+		}
+		if candidate.SDPMLineIndex < 0 {
+			return errors.New("sdpMLineIndex must not be negative")
+		}
+	case models.MessageTypeChat:
+		var chat models.ChatMessage
+		if err := decodePayload(message.Payload, &chat); err != nil {
+			return fmt.Errorf("invalid chat message: %w", err)
+		}
+		switch chat.Action {
+		case "", models.ChatActionSend, models.ChatActionEdit:
+			if strings.TrimSpace(chat.Content) == "" {
+				return errors.New("chat content is required")
+			}
+			if utf8.RuneCountInString(chat.Content) > maxChatContentRunes {
+				return fmt.Errorf("chat content exceeds %d characters", maxChatContentRunes)
+			}
+		case models.ChatActionDelete:
+		default:
+			return errors.New("chat action must be send, edit or delete")
+		}
+		if chat.Action == models.ChatActionEdit || chat.Action == models.ChatActionDelete {
+			if chat.ID == "" {
+				return errors.New("chat id is required")
+			}
+		}
+		if chat.ToUserID != 0 && chat.ToUserID == senderID {
+			return errors.New("cannot send a private message to yourself")
+		}
+		if chat.UserID != 0 && chat.UserID != senderID {
+			return errors.New("chat user_id does not match sender")
+		}
+	case models.MessageTypeMediaControl:
+		var control models.MediaControlMessage
+		if err := decodePayload(message.Payload, &control); err != nil {
+			return fmt.Errorf("invalid media control: %w", err)
+		}
+		if control.Action == "" || len(control.Action) > maxMediaControlAction {
+			return errors.New("media control action is required")
+		}
+		if control.MediaType != "" && !models.ValidModerationMedia(control.MediaType) {
+			return errors.New("media_type must be audio, video or screen")
+		}
+	case models.MessageTypeAILiveClaim:
+		var claim struct {
+			Enable *bool `json:"enable"`
+		}
+		if err := decodePayload(message.Payload, &claim); err != nil {
+			return fmt.Errorf("invalid ai live claim: %w", err)
+		}
+	case models.MessageTypeAILiveResult:
+		var result models.AILiveResultMessage
+		if err := decodePayload(message.Payload, &result); err != nil {
+			return fmt.Errorf("invalid ai live result: %w", err)
+		}
+		if result.LineID == "" {
+			return errors.New("line_id is required")
+		}
+		if utf8.RuneCountInString(result.Text) > maxAILiveTextRunes {
+			return fmt.Errorf("text exceeds %d characters", maxAILiveTextRunes)
+		}
+		if len(result.Tags) > maxAILiveTags {
+			return fmt.Errorf("at most %d tags are allowed", maxAILiveTags)
+		}
+	case models.MessageTypeModerateMedia:
+		var req models.ModerateMediaMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid moderation request: %w", err)
+		}
+		if req.TargetUserID == 0 {
+			return errors.New("target_user_id is required")
+		}
+		if !models.ValidModerationMedia(req.MediaType) {
+			return errors.New("media_type must be audio, video or screen")
+		}
+	case models.MessageTypeLobbyAction:
+		var req models.LobbyActionMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid lobby action: %w", err)
+		}
+		switch req.Action {
+		case models.LobbyActionAdmit, models.LobbyActionDeny:
+			if req.UserID == 0 {
+				return errors.New("user_id is required")
+			}
+		case models.LobbyActionAdmitAll:
+		default:
+			return errors.New("action must be admit, deny or admit_all")
+		}
+	case models.MessageTypeHostControl:
+		var req models.HostControlMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid host control: %w", err)
+		}
+		switch req.Action {
+		case models.HostControlRemove, models.HostControlBan:
+			if req.TargetUserID == 0 {
+				return errors.New("target_user_id is required")
+			}
+		case models.HostControlSetRole:
+			if req.TargetUserID == 0 {
+				return errors.New("target_user_id is required")
+			}
+			if !req.Role.Assignable() {
+				return errors.New("role must be participant, moderator or presenter")
+			}
+		case models.HostControlMuteAll, models.HostControlLock, models.HostControlUnlock:
+		default:
+			return errors.New("action must be mute_all, remove, ban, lock, unlock or set_role")
+		}
+	case models.MessageTypeRaiseHand:
+		var req models.RaiseHandMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid raise hand request: %w", err)
+		}
+		switch req.Action {
+		case models.RaiseHandActionRaise, models.RaiseHandActionLower, models.RaiseHandActionLowerAll:
+		default:
+			return errors.New("action must be raise, lower or lower_all")
+		}
+	case models.MessageTypeReaction:
+		var req models.ReactionMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid reaction: %w", err)
+		}
+		if !models.ValidReaction(req.Reaction) {
+			return fmt.Errorf("unsupported reaction %q", req.Reaction)
+		}
+	case models.MessageTypeFeedback:
+		var req models.FeedbackMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid feedback: %w", err)
+		}
+		if !models.ValidFeedback(req.Feedback) {
+			return errors.New("feedback must be yes, no, slower, faster or empty")
+		}
+	case models.MessageTypePoll:
+		var req models.PollMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid poll request: %w", err)
+		}
+		switch req.Action {
+		case models.PollActionCreate:
+			if len(req.Options) > maxPollOptions {
+				return fmt.Errorf("at most %d options are allowed", maxPollOptions)
+			}
+		case models.PollActionVote, models.PollActionClose:
+			if req.PollID == 0 {
+				return errors.New("poll_id is required")
+			}
+		default:
+			return errors.New("action must be create, vote or close")
+		}
+	case models.MessageTypeQuestion:
+		var req models.QuestionMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid question request: %w", err)
+		}
+		switch req.Action {
+		case models.QuestionActionAsk:
+			if utf8.RuneCountInString(req.Text) > maxQuestionRunes {
+				return fmt.Errorf("text exceeds %d characters", maxQuestionRunes)
+			}
+		case models.QuestionActionUpvote, models.QuestionActionUnvote, models.QuestionActionApprove,
+			models.QuestionActionAnswer, models.QuestionActionDismiss:
+			if req.QuestionID == 0 {
+				return errors.New("question_id is required")
+			}
+		default:
+			return errors.New("action must be ask, upvote, unvote, approve, answer or dismiss")
+		}
+	case models.MessageTypeScreenShare:
+		var req models.ScreenShareMessage
+		if err := decodePayload(message.Payload, &req); err != nil {
+			return fmt.Errorf("invalid screen share request: %w", err)
+		}
+		if req.Action != models.ScreenShareActionStart && req.Action != models.ScreenShareActionStop {
+			return errors.New("action must be start or stop")
+		}
+		if !validScreenShareHint(req.ContentHint) {
+			return errors.New("content_hint must be motion, detail or text")
+		}
+	}
+	return nil
+}
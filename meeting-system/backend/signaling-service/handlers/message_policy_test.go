@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/models"
+)
+
+func newPolicyClient(policy messagePolicy) *Client {
+	h := newResumeHandler(0, 0, 0)
+	h.policy = policy
+	return joinResumeHandler(h, "session-policy", 1)
+}
+
+// drainErrors 读取优先队列中的错误消息
+func drainErrors(t *testing.T, client *Client) []models.ErrorMessage {
+	t.Helper()
+	var errs []models.ErrorMessage
+	for {
+		select {
+		case data := <-client.PrioritySend:
+			var msg models.WebSocketMessage
+			require.NoError(t, json.Unmarshal(data, &msg))
+			if msg.Type != models.MessageTypeError {
+				continue
+			}
+			var payload models.ErrorMessage
+			require.NoError(t, decodePayload(msg.Payload, &payload))
+			errs = append(errs, payload)
+		default:
+			return errs
+		}
+	}
+}
+
+func chatMessage(content string) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		Type:    models.MessageTypeChat,
+		Payload: map[string]interface{}{"content": content, "user_id": 1},
+	}
+}
+
+func TestMessagePolicy_EscalatesFromWarnToThrottleToDisconnect(t *testing.T) {
+	policy := messagePolicyFromConfig(config.MessagePolicyConfig{
+		Enabled:          true,
+		ThrottleAfter:    2,
+		ThrottleDuration: 60,
+		DisconnectAfter:  4,
+		Limits:           map[string]config.MessageLimitConfig{"chat": {Rate: 0.001, Burst: 2}},
+	})
+	client := newPolicyClient(policy)
+	before := policyViolationCount(t, models.MessageTypeChat, violationRateLimited, policyActionWarn)
+
+	assert.Equal(t, policyAllow, client.admitMessage(chatMessage("1"), 64))
+	assert.Equal(t, policyAllow, client.admitMessage(chatMessage("2"), 64))
+	assert.Empty(t, drainErrors(t, client))
+
+	// 第一次违规：警告
+	assert.Equal(t, policyDrop, client.admitMessage(chatMessage("3"), 64))
+	errs := drainErrors(t, client)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 429, errs[0].Code)
+	assert.Equal(t, "Message rejected", errs[0].Message)
+	assert.Equal(t, before+1, policyViolationCount(t, models.MessageTypeChat, violationRateLimited, policyActionWarn))
+
+	// 第二次违规：限流，心跳仍然放行
+	assert.Equal(t, policyDrop, client.admitMessage(chatMessage("4"), 64))
+	errs = drainErrors(t, client)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Rate limited", errs[0].Message)
+	assert.Equal(t, policyAllow, client.admitMessage(&models.WebSocketMessage{Type: models.MessageTypePing}, 16))
+
+	// 持续违规：断开
+	assert.Equal(t, policyDrop, client.admitMessage(chatMessage("5"), 64))
+	assert.Equal(t, policyDisconnect, client.admitMessage(chatMessage("6"), 64))
+}
+
+func TestMessagePolicy_ThrottleDropsMessagesUntilExpiry(t *testing.T) {
+	policy := messagePolicyFromConfig(config.MessagePolicyConfig{Enabled: true, ThrottleAfter: 1})
+	policy.throttleFor = 30 * time.Millisecond
+	client := newPolicyClient(policy)
+
+	assert.Equal(t, policyDrop, client.admitMessage(chatMessage(""), 64), "空聊天内容无效")
+	errs := drainErrors(t, client)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 400, errs[0].Code)
+
+	// 限流期间合法消息被静默丢弃
+	assert.Equal(t, policyDrop, client.admitMessage(chatMessage("hello"), 64))
+	assert.Empty(t, drainErrors(t, client))
+
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, policyAllow, client.admitMessage(chatMessage("hello"), 64))
+}
+
+func TestMessagePolicy_RejectsOversizedMessages(t *testing.T) {
+	client := newPolicyClient(messagePolicyFromConfig(config.MessagePolicyConfig{Enabled: true}))
+
+	assert.Equal(t, policyDrop, client.admitMessage(&models.WebSocketMessage{Type: models.MessageTypeMediaControl}, 2048))
+	errs := drainErrors(t, client)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 413, errs[0].Code)
+
+	// 不同类型的限额相互独立
+	assert.Equal(t, policyAllow, client.admitMessage(chatMessage(strings.Repeat("a", 2000)), 2048))
+}
+
+func TestMessagePolicy_DisabledAllowsEverything(t *testing.T) {
+	client := newPolicyClient(messagePolicyFromConfig(config.MessagePolicyConfig{}))
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, policyAllow, client.admitMessage(chatMessage(""), 1<<20))
+	}
+	assert.Nil(t, client.limiter)
+}
+
+func TestMessagePolicyFromConfig_OverridesLimits(t *testing.T) {
+	policy := messagePolicyFromConfig(config.MessagePolicyConfig{
+		Enabled: true,
+		Limits: map[string]config.MessageLimitConfig{
+			"Media-Control": {MaxSize: 4096},
+			"poll":          {Rate: 0.5, Burst: 2},
+			"no-such-type":  {Rate: 100},
+		},
+	})
+
+	limit := policy.limitFor(models.MessageTypeMediaControl)
+	assert.Equal(t, 4096, limit.maxSize)
+	assert.Equal(t, defaultMessageLimits[models.MessageTypeMediaControl].rate, limit.rate, "未覆盖的字段沿用内置值")
+	assert.Equal(t, fallbackMessageLimit, policy.limitFor(models.MessageTypeRoomInfo))
+	assert.Equal(t, 0.5, policy.limitFor(models.MessageTypePoll).rate, "会话续传之后新增的类型同样可以覆盖")
+	assert.Equal(t, 2, policy.limitFor(models.MessageTypePoll).burst)
+	assert.Equal(t, defaultThrottleAfter, policy.throttleAfter)
+	assert.Equal(t, defaultViolationWindow, policy.window)
+}
+
+func TestValidateMessagePayload(t *testing.T) {
+	cases := []struct {
+		name    string
+		message models.WebSocketMessage
+		valid   bool
+	}{
+		{"offer", models.WebSocketMessage{Type: models.MessageTypeOffer, Payload: models.WebRTCOffer{SDP: "v=0", Type: "offer"}}, true},
+		{"offer without sdp", models.WebSocketMessage{Type: models.MessageTypeOffer, Payload: map[string]interface{}{"type": "offer"}}, false},
+		{"answer labelled offer", models.WebSocketMessage{Type: models.MessageTypeAnswer, Payload: models.WebRTCOffer{SDP: "v=0", Type: "offer"}}, false},
+		{"ice candidate", models.WebSocketMessage{Type: models.MessageTypeICECandidate, Payload: map[string]interface{}{"candidate": "c", "sdpMid": "0", "sdpMLineIndex": 0}}, true},
+		{"ice candidate wrong shape", models.WebSocketMessage{Type: models.MessageTypeICECandidate, Payload: "candidate"}, false},
+		{"chat", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Content: "hi", UserID: 7}}, true},
+		{"chat spoofed sender", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Content: "hi", UserID: 8}}, false},
+		{"chat too long", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Content: strings.Repeat("字", maxChatContentRunes+1)}}, false},
+		{"chat delete", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Action: models.ChatActionDelete, ID: "m1"}}, true},
+		{"chat edit without id", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Action: models.ChatActionEdit, Content: "hi"}}, false},
+		{"chat private to self", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Content: "hi", ToUserID: 7}}, false},
+		{"chat unknown action", models.WebSocketMessage{Type: models.MessageTypeChat, Payload: models.ChatMessage{Action: "pin", ID: "m1"}}, false},
+		{"media control", models.WebSocketMessage{Type: models.MessageTypeMediaControl, Payload: models.MediaControlMessage{Action: "mute", MediaType: "audio"}}, true},
+		{"media control bad media", models.WebSocketMessage{Type: models.MessageTypeMediaControl, Payload: models.MediaControlMessage{Action: "mute", MediaType: "smell"}}, false},
+		{"ai live claim", models.WebSocketMessage{Type: models.MessageTypeAILiveClaim}, true},
+		{"ai live claim wrong type", models.WebSocketMessage{Type: models.MessageTypeAILiveClaim, Payload: map[string]interface{}{"enable": "yes"}}, false},
+		{"ai live result without line", models.WebSocketMessage{Type: models.MessageTypeAILiveResult, Payload: models.AILiveResultMessage{Text: "hi"}}, false},
+		{"moderate media", models.WebSocketMessage{Type: models.MessageTypeModerateMedia, Payload: models.ModerateMediaMessage{TargetUserID: 2, MediaType: "video", Muted: true}}, true},
+		{"moderate media without target", models.WebSocketMessage{Type: models.MessageTypeModerateMedia, Payload: models.ModerateMediaMessage{MediaType: "video"}}, false},
+		{"screen share", models.WebSocketMessage{Type: models.MessageTypeScreenShare, Payload: models.ScreenShareMessage{Action: "start", ContentHint: "text"}}, true},
+		{"screen share bad action", models.WebSocketMessage{Type: models.MessageTypeScreenShare, Payload: models.ScreenShareMessage{Action: "pause"}}, false},
+		{"lobby admit all", models.WebSocketMessage{Type: models.MessageTypeLobbyAction, Payload: models.LobbyActionMessage{Action: models.LobbyActionAdmitAll}}, true},
+		{"lobby admit without user", models.WebSocketMessage{Type: models.MessageTypeLobbyAction, Payload: models.LobbyActionMessage{Action: models.LobbyActionAdmit}}, false},
+		{"host control mute all", models.WebSocketMessage{Type: models.MessageTypeHostControl, Payload: models.HostControlMessage{Action: models.HostControlMuteAll}}, true},
+		{"host control ban without target", models.WebSocketMessage{Type: models.MessageTypeHostControl, Payload: models.HostControlMessage{Action: models.HostControlBan}}, false},
+		{"raise hand", models.WebSocketMessage{Type: models.MessageTypeRaiseHand, Payload: models.RaiseHandMessage{Action: models.RaiseHandActionRaise}}, true},
+		{"reaction unsupported", models.WebSocketMessage{Type: models.MessageTypeReaction, Payload: models.ReactionMessage{Reaction: "rocket"}}, false},
+		{"feedback clear", models.WebSocketMessage{Type: models.MessageTypeFeedback, Payload: models.FeedbackMessage{}}, true},
+		{"host control promote to host", models.WebSocketMessage{Type: models.MessageTypeHostControl, Payload: models.HostControlMessage{Action: models.HostControlSetRole, TargetUserID: 2, Role: models.ParticipantRoleHost}}, false},
+		{"poll create", models.WebSocketMessage{Type: models.MessageTypePoll, Payload: models.PollMessage{Action: models.PollActionCreate, Question: "q", Options: []string{"a", "b"}}}, true},
+		{"poll vote without poll", models.WebSocketMessage{Type: models.MessageTypePoll, Payload: models.PollMessage{Action: models.PollActionVote, OptionIDs: []uint{1}}}, false},
+		{"question upvote without question", models.WebSocketMessage{Type: models.MessageTypeQuestion, Payload: models.QuestionMessage{Action: models.QuestionActionUpvote}}, false},
+		{"ping", models.WebSocketMessage{Type: models.MessageTypePing}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateMessagePayload(&tc.message, 7)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// policyViolationCount 读取策略违规计数器的当前值
+func policyViolationCount(t *testing.T, messageType models.MessageType, reason, action string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	want := map[string]string{"service": policyMetricsService, "message_type": messageType.String(), "reason": reason, "action": action}
+	for _, family := range families {
+		if family.GetName() != "signaling_message_policy_violations_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if want[label.GetName()] == label.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(want) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+const (
+	// minPollOptions/maxPollOptions 投票选项数量范围
+	minPollOptions = 2
+	maxPollOptions = 10
+	// maxPollQuestionRunes/maxPollOptionRunes 投票题目与选项的长度上限
+	maxPollQuestionRunes = 500
+	maxPollOptionRunes   = 200
+)
+
+// PollStore 投票的持久化（生产环境为 SignalingService）
+type PollStore interface {
+	CreatePoll(poll *models.Poll) error
+	GetPoll(pollID uint) (*models.Poll, error)
+	ClosePoll(pollID uint, closedAt time.Time) error
+	// ReplacePollVotes 以 optionIDs 覆盖用户之前的选择
+	ReplacePollVotes(pollID, userID uint, optionIDs []uint) error
+	ListPollVotes(pollID uint) ([]models.PollVote, error)
+}
+
+// handlePoll 投票：主办人/主持人创建与结束投票，参与者投票。
+// 实时公开的投票每次投票后向全员广播结果；隐藏结果的投票只发给主办人/主持人，结束时公开
+func (c *Client) handlePoll(message *models.WebSocketMessage) {
+	var req models.PollMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid poll request", err.Error())
+		return
+	}
+	if c.Handler.polls == nil {
+		c.sendErrorCode(503, "Poll unavailable", "poll storage unavailable")
+		return
+	}
+
+	switch req.Action {
+	case models.PollActionCreate:
+		c.createPoll(req)
+	case models.PollActionVote:
+		c.votePoll(req)
+	case models.PollActionClose:
+		c.closePoll(req)
+	default:
+		c.sendError("Invalid poll request", "action must be create, vote or close")
+	}
+}
+
+func (c *Client) createPoll(req models.PollMessage) {
+	if !c.canModerate() {
+		c.sendErrorCode(403, "Poll denied", "only host or moderator can create polls")
+		return
+	}
+	poll, err := newPoll(c.MeetingID(), c.UserID, req)
+	if err != nil {
+		c.sendError("Invalid poll", err.Error())
+		return
+	}
+
+	h := c.Handler
+	if err := h.polls.CreatePoll(poll); err != nil {
+		c.sendErrorCode(500, "Poll failed", err.Error())
+		return
+	}
+
+	logger.Info("Poll created",
+		logger.Uint("meeting_id", c.MeetingID()),
+		logger.Uint("poll_id", poll.ID),
+		logger.Uint("user_id", c.UserID))
+	h.broadcastToRoom(c.MeetingID(), pollMessage(c.MeetingID(), models.PollEventCreated, poll.State(nil), c.UserID), "")
+}
+
+func (c *Client) votePoll(req models.PollMessage) {
+	poll, ok := c.meetingPoll(req.PollID)
+	if !ok {
+		return
+	}
+	if !poll.IsOpen() {
+		c.sendErrorCode(409, "Vote failed", "poll is closed")
+		return
+	}
+	optionIDs, err := validatePollVote(poll, req.OptionIDs)
+	if err != nil {
+		c.sendError("Invalid vote", err.Error())
+		return
+	}
+
+	h := c.Handler
+	if err := h.polls.ReplacePollVotes(poll.ID, c.UserID, optionIDs); err != nil {
+		c.sendErrorCode(500, "Vote failed", err.Error())
+		return
+	}
+	h.publishPollResults(poll, models.PollEventResults, c.UserID)
+}
+
+func (c *Client) closePoll(req models.PollMessage) {
+	if !c.canModerate() {
+		c.sendErrorCode(403, "Poll denied", "only host or moderator can close polls")
+		return
+	}
+	poll, ok := c.meetingPoll(req.PollID)
+	if !ok {
+		return
+	}
+	if !poll.IsOpen() {
+		c.sendErrorCode(409, "Poll failed", "poll is already closed")
+		return
+	}
+
+	h := c.Handler
+	closedAt := time.Now()
+	if err := h.polls.ClosePoll(poll.ID, closedAt); err != nil {
+		c.sendErrorCode(500, "Poll failed", err.Error())
+		return
+	}
+	poll.Status = models.PollStatusClosed
+	poll.ClosedAt = &closedAt
+
+	logger.Info("Poll closed",
+		logger.Uint("meeting_id", c.MeetingID()),
+		logger.Uint("poll_id", poll.ID),
+		logger.Uint("user_id", c.UserID))
+	h.publishPollResults(poll, models.PollEventClosed, c.UserID)
+}
+
+// meetingPoll 查询本会议的投票，不存在时回复 404
+func (c *Client) meetingPoll(pollID uint) (*models.Poll, bool) {
+	if pollID == 0 {
+		c.sendError("Invalid poll request", "poll_id is required")
+		return nil, false
+	}
+	poll, err := c.Handler.polls.GetPoll(pollID)
+	if err != nil || poll == nil || poll.MeetingID != c.MeetingID() {
+		c.sendErrorCode(404, "Poll not found", fmt.Sprintf("poll %d not found", pollID))
+		return nil, false
+	}
+	return poll, true
+}
+
+// publishPollResults 汇总最新结果：对全员可见时广播到房间，否则只发给主办人/主持人
+func (h *WebSocketHandler) publishPollResults(poll *models.Poll, event string, byUserID uint) {
+	votes, err := h.polls.ListPollVotes(poll.ID)
+	if err != nil {
+		logger.Error("Failed to load poll votes", logger.Uint("poll_id", poll.ID), logger.Err(err))
+		return
+	}
+
+	message := pollMessage(poll.MeetingID, event, poll.State(votes), byUserID)
+	if poll.ResultsVisibleTo(false) {
+		h.broadcastToRoom(poll.MeetingID, message, "")
+		return
+	}
+	h.broadcastToModerators(poll.MeetingID, message)
+}
+
+func pollMessage(meetingID uint, event string, state models.PollState, byUserID uint) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		ID:         fmt.Sprintf("poll_%s_%d", event, time.Now().UnixNano()),
+		Type:       models.MessageTypePoll,
+		FromUserID: byUserID,
+		MeetingID:  meetingID,
+		Payload: models.PollEvent{
+			Event:     event,
+			Poll:      state,
+			ByUserID:  byUserID,
+			Timestamp: time.Now(),
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// newPoll 校验创建请求并构造投票（题目与选项去除首尾空白，选项不能重复）
+func newPoll(meetingID, creatorID uint, req models.PollMessage) (*models.Poll, error) {
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, errors.New("question is required")
+	}
+	if utf8.RuneCountInString(question) > maxPollQuestionRunes {
+		return nil, fmt.Errorf("question exceeds %d characters", maxPollQuestionRunes)
+	}
+	if len(req.Options) < minPollOptions || len(req.Options) > maxPollOptions {
+		return nil, fmt.Errorf("a poll needs %d to %d options", minPollOptions, maxPollOptions)
+	}
+
+	visibility := req.ResultsVisibility
+	switch visibility {
+	case "":
+		visibility = models.PollResultsLive
+	case models.PollResultsLive, models.PollResultsHidden:
+	default:
+		return nil, errors.New("results_visibility must be live or hidden")
+	}
+
+	options := make([]models.PollOption, 0, len(req.Options))
+	seen := make(map[string]bool, len(req.Options))
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, fmt.Errorf("option %d is empty", i+1)
+		}
+		if utf8.RuneCountInString(text) > maxPollOptionRunes {
+			return nil, fmt.Errorf("option %d exceeds %d characters", i+1, maxPollOptionRunes)
+		}
+		if seen[text] {
+			return nil, fmt.Errorf("duplicate option %q", text)
+		}
+		seen[text] = true
+		options = append(options, models.PollOption{Position: i, Text: text})
+	}
+
+	return &models.Poll{
+		MeetingID:         meetingID,
+		CreatorID:         creatorID,
+		Question:          question,
+		MultipleChoice:    req.MultipleChoice,
+		Anonymous:         req.Anonymous,
+		ResultsVisibility: visibility,
+		Status:            models.PollStatusOpen,
+		Options:           options,
+	}, nil
+}
+
+// validatePollVote 选项必须属于该投票且不重复，单选投票只能选一项
+func validatePollVote(poll *models.Poll, optionIDs []uint) ([]uint, error) {
+	if len(optionIDs) == 0 {
+		return nil, errors.New("option_ids is required")
+	}
+	if !poll.MultipleChoice && len(optionIDs) > 1 {
+		return nil, errors.New("only one option can be chosen")
+	}
+
+	valid := make(map[uint]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+	chosen := make(map[uint]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, fmt.Errorf("invalid option: %d", id)
+		}
+		if chosen[id] {
+			return nil, fmt.Errorf("duplicate option: %d", id)
+		}
+		chosen[id] = true
+	}
+	return optionIDs, nil
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+// memoryPolls 内存中的投票与选择
+type memoryPolls struct {
+	nextID uint
+	polls  map[uint]*models.Poll
+	votes  map[uint][]models.PollVote
+}
+
+func newMemoryPolls() *memoryPolls {
+	return &memoryPolls{polls: make(map[uint]*models.Poll), votes: make(map[uint][]models.PollVote)}
+}
+
+func (m *memoryPolls) CreatePoll(poll *models.Poll) error {
+	m.nextID++
+	poll.ID = m.nextID
+	for i := range poll.Options {
+		m.nextID++
+		poll.Options[i].ID = m.nextID
+		poll.Options[i].PollID = poll.ID
+	}
+	stored := *poll
+	m.polls[poll.ID] = &stored
+	return nil
+}
+
+func (m *memoryPolls) GetPoll(pollID uint) (*models.Poll, error) {
+	poll, ok := m.polls[pollID]
+	if !ok {
+		return nil, assert.AnError
+	}
+	copied := *poll
+	return &copied, nil
+}
+
+func (m *memoryPolls) ClosePoll(pollID uint, closedAt time.Time) error {
+	m.polls[pollID].Status = models.PollStatusClosed
+	m.polls[pollID].ClosedAt = &closedAt
+	return nil
+}
+
+func (m *memoryPolls) ReplacePollVotes(pollID, userID uint, optionIDs []uint) error {
+	kept := m.votes[pollID][:0]
+	for _, vote := range m.votes[pollID] {
+		if vote.UserID != userID {
+			kept = append(kept, vote)
+		}
+	}
+	for _, optionID := range optionIDs {
+		kept = append(kept, models.PollVote{PollID: pollID, UserID: userID, OptionID: optionID})
+	}
+	m.votes[pollID] = kept
+	return nil
+}
+
+func (m *memoryPolls) ListPollVotes(pollID uint) ([]models.PollVote, error) {
+	return append([]models.PollVote(nil), m.votes[pollID]...), nil
+}
+
+func pollRequest(req models.PollMessage) *models.WebSocketMessage {
+	return &models.WebSocketMessage{Type: models.MessageTypePoll, Payload: req}
+}
+
+func TestPoll_LiveResultsBroadcastToEveryone(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.polls = newMemoryPolls()
+
+	clients[7].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionCreate, Question: " 午饭吃什么？ ", Options: []string{"面", "饭"}}))
+	created := decodeEvents[models.PollEvent](t, mustDrain(t, clients[10]), models.MessageTypePoll)
+	require.Len(t, created, 1)
+	assert.Equal(t, models.PollEventCreated, created[0].Event)
+	assert.Equal(t, "午饭吃什么？", created[0].Poll.Question)
+	assert.Equal(t, models.PollResultsLive, created[0].Poll.ResultsVisibility)
+	poll := created[0].Poll
+	require.Len(t, poll.Options, 2)
+
+	clients[9].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionVote, PollID: poll.ID, OptionIDs: []uint{poll.Options[0].ID}}))
+	clients[9].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionVote, PollID: poll.ID, OptionIDs: []uint{poll.Options[1].ID}}))
+
+	results := decodeEvents[models.PollEvent](t, mustDrain(t, clients[10]), models.MessageTypePoll)
+	require.Len(t, results, 2)
+	latest := results[1].Poll
+	assert.Equal(t, models.PollEventResults, results[1].Event)
+	assert.False(t, latest.ResultsHidden)
+	assert.Equal(t, 1, latest.TotalVoters, "重复投票覆盖之前的选择")
+	assert.Equal(t, 0, latest.Options[0].Votes)
+	assert.Equal(t, 1, latest.Options[1].Votes)
+	assert.Equal(t, []uint{9}, latest.Options[1].VoterIDs, "实名投票列出投票人")
+
+	clients[9].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionVote, PollID: poll.ID, OptionIDs: []uint{poll.Options[0].ID, poll.Options[1].ID}}))
+	errs := drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 400, errs[0].Code, "单选投票只能选一项")
+}
+
+func TestPoll_HiddenResultsOnlyForModeratorsUntilClosed(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.polls = newMemoryPolls()
+
+	clients[8].handleMessage(pollRequest(models.PollMessage{
+		Action:            models.PollActionCreate,
+		Question:          "满意度",
+		Options:           []string{"满意", "一般", "不满意"},
+		MultipleChoice:    true,
+		Anonymous:         true,
+		ResultsVisibility: models.PollResultsHidden,
+	}))
+	for _, client := range clients {
+		drainAll(t, client)
+	}
+	poll := h.polls.(*memoryPolls).polls[1]
+	require.NotNil(t, poll)
+
+	clients[9].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionVote, PollID: poll.ID, OptionIDs: []uint{poll.Options[0].ID, poll.Options[1].ID}}))
+	assert.Empty(t, decodeEvents[models.PollEvent](t, mustDrain(t, clients[10]), models.MessageTypePoll), "投票期间参与者看不到结果")
+	hostView := decodeEvents[models.PollEvent](t, mustDrain(t, clients[7]), models.MessageTypePoll)
+	require.Len(t, hostView, 1)
+	assert.Equal(t, 1, hostView[0].Poll.Options[0].Votes)
+	assert.Empty(t, hostView[0].Poll.Options[0].VoterIDs, "匿名投票不列出投票人")
+
+	clients[9].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionClose, PollID: poll.ID}))
+	errs := drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+
+	clients[7].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionClose, PollID: poll.ID}))
+	closed := decodeEvents[models.PollEvent](t, mustDrain(t, clients[10]), models.MessageTypePoll)
+	require.Len(t, closed, 1)
+	assert.Equal(t, models.PollEventClosed, closed[0].Event)
+	assert.Equal(t, models.PollStatusClosed, closed[0].Poll.Status)
+	assert.Equal(t, []int{1, 1, 0}, []int{closed[0].Poll.Options[0].Votes, closed[0].Poll.Options[1].Votes, closed[0].Poll.Options[2].Votes})
+
+	clients[10].handleMessage(pollRequest(models.PollMessage{Action: models.PollActionVote, PollID: poll.ID, OptionIDs: []uint{poll.Options[2].ID}}))
+	errs = drainErrors(t, clients[10])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 409, errs[0].Code)
+}
+
+func TestNewPoll_Validation(t *testing.T) {
+	cases := []struct {
+		name    string
+		req     models.PollMessage
+		wantErr string
+	}{
+		{"valid", models.PollMessage{Question: "q", Options: []string{"a", "b"}}, ""},
+		{"no question", models.PollMessage{Question: " ", Options: []string{"a", "b"}}, "question is required"},
+		{"one option", models.PollMessage{Question: "q", Options: []string{"a"}}, "a poll needs 2 to 10 options"},
+		{"empty option", models.PollMessage{Question: "q", Options: []string{"a", " "}}, "option 2 is empty"},
+		{"duplicate option", models.PollMessage{Question: "q", Options: []string{"a", " a"}}, `duplicate option "a"`},
+		{"bad visibility", models.PollMessage{Question: "q", Options: []string{"a", "b"}, ResultsVisibility: "secret"}, "results_visibility must be live or hidden"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newPoll(1, 7, tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
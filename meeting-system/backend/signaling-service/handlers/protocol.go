@@ -0,0 +1,517 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	sharedgrpc "meeting-system/shared/grpc"
+	"meeting-system/shared/models"
+)
+
+// 信令子协议（Sec-WebSocket-Protocol）。未携带子协议的旧客户端按 JSON v1 处理
+const (
+	// SubprotocolJSONV1 JSON 文本帧（models.WebSocketMessage）
+	SubprotocolJSONV1 = "signaling.v1+json"
+	// SubprotocolProtobufV2 protobuf 二进制帧（grpc.SignalEnvelope）
+	SubprotocolProtobufV2 = "signaling.v2+protobuf"
+)
+
+// signalingSubprotocols 服务端支持的子协议，按优先级排列
+var signalingSubprotocols = []string{SubprotocolProtobufV2, SubprotocolJSONV1}
+
+// 服务内部（广播、重放缓冲、集群扇出）统一使用 JSON 编码的消息，
+// 仅在写出给 v2 客户端时转码为 protobuf，读取二进制帧时解码为 models.WebSocketMessage
+
+// encodeProtobufFrame 把内部 JSON 消息转码为 v2 二进制帧
+func encodeProtobufFrame(data []byte) ([]byte, error) {
+	var message models.WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("decode signaling message: %w", err)
+	}
+	envelope, err := messageToEnvelope(&message)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(envelope)
+}
+
+// decodeProtobufFrame 解析 v2 二进制帧，载荷为对应的 models 类型化结构
+func decodeProtobufFrame(data []byte) (*models.WebSocketMessage, error) {
+	var envelope sharedgrpc.SignalEnvelope
+	if err := proto.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("decode signaling frame: %w", err)
+	}
+	return envelopeToMessage(&envelope)
+}
+
+func messageToEnvelope(message *models.WebSocketMessage) (*sharedgrpc.SignalEnvelope, error) {
+	envelope := &sharedgrpc.SignalEnvelope{
+		Id:         message.ID,
+		Type:       sharedgrpc.SignalType(message.Type),
+		FromUserId: uint32(message.FromUserID),
+		MeetingId:  uint32(message.MeetingID),
+		SessionId:  message.SessionID,
+		PeerId:     message.PeerID,
+		Timestamp:  toTimestamp(message.Timestamp),
+		Seq:        message.Seq,
+	}
+	if message.ToUserID != nil {
+		to := uint32(*message.ToUserID)
+		envelope.ToUserId = &to
+	}
+	if message.Payload == nil {
+		return envelope, nil
+	}
+
+	raw, err := json.Marshal(message.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode signaling payload: %w", err)
+	}
+	if string(raw) == "null" {
+		return envelope, nil
+	}
+	if typed := typedEnvelopePayload(message.Type, raw); typed != nil {
+		envelope.Payload = typed.Payload
+		return envelope, nil
+	}
+	// 无对应类型化结构或含未知字段时按 JSON 透传，保证不丢字段
+	envelope.Payload = &sharedgrpc.SignalEnvelope_JsonPayload{JsonPayload: raw}
+	return envelope, nil
+}
+
+// strictDecode 仅当载荷字段全部属于目标结构时成功
+func strictDecode(raw []byte, out interface{}) bool {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out) == nil && !decoder.More()
+}
+
+// typedEnvelopePayload 按消息类型转换为类型化载荷（返回仅含 Payload 的封装），无法无损转换时返回 nil
+func typedEnvelopePayload(messageType models.MessageType, raw []byte) *sharedgrpc.SignalEnvelope {
+	switch messageType {
+	case models.MessageTypeOffer, models.MessageTypeAnswer:
+		var v models.WebRTCOffer
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_Sdp{Sdp: &sharedgrpc.SignalSessionDescription{Sdp: v.SDP, Type: v.Type}}}
+		}
+	case models.MessageTypeICECandidate:
+		var v models.ICECandidate
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_IceCandidate{IceCandidate: &sharedgrpc.SignalICECandidate{
+				Candidate: v.Candidate, SdpMid: v.SDPMid, SdpMlineIndex: int32(v.SDPMLineIndex),
+			}}}
+		}
+	case models.MessageTypeUserJoined, models.MessageTypeUserLeft:
+		var v models.UserJoinedNotification
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_Participant{Participant: &sharedgrpc.SignalParticipant{
+				UserId: uint32(v.UserID), Username: v.Username, PeerId: v.PeerID, MeetingId: uint32(v.MeetingID),
+			}}}
+		}
+	case models.MessageTypeChat:
+		var v models.ChatMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_Chat{Chat: &sharedgrpc.SignalChat{
+				Content: v.Content, UserId: uint32(v.UserID), Username: v.Username, MeetingId: uint32(v.MeetingID),
+				Id: v.ID, Action: v.Action, ToUserId: uint32(v.ToUserID), ReplyToId: v.ReplyToID,
+				CreatedAt: toTimestampPtr(v.CreatedAt), EditedAt: toTimestampPtr(v.EditedAt), Deleted: v.Deleted,
+			}}}
+		}
+	case models.MessageTypeScreenShare:
+		var event models.ScreenShareEvent
+		if strictDecode(raw, &event) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_ScreenShareEvent{ScreenShareEvent: screenShareEventToProto(event)}}
+		}
+		var req models.ScreenShareMessage
+		if strictDecode(raw, &req) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_ScreenShareRequest{ScreenShareRequest: &sharedgrpc.SignalScreenShareRequest{
+				Action: req.Action, StreamId: req.StreamID, TrackId: req.TrackID, ContentHint: req.ContentHint,
+			}}}
+		}
+	case models.MessageTypeMediaControl:
+		var v models.MediaControlMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_MediaControl{MediaControl: &sharedgrpc.SignalMediaControl{
+				Action: v.Action, MediaType: v.MediaType, UserId: uint32(v.UserID), PeerId: v.PeerID,
+			}}}
+		}
+	case models.MessageTypeError:
+		var v models.ErrorMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_Error{Error: &sharedgrpc.SignalError{
+				Code: int32(v.Code), Message: v.Message, Details: v.Details,
+			}}}
+		}
+	case models.MessageTypeRoomInfo:
+		var v models.RoomInfoMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_RoomInfo{RoomInfo: roomInfoToProto(v)}}
+		}
+	case models.MessageTypeAILiveClaim:
+		var v models.AILiveClaimRequest
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_AiLiveClaim{AiLiveClaim: &sharedgrpc.SignalAILiveClaim{Enable: v.Enable}}}
+		}
+	case models.MessageTypeAILiveStatus:
+		var v models.AILiveStatusMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_AiLiveStatus{AiLiveStatus: aiLiveStatusToProto(&v)}}
+		}
+	case models.MessageTypeAILiveResult:
+		var v models.AILiveResultMessage
+		if strictDecode(raw, &v) {
+			result := &sharedgrpc.SignalAILiveResult{
+				LineId: v.LineID, SpeakerKey: v.SpeakerKey, SpeakerLabel: v.SpeakerLabel,
+				TimestampMs: v.TimestampMs, Text: v.Text,
+			}
+			for _, tag := range v.Tags {
+				result.Tags = append(result.Tags, &sharedgrpc.SignalAILiveTag{Text: tag.Text, Kind: tag.Kind})
+			}
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_AiLiveResult{AiLiveResult: result}}
+		}
+	case models.MessageTypeICERestart:
+		var v models.ICERestartMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_IceRestart{IceRestart: &sharedgrpc.SignalICERestart{
+				PeerId: v.PeerID, RoomId: v.RoomID, Reason: v.Reason, GracePeriodMs: v.GracePeriodMs,
+			}}}
+		}
+	case models.MessageTypeModerateMedia:
+		var v models.ModerateMediaMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_ModerateMedia{ModerateMedia: &sharedgrpc.SignalModerateMedia{
+				TargetUserId: uint32(v.TargetUserID), MediaType: v.MediaType, Muted: v.Muted,
+			}}}
+		}
+	case models.MessageTypeMediaModerated:
+		var v models.MediaModerationState
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_MediaModeration{MediaModeration: moderationToProto(v)}}
+		}
+	case models.MessageTypeSessionResumed:
+		var v models.SessionResumedMessage
+		if strictDecode(raw, &v) {
+			return &sharedgrpc.SignalEnvelope{Payload: &sharedgrpc.SignalEnvelope_SessionResumed{SessionResumed: &sharedgrpc.SignalSessionResumed{
+				SessionId: v.SessionID, ResumeToken: v.ResumeToken, LastSeq: v.LastSeq, Replayed: int32(v.Replayed),
+			}}}
+		}
+	}
+	return nil
+}
+
+func envelopeToMessage(envelope *sharedgrpc.SignalEnvelope) (*models.WebSocketMessage, error) {
+	message := &models.WebSocketMessage{
+		ID:         envelope.GetId(),
+		Type:       models.MessageType(envelope.GetType()),
+		FromUserID: uint(envelope.GetFromUserId()),
+		MeetingID:  uint(envelope.GetMeetingId()),
+		SessionID:  envelope.GetSessionId(),
+		PeerID:     envelope.GetPeerId(),
+		Timestamp:  fromTimestamp(envelope.GetTimestamp()),
+		Seq:        envelope.GetSeq(),
+	}
+	if envelope.ToUserId != nil {
+		to := uint(envelope.GetToUserId())
+		message.ToUserID = &to
+	}
+
+	switch p := envelope.GetPayload().(type) {
+	case nil:
+	case *sharedgrpc.SignalEnvelope_Sdp:
+		message.Payload = models.WebRTCOffer{SDP: p.Sdp.GetSdp(), Type: p.Sdp.GetType()}
+	case *sharedgrpc.SignalEnvelope_IceCandidate:
+		message.Payload = models.ICECandidate{
+			Candidate: p.IceCandidate.GetCandidate(), SDPMid: p.IceCandidate.GetSdpMid(),
+			SDPMLineIndex: int(p.IceCandidate.GetSdpMlineIndex()),
+		}
+	case *sharedgrpc.SignalEnvelope_Chat:
+		message.Payload = models.ChatMessage{
+			Content: p.Chat.GetContent(), UserID: uint(p.Chat.GetUserId()),
+			Username: p.Chat.GetUsername(), MeetingID: uint(p.Chat.GetMeetingId()),
+			ID: p.Chat.GetId(), Action: p.Chat.GetAction(), ToUserID: uint(p.Chat.GetToUserId()), ReplyToID: p.Chat.GetReplyToId(),
+			CreatedAt: fromTimestampPtr(p.Chat.GetCreatedAt()), EditedAt: fromTimestampPtr(p.Chat.GetEditedAt()), Deleted: p.Chat.GetDeleted(),
+		}
+	case *sharedgrpc.SignalEnvelope_Participant:
+		message.Payload = models.UserJoinedNotification{
+			UserID: uint(p.Participant.GetUserId()), Username: p.Participant.GetUsername(),
+			PeerID: p.Participant.GetPeerId(), MeetingID: uint(p.Participant.GetMeetingId()),
+		}
+	case *sharedgrpc.SignalEnvelope_RoomInfo:
+		message.Payload = roomInfoFromProto(p.RoomInfo)
+	case *sharedgrpc.SignalEnvelope_Error:
+		message.Payload = models.ErrorMessage{
+			Code: int(p.Error.GetCode()), Message: p.Error.GetMessage(), Details: p.Error.GetDetails(),
+		}
+	case *sharedgrpc.SignalEnvelope_MediaControl:
+		message.Payload = models.MediaControlMessage{
+			Action: p.MediaControl.GetAction(), MediaType: p.MediaControl.GetMediaType(),
+			UserID: uint(p.MediaControl.GetUserId()), PeerID: p.MediaControl.GetPeerId(),
+		}
+	case *sharedgrpc.SignalEnvelope_AiLiveClaim:
+		message.Payload = models.AILiveClaimRequest{Enable: p.AiLiveClaim.GetEnable()}
+	case *sharedgrpc.SignalEnvelope_AiLiveStatus:
+		message.Payload = aiLiveStatusFromProto(p.AiLiveStatus)
+	case *sharedgrpc.SignalEnvelope_AiLiveResult:
+		result := models.AILiveResultMessage{
+			LineID: p.AiLiveResult.GetLineId(), SpeakerKey: p.AiLiveResult.GetSpeakerKey(),
+			SpeakerLabel: p.AiLiveResult.GetSpeakerLabel(), TimestampMs: p.AiLiveResult.GetTimestampMs(),
+			Text: p.AiLiveResult.GetText(),
+		}
+		for _, tag := range p.AiLiveResult.GetTags() {
+			result.Tags = append(result.Tags, models.AILiveTag{Text: tag.GetText(), Kind: tag.GetKind()})
+		}
+		message.Payload = result
+	case *sharedgrpc.SignalEnvelope_ModerateMedia:
+		message.Payload = models.ModerateMediaMessage{
+			TargetUserID: uint(p.ModerateMedia.GetTargetUserId()), MediaType: p.ModerateMedia.GetMediaType(),
+			Muted: p.ModerateMedia.GetMuted(),
+		}
+	case *sharedgrpc.SignalEnvelope_MediaModeration:
+		message.Payload = moderationFromProto(p.MediaModeration)
+	case *sharedgrpc.SignalEnvelope_ScreenShareRequest:
+		message.Payload = models.ScreenShareMessage{
+			Action: p.ScreenShareRequest.GetAction(), StreamID: p.ScreenShareRequest.GetStreamId(),
+			TrackID: p.ScreenShareRequest.GetTrackId(), ContentHint: p.ScreenShareRequest.GetContentHint(),
+		}
+	case *sharedgrpc.SignalEnvelope_ScreenShareEvent:
+		message.Payload = models.ScreenShareEvent{
+			Event:          p.ScreenShareEvent.GetEvent(),
+			Share:          screenShareStateFromProto(p.ScreenShareEvent.GetShare()),
+			PreviousUserID: uint(p.ScreenShareEvent.GetPreviousUserId()),
+			ByUserID:       uint(p.ScreenShareEvent.GetByUserId()),
+			Exclusive:      p.ScreenShareEvent.GetExclusive(),
+			Timestamp:      fromTimestamp(p.ScreenShareEvent.GetTimestamp()),
+		}
+	case *sharedgrpc.SignalEnvelope_IceRestart:
+		message.Payload = models.ICERestartMessage{
+			PeerID: p.IceRestart.GetPeerId(), RoomID: p.IceRestart.GetRoomId(),
+			Reason: p.IceRestart.GetReason(), GracePeriodMs: p.IceRestart.GetGracePeriodMs(),
+		}
+	case *sharedgrpc.SignalEnvelope_SessionResumed:
+		message.Payload = models.SessionResumedMessage{
+			SessionID: p.SessionResumed.GetSessionId(), ResumeToken: p.SessionResumed.GetResumeToken(),
+			LastSeq: p.SessionResumed.GetLastSeq(), Replayed: int(p.SessionResumed.GetReplayed()),
+		}
+	case *sharedgrpc.SignalEnvelope_JsonPayload:
+		var payload interface{}
+		if err := json.Unmarshal(p.JsonPayload, &payload); err != nil {
+			return nil, fmt.Errorf("decode json payload: %w", err)
+		}
+		message.Payload = payload
+	default:
+		return nil, fmt.Errorf("unsupported signaling payload %T", p)
+	}
+	return message, nil
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromTimestamp 超出 RFC 3339 范围的时间按未设置处理（否则无法再编码为 JSON）
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil || ts.CheckValid() != nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toTimestampPtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return toTimestamp(*t)
+}
+
+func fromTimestampPtr(ts *timestamppb.Timestamp) *time.Time {
+	t := fromTimestamp(ts)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func aiLiveStatusToProto(status *models.AILiveStatusMessage) *sharedgrpc.SignalAILiveStatus {
+	if status == nil {
+		return nil
+	}
+	return &sharedgrpc.SignalAILiveStatus{
+		Enabled:         status.Enabled,
+		LeaderUserId:    uint32(status.LeaderUserID),
+		LeaderSessionId: status.LeaderSessionID,
+		LeaderUsername:  status.LeaderUsername,
+		UpdatedAt:       toTimestamp(status.UpdatedAt),
+	}
+}
+
+func aiLiveStatusFromProto(status *sharedgrpc.SignalAILiveStatus) models.AILiveStatusMessage {
+	return models.AILiveStatusMessage{
+		Enabled:         status.GetEnabled(),
+		LeaderUserID:    uint(status.GetLeaderUserId()),
+		LeaderSessionID: status.GetLeaderSessionId(),
+		LeaderUsername:  status.GetLeaderUsername(),
+		UpdatedAt:       fromTimestamp(status.GetUpdatedAt()),
+	}
+}
+
+func moderationToProto(state models.MediaModerationState) *sharedgrpc.SignalMediaModeration {
+	return &sharedgrpc.SignalMediaModeration{
+		UserId:    uint32(state.UserID),
+		MediaType: state.MediaType,
+		Muted:     state.Muted,
+		ByUserId:  uint32(state.ByUserID),
+		UpdatedAt: toTimestamp(state.UpdatedAt),
+	}
+}
+
+func moderationFromProto(state *sharedgrpc.SignalMediaModeration) models.MediaModerationState {
+	return models.MediaModerationState{
+		UserID:    uint(state.GetUserId()),
+		MediaType: state.GetMediaType(),
+		Muted:     state.GetMuted(),
+		ByUserID:  uint(state.GetByUserId()),
+		UpdatedAt: fromTimestamp(state.GetUpdatedAt()),
+	}
+}
+
+func screenShareStateToProto(share models.ScreenShareState) *sharedgrpc.SignalScreenShareState {
+	return &sharedgrpc.SignalScreenShareState{
+		UserId:      uint32(share.UserID),
+		Username:    share.Username,
+		PeerId:      share.PeerID,
+		StreamId:    share.StreamID,
+		TrackId:     share.TrackID,
+		ContentHint: share.ContentHint,
+		StartedAt:   toTimestamp(share.StartedAt),
+	}
+}
+
+func screenShareStateFromProto(share *sharedgrpc.SignalScreenShareState) models.ScreenShareState {
+	return models.ScreenShareState{
+		UserID:      uint(share.GetUserId()),
+		Username:    share.GetUsername(),
+		PeerID:      share.GetPeerId(),
+		StreamID:    share.GetStreamId(),
+		TrackID:     share.GetTrackId(),
+		ContentHint: share.GetContentHint(),
+		StartedAt:   fromTimestamp(share.GetStartedAt()),
+	}
+}
+
+func screenShareEventToProto(event models.ScreenShareEvent) *sharedgrpc.SignalScreenShareEvent {
+	return &sharedgrpc.SignalScreenShareEvent{
+		Event:          event.Event,
+		Share:          screenShareStateToProto(event.Share),
+		PreviousUserId: uint32(event.PreviousUserID),
+		ByUserId:       uint32(event.ByUserID),
+		Exclusive:      event.Exclusive,
+		Timestamp:      toTimestamp(event.Timestamp),
+	}
+}
+
+func roomInfoToProto(info models.RoomInfoMessage) *sharedgrpc.SignalRoomInfo {
+	out := &sharedgrpc.SignalRoomInfo{
+		MeetingId:        uint32(info.MeetingID),
+		ParticipantCount: int32(info.ParticipantCount),
+		SessionId:        info.SessionID,
+		PeerId:           info.PeerID,
+		AiLive:           aiLiveStatusToProto(info.AILive),
+		ResumeToken:      info.ResumeToken,
+	}
+	for _, srv := range info.IceServers {
+		out.IceServers = append(out.IceServers, &sharedgrpc.SignalICEServer{
+			Urls: srv.URLs, Username: srv.Username, Credential: srv.Credential,
+		})
+	}
+	for _, p := range info.Participants {
+		out.Participants = append(out.Participants, &sharedgrpc.SignalRoomParticipant{
+			UserId:       uint32(p.UserID),
+			Username:     p.Username,
+			SessionId:    p.SessionID,
+			PeerId:       p.PeerID,
+			JoinedAt:     toTimestamp(p.JoinedAt),
+			LastActiveAt: toTimestamp(p.LastActiveAt),
+			IsSelf:       p.IsSelf,
+		})
+	}
+	for _, state := range info.Moderation {
+		out.Moderation = append(out.Moderation, moderationToProto(state))
+	}
+	for _, share := range info.ScreenShares {
+		out.ScreenShares = append(out.ScreenShares, screenShareStateToProto(share))
+	}
+	for _, hand := range info.RaisedHands {
+		out.RaisedHands = append(out.RaisedHands, &sharedgrpc.SignalRaisedHand{
+			UserId:   uint32(hand.UserID),
+			Username: hand.Username,
+			RaisedAt: toTimestamp(hand.RaisedAt),
+		})
+	}
+	for _, state := range info.Feedback {
+		out.Feedback = append(out.Feedback, &sharedgrpc.SignalFeedbackState{
+			UserId:    uint32(state.UserID),
+			Feedback:  state.Feedback,
+			UpdatedAt: toTimestamp(state.UpdatedAt),
+		})
+	}
+	return out
+}
+
+func roomInfoFromProto(info *sharedgrpc.SignalRoomInfo) models.RoomInfoMessage {
+	out := models.RoomInfoMessage{
+		MeetingID:        uint(info.GetMeetingId()),
+		ParticipantCount: int(info.GetParticipantCount()),
+		SessionID:        info.GetSessionId(),
+		PeerID:           info.GetPeerId(),
+		ResumeToken:      info.GetResumeToken(),
+	}
+	if info.GetAiLive() != nil {
+		status := aiLiveStatusFromProto(info.GetAiLive())
+		out.AILive = &status
+	}
+	for _, srv := range info.GetIceServers() {
+		out.IceServers = append(out.IceServers, models.RoomICEServer{
+			URLs: srv.GetUrls(), Username: srv.GetUsername(), Credential: srv.GetCredential(),
+		})
+	}
+	for _, p := range info.GetParticipants() {
+		out.Participants = append(out.Participants, models.RoomParticipant{
+			UserID:       uint(p.GetUserId()),
+			Username:     p.GetUsername(),
+			SessionID:    p.GetSessionId(),
+			PeerID:       p.GetPeerId(),
+			JoinedAt:     fromTimestamp(p.GetJoinedAt()),
+			LastActiveAt: fromTimestamp(p.GetLastActiveAt()),
+			IsSelf:       p.GetIsSelf(),
+		})
+	}
+	for _, state := range info.GetModeration() {
+		out.Moderation = append(out.Moderation, moderationFromProto(state))
+	}
+	for _, share := range info.GetScreenShares() {
+		out.ScreenShares = append(out.ScreenShares, screenShareStateFromProto(share))
+	}
+	for _, hand := range info.GetRaisedHands() {
+		out.RaisedHands = append(out.RaisedHands, models.RaisedHand{
+			UserID:   uint(hand.GetUserId()),
+			Username: hand.GetUsername(),
+			RaisedAt: fromTimestamp(hand.GetRaisedAt()),
+		})
+	}
+	for _, state := range info.GetFeedback() {
+		out.Feedback = append(out.Feedback, models.FeedbackState{
+			UserID:    uint(state.GetUserId()),
+			Feedback:  state.GetFeedback(),
+			UpdatedAt: fromTimestamp(state.GetUpdatedAt()),
+		})
+	}
+	return out
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"meeting-system/shared/config"
+	sharedgrpc "meeting-system/shared/grpc"
+	"meeting-system/shared/models"
+)
+
+var protocolTestTime = time.Date(2026, 3, 1, 8, 30, 0, 123000000, time.UTC)
+
+// protocolCompatCases 每种类型化载荷一例，payload 为 JSON v1 客户端看到的结构
+func protocolCompatCases() []models.WebSocketMessage {
+	to := uint(7)
+	status := &models.AILiveStatusMessage{Enabled: true, LeaderUserID: 3, LeaderSessionID: "s3", LeaderUsername: "carol", UpdatedAt: protocolTestTime}
+	share := models.ScreenShareState{UserID: 3, Username: "carol", PeerID: "p3", StreamID: "screen-1", TrackID: "t1", ContentHint: models.ScreenShareHintText, StartedAt: protocolTestTime}
+	moderation := models.MediaModerationState{UserID: 4, MediaType: models.ModerationMediaAudio, Muted: true, ByUserID: 1, UpdatedAt: protocolTestTime}
+
+	return []models.WebSocketMessage{
+		{Type: models.MessageTypeOffer, Payload: models.WebRTCOffer{SDP: "v=0\r\n", Type: "offer"}},
+		{Type: models.MessageTypeAnswer, ToUserID: &to, Payload: models.WebRTCAnswer{SDP: "v=0\r\n", Type: "answer"}},
+		{Type: models.MessageTypeICECandidate, Payload: models.ICECandidate{Candidate: "candidate:1 1 udp 1 10.0.0.1 5000 typ host", SDPMid: "0", SDPMLineIndex: 1}},
+		{Type: models.MessageTypeUserJoined, Payload: models.UserJoinedNotification{UserID: 2, Username: "bob", PeerID: "p2", MeetingID: 1}},
+		{Type: models.MessageTypeUserLeft, Payload: models.UserLeftNotification{UserID: 2, Username: "bob", PeerID: "p2", MeetingID: 1}},
+		{Type: models.MessageTypeChat, Payload: models.ChatMessage{Content: "你好", UserID: 2, Username: "bob", MeetingID: 1}},
+		{Type: models.MessageTypeChat, Payload: models.ChatMessage{
+			Content: "已编辑", UserID: 2, Username: "bob", MeetingID: 1, ID: "c1", Action: models.ChatActionEdit,
+			ToUserID: 3, ReplyToID: "c0", CreatedAt: &protocolTestTime, EditedAt: &protocolTestTime,
+		}},
+		{Type: models.MessageTypeChat, Payload: models.ChatMessage{UserID: 2, MeetingID: 1, ID: "c2", CreatedAt: &protocolTestTime, Deleted: true}},
+		{Type: models.MessageTypeScreenShare, Payload: models.ScreenShareMessage{Action: models.ScreenShareActionStart, StreamID: "screen-1", ContentHint: models.ScreenShareHintMotion}},
+		{Type: models.MessageTypeScreenShare, Payload: models.ScreenShareEvent{Event: models.ScreenShareEventTakeover, Share: share, PreviousUserID: 2, ByUserID: 3, Exclusive: true, Timestamp: protocolTestTime}},
+		{Type: models.MessageTypeMediaControl, Payload: models.MediaControlMessage{Action: "mute", MediaType: "audio", UserID: 2, PeerID: "p2"}},
+		{Type: models.MessageTypeError, Payload: models.ErrorMessage{Code: 403, Message: "forbidden", Details: "host only"}},
+		{Type: models.MessageTypeRoomInfo, Payload: models.RoomInfoMessage{
+			MeetingID: 1, ParticipantCount: 2, SessionID: "s1", PeerID: "p1",
+			IceServers: []models.RoomICEServer{{URLs: "stun:stun.example.com:3478"}, {URLs: "turn:turn.example.com", Username: "u", Credential: "c"}},
+			Participants: []models.RoomParticipant{
+				{UserID: 1, Username: "alice", SessionID: "s1", PeerID: "p1", JoinedAt: protocolTestTime, LastActiveAt: protocolTestTime, IsSelf: true},
+				{UserID: 2, Username: "bob", SessionID: "s2", PeerID: "p2", JoinedAt: protocolTestTime, LastActiveAt: protocolTestTime},
+			},
+			AILive:       status,
+			Moderation:   []models.MediaModerationState{moderation},
+			ScreenShares: []models.ScreenShareState{share},
+			RaisedHands:  []models.RaisedHand{{UserID: 2, Username: "bob", RaisedAt: protocolTestTime}},
+			Feedback:     []models.FeedbackState{{UserID: 2, Feedback: models.FeedbackSlower, UpdatedAt: protocolTestTime}},
+			ResumeToken:  "token",
+		}},
+		{Type: models.MessageTypeAILiveClaim, Payload: models.AILiveClaimRequest{Enable: true}},
+		{Type: models.MessageTypeAILiveStatus, Payload: *status},
+		{Type: models.MessageTypeAILiveResult, Payload: models.AILiveResultMessage{
+			LineID: "l1", SpeakerKey: "k", SpeakerLabel: "Bob", TimestampMs: 1234, Text: "hello",
+			Tags: []models.AILiveTag{{Text: "calm", Kind: "ok"}},
+		}},
+		{Type: models.MessageTypeICERestart, Payload: models.ICERestartMessage{PeerID: "p2", RoomID: "1", Reason: "failed", GracePeriodMs: 15000}},
+		{Type: models.MessageTypeModerateMedia, Payload: models.ModerateMediaMessage{TargetUserID: 4, MediaType: models.ModerationMediaVideo, Muted: true}},
+		{Type: models.MessageTypeMediaModerated, Payload: moderation},
+		{Type: models.MessageTypeSessionResumed, Payload: models.SessionResumedMessage{SessionID: "s1", ResumeToken: "token", LastSeq: 41, Replayed: 3}},
+		{Type: models.MessageTypePing},
+	}
+}
+
+func TestProtocol_TypedPayloadsRoundTrip(t *testing.T) {
+	for i, original := range protocolCompatCases() {
+		original.ID = "msg"
+		original.FromUserID = 1
+		original.MeetingID = 1
+		original.SessionID = "s1"
+		original.PeerID = "p1"
+		original.Timestamp = protocolTestTime
+		original.Seq = uint64(i + 1)
+
+		t.Run(original.Type.String(), func(t *testing.T) {
+			data, err := json.Marshal(&original)
+			require.NoError(t, err)
+			frame, err := encodeProtobufFrame(data)
+			require.NoError(t, err)
+
+			var envelope sharedgrpc.SignalEnvelope
+			require.NoError(t, proto.Unmarshal(frame, &envelope))
+			_, fallback := envelope.GetPayload().(*sharedgrpc.SignalEnvelope_JsonPayload)
+			assert.False(t, fallback, "已定义类型化结构的载荷不应回退为 JSON")
+
+			decoded, err := decodeProtobufFrame(frame)
+			require.NoError(t, err)
+			assert.Equal(t, original.ID, decoded.ID)
+			assert.Equal(t, original.Type, decoded.Type)
+			assert.Equal(t, original.ToUserID, decoded.ToUserID)
+			assert.Equal(t, original.SessionID, decoded.SessionID)
+			assert.Equal(t, original.Seq, decoded.Seq)
+			assert.True(t, original.Timestamp.Equal(decoded.Timestamp))
+
+			if original.Payload == nil {
+				assert.Nil(t, decoded.Payload)
+				return
+			}
+			// 按原载荷类型解码双方载荷后比较（JSON v1 客户端与 handler 都是这样读取的）
+			want := reflect.New(reflect.TypeOf(original.Payload))
+			got := reflect.New(reflect.TypeOf(original.Payload))
+			require.NoError(t, decodePayload(original.Payload, want.Interface()))
+			require.NoError(t, decodePayload(decoded.Payload, got.Interface()))
+			assert.Equal(t, want.Interface(), got.Interface())
+		})
+	}
+}
+
+func TestProtocol_UntypedPayloadsFallBackToJSON(t *testing.T) {
+	cases := []models.WebSocketMessage{
+		// 尚无类型化结构
+		{Type: models.MessageTypeAIStreamResult, Payload: models.AIStreamResultMessage{
+			ResultID: "s/1/asr/1", Kind: models.AIResultKindCaption, StreamID: "s", Sequence: 1,
+			Confidence: 0.9, Text: "hi", Data: json.RawMessage(`{"raw":true}`), TimestampMs: 10,
+		}},
+		// 新版客户端附加了 v2 结构中没有的字段，不能被丢弃
+		{Type: models.MessageTypeChat, Payload: map[string]interface{}{"content": "hi", "reply_to": "m1"}},
+		// 旧客户端的非对象载荷
+		{Type: models.MessageTypeChat, Payload: "plain text"},
+	}
+
+	for _, original := range cases {
+		data, err := json.Marshal(&original)
+		require.NoError(t, err)
+		frame, err := encodeProtobufFrame(data)
+		require.NoError(t, err)
+
+		var envelope sharedgrpc.SignalEnvelope
+		require.NoError(t, proto.Unmarshal(frame, &envelope))
+		require.NotNil(t, envelope.GetJsonPayload())
+
+		decoded, err := decodeProtobufFrame(frame)
+		require.NoError(t, err)
+		wantJSON, _ := json.Marshal(original.Payload)
+		gotJSON, _ := json.Marshal(decoded.Payload)
+		assert.JSONEq(t, string(wantJSON), string(gotJSON))
+	}
+}
+
+func TestProtocol_RejectsMalformedFrames(t *testing.T) {
+	_, err := decodeProtobufFrame([]byte{0xff, 0xff, 0xff})
+	assert.Error(t, err)
+
+	frame, err := proto.Marshal(&sharedgrpc.SignalEnvelope{
+		Type:    sharedgrpc.SignalType_SIGNAL_TYPE_CHAT,
+		Payload: &sharedgrpc.SignalEnvelope_JsonPayload{JsonPayload: []byte("{not json")},
+	})
+	require.NoError(t, err)
+	_, err = decodeProtobufFrame(frame)
+	assert.Error(t, err)
+
+	_, err = encodeProtobufFrame([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestProtocol_SignalTypesMirrorMessageTypes(t *testing.T) {
+	pairs := map[models.MessageType]sharedgrpc.SignalType{
+		models.MessageTypeOffer:          sharedgrpc.SignalType_SIGNAL_TYPE_OFFER,
+		models.MessageTypeICECandidate:   sharedgrpc.SignalType_SIGNAL_TYPE_ICE_CANDIDATE,
+		models.MessageTypeChat:           sharedgrpc.SignalType_SIGNAL_TYPE_CHAT,
+		models.MessageTypePing:           sharedgrpc.SignalType_SIGNAL_TYPE_PING,
+		models.MessageTypeRoomInfo:       sharedgrpc.SignalType_SIGNAL_TYPE_ROOM_INFO,
+		models.MessageTypeAILiveClaim:    sharedgrpc.SignalType_SIGNAL_TYPE_AI_LIVE_CLAIM,
+		models.MessageTypeICERestart:     sharedgrpc.SignalType_SIGNAL_TYPE_ICE_RESTART,
+		models.MessageTypeAIStreamResult: sharedgrpc.SignalType_SIGNAL_TYPE_AI_STREAM_RESULT,
+		models.MessageTypeMediaModerated: sharedgrpc.SignalType_SIGNAL_TYPE_MEDIA_MODERATED,
+		models.MessageTypeSessionResumed: sharedgrpc.SignalType_SIGNAL_TYPE_SESSION_RESUMED,
+		models.MessageTypeLobbyStatus:    sharedgrpc.SignalType_SIGNAL_TYPE_LOBBY_STATUS,
+		models.MessageTypeLobbyAction:    sharedgrpc.SignalType_SIGNAL_TYPE_LOBBY_ACTION,
+		models.MessageTypeHostControl:    sharedgrpc.SignalType_SIGNAL_TYPE_HOST_CONTROL,
+		models.MessageTypeRaiseHand:      sharedgrpc.SignalType_SIGNAL_TYPE_RAISE_HAND,
+		models.MessageTypeFeedback:       sharedgrpc.SignalType_SIGNAL_TYPE_FEEDBACK,
+		models.MessageTypeBreakout:       sharedgrpc.SignalType_SIGNAL_TYPE_BREAKOUT,
+		models.MessageTypePoll:           sharedgrpc.SignalType_SIGNAL_TYPE_POLL,
+		models.MessageTypeQuestion:       sharedgrpc.SignalType_SIGNAL_TYPE_QUESTION,
+		models.MessageTypeChatHistory:    sharedgrpc.SignalType_SIGNAL_TYPE_CHAT_HISTORY,
+	}
+	for messageType, signalType := range pairs {
+		assert.Equal(t, int32(messageType), int32(signalType), messageType.String())
+	}
+	// 新增消息类型时需同步 signaling.proto
+	assert.Len(t, sharedgrpc.SignalType_name, int(models.MessageTypeChatHistory)+1)
+}
+
+func TestProtocol_SubprotocolNegotiation(t *testing.T) {
+	if config.GlobalConfig == nil {
+		config.GlobalConfig = &config.Config{}
+	}
+	writeWait := config.GlobalConfig.WebSocket.WriteWait
+	config.GlobalConfig.WebSocket.WriteWait = 5
+	t.Cleanup(func() { config.GlobalConfig.WebSocket.WriteWait = writeWait })
+
+	upgrader := websocket.Upgrader{Subprotocols: signalingSubprotocols}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &Client{ID: "s1", Conn: conn, Binary: conn.Subprotocol() == SubprotocolProtobufV2}
+		data, _ := json.Marshal(&models.WebSocketMessage{
+			Type:    models.MessageTypeChat,
+			Payload: models.ChatMessage{Content: "hi", UserID: 1},
+		})
+		_ = client.writeBytes(data)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	cases := []struct {
+		name      string
+		requested []string
+		selected  string
+		frameType int
+	}{
+		{name: "protobuf", requested: []string{SubprotocolProtobufV2, SubprotocolJSONV1}, selected: SubprotocolProtobufV2, frameType: websocket.BinaryMessage},
+		{name: "json", requested: []string{SubprotocolJSONV1}, selected: SubprotocolJSONV1, frameType: websocket.TextMessage},
+		{name: "legacy", selected: "", frameType: websocket.TextMessage},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dialer := websocket.Dialer{Subprotocols: tc.requested}
+			conn, _, err := dialer.Dial(url, nil)
+			require.NoError(t, err)
+			defer conn.Close()
+			assert.Equal(t, tc.selected, conn.Subprotocol())
+
+			frameType, data, err := conn.ReadMessage()
+			require.NoError(t, err)
+			assert.Equal(t, tc.frameType, frameType)
+
+			var message *models.WebSocketMessage
+			if frameType == websocket.BinaryMessage {
+				message, err = decodeProtobufFrame(data)
+				require.NoError(t, err)
+			} else {
+				message = &models.WebSocketMessage{}
+				require.NoError(t, json.Unmarshal(data, message))
+			}
+			var chat models.ChatMessage
+			require.NoError(t, decodePayload(message.Payload, &chat))
+			assert.Equal(t, "hi", chat.Content)
+		})
+	}
+}
+
+func FuzzDecodeProtobufFrame(f *testing.F) {
+	for _, message := range protocolCompatCases() {
+		data, err := json.Marshal(&message)
+		require.NoError(f, err)
+		frame, err := encodeProtobufFrame(data)
+		require.NoError(f, err)
+		f.Add(frame)
+	}
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		message, err := decodeProtobufFrame(frame)
+		if err != nil {
+			return
+		}
+		// 能解码的帧必须能按 JSON v1 下发，并且再次转码后语义不变
+		data, err := json.Marshal(message)
+		require.NoError(t, err)
+		again, err := encodeProtobufFrame(data)
+		require.NoError(t, err)
+		decoded, err := decodeProtobufFrame(again)
+		require.NoError(t, err)
+		assert.Equal(t, message.Type, decoded.Type)
+		assert.Equal(t, message.Seq, decoded.Seq)
+		assert.True(t, message.Timestamp.Equal(decoded.Timestamp))
+	})
+}
+
+func FuzzEncodeJSONFrame(f *testing.F) {
+	for _, message := range protocolCompatCases() {
+		data, err := json.Marshal(&message)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	f.Add([]byte(`{"type":8,"payload":{"content":"hi","extra":1}}`))
+	f.Add([]byte(`{"type":14,"payload":null}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		frame, err := encodeProtobufFrame(data)
+		if err != nil {
+			return
+		}
+		_, err = decodeProtobufFrame(frame)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+const (
+	// maxQuestionRunes/maxAnswerRunes 问题与文字回答的长度上限
+	maxQuestionRunes = 1000
+	maxAnswerRunes   = 2000
+)
+
+// QuestionStore 问答的持久化（生产环境为 SignalingService）
+type QuestionStore interface {
+	CreateQuestion(question *models.MeetingQuestion) error
+	GetQuestion(questionID uint) (*models.MeetingQuestion, error)
+	UpdateQuestion(question *models.MeetingQuestion) error
+	// SetQuestionUpvote 点赞或取消点赞，返回最新点赞数
+	SetQuestionUpvote(questionID, userID uint, upvote bool) (int, error)
+}
+
+// handleQuestion 问答：参与者提问与点赞；主办人/主持人审核、标记已回答或忽略。
+// 会议开启问答审核时，参与者的问题审核通过前只有主办人/主持人与提问者可见
+func (c *Client) handleQuestion(message *models.WebSocketMessage) {
+	var req models.QuestionMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid question request", err.Error())
+		return
+	}
+	if c.Handler.questions == nil {
+		c.sendErrorCode(503, "Q&A unavailable", "question storage unavailable")
+		return
+	}
+
+	switch req.Action {
+	case models.QuestionActionAsk:
+		c.askQuestion(req)
+	case models.QuestionActionUpvote, models.QuestionActionUnvote:
+		c.upvoteQuestion(req)
+	case models.QuestionActionApprove, models.QuestionActionAnswer, models.QuestionActionDismiss:
+		c.moderateQuestion(req)
+	default:
+		c.sendError("Invalid question request", "action must be ask, upvote, unvote, approve, answer or dismiss")
+	}
+}
+
+func (c *Client) askQuestion(req models.QuestionMessage) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		c.sendError("Invalid question", "text is required")
+		return
+	}
+	if utf8.RuneCountInString(text) > maxQuestionRunes {
+		c.sendError("Invalid question", fmt.Sprintf("text exceeds %d characters", maxQuestionRunes))
+		return
+	}
+
+	h := c.Handler
+	question := &models.MeetingQuestion{
+		MeetingID: c.MeetingID(),
+		UserID:    c.UserID,
+		Username:  c.Username,
+		Text:      text,
+		Status:    models.QuestionStatusOpen,
+	}
+	if h.questionApproval(c.MeetingID()) && !c.canModerate() {
+		question.Status = models.QuestionStatusPending
+	}
+	if err := h.questions.CreateQuestion(question); err != nil {
+		c.sendErrorCode(500, "Question failed", err.Error())
+		return
+	}
+	h.publishQuestion(question, models.QuestionEventAsked, c.UserID, false)
+}
+
+func (c *Client) upvoteQuestion(req models.QuestionMessage) {
+	question, ok := c.meetingQuestion(req.QuestionID)
+	if !ok {
+		return
+	}
+	if question.Status != models.QuestionStatusOpen {
+		c.sendErrorCode(409, "Upvote failed", "question is not open")
+		return
+	}
+
+	h := c.Handler
+	upvotes, err := h.questions.SetQuestionUpvote(question.ID, c.UserID, req.Action == models.QuestionActionUpvote)
+	if err != nil {
+		c.sendErrorCode(500, "Upvote failed", err.Error())
+		return
+	}
+	if upvotes == question.Upvotes {
+		return
+	}
+	question.Upvotes = upvotes
+	h.publishQuestion(question, models.QuestionEventUpvoted, c.UserID, true)
+}
+
+func (c *Client) moderateQuestion(req models.QuestionMessage) {
+	if !c.canModerate() {
+		c.sendErrorCode(403, "Q&A denied", "only host or moderator can moderate questions")
+		return
+	}
+	question, ok := c.meetingQuestion(req.QuestionID)
+	if !ok {
+		return
+	}
+
+	wasPublic := question.VisibleTo(0, false)
+	event, err := applyQuestionAction(question, req, c.UserID, time.Now())
+	if err != nil {
+		c.sendErrorCode(409, "Q&A failed", err.Error())
+		return
+	}
+
+	h := c.Handler
+	if err := h.questions.UpdateQuestion(question); err != nil {
+		c.sendErrorCode(500, "Q&A failed", err.Error())
+		return
+	}
+	logger.Info("Question moderated",
+		logger.Uint("meeting_id", c.MeetingID()),
+		logger.Uint("question_id", question.ID),
+		logger.String("event", event),
+		logger.Uint("user_id", c.UserID))
+	h.publishQuestion(question, event, c.UserID, wasPublic)
+}
+
+// applyQuestionAction 审核/回答/忽略的状态流转：待审核 -> 待回答 -> 已回答，待审核或待回答 -> 已忽略
+func applyQuestionAction(question *models.MeetingQuestion, req models.QuestionMessage, byUserID uint, now time.Time) (string, error) {
+	switch req.Action {
+	case models.QuestionActionApprove:
+		if question.Status != models.QuestionStatusPending {
+			return "", errors.New("question is not pending")
+		}
+		question.Status = models.QuestionStatusOpen
+		return models.QuestionEventApproved, nil
+
+	case models.QuestionActionAnswer:
+		if question.Status != models.QuestionStatusPending && question.Status != models.QuestionStatusOpen {
+			return "", fmt.Errorf("question is already %s", question.Status)
+		}
+		answer := strings.TrimSpace(req.Answer)
+		if utf8.RuneCountInString(answer) > maxAnswerRunes {
+			return "", fmt.Errorf("answer exceeds %d characters", maxAnswerRunes)
+		}
+		question.Status = models.QuestionStatusAnswered
+		question.Answer = answer
+		question.AnsweredBy = &byUserID
+		question.AnsweredAt = &now
+		return models.QuestionEventAnswered, nil
+
+	case models.QuestionActionDismiss:
+		if question.Status != models.QuestionStatusPending && question.Status != models.QuestionStatusOpen {
+			return "", fmt.Errorf("question is already %s", question.Status)
+		}
+		question.Status = models.QuestionStatusDismissed
+		return models.QuestionEventDismissed, nil
+	}
+	return "", fmt.Errorf("unsupported action %q", req.Action)
+}
+
+// meetingQuestion 查询本会议的问题，不存在时回复 404
+func (c *Client) meetingQuestion(questionID uint) (*models.MeetingQuestion, bool) {
+	if questionID == 0 {
+		c.sendError("Invalid question request", "question_id is required")
+		return nil, false
+	}
+	question, err := c.Handler.questions.GetQuestion(questionID)
+	if err != nil || question == nil || question.MeetingID != c.MeetingID() {
+		c.sendErrorCode(404, "Question not found", fmt.Sprintf("question %d not found", questionID))
+		return nil, false
+	}
+	return question, true
+}
+
+// questionApproval 会议是否开启问答审核
+func (h *WebSocketHandler) questionApproval(meetingID uint) bool {
+	if h.settings == nil {
+		return false
+	}
+	settings, err := h.settings.GetMeetingSettings(meetingID)
+	return err == nil && settings != nil && settings.QAApproval
+}
+
+// publishQuestion 变化前后任一时刻对全员可见则广播到房间，否则只发给主办人/主持人与提问者
+func (h *WebSocketHandler) publishQuestion(question *models.MeetingQuestion, event string, byUserID uint, wasPublic bool) {
+	message := &models.WebSocketMessage{
+		ID:         fmt.Sprintf("question_%s_%d", event, time.Now().UnixNano()),
+		Type:       models.MessageTypeQuestion,
+		FromUserID: byUserID,
+		MeetingID:  question.MeetingID,
+		Payload: models.QuestionEvent{
+			Event:     event,
+			Question:  *question,
+			ByUserID:  byUserID,
+			Timestamp: time.Now(),
+		},
+		Timestamp: time.Now(),
+	}
+
+	if wasPublic || question.VisibleTo(0, false) {
+		h.broadcastToRoom(question.MeetingID, message, "")
+		return
+	}
+	h.broadcastToModerators(question.MeetingID, message)
+	if !h.isModerator(question.UserID, question.MeetingID) {
+		h.forwardToUser(question.UserID, message)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+// memoryQuestions 内存中的问答
+type memoryQuestions struct {
+	nextID    uint
+	questions map[uint]*models.MeetingQuestion
+	upvotes   map[uint]map[uint]bool
+}
+
+func newMemoryQuestions() *memoryQuestions {
+	return &memoryQuestions{questions: make(map[uint]*models.MeetingQuestion), upvotes: make(map[uint]map[uint]bool)}
+}
+
+func (m *memoryQuestions) CreateQuestion(question *models.MeetingQuestion) error {
+	m.nextID++
+	question.ID = m.nextID
+	stored := *question
+	m.questions[question.ID] = &stored
+	return nil
+}
+
+func (m *memoryQuestions) GetQuestion(questionID uint) (*models.MeetingQuestion, error) {
+	question, ok := m.questions[questionID]
+	if !ok {
+		return nil, assert.AnError
+	}
+	copied := *question
+	return &copied, nil
+}
+
+func (m *memoryQuestions) UpdateQuestion(question *models.MeetingQuestion) error {
+	stored := *question
+	m.questions[question.ID] = &stored
+	return nil
+}
+
+func (m *memoryQuestions) SetQuestionUpvote(questionID, userID uint, upvote bool) (int, error) {
+	if m.upvotes[questionID] == nil {
+		m.upvotes[questionID] = make(map[uint]bool)
+	}
+	if upvote {
+		m.upvotes[questionID][userID] = true
+	} else {
+		delete(m.upvotes[questionID], userID)
+	}
+	m.questions[questionID].Upvotes = len(m.upvotes[questionID])
+	return len(m.upvotes[questionID]), nil
+}
+
+func questionRequest(req models.QuestionMessage) *models.WebSocketMessage {
+	return &models.WebSocketMessage{Type: models.MessageTypeQuestion, Payload: req}
+}
+
+func TestQuestion_ApprovalKeepsPendingQuestionsPrivate(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.questions = newMemoryQuestions()
+	h.settings = staticSettings{QAApproval: true}
+
+	clients[9].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionAsk, Text: "下次会议什么时候？"}))
+	for _, userID := range []uint{7, 8, 9} {
+		events := decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[userID]), models.MessageTypeQuestion)
+		require.Len(t, events, 1, "user %d", userID)
+		assert.Equal(t, models.QuestionEventAsked, events[0].Event)
+		assert.Equal(t, models.QuestionStatusPending, events[0].Question.Status)
+	}
+	assert.Empty(t, decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[10]), models.MessageTypeQuestion), "待审核的问题对其他参与者不可见")
+
+	clients[10].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionUpvote, QuestionID: 1}))
+	errs := drainErrors(t, clients[10])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 409, errs[0].Code)
+
+	clients[8].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionApprove, QuestionID: 1}))
+	approved := decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[10]), models.MessageTypeQuestion)
+	require.Len(t, approved, 1)
+	assert.Equal(t, models.QuestionEventApproved, approved[0].Event)
+	assert.Equal(t, models.QuestionStatusOpen, approved[0].Question.Status)
+	mustDrain(t, clients[9])
+
+	clients[10].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionUpvote, QuestionID: 1}))
+	clients[10].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionUpvote, QuestionID: 1}))
+	upvoted := decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[9]), models.MessageTypeQuestion)
+	require.Len(t, upvoted, 1, "重复点赞不再广播")
+	assert.Equal(t, 1, upvoted[0].Question.Upvotes)
+
+	clients[7].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionAsk, Text: "主持人提问"}))
+	hostQuestion := decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[10]), models.MessageTypeQuestion)
+	require.NotEmpty(t, hostQuestion)
+	last := hostQuestion[len(hostQuestion)-1]
+	assert.Equal(t, models.QuestionEventAsked, last.Event)
+	assert.Equal(t, models.QuestionStatusOpen, last.Question.Status, "主办人的问题无需审核")
+}
+
+func TestQuestion_AnswerAndDismiss(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	h.questions = newMemoryQuestions()
+
+	clients[9].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionAsk, Text: "能分享幻灯片吗？"}))
+	asked := decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[10]), models.MessageTypeQuestion)
+	require.Len(t, asked, 1)
+	assert.Equal(t, models.QuestionStatusOpen, asked[0].Question.Status)
+
+	clients[9].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionAnswer, QuestionID: 1, Answer: "可以"}))
+	errs := drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+
+	clients[7].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionAnswer, QuestionID: 1, Answer: " 会后发到群里 "}))
+	answered := decodeEvents[models.QuestionEvent](t, mustDrain(t, clients[10]), models.MessageTypeQuestion)
+	require.Len(t, answered, 1)
+	assert.Equal(t, models.QuestionEventAnswered, answered[0].Event)
+	assert.Equal(t, "会后发到群里", answered[0].Question.Answer)
+	require.NotNil(t, answered[0].Question.AnsweredBy)
+	assert.Equal(t, uint(7), *answered[0].Question.AnsweredBy)
+
+	clients[7].handleMessage(questionRequest(models.QuestionMessage{Action: models.QuestionActionDismiss, QuestionID: 1}))
+	errs = drainErrors(t, clients[7])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 409, errs[0].Code, "已回答的问题不能再忽略")
+}
+
+func TestApplyQuestionAction_Transitions(t *testing.T) {
+	cases := []struct {
+		status    string
+		action    string
+		wantEvent string
+		wantErr   bool
+	}{
+		{models.QuestionStatusPending, models.QuestionActionApprove, models.QuestionEventApproved, false},
+		{models.QuestionStatusOpen, models.QuestionActionApprove, "", true},
+		{models.QuestionStatusPending, models.QuestionActionAnswer, models.QuestionEventAnswered, false},
+		{models.QuestionStatusOpen, models.QuestionActionDismiss, models.QuestionEventDismissed, false},
+		{models.QuestionStatusDismissed, models.QuestionActionAnswer, "", true},
+		{models.QuestionStatusAnswered, models.QuestionActionDismiss, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.status+"_"+tc.action, func(t *testing.T) {
+			question := &models.MeetingQuestion{Status: tc.status}
+			event, err := applyQuestionAction(question, models.QuestionMessage{Action: tc.action}, 7, time.Now())
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tc.status, question.Status, "失败时不修改状态")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantEvent, event)
+		})
+	}
+}
@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+const (
+	// reactionAggregateThreshold 房间内连接数超过该值（或集群模式）时按时间窗聚合表情反应
+	reactionAggregateThreshold = 20
+	// reactionFlushInterval 表情反应的聚合时间窗
+	reactionFlushInterval = 500 * time.Millisecond
+	// reactionUserSample 聚合结果中最多列出的发送者数量
+	reactionUserSample = 10
+)
+
+// raisedHand 举手队列中的一项，sessionID 为举手的连接（断开时自动放下）
+type raisedHand struct {
+	models.RaisedHand
+	sessionID string
+}
+
+// activeFeedback 参与者当前的非语言反馈，sessionID 为设置反馈的连接（断开时自动清除）
+type activeFeedback struct {
+	models.FeedbackState
+	sessionID string
+}
+
+// reactionBatch 一个时间窗内待广播的表情反应
+type reactionBatch struct {
+	counts map[string]*models.ReactionCount
+}
+
+// handleRaiseHand 举手、放下自己的手；主办人/主持人可以放下他人的手或全部放下。
+// 队列按举手先后保存在房间内（集群模式保存在 Redis），每次变化广播完整队列
+func (c *Client) handleRaiseHand(message *models.WebSocketMessage) {
+	var req models.RaiseHandMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid raise hand request", err.Error())
+		return
+	}
+
+	h := c.Handler
+	h.mutex.RLock()
+	room := h.rooms[c.MeetingID()]
+	h.mutex.RUnlock()
+	if room == nil {
+		c.sendError("Room not found", "meeting room not available")
+		return
+	}
+
+	switch req.Action {
+	case models.RaiseHandActionRaise:
+		raised, queue, err := h.raiseHand(room, c)
+		if err != nil {
+			logger.Error("Failed to raise hand", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+			c.sendError("Raise hand unavailable", "failed to coordinate raised hands")
+			return
+		}
+		if raised {
+			h.broadcastHandQueue(c.MeetingID(), models.HandEventRaised, c.UserID, c.UserID, queue)
+		}
+
+	case models.RaiseHandActionLower:
+		target := req.UserID
+		if target == 0 {
+			target = c.UserID
+		}
+		if target != c.UserID && !c.canModerate() {
+			c.sendErrorCode(403, "Raise hand denied", "only host or moderator can lower other hands")
+			return
+		}
+		lowered, queue, err := h.lowerHand(room, c.MeetingID(), target, "")
+		if err != nil {
+			logger.Error("Failed to lower hand", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+			c.sendError("Raise hand unavailable", "failed to coordinate raised hands")
+			return
+		}
+		if !lowered {
+			if target != c.UserID {
+				c.sendErrorCode(404, "Raise hand failed", "hand is not raised")
+			}
+			return
+		}
+		h.broadcastHandQueue(c.MeetingID(), models.HandEventLowered, target, c.UserID, queue)
+
+	case models.RaiseHandActionLowerAll:
+		if !c.canModerate() {
+			c.sendErrorCode(403, "Raise hand denied", "only host or moderator can lower all hands")
+			return
+		}
+		lowered, err := h.lowerAllHands(room, c.MeetingID())
+		if err != nil {
+			logger.Error("Failed to lower all hands", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+			c.sendError("Raise hand unavailable", "failed to coordinate raised hands")
+			return
+		}
+		if lowered {
+			h.broadcastHandQueue(c.MeetingID(), models.HandEventLoweredAll, 0, c.UserID, []models.RaisedHand{})
+		}
+
+	default:
+		c.sendError("Invalid raise hand request", "action must be raise, lower or lower_all")
+	}
+}
+
+// raiseHand 把用户加入举手队列末尾，返回是否新举手与最新队列
+func (h *WebSocketHandler) raiseHand(room *Room, c *Client) (bool, []models.RaisedHand, error) {
+	if h.cluster != nil {
+		hand := models.RaisedHand{UserID: c.UserID, Username: c.Username, RaisedAt: time.Now()}
+		raised, err := h.cluster.raiseHand(c.MeetingID(), hand, c.ID)
+		if err != nil || !raised {
+			return false, nil, err
+		}
+		queue, err := h.cluster.handQueue(c.MeetingID())
+		return err == nil, queue, err
+	}
+
+	room.mutex.Lock()
+	defer room.mutex.Unlock()
+	raised := room.raiseHandLocked(c)
+	return raised, room.handQueueLocked(), nil
+}
+
+// lowerHand 把用户移出举手队列，返回是否放下与最新队列；sessionID 非空时仅当由该会话举手（集群模式连接断开）
+func (h *WebSocketHandler) lowerHand(room *Room, meetingID, userID uint, sessionID string) (bool, []models.RaisedHand, error) {
+	if h.cluster != nil {
+		lowered, err := h.cluster.lowerHand(meetingID, userID, sessionID)
+		if err != nil || !lowered {
+			return false, nil, err
+		}
+		queue, err := h.cluster.handQueue(meetingID)
+		return err == nil, queue, err
+	}
+
+	room.mutex.Lock()
+	defer room.mutex.Unlock()
+	lowered := room.lowerHandLocked(userID)
+	return lowered, room.handQueueLocked(), nil
+}
+
+// lowerAllHands 清空举手队列；队列原本为空时返回 false
+func (h *WebSocketHandler) lowerAllHands(room *Room, meetingID uint) (bool, error) {
+	if h.cluster != nil {
+		return h.cluster.lowerAllHands(meetingID)
+	}
+
+	room.mutex.Lock()
+	defer room.mutex.Unlock()
+	lowered := len(room.hands) > 0
+	room.hands = nil
+	return lowered, nil
+}
+
+// canModerate 连接所属用户是否为主办人/主持人
+func (c *Client) canModerate() bool {
+	return c.Handler.isModerator(c.UserID, c.MeetingID())
+}
+
+// isModerator 用户是否为会议的主办人/主持人
+func (h *WebSocketHandler) isModerator(userID, meetingID uint) bool {
+	if h.roles == nil {
+		return false
+	}
+	role, err := h.roles.GetParticipantRole(userID, meetingID)
+	return err == nil && role.CanModerate()
+}
+
+// raiseHandLocked 把用户加入举手队列末尾；已举手时返回 false（调用方持有 room.mutex）
+func (r *Room) raiseHandLocked(c *Client) bool {
+	for _, hand := range r.hands {
+		if hand.UserID == c.UserID {
+			return false
+		}
+	}
+	r.hands = append(r.hands, &raisedHand{
+		RaisedHand: models.RaisedHand{UserID: c.UserID, Username: c.Username, RaisedAt: time.Now()},
+		sessionID:  c.ID,
+	})
+	return true
+}
+
+// lowerHandLocked 把用户移出举手队列（调用方持有 room.mutex）
+func (r *Room) lowerHandLocked(userID uint) bool {
+	for i, hand := range r.hands {
+		if hand.UserID == userID {
+			r.hands = append(r.hands[:i], r.hands[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// releaseHandLocked 连接断开时放下该连接举起的手（调用方持有 room.mutex）
+func (r *Room) releaseHandLocked(sessionID string) (uint, bool) {
+	for _, hand := range r.hands {
+		if hand.sessionID == sessionID {
+			r.lowerHandLocked(hand.UserID)
+			return hand.UserID, true
+		}
+	}
+	return 0, false
+}
+
+// handQueueLocked 举手队列快照（调用方持有 room.mutex）
+func (r *Room) handQueueLocked() []models.RaisedHand {
+	queue := make([]models.RaisedHand, 0, len(r.hands))
+	for _, hand := range r.hands {
+		queue = append(queue, hand.RaisedHand)
+	}
+	return queue
+}
+
+// sortHandQueue 按举手先后排序（同一时刻按用户）
+func sortHandQueue(queue []models.RaisedHand) {
+	sort.Slice(queue, func(i, j int) bool {
+		if !queue[i].RaisedAt.Equal(queue[j].RaisedAt) {
+			return queue[i].RaisedAt.Before(queue[j].RaisedAt)
+		}
+		return queue[i].UserID < queue[j].UserID
+	})
+}
+
+// getRaisedHands 房间内的举手队列，供新加入者同步；集群模式以 Redis 为准
+func (h *WebSocketHandler) getRaisedHands(meetingID uint) []models.RaisedHand {
+	if h.cluster != nil {
+		queue, err := h.cluster.handQueue(meetingID)
+		if err != nil {
+			logger.Error("Failed to load cluster raised hands", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+		if len(queue) == 0 {
+			return nil
+		}
+		return queue
+	}
+
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return nil
+	}
+
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+	if len(room.hands) == 0 {
+		return nil
+	}
+	return room.handQueueLocked()
+}
+
+func (h *WebSocketHandler) broadcastHandQueue(meetingID uint, event string, userID, byUserID uint, queue []models.RaisedHand) {
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("raise_hand_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeRaiseHand,
+		FromUserID: byUserID,
+		MeetingID:  meetingID,
+		Payload: models.RaiseHandEvent{
+			Event:     event,
+			UserID:    userID,
+			ByUserID:  byUserID,
+			Queue:     queue,
+			Timestamp: time.Now(),
+		},
+		Timestamp: time.Now(),
+	}, "")
+}
+
+// handleFeedback 设置或清除非语言反馈，状态保存在房间内并广播
+func (c *Client) handleFeedback(message *models.WebSocketMessage) {
+	var req models.FeedbackMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid feedback", err.Error())
+		return
+	}
+	if !models.ValidFeedback(req.Feedback) {
+		c.sendError("Invalid feedback", "feedback must be yes, no, slower, faster or empty")
+		return
+	}
+
+	h := c.Handler
+	h.mutex.RLock()
+	room := h.rooms[c.MeetingID()]
+	h.mutex.RUnlock()
+	if room == nil {
+		c.sendError("Room not found", "meeting room not available")
+		return
+	}
+
+	state := models.FeedbackState{UserID: c.UserID, Feedback: req.Feedback, UpdatedAt: time.Now()}
+	changed, err := h.setFeedback(room, c, state)
+	if err != nil {
+		logger.Error("Failed to set feedback", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+		c.sendError("Feedback unavailable", "failed to coordinate feedback")
+		return
+	}
+	if changed {
+		h.broadcastFeedback(c.MeetingID(), state)
+	}
+}
+
+// setFeedback 设置或清除连接所属用户的反馈，返回反馈是否变化
+func (h *WebSocketHandler) setFeedback(room *Room, c *Client, state models.FeedbackState) (bool, error) {
+	if h.cluster != nil {
+		return h.cluster.setFeedback(c.MeetingID(), state, c.ID)
+	}
+
+	room.mutex.Lock()
+	defer room.mutex.Unlock()
+	current, exists := room.feedback[c.UserID]
+	changed := (exists && current.Feedback != state.Feedback) || (!exists && state.Feedback != "")
+	if state.Feedback == "" {
+		delete(room.feedback, c.UserID)
+	} else {
+		if room.feedback == nil {
+			room.feedback = make(map[uint]*activeFeedback)
+		}
+		room.feedback[c.UserID] = &activeFeedback{FeedbackState: state, sessionID: c.ID}
+	}
+	return changed, nil
+}
+
+// releaseFeedbackLocked 连接断开时清除该连接设置的反馈（调用方持有 room.mutex）
+func (r *Room) releaseFeedbackLocked(sessionID string) (uint, bool) {
+	for userID, feedback := range r.feedback {
+		if feedback.sessionID == sessionID {
+			delete(r.feedback, userID)
+			return userID, true
+		}
+	}
+	return 0, false
+}
+
+// getFeedback 房间内参与者当前的反馈（按用户排序）；集群模式以 Redis 为准
+func (h *WebSocketHandler) getFeedback(meetingID uint) []models.FeedbackState {
+	var states []models.FeedbackState
+	if h.cluster != nil {
+		var err error
+		if states, err = h.cluster.feedbackStates(meetingID); err != nil {
+			logger.Error("Failed to load cluster feedback", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+	} else {
+		h.mutex.RLock()
+		room := h.rooms[meetingID]
+		h.mutex.RUnlock()
+		if room == nil {
+			return nil
+		}
+
+		room.mutex.RLock()
+		states = make([]models.FeedbackState, 0, len(room.feedback))
+		for _, feedback := range room.feedback {
+			states = append(states, feedback.FeedbackState)
+		}
+		room.mutex.RUnlock()
+	}
+	if len(states) == 0 {
+		return nil
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].UserID < states[j].UserID })
+	return states
+}
+
+func (h *WebSocketHandler) broadcastFeedback(meetingID uint, state models.FeedbackState) {
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("feedback_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeFeedback,
+		FromUserID: state.UserID,
+		MeetingID:  meetingID,
+		Payload:    state,
+		Timestamp:  time.Now(),
+	}, "")
+}
+
+// releaseNonverbalOnLeave 连接断开后放下其举起的手、清除其反馈，并通知房间内其他人
+func (h *WebSocketHandler) releaseNonverbalOnLeave(meetingID uint, handUserID uint, handLowered bool, feedbackUserID uint, feedbackCleared bool) {
+	if handLowered {
+		queue := h.getRaisedHands(meetingID)
+		if queue == nil {
+			queue = []models.RaisedHand{}
+		}
+		h.broadcastHandQueue(meetingID, models.HandEventLowered, handUserID, handUserID, queue)
+	}
+	if feedbackCleared {
+		h.broadcastFeedback(meetingID, models.FeedbackState{UserID: feedbackUserID, UpdatedAt: time.Now()})
+	}
+}
+
+// handleReaction 表情反应：小房间立即广播；大房间（或集群模式）在时间窗内按反应类型聚合，
+// 每个时间窗每个节点只广播一次
+func (c *Client) handleReaction(message *models.WebSocketMessage) {
+	var req models.ReactionMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid reaction", err.Error())
+		return
+	}
+	if !models.ValidReaction(req.Reaction) {
+		c.sendError("Invalid reaction", fmt.Sprintf("unsupported reaction %q", req.Reaction))
+		return
+	}
+
+	h := c.Handler
+	h.mutex.RLock()
+	room := h.rooms[c.MeetingID()]
+	h.mutex.RUnlock()
+	if room == nil {
+		c.sendError("Room not found", "meeting room not available")
+		return
+	}
+
+	room.mutex.Lock()
+	if h.cluster == nil && len(room.Clients) <= reactionAggregateThreshold && room.reactions == nil {
+		room.mutex.Unlock()
+		h.broadcastReactions(c.MeetingID(), []models.ReactionCount{
+			{Reaction: req.Reaction, Count: 1, UserIDs: []uint{c.UserID}},
+		}, false)
+		return
+	}
+
+	if room.reactions == nil {
+		room.reactions = &reactionBatch{counts: make(map[string]*models.ReactionCount)}
+		meetingID := c.MeetingID()
+		time.AfterFunc(h.reactionFlushInterval(), func() { h.flushReactions(meetingID) })
+	}
+	count := room.reactions.counts[req.Reaction]
+	if count == nil {
+		count = &models.ReactionCount{Reaction: req.Reaction}
+		room.reactions.counts[req.Reaction] = count
+	}
+	count.Count++
+	if len(count.UserIDs) < reactionUserSample {
+		count.UserIDs = append(count.UserIDs, c.UserID)
+	}
+	room.mutex.Unlock()
+}
+
+func (h *WebSocketHandler) reactionFlushInterval() time.Duration {
+	if h.reactionWindow > 0 {
+		return h.reactionWindow
+	}
+	return reactionFlushInterval
+}
+
+// flushReactions 广播时间窗内聚合的表情反应
+func (h *WebSocketHandler) flushReactions(meetingID uint) {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return
+	}
+
+	room.mutex.Lock()
+	batch := room.reactions
+	room.reactions = nil
+	room.mutex.Unlock()
+	if batch == nil || len(batch.counts) == 0 {
+		return
+	}
+
+	counts := make([]models.ReactionCount, 0, len(batch.counts))
+	for _, count := range batch.counts {
+		if count.Count > reactionUserSample {
+			count.UserIDs = nil
+		}
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Reaction < counts[j].Reaction
+	})
+
+	logger.Debug("Reactions aggregated", logger.Uint("meeting_id", meetingID), logger.Int("kinds", len(counts)))
+	h.broadcastReactions(meetingID, counts, true)
+}
+
+func (h *WebSocketHandler) broadcastReactions(meetingID uint, counts []models.ReactionCount, aggregated bool) {
+	var fromUserID uint
+	if !aggregated && len(counts) == 1 && len(counts[0].UserIDs) == 1 {
+		fromUserID = counts[0].UserIDs[0]
+	}
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("reaction_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeReaction,
+		FromUserID: fromUserID,
+		MeetingID:  meetingID,
+		Payload: models.ReactionBurstMessage{
+			Reactions:  counts,
+			Aggregated: aggregated,
+			Timestamp:  time.Now(),
+		},
+		Timestamp: time.Now(),
+	}, "")
+}
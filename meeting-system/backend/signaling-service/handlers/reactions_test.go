@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+)
+
+func raiseHand(action string, userID uint) *models.WebSocketMessage {
+	return &models.WebSocketMessage{Type: models.MessageTypeRaiseHand, Payload: models.RaiseHandMessage{Action: action, UserID: userID}}
+}
+
+func queueUsers(queue []models.RaisedHand) []uint {
+	users := make([]uint, 0, len(queue))
+	for _, hand := range queue {
+		users = append(users, hand.UserID)
+	}
+	return users
+}
+
+func TestRaiseHand_OrderedQueueSyncedToLateJoiners(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+
+	clients[10].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+	clients[9].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+	clients[10].handleMessage(raiseHand(models.RaiseHandActionRaise, 0)) // 重复举手不改变顺序
+
+	messages, _ := drainAll(t, clients[7])
+	events := decodeEvents[models.RaiseHandEvent](t, messages, models.MessageTypeRaiseHand)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.HandEventRaised, events[1].Event)
+	assert.Equal(t, uint(9), events[1].UserID)
+	assert.Equal(t, []uint{10, 9}, queueUsers(events[1].Queue))
+
+	late := joinResumeHandler(h, "session-late", 11)
+	late.sendRoomInfo()
+	messages, _ = drainAll(t, late)
+	require.NotEmpty(t, messages)
+	require.Equal(t, models.MessageTypeRoomInfo, messages[0].Type)
+	var info models.RoomInfoMessage
+	require.NoError(t, decodePayload(messages[0].Payload, &info))
+	assert.Equal(t, []uint{10, 9}, queueUsers(info.RaisedHands))
+}
+
+func TestRaiseHand_LoweringOthersRequiresModerator(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	clients[9].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+	clients[10].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+	drainAll(t, clients[9])
+
+	clients[9].handleMessage(raiseHand(models.RaiseHandActionLower, 10))
+	errs := drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+
+	clients[9].handleMessage(raiseHand(models.RaiseHandActionLowerAll, 0))
+	errs = drainErrors(t, clients[9])
+	require.Len(t, errs, 1)
+	assert.Equal(t, 403, errs[0].Code)
+
+	clients[7].handleMessage(raiseHand(models.RaiseHandActionLower, 10))
+	assert.Equal(t, []uint{9}, queueUsers(h.getRaisedHands(1)))
+
+	clients[8].handleMessage(raiseHand(models.RaiseHandActionLowerAll, 0))
+	assert.Empty(t, h.getRaisedHands(1))
+
+	messages, _ := drainAll(t, clients[9])
+	events := decodeEvents[models.RaiseHandEvent](t, messages, models.MessageTypeRaiseHand)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.HandEventLowered, events[0].Event)
+	assert.Equal(t, uint(7), events[0].ByUserID)
+	assert.Equal(t, models.HandEventLoweredAll, events[1].Event)
+	assert.Empty(t, events[1].Queue)
+}
+
+func TestNonverbal_ReleasedWhenParticipantLeaves(t *testing.T) {
+	h, _, _, _, clients := newHostControlHandler(t)
+	clients[9].handleMessage(raiseHand(models.RaiseHandActionRaise, 0))
+	clients[9].handleMessage(&models.WebSocketMessage{Type: models.MessageTypeFeedback, Payload: models.FeedbackMessage{Feedback: models.FeedbackSlower}})
+	assert.Len(t, h.getFeedback(1), 1)
+	drainAll(t, clients[10])
+
+	h.unregisterClient(clients[9])
+
+	assert.Empty(t, h.getRaisedHands(1))
+	assert.Empty(t, h.getFeedback(1))
+	messages, _ := drainAll(t, clients[10])
+	events := decodeEvents[models.RaiseHandEvent](t, messages, models.MessageTypeRaiseHand)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint(9), events[0].UserID)
+	assert.Empty(t, events[0].Queue)
+	assert.Contains(t, messageTypes(messages), models.MessageTypeFeedback)
+}
+
+func TestReaction_SmallRoomBroadcastsImmediately(t *testing.T) {
+	_, _, _, _, clients := newHostControlHandler(t)
+
+	clients[9].handleMessage(&models.WebSocketMessage{Type: models.MessageTypeReaction, Payload: models.ReactionMessage{Reaction: models.ReactionClap}})
+
+	messages, _ := drainAll(t, clients[7])
+	bursts := decodeEvents[models.ReactionBurstMessage](t, messages, models.MessageTypeReaction)
+	require.Len(t, bursts, 1)
+	assert.False(t, bursts[0].Aggregated)
+	assert.Equal(t, []models.ReactionCount{{Reaction: models.ReactionClap, Count: 1, UserIDs: []uint{9}}}, bursts[0].Reactions)
+}
+
+func TestReaction_LargeRoomAggregatesWithinWindow(t *testing.T) {
+	h := newResumeHandler(0, 0, 0)
+	h.reactionWindow = 20 * time.Millisecond
+	clients := make([]*Client, 0, 30)
+	for userID := uint(1); userID <= 30; userID++ {
+		clients = append(clients, joinResumeHandler(h, fmt.Sprintf("session-%d", userID), userID))
+	}
+	observer := clients[0]
+	drainAll(t, observer)
+
+	for i, client := range clients {
+		reaction := models.ReactionThumbsUp
+		if i%10 == 0 {
+			reaction = models.ReactionHeart
+		}
+		client.handleMessage(&models.WebSocketMessage{Type: models.MessageTypeReaction, Payload: models.ReactionMessage{Reaction: reaction}})
+	}
+
+	var bursts []models.ReactionBurstMessage
+	require.Eventually(t, func() bool {
+		messages, _ := drainAll(t, observer)
+		bursts = append(bursts, decodeEvents[models.ReactionBurstMessage](t, messages, models.MessageTypeReaction)...)
+		return len(bursts) > 0
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(3 * h.reactionWindow)
+	messages, _ := drainAll(t, observer)
+	bursts = append(bursts, decodeEvents[models.ReactionBurstMessage](t, messages, models.MessageTypeReaction)...)
+
+	require.Len(t, bursts, 1, "30 个反应只广播一次")
+	assert.True(t, bursts[0].Aggregated)
+	require.Len(t, bursts[0].Reactions, 2)
+	assert.Equal(t, models.ReactionCount{Reaction: models.ReactionThumbsUp, Count: 27}, bursts[0].Reactions[0], "数量较多时不列出发送者")
+	assert.Equal(t, models.ReactionCount{Reaction: models.ReactionHeart, Count: 3, UserIDs: []uint{1, 11, 21}}, bursts[0].Reactions[1])
+}
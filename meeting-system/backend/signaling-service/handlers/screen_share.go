@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+// MeetingSettingsResolver 查询会议设置（生产环境为 SignalingService）
+type MeetingSettingsResolver interface {
+	GetMeetingSettings(meetingID uint) (*models.MeetingSettings, error)
+}
+
+// activeScreenShare 房间内正在进行的屏幕共享，sessionID 为发起共享的连接（断开时自动结束）
+type activeScreenShare struct {
+	models.ScreenShareState
+	sessionID string
+}
+
+func validScreenShareHint(hint string) bool {
+	switch hint {
+	case "", models.ScreenShareHintMotion, models.ScreenShareHintDetail, models.ScreenShareHintText:
+		return true
+	}
+	return false
+}
+
+// handleScreenShare 处理屏幕共享开始/结束：独占模式（会议默认）下同一时间只有一人共享，
+// 主办人/主持人可以接管；状态以 ScreenShareEvent 广播，并通知媒体服务在 SFU 只转发当前共享者
+func (c *Client) handleScreenShare(message *models.WebSocketMessage) {
+	var req models.ScreenShareMessage
+	if err := decodePayload(message.Payload, &req); err != nil {
+		c.sendError("Invalid screen share request", err.Error())
+		return
+	}
+
+	switch req.Action {
+	case models.ScreenShareActionStart:
+		c.startScreenShare(req)
+	case models.ScreenShareActionStop:
+		c.stopScreenShare()
+	default:
+		c.sendError("Invalid screen share request", "action must be start or stop")
+	}
+}
+
+func (c *Client) startScreenShare(req models.ScreenShareMessage) {
+	if !validScreenShareHint(req.ContentHint) {
+		c.sendError("Invalid screen share request", "content_hint must be motion, detail or text")
+		return
+	}
+
+	h := c.Handler
+	if h.mediaModerated(c.MeetingID(), c.UserID, models.ModerationMediaScreen) {
+		c.sendError("Screen share denied", "screen share is stopped by the host")
+		return
+	}
+
+	h.mutex.RLock()
+	room := h.rooms[c.MeetingID()]
+	h.mutex.RUnlock()
+	if room == nil {
+		c.sendError("Room not found", "meeting room not available")
+		return
+	}
+
+	exclusive := h.exclusiveScreenShare(c.MeetingID())
+	// 角色查询不能持有房间锁，先确认是否存在需要接管的共享（集群中其他节点的共享只在 Redis 登记）
+	canTakeover := false
+	if exclusive && (h.cluster != nil || room.hasOtherScreenShare(c.UserID)) {
+		if h.roles != nil {
+			if role, err := h.roles.GetParticipantRole(c.UserID, c.MeetingID()); err == nil {
+				canTakeover = role.CanModerate()
+			}
+		}
+	}
+
+	state := models.ScreenShareState{
+		UserID:      c.UserID,
+		Username:    c.Username,
+		PeerID:      c.PeerID,
+		StreamID:    req.StreamID,
+		TrackID:     req.TrackID,
+		ContentHint: req.ContentHint,
+		StartedAt:   time.Now(),
+	}
+
+	var previous []models.ScreenShareState
+	if h.cluster != nil {
+		claimed, others, err := h.cluster.claimScreenShare(c.MeetingID(), state, c.ID, exclusive, canTakeover)
+		if err != nil {
+			logger.Error("Failed to claim cluster screen share", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+			c.sendError("Screen share unavailable", "failed to coordinate screen share")
+			return
+		}
+		if !claimed {
+			c.sendError("Screen share denied", fmt.Sprintf("%s is already sharing", others[0].Username))
+			return
+		}
+		previous = others
+	}
+
+	room.mutex.Lock()
+	if room.screenShares == nil {
+		room.screenShares = make(map[uint]*activeScreenShare)
+	}
+	if exclusive && h.cluster == nil {
+		for userID, share := range room.screenShares {
+			if userID != c.UserID {
+				previous = append(previous, share.ScreenShareState)
+			}
+		}
+		if len(previous) > 0 && !canTakeover {
+			room.mutex.Unlock()
+			c.sendError("Screen share denied", fmt.Sprintf("%s is already sharing", previous[0].Username))
+			return
+		}
+	}
+	for _, p := range previous {
+		delete(room.screenShares, p.UserID)
+	}
+	room.screenShares[c.UserID] = &activeScreenShare{ScreenShareState: state, sessionID: c.ID}
+	room.mutex.Unlock()
+
+	h.publishScreenShare(c.MeetingID(), c.UserID, true, exclusive, req.ContentHint)
+
+	if len(previous) > 0 {
+		for _, p := range previous {
+			logger.Info("Screen share taken over",
+				logger.Uint("meeting_id", c.MeetingID()),
+				logger.Uint("previous_user_id", p.UserID),
+				logger.Uint("user_id", c.UserID))
+			h.broadcastScreenShareEvent(c.MeetingID(), models.ScreenShareEvent{
+				Event:          models.ScreenShareEventTakeover,
+				Share:          state,
+				PreviousUserID: p.UserID,
+				ByUserID:       c.UserID,
+				Exclusive:      exclusive,
+			})
+		}
+		return
+	}
+	h.broadcastScreenShareEvent(c.MeetingID(), models.ScreenShareEvent{
+		Event:     models.ScreenShareEventStarted,
+		Share:     state,
+		ByUserID:  c.UserID,
+		Exclusive: exclusive,
+	})
+}
+
+func (c *Client) stopScreenShare() {
+	c.Handler.stopUserScreenShare(c.MeetingID(), c.UserID, c.UserID)
+}
+
+// stopUserScreenShare 结束用户的屏幕共享（本人停止或被主办人/主持人禁止共享），通知媒体服务与房间
+func (h *WebSocketHandler) stopUserScreenShare(meetingID, userID, byUserID uint) bool {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil && h.cluster == nil {
+		return false
+	}
+
+	var share models.ScreenShareState
+	ok := false
+	if room != nil {
+		room.mutex.Lock()
+		if local, exists := room.screenShares[userID]; exists {
+			share, ok = local.ScreenShareState, true
+			delete(room.screenShares, userID)
+		}
+		room.mutex.Unlock()
+	}
+	if h.cluster != nil {
+		stored, removed, err := h.cluster.removeScreenShare(meetingID, userID, "")
+		if err != nil {
+			logger.Error("Failed to remove cluster screen share", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+		if removed && !ok {
+			share, ok = stored, true
+		}
+	}
+	if !ok {
+		return false
+	}
+
+	exclusive := h.exclusiveScreenShare(meetingID)
+	h.publishScreenShare(meetingID, userID, false, exclusive, "")
+	h.broadcastScreenShareEvent(meetingID, models.ScreenShareEvent{
+		Event:     models.ScreenShareEventStopped,
+		Share:     share,
+		ByUserID:  byUserID,
+		Exclusive: exclusive,
+	})
+	return true
+}
+
+// releaseScreenShareLocked 连接断开时结束该连接发起的共享（调用方持有 room.mutex）
+func (r *Room) releaseScreenShareLocked(sessionID string) (models.ScreenShareState, bool) {
+	for userID, share := range r.screenShares {
+		if share.sessionID == sessionID {
+			delete(r.screenShares, userID)
+			return share.ScreenShareState, true
+		}
+	}
+	return models.ScreenShareState{}, false
+}
+
+// endScreenShareOnLeave 共享者断开后通知媒体服务，房间仍有人时广播结束事件
+func (h *WebSocketHandler) endScreenShareOnLeave(meetingID uint, share models.ScreenShareState, broadcast bool) {
+	exclusive := h.exclusiveScreenShare(meetingID)
+	h.publishScreenShare(meetingID, share.UserID, false, exclusive, "")
+	if broadcast {
+		h.broadcastScreenShareEvent(meetingID, models.ScreenShareEvent{
+			Event:     models.ScreenShareEventStopped,
+			Share:     share,
+			Exclusive: exclusive,
+		})
+	}
+}
+
+// applyClusterScreenShare 其他节点结束或接管了共享：移除本节点上对应的本地共享，避免连接断开时重复结束
+func (h *WebSocketHandler) applyClusterScreenShare(meetingID uint, data []byte) {
+	var message models.WebSocketMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return
+	}
+	var event models.ScreenShareEvent
+	if err := decodePayload(message.Payload, &event); err != nil {
+		return
+	}
+
+	var userID uint
+	switch event.Event {
+	case models.ScreenShareEventStopped:
+		userID = event.Share.UserID
+	case models.ScreenShareEventTakeover:
+		userID = event.PreviousUserID
+	default:
+		return
+	}
+
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return
+	}
+	room.mutex.Lock()
+	delete(room.screenShares, userID)
+	room.mutex.Unlock()
+}
+
+func (r *Room) hasOtherScreenShare(userID uint) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for id := range r.screenShares {
+		if id != userID {
+			return true
+		}
+	}
+	return false
+}
+
+// exclusiveScreenShare 会议是否为独占屏幕共享模式（查询失败时按默认的独占模式处理）
+func (h *WebSocketHandler) exclusiveScreenShare(meetingID uint) bool {
+	if h.settings == nil {
+		return true
+	}
+	settings, err := h.settings.GetMeetingSettings(meetingID)
+	if err != nil {
+		return true
+	}
+	return settings.ExclusiveScreenShare()
+}
+
+// getScreenShares 房间内正在进行的屏幕共享（按开始时间排序）
+func (h *WebSocketHandler) getScreenShares(meetingID uint) []models.ScreenShareState {
+	h.mutex.RLock()
+	room := h.rooms[meetingID]
+	h.mutex.RUnlock()
+	if room == nil {
+		return nil
+	}
+
+	var shares []models.ScreenShareState
+	if h.cluster != nil {
+		var err error
+		if shares, err = h.cluster.screenShares(meetingID); err != nil {
+			logger.Error("Failed to load cluster screen shares", logger.Uint("meeting_id", meetingID), logger.Err(err))
+			shares = nil
+		}
+	}
+	if shares == nil {
+		room.mutex.RLock()
+		shares = make([]models.ScreenShareState, 0, len(room.screenShares))
+		for _, share := range room.screenShares {
+			shares = append(shares, share.ScreenShareState)
+		}
+		room.mutex.RUnlock()
+	}
+
+	sort.Slice(shares, func(i, j int) bool { return shares[i].StartedAt.Before(shares[j].StartedAt) })
+	return shares
+}
+
+func (h *WebSocketHandler) broadcastScreenShareEvent(meetingID uint, event models.ScreenShareEvent) {
+	event.Timestamp = time.Now()
+	h.broadcastToRoom(meetingID, &models.WebSocketMessage{
+		ID:         fmt.Sprintf("screen_share_%d", time.Now().UnixNano()),
+		Type:       models.MessageTypeScreenShare,
+		FromUserID: event.ByUserID,
+		MeetingID:  meetingID,
+		Payload:    event,
+		Timestamp:  event.Timestamp,
+	}, "")
+}
+
+// publishScreenShare 通知媒体服务屏幕共享开始/结束（独占模式下 SFU 只转发当前共享者的屏幕轨道）
+func (h *WebSocketHandler) publishScreenShare(meetingID, userID uint, sharing, exclusive bool, contentHint string) {
+	if h.events == nil {
+		return
+	}
+
+	eventType := queue.EventScreenShareStopped
+	if sharing {
+		eventType = queue.EventScreenShareStarted
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiLiveEventTimeout)
+	defer cancel()
+	if err := h.events.Publish(ctx, queue.ChannelSignalingEvents, &queue.PubSubMessage{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"meeting_id":   meetingID,
+			"user_id":      userID,
+			"exclusive":    exclusive,
+			"content_hint": contentHint,
+		},
+		Source: "signaling-service",
+	}); err != nil {
+		logger.Warn("Failed to publish screen share event",
+			logger.Uint("meeting_id", meetingID),
+			logger.Uint("user_id", userID),
+			logger.String("event", eventType),
+			logger.Err(err))
+	}
+}
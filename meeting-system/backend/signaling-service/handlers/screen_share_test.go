@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/models"
+	"meeting-system/shared/queue"
+)
+
+type staticSettings models.MeetingSettings
+
+func (s staticSettings) GetMeetingSettings(uint) (*models.MeetingSettings, error) {
+	settings := models.MeetingSettings(s)
+	return &settings, nil
+}
+
+func screenShareMessage(action, streamID, hint string) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		Type:    models.MessageTypeScreenShare,
+		Payload: map[string]interface{}{"action": action, "stream_id": streamID, "content_hint": hint},
+	}
+}
+
+// TestScreenShare_ExclusiveTakeover 默认独占模式：参与者不能抢占，主办人/主持人可以接管
+func TestScreenShare_ExclusiveTakeover(t *testing.T) {
+	h, clients, events := newModerationHandler()
+
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-9", models.ScreenShareHintText))
+	started := decodeEvents[models.ScreenShareEvent](t, drainMessages(t, clients[7]), models.MessageTypeScreenShare)
+	require.Len(t, started, 1)
+	assert.Equal(t, models.ScreenShareEventStarted, started[0].Event)
+	assert.Equal(t, uint(9), started[0].Share.UserID)
+	assert.Equal(t, "screen-9", started[0].Share.StreamID)
+	assert.True(t, started[0].Exclusive)
+	require.Len(t, events.messages, 1)
+	assert.Equal(t, queue.EventScreenShareStarted, events.messages[0].Type)
+	assert.Equal(t, "text", events.messages[0].Payload["content_hint"])
+	drainMessages(t, clients[8])
+	drainMessages(t, clients[9])
+
+	// 把主持人降级为普通参与者无法接管
+	h.roles = staticRoles{7: models.ParticipantRoleHost, 8: models.ParticipantRoleParticipant, 9: models.ParticipantRoleParticipant}
+	clients[8].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-8", ""))
+	denied := drainMessages(t, clients[8])
+	require.Len(t, denied, 1)
+	assert.Equal(t, models.MessageTypeError, denied[0].Type)
+	assert.Len(t, events.messages, 1)
+
+	clients[7].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-7", models.ScreenShareHintMotion))
+	takeover := decodeEvents[models.ScreenShareEvent](t, drainMessages(t, clients[9]), models.MessageTypeScreenShare)
+	require.Len(t, takeover, 1)
+	assert.Equal(t, models.ScreenShareEventTakeover, takeover[0].Event)
+	assert.Equal(t, uint(9), takeover[0].PreviousUserID)
+	assert.Equal(t, uint(7), takeover[0].Share.UserID)
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, uint(7), events.messages[1].Payload["user_id"])
+	assert.Equal(t, true, events.messages[1].Payload["exclusive"])
+
+	shares := h.getScreenShares(1)
+	require.Len(t, shares, 1)
+	assert.Equal(t, uint(7), shares[0].UserID)
+
+	// 被接管者再发 stop 不产生事件
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStop, "", ""))
+	assert.Len(t, events.messages, 2)
+
+	clients[7].handleScreenShare(screenShareMessage(models.ScreenShareActionStop, "", ""))
+	stopped := decodeEvents[models.ScreenShareEvent](t, drainMessages(t, clients[8]), models.MessageTypeScreenShare)
+	require.Len(t, stopped, 2)
+	assert.Equal(t, models.ScreenShareEventStopped, stopped[1].Event)
+	require.Len(t, events.messages, 3)
+	assert.Equal(t, queue.EventScreenShareStopped, events.messages[2].Type)
+	assert.Empty(t, h.getScreenShares(1))
+}
+
+func TestScreenShare_MultipleMode(t *testing.T) {
+	h, clients, _ := newModerationHandler()
+	h.settings = staticSettings{ScreenShareMode: models.ScreenShareModeMultiple}
+
+	clients[8].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-8", ""))
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-9", ""))
+
+	events := decodeEvents[models.ScreenShareEvent](t, drainMessages(t, clients[7]), models.MessageTypeScreenShare)
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, models.ScreenShareEventStarted, event.Event)
+		assert.False(t, event.Exclusive)
+	}
+	assert.Len(t, h.getScreenShares(1), 2)
+}
+
+func TestScreenShare_RejectedWhenModeratedOrInvalid(t *testing.T) {
+	h, clients, _ := newModerationHandler()
+
+	clients[7].handleModerateMedia(moderateMessage(9, models.ModerationMediaScreen, true))
+	drainMessages(t, clients[9])
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-9", ""))
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-9", "cinematic"))
+	clients[9].handleScreenShare(screenShareMessage("pause", "", ""))
+
+	messages := drainMessages(t, clients[9])
+	require.Len(t, messages, 3)
+	for _, msg := range messages {
+		assert.Equal(t, models.MessageTypeError, msg.Type)
+	}
+	assert.Empty(t, h.getScreenShares(1))
+}
+
+// TestScreenShare_ModeratorMuteEndsShare 主办人禁止屏幕共享时结束当前共享并通知媒体服务
+func TestScreenShare_ModeratorMuteEndsShare(t *testing.T) {
+	h, clients, events := newModerationHandler()
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-9", ""))
+	drainMessages(t, clients[8])
+
+	clients[7].handleModerateMedia(moderateMessage(9, models.ModerationMediaScreen, true))
+	stopped := decodeEvents[models.ScreenShareEvent](t, drainMessages(t, clients[8]), models.MessageTypeScreenShare)
+	require.Len(t, stopped, 1)
+	assert.Equal(t, models.ScreenShareEventStopped, stopped[0].Event)
+	assert.Equal(t, uint(9), stopped[0].Share.UserID)
+	assert.Equal(t, uint(7), stopped[0].ByUserID)
+	assert.Empty(t, h.getScreenShares(1))
+
+	var types []string
+	for _, msg := range events.messages {
+		types = append(types, msg.Type)
+	}
+	assert.Contains(t, types, queue.EventScreenShareStopped)
+}
+
+// TestScreenShare_EndsWhenSessionLeaves 共享者断开后结束共享并通知其他人
+func TestScreenShare_EndsWhenSessionLeaves(t *testing.T) {
+	h, clients, events := newModerationHandler()
+	clients[9].handleScreenShare(screenShareMessage(models.ScreenShareActionStart, "screen-9", ""))
+	drainMessages(t, clients[7])
+
+	room := h.rooms[1]
+	room.mutex.Lock()
+	_, ok := room.releaseScreenShareLocked(clients[8].ID)
+	assert.False(t, ok, "其他连接断开不影响共享")
+	share, ok := room.releaseScreenShareLocked(clients[9].ID)
+	room.mutex.Unlock()
+	require.True(t, ok)
+
+	h.endScreenShareOnLeave(1, share, true)
+	stopped := decodeEvents[models.ScreenShareEvent](t, drainMessages(t, clients[7]), models.MessageTypeScreenShare)
+	require.Len(t, stopped, 1)
+	assert.Equal(t, models.ScreenShareEventStopped, stopped[0].Event)
+	assert.Equal(t, uint(9), stopped[0].Share.UserID)
+	require.Len(t, events.messages, 2)
+	assert.Equal(t, queue.EventScreenShareStopped, events.messages[1].Type)
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/logger"
+	"meeting-system/shared/models"
+)
+
+const (
+	defaultResumeTimeout    = 30 * time.Second
+	defaultReplayBufferSize = 256
+	// 续传时重放消息一次性写入新连接的 Send 通道，缓冲上限不能超过其容量
+	maxReplayBufferSize = 1024
+)
+
+// resumeOptions 断线续传配置；maxAttempts<=0 时关闭
+type resumeOptions struct {
+	maxAttempts int
+	timeout     time.Duration
+	bufferSize  int
+}
+
+func resumeOptionsFromConfig(cfg config.SessionConfig) resumeOptions {
+	opts := resumeOptions{
+		maxAttempts: cfg.MaxReconnectAttempts,
+		timeout:     time.Duration(cfg.ResumeTimeout) * time.Second,
+		bufferSize:  cfg.ReplayBufferSize,
+	}
+	if opts.timeout <= 0 {
+		opts.timeout = defaultResumeTimeout
+	}
+	if opts.bufferSize <= 0 {
+		opts.bufferSize = defaultReplayBufferSize
+	}
+	if opts.bufferSize > maxReplayBufferSize {
+		opts.bufferSize = maxReplayBufferSize
+	}
+	return opts
+}
+
+func (o resumeOptions) enabled() bool {
+	return o.maxAttempts > 0
+}
+
+type replayEntry struct {
+	seq  uint64
+	data []byte
+}
+
+// resumableSession 可续传会话：下发序号、重放缓冲与断线挂起状态，在同一 sessionID 的前后连接间共享。
+// 锁顺序：h.mutex / room.mutex 在前，mu 在后（enqueue 在持有房间锁时调用）
+type resumableSession struct {
+	mu        sync.Mutex
+	token     string
+	current   *Client // 当前连接；续传后指向新的 Client
+	lastSeq   uint64
+	buffer    []replayEntry
+	capacity  int
+	resumes   int
+	suspended bool // 连接已断开，等待续传；期间下发的消息只进缓冲
+	closed    bool // 已注销或挂起超时，不能再续传
+	expiry    *time.Timer
+}
+
+func newResumableSession(client *Client, capacity int) (*resumableSession, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	return &resumableSession{
+		token:    hex.EncodeToString(raw),
+		current:  client,
+		capacity: capacity,
+	}, nil
+}
+
+// recordLocked 为消息分配序号并写入重放缓冲，返回带 seq 字段的消息
+func (s *resumableSession) recordLocked(data []byte) []byte {
+	s.lastSeq++
+	stamped := withSeq(data, s.lastSeq)
+	if len(s.buffer) >= s.capacity {
+		s.buffer = append(s.buffer[:0], s.buffer[1:]...)
+	}
+	s.buffer = append(s.buffer, replayEntry{seq: s.lastSeq, data: stamped})
+	return stamped
+}
+
+// replayableLocked 客户端最后收到 lastSeq 时，缓冲中是否仍保留其后的全部消息
+func (s *resumableSession) replayableLocked(lastSeq uint64) bool {
+	if lastSeq > s.lastSeq {
+		return false
+	}
+	if lastSeq == s.lastSeq {
+		return true
+	}
+	return len(s.buffer) > 0 && s.buffer[0].seq <= lastSeq+1
+}
+
+// withSeq 在已序列化的消息对象中插入 seq 字段（广播消息只序列化一次，序号按会话分别写入）
+func withSeq(data []byte, seq uint64) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	out := make([]byte, 0, len(data)+24)
+	out = append(out, `{"seq":`...)
+	out = strconv.AppendUint(out, seq, 10)
+	if len(data) > 2 {
+		out = append(out, ',')
+	}
+	return append(out, data[1:]...)
+}
+
+// suspended 连接已断开、会话等待续传
+func (c *Client) suspended() bool {
+	if c.session == nil {
+		return false
+	}
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	return c.session.suspended && c.session.current == c
+}
+
+func (c *Client) resumeToken() string {
+	if c.session == nil {
+		return ""
+	}
+	return c.session.token
+}
+
+// attachSession 为新连接创建可续传会话
+func (h *WebSocketHandler) attachSession(client *Client) {
+	if !h.resume.enabled() || client.session != nil {
+		return
+	}
+	session, err := newResumableSession(client, h.resume.bufferSize)
+	if err != nil {
+		logger.Warn("Failed to create resume token; session will not be resumable",
+			logger.String("session", client.ID), logger.Err(err))
+		return
+	}
+	client.session = session
+	if h.resumeTokens == nil {
+		h.resumeTokens = make(map[string]*resumableSession)
+	}
+	h.resumeTokens[session.token] = session
+}
+
+// detachSessionLocked 会话注销时作废续传令牌（调用方持有 h.mutex）
+func (h *WebSocketHandler) detachSessionLocked(client *Client) {
+	session := client.session
+	if session == nil {
+		return
+	}
+	session.mu.Lock()
+	session.closed = true
+	if session.expiry != nil {
+		session.expiry.Stop()
+	}
+	session.mu.Unlock()
+	delete(h.resumeTokens, session.token)
+}
+
+// disconnectClient 连接断开：可续传的会话先挂起，超时未续传再注销。
+// 续传缓冲只在本节点内存中，集群模式下需按用户粘性路由才能无损续传；
+// 续传请求落到其他节点时，该节点经 Redis 登记找到本节点并让本节点立即注销挂起的会话
+func (h *WebSocketHandler) disconnectClient(client *Client) {
+	if h.suspendClient(client) {
+		if h.cluster != nil && client.suspended() {
+			owner := clusterResumeOwner{SessionID: client.ID, UserID: client.UserID, MeetingID: client.MeetingID()}
+			if err := h.cluster.claimResume(client.session.token, owner, h.resume.timeout); err != nil {
+				logger.Warn("Failed to register suspended session in cluster",
+					logger.String("session", client.ID), logger.Err(err))
+			}
+		}
+		return
+	}
+	h.unregisterClient(client)
+}
+
+// suspendClient 挂起会话并保留房间成员身份；返回 false 表示应直接注销
+func (h *WebSocketHandler) suspendClient(client *Client) bool {
+	session := client.session
+	if session == nil {
+		return false
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.current != client {
+		// 已被新连接接管，旧连接断开无需处理
+		return true
+	}
+	if session.closed || session.resumes >= h.resume.maxAttempts {
+		return false
+	}
+	if session.suspended {
+		return true
+	}
+
+	session.suspended = true
+	session.expiry = time.AfterFunc(h.resume.timeout, func() {
+		h.expireSession(session, client)
+	})
+	logger.Info("WebSocket session suspended awaiting resume",
+		logger.String("session", client.ID),
+		logger.Uint("user_id", client.UserID),
+		logger.Int("resumes", session.resumes))
+	return true
+}
+
+func (h *WebSocketHandler) expireSession(session *resumableSession, client *Client) {
+	session.mu.Lock()
+	if !session.suspended || session.closed || session.current != client {
+		session.mu.Unlock()
+		return
+	}
+	session.closed = true
+	session.mu.Unlock()
+
+	logger.Info("WebSocket session resume window expired", logger.String("session", client.ID))
+	h.releaseClusterResume(session)
+	h.unregisterClient(client)
+}
+
+// endSuspendedSession 其他节点接手了该会话的续传请求，不再等待续传窗口
+func (h *WebSocketHandler) endSuspendedSession(token, sessionID string) {
+	h.mutex.RLock()
+	session := h.resumeTokens[token]
+	h.mutex.RUnlock()
+	if session == nil {
+		return
+	}
+
+	session.mu.Lock()
+	client := session.current
+	session.mu.Unlock()
+	if client.ID != sessionID {
+		return
+	}
+	// 仍处于挂起状态才注销；随后触发的超时定时器不会重复处理
+	h.expireSession(session, client)
+}
+
+// takeOverRemoteSession 续传令牌不在本节点：若由其他存活节点挂起，通知其立即注销，调用方按新会话处理
+func (h *WebSocketHandler) takeOverRemoteSession(token string, userID, meetingID uint) {
+	owner, ok, err := h.cluster.resumeOwner(token)
+	if err != nil {
+		logger.Warn("Failed to look up resume token in cluster", logger.Err(err))
+		return
+	}
+	if !ok || owner.NodeID == h.cluster.nodeID || owner.UserID != userID || owner.MeetingID != meetingID {
+		return
+	}
+	logger.Info("Resume token is held by another signaling node; ending it there and starting a new session",
+		logger.String("owner_node", owner.NodeID),
+		logger.String("session", owner.SessionID),
+		logger.Uint("user_id", userID))
+	h.cluster.publishSessionTakeover(owner, token)
+}
+
+func (h *WebSocketHandler) releaseClusterResume(session *resumableSession) {
+	if h.cluster == nil {
+		return
+	}
+	if err := h.cluster.releaseResume(session.token); err != nil {
+		logger.Warn("Failed to release resume token in cluster", logger.Err(err))
+	}
+}
+
+// lookupResumable 按令牌查找属于该用户与会议的可续传会话
+func (h *WebSocketHandler) lookupResumable(token string, userID, meetingID uint) *resumableSession {
+	h.mutex.RLock()
+	session := h.resumeTokens[token]
+	h.mutex.RUnlock()
+	if session == nil {
+		return nil
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.current.UserID != userID || session.current.MeetingID() != meetingID {
+		return nil
+	}
+	return session
+}
+
+// resumeClient 用新连接接管会话并重放 lastSeq 之后的消息，不广播离开/加入。
+// 续传失败时注销原会话，调用方按新会话处理
+func (h *WebSocketHandler) resumeClient(session *resumableSession, conn *websocket.Conn, lastSeq uint64) (*Client, error) {
+	session.mu.Lock()
+	old := session.current
+	var reason string
+	switch {
+	case session.closed:
+		reason = "session closed"
+	case session.resumes >= h.resume.maxAttempts:
+		reason = "reconnect attempts exhausted"
+	case !session.replayableLocked(lastSeq):
+		reason = fmt.Sprintf("messages after seq %d no longer buffered", lastSeq)
+	}
+	if reason != "" {
+		session.mu.Unlock()
+		h.unregisterClient(old)
+		if old.Conn != nil {
+			old.Conn.Close()
+		}
+		return nil, fmt.Errorf("cannot resume session %s: %s", old.ID, reason)
+	}
+
+	now := time.Now()
+	client := &Client{
+		ID:           old.ID,
+		UserID:       old.UserID,
+		PeerID:       old.PeerID,
+		Username:     old.Username,
+		Conn:         conn,
+		Send:         make(chan []byte, 2048),
+		PrioritySend: make(chan []byte, 128),
+		Handler:      h,
+		LastPing:     now,
+		JoinedAt:     old.JoinedAt,
+		session:      session,
+		limiter:      old.limiter,
+	}
+	client.setMeetingID(old.MeetingID())
+	client.breakoutOf.Store(uint64(old.parentMeetingID()))
+	if conn != nil {
+		client.Binary = conn.Subprotocol() == SubprotocolProtobufV2
+	}
+	// 保持挂起直到重放完成，期间的新消息只进缓冲，保证顺序
+	session.current = client
+	session.suspended = true
+	session.resumes++
+	if session.expiry != nil {
+		session.expiry.Stop()
+	}
+	session.mu.Unlock()
+
+	h.mutex.Lock()
+	if h.clients[old.ID] != old {
+		// 原会话在接管前已被注销
+		h.mutex.Unlock()
+		return nil, fmt.Errorf("cannot resume session %s: session closed", old.ID)
+	}
+	h.clients[client.ID] = client
+	if room, ok := h.rooms[client.MeetingID()]; ok {
+		room.mutex.Lock()
+		room.Clients[client.ID] = client
+		room.LastActivity = now
+		room.mutex.Unlock()
+	}
+	h.mutex.Unlock()
+
+	session.mu.Lock()
+	replayed := 0
+	for _, entry := range session.buffer {
+		if entry.seq <= lastSeq {
+			continue
+		}
+		client.Send <- entry.data
+		replayed++
+	}
+	ack, _ := json.Marshal(&models.WebSocketMessage{
+		ID:        fmt.Sprintf("resumed_%d", now.UnixNano()),
+		Type:      models.MessageTypeSessionResumed,
+		MeetingID: client.MeetingID(),
+		SessionID: client.ID,
+		PeerID:    client.PeerID,
+		Payload: models.SessionResumedMessage{
+			SessionID:   client.ID,
+			ResumeToken: session.token,
+			LastSeq:     lastSeq,
+			Replayed:    replayed,
+		},
+		Timestamp: now,
+	})
+	client.PrioritySend <- ack
+	session.suspended = false
+	session.mu.Unlock()
+
+	h.releaseClusterResume(session)
+
+	// 旧连接可能仍半开，关闭后其读写协程自行退出
+	if old.Conn != nil {
+		old.Conn.Close()
+	}
+	old.closeSend()
+
+	logger.Info("WebSocket session resumed",
+		logger.String("session", client.ID),
+		logger.Uint("user_id", client.UserID),
+		logger.Int("replayed", replayed),
+		logger.Int("resumes", session.resumes))
+	return client, nil
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-system/shared/config"
+	"meeting-system/shared/models"
+)
+
+func newResumeHandler(maxAttempts, bufferSize int, timeout time.Duration) *WebSocketHandler {
+	if config.GlobalConfig == nil {
+		config.GlobalConfig = &config.Config{}
+	}
+	return &WebSocketHandler{
+		clients:      make(map[string]*Client),
+		rooms:        make(map[uint]*Room),
+		resume:       resumeOptions{maxAttempts: maxAttempts, timeout: timeout, bufferSize: bufferSize},
+		resumeTokens: make(map[string]*resumableSession),
+	}
+}
+
+func joinResumeHandler(h *WebSocketHandler, sessionID string, userID uint) *Client {
+	client := &Client{
+		ID:           sessionID,
+		UserID:       userID,
+		Username:     "user",
+		Handler:      h,
+		Send:         make(chan []byte, 64),
+		PrioritySend: make(chan []byte, 16),
+	}
+	client.setMeetingID(1)
+	h.registerClient(client)
+	return client
+}
+
+func chatFrom(client *Client, content string) *models.WebSocketMessage {
+	return &models.WebSocketMessage{
+		Type:       models.MessageTypeChat,
+		FromUserID: client.UserID,
+		MeetingID:  client.MeetingID(),
+		SessionID:  client.ID,
+		Payload:    map[string]interface{}{"content": content},
+	}
+}
+
+func messageTypes(messages []models.WebSocketMessage) []models.MessageType {
+	types := make([]models.MessageType, 0, len(messages))
+	for _, msg := range messages {
+		types = append(types, msg.Type)
+	}
+	return types
+}
+
+func TestSessionResume_ReplaysMissedMessagesWithoutLeaveJoin(t *testing.T) {
+	h := newResumeHandler(2, 8, time.Minute)
+	alice := joinResumeHandler(h, "session-alice", 1)
+	bob := joinResumeHandler(h, "session-bob", 2)
+
+	alice.sendRoomInfo()
+	var info models.WebSocketMessage
+	require.NoError(t, json.Unmarshal(<-alice.PrioritySend, &info))
+	var roomInfo models.RoomInfoMessage
+	require.NoError(t, decodePayload(info.Payload, &roomInfo))
+	require.NotEmpty(t, roomInfo.ResumeToken)
+
+	h.broadcastToRoom(1, chatFrom(bob, "before"), bob.ID)
+	received := drainMessages(t, alice)
+	require.Len(t, received, 1)
+	assert.Equal(t, uint64(1), info.Seq, "优先通道的消息同样分配序号")
+	assert.Equal(t, uint64(2), received[0].Seq)
+
+	// 断线：会话挂起，其他人看不到离开，期间的消息进入重放缓冲
+	h.disconnectClient(alice)
+	h.broadcastToRoom(1, chatFrom(bob, "gap-1"), bob.ID)
+	h.broadcastToRoom(1, chatFrom(bob, "gap-2"), bob.ID)
+	assert.Empty(t, drainMessages(t, alice))
+	assert.Len(t, h.collectRoomParticipants(1), 2)
+
+	session := h.lookupResumable(roomInfo.ResumeToken, alice.UserID, alice.MeetingID())
+	require.NotNil(t, session)
+	assert.Nil(t, h.lookupResumable(roomInfo.ResumeToken, bob.UserID, alice.MeetingID()), "令牌只属于原用户")
+
+	resumed, err := h.resumeClient(session, nil, 2)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, resumed.ID)
+
+	var ack models.WebSocketMessage
+	require.NoError(t, json.Unmarshal(<-resumed.PrioritySend, &ack))
+	assert.Equal(t, models.MessageTypeSessionResumed, ack.Type)
+	var resumedInfo models.SessionResumedMessage
+	require.NoError(t, decodePayload(ack.Payload, &resumedInfo))
+	assert.Equal(t, 2, resumedInfo.Replayed)
+
+	replayed := drainMessages(t, resumed)
+	require.Len(t, replayed, 2)
+	assert.Equal(t, []uint64{3, 4}, []uint64{replayed[0].Seq, replayed[1].Seq})
+
+	// 续传后消息发往新连接，序号连续；其他人没有收到离开/加入
+	h.broadcastToRoom(1, chatFrom(bob, "after"), bob.ID)
+	after := drainMessages(t, resumed)
+	require.Len(t, after, 1)
+	assert.Equal(t, uint64(5), after[0].Seq)
+	assert.NotContains(t, messageTypes(drainMessages(t, bob)), models.MessageTypeUserLeft)
+
+	// 旧连接的迟到注销不影响新连接
+	h.unregisterClient(alice)
+	assert.Len(t, h.collectRoomParticipants(1), 2)
+}
+
+func TestSessionResume_BuffersPriorityMessagesWhileSuspended(t *testing.T) {
+	h := newResumeHandler(2, 8, time.Minute)
+	alice := joinResumeHandler(h, "session-alice", 1)
+	bob := joinResumeHandler(h, "session-bob", 2)
+
+	// 挂起期间的房间信息不能写入即将关闭的旧通道，而要进入重放缓冲
+	h.disconnectClient(alice)
+	h.broadcastToRoom(1, chatFrom(bob, "gap"), bob.ID)
+	alice.sendRoomInfo()
+	assert.Empty(t, alice.PrioritySend)
+
+	resumed, err := h.resumeClient(alice.session, nil, 0)
+	require.NoError(t, err)
+	var ack models.WebSocketMessage
+	require.NoError(t, json.Unmarshal(<-resumed.PrioritySend, &ack))
+	assert.Equal(t, models.MessageTypeSessionResumed, ack.Type)
+
+	replayed := drainMessages(t, resumed)
+	require.Len(t, replayed, 2)
+	assert.Equal(t, []models.MessageType{models.MessageTypeChat, models.MessageTypeRoomInfo}, messageTypes(replayed))
+	assert.Equal(t, []uint64{1, 2}, []uint64{replayed[0].Seq, replayed[1].Seq})
+
+	// 续传后优先消息发往新连接
+	resumed.sendRoomInfo()
+	var info models.WebSocketMessage
+	require.NoError(t, json.Unmarshal(<-resumed.PrioritySend, &info))
+	assert.Equal(t, uint64(3), info.Seq)
+}
+
+func TestSessionResume_HonoursMaxReconnectAttempts(t *testing.T) {
+	h := newResumeHandler(1, 8, time.Minute)
+	alice := joinResumeHandler(h, "session-alice", 1)
+	bob := joinResumeHandler(h, "session-bob", 2)
+
+	h.disconnectClient(alice)
+	resumed, err := h.resumeClient(alice.session, nil, 0)
+	require.NoError(t, err)
+
+	// 已用完续传次数，再次断线直接离开
+	h.disconnectClient(resumed)
+	assert.Contains(t, messageTypes(drainMessages(t, bob)), models.MessageTypeUserLeft)
+	assert.Nil(t, h.lookupResumable(alice.resumeToken(), alice.UserID, alice.MeetingID()))
+	assert.Len(t, h.collectRoomParticipants(1), 1)
+}
+
+func TestSessionResume_RejectsWhenBufferOverflowed(t *testing.T) {
+	h := newResumeHandler(3, 2, time.Minute)
+	alice := joinResumeHandler(h, "session-alice", 1)
+	bob := joinResumeHandler(h, "session-bob", 2)
+
+	h.disconnectClient(alice)
+	for i := 0; i < 3; i++ {
+		h.broadcastToRoom(1, chatFrom(bob, "gap"), bob.ID)
+	}
+
+	_, err := h.resumeClient(alice.session, nil, 0)
+	require.Error(t, err, "seq 1 已被挤出缓冲，无法无损续传")
+	assert.Contains(t, messageTypes(drainMessages(t, bob)), models.MessageTypeUserLeft)
+	assert.Len(t, h.collectRoomParticipants(1), 1)
+}
+
+func TestSessionResume_ExpiresAfterTimeout(t *testing.T) {
+	h := newResumeHandler(3, 8, 20*time.Millisecond)
+	alice := joinResumeHandler(h, "session-alice", 1)
+	bob := joinResumeHandler(h, "session-bob", 2)
+
+	h.disconnectClient(alice)
+	assert.True(t, alice.suspended())
+	// 等到离开广播的最后一步（重发房间信息）完成，避免超时协程越过本测试
+	require.Eventually(t, func() bool {
+		return len(bob.PrioritySend) > 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Len(t, h.collectRoomParticipants(1), 1)
+	assert.Contains(t, messageTypes(drainMessages(t, bob)), models.MessageTypeUserLeft)
+	assert.Nil(t, h.lookupResumable(alice.resumeToken(), alice.UserID, alice.MeetingID()))
+}
+
+func TestWithSeq(t *testing.T) {
+	assert.Equal(t, `{"seq":7,"type":8}`, string(withSeq([]byte(`{"type":8}`), 7)))
+	assert.Equal(t, `{"seq":7}`, string(withSeq([]byte(`{}`), 7)))
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,13 +31,30 @@ type WebSocketHandler struct {
 	rooms            map[uint]*Room     // meetingID -> Room
 	mutex            sync.RWMutex
 	pingTicker       *time.Ticker
+	pumps            sync.WaitGroup          // 连接读写协程，Stop 等待其退出
+	aiResults        *resultDeduper          // 已推送的 AI 流式结果 ID
+	serverAILive     bool                    // 服务端 AI Live 模式（不依赖浏览器领导者）
+	events           EventPublisher          // 跨服务事件发布（通知媒体服务开启服务端 AI Live、执行媒体管控）
+	roles            RoleResolver            // 参与者角色查询（媒体管控权限）
+	settings         MeetingSettingsResolver // 会议设置查询（屏幕共享模式）
+	cluster          *Cluster                // 多实例部署的集群协调（nil 为单节点模式）
+	resume           resumeOptions           // 断线续传配置
+	resumeTokens     map[string]*resumableSession
+	policy           messagePolicy               // 上行消息限流与校验
+	admissions       AdmissionStore              // 等候室准入状态
+	lobby            map[uint]map[string]*Client // meetingID -> sessionID -> 等候中的连接
+	controls         MeetingControlStore         // 主持控制的持久化与审计
+	reactionWindow   time.Duration               // 表情反应聚合时间窗（0 为默认值）
+	polls            PollStore                   // 投票的持久化
+	questions        QuestionStore               // 问答的持久化
+	chat             ChatStore                   // 聊天消息的持久化
+	chatBacklog      int                         // 入会时推送的最近聊天条数
 }
 
 // Client WebSocket客户端
 type Client struct {
 	ID           string
 	UserID       uint
-	MeetingID    uint
 	PeerID       string
 	Username     string
 	Conn         *websocket.Conn
@@ -45,7 +63,42 @@ type Client struct {
 	Handler      *WebSocketHandler
 	LastPing     time.Time
 	JoinedAt     time.Time
+	Binary       bool              // 协商为 protobuf 子协议，下行消息以二进制帧发送
+	session      *resumableSession // 断线续传状态（nil 表示不可续传）
+	limiter      *sessionLimiter   // 上行消息限流状态（首条消息时创建）
+	waiting      bool              // 在等候室中等待准入（由 Handler.mutex 保护）
+	meetingID    atomic.Uint64     // 所在会议房间（迁移到分组时由其他协程修改，经 MeetingID/setMeetingID 访问）
+	breakoutOf   atomic.Uint64     // 在分组房间中时为所属主会议，回到主会议后为 0
 	mutex        sync.Mutex
+	sendMu       sync.RWMutex // 发送方持读锁写入 Send/PrioritySend，closeSend 持写锁关闭
+	sendClosed   bool
+}
+
+// MeetingID 连接当前所在的会议房间
+func (c *Client) MeetingID() uint {
+	return uint(c.meetingID.Load())
+}
+
+// setMeetingID 修改连接所在的会议房间（迁移时调用方同时持有 Handler.mutex）
+func (c *Client) setMeetingID(meetingID uint) {
+	c.meetingID.Store(uint64(meetingID))
+}
+
+// parentMeetingID 连接在分组房间中时所属的主会议，否则为 0
+func (c *Client) parentMeetingID() uint {
+	return uint(c.breakoutOf.Load())
+}
+
+// closeSend 关闭发送通道，等待进行中的发送完成；重复调用无副作用
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.sendClosed {
+		return
+	}
+	c.sendClosed = true
+	close(c.Send)
+	close(c.PrioritySend)
 }
 
 // Room 会议房间
@@ -54,7 +107,13 @@ type Room struct {
 	Clients      map[string]*Client // sessionID -> Client
 	CreatedAt    time.Time
 	LastActivity time.Time
-	AILive       models.AILiveStatusMessage // 会议内 AI Live 共享状态（由信令服务协调）
+	AILive       models.AILiveStatusMessage             // 会议内 AI Live 共享状态（由信令服务协调）
+	aiLiveLines  *aiLiveLineCache                       // 服务端 AI Live 最近的结果行
+	moderation   map[string]models.MediaModerationState // "<userID>/<mediaType>" -> 强制静音状态
+	screenShares map[uint]*activeScreenShare            // userID -> 正在进行的屏幕共享
+	hands        []*raisedHand                          // 举手队列（按举手先后；集群模式保存在 Redis）
+	feedback     map[uint]*activeFeedback               // userID -> 非语言反馈（集群模式保存在 Redis）
+	reactions    *reactionBatch                         // 当前时间窗内待广播的表情反应（聚合模式）
 	mutex        sync.RWMutex
 }
 
@@ -75,9 +134,24 @@ func NewWebSocketHandler(signalingService *services.SignalingService) *WebSocket
 			CheckOrigin: func(r *http.Request) bool {
 				return cfg.WebSocket.CheckOrigin
 			},
+			Subprotocols: signalingSubprotocols,
 		},
-		clients: make(map[string]*Client),
-		rooms:   make(map[uint]*Room),
+		clients:      make(map[string]*Client),
+		rooms:        make(map[uint]*Room),
+		aiResults:    newResultDeduper(aiResultDedupCapacity),
+		serverAILive: serverAILiveConfigured(),
+		roles:        signalingService,
+		settings:     signalingService,
+		resume:       resumeOptionsFromConfig(cfg.Signaling.Session),
+		resumeTokens: make(map[string]*resumableSession),
+		policy:       messagePolicyFromConfig(cfg.Signaling.MessagePolicy),
+		admissions:   signalingService,
+		lobby:        make(map[uint]map[string]*Client),
+		controls:     signalingService,
+		polls:        signalingService,
+		questions:    signalingService,
+		chat:         signalingService,
+		chatBacklog:  cfg.Chat.HistoryOnJoin,
 	}
 
 	// 启动心跳检查
@@ -146,6 +220,7 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	userIDStr := c.Query("user_id")
 	meetingIDStr := c.Query("meeting_id")
 	peerID := c.Query("peer_id")
+	resumeToken := c.Query("resume_token")
 
 	if userIDStr == "" || meetingIDStr == "" || peerID == "" {
 		response.Error(c, http.StatusBadRequest, "Missing required parameters")
@@ -173,15 +248,40 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
-	username := fmt.Sprintf("user_%d", userID)
-	if userInfo, err := h.signalingService.GetUserInfo(uint(userID)); err != nil {
-		logger.Warn("Failed to load user info for room snapshot",
-			logger.Uint("user_id", uint(userID)),
-			logger.Err(err))
-	} else {
-		username = userInfo.Username
+	// 断线续传：携带 resume_token 与最后连续收到的 last_seq 重连（优先通道的消息可能先于较小序号到达）
+	var resumable *resumableSession
+	var lastSeq uint64
+	if resumeToken != "" && h.resume.enabled() {
+		lastSeq, err = strconv.ParseUint(c.DefaultQuery("last_seq", "0"), 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid last_seq")
+			return
+		}
+		resumable = h.lookupResumable(resumeToken, uint(userID), uint(meetingID))
+		if resumable == nil && h.cluster != nil {
+			h.takeOverRemoteSession(resumeToken, uint(userID), uint(meetingID))
+		}
+	}
+
+	if resumable != nil {
+		conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			logger.Error("Failed to upgrade to WebSocket", logger.Err(err))
+			return
+		}
+		client, err := h.resumeClient(resumable, conn, lastSeq)
+		if err == nil {
+			h.startPumps(client)
+			return
+		}
+		// 无法续传时原会话已注销，按新会话加入，客户端收到新的 RoomInfo 后重建状态
+		logger.Warn("Session resume rejected; starting new session", logger.Uint("user_id", uint(userID)), logger.Err(err))
+		h.startSession(conn, uint(userID), uint(meetingID), peerID, h.lookupUsername(uint(userID)))
+		return
 	}
 
+	username := h.lookupUsername(uint(userID))
+
 	// 升级为WebSocket连接
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -189,13 +289,29 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	h.startSession(conn, uint(userID), uint(meetingID), peerID, username)
+}
+
+func (h *WebSocketHandler) lookupUsername(userID uint) string {
+	username := fmt.Sprintf("user_%d", userID)
+	if userInfo, err := h.signalingService.GetUserInfo(userID); err != nil {
+		logger.Warn("Failed to load user info for room snapshot",
+			logger.Uint("user_id", uint(userID)),
+			logger.Err(err))
+	} else {
+		username = userInfo.Username
+	}
+	return username
+}
+
+// startSession 为新连接创建会话并启动读写协程
+func (h *WebSocketHandler) startSession(conn *websocket.Conn, userID, meetingID uint, peerID, username string) {
 	// 创建客户端
 	sessionID := fmt.Sprintf("%d_%d_%s_%d", userID, meetingID, peerID, time.Now().UnixNano())
 	now := time.Now()
 	client := &Client{
 		ID:           sessionID,
-		UserID:       uint(userID),
-		MeetingID:    uint(meetingID),
+		UserID:       userID,
 		PeerID:       peerID,
 		Username:     username,
 		Conn:         conn,
@@ -204,95 +320,221 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		Handler:      h,
 		LastPing:     now,
 		JoinedAt:     now,
+		Binary:       conn.Subprotocol() == SubprotocolProtobufV2,
 	}
+	client.setMeetingID(meetingID)
 
-	// 注册客户端
-	h.registerClient(client)
+	// 注册客户端（需要审批的用户进入等候室）
+	waiting, err := h.needsAdmission(userID, meetingID)
+	if err != nil {
+		// 准入状态未知时不能放行，让客户端稍后重试
+		logger.Warn("Failed to load participant status; refusing connection",
+			logger.Uint("user_id", userID),
+			logger.Uint("meeting_id", meetingID),
+			logger.Err(err))
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "participant status unavailable"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
+	if waiting {
+		h.enterLobby(client)
+	} else {
+		h.registerClient(client)
+	}
 
 	// 启动客户端处理协程
-	go client.writePump()
-	go client.readPump()
+	h.startPumps(client)
 
 	logger.Info(fmt.Sprintf("WebSocket client connected: %s", sessionID))
 }
 
 // registerClient 注册客户端
 func (h *WebSocketHandler) registerClient(client *Client) {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
+	// 集群模式先登记成员，其他节点随后的 RoomInfo 即可看到该会话
+	if h.cluster != nil {
+		if err := h.cluster.join(client); err != nil {
+			logger.Error("Failed to join cluster room", logger.Uint("meeting_id", client.MeetingID()), logger.Err(err))
+		}
+	}
 
+	h.mutex.Lock()
 	// 添加到客户端列表
 	h.clients[client.ID] = client
+	h.attachSession(client)
+	h.addToRoomLocked(client)
+	h.mutex.Unlock()
 
-	// 添加到房间
-	room, exists := h.rooms[client.MeetingID]
+	// 创建信令会话
+	if h.signalingService == nil {
+		return
+	}
+	if err := h.signalingService.CreateSession(client.ID, client.UserID, client.MeetingID(), client.PeerID); err != nil {
+		logger.Error("Failed to create signaling session", logger.Err(err))
+	}
+}
+
+// addToRoomLocked 把连接加入会议房间（调用方需持有 h.mutex）
+func (h *WebSocketHandler) addToRoomLocked(client *Client) {
+	room, exists := h.rooms[client.MeetingID()]
 	if !exists {
 		room = &Room{
-			ID:           client.MeetingID,
+			ID:           client.MeetingID(),
 			Clients:      make(map[string]*Client),
 			CreatedAt:    time.Now(),
 			LastActivity: time.Now(),
 		}
-		h.rooms[client.MeetingID] = room
+		if h.serverAILive {
+			room.AILive = serverAILiveStatus()
+			go h.publishAILiveControl(client.MeetingID(), true)
+		}
+		h.rooms[client.MeetingID()] = room
 	}
 
 	room.mutex.Lock()
 	room.Clients[client.ID] = client
 	room.LastActivity = time.Now()
 	room.mutex.Unlock()
-
-	// 创建信令会话
-	if err := h.signalingService.CreateSession(client.ID, client.UserID, client.MeetingID, client.PeerID); err != nil {
-		logger.Error("Failed to create signaling session", logger.Err(err))
-	}
 }
 
 // unregisterClient 注销客户端
 func (h *WebSocketHandler) unregisterClient(client *Client) {
 	h.mutex.Lock()
-	if _, exists := h.clients[client.ID]; exists {
-		delete(h.clients, client.ID)
+	// 已注销，或会话已被续传的新连接接管
+	if current, exists := h.clients[client.ID]; !exists || current != client {
+		h.mutex.Unlock()
+		return
 	}
-
-	var shouldCleanup bool
-	var shouldBroadcastAILive bool
-	if room, exists := h.rooms[client.MeetingID]; exists {
-		room.mutex.Lock()
-		delete(room.Clients, client.ID)
-		room.LastActivity = time.Now()
-		if room.AILive.LeaderSessionID == client.ID {
-			room.AILive = models.AILiveStatusMessage{
-				Enabled:   false,
-				UpdatedAt: time.Now(),
-			}
-			shouldBroadcastAILive = true
-		}
-		shouldCleanup = len(room.Clients) == 0
-		room.mutex.Unlock()
-		if shouldCleanup {
-			delete(h.rooms, client.MeetingID)
-		}
+	delete(h.clients, client.ID)
+	h.detachSessionLocked(client)
+	if client.waiting {
+		h.unregisterWaitingLocked(client)
+		return
 	}
+	departure := h.leaveRoomLocked(client)
 	h.mutex.Unlock()
 
+	h.leaveClusterRoom(client.ID, departure)
+
 	// 关闭发送通道
-	close(client.Send)
-	close(client.PrioritySend)
+	client.closeSend()
 
 	// 更新会话状态
-	if err := h.signalingService.DisconnectSession(client.ID); err != nil {
-		logger.Error("Failed to disconnect signaling session", logger.Err(err))
+	if h.signalingService != nil {
+		if err := h.signalingService.DisconnectSession(client.ID); err != nil {
+			logger.Error("Failed to disconnect signaling session", logger.Err(err))
+		}
 	}
 
 	// 通知其他用户有用户离开
-	h.broadcastUserLeft(client)
-	if shouldBroadcastAILive && !shouldCleanup {
-		h.broadcastAILiveStatus(client.MeetingID)
-	}
+	h.announceDeparture(client, departure)
 
 	logger.Info(fmt.Sprintf("WebSocket client disconnected: %s", client.ID))
 }
 
+// roomDeparture 连接离开会议房间时释放的房间状态（断开连接与迁移到分组房间共用）
+type roomDeparture struct {
+	meetingID       uint
+	userID          uint
+	localEmpty      bool // 本节点房间已空并被删除
+	roomEmpty       bool // 整个会议已无连接（集群模式以 Redis 为准）
+	aiLiveReleased  bool
+	endedShare      models.ScreenShareState
+	shareEnded      bool
+	handUserID      uint
+	handLowered     bool
+	feedbackUserID  uint
+	feedbackCleared bool
+}
+
+// leaveRoomLocked 把连接移出当前会议房间并释放其屏幕共享、举手与反馈（调用方需持有 h.mutex）
+func (h *WebSocketHandler) leaveRoomLocked(client *Client) *roomDeparture {
+	departure := &roomDeparture{meetingID: client.MeetingID(), userID: client.UserID}
+	room, exists := h.rooms[client.MeetingID()]
+	if !exists {
+		return departure
+	}
+
+	room.mutex.Lock()
+	delete(room.Clients, client.ID)
+	room.LastActivity = time.Now()
+	departure.endedShare, departure.shareEnded = room.releaseScreenShareLocked(client.ID)
+	departure.handUserID, departure.handLowered = room.releaseHandLocked(client.ID)
+	departure.feedbackUserID, departure.feedbackCleared = room.releaseFeedbackLocked(client.ID)
+	if h.cluster == nil && room.AILive.LeaderSessionID == client.ID {
+		room.AILive = models.AILiveStatusMessage{
+			Enabled:   false,
+			UpdatedAt: time.Now(),
+		}
+		departure.aiLiveReleased = true
+	}
+	departure.localEmpty = len(room.Clients) == 0
+	room.mutex.Unlock()
+	if departure.localEmpty {
+		delete(h.rooms, client.MeetingID())
+	}
+	return departure
+}
+
+// leaveClusterRoom 集群模式下登记离开；房间是否为空、AI Live 领导者、举手与反馈以 Redis 为准，整个房间为空时清除房间状态
+func (h *WebSocketHandler) leaveClusterRoom(sessionID string, departure *roomDeparture) {
+	meetingID := departure.meetingID
+	departure.roomEmpty = departure.localEmpty
+	if h.cluster != nil {
+		if departure.shareEnded {
+			if _, _, err := h.cluster.removeScreenShare(meetingID, departure.endedShare.UserID, sessionID); err != nil {
+				logger.Error("Failed to release cluster screen share", logger.Uint("meeting_id", meetingID), logger.Err(err))
+			}
+		}
+		lowered, err := h.cluster.lowerHand(meetingID, departure.userID, sessionID)
+		if err != nil {
+			logger.Error("Failed to release cluster raised hand", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+		departure.handUserID, departure.handLowered = departure.userID, lowered
+		cleared, err := h.cluster.releaseFeedback(meetingID, departure.userID, sessionID)
+		if err != nil {
+			logger.Error("Failed to release cluster feedback", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+		departure.feedbackUserID, departure.feedbackCleared = departure.userID, cleared
+		if remaining, err := h.cluster.leave(meetingID, sessionID); err != nil {
+			logger.Error("Failed to leave cluster room", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		} else {
+			departure.roomEmpty = remaining == 0
+		}
+		if departure.roomEmpty {
+			if err := h.cluster.clearRoomState(meetingID); err != nil {
+				logger.Error("Failed to clear cluster room state", logger.Uint("meeting_id", meetingID), logger.Err(err))
+			}
+		}
+		if !h.serverAILive {
+			released, err := h.cluster.releaseAILive(meetingID, sessionID)
+			if err != nil {
+				logger.Error("Failed to release cluster AI Live", logger.Uint("meeting_id", meetingID), logger.Err(err))
+			}
+			departure.aiLiveReleased = released
+		}
+	}
+	if departure.localEmpty && departure.roomEmpty && h.serverAILive {
+		go h.publishAILiveControl(meetingID, false)
+	}
+}
+
+// announceDeparture 通知原房间：用户离开、AI Live 领导者释放、屏幕共享结束、举手与反馈清除
+func (h *WebSocketHandler) announceDeparture(client *Client, departure *roomDeparture) {
+	meetingID := departure.meetingID
+	h.broadcastUserLeft(client, meetingID)
+	if departure.aiLiveReleased && !departure.roomEmpty {
+		h.broadcastAILiveStatus(meetingID)
+	}
+	if departure.shareEnded {
+		h.endScreenShareOnLeave(meetingID, departure.endedShare, !departure.localEmpty)
+	}
+	if !departure.roomEmpty {
+		h.releaseNonverbalOnLeave(meetingID, departure.handUserID, departure.handLowered, departure.feedbackUserID, departure.feedbackCleared)
+	}
+}
+
 // broadcastUserJoined 广播用户加入消息
 func (h *WebSocketHandler) broadcastUserJoined(client *Client) {
 	username := client.Username
@@ -310,26 +552,26 @@ func (h *WebSocketHandler) broadcastUserJoined(client *Client) {
 		UserID:    client.UserID,
 		Username:  username,
 		PeerID:    client.PeerID,
-		MeetingID: client.MeetingID,
+		MeetingID: client.MeetingID(),
 	}
 
 	message := &models.WebSocketMessage{
 		ID:         fmt.Sprintf("join_%d_%d", client.UserID, time.Now().Unix()),
 		Type:       models.MessageTypeUserJoined,
 		FromUserID: client.UserID,
-		MeetingID:  client.MeetingID,
+		MeetingID:  client.MeetingID(),
 		SessionID:  client.ID,
 		PeerID:     client.PeerID,
 		Payload:    notification,
 		Timestamp:  time.Now(),
 	}
 
-	h.broadcastToRoom(client.MeetingID, message, client.ID)
-	h.broadcastRoomInfo(client.MeetingID)
+	h.broadcastToRoom(client.MeetingID(), message, client.ID)
+	h.broadcastRoomInfo(client.MeetingID())
 }
 
-// broadcastUserLeft 广播用户离开消息
-func (h *WebSocketHandler) broadcastUserLeft(client *Client) {
+// broadcastUserLeft 广播用户离开 meetingID 房间的消息（迁移到分组时 client.MeetingID() 已是新房间）
+func (h *WebSocketHandler) broadcastUserLeft(client *Client, meetingID uint) {
 	username := client.Username
 	if username == "" {
 		if userInfo, err := h.signalingService.GetUserInfo(client.UserID); err != nil {
@@ -345,26 +587,40 @@ func (h *WebSocketHandler) broadcastUserLeft(client *Client) {
 		UserID:    client.UserID,
 		Username:  username,
 		PeerID:    client.PeerID,
-		MeetingID: client.MeetingID,
+		MeetingID: meetingID,
 	}
 
 	message := &models.WebSocketMessage{
 		ID:         fmt.Sprintf("leave_%d_%d", client.UserID, time.Now().Unix()),
 		Type:       models.MessageTypeUserLeft,
 		FromUserID: client.UserID,
-		MeetingID:  client.MeetingID,
+		MeetingID:  meetingID,
 		SessionID:  client.ID,
 		PeerID:     client.PeerID,
 		Payload:    notification,
 		Timestamp:  time.Now(),
 	}
 
-	h.broadcastToRoom(client.MeetingID, message, client.ID)
-	h.broadcastRoomInfo(client.MeetingID)
+	h.broadcastToRoom(meetingID, message, client.ID)
+	h.broadcastRoomInfo(meetingID)
 }
 
-// broadcastToRoom 向房间广播消息
+// broadcastToRoom 向房间广播消息（集群模式下同时扇出到其他节点）
 func (h *WebSocketHandler) broadcastToRoom(meetingID uint, message *models.WebSocketMessage, excludeSessionID string) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Failed to marshal message", logger.Err(err))
+		return
+	}
+
+	h.deliverToRoom(meetingID, data, int(message.Type), excludeSessionID)
+	if h.cluster != nil {
+		h.cluster.publishRoomBroadcast(meetingID, data, int(message.Type), excludeSessionID)
+	}
+}
+
+// deliverToRoom 投递给本节点上该房间的连接
+func (h *WebSocketHandler) deliverToRoom(meetingID uint, data []byte, messageType int, excludeSessionID string) {
 	h.mutex.RLock()
 	room, exists := h.rooms[meetingID]
 	h.mutex.RUnlock()
@@ -373,23 +629,17 @@ func (h *WebSocketHandler) broadcastToRoom(meetingID uint, message *models.WebSo
 		return
 	}
 
-	data, err := json.Marshal(message)
-	if err != nil {
-		logger.Error("Failed to marshal message", logger.Err(err))
-		return
-	}
-
 	room.mutex.RLock()
 	defer room.mutex.RUnlock()
 
 	for sessionID, client := range room.Clients {
 		if sessionID != excludeSessionID {
-			if !client.enqueue(data, fmt.Sprintf("broadcast:%d", message.Type)) {
+			if !client.enqueue(data, fmt.Sprintf("broadcast:%d", messageType)) {
 				logger.Warn("Broadcast send failed; unregistering slow client",
 					logger.String("target_session", client.ID),
 					logger.Uint("target_user", client.UserID),
 					logger.Uint("meeting_id", meetingID),
-					logger.Int("message_type", int(message.Type)),
+					logger.Int("message_type", messageType),
 				)
 				go h.unregisterClient(client)
 			}
@@ -397,7 +647,53 @@ func (h *WebSocketHandler) broadcastToRoom(meetingID uint, message *models.WebSo
 	}
 }
 
+// broadcastToModerators 发给房间内的主办人/主持人（集群模式下其他节点上的同样收到）
+func (h *WebSocketHandler) broadcastToModerators(meetingID uint, message *models.WebSocketMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Failed to marshal message", logger.Err(err))
+		return
+	}
+
+	h.deliverToModerators(meetingID, data, int(message.Type))
+	if h.cluster != nil {
+		h.cluster.publishModeratorBroadcast(meetingID, data, int(message.Type))
+	}
+}
+
+// deliverToModerators 投递给本节点上该房间的主办人/主持人
+func (h *WebSocketHandler) deliverToModerators(meetingID uint, data []byte, messageType int) {
+	canModerate := make(map[uint]bool)
+	for _, client := range h.roomClients(meetingID) {
+		allowed, checked := canModerate[client.UserID]
+		if !checked {
+			allowed = h.isModerator(client.UserID, meetingID)
+			canModerate[client.UserID] = allowed
+		}
+		if !allowed {
+			continue
+		}
+		if !client.enqueue(data, fmt.Sprintf("moderators:%d", messageType)) {
+			logger.Warn("Moderator send failed; unregistering slow client",
+				logger.String("target_session", client.ID),
+				logger.Uint("target_user", client.UserID),
+				logger.Uint("meeting_id", meetingID),
+				logger.Int("message_type", messageType),
+			)
+			go h.unregisterClient(client)
+		}
+	}
+}
+
 func (h *WebSocketHandler) broadcastRoomInfo(meetingID uint) {
+	h.sendRoomInfoToLocalClients(meetingID)
+	if h.cluster != nil {
+		h.cluster.publishRoomInfo(meetingID)
+	}
+}
+
+// sendRoomInfoToLocalClients 向本节点上该房间的连接重发 RoomInfo
+func (h *WebSocketHandler) sendRoomInfoToLocalClients(meetingID uint) {
 	h.mutex.RLock()
 	room, exists := h.rooms[meetingID]
 	h.mutex.RUnlock()
@@ -439,7 +735,7 @@ func (h *WebSocketHandler) sendToClient(sessionID string, message *models.WebSoc
 		logger.Warn("Direct send failed; unregistering slow client",
 			logger.String("target_session", client.ID),
 			logger.Uint("target_user", client.UserID),
-			logger.Uint("meeting_id", client.MeetingID),
+			logger.Uint("meeting_id", client.MeetingID()),
 			logger.Int("message_type", int(message.Type)),
 		)
 		go h.unregisterClient(client)
@@ -471,6 +767,9 @@ func (h *WebSocketHandler) checkHeartbeat() {
 	h.mutex.RUnlock()
 
 	for _, client := range clients {
+		if client.suspended() {
+			continue
+		}
 		if time.Since(client.LastPing) > timeout {
 			logger.Warn(fmt.Sprintf("Client heartbeat timeout: %s", client.ID))
 			go h.unregisterClient(client)
@@ -531,6 +830,20 @@ func (h *WebSocketHandler) GetRoomStats() map[uint]int {
 }
 
 func (h *WebSocketHandler) collectRoomParticipants(meetingID uint) []models.RoomParticipant {
+	if h.cluster != nil {
+		members, err := h.cluster.members(meetingID)
+		if err == nil {
+			participants := make([]models.RoomParticipant, 0, len(members))
+			for _, member := range members {
+				participants = append(participants, member.RoomParticipant)
+			}
+			return participants
+		}
+		logger.Warn("Failed to load cluster room members; using local sessions",
+			logger.Uint("meeting_id", meetingID),
+			logger.Err(err))
+	}
+
 	h.mutex.RLock()
 	room, exists := h.rooms[meetingID]
 	h.mutex.RUnlock()
@@ -571,17 +884,41 @@ func (h *WebSocketHandler) Stop() {
 
 	// 关闭所有客户端连接
 	h.mutex.Lock()
-	defer h.mutex.Unlock()
-
+	clients := make([]*Client, 0, len(h.clients))
 	for _, client := range h.clients {
 		client.Conn.Close()
+		clients = append(clients, client)
+	}
+	h.mutex.Unlock()
+
+	// 挂起等待续传的会话不会再被接管，直接注销，读写协程随之退出
+	for _, client := range clients {
+		h.unregisterClient(client)
+	}
+	h.pumps.Wait()
+
+	if h.cluster != nil {
+		h.cluster.shutdown()
 	}
 }
 
+// startPumps 启动连接的读写协程
+func (h *WebSocketHandler) startPumps(client *Client) {
+	h.pumps.Add(2)
+	go func() {
+		defer h.pumps.Done()
+		client.writePump()
+	}()
+	go func() {
+		defer h.pumps.Done()
+		client.readPump()
+	}()
+}
+
 // readPump 读取消息泵
 func (c *Client) readPump() {
 	defer func() {
-		c.Handler.unregisterClient(c)
+		c.Handler.disconnectClient(c)
 		c.Conn.Close()
 	}()
 
@@ -618,44 +955,83 @@ func (c *Client) readPump() {
 			break
 		}
 
-		// 只处理文本消息
-		if msgType != websocket.TextMessage {
-			continue
-		}
-
-		// 解析消息
+		// 文本帧为 JSON v1，二进制帧为 protobuf v2（与协商的下行编码无关，均可接收）
 		var message models.WebSocketMessage
-		if err := json.Unmarshal(messageData, &message); err != nil {
-			preview := string(messageData)
-			if len(preview) > 256 {
-				preview = preview[:256] + "..."
+		switch msgType {
+		case websocket.TextMessage:
+			if err := json.Unmarshal(messageData, &message); err != nil {
+				preview := string(messageData)
+				if len(preview) > 256 {
+					preview = preview[:256] + "..."
+				}
+				logger.Error("Failed to unmarshal WebSocket message",
+					logger.Err(err),
+					logger.String("session", c.ID),
+					logger.Uint("user_id", c.UserID),
+					logger.String("payload_preview", preview),
+				)
+				c.sendError("Invalid message format", err.Error())
+				continue
 			}
-			logger.Error("Failed to unmarshal WebSocket message",
-				logger.Err(err),
-				logger.String("session", c.ID),
-				logger.Uint("user_id", c.UserID),
-				logger.String("payload_preview", preview),
-			)
-			c.sendError("Invalid message format", err.Error())
+		case websocket.BinaryMessage:
+			decoded, err := decodeProtobufFrame(messageData)
+			if err != nil {
+				logger.Error("Failed to decode binary signaling frame",
+					logger.Err(err),
+					logger.String("session", c.ID),
+					logger.Uint("user_id", c.UserID),
+				)
+				c.sendError("Invalid message format", err.Error())
+				continue
+			}
+			message = *decoded
+		default:
 			continue
 		}
 
 		// 设置消息元数据
 		message.FromUserID = c.UserID
-		message.MeetingID = c.MeetingID
+		message.MeetingID = c.MeetingID()
 		message.SessionID = c.ID
 		message.Timestamp = time.Now()
+		message.Seq = 0
+
+		switch c.admitMessage(&message, len(messageData)) {
+		case policyDrop:
+			continue
+		case policyDisconnect:
+			// 策略断开不保留续传
+			c.Handler.unregisterClient(c)
+			deadline := time.Now().Add(time.Duration(cfg.WebSocket.WriteWait) * time.Second)
+			_ = c.Conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "message policy violation"), deadline)
+			return
+		}
 
 		// 处理消息
 		c.handleMessage(&message)
 	}
 }
 
-// writeBytes 写入文本消息
+// writeBytes 写入消息（v2 客户端转码为 protobuf 二进制帧）
 func (c *Client) writeBytes(message []byte) error {
+	frameType := websocket.TextMessage
+	if c.Binary {
+		encoded, err := encodeProtobufFrame(message)
+		if err != nil {
+			// 单条消息无法转码不影响连接
+			logger.Error("Failed to encode binary signaling frame", logger.Err(err),
+				logger.String("session", c.ID),
+				logger.Uint("user_id", c.UserID),
+			)
+			return nil
+		}
+		message, frameType = encoded, websocket.BinaryMessage
+	}
+
 	cfg := config.GlobalConfig
 	c.Conn.SetWriteDeadline(time.Now().Add(time.Duration(cfg.WebSocket.WriteWait) * time.Second))
-	if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+	if err := c.Conn.WriteMessage(frameType, message); err != nil {
 		logger.Error("Failed to write WebSocket message", logger.Err(err),
 			logger.String("session", c.ID),
 			logger.Uint("user_id", c.UserID),
@@ -688,8 +1064,29 @@ func (c *Client) enqueue(data []byte, context string) (ok bool) {
 		}
 	}()
 
+	target := c
+	if s := c.session; s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.closed {
+			// 已注销，发送通道即将关闭
+			return false
+		}
+		data = s.recordLocked(data)
+		if s.suspended {
+			// 断线等待续传，续传时从重放缓冲补发
+			return true
+		}
+		target = s.current
+	}
+
+	target.sendMu.RLock()
+	defer target.sendMu.RUnlock()
+	if target.sendClosed {
+		return false
+	}
 	select {
-	case c.Send <- data:
+	case target.Send <- data:
 		return true
 	default:
 		logger.Warn("Send buffer full",
@@ -713,6 +1110,37 @@ func (c *Client) enqueuePriority(data []byte, context string) (ok bool) {
 		}
 	}()
 
+	if s := c.session; s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.closed {
+			return false
+		}
+		data = s.recordLocked(data)
+		if s.suspended {
+			// 与 enqueue 相同，挂起期间只进重放缓冲，续传时按序补发
+			return true
+		}
+		// 持有会话锁时不能阻塞等待（房间广播持锁调用 enqueue），缓冲满即放弃
+		target := s.current
+		target.sendMu.RLock()
+		defer target.sendMu.RUnlock()
+		if target.sendClosed {
+			return false
+		}
+		select {
+		case target.PrioritySend <- data:
+			return true
+		default:
+			logger.Error("Priority send buffer full",
+				logger.String("session", c.ID),
+				logger.Uint("user_id", c.UserID),
+				logger.String("context", context),
+			)
+			return false
+		}
+	}
+
 	timeout := time.Duration(config.GlobalConfig.WebSocket.WriteWait) * time.Second
 	if timeout <= 0 {
 		timeout = 5 * time.Second
@@ -720,6 +1148,11 @@ func (c *Client) enqueuePriority(data []byte, context string) (ok bool) {
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
+	c.sendMu.RLock()
+	defer c.sendMu.RUnlock()
+	if c.sendClosed {
+		return false
+	}
 	select {
 	case c.PrioritySend <- data:
 		return true
@@ -796,6 +1229,10 @@ func (c *Client) writePump() {
 // handleMessage 处理接收到的消息
 func (c *Client) handleMessage(message *models.WebSocketMessage) {
 	logger.Info("Received message", logger.Uint("meeting_id", message.MeetingID), logger.Uint("from", message.FromUserID), logger.Int("type", int(message.Type)))
+	if c.Handler.inLobby(c) {
+		c.handleWaitingMessage(message)
+		return
+	}
 	switch message.Type {
 	case models.MessageTypeOffer:
 		c.handleOffer(message)
@@ -817,6 +1254,24 @@ func (c *Client) handleMessage(message *models.WebSocketMessage) {
 		c.handleAILiveClaim(message)
 	case models.MessageTypeAILiveResult:
 		c.handleAILiveResult(message)
+	case models.MessageTypeModerateMedia:
+		c.handleModerateMedia(message)
+	case models.MessageTypeScreenShare:
+		c.handleScreenShare(message)
+	case models.MessageTypeLobbyAction:
+		c.handleLobbyAction(message)
+	case models.MessageTypeHostControl:
+		c.handleHostControl(message)
+	case models.MessageTypeRaiseHand:
+		c.handleRaiseHand(message)
+	case models.MessageTypeReaction:
+		c.handleReaction(message)
+	case models.MessageTypeFeedback:
+		c.handleFeedback(message)
+	case models.MessageTypePoll:
+		c.handlePoll(message)
+	case models.MessageTypeQuestion:
+		c.handleQuestion(message)
 	default:
 		logger.Warn(fmt.Sprintf("Unknown message type: %d", message.Type))
 		c.sendError("Unknown message type", fmt.Sprintf("Type: %d", message.Type))
@@ -879,6 +1334,8 @@ func (c *Client) handleJoinRoom(message *models.WebSocketMessage) {
 	// 发送房间信息
 	logger.Info(fmt.Sprintf("Sending room info to session %s", c.ID))
 	c.sendRoomInfo()
+	c.sendLobbySnapshot()
+	c.sendChatHistory()
 
 	// 通知其他用户新成员加入
 	c.Handler.broadcastUserJoined(c)
@@ -890,21 +1347,19 @@ func (c *Client) handleLeaveRoom(message *models.WebSocketMessage) {
 	go c.Handler.unregisterClient(c)
 }
 
-// handleChat 处理聊天消息
-func (c *Client) handleChat(message *models.WebSocketMessage) {
-	// 广播聊天消息到房间
-	c.Handler.broadcastToRoom(c.MeetingID, message, "")
-
-	// 记录消息
-	if err := c.Handler.signalingService.SaveMessage(message); err != nil {
-		logger.Error("Failed to save chat message", logger.Err(err))
-	}
-}
-
 // handleMediaControl 处理媒体控制
 func (c *Client) handleMediaControl(message *models.WebSocketMessage) {
+	// 被主持人强制静音的媒体不允许自行恢复
+	var control models.MediaControlMessage
+	if err := decodePayload(message.Payload, &control); err == nil {
+		if mediaType, ok := mediaControlUnmutes(control); ok && c.Handler.mediaModerated(c.MeetingID(), c.UserID, mediaType) {
+			c.sendError("Media control denied", fmt.Sprintf("%s is muted by the host", mediaType))
+			return
+		}
+	}
+
 	// 广播媒体控制消息到房间
-	c.Handler.broadcastToRoom(c.MeetingID, message, c.ID)
+	c.Handler.broadcastToRoom(c.MeetingID(), message, c.ID)
 }
 
 // handlePing 处理心跳
@@ -916,7 +1371,7 @@ func (c *Client) handlePing(message *models.WebSocketMessage) {
 		ID:         fmt.Sprintf("pong_%d", time.Now().Unix()),
 		Type:       models.MessageTypePong,
 		FromUserID: c.UserID,
-		MeetingID:  c.MeetingID,
+		MeetingID:  c.MeetingID(),
 		SessionID:  c.ID,
 		Timestamp:  time.Now(),
 	}
@@ -928,16 +1383,24 @@ func (c *Client) handlePing(message *models.WebSocketMessage) {
 }
 
 func (c *Client) handleAILiveClaim(message *models.WebSocketMessage) {
+	if c.Handler.serverAILive {
+		// 服务端模式下不接受浏览器领导者，回送当前状态让客户端进入跟随模式
+		c.Handler.broadcastAILiveStatus(c.MeetingID())
+		return
+	}
+
+	// 未携带 enable 时视为申请
+	var claim struct {
+		Enable *bool `json:"enable"`
+	}
 	enable := true
-	if payload, ok := message.Payload.(map[string]interface{}); ok {
-		if v, ok := payload["enable"].(bool); ok {
-			enable = v
-		}
+	if err := decodePayload(message.Payload, &claim); err == nil && claim.Enable != nil {
+		enable = *claim.Enable
 	}
 
 	h := c.Handler
 	h.mutex.RLock()
-	room := h.rooms[c.MeetingID]
+	room := h.rooms[c.MeetingID()]
 	h.mutex.RUnlock()
 	if room == nil {
 		c.sendError("Room not found", "meeting room not available")
@@ -945,6 +1408,29 @@ func (c *Client) handleAILiveClaim(message *models.WebSocketMessage) {
 	}
 
 	now := time.Now()
+	if h.cluster != nil {
+		// 集群内同一会议只能有一个领导者，由 Redis 原子地裁决
+		var err error
+		if enable {
+			_, err = h.cluster.claimAILive(c.MeetingID(), models.AILiveStatusMessage{
+				Enabled:         true,
+				LeaderUserID:    c.UserID,
+				LeaderSessionID: c.ID,
+				LeaderUsername:  c.Username,
+				UpdatedAt:       now,
+			})
+		} else {
+			_, err = h.cluster.releaseAILive(c.MeetingID(), c.ID)
+		}
+		if err != nil {
+			logger.Error("Failed to update cluster AI Live", logger.Uint("meeting_id", c.MeetingID()), logger.Err(err))
+			c.sendError("AI Live unavailable", "failed to coordinate AI Live leader")
+			return
+		}
+		h.broadcastAILiveStatus(c.MeetingID())
+		return
+	}
+
 	room.mutex.Lock()
 	if enable {
 		// 申请成为领导者：只有在无人占用或自己已是领导者时允许
@@ -966,27 +1452,28 @@ func (c *Client) handleAILiveClaim(message *models.WebSocketMessage) {
 	}
 	room.mutex.Unlock()
 
-	h.broadcastAILiveStatus(c.MeetingID)
+	h.broadcastAILiveStatus(c.MeetingID())
 }
 
 func (c *Client) handleAILiveResult(message *models.WebSocketMessage) {
 	h := c.Handler
 	h.mutex.RLock()
-	room := h.rooms[c.MeetingID]
+	room := h.rooms[c.MeetingID()]
 	h.mutex.RUnlock()
 	if room == nil {
 		return
 	}
 
-	room.mutex.RLock()
-	leaderSession := room.AILive.LeaderSessionID
-	room.mutex.RUnlock()
+	leaderSession := ""
+	if status := h.getAILiveStatus(c.MeetingID()); status != nil {
+		leaderSession = status.LeaderSessionID
+	}
 	if leaderSession != c.ID {
 		c.sendError("AI Live denied", "only AI Live leader can broadcast results")
 		return
 	}
 
-	h.broadcastToRoom(c.MeetingID, message, "")
+	h.broadcastToRoom(c.MeetingID(), message, "")
 }
 
 func (h *WebSocketHandler) broadcastAILiveStatus(meetingID uint) {
@@ -1015,6 +1502,17 @@ func (h *WebSocketHandler) getAILiveStatus(meetingID uint) *models.AILiveStatusM
 		return nil
 	}
 
+	if h.cluster != nil && !h.serverAILive {
+		status, err := h.cluster.aiLiveStatus(meetingID)
+		if err != nil {
+			logger.Error("Failed to load cluster AI Live status", logger.Uint("meeting_id", meetingID), logger.Err(err))
+		}
+		if status.UpdatedAt.IsZero() {
+			status.UpdatedAt = time.Now()
+		}
+		return &status
+	}
+
 	room.mutex.RLock()
 	defer room.mutex.RUnlock()
 
@@ -1027,14 +1525,19 @@ func (h *WebSocketHandler) getAILiveStatus(meetingID uint) *models.AILiveStatusM
 
 // sendError 发送错误消息
 func (c *Client) sendError(message, details string) {
+	c.sendErrorCode(400, message, details)
+}
+
+// sendErrorCode 发送带错误码的错误消息
+func (c *Client) sendErrorCode(code int, message, details string) {
 	errorMsg := &models.WebSocketMessage{
 		ID:         fmt.Sprintf("error_%d", time.Now().Unix()),
 		Type:       models.MessageTypeError,
 		FromUserID: 0, // 系统消息
-		MeetingID:  c.MeetingID,
+		MeetingID:  c.MeetingID(),
 		SessionID:  c.ID,
 		Payload: models.ErrorMessage{
-			Code:    400,
+			Code:    code,
 			Message: message,
 			Details: details,
 		},
@@ -1049,7 +1552,7 @@ func (c *Client) sendError(message, details string) {
 
 // sendRoomInfo 发送房间信息
 func (c *Client) sendRoomInfo() {
-	participants := c.Handler.collectRoomParticipants(c.MeetingID)
+	participants := c.Handler.collectRoomParticipants(c.MeetingID())
 	participantSnapshot := make([]models.RoomParticipant, 0, len(participants))
 	uniqueUsers := make(map[uint]struct{}, len(participants))
 	for _, participant := range participants {
@@ -1072,22 +1575,27 @@ func (c *Client) sendRoomInfo() {
 	}
 
 	roomInfo := models.RoomInfoMessage{
-		MeetingID:        c.MeetingID,
+		MeetingID:        c.MeetingID(),
 		ParticipantCount: participantCount,
 		SessionID:        c.ID,
 		PeerID:           c.PeerID,
 		IceServers:       iceServers,
 		Participants:     participantSnapshot,
-		AILive:           c.Handler.getAILiveStatus(c.MeetingID),
+		AILive:           c.Handler.getAILiveStatus(c.MeetingID()),
+		Moderation:       c.Handler.getModerationStates(c.MeetingID()),
+		ScreenShares:     c.Handler.getScreenShares(c.MeetingID()),
+		RaisedHands:      c.Handler.getRaisedHands(c.MeetingID()),
+		Feedback:         c.Handler.getFeedback(c.MeetingID()),
+		ResumeToken:      c.resumeToken(),
 	}
 
-	logger.Debug("Room info payload", logger.Uint("meeting_id", c.MeetingID), logger.Int("participants", participantCount))
+	logger.Debug("Room info payload", logger.Uint("meeting_id", c.MeetingID()), logger.Int("participants", participantCount))
 
 	message := &models.WebSocketMessage{
 		ID:         fmt.Sprintf("room_info_%d", time.Now().UnixNano()),
 		Type:       models.MessageTypeRoomInfo,
 		FromUserID: 0, // 系统消息
-		MeetingID:  c.MeetingID,
+		MeetingID:  c.MeetingID(),
 		SessionID:  c.ID,
 		PeerID:     c.PeerID,
 		Payload:    roomInfo,
@@ -1099,7 +1607,7 @@ func (c *Client) sendRoomInfo() {
 		logger.Debug("Enqueued room info ack",
 			logger.String("session", c.ID),
 			logger.Uint("user_id", c.UserID),
-			logger.Uint("meeting_id", c.MeetingID),
+			logger.Uint("meeting_id", c.MeetingID()),
 		)
 		return
 	}
@@ -1107,37 +1615,46 @@ func (c *Client) sendRoomInfo() {
 	logger.Error("Priority channel saturated for room info",
 		logger.String("session", c.ID),
 		logger.Uint("user_id", c.UserID),
-		logger.Uint("meeting_id", c.MeetingID),
+		logger.Uint("meeting_id", c.MeetingID()),
 	)
 	if !c.enqueue(data, "room_info_fallback") {
 		logger.Error("Failed to enqueue room info on fallback channel",
 			logger.String("session", c.ID),
 			logger.Uint("user_id", c.UserID),
-			logger.Uint("meeting_id", c.MeetingID),
+			logger.Uint("meeting_id", c.MeetingID()),
 		)
 	}
 }
 
-// forwardToUser 转发消息给指定用户
+// forwardToUser 转发消息给指定用户（集群模式下目标用户在其他节点上的会话同样收到）
 func (h *WebSocketHandler) forwardToUser(userID uint, message *models.WebSocketMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Failed to marshal message for forwarding", logger.Err(err))
+		return
+	}
+
+	h.deliverToUser(message.MeetingID, userID, data, int(message.Type))
+	if h.cluster != nil {
+		h.cluster.publishUserForward(message.MeetingID, userID, data, int(message.Type))
+	}
+}
+
+// deliverToUser 投递给目标用户在本节点上的所有会话
+func (h *WebSocketHandler) deliverToUser(meetingID, userID uint, data []byte, messageType int) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 
 	// 查找目标用户的所有会话
 	for _, client := range h.clients {
-		if client.UserID == userID && client.MeetingID == message.MeetingID {
-			data, err := json.Marshal(message)
-			if err != nil {
-				logger.Error("Failed to marshal message for forwarding", logger.Err(err))
-				continue
-			}
-
-			if !client.enqueue(data, fmt.Sprintf("forward:%d", message.Type)) {
+		// 等候中的连接收不到房间内的信令
+		if client.UserID == userID && client.MeetingID() == meetingID && !client.waiting {
+			if !client.enqueue(data, fmt.Sprintf("forward:%d", messageType)) {
 				logger.Warn("Forward send failed; unregistering slow client",
 					logger.String("target_session", client.ID),
 					logger.Uint("target_user", client.UserID),
-					logger.Uint("meeting_id", client.MeetingID),
-					logger.Int("message_type", int(message.Type)),
+					logger.Uint("meeting_id", client.MeetingID()),
+					logger.Int("message_type", messageType),
 				)
 				go h.unregisterClient(client)
 			}
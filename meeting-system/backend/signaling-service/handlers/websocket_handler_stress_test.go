@@ -86,7 +86,7 @@ func TestWebSocketHandlerStress(t *testing.T) {
 
 	totalUsers := 100
 	for i := 1; i <= totalUsers; i++ {
-		user := models.User{ID: uint(i), Username: fmt.Sprintf("user_%d", i), Email: fmt.Sprintf("user_%d@example.com", i), Password: "pwd", Status: models.UserStatusActive}
+		user := models.User{ID: uint(i), Username: fmt.Sprintf("user_%d", i), Email: fmt.Sprintf("user_%d@example.com", i), PasswordHash: "pwd", Status: models.UserStatusActive}
 		require.NoError(t, db.Create(&user).Error)
 		participant := models.MeetingParticipant{MeetingID: meeting.ID, UserID: user.ID, Role: models.ParticipantRoleParticipant, Status: models.ParticipantStatusJoined}
 		require.NoError(t, db.Create(&participant).Error)
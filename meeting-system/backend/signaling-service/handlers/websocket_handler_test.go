@@ -158,6 +158,7 @@ func (suite *WebSocketHandlerTestSuite) SetupSuite() {
 		&models.MeetingParticipant{},
 		&models.SignalingSession{},
 		&models.SignalingMessage{},
+		&models.MeetingChatMessage{},
 	)
 	suite.Require().NoError(err)
 
@@ -195,23 +196,38 @@ func (suite *WebSocketHandlerTestSuite) TearDownSuite() {
 	}
 }
 
+// TearDownTest 等待上一个用例的连接全部注销，避免其离开通知串到下一个用例
+func (suite *WebSocketHandlerTestSuite) TearDownTest() {
+	suite.Eventually(func() bool {
+		return suite.handler.GetClientCount() == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 // createTestData 创建测试数据
 func (suite *WebSocketHandlerTestSuite) createTestData() {
 	// 创建测试用户
 	users := []models.User{
 		{
-			ID:       1,
-			Username: "testuser1",
-			Email:    "test1@example.com",
-			Password: "hashedpassword",
-			Status:   models.UserStatusActive,
+			ID:           1,
+			Username:     "testuser1",
+			Email:        "test1@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
 		},
 		{
-			ID:       2,
-			Username: "testuser2",
-			Email:    "test2@example.com",
-			Password: "hashedpassword",
-			Status:   models.UserStatusActive,
+			ID:           2,
+			Username:     "testuser2",
+			Email:        "test2@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
+		},
+		{
+			// 没有参与者记录，准入状态查询失败
+			ID:           3,
+			Username:     "testuser3",
+			Email:        "test3@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
 		},
 	}
 
@@ -301,6 +317,21 @@ func (suite *WebSocketHandlerTestSuite) connectWebSocket(userID, meetingID uint,
 	return conn, err
 }
 
+// readMessageOfType 读取直到收到指定类型的消息，跳过入会时下发的房间信息、等候室快照等
+func (suite *WebSocketHandlerTestSuite) readMessageOfType(conn *websocket.Conn, msgType models.MessageType) models.WebSocketMessage {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := conn.ReadMessage()
+		suite.Require().NoError(err)
+
+		var msg models.WebSocketMessage
+		suite.Require().NoError(json.Unmarshal(data, &msg))
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
 // TestWebSocketConnection 测试WebSocket连接
 func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection() {
 	conn, err := suite.connectWebSocket(1, 1, "test_peer_1")
@@ -317,6 +348,18 @@ func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection() {
 	suite.Equal(1, roomStats[1])
 }
 
+// TestConnectionRefusedWhenAdmissionUnknown 准入状态查询失败时拒绝连接，不绕过等候室
+func (suite *WebSocketHandlerTestSuite) TestConnectionRefusedWhenAdmissionUnknown() {
+	conn, err := suite.connectWebSocket(3, 1, "unknown_peer")
+	suite.Require().NoError(err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	suite.True(websocket.IsCloseError(err, websocket.CloseTryAgainLater), "unexpected error: %v", err)
+	suite.Equal(0, suite.handler.GetClientCount())
+}
+
 // TestWebSocketConnectionWithMissingParams 测试缺少参数的WebSocket连接
 func (suite *WebSocketHandlerTestSuite) TestWebSocketConnectionWithMissingParams() {
 	// 构建不完整的URL
@@ -397,13 +440,7 @@ func (suite *WebSocketHandlerTestSuite) TestRoomUpdatesOnNewParticipant() {
 	suite.NoError(conn1.WriteMessage(websocket.TextMessage, data))
 
 	// 第一个用户应收到房间信息，参与人数为1
-	conn1.SetReadDeadline(time.Now().Add(5 * time.Second))
-	_, rawMessage, err := conn1.ReadMessage()
-	suite.NoError(err)
-
-	var roomInfo models.WebSocketMessage
-	suite.NoError(json.Unmarshal(rawMessage, &roomInfo))
-	suite.Equal(models.MessageTypeRoomInfo, roomInfo.Type)
+	roomInfo := suite.readMessageOfType(conn1, models.MessageTypeRoomInfo)
 
 	payload, ok := roomInfo.Payload.(map[string]interface{})
 	suite.True(ok)
@@ -455,7 +492,7 @@ func (suite *WebSocketHandlerTestSuite) TestRoomUpdatesOnNewParticipant() {
 	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
 	var roomInfo2 models.WebSocketMessage
 	for i := 0; i < 3; i++ {
-		_, rawMessage, err = conn2.ReadMessage()
+		_, rawMessage, err := conn2.ReadMessage()
 		suite.NoError(err)
 
 		suite.NoError(json.Unmarshal(rawMessage, &roomInfo2))
@@ -511,9 +548,10 @@ func (suite *WebSocketHandlerTestSuite) TestRoomUpdatesOnNewParticipant() {
 	conn1.SetReadDeadline(time.Now().Add(5 * time.Second))
 	receivedJoin := false
 	receivedRoomInfo := false
-	for i := 0; i < 3; i++ {
+	// 主办人入会时还会收到等候室快照，读到两类通知为止
+	for !(receivedJoin && receivedRoomInfo) {
 		_, broadcastData, err := conn1.ReadMessage()
-		suite.NoError(err)
+		suite.Require().NoError(err)
 		var msg models.WebSocketMessage
 		suite.NoError(json.Unmarshal(broadcastData, &msg))
 		switch msg.Type {
@@ -560,9 +598,6 @@ func (suite *WebSocketHandlerTestSuite) TestRoomUpdatesOnNewParticipant() {
 			}
 			receivedRoomInfo = true
 		}
-		if receivedJoin && receivedRoomInfo {
-			break
-		}
 	}
 	suite.True(receivedJoin)
 	suite.True(receivedRoomInfo)
@@ -602,13 +637,7 @@ func (suite *WebSocketHandlerTestSuite) TestMessageRouting() {
 	suite.NoError(err)
 
 	// 从conn2接收消息
-	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
-	_, receivedData, err := conn2.ReadMessage()
-	suite.NoError(err)
-
-	var receivedMessage models.WebSocketMessage
-	err = json.Unmarshal(receivedData, &receivedMessage)
-	suite.NoError(err)
+	receivedMessage := suite.readMessageOfType(conn2, models.MessageTypeChat)
 
 	// 验证消息内容
 	suite.Equal(models.MessageTypeChat, receivedMessage.Type)
@@ -651,13 +680,7 @@ func (suite *WebSocketHandlerTestSuite) TestOfferAnswerExchange() {
 	suite.NoError(err)
 
 	// 从conn2接收Offer
-	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
-	_, receivedData, err := conn2.ReadMessage()
-	suite.NoError(err)
-
-	var receivedOffer models.WebSocketMessage
-	err = json.Unmarshal(receivedData, &receivedOffer)
-	suite.NoError(err)
+	receivedOffer := suite.readMessageOfType(conn2, models.MessageTypeOffer)
 
 	// 验证Offer消息
 	suite.Equal(models.MessageTypeOffer, receivedOffer.Type)
@@ -685,13 +708,7 @@ func (suite *WebSocketHandlerTestSuite) TestOfferAnswerExchange() {
 	suite.NoError(err)
 
 	// 从conn1接收Answer
-	conn1.SetReadDeadline(time.Now().Add(5 * time.Second))
-	_, receivedAnswerData, err := conn1.ReadMessage()
-	suite.NoError(err)
-
-	var receivedAnswer models.WebSocketMessage
-	err = json.Unmarshal(receivedAnswerData, &receivedAnswer)
-	suite.NoError(err)
+	receivedAnswer := suite.readMessageOfType(conn1, models.MessageTypeAnswer)
 
 	// 验证Answer消息
 	suite.Equal(models.MessageTypeAnswer, receivedAnswer.Type)
@@ -723,13 +740,7 @@ func (suite *WebSocketHandlerTestSuite) TestPingPong() {
 	suite.NoError(err)
 
 	// 接收Pong响应
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	_, receivedData, err := conn.ReadMessage()
-	suite.NoError(err)
-
-	var pongMessage models.WebSocketMessage
-	err = json.Unmarshal(receivedData, &pongMessage)
-	suite.NoError(err)
+	pongMessage := suite.readMessageOfType(conn, models.MessageTypePong)
 
 	// 验证Pong消息
 	suite.Equal(models.MessageTypePong, pongMessage.Type)
@@ -769,15 +780,7 @@ func (suite *WebSocketHandlerTestSuite) TestInvalidMessage() {
 	suite.NoError(err)
 
 	// 接收错误消息
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	_, receivedData, err := conn.ReadMessage()
-	suite.NoError(err)
-
-	var errorMessage models.WebSocketMessage
-	err = json.Unmarshal(receivedData, &errorMessage)
-	suite.NoError(err)
-
-	// 验证错误消息
+	errorMessage := suite.readMessageOfType(conn, models.MessageTypeError)
 	suite.Equal(models.MessageTypeError, errorMessage.Type)
 }
 
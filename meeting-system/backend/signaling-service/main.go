@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -139,9 +140,17 @@ func main() {
 		registerSignalingTaskHandlers(queueManager)
 	}
 
-	// MongoDB和MinIO不是信令服务的必需依赖，跳过初始化
-	logger.Info("MongoDB and MinIO initialization skipped (not required for signaling service)")
-	log.Println("MongoDB and MinIO initialization skipped (not required for signaling service)")
+	// MongoDB 仅在聊天存储配置为 mongodb 时需要；MinIO 不是信令服务的依赖
+	if cfg.Chat.Store == config.ChatStoreMongoDB {
+		logger.Info("Initializing MongoDB for chat storage...")
+		if err := database.InitMongoDB(cfg.MongoDB); err != nil {
+			logger.Fatal("Failed to initialize MongoDB for chat storage: " + err.Error())
+		}
+		defer database.CloseMongoDB()
+	} else {
+		logger.Info("MongoDB and MinIO initialization skipped (not required for signaling service)")
+		log.Println("MongoDB and MinIO initialization skipped (not required for signaling service)")
+	}
 
 	// 自动迁移数据库表（异步执行，不阻塞服务启动）
 	go func() {
@@ -150,6 +159,13 @@ func main() {
 		if err := database.AutoMigrate(
 			&models.SignalingSession{},
 			&models.SignalingMessage{},
+			&models.AIStreamResultRecord{},
+			&models.Poll{},
+			&models.PollOption{},
+			&models.PollVote{},
+			&models.MeetingQuestion{},
+			&models.QuestionUpvote{},
+			&models.MeetingChatMessage{},
 		); err != nil {
 			logger.Error("Failed to migrate database: " + err.Error())
 			log.Printf("Failed to migrate database: %v", err)
@@ -209,7 +225,27 @@ func main() {
 	// 初始化服务
 	logger.Info("Initializing signaling service components...")
 	signalingService := services.NewSignalingService(grpcClients)
+	chatStore, err := database.NewChatStore(cfg.Chat)
+	if err != nil {
+		logger.Fatal("Failed to initialize chat store: " + err.Error())
+	}
+	signalingService.SetChatStore(chatStore)
 	wsHandler := handlers.NewWebSocketHandler(signalingService)
+	if queueManager != nil {
+		registerAIResultDelivery(queueManager, wsHandler)
+		registerLobbyDecisions(queueManager, wsHandler)
+		registerHostControls(queueManager, wsHandler)
+		registerBreakouts(queueManager, wsHandler)
+		registerChatDelivery(queueManager, wsHandler)
+		registerICERestartDelivery(queueManager, wsHandler)
+	}
+	if cfg.Signaling.Cluster.Enabled {
+		if !redisInitialized {
+			logger.Fatal("Signaling cluster mode requires Redis")
+		}
+		stopClusterBus := enableClusterMode(cfg, wsHandler)
+		defer stopClusterBus()
+	}
 	logger.Info("Signaling service components initialized")
 
 	// 注册路由
@@ -324,6 +360,7 @@ func setupRoutes(r *gin.Engine, wsHandler *handlers.WebSocketHandler, signalingS
 		messages := v1.Group("/messages")
 		{
 			messages.GET("/history/:meeting_id", getMessageHistory(signalingService))
+			messages.GET("/ai-results/:meeting_id", getAIStreamResults(signalingService))
 		}
 
 		// 统计信息
@@ -405,6 +442,40 @@ func getMessageHistory(service *services.SignalingService) gin.HandlerFunc {
 	}
 }
 
+// getAIStreamResults 获取会议的 AI 流式结果（字幕/情绪/伪造告警），支持按类别与说话人过滤
+func getAIStreamResults(service *services.SignalingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meetingID, err := parseUint(c.Param("meeting_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meeting_id"})
+			return
+		}
+
+		var speakerUserID uint64
+		if speakerStr := c.Query("speaker_user_id"); speakerStr != "" {
+			if speakerUserID, err = parseUint(speakerStr); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid speaker_user_id"})
+				return
+			}
+		}
+
+		limit := 200 // 默认限制
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := parseUint(limitStr); err == nil && l > 0 && l <= 1000 {
+				limit = int(l)
+			}
+		}
+
+		results, err := service.GetAIStreamResults(uint(meetingID), c.Query("kind"), uint(speakerUserID), limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get AI stream results"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": results})
+	}
+}
+
 // getStatsOverview 获取统计概览
 func getStatsOverview(service *services.SignalingService, wsHandler *handlers.WebSocketHandler) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -651,3 +722,276 @@ func registerSignalingTaskHandlers(qm *queue.QueueManager) {
 
 	logger.Info("All signaling task handlers registered successfully")
 }
+
+// enableClusterMode 开启多实例集群模式，返回关闭节点间事件总线的函数
+func enableClusterMode(cfg *config.Config, wsHandler *handlers.WebSocketHandler) func() {
+	clusterCfg := cfg.Signaling.Cluster
+	nodeID := clusterCfg.NodeID
+	if nodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			logger.Fatal("Failed to resolve cluster node id: " + err.Error())
+		}
+		nodeID = hostname
+	}
+
+	var bus interface {
+		handlers.ClusterBus
+		Stop() error
+	}
+	switch clusterCfg.Transport {
+	case "", "redis":
+		bus = queue.NewRedisPubSubQueue(database.GetRedis())
+	case "kafka":
+		// 每个节点使用独立消费组，保证所有节点都能收到全部扇出消息
+		kafkaCfg := *cfg
+		kafkaCfg.Kafka.GroupID = cfg.Kafka.GroupID + "-" + nodeID
+		bus = queue.NewKafkaPubSubFromConfig(kafkaCfg)
+	default:
+		logger.Fatal("Unsupported signaling cluster transport: " + clusterCfg.Transport)
+	}
+
+	cluster := handlers.NewCluster(nodeID, database.GetRedis(), bus, time.Duration(clusterCfg.PresenceTTL)*time.Second)
+	if err := wsHandler.EnableCluster(cluster); err != nil {
+		logger.Fatal("Failed to enable signaling cluster mode: " + err.Error())
+	}
+	logger.Info("Signaling cluster mode enabled",
+		logger.String("node_id", nodeID),
+		logger.String("transport", clusterCfg.Transport))
+
+	return func() {
+		if err := bus.Stop(); err != nil {
+			logger.Warn("Failed to stop cluster bus: " + err.Error())
+		}
+	}
+}
+
+// registerAIResultDelivery 订阅媒体服务发布的 AI 流式结果并推送给会议参与者；
+// 服务端 AI Live 模式下同时通过事件总线通知媒体服务对会议内的音频轨道运行 AI
+func registerAIResultDelivery(qm *queue.QueueManager, wsHandler *handlers.WebSocketHandler) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+	wsHandler.SetEventPublisher(pubsub)
+
+	pubsub.Subscribe(queue.ChannelAIEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		if msg.Type != queue.EventAIStreamResult {
+			return nil
+		}
+
+		meetingID, ok := msg.Payload["meeting_id"].(float64)
+		if !ok || meetingID <= 0 {
+			return fmt.Errorf("ai stream result missing meeting_id")
+		}
+		data, err := json.Marshal(msg.Payload["result"])
+		if err != nil {
+			return fmt.Errorf("failed to encode ai stream result: %w", err)
+		}
+		var result models.AIStreamResultMessage
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("invalid ai stream result: %w", err)
+		}
+
+		wsHandler.DeliverAIStreamResult(uint(meetingID), &result)
+		return nil
+	})
+
+	logger.Info("AI stream result delivery registered")
+}
+
+// registerLobbyDecisions 订阅会议服务发布的等候室准入/拒绝结果，处理本节点上等候中的连接
+func registerLobbyDecisions(qm *queue.QueueManager, wsHandler *handlers.WebSocketHandler) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+
+	pubsub.Subscribe(queue.ChannelMeetingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		if msg.Type != queue.EventLobbyAdmitted && msg.Type != queue.EventLobbyDenied {
+			return nil
+		}
+
+		meetingID, ok := msg.Payload["meeting_id"].(float64)
+		if !ok || meetingID <= 0 {
+			return fmt.Errorf("lobby event missing meeting_id")
+		}
+		rawIDs, ok := msg.Payload["user_ids"].([]interface{})
+		if !ok {
+			return fmt.Errorf("lobby event missing user_ids")
+		}
+		userIDs := make([]uint, 0, len(rawIDs))
+		for _, raw := range rawIDs {
+			if id, ok := raw.(float64); ok && id > 0 {
+				userIDs = append(userIDs, uint(id))
+			}
+		}
+		byUserID, _ := msg.Payload["by_user_id"].(float64)
+
+		wsHandler.ApplyLobbyDecision(uint(meetingID), userIDs, msg.Type == queue.EventLobbyAdmitted, uint(byUserID))
+		return nil
+	})
+
+	logger.Info("Lobby decision delivery registered")
+}
+
+// registerHostControls 订阅会议服务发布的主持控制（REST 接口发起），在信令房间内执行并广播
+func registerHostControls(qm *queue.QueueManager, wsHandler *handlers.WebSocketHandler) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+
+	pubsub.Subscribe(queue.ChannelMeetingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		if msg.Type != queue.EventHostControl {
+			return nil
+		}
+
+		meetingID, ok := msg.Payload["meeting_id"].(float64)
+		if !ok || meetingID <= 0 {
+			return fmt.Errorf("host control event missing meeting_id")
+		}
+		action, _ := msg.Payload["action"].(string)
+		if action == "" {
+			return fmt.Errorf("host control event missing action")
+		}
+		targetUserID, _ := msg.Payload["target_user_id"].(float64)
+		role, _ := msg.Payload["role"].(float64)
+		allowSelfUnmute, _ := msg.Payload["allow_self_unmute"].(bool)
+		byUserID, _ := msg.Payload["by_user_id"].(float64)
+
+		wsHandler.ApplyHostControl(uint(meetingID), models.HostControlMessage{
+			Action:          action,
+			TargetUserID:    uint(targetUserID),
+			AllowSelfUnmute: allowSelfUnmute,
+			Role:            models.ParticipantRole(role),
+		}, uint(byUserID))
+		return nil
+	})
+
+	logger.Info("Host control delivery registered")
+}
+
+// registerBreakouts 订阅会议服务发布的分组讨论事件：迁移连接、向各分组广播消息
+func registerBreakouts(qm *queue.QueueManager, wsHandler *handlers.WebSocketHandler) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+
+	pubsub.Subscribe(queue.ChannelMeetingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		switch msg.Type {
+		case queue.EventBreakoutOpened, queue.EventBreakoutBroadcast, queue.EventBreakoutClosed:
+		default:
+			return nil
+		}
+
+		meetingID, ok := msg.Payload["meeting_id"].(float64)
+		if !ok || meetingID <= 0 {
+			return fmt.Errorf("breakout event missing meeting_id")
+		}
+		byUserID, _ := msg.Payload["by_user_id"].(float64)
+		rawIDs, ok := msg.Payload["breakout_ids"].([]interface{})
+		if !ok {
+			return fmt.Errorf("breakout event missing breakout_ids")
+		}
+		breakoutIDs := make([]uint, 0, len(rawIDs))
+		for _, raw := range rawIDs {
+			if id, ok := raw.(float64); ok && id > 0 {
+				breakoutIDs = append(breakoutIDs, uint(id))
+			}
+		}
+
+		switch msg.Type {
+		case queue.EventBreakoutOpened:
+			var assignments []models.BreakoutAssignment
+			items, _ := msg.Payload["assignments"].([]interface{})
+			for _, item := range items {
+				entry, _ := item.(map[string]interface{})
+				userID, _ := entry["user_id"].(float64)
+				breakoutID, _ := entry["breakout_id"].(float64)
+				if userID > 0 && breakoutID > 0 {
+					assignments = append(assignments, models.BreakoutAssignment{UserID: uint(userID), BreakoutID: uint(breakoutID)})
+				}
+			}
+			var closesAt *time.Time
+			if unix, _ := msg.Payload["closes_at"].(float64); unix > 0 {
+				t := time.Unix(int64(unix), 0)
+				closesAt = &t
+			}
+			wsHandler.ApplyBreakoutOpened(uint(meetingID), breakoutIDs, assignments, closesAt, uint(byUserID))
+		case queue.EventBreakoutBroadcast:
+			message, _ := msg.Payload["message"].(string)
+			wsHandler.ApplyBreakoutBroadcast(uint(meetingID), breakoutIDs, message, uint(byUserID))
+		case queue.EventBreakoutClosed:
+			wsHandler.ApplyBreakoutClosed(uint(meetingID), breakoutIDs, uint(byUserID))
+		}
+		return nil
+	})
+
+	logger.Info("Breakout delivery registered")
+}
+
+// registerChatDelivery 订阅会议服务发布的聊天消息（REST 接口发送、编辑或删除），按可见范围推送给会议参与者
+func registerChatDelivery(qm *queue.QueueManager, wsHandler *handlers.WebSocketHandler) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+
+	pubsub.Subscribe(queue.ChannelMeetingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		if msg.Type != queue.EventChatMessage {
+			return nil
+		}
+
+		action, _ := msg.Payload["action"].(string)
+		byUserID, _ := msg.Payload["by_user_id"].(float64)
+		raw, err := json.Marshal(msg.Payload["message"])
+		if err != nil {
+			return fmt.Errorf("invalid chat event: %w", err)
+		}
+		var payload models.ChatMessage
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return fmt.Errorf("invalid chat event: %w", err)
+		}
+		if payload.MeetingID == 0 || payload.ID == "" {
+			return fmt.Errorf("chat event missing meeting_id or id")
+		}
+		wsHandler.ApplyChatMessage(payload, action, uint(byUserID))
+		return nil
+	})
+
+	logger.Info("Chat delivery registered")
+}
+
+// registerICERestartDelivery 订阅媒体服务发布的 ICE restart 通知，推送给对应用户的客户端
+func registerICERestartDelivery(qm *queue.QueueManager, wsHandler *handlers.WebSocketHandler) {
+	pubsub := qm.GetKafkaEventBus()
+	if pubsub == nil {
+		return
+	}
+
+	pubsub.Subscribe(queue.ChannelSignalingEvents, func(ctx context.Context, msg *queue.PubSubMessage) error {
+		if msg.Type != queue.EventICERestart {
+			return nil
+		}
+
+		meetingID, _ := msg.Payload["meeting_id"].(float64)
+		userID, _ := msg.Payload["user_id"].(float64)
+		if meetingID <= 0 || userID <= 0 {
+			return fmt.Errorf("ice restart event missing meeting_id/user_id")
+		}
+		data, err := json.Marshal(msg.Payload["message"])
+		if err != nil {
+			return fmt.Errorf("invalid ice restart event: %w", err)
+		}
+		var restart models.ICERestartMessage
+		if err := json.Unmarshal(data, &restart); err != nil {
+			return fmt.Errorf("invalid ice restart event: %w", err)
+		}
+		wsHandler.DeliverICERestart(uint(meetingID), uint(userID), &restart)
+		return nil
+	})
+
+	logger.Info("ICE restart delivery registered")
+}
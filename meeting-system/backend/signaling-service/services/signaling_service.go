@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"meeting-system/shared/config"
 	"meeting-system/shared/database"
@@ -22,6 +24,7 @@ type SignalingService struct {
 	db          *gorm.DB
 	cache       *redis.Client
 	grpcClients *grpc.ServiceClients
+	chat        database.ChatStore
 }
 
 var errMeetingValidationUnavailable = errors.New("meeting service validation unavailable")
@@ -32,9 +35,15 @@ func NewSignalingService(grpcClients *grpc.ServiceClients) *SignalingService {
 		db:          database.GetDB(),
 		cache:       database.GetRedis(),
 		grpcClients: grpcClients,
+		chat:        database.NewSQLChatStore(database.GetDB()),
 	}
 }
 
+// SetChatStore 替换聊天存储（chat.store 为 mongodb 时使用 MongoDB）
+func (s *SignalingService) SetChatStore(store database.ChatStore) {
+	s.chat = store
+}
+
 // ValidateUserAccess 检查用户是否有权加入会议
 func (s *SignalingService) ValidateUserAccess(userID, meetingID uint) error {
 	if err := s.validateUserAccessViaMeetingService(userID, meetingID); err != nil {
@@ -152,6 +161,27 @@ func (s *SignalingService) UpdateSessionStatus(sessionID string, status models.S
 	return nil
 }
 
+// MoveSession 会话迁移到另一个会议房间（分组讨论开启/关闭时不重连）
+func (s *SignalingService) MoveSession(sessionID string, fromMeetingID, toMeetingID uint) error {
+	if err := s.db.Model(&models.SignalingSession{}).
+		Where("session_id = ?", sessionID).
+		Update("meeting_id", toMeetingID).Error; err != nil {
+		return fmt.Errorf("failed to move session: %w", err)
+	}
+
+	if s.cache != nil {
+		cfg := config.GlobalConfig
+		ctx := context.Background()
+		if err := s.cache.SRem(ctx, cfg.Redis.RoomPrefix+fmt.Sprintf("%d", fromMeetingID), sessionID).Err(); err != nil {
+			logger.Warn("Failed to remove session from room", logger.Err(err))
+		}
+		if err := s.cache.SAdd(ctx, cfg.Redis.RoomPrefix+fmt.Sprintf("%d", toMeetingID), sessionID).Err(); err != nil {
+			logger.Warn("Failed to add session to room", logger.Err(err))
+		}
+	}
+	return nil
+}
+
 // DisconnectSession 断开会话
 func (s *SignalingService) DisconnectSession(sessionID string) error {
 	// 获取会话信息
@@ -286,6 +316,152 @@ func (s *SignalingService) GetMeetingInfo(meetingID uint) (*models.Meeting, erro
 	return &meeting, nil
 }
 
+// GetMeetingSettings 获取会议设置（未设置时返回默认值）
+func (s *SignalingService) GetMeetingSettings(meetingID uint) (*models.MeetingSettings, error) {
+	meeting, err := s.GetMeetingInfo(meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMeetingSettings(meeting.Settings)
+}
+
+func decodeMeetingSettings(raw string) (*models.MeetingSettings, error) {
+	settings := &models.MeetingSettings{}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), settings); err != nil {
+			return nil, fmt.Errorf("invalid meeting settings: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// SetMeetingLocked 锁定或解锁会议（保存在会议设置中）；在行锁下读改写，避免并发修改设置时互相覆盖
+func (s *SignalingService) SetMeetingLocked(meetingID uint, locked bool) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var meeting models.Meeting
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&meeting, meetingID).Error; err != nil {
+			return fmt.Errorf("meeting not found: %w", err)
+		}
+		settings, err := decodeMeetingSettings(meeting.Settings)
+		if err != nil {
+			return err
+		}
+		settings.Locked = locked
+
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to encode meeting settings: %w", err)
+		}
+		if err := tx.Model(&models.Meeting{}).Where("id = ?", meetingID).Update("settings", string(data)).Error; err != nil {
+			return fmt.Errorf("failed to update meeting settings: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetParticipantRole 变更参与者角色
+func (s *SignalingService) SetParticipantRole(userID, meetingID uint, role models.ParticipantRole) error {
+	if err := s.db.Model(&models.MeetingParticipant{}).
+		Where("meeting_id = ? AND user_id = ?", meetingID, userID).
+		Update("role", role).Error; err != nil {
+		return fmt.Errorf("failed to update participant role: %w", err)
+	}
+	return nil
+}
+
+// RecordOperation 写入操作审计日志
+func (s *SignalingService) RecordOperation(entry *models.OperationLog) error {
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+	return nil
+}
+
+// GetParticipantRole 获取用户在会议中的角色（会议创建者视为主办人）
+func (s *SignalingService) GetParticipantRole(userID, meetingID uint) (models.ParticipantRole, error) {
+	var meeting models.Meeting
+	if err := s.db.First(&meeting, meetingID).Error; err != nil {
+		return 0, fmt.Errorf("meeting not found: %w", err)
+	}
+	if meeting.CreatorID == userID {
+		return models.ParticipantRoleHost, nil
+	}
+
+	var participant models.MeetingParticipant
+	if err := s.db.Where("meeting_id = ? AND user_id = ?", meetingID, userID).First(&participant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("user not in meeting")
+		}
+		return 0, fmt.Errorf("failed to query participant: %w", err)
+	}
+
+	return participant.Role, nil
+}
+
+// GetParticipantStatus 获取用户在会议中的参与状态（会议创建者视为已加入）
+func (s *SignalingService) GetParticipantStatus(userID, meetingID uint) (models.ParticipantStatus, error) {
+	var meeting models.Meeting
+	if err := s.db.First(&meeting, meetingID).Error; err != nil {
+		return 0, fmt.Errorf("meeting not found: %w", err)
+	}
+	if meeting.CreatorID == userID {
+		return models.ParticipantStatusJoined, nil
+	}
+
+	var participant models.MeetingParticipant
+	if err := s.db.Where("meeting_id = ? AND user_id = ?", meetingID, userID).First(&participant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("user not in meeting")
+		}
+		return 0, fmt.Errorf("failed to query participant: %w", err)
+	}
+
+	return participant.Status, nil
+}
+
+// SetParticipantStatus 更新参与状态（等候室准入/拒绝、移出/封禁）；准入时记录加入时间，移出时记录离开时间
+func (s *SignalingService) SetParticipantStatus(userID, meetingID uint, status models.ParticipantStatus) error {
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case models.ParticipantStatusJoined:
+		updates["joined_at"] = time.Now()
+	case models.ParticipantStatusLeft, models.ParticipantStatusBanned:
+		updates["left_at"] = time.Now()
+	}
+	if err := s.db.Model(&models.MeetingParticipant{}).
+		Where("meeting_id = ? AND user_id = ?", meetingID, userID).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update participant status: %w", err)
+	}
+	return nil
+}
+
+// ListWaitingParticipants 获取等候室中的用户（按进入等候室的先后排序）
+func (s *SignalingService) ListWaitingParticipants(meetingID uint) ([]models.LobbyEntry, error) {
+	var participants []models.MeetingParticipant
+	if err := s.db.Preload("User").
+		Where("meeting_id = ? AND status = ?", meetingID, models.ParticipantStatusWaiting).
+		Order("created_at ASC").
+		Find(&participants).Error; err != nil {
+		return nil, fmt.Errorf("failed to query waiting participants: %w", err)
+	}
+
+	entries := make([]models.LobbyEntry, 0, len(participants))
+	for _, participant := range participants {
+		username := participant.User.Username
+		if username == "" {
+			username = fmt.Sprintf("user_%d", participant.UserID)
+		}
+		entries = append(entries, models.LobbyEntry{
+			UserID:       participant.UserID,
+			Username:     username,
+			WaitingSince: participant.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
 // GetActiveSessionCount 获取活跃会话数量
 func (s *SignalingService) GetActiveSessionCount() (int64, error) {
 	var count int64
@@ -357,6 +533,213 @@ func (s *SignalingService) GetMessageHistory(meetingID uint, limit int) ([]*mode
 	return messages, nil
 }
 
+// SaveAIStreamResult 持久化 AI 流式结果；ResultID 已存在时不重复写入，返回 false
+func (s *SignalingService) SaveAIStreamResult(meetingID uint, result *models.AIStreamResultMessage) (bool, error) {
+	if result == nil || result.ResultID == "" {
+		return false, fmt.Errorf("ai stream result missing result_id")
+	}
+
+	payloadJSON, err := json.Marshal(result)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal ai stream result: %w", err)
+	}
+
+	resultAt := time.Now()
+	if result.TimestampMs > 0 {
+		resultAt = time.UnixMilli(result.TimestampMs)
+	}
+	record := &models.AIStreamResultRecord{
+		ResultID:      result.ResultID,
+		MeetingID:     meetingID,
+		SpeakerUserID: result.SpeakerUserID,
+		SpeakerPeerID: result.SpeakerPeerID,
+		StreamID:      result.StreamID,
+		TrackID:       result.TrackID,
+		TrackKind:     result.TrackKind,
+		Kind:          result.Kind,
+		ResultType:    result.ResultType,
+		Sequence:      result.Sequence,
+		Confidence:    result.Confidence,
+		Text:          result.Text,
+		Label:         result.Label,
+		Warning:       result.Warning,
+		Payload:       string(payloadJSON),
+		ResultAt:      resultAt,
+	}
+
+	tx := s.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "result_id"}}, DoNothing: true}).Create(record)
+	if tx.Error != nil {
+		return false, fmt.Errorf("failed to save ai stream result: %w", tx.Error)
+	}
+	return tx.RowsAffected > 0, nil
+}
+
+// GetAIStreamResults 按时间顺序获取会议的 AI 流式结果（kind/speaker 为空或 0 时不过滤）
+func (s *SignalingService) GetAIStreamResults(meetingID uint, kind string, speakerUserID uint, limit int) ([]*models.AIStreamResultRecord, error) {
+	query := s.db.Where("meeting_id = ?", meetingID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if speakerUserID > 0 {
+		query = query.Where("speaker_user_id = ?", speakerUserID)
+	}
+
+	var records []*models.AIStreamResultRecord
+	if err := query.Order("result_at DESC").Order("id DESC").Limit(limit).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ai stream results: %w", err)
+	}
+
+	// 取最近 limit 条后按时间正序返回，便于直接渲染字幕
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+// CreatePoll 保存投票及其选项
+func (s *SignalingService) CreatePoll(poll *models.Poll) error {
+	if err := s.db.Create(poll).Error; err != nil {
+		return fmt.Errorf("failed to create poll: %w", err)
+	}
+	return nil
+}
+
+// GetPoll 获取投票及其选项
+func (s *SignalingService) GetPoll(pollID uint) (*models.Poll, error) {
+	var poll models.Poll
+	if err := s.db.Preload("Options").First(&poll, pollID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	return &poll, nil
+}
+
+// ClosePoll 结束投票
+func (s *SignalingService) ClosePoll(pollID uint, closedAt time.Time) error {
+	if err := s.db.Model(&models.Poll{}).
+		Where("id = ? AND status = ?", pollID, models.PollStatusOpen).
+		Updates(map[string]interface{}{"status": models.PollStatusClosed, "closed_at": closedAt}).Error; err != nil {
+		return fmt.Errorf("failed to close poll: %w", err)
+	}
+	return nil
+}
+
+// ReplacePollVotes 以 optionIDs 覆盖用户在该投票中之前的选择
+func (s *SignalingService) ReplacePollVotes(pollID, userID uint, optionIDs []uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).Delete(&models.PollVote{}).Error; err != nil {
+			return err
+		}
+		votes := make([]models.PollVote, 0, len(optionIDs))
+		for _, optionID := range optionIDs {
+			votes = append(votes, models.PollVote{PollID: pollID, UserID: userID, OptionID: optionID})
+		}
+		return tx.Create(&votes).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save poll votes: %w", err)
+	}
+	return nil
+}
+
+// ListPollVotes 获取投票的全部选择（按投票先后）
+func (s *SignalingService) ListPollVotes(pollID uint) ([]models.PollVote, error) {
+	var votes []models.PollVote
+	if err := s.db.Where("poll_id = ?", pollID).Order("id ASC").Find(&votes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll votes: %w", err)
+	}
+	return votes, nil
+}
+
+// CreateQuestion 保存问答中的问题
+func (s *SignalingService) CreateQuestion(question *models.MeetingQuestion) error {
+	if err := s.db.Create(question).Error; err != nil {
+		return fmt.Errorf("failed to create question: %w", err)
+	}
+	return nil
+}
+
+// GetQuestion 获取问题
+func (s *SignalingService) GetQuestion(questionID uint) (*models.MeetingQuestion, error) {
+	var question models.MeetingQuestion
+	if err := s.db.First(&question, questionID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+	return &question, nil
+}
+
+// UpdateQuestion 保存问题的审核/回答状态
+func (s *SignalingService) UpdateQuestion(question *models.MeetingQuestion) error {
+	if err := s.db.Model(question).Updates(map[string]interface{}{
+		"status":      question.Status,
+		"answer":      question.Answer,
+		"answered_by": question.AnsweredBy,
+		"answered_at": question.AnsweredAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update question: %w", err)
+	}
+	return nil
+}
+
+// SetQuestionUpvote 点赞（每人每题一次）或取消点赞，重新统计并返回点赞数
+func (s *SignalingService) SetQuestionUpvote(questionID, userID uint, upvote bool) (int, error) {
+	var count int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if upvote {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.QuestionUpvote{QuestionID: questionID, UserID: userID}).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Where("question_id = ? AND user_id = ?", questionID, userID).
+			Delete(&models.QuestionUpvote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.QuestionUpvote{}).Where("question_id = ?", questionID).Count(&count).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.MeetingQuestion{}).Where("id = ?", questionID).Update("upvotes", count).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update question upvote: %w", err)
+	}
+	return int(count), nil
+}
+
+const chatStoreTimeout = 5 * time.Second
+
+// SaveChatMessage 保存聊天消息
+func (s *SignalingService) SaveChatMessage(message *models.MeetingChatMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+	if err := s.chat.SaveChatMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to save chat message: %w", err)
+	}
+	return nil
+}
+
+// GetChatMessage 查询聊天消息，不存在时返回 models.ErrChatMessageNotFound
+func (s *SignalingService) GetChatMessage(id string) (*models.MeetingChatMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+	return s.chat.GetChatMessage(ctx, id)
+}
+
+// UpdateChatMessage 保存聊天消息的编辑或删除
+func (s *SignalingService) UpdateChatMessage(message *models.MeetingChatMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+	if err := s.chat.UpdateChatMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to update chat message: %w", err)
+	}
+	return nil
+}
+
+// ListChatMessages 查询聊天历史（倒序）
+func (s *SignalingService) ListChatMessages(query models.ChatHistoryQuery) ([]models.MeetingChatMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), chatStoreTimeout)
+	defer cancel()
+	return s.chat.ListChatMessages(ctx, query)
+}
+
 // ValidateUserToken 验证用户令牌 (调用用户服务)
 func (s *SignalingService) ValidateUserToken(ctx context.Context, token string) (*grpc.ValidateTokenResponse, error) {
 	if s.grpcClients == nil || s.grpcClients.UserClient == nil {
@@ -427,6 +810,9 @@ func (s *SignalingService) validateUserAccessFromDB(userID, meetingID uint) erro
 	if participant.Status == models.ParticipantStatusRejected {
 		return fmt.Errorf("user is rejected from meeting")
 	}
+	if participant.Status == models.ParticipantStatusBanned {
+		return fmt.Errorf("user is banned from meeting")
+	}
 
 	return nil
 }
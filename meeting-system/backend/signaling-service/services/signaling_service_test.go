@@ -72,18 +72,18 @@ func (suite *SignalingServiceTestSuite) createTestData() {
 	// 创建测试用户
 	users := []models.User{
 		{
-			ID:       1,
-			Username: "testuser1",
-			Email:    "test1@example.com",
-			Password: "hashedpassword",
-			Status:   models.UserStatusActive,
+			ID:           1,
+			Username:     "testuser1",
+			Email:        "test1@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
 		},
 		{
-			ID:       2,
-			Username: "testuser2",
-			Email:    "test2@example.com",
-			Password: "hashedpassword",
-			Status:   models.UserStatusActive,
+			ID:           2,
+			Username:     "testuser2",
+			Email:        "test2@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
 		},
 	}
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -13,7 +14,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
 	"meeting-system/shared/config"
@@ -85,6 +85,7 @@ func (suite *ConcurrentStressTestSuite) SetupSuite() {
 		&models.MeetingParticipant{},
 		&models.SignalingSession{},
 		&models.SignalingMessage{},
+		&models.MeetingChatMessage{},
 	)
 	suite.NoError(err)
 
@@ -128,10 +129,10 @@ func (suite *ConcurrentStressTestSuite) createTestData() {
 	// 创建测试用户
 	for i := 1; i <= 1000; i++ {
 		user := &models.User{
-			Username: fmt.Sprintf("stress_user_%d", i),
-			Email:    fmt.Sprintf("stress_user_%d@test.com", i),
-			Password: "hashed_password",
-			Status:   models.UserStatusActive,
+			Username:     fmt.Sprintf("stress_user_%d", i),
+			Email:        fmt.Sprintf("stress_user_%d@test.com", i),
+			PasswordHash: "hashed_password",
+			Status:       models.UserStatusActive,
 		}
 		db.Create(user)
 	}
@@ -153,7 +154,7 @@ func (suite *ConcurrentStressTestSuite) createTestData() {
 				MeetingID: uint(i),
 				UserID:    uint(j),
 				Role:      models.ParticipantRoleParticipant,
-				Status:    models.ParticipantStatusAccepted,
+				Status:    models.ParticipantStatusJoined,
 			}
 			db.Create(participant)
 		}
@@ -199,9 +200,9 @@ func (suite *ConcurrentStressTestSuite) printMetrics() {
 	suite.metrics.EndTime = time.Now()
 	duration := suite.metrics.EndTime.Sub(suite.metrics.StartTime)
 
-	fmt.Println("\n" + "="*80)
+	fmt.Println("\n" + strings.Repeat("=", 80))
 	fmt.Println("📊 信令服务并发压力测试报告")
-	fmt.Println("="*80)
+	fmt.Println(strings.Repeat("=", 80))
 	fmt.Printf("测试时长: %v\n", duration)
 	fmt.Printf("总连接数: %d\n", suite.metrics.TotalConnections)
 	fmt.Printf("成功连接: %d (%.2f%%)\n", suite.metrics.SuccessfulConnections,
@@ -228,7 +229,7 @@ func (suite *ConcurrentStressTestSuite) printMetrics() {
 		fmt.Printf("连接速率: %.2f conn/s\n", connPerSec)
 	}
 
-	fmt.Println("="*80)
+	fmt.Println(strings.Repeat("=", 80))
 }
 
 // TestConcurrentConnections 测试并发连接
@@ -309,10 +310,10 @@ func (suite *ConcurrentStressTestSuite) runConcurrentConnectionTest(numConnectio
 // TestConcurrentMessaging 测试并发消息发送
 func (suite *ConcurrentStressTestSuite) TestConcurrentMessaging() {
 	testCases := []struct {
-		name           string
-		numClients     int
+		name              string
+		numClients        int
 		messagesPerClient int
-		meetingID      uint
+		meetingID         uint
 	}{
 		{"小规模消息-10客户端x10消息", 10, 10, 1},
 		{"中规模消息-20客户端x20消息", 20, 20, 1},
@@ -565,7 +566,6 @@ func (suite *ConcurrentStressTestSuite) TestMixedScenario() {
 	}
 
 	// 场景3: 高频消息客户端 (40%)
-	highFreqClients := numClients - longLivedClients - reconnectClients
 	for i := longLivedClients + reconnectClients; i < numClients; i++ {
 		wg.Add(1)
 		go func(clientID int) {
@@ -703,4 +703,3 @@ func (suite *ConcurrentStressTestSuite) simulateHighFrequencyClient(ctx context.
 func TestConcurrentStressTestSuite(t *testing.T) {
 	suite.Run(t, new(ConcurrentStressTestSuite))
 }
-
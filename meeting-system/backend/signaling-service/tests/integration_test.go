@@ -99,6 +99,7 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 		&models.MeetingParticipant{},
 		&models.SignalingSession{},
 		&models.SignalingMessage{},
+		&models.MeetingChatMessage{},
 	)
 	suite.Require().NoError(err)
 
@@ -174,18 +175,18 @@ func (suite *IntegrationTestSuite) createTestData() {
 	// 创建测试用户
 	users := []models.User{
 		{
-			ID:       1,
-			Username: "testuser1",
-			Email:    "test1@example.com",
-			Password: "hashedpassword",
-			Status:   models.UserStatusActive,
+			ID:           1,
+			Username:     "testuser1",
+			Email:        "test1@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
 		},
 		{
-			ID:       2,
-			Username: "testuser2",
-			Email:    "test2@example.com",
-			Password: "hashedpassword",
-			Status:   models.UserStatusActive,
+			ID:           2,
+			Username:     "testuser2",
+			Email:        "test2@example.com",
+			PasswordHash: "hashedpassword",
+			Status:       models.UserStatusActive,
 		},
 	}
 